@@ -9,8 +9,12 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
 	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
 	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/imu"
+	"github.com/shaunagostinho/speeduino-dash/internal/keypad"
+	"github.com/shaunagostinho/speeduino-dash/internal/sensors"
 	"github.com/shaunagostinho/speeduino-dash/internal/server"
 	"github.com/shaunagostinho/speeduino-dash/web"
 )
@@ -18,7 +22,10 @@ import (
 func main() {
 	configPath := flag.String("config", "/etc/goefidash/config.yaml", "Path to config file")
 	demo := flag.Bool("demo", false, "Run with simulated ECU and GPS data")
+	demoECU := flag.Bool("demo-ecu", false, "Simulate the ECU, extra ECUs and sensors only (real GPS)")
+	demoGPS := flag.Bool("demo-gps", false, "Simulate GPS and the IMU only (real ECU), e.g. on the bench indoors")
 	listenAddr := flag.String("listen", "", "Override listen address (e.g. :8080)")
+	debug := flag.Bool("debug", false, "Enable debug API (fault injection)")
 	flag.Parse()
 
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
@@ -28,12 +35,20 @@ func main() {
 	cfg := server.LoadConfig(*configPath)
 
 	if *demo {
+		*demoECU, *demoGPS = true, true
+	}
+	if *demoECU {
 		cfg.ECU.Type = "demo"
+	}
+	if *demoGPS {
 		cfg.GPS.Type = "demo"
 	}
 	if *listenAddr != "" {
 		cfg.Server.ListenAddr = *listenAddr
 	}
+	if *debug {
+		cfg.Server.Debug = true
+	}
 
 	// Create context with signal handling
 	ctx, cancel := context.WithCancel(context.Background())
@@ -48,31 +63,37 @@ func main() {
 	}()
 
 	// Initialize ECU provider with exponential backoff retry
-	var ecuProv ecu.Provider
-	switch cfg.ECU.Type {
-	case "speeduino":
-		ecuProv = ecu.NewSpeeduino(ecu.SpeeduinoConfig{
-			PortPath: cfg.ECU.PortPath,
-			BaudRate: cfg.ECU.BaudRate,
-			CanID:    byte(cfg.ECU.CanID),
-			Stoich:   cfg.ECU.Stoich,
-			Protocol: cfg.ECU.Protocol,
-		})
-	default:
-		ecuProv = ecu.NewDemoProvider()
-	}
+	ecuProv := newECUProvider(cfg.ECU)
+
+	portWait := time.Duration(cfg.Startup.PortWaitSec) * time.Second
 
 	// Try connecting with exponential backoff (non-blocking — dashboard starts regardless)
-	go connectWithRetry(ctx, "ECU", ecuProv, 10)
+	go func() {
+		if cfg.ECU.Type == "speeduino" {
+			waitForPort(ctx, "ECU", cfg.ECU.PortPath, portWait)
+		}
+		connectWithRetry(ctx, "ECU", ecuProv, 10)
+	}()
 
 	// Initialize GPS provider
 	var gpsProv gps.Provider
 	switch cfg.GPS.Type {
 	case "nmea":
 		gpsProv = gps.NewNMEA(gps.NMEAConfig{
-			PortPath: cfg.GPS.PortPath,
-			BaudRate: cfg.GPS.BaudRate,
+			PortPath:  cfg.GPS.PortPath,
+			BaudRate:  cfg.GPS.BaudRate,
+			Configure: cfg.GPS.ConfigureReceiver,
+			RateHz:    cfg.GPS.RateHz,
 		})
+	case "ubx":
+		gpsProv = gps.NewUBX(gps.UBXConfig{
+			PortPath:  cfg.GPS.PortPath,
+			BaudRate:  cfg.GPS.BaudRate,
+			RateHz:    cfg.GPS.RateHz,
+			Configure: cfg.GPS.ConfigureReceiver,
+		})
+	case "udp":
+		gpsProv = gps.NewNet(gps.NetConfig{Listen: cfg.GPS.Listen})
 	case "disabled":
 		gpsProv = nil
 	default:
@@ -80,11 +101,80 @@ func main() {
 	}
 
 	if gpsProv != nil {
-		go connectWithRetry(ctx, "GPS", gpsProv, 10)
+		go func() {
+			if cfg.GPS.Type == "nmea" || cfg.GPS.Type == "ubx" {
+				waitForPort(ctx, "GPS", cfg.GPS.PortPath, portWait)
+			}
+			connectWithRetry(ctx, "GPS", gpsProv, 10)
+		}()
 	}
 
 	// Start server — works immediately even if ECU/GPS are still connecting
 	srv := server.New(cfg, ecuProv, gpsProv, web.FS)
+
+	// Additional ECU providers (wideband, second Speeduino, ...)
+	seen := map[string]bool{}
+	for _, xc := range cfg.ExtraECUs {
+		if xc.Name == "" || seen[xc.Name] {
+			log.Printf("[main] skipping extra ECU with missing or duplicate name %q", xc.Name)
+			continue
+		}
+		seen[xc.Name] = true
+		if *demoECU {
+			xc.Type = "demo"
+		}
+		prov := newECUProvider(xc.ECUConfig)
+		srv.AddECU(xc.Name, prov, xc.PollHz)
+
+		name, ecuCfg := "ECU:"+xc.Name, xc.ECUConfig
+		go func() {
+			if ecuCfg.Type == "speeduino" {
+				waitForPort(ctx, name, ecuCfg.PortPath, portWait)
+			}
+			connectWithRetry(ctx, name, prov, 10)
+		}()
+	}
+	// Auxiliary sensors (standalone wideband, EGT, Pi-attached, ...)
+	for _, sc := range cfg.Sensors {
+		if sc.Name == "" || seen[sc.Name] {
+			log.Printf("[main] skipping sensor with missing or duplicate name %q", sc.Name)
+			continue
+		}
+		seen[sc.Name] = true
+		prov := newSensorProvider(sc)
+		if *demoECU {
+			prov = newDemoSensor(sc)
+		}
+		if prov == nil {
+			log.Printf("[main] unknown sensor type %q for %q", sc.Type, sc.Name)
+			continue
+		}
+		srv.AddSensor(sc, prov) // server connects and reconnects sensors itself
+	}
+
+	// Accelerometer/gyro
+	if cfg.IMU.Type != "" && cfg.IMU.Type != "disabled" {
+		if *demoGPS {
+			cfg.IMU.Type = "demo"
+		}
+		if prov, err := newIMUProvider(cfg.IMU); err != nil {
+			log.Printf("[main] %v", err)
+		} else {
+			srv.SetIMU(prov) // server connects and reconnects it itself
+		}
+	}
+
+	// CAN keypad
+	if cfg.Keypad.Type == "pkp" {
+		srv.SetKeypad(keypad.NewPKP(keypad.Config{
+			Interface: cfg.Keypad.Interface,
+			NodeID:    cfg.Keypad.NodeID,
+			Keys:      cfg.Keypad.Size,
+		})) // server connects and reconnects it itself
+	} else if t := cfg.Keypad.Type; t != "" && t != "disabled" {
+		log.Printf("[main] unknown keypad type %q", t)
+	}
+
 	if err := srv.Run(ctx); err != nil {
 		log.Printf("[main] server exited: %v", err)
 	}
@@ -92,10 +182,103 @@ func main() {
 
 // connectable is satisfied by both ecu.Provider and gps.Provider.
 type connectable interface {
-	Connect() error
+	Connect(ctx context.Context) error
 	Close() error
 }
 
+// newECUProvider builds the ECU provider described by c.
+func newECUProvider(c server.ECUConfig) ecu.Provider {
+	switch c.Type {
+	case "speeduino":
+		return ecu.NewSpeeduino(ecu.SpeeduinoConfig{
+			PortPath: c.PortPath,
+			BaudRate: c.BaudRate,
+			CanID:    byte(c.CanID),
+			Stoich:   c.Stoich,
+			Protocol: c.Protocol,
+			Aux:      c.Aux,
+		})
+	default:
+		d := ecu.NewDemoProvider()
+		d.SetAux(c.Aux)
+		return d
+	}
+}
+
+// newSensorProvider builds the auxiliary sensor provider described by c,
+// or returns nil for an unknown type.
+func newSensorProvider(c server.SensorConfig) sensors.Provider {
+	sc := sensors.Config{
+		PortPath: c.PortPath,
+		BaudRate: c.BaudRate,
+		Stoich:   c.Stoich,
+		Address:  c.Address,
+		VRef:     c.VRef,
+		Channels: c.Channels,
+
+		FrequencyMHz: c.FrequencyMHz,
+		Device:       c.Device,
+	}
+	switch c.Type {
+	case "innovate":
+		return sensors.NewInnovate(sc)
+	case "aem":
+		return sensors.NewAEM(sc)
+	case "max31855", "max31856":
+		return sensors.NewMAX318xx(c.Type, c.Ports, c.Thermocouple)
+	case "egt-serial":
+		return sensors.NewEGTSerial(sc)
+	case "bmp280":
+		return sensors.NewBMP280(sc)
+	case "ds18b20":
+		return sensors.NewDS18B20(sc)
+	case "mcp3008":
+		return sensors.NewMCP3008(sc)
+	case "tpms":
+		return sensors.NewTPMS(sc)
+	case "demo":
+		return sensors.NewDemo(sc)
+	default:
+		return nil
+	}
+}
+
+// newIMUProvider builds the IMU provider described by c.
+func newIMUProvider(c server.IMUConfig) (imu.Provider, error) {
+	if c.Type == "demo" {
+		return imu.NewDemo(), nil
+	}
+	return imu.NewI2C(imu.Config{
+		Chip:    c.Type,
+		Bus:     c.Bus,
+		Address: c.Address,
+		RateHz:  c.RateHz,
+		Forward: c.Forward,
+		Left:    c.Left,
+	})
+}
+
+// newDemoSensor returns a simulated stand-in matching the kind of sensor c describes.
+func newDemoSensor(c server.SensorConfig) sensors.Provider {
+	if c.IsEGT() {
+		return sensors.NewDemoEGT(len(c.Ports))
+	}
+	if c.IsAux() {
+		return sensors.NewDemoAux(c.Type, sensors.Config{Channels: c.Channels, VRef: c.VRef})
+	}
+	return sensors.NewDemo(sensors.Config{Stoich: c.Stoich})
+}
+
+// waitForPort blocks (bounded) until a configured device path or network
+// transport is available, so slow USB enumeration at boot doesn't burn
+// through the first connect attempts. On timeout it logs and returns —
+// connectWithRetry takes over from there.
+func waitForPort(ctx context.Context, name, path string, timeout time.Duration) {
+	if err := device.WaitReady(ctx, name, path, timeout); err != nil && ctx.Err() == nil {
+		log.Printf("[%s] %v — continuing with connect retries", name, err)
+	}
+}
+
 // connectWithRetry attempts to connect with exponential backoff.
 // Starts at 1s, doubles each attempt up to 60s, retries up to maxAttempts
 // then continues at max interval indefinitely.
@@ -111,7 +294,7 @@ func connectWithRetry(ctx context.Context, name string, c connectable, maxAttemp
 		default:
 		}
 
-		if err := c.Connect(); err != nil {
+		if err := c.Connect(ctx); err != nil {
 			attempt++
 			if attempt <= maxAttempts {
 				log.Printf("[%s] connect attempt %d/%d failed: %v (retry in %v)",
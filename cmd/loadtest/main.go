@@ -0,0 +1,236 @@
+// Command loadtest opens many WebSocket clients against a running
+// goefidash and reports the frame rates they achieve and the frames the
+// server dropped for them, to measure a change on Pi hardware before and
+// after:
+//
+//	go run ./cmd/loadtest -addr pi.local:8080 -n 20 -d 30s
+//
+// -slow makes every client take that long over each frame, to see how the
+// server steps slow clients down. Run it from another machine where you
+// can: on the Pi itself it competes with the server for CPU.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// frame is the part of a server frame the load test looks at.
+type frame struct {
+	Stamp int64           `json:"stamp"`
+	ECU   json.RawMessage `json:"ecu"`
+	GPS   json.RawMessage `json:"gps"`
+	Link  *struct {
+		Reduced bool    `json:"reduced"`
+		RateHz  float64 `json:"rateHz"`
+	} `json:"link"`
+}
+
+// clientStats is what one client saw.
+type clientStats struct {
+	id       int
+	err      error
+	frames   int // Every message
+	data     int // Frames with ECU or GPS data
+	bytes    int64
+	maxGap   time.Duration // Longest wait between data frames
+	ages     []float64     // ms from the frame's stamp to receipt
+	reduced  bool          // Stepped down by the server at the end
+	minRate  float64       // Lowest reduced rate the server set, Hz
+	duration time.Duration
+}
+
+// wsDiag is the WebSocket part of /api/diagnostics.
+type wsDiag struct {
+	WebSocket struct {
+		Clients        int    `json:"clients"`
+		ReducedClients int    `json:"reducedClients"`
+		FramesSent     uint64 `json:"framesSent"`
+		FramesDropped  uint64 `json:"framesDropped"`
+		LastFrameBytes int64  `json:"lastFrameBytes"`
+	} `json:"websocket"`
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "goefidash host:port")
+	n := flag.Int("n", 10, "WebSocket clients to open")
+	dur := flag.Duration("d", 30*time.Second, "How long to run")
+	ramp := flag.Duration("ramp", 0, "Spread the connections over this long")
+	slow := flag.Duration("slow", 0, "Time each client spends on each frame")
+	verbose := flag.Bool("v", false, "Show each client's figures")
+	flag.Parse()
+
+	wsURL := url.URL{Scheme: "ws", Host: *addr, Path: "/ws"}
+	diagURL := url.URL{Scheme: "http", Host: *addr, Path: "/api/diagnostics"}
+
+	before, err := diagnostics(diagURL.String())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loadtest: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("%d clients → %s for %v\n", *n, wsURL.String(), *dur)
+	deadline := time.Now().Add(*ramp + *dur)
+	results := make([]*clientStats, *n)
+	var wg sync.WaitGroup
+	for i := 0; i < *n; i++ {
+		if *ramp > 0 && i > 0 {
+			time.Sleep(*ramp / time.Duration(*n))
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = runClient(i, wsURL.String(), deadline, *slow)
+		}(i)
+	}
+
+	// Server side, while everyone is still connected
+	time.Sleep(time.Until(deadline) - time.Second)
+	during, err := diagnostics(diagURL.String())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loadtest: %v\n", err)
+	}
+	wg.Wait()
+
+	report(results, before, during, *verbose)
+}
+
+// runClient reads frames until deadline.
+func runClient(id int, wsURL string, deadline time.Time, slow time.Duration) *clientStats {
+	st := &clientStats{id: id}
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		st.err = err
+		return st
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(deadline)
+
+	start := time.Now()
+	var lastData time.Time
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if time.Now().Before(deadline) {
+				st.err = err
+			}
+			break
+		}
+		now := time.Now()
+		st.frames++
+		st.bytes += int64(len(msg))
+		var f frame
+		if json.Unmarshal(msg, &f) != nil {
+			continue
+		}
+		if f.Link != nil {
+			st.reduced = f.Link.Reduced
+			if f.Link.Reduced && (st.minRate == 0 || f.Link.RateHz < st.minRate) {
+				st.minRate = f.Link.RateHz
+			}
+		}
+		if f.ECU == nil && f.GPS == nil {
+			continue
+		}
+		st.data++
+		if !lastData.IsZero() && now.Sub(lastData) > st.maxGap {
+			st.maxGap = now.Sub(lastData)
+		}
+		lastData = now
+		if f.Stamp > 0 {
+			st.ages = append(st.ages, float64(now.UnixMilli()-f.Stamp))
+		}
+		if slow > 0 {
+			time.Sleep(slow)
+		}
+	}
+	st.duration = time.Since(start)
+	return st
+}
+
+// diagnostics fetches the server's WebSocket counters.
+func diagnostics(u string) (*wsDiag, error) {
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", u, resp.Status)
+	}
+	var d wsDiag
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return nil, fmt.Errorf("%s: %w", u, err)
+	}
+	return &d, nil
+}
+
+// report prints the results.
+func report(results []*clientStats, before, during *wsDiag, verbose bool) {
+	var rates, ages []float64
+	var frames, failed, reduced int
+	var bytes int64
+	var maxGap time.Duration
+	for _, st := range results {
+		if st.err != nil {
+			failed++
+			fmt.Printf("client %3d: %v\n", st.id, st.err)
+			if st.frames == 0 {
+				continue
+			}
+		}
+		rate := float64(st.data) / st.duration.Seconds()
+		rates = append(rates, rate)
+		ages = append(ages, st.ages...)
+		frames += st.frames
+		bytes += st.bytes
+		maxGap = max(maxGap, st.maxGap)
+		if st.reduced {
+			reduced++
+		}
+		if verbose {
+			line := fmt.Sprintf("client %3d: %6.1f Hz  %6d frames  max gap %v", st.id, rate, st.frames, st.maxGap.Round(time.Millisecond))
+			if st.minRate > 0 {
+				line += fmt.Sprintf("  stepped down to %g Hz", st.minRate)
+			}
+			fmt.Println(line)
+		}
+	}
+	if len(rates) == 0 {
+		fmt.Println("no client received any frames")
+		os.Exit(1)
+	}
+
+	sort.Float64s(rates)
+	sort.Float64s(ages)
+	fmt.Printf("clients:     %d received frames, %d errors, %d stepped down at the end\n", len(rates), failed, reduced)
+	fmt.Printf("data rate:   min %.1f  median %.1f  max %.1f Hz per client\n",
+		rates[0], percentile(rates, 50), rates[len(rates)-1])
+	fmt.Printf("frames:      %d, %.0f bytes average\n", frames, float64(bytes)/float64(max(frames, 1)))
+	if len(ages) > 0 {
+		fmt.Printf("frame age:   median %.0f  p99 %.0f  max %.0f ms (needs synced clocks)\n",
+			percentile(ages, 50), percentile(ages, 99), ages[len(ages)-1])
+	}
+	fmt.Printf("max gap:     %v\n", maxGap.Round(time.Millisecond))
+	if during != nil {
+		sent := during.WebSocket.FramesSent - before.WebSocket.FramesSent
+		dropped := during.WebSocket.FramesDropped - before.WebSocket.FramesDropped
+		fmt.Printf("server:      %d frames queued, %d dropped (%.2f%%), %d clients stepped down\n",
+			sent, dropped, 100*float64(dropped)/float64(max(sent, 1)), during.WebSocket.ReducedClients)
+	}
+}
+
+// percentile returns the p-th percentile of sorted v.
+func percentile(v []float64, p float64) float64 {
+	i := int(float64(len(v)-1) * p / 100)
+	return v[i]
+}
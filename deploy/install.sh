@@ -7,6 +7,7 @@ echo "=== Speeduino Dash Installer ==="
 INSTALL_DIR="/usr/local/bin"
 CONFIG_DIR="/etc/speeduino-dash"
 LOG_DIR="/var/log/speeduino-dash"
+DATA_DIR="/var/lib/speeduino-dash"
 SYSTEMD_DIR="/etc/systemd/system"
 UDEV_DIR="/etc/udev/rules.d"
 
@@ -82,6 +83,8 @@ sudo chown -R "$DASH_USER:$DASH_GROUP" "$CONFIG_DIR"
 echo "[5/7] Setting up logging..."
 sudo mkdir -p "$LOG_DIR"
 sudo chown "$DASH_USER:$DASH_GROUP" "$LOG_DIR"
+sudo mkdir -p "$DATA_DIR"
+sudo chown "$DASH_USER:$DASH_GROUP" "$DATA_DIR"
 
 # Install udev rules for serial port symlinks
 echo "[6/7] Installing udev rules..."
@@ -114,6 +117,16 @@ if [ -f "deploy/logrotate-speeduino-dash" ]; then
     echo "    Log rotation installed."
 fi
 
+# Install sudoers rule for the dash's system maintenance API
+if [ -f "deploy/sudoers-speeduino-dash" ]; then
+    sed "s/^pi /$DASH_USER /" deploy/sudoers-speeduino-dash > /tmp/speeduino-dash.sudoers
+    if sudo visudo -cf /tmp/speeduino-dash.sudoers > /dev/null; then
+        sudo install -m 0440 /tmp/speeduino-dash.sudoers /etc/sudoers.d/speeduino-dash
+        echo "    Sudoers rule installed (enable with system.enabled in config.yaml)."
+    fi
+    rm -f /tmp/speeduino-dash.sudoers
+fi
+
 echo ""
 echo "=== Installation complete ==="
 echo ""
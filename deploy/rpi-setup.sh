@@ -58,6 +58,7 @@ SCRIPT_DIR="$(cd "$(dirname "$0")" && pwd)"
 INSTALL_DIR="/usr/local/bin"
 CONFIG_DIR="/etc/speeduino-dash"
 LOG_DIR="/var/log/speeduino-dash"
+DATA_DIR="/var/lib/speeduino-dash"
 SYSTEMD_DIR="/etc/systemd/system"
 UDEV_DIR="/etc/udev/rules.d"
 UDEV_FILE="$UDEV_DIR/99-speeduino.rules"
@@ -496,6 +497,10 @@ mkdir -p "$LOG_DIR"
 chown "$DASH_USER:$DASH_GROUP" "$LOG_DIR"
 info "Log directory: $LOG_DIR"
 
+mkdir -p "$DATA_DIR"
+chown "$DASH_USER:$DASH_GROUP" "$DATA_DIR"
+info "Data directory: $DATA_DIR"
+
 if [[ -f "$SCRIPT_DIR/logrotate-speeduino-dash" ]]; then
     cp "$SCRIPT_DIR/logrotate-speeduino-dash" /etc/logrotate.d/goefidash
     info "Log rotation installed"
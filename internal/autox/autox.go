@@ -0,0 +1,385 @@
+// Package autox implements single timed runs — autocross and hill-climb:
+// armed at a standstill, started on launch, stopped at the finish line
+// (or when the car comes to rest), with cone penalties added afterwards.
+// Each run also records distance, vertical metres climbed and an
+// elevation-versus-distance profile.
+package autox
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// Run states.
+const (
+	StateIdle    = "idle"
+	StateArmed   = "armed"
+	StateRunning = "running"
+)
+
+// Run modes. They only label runs, so each kind is compared separately.
+const (
+	ModeAutocross = "autocross"
+	ModeHillClimb = "hillclimb"
+)
+
+// Run end reasons.
+const (
+	EndFinish  = "finish"  // Crossed the finish line
+	EndStopped = "stopped" // Came to rest before any finish line
+)
+
+const (
+	standstillKph = 2.0             // At or below = stationary
+	launchKph     = 3.0             // Above = run has started
+	stopHold      = 2 * time.Second // Stationary this long ends a run
+	minRunTime    = 5 * time.Second // Ignore finish crossings before this
+	defaultCone   = 2000 * time.Millisecond
+)
+
+// ErrMoving is returned by Arm when the car isn't at a standstill.
+var ErrMoving = errors.New("autox: car must be stationary to arm")
+
+// ErrNoRun is returned when a run ID doesn't exist.
+var ErrNoRun = errors.New("autox: no such run")
+
+// Run is one completed timed run.
+type Run struct {
+	ID        int    `json:"id"`
+	Mode      string `json:"mode"`      // ModeAutocross or ModeHillClimb
+	Start     int64  `json:"start"`     // Unix ms
+	RawMs     int64  `json:"rawMs"`     // Elapsed, launch to finish
+	Cones     int    `json:"cones"`     // Cones hit
+	PenaltyMs int64  `json:"penaltyMs"` // Cones × per-cone penalty
+	TotalMs   int64  `json:"totalMs"`   // RawMs + PenaltyMs
+	DNF       bool   `json:"dnf"`       // Did not finish (off course, etc.)
+	End       string `json:"end"`       // EndFinish or EndStopped
+
+	DistanceM float64        `json:"distanceM"`         // GPS distance covered
+	ClimbM    float64        `json:"climbM"`            // Vertical metres climbed
+	DescentM  float64        `json:"descentM"`          // Vertical metres descended
+	Profile   []ProfilePoint `json:"profile,omitempty"` // Elevation vs distance
+}
+
+// Sample is one broadcast tick's worth of vehicle state.
+type Sample struct {
+	SpeedKph float64
+	Pos      *track.Point // nil without a valid GPS fix
+	Heading  float64      // Degrees true
+	AltM     float64      // GPS altitude, metres
+}
+
+// Status is the live session state sent to clients.
+type Status struct {
+	State     string `json:"state"`
+	Mode      string `json:"mode,omitempty"`      // Mode of the armed/running run
+	ElapsedMs int64  `json:"elapsedMs,omitempty"` // Running time so far
+	Last      *Run   `json:"last,omitempty"`      // Most recent completed run
+	Best      *Run   `json:"best,omitempty"`      // Fastest clean run
+}
+
+// Compared is a run annotated for the run-list comparison.
+type Compared struct {
+	Run
+	Rank    int   `json:"rank"`    // 1 = fastest; 0 for DNF
+	DeltaMs int64 `json:"deltaMs"` // TotalMs minus the best total
+}
+
+// Session tracks the run state machine and the run list.
+// Update is called from the broadcast loop; everything else from
+// HTTP handlers.
+type Session struct {
+	mu    sync.Mutex
+	cone  time.Duration
+	state string
+	mode  string
+	runs  []Run
+	next  int
+
+	elev elevation // Current run's distance/climb/profile
+
+	start     time.Time // Launch time of the current run
+	lastSpeed float64
+	stillAt   time.Time // When the car last came to rest (zero if moving)
+
+	prevPos  track.Point
+	prevTime time.Time
+	havePrev bool
+}
+
+// New returns an idle session. conePenalty <= 0 uses the usual 2 s.
+func New(conePenalty time.Duration) *Session {
+	if conePenalty <= 0 {
+		conePenalty = defaultCone
+	}
+	return &Session{cone: conePenalty, state: StateIdle, next: 1}
+}
+
+// Restore replaces the run list (e.g. loaded from disk).
+func (s *Session) Restore(runs []Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = runs
+	s.next = 1
+	for i, r := range runs {
+		if r.Mode == "" {
+			s.runs[i].Mode = ModeAutocross // Saved before hill-climb mode
+		}
+		if r.ID >= s.next {
+			s.next = r.ID + 1
+		}
+	}
+}
+
+// Arm readies a run in the given mode (empty = autocross); the clock
+// starts when the car launches.
+func (s *Session) Arm(mode string) error {
+	switch mode {
+	case "":
+		mode = ModeAutocross
+	case ModeAutocross, ModeHillClimb:
+	default:
+		return errors.New("autox: unknown mode " + mode)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == StateRunning {
+		return errors.New("autox: run in progress")
+	}
+	if s.lastSpeed > standstillKph {
+		return ErrMoving
+	}
+	s.state = StateArmed
+	s.mode = mode
+	return nil
+}
+
+// Cancel disarms, or abandons a run in progress without recording it.
+func (s *Session) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = StateIdle
+}
+
+// Update advances the state machine. finish may be nil if no finish
+// line is set (runs then end at rest). It returns the completed run, if
+// this update finished one.
+func (s *Session) Update(now time.Time, smp Sample, finish *track.Line) *Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	speedKph, pos, heading := smp.SpeedKph, smp.Pos, smp.Heading
+
+	s.lastSpeed = speedKph
+	if speedKph <= standstillKph {
+		if s.stillAt.IsZero() {
+			s.stillAt = now
+		}
+	} else {
+		s.stillAt = time.Time{}
+	}
+
+	// Finish-line crossing, interpolated between fixes
+	crossedAt := time.Time{}
+	if pos != nil {
+		if s.havePrev && *pos != s.prevPos {
+			if finish != nil && s.state == StateRunning {
+				if ok, frac := finish.Crossing(s.prevPos, *pos, heading); ok {
+					crossedAt = s.prevTime.Add(time.Duration(frac * float64(now.Sub(s.prevTime))))
+				}
+			}
+		}
+		if !s.havePrev || *pos != s.prevPos {
+			s.prevPos, s.prevTime, s.havePrev = *pos, now, true
+			if s.state == StateRunning {
+				s.elev.add(*pos, smp.AltM)
+			}
+		}
+	}
+
+	switch s.state {
+	case StateArmed:
+		if speedKph > launchKph {
+			s.state = StateRunning
+			s.start = now
+			s.elev.reset()
+			if pos != nil {
+				s.elev.add(*pos, smp.AltM)
+			}
+		}
+
+	case StateRunning:
+		if !crossedAt.IsZero() && crossedAt.Sub(s.start) >= minRunTime {
+			return s.finishLocked(crossedAt, EndFinish)
+		}
+		if !s.stillAt.IsZero() && now.Sub(s.stillAt) >= stopHold {
+			return s.finishLocked(s.stillAt, EndStopped)
+		}
+	}
+	return nil
+}
+
+func (s *Session) finishLocked(end time.Time, reason string) *Run {
+	r := Run{
+		ID:    s.next,
+		Start: s.start.UnixMilli(),
+		Mode:  s.mode,
+		RawMs: end.Sub(s.start).Milliseconds(),
+		End:   reason,
+
+		DistanceM: math.Round(s.elev.distM),
+		ClimbM:    round1(s.elev.climbM),
+		DescentM:  round1(s.elev.descentM),
+		Profile:   s.elev.profile,
+	}
+	r.TotalMs = r.RawMs
+	s.next++
+	s.runs = append(s.runs, r)
+	s.state = StateIdle
+	s.elev.reset()
+	return &r
+}
+
+// SetPenalty sets a run's cone count and DNF flag. id 0 means the
+// most recent run.
+func (s *Session) SetPenalty(id, cones int, dnf bool) (*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexLocked(id)
+	if i < 0 {
+		return nil, ErrNoRun
+	}
+	if cones < 0 {
+		cones = 0
+	}
+	r := &s.runs[i]
+	r.Cones = cones
+	r.DNF = dnf
+	r.PenaltyMs = int64(cones) * s.cone.Milliseconds()
+	r.TotalMs = r.RawMs + r.PenaltyMs
+	out := *r
+	out.Profile = nil
+	return &out, nil
+}
+
+// Profile returns a run's elevation-versus-distance profile (id 0 =
+// latest).
+func (s *Session) Profile(id int) (*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexLocked(id)
+	if i < 0 {
+		return nil, ErrNoRun
+	}
+	r := s.runs[i]
+	return &r, nil
+}
+
+// Penalty returns a run's current cone count and DNF flag (id 0 = latest).
+func (s *Session) Penalty(id int) (cones int, dnf bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexLocked(id)
+	if i < 0 {
+		return 0, false, ErrNoRun
+	}
+	return s.runs[i].Cones, s.runs[i].DNF, nil
+}
+
+func (s *Session) indexLocked(id int) int {
+	if id == 0 {
+		return len(s.runs) - 1
+	}
+	for i := range s.runs {
+		if s.runs[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Runs returns a copy of the run list, oldest first, profiles included.
+func (s *Session) Runs() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Run(nil), s.runs...)
+}
+
+// Clear deletes all recorded runs.
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = nil
+	s.next = 1
+}
+
+// Status returns the live state.
+func (s *Session) Status(now time.Time) Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Status{State: s.state}
+	if s.state != StateIdle {
+		st.Mode = s.mode
+	}
+	if s.state == StateRunning {
+		st.ElapsedMs = now.Sub(s.start).Milliseconds()
+	}
+	if n := len(s.runs); n > 0 {
+		last := s.runs[n-1]
+		last.Profile = nil
+		st.Last = &last
+	}
+	for i := range s.runs {
+		r := s.runs[i]
+		// Best of the latest run's kind
+		if r.DNF || r.Mode != st.Last.Mode {
+			continue
+		}
+		r.Profile = nil
+		if st.Best == nil || r.TotalMs < st.Best.TotalMs {
+			st.Best = &r
+		}
+	}
+	return st
+}
+
+// Compare ranks runs by total time (penalties included). If mode is
+// set only runs of that mode are compared; if ids is set only those
+// runs. DNF runs are listed last, unranked. Profiles are omitted.
+func (s *Session) Compare(mode string, ids []int) []Compared {
+	want := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var runs []Run
+	for _, r := range s.Runs() {
+		if (mode != "" && r.Mode != mode) || (len(ids) > 0 && !want[r.ID]) {
+			continue
+		}
+		r.Profile = nil
+		runs = append(runs, r)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].DNF != runs[j].DNF {
+			return !runs[i].DNF
+		}
+		return runs[i].TotalMs < runs[j].TotalMs
+	})
+
+	out := make([]Compared, len(runs))
+	for i, r := range runs {
+		out[i] = Compared{Run: r}
+		if !r.DNF {
+			out[i].Rank = i + 1
+			out[i].DeltaMs = r.TotalMs - runs[0].TotalMs
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package autox
+
+import (
+	"math"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+const (
+	profileStepM   = 5.0 // Distance between profile samples
+	climbDeadbandM = 1.0 // Altitude change ignored as GPS noise
+	maxFixJumpM    = 100 // Larger position jumps between fixes are glitches
+)
+
+// ProfilePoint is one elevation-versus-distance sample.
+type ProfilePoint struct {
+	DistM float64 `json:"d"`   // Distance from launch, metres
+	AltM  float64 `json:"alt"` // GPS altitude, metres
+}
+
+// elevation accumulates distance, climb and an elevation profile over
+// a run. GPS altitude is noisy, so climb/descent only count once the
+// altitude has moved climbDeadbandM from the last reference point.
+type elevation struct {
+	distM    float64
+	climbM   float64
+	descentM float64
+	profile  []ProfilePoint
+
+	last    track.Point
+	refAlt  float64 // Deadband reference altitude
+	started bool
+}
+
+func (e *elevation) reset() { *e = elevation{} }
+
+// add records a new fix.
+func (e *elevation) add(p track.Point, alt float64) {
+	if !e.started {
+		e.last, e.refAlt, e.started = p, alt, true
+		e.profile = append(e.profile, ProfilePoint{0, round1(alt)})
+		return
+	}
+
+	d := track.DistanceM(e.last, p)
+	if d > maxFixJumpM {
+		e.last = p
+		return
+	}
+	e.distM += d
+	e.last = p
+
+	switch dh := alt - e.refAlt; {
+	case dh >= climbDeadbandM:
+		e.climbM += dh
+		e.refAlt = alt
+	case dh <= -climbDeadbandM:
+		e.descentM -= dh
+		e.refAlt = alt
+	}
+
+	if e.distM-e.profile[len(e.profile)-1].DistM >= profileStepM {
+		e.profile = append(e.profile, ProfilePoint{round1(e.distM), round1(alt)})
+	}
+}
+
+func round1(v float64) float64 { return math.Round(v*10) / 10 }
@@ -0,0 +1,193 @@
+// Package breadcrumb records a session's GPS path to a compact CSV file
+// and exports it as GPX or KML for Google Earth and analysis tools.
+package breadcrumb
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+const (
+	minSpacingM  = 3.0                    // Closer fixes are jitter at a standstill
+	minInterval  = 100 * time.Millisecond // At most 10 points a second
+	keepInterval = 30 * time.Second       // A point this often even when stopped
+)
+
+const header = "unix_ms,lat,lon,alt_m,speed_kph\n"
+
+// Point is one breadcrumb.
+type Point struct {
+	Time  int64   // Unix ms
+	Lat   float64 // Degrees
+	Lon   float64 // Degrees
+	Alt   float64 // Metres
+	Speed float64 // km/h
+}
+
+// Recorder appends breadcrumbs to a file, thinning them by distance.
+type Recorder struct {
+	mu   sync.Mutex
+	f    *os.File
+	last Point
+	n    int
+}
+
+// Create starts a new breadcrumb file at path.
+func Create(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("breadcrumb: %w", err)
+	}
+	if _, err := f.WriteString(header); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("breadcrumb: %w", err)
+	}
+	return &Recorder{f: f}, nil
+}
+
+// Add records p unless it's too close in time or space to the last point.
+func (r *Recorder) Add(p Point) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	if r.n > 0 {
+		dt := time.Duration(p.Time-r.last.Time) * time.Millisecond
+		moved := track.DistanceM(track.Point{Lat: r.last.Lat, Lon: r.last.Lon}, track.Point{Lat: p.Lat, Lon: p.Lon})
+		if dt < minInterval || (moved < minSpacingM && dt < keepInterval) {
+			return nil
+		}
+	}
+	line := fmt.Sprintf("%d,%.7f,%.7f,%.1f,%.1f\n", p.Time, p.Lat, p.Lon, p.Alt, p.Speed)
+	if _, err := r.f.WriteString(line); err != nil {
+		return fmt.Errorf("breadcrumb: %w", err)
+	}
+	r.last = p
+	r.n++
+	return nil
+}
+
+// Len returns the number of points recorded.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.n
+}
+
+// Close closes the file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// Read parses a breadcrumb file. Malformed lines — such as one cut short
+// by a power loss — are skipped.
+func Read(rd io.Reader) ([]Point, error) {
+	var pts []Point
+	sc := bufio.NewScanner(rd)
+	for sc.Scan() {
+		f := strings.Split(sc.Text(), ",")
+		if len(f) != 5 {
+			continue
+		}
+		var p Point
+		var err error
+		var v [4]float64
+		if p.Time, err = strconv.ParseInt(f[0], 10, 64); err != nil {
+			continue // Header or torn line
+		}
+		ok := true
+		for i := range v {
+			if v[i], err = strconv.ParseFloat(f[i+1], 64); err != nil {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		p.Lat, p.Lon, p.Alt, p.Speed = v[0], v[1], v[2], v[3]
+		pts = append(pts, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("breadcrumb: %w", err)
+	}
+	return pts, nil
+}
+
+// Between returns the points from start to end (Unix ms, inclusive).
+func Between(pts []Point, start, end int64) []Point {
+	var out []Point
+	for _, p := range pts {
+		if p.Time >= start && p.Time <= end {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func isoTime(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// WriteGPX writes pts as a GPX 1.1 track named name.
+func WriteGPX(w io.Writer, name string, pts []Point) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<gpx version="1.1" creator="speeduino-dash" xmlns="http://www.topografix.com/GPX/1/1">` + "\n")
+	bw.WriteString("  <metadata>\n")
+	fmt.Fprintf(bw, "    <name>%s</name>\n", escape(name))
+	if len(pts) > 0 {
+		fmt.Fprintf(bw, "    <time>%s</time>\n", isoTime(pts[0].Time))
+	}
+	bw.WriteString("  </metadata>\n")
+	fmt.Fprintf(bw, "  <trk>\n    <name>%s</name>\n    <trkseg>\n", escape(name))
+	for _, p := range pts {
+		fmt.Fprintf(bw, "      <trkpt lat=\"%.7f\" lon=\"%.7f\"><ele>%.1f</ele><time>%s</time></trkpt>\n",
+			p.Lat, p.Lon, p.Alt, isoTime(p.Time))
+	}
+	bw.WriteString("    </trkseg>\n  </trk>\n</gpx>\n")
+	return bw.Flush()
+}
+
+// WriteKML writes pts as a KML path named name.
+func WriteKML(w io.Writer, name string, pts []Point) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<kml xmlns="http://www.opengis.net/kml/2.2">` + "\n<Document>\n")
+	fmt.Fprintf(bw, "  <name>%s</name>\n", escape(name))
+	bw.WriteString("  <Style id=\"path\"><LineStyle><color>ff0000ff</color><width>3</width></LineStyle></Style>\n")
+	fmt.Fprintf(bw, "  <Placemark>\n    <name>%s</name>\n    <styleUrl>#path</styleUrl>\n", escape(name))
+	if len(pts) > 0 {
+		fmt.Fprintf(bw, "    <TimeSpan><begin>%s</begin><end>%s</end></TimeSpan>\n",
+			isoTime(pts[0].Time), isoTime(pts[len(pts)-1].Time))
+	}
+	bw.WriteString("    <LineString>\n      <tessellate>1</tessellate>\n      <coordinates>\n")
+	for _, p := range pts {
+		fmt.Fprintf(bw, "        %.7f,%.7f,%.1f\n", p.Lon, p.Lat, p.Alt)
+	}
+	bw.WriteString("      </coordinates>\n    </LineString>\n  </Placemark>\n</Document>\n</kml>\n")
+	return bw.Flush()
+}
@@ -0,0 +1,97 @@
+// Package canbus sends and receives classic CAN frames on a SocketCAN
+// interface, and packs values into frame data as DBC signals.
+package canbus
+
+import (
+	"fmt"
+	"math"
+)
+
+// Signal is a value packed into frame data, laid out as in a DBC file.
+type Signal struct {
+	Start     int  // Start bit, DBC numbering: the LSB, or the MSB with BigEndian
+	Length    int  // Bits, 1-64
+	BigEndian bool // Motorola byte order
+	Signed    bool
+	Factor    float64 // Physical value = raw × Factor + Offset; 0 means 1
+	Offset    float64
+}
+
+// Put packs physical value v into data, rounded and clamped to the
+// signal's range. It fails if the signal doesn't fit in data.
+func (s Signal) Put(data []byte, v float64) error {
+	if s.Length < 1 || s.Length > 64 {
+		return fmt.Errorf("canbus: signal length %d bits", s.Length)
+	}
+	bits, err := s.positions(len(data))
+	if err != nil {
+		return err
+	}
+	raw := s.raw(v)
+	for k, pos := range bits {
+		mask := byte(1) << (pos % 8)
+		if raw>>k&1 != 0 {
+			data[pos/8] |= mask
+		} else {
+			data[pos/8] &^= mask
+		}
+	}
+	return nil
+}
+
+// raw scales v to the signal's raw value, two's complement if Signed.
+func (s Signal) raw(v float64) uint64 {
+	factor := s.Factor
+	if factor == 0 {
+		factor = 1
+	}
+	r := math.Round((v - s.Offset) / factor)
+	if math.IsNaN(r) {
+		r = 0
+	}
+	n := uint(s.Length)
+	mask := uint64(math.MaxUint64) >> (64 - n)
+	if s.Signed {
+		half := math.Ldexp(1, int(n)-1)
+		switch {
+		case r >= half:
+			return mask >> 1
+		case r < -half:
+			r = -half
+		}
+		return uint64(int64(r)) & mask
+	}
+	switch {
+	case r <= 0:
+		return 0
+	case r >= math.Ldexp(1, int(n)):
+		return mask
+	}
+	return uint64(r)
+}
+
+// positions returns the data bit holding each raw bit, LSB first. Bit b
+// is bit b%8 (0 = least significant) of byte b/8.
+func (s Signal) positions(size int) ([]int, error) {
+	out := make([]int, s.Length)
+	pos := s.Start
+	for i := range out {
+		if pos < 0 || pos >= size*8 {
+			return nil, fmt.Errorf("canbus: signal at bit %d (%d bits) doesn't fit in %d bytes", s.Start, s.Length, size)
+		}
+		if !s.BigEndian {
+			out[i] = pos
+			pos++
+			continue
+		}
+		// Motorola: from the MSB down through each byte, then on to the
+		// next byte's top bit
+		out[s.Length-1-i] = pos
+		if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,94 @@
+//go:build linux
+
+package canbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Bus is a raw SocketCAN socket.
+type Bus struct {
+	f *os.File
+}
+
+// Open binds a raw CAN socket to the named interface, e.g. can0.
+func Open(name string) (*Bus, error) {
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.CAN_RAW)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrCAN{Ifindex: ifi.Index}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	// Non-blocking so the runtime poller handles it and read deadlines work
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &Bus{f: os.NewFile(uintptr(fd), name)}, nil
+}
+
+// Read returns the next standard data frame, skipping extended, remote
+// and error frames.
+func (b *Bus) Read(deadline time.Time) (uint32, []byte, error) {
+	b.f.SetReadDeadline(deadline)
+	var frame [unix.CAN_MTU]byte
+	for {
+		n, err := b.f.Read(frame[:])
+		if err != nil {
+			return 0, nil, err
+		}
+		if n < unix.CAN_MTU {
+			continue
+		}
+		id := binary.LittleEndian.Uint32(frame[0:4])
+		if id&(unix.CAN_EFF_FLAG|unix.CAN_RTR_FLAG|unix.CAN_ERR_FLAG) != 0 {
+			continue
+		}
+		dlc := min(int(frame[4]), 8)
+		return id & unix.CAN_SFF_MASK, append([]byte(nil), frame[8:8+dlc]...), nil
+	}
+}
+
+// Write sends a standard (11-bit ID) data frame.
+func (b *Bus) Write(id uint32, data []byte) error {
+	return b.write(id&unix.CAN_SFF_MASK, data)
+}
+
+// WriteExtended sends an extended (29-bit ID) data frame.
+func (b *Bus) WriteExtended(id uint32, data []byte) error {
+	return b.write(id&unix.CAN_EFF_MASK|unix.CAN_EFF_FLAG, data)
+}
+
+func (b *Bus) write(id uint32, data []byte) error {
+	if len(data) > 8 {
+		return fmt.Errorf("CAN frame too long (%d bytes)", len(data))
+	}
+	var frame [unix.CAN_MTU]byte
+	binary.LittleEndian.PutUint32(frame[0:4], id)
+	frame[4] = byte(len(data))
+	copy(frame[8:], data)
+	_, err := b.f.Write(frame[:])
+	return err
+}
+
+func (b *Bus) Close() error {
+	return b.f.Close()
+}
+
+// IsTimeout reports whether err is a Read passing its deadline.
+func IsTimeout(err error) bool {
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
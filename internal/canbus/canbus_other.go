@@ -0,0 +1,28 @@
+//go:build !linux
+
+package canbus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Bus is unavailable off Linux; buses fail to open.
+type Bus struct{}
+
+func Open(name string) (*Bus, error) {
+	return nil, fmt.Errorf("SocketCAN is only supported on Linux")
+}
+
+func (b *Bus) Read(deadline time.Time) (uint32, []byte, error) {
+	return 0, nil, errors.ErrUnsupported
+}
+
+func (b *Bus) Write(id uint32, data []byte) error { return errors.ErrUnsupported }
+
+func (b *Bus) WriteExtended(id uint32, data []byte) error { return errors.ErrUnsupported }
+
+func (b *Bus) Close() error { return nil }
+
+func IsTimeout(err error) bool { return false }
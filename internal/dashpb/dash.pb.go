@@ -0,0 +1,1741 @@
+// The dash's typed API for external tools: the live data of the
+// WebSocket's frames as protobuf, over gRPC. Generate a client from this
+// file in any language.
+//
+// goefidash.v1 only ever gains fields and methods; anything that would
+// break an existing client goes in a new package version. Regenerate the
+// Go code with `make proto` after editing.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.3
+// source: dash.proto
+
+package dashpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetSnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ECU and aux channels to include; empty = all.
+	Channels []string `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *GetSnapshotRequest) Reset() {
+	*x = GetSnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dash_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotRequest) ProtoMessage() {}
+
+func (x *GetSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dash_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*GetSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_dash_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetSnapshotRequest) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Frames per second; 0 = every frame, at the dash's ECU poll rate.
+	RateHz float64 `protobuf:"fixed64,1,opt,name=rate_hz,json=rateHz,proto3" json:"rate_hz,omitempty"`
+	// ECU and aux channels to include; empty = all.
+	Channels []string `protobuf:"bytes,2,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dash_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dash_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_dash_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubscribeRequest) GetRateHz() float64 {
+	if x != nil {
+		return x.RateHz
+	}
+	return 0
+}
+
+func (x *SubscribeRequest) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+// Frame is one tick of the dash's data loop.
+type Frame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stamp        int64      `protobuf:"varint,1,opt,name=stamp,proto3" json:"stamp,omitempty"` // Unix ms
+	Ecu          *DataFrame `protobuf:"bytes,2,opt,name=ecu,proto3" json:"ecu,omitempty"`      // Unset while the ECU data is stale
+	EcuConnected *bool      `protobuf:"varint,3,opt,name=ecu_connected,json=ecuConnected,proto3,oneof" json:"ecu_connected,omitempty"`
+	Gps          *GPS       `protobuf:"bytes,4,opt,name=gps,proto3" json:"gps,omitempty"` // Unset while the GPS data is stale
+	Speed        *Speed     `protobuf:"bytes,5,opt,name=speed,proto3" json:"speed,omitempty"`
+	Imu          *IMU       `protobuf:"bytes,6,opt,name=imu,proto3" json:"imu,omitempty"`
+	Egt          []float64  `protobuf:"fixed64,7,rep,packed,name=egt,proto3" json:"egt,omitempty"` // Per-cylinder EGT °C, egt[0] = cylinder 1
+	// Additional ECU providers, by their configured name
+	Ecus          map[string]*DataFrame `protobuf:"bytes,8,rep,name=ecus,proto3" json:"ecus,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	EcusConnected map[string]bool       `protobuf:"bytes,9,rep,name=ecus_connected,json=ecusConnected,proto3" json:"ecus_connected,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// Set on heartbeat frames while both ECU and GPS are stale
+	NoData   bool  `protobuf:"varint,10,opt,name=no_data,json=noData,proto3" json:"no_data,omitempty"`
+	LastData int64 `protobuf:"varint,11,opt,name=last_data,json=lastData,proto3" json:"last_data,omitempty"` // Unix ms of the last ECU or GPS data
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dash_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_dash_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_dash_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Frame) GetStamp() int64 {
+	if x != nil {
+		return x.Stamp
+	}
+	return 0
+}
+
+func (x *Frame) GetEcu() *DataFrame {
+	if x != nil {
+		return x.Ecu
+	}
+	return nil
+}
+
+func (x *Frame) GetEcuConnected() bool {
+	if x != nil && x.EcuConnected != nil {
+		return *x.EcuConnected
+	}
+	return false
+}
+
+func (x *Frame) GetGps() *GPS {
+	if x != nil {
+		return x.Gps
+	}
+	return nil
+}
+
+func (x *Frame) GetSpeed() *Speed {
+	if x != nil {
+		return x.Speed
+	}
+	return nil
+}
+
+func (x *Frame) GetImu() *IMU {
+	if x != nil {
+		return x.Imu
+	}
+	return nil
+}
+
+func (x *Frame) GetEgt() []float64 {
+	if x != nil {
+		return x.Egt
+	}
+	return nil
+}
+
+func (x *Frame) GetEcus() map[string]*DataFrame {
+	if x != nil {
+		return x.Ecus
+	}
+	return nil
+}
+
+func (x *Frame) GetEcusConnected() map[string]bool {
+	if x != nil {
+		return x.EcusConnected
+	}
+	return nil
+}
+
+func (x *Frame) GetNoData() bool {
+	if x != nil {
+		return x.NoData
+	}
+	return false
+}
+
+func (x *Frame) GetLastData() int64 {
+	if x != nil {
+		return x.LastData
+	}
+	return 0
+}
+
+// DataFrame is an ECU's channels, named and in the units of the WebSocket
+// frames' ecu object (docs/WEBSOCKET.md). Every channel is optional: only
+// channels the ECU actually sends are set, so an unset field means "no
+// value", never zero.
+//
+// Fields 1-15 encode in one byte and hold the channels nearly every ECU
+// sends. The rest are in blocks by kind, each with numbers left free so a
+// new channel can join its group; take the next free number in the block.
+type DataFrame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Core engine and the gauges every dash shows (1-15, full)
+	Rpm            *uint32  `protobuf:"varint,1,opt,name=rpm,proto3,oneof" json:"rpm,omitempty"`
+	Map            *uint32  `protobuf:"varint,2,opt,name=map,proto3,oneof" json:"map,omitempty"`  // kPa
+	Tps            *float64 `protobuf:"fixed64,3,opt,name=tps,proto3,oneof" json:"tps,omitempty"` // %
+	Afr            *float64 `protobuf:"fixed64,4,opt,name=afr,proto3,oneof" json:"afr,omitempty"`
+	Coolant        *float64 `protobuf:"fixed64,5,opt,name=coolant,proto3,oneof" json:"coolant,omitempty"`                                     // °C
+	Iat            *float64 `protobuf:"fixed64,6,opt,name=iat,proto3,oneof" json:"iat,omitempty"`                                             // °C
+	BatteryVoltage *float64 `protobuf:"fixed64,7,opt,name=battery_voltage,json=batteryVoltage,proto3,oneof" json:"battery_voltage,omitempty"` // V
+	Advance        *int32   `protobuf:"zigzag32,8,opt,name=advance,proto3,oneof" json:"advance,omitempty"`                                    // Degrees
+	Lambda         *float64 `protobuf:"fixed64,9,opt,name=lambda,proto3,oneof" json:"lambda,omitempty"`
+	Vss            *uint32  `protobuf:"varint,10,opt,name=vss,proto3,oneof" json:"vss,omitempty"` // km/h
+	Gear           *uint32  `protobuf:"varint,11,opt,name=gear,proto3,oneof" json:"gear,omitempty"`
+	OilPressure    *uint32  `protobuf:"varint,12,opt,name=oil_pressure,json=oilPressure,proto3,oneof" json:"oil_pressure,omitempty"`    // psi
+	FuelPressure   *uint32  `protobuf:"varint,13,opt,name=fuel_pressure,json=fuelPressure,proto3,oneof" json:"fuel_pressure,omitempty"` // psi
+	AfrTarget      *float64 `protobuf:"fixed64,14,opt,name=afr_target,json=afrTarget,proto3,oneof" json:"afr_target,omitempty"`
+	DutyCycle      *float64 `protobuf:"fixed64,15,opt,name=duty_cycle,json=dutyCycle,proto3,oneof" json:"duty_cycle,omitempty"` // Injector duty %
+	// Load and engine state (16-29)
+	Advance1 *int32   `protobuf:"zigzag32,16,opt,name=advance1,proto3,oneof" json:"advance1,omitempty"` // Advance table 1, degrees
+	Advance2 *int32   `protobuf:"zigzag32,17,opt,name=advance2,proto3,oneof" json:"advance2,omitempty"` // Advance table 2, degrees
+	FuelLoad *float64 `protobuf:"fixed64,18,opt,name=fuel_load,json=fuelLoad,proto3,oneof" json:"fuel_load,omitempty"`
+	IgnLoad  *float64 `protobuf:"fixed64,19,opt,name=ign_load,json=ignLoad,proto3,oneof" json:"ign_load,omitempty"`
+	MapDot   *int32   `protobuf:"zigzag32,20,opt,name=map_dot,json=mapDot,proto3,oneof" json:"map_dot,omitempty"` // kPa/s
+	RpmDot   *int32   `protobuf:"zigzag32,21,opt,name=rpm_dot,json=rpmDot,proto3,oneof" json:"rpm_dot,omitempty"` // rpm/s
+	Emap     *uint32  `protobuf:"varint,22,opt,name=emap,proto3,oneof" json:"emap,omitempty"`                     // Exhaust MAP, kPa
+	Baro     *uint32  `protobuf:"varint,23,opt,name=baro,proto3,oneof" json:"baro,omitempty"`                     // kPa
+	Afr2     *float64 `protobuf:"fixed64,24,opt,name=afr2,proto3,oneof" json:"afr2,omitempty"`                    // Second O2
+	// Fuel (30-49)
+	PulseWidth1 *float64 `protobuf:"fixed64,30,opt,name=pulse_width1,json=pulseWidth1,proto3,oneof" json:"pulse_width1,omitempty"`  // ms
+	PulseWidth2 *float64 `protobuf:"fixed64,31,opt,name=pulse_width2,json=pulseWidth2,proto3,oneof" json:"pulse_width2,omitempty"`  // ms
+	PulseWidth3 *float64 `protobuf:"fixed64,32,opt,name=pulse_width3,json=pulseWidth3,proto3,oneof" json:"pulse_width3,omitempty"`  // ms
+	PulseWidth4 *float64 `protobuf:"fixed64,33,opt,name=pulse_width4,json=pulseWidth4,proto3,oneof" json:"pulse_width4,omitempty"`  // ms
+	Ve1         *uint32  `protobuf:"varint,34,opt,name=ve1,proto3,oneof" json:"ve1,omitempty"`                                      // %
+	Ve2         *uint32  `protobuf:"varint,35,opt,name=ve2,proto3,oneof" json:"ve2,omitempty"`                                      // %
+	VeCurr      *uint32  `protobuf:"varint,36,opt,name=ve_curr,json=veCurr,proto3,oneof" json:"ve_curr,omitempty"`                  // %
+	PwImbalance *float64 `protobuf:"fixed64,37,opt,name=pw_imbalance,json=pwImbalance,proto3,oneof" json:"pw_imbalance,omitempty"`  // Spread of pulse widths 1-4, % of their mean
+	FlexPct     *uint32  `protobuf:"varint,38,opt,name=flex_pct,json=flexPct,proto3,oneof" json:"flex_pct,omitempty"`               // Ethanol %
+	FlexFuelCor *uint32  `protobuf:"varint,39,opt,name=flex_fuel_cor,json=flexFuelCor,proto3,oneof" json:"flex_fuel_cor,omitempty"` // %
+	FlexIgnCor  *int32   `protobuf:"zigzag32,40,opt,name=flex_ign_cor,json=flexIgnCor,proto3,oneof" json:"flex_ign_cor,omitempty"`  // Degrees
+	// Corrections, % (50-59)
+	GammaEnrich    *uint32 `protobuf:"varint,50,opt,name=gamma_enrich,json=gammaEnrich,proto3,oneof" json:"gamma_enrich,omitempty"`
+	EgoCorrection  *uint32 `protobuf:"varint,51,opt,name=ego_correction,json=egoCorrection,proto3,oneof" json:"ego_correction,omitempty"`
+	AirCorrection  *uint32 `protobuf:"varint,52,opt,name=air_correction,json=airCorrection,proto3,oneof" json:"air_correction,omitempty"`
+	WarmupEnrich   *uint32 `protobuf:"varint,53,opt,name=warmup_enrich,json=warmupEnrich,proto3,oneof" json:"warmup_enrich,omitempty"`
+	BatCorrection  *uint32 `protobuf:"varint,54,opt,name=bat_correction,json=batCorrection,proto3,oneof" json:"bat_correction,omitempty"`
+	AseCurr        *uint32 `protobuf:"varint,55,opt,name=ase_curr,json=aseCurr,proto3,oneof" json:"ase_curr,omitempty"`
+	BaroCorrection *uint32 `protobuf:"varint,56,opt,name=baro_correction,json=baroCorrection,proto3,oneof" json:"baro_correction,omitempty"`
+	AccelEnrich    *uint32 `protobuf:"varint,57,opt,name=accel_enrich,json=accelEnrich,proto3,oneof" json:"accel_enrich,omitempty"`
+	// Ignition (60-69)
+	Dwell       *float64 `protobuf:"fixed64,60,opt,name=dwell,proto3,oneof" json:"dwell,omitempty"`                                // ms
+	DwellActual *float64 `protobuf:"fixed64,61,opt,name=dwell_actual,json=dwellActual,proto3,oneof" json:"dwell_actual,omitempty"` // ms
+	KnockCount  *uint32  `protobuf:"varint,62,opt,name=knock_count,json=knockCount,proto3,oneof" json:"knock_count,omitempty"`
+	KnockCor    *uint32  `protobuf:"varint,63,opt,name=knock_cor,json=knockCor,proto3,oneof" json:"knock_cor,omitempty"` // Degrees
+	// Boost, idle and fan (70-79)
+	BoostTarget  *uint32  `protobuf:"varint,70,opt,name=boost_target,json=boostTarget,proto3,oneof" json:"boost_target,omitempty"` // kPa (×2)
+	BoostDuty    *uint32  `protobuf:"varint,71,opt,name=boost_duty,json=boostDuty,proto3,oneof" json:"boost_duty,omitempty"`       // %
+	IdleLoad     *uint32  `protobuf:"varint,72,opt,name=idle_load,json=idleLoad,proto3,oneof" json:"idle_load,omitempty"`
+	ClIdleTarget *uint32  `protobuf:"varint,73,opt,name=cl_idle_target,json=clIdleTarget,proto3,oneof" json:"cl_idle_target,omitempty"` // RPM (×10)
+	FanDuty      *float64 `protobuf:"fixed64,74,opt,name=fan_duty,json=fanDuty,proto3,oneof" json:"fan_duty,omitempty"`                 // %
+	// VVT, degrees and % (80-89)
+	Vvt1Angle  *float64 `protobuf:"fixed64,80,opt,name=vvt1_angle,json=vvt1Angle,proto3,oneof" json:"vvt1_angle,omitempty"`
+	Vvt1Target *float64 `protobuf:"fixed64,81,opt,name=vvt1_target,json=vvt1Target,proto3,oneof" json:"vvt1_target,omitempty"`
+	Vvt1Duty   *float64 `protobuf:"fixed64,82,opt,name=vvt1_duty,json=vvt1Duty,proto3,oneof" json:"vvt1_duty,omitempty"`
+	Vvt1Error  *float64 `protobuf:"fixed64,83,opt,name=vvt1_error,json=vvt1Error,proto3,oneof" json:"vvt1_error,omitempty"` // Target - angle, smoothed
+	Vvt2Angle  *float64 `protobuf:"fixed64,84,opt,name=vvt2_angle,json=vvt2Angle,proto3,oneof" json:"vvt2_angle,omitempty"`
+	Vvt2Target *float64 `protobuf:"fixed64,85,opt,name=vvt2_target,json=vvt2Target,proto3,oneof" json:"vvt2_target,omitempty"`
+	Vvt2Duty   *float64 `protobuf:"fixed64,86,opt,name=vvt2_duty,json=vvt2Duty,proto3,oneof" json:"vvt2_duty,omitempty"`
+	Vvt2Error  *float64 `protobuf:"fixed64,87,opt,name=vvt2_error,json=vvt2Error,proto3,oneof" json:"vvt2_error,omitempty"`
+	// Status bits (90-99)
+	Running   *bool `protobuf:"varint,90,opt,name=running,proto3,oneof" json:"running,omitempty"`
+	Cranking  *bool `protobuf:"varint,91,opt,name=cranking,proto3,oneof" json:"cranking,omitempty"`
+	Ase       *bool `protobuf:"varint,92,opt,name=ase,proto3,oneof" json:"ase,omitempty"` // Afterstart enrichment active
+	Warmup    *bool `protobuf:"varint,93,opt,name=warmup,proto3,oneof" json:"warmup,omitempty"`
+	DfcoOn    *bool `protobuf:"varint,94,opt,name=dfco_on,json=dfcoOn,proto3,oneof" json:"dfco_on,omitempty"` // Decel fuel cut
+	Sync      *bool `protobuf:"varint,95,opt,name=sync,proto3,oneof" json:"sync,omitempty"`                   // Trigger sync
+	FanStatus *bool `protobuf:"varint,96,opt,name=fan_status,json=fanStatus,proto3,oneof" json:"fan_status,omitempty"`
+	// ECU diagnostics (100-109)
+	Errors         *uint32 `protobuf:"varint,100,opt,name=errors,proto3,oneof" json:"errors,omitempty"`
+	SyncLoss       *uint32 `protobuf:"varint,101,opt,name=sync_loss,json=syncLoss,proto3,oneof" json:"sync_loss,omitempty"` // Sync loss counter
+	LoopsPerSecond *uint32 `protobuf:"varint,102,opt,name=loops_per_second,json=loopsPerSecond,proto3,oneof" json:"loops_per_second,omitempty"`
+	FreeRam        *uint32 `protobuf:"varint,103,opt,name=free_ram,json=freeRAM,proto3,oneof" json:"free_ram,omitempty"`
+	SdStatus       *uint32 `protobuf:"varint,104,opt,name=sd_status,json=sdStatus,proto3,oneof" json:"sd_status,omitempty"`
+	Secl           *uint32 `protobuf:"varint,105,opt,name=secl,proto3,oneof" json:"secl,omitempty"` // Seconds counter
+	// Aux/CAN inputs (110-119): the raw words, and the configured aux
+	// channels (from the ECU or Pi-attached sensors) by their configured
+	// name — the only channels whose names aren't fixed by this schema
+	AuxIn []uint32           `protobuf:"varint,110,rep,packed,name=aux_in,json=auxIn,proto3" json:"aux_in,omitempty"` // canin[0..15]
+	Aux   map[string]float64 `protobuf:"bytes,111,rep,name=aux,proto3" json:"aux,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+}
+
+func (x *DataFrame) Reset() {
+	*x = DataFrame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dash_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataFrame) ProtoMessage() {}
+
+func (x *DataFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_dash_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataFrame.ProtoReflect.Descriptor instead.
+func (*DataFrame) Descriptor() ([]byte, []int) {
+	return file_dash_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DataFrame) GetRpm() uint32 {
+	if x != nil && x.Rpm != nil {
+		return *x.Rpm
+	}
+	return 0
+}
+
+func (x *DataFrame) GetMap() uint32 {
+	if x != nil && x.Map != nil {
+		return *x.Map
+	}
+	return 0
+}
+
+func (x *DataFrame) GetTps() float64 {
+	if x != nil && x.Tps != nil {
+		return *x.Tps
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAfr() float64 {
+	if x != nil && x.Afr != nil {
+		return *x.Afr
+	}
+	return 0
+}
+
+func (x *DataFrame) GetCoolant() float64 {
+	if x != nil && x.Coolant != nil {
+		return *x.Coolant
+	}
+	return 0
+}
+
+func (x *DataFrame) GetIat() float64 {
+	if x != nil && x.Iat != nil {
+		return *x.Iat
+	}
+	return 0
+}
+
+func (x *DataFrame) GetBatteryVoltage() float64 {
+	if x != nil && x.BatteryVoltage != nil {
+		return *x.BatteryVoltage
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAdvance() int32 {
+	if x != nil && x.Advance != nil {
+		return *x.Advance
+	}
+	return 0
+}
+
+func (x *DataFrame) GetLambda() float64 {
+	if x != nil && x.Lambda != nil {
+		return *x.Lambda
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVss() uint32 {
+	if x != nil && x.Vss != nil {
+		return *x.Vss
+	}
+	return 0
+}
+
+func (x *DataFrame) GetGear() uint32 {
+	if x != nil && x.Gear != nil {
+		return *x.Gear
+	}
+	return 0
+}
+
+func (x *DataFrame) GetOilPressure() uint32 {
+	if x != nil && x.OilPressure != nil {
+		return *x.OilPressure
+	}
+	return 0
+}
+
+func (x *DataFrame) GetFuelPressure() uint32 {
+	if x != nil && x.FuelPressure != nil {
+		return *x.FuelPressure
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAfrTarget() float64 {
+	if x != nil && x.AfrTarget != nil {
+		return *x.AfrTarget
+	}
+	return 0
+}
+
+func (x *DataFrame) GetDutyCycle() float64 {
+	if x != nil && x.DutyCycle != nil {
+		return *x.DutyCycle
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAdvance1() int32 {
+	if x != nil && x.Advance1 != nil {
+		return *x.Advance1
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAdvance2() int32 {
+	if x != nil && x.Advance2 != nil {
+		return *x.Advance2
+	}
+	return 0
+}
+
+func (x *DataFrame) GetFuelLoad() float64 {
+	if x != nil && x.FuelLoad != nil {
+		return *x.FuelLoad
+	}
+	return 0
+}
+
+func (x *DataFrame) GetIgnLoad() float64 {
+	if x != nil && x.IgnLoad != nil {
+		return *x.IgnLoad
+	}
+	return 0
+}
+
+func (x *DataFrame) GetMapDot() int32 {
+	if x != nil && x.MapDot != nil {
+		return *x.MapDot
+	}
+	return 0
+}
+
+func (x *DataFrame) GetRpmDot() int32 {
+	if x != nil && x.RpmDot != nil {
+		return *x.RpmDot
+	}
+	return 0
+}
+
+func (x *DataFrame) GetEmap() uint32 {
+	if x != nil && x.Emap != nil {
+		return *x.Emap
+	}
+	return 0
+}
+
+func (x *DataFrame) GetBaro() uint32 {
+	if x != nil && x.Baro != nil {
+		return *x.Baro
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAfr2() float64 {
+	if x != nil && x.Afr2 != nil {
+		return *x.Afr2
+	}
+	return 0
+}
+
+func (x *DataFrame) GetPulseWidth1() float64 {
+	if x != nil && x.PulseWidth1 != nil {
+		return *x.PulseWidth1
+	}
+	return 0
+}
+
+func (x *DataFrame) GetPulseWidth2() float64 {
+	if x != nil && x.PulseWidth2 != nil {
+		return *x.PulseWidth2
+	}
+	return 0
+}
+
+func (x *DataFrame) GetPulseWidth3() float64 {
+	if x != nil && x.PulseWidth3 != nil {
+		return *x.PulseWidth3
+	}
+	return 0
+}
+
+func (x *DataFrame) GetPulseWidth4() float64 {
+	if x != nil && x.PulseWidth4 != nil {
+		return *x.PulseWidth4
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVe1() uint32 {
+	if x != nil && x.Ve1 != nil {
+		return *x.Ve1
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVe2() uint32 {
+	if x != nil && x.Ve2 != nil {
+		return *x.Ve2
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVeCurr() uint32 {
+	if x != nil && x.VeCurr != nil {
+		return *x.VeCurr
+	}
+	return 0
+}
+
+func (x *DataFrame) GetPwImbalance() float64 {
+	if x != nil && x.PwImbalance != nil {
+		return *x.PwImbalance
+	}
+	return 0
+}
+
+func (x *DataFrame) GetFlexPct() uint32 {
+	if x != nil && x.FlexPct != nil {
+		return *x.FlexPct
+	}
+	return 0
+}
+
+func (x *DataFrame) GetFlexFuelCor() uint32 {
+	if x != nil && x.FlexFuelCor != nil {
+		return *x.FlexFuelCor
+	}
+	return 0
+}
+
+func (x *DataFrame) GetFlexIgnCor() int32 {
+	if x != nil && x.FlexIgnCor != nil {
+		return *x.FlexIgnCor
+	}
+	return 0
+}
+
+func (x *DataFrame) GetGammaEnrich() uint32 {
+	if x != nil && x.GammaEnrich != nil {
+		return *x.GammaEnrich
+	}
+	return 0
+}
+
+func (x *DataFrame) GetEgoCorrection() uint32 {
+	if x != nil && x.EgoCorrection != nil {
+		return *x.EgoCorrection
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAirCorrection() uint32 {
+	if x != nil && x.AirCorrection != nil {
+		return *x.AirCorrection
+	}
+	return 0
+}
+
+func (x *DataFrame) GetWarmupEnrich() uint32 {
+	if x != nil && x.WarmupEnrich != nil {
+		return *x.WarmupEnrich
+	}
+	return 0
+}
+
+func (x *DataFrame) GetBatCorrection() uint32 {
+	if x != nil && x.BatCorrection != nil {
+		return *x.BatCorrection
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAseCurr() uint32 {
+	if x != nil && x.AseCurr != nil {
+		return *x.AseCurr
+	}
+	return 0
+}
+
+func (x *DataFrame) GetBaroCorrection() uint32 {
+	if x != nil && x.BaroCorrection != nil {
+		return *x.BaroCorrection
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAccelEnrich() uint32 {
+	if x != nil && x.AccelEnrich != nil {
+		return *x.AccelEnrich
+	}
+	return 0
+}
+
+func (x *DataFrame) GetDwell() float64 {
+	if x != nil && x.Dwell != nil {
+		return *x.Dwell
+	}
+	return 0
+}
+
+func (x *DataFrame) GetDwellActual() float64 {
+	if x != nil && x.DwellActual != nil {
+		return *x.DwellActual
+	}
+	return 0
+}
+
+func (x *DataFrame) GetKnockCount() uint32 {
+	if x != nil && x.KnockCount != nil {
+		return *x.KnockCount
+	}
+	return 0
+}
+
+func (x *DataFrame) GetKnockCor() uint32 {
+	if x != nil && x.KnockCor != nil {
+		return *x.KnockCor
+	}
+	return 0
+}
+
+func (x *DataFrame) GetBoostTarget() uint32 {
+	if x != nil && x.BoostTarget != nil {
+		return *x.BoostTarget
+	}
+	return 0
+}
+
+func (x *DataFrame) GetBoostDuty() uint32 {
+	if x != nil && x.BoostDuty != nil {
+		return *x.BoostDuty
+	}
+	return 0
+}
+
+func (x *DataFrame) GetIdleLoad() uint32 {
+	if x != nil && x.IdleLoad != nil {
+		return *x.IdleLoad
+	}
+	return 0
+}
+
+func (x *DataFrame) GetClIdleTarget() uint32 {
+	if x != nil && x.ClIdleTarget != nil {
+		return *x.ClIdleTarget
+	}
+	return 0
+}
+
+func (x *DataFrame) GetFanDuty() float64 {
+	if x != nil && x.FanDuty != nil {
+		return *x.FanDuty
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt1Angle() float64 {
+	if x != nil && x.Vvt1Angle != nil {
+		return *x.Vvt1Angle
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt1Target() float64 {
+	if x != nil && x.Vvt1Target != nil {
+		return *x.Vvt1Target
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt1Duty() float64 {
+	if x != nil && x.Vvt1Duty != nil {
+		return *x.Vvt1Duty
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt1Error() float64 {
+	if x != nil && x.Vvt1Error != nil {
+		return *x.Vvt1Error
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt2Angle() float64 {
+	if x != nil && x.Vvt2Angle != nil {
+		return *x.Vvt2Angle
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt2Target() float64 {
+	if x != nil && x.Vvt2Target != nil {
+		return *x.Vvt2Target
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt2Duty() float64 {
+	if x != nil && x.Vvt2Duty != nil {
+		return *x.Vvt2Duty
+	}
+	return 0
+}
+
+func (x *DataFrame) GetVvt2Error() float64 {
+	if x != nil && x.Vvt2Error != nil {
+		return *x.Vvt2Error
+	}
+	return 0
+}
+
+func (x *DataFrame) GetRunning() bool {
+	if x != nil && x.Running != nil {
+		return *x.Running
+	}
+	return false
+}
+
+func (x *DataFrame) GetCranking() bool {
+	if x != nil && x.Cranking != nil {
+		return *x.Cranking
+	}
+	return false
+}
+
+func (x *DataFrame) GetAse() bool {
+	if x != nil && x.Ase != nil {
+		return *x.Ase
+	}
+	return false
+}
+
+func (x *DataFrame) GetWarmup() bool {
+	if x != nil && x.Warmup != nil {
+		return *x.Warmup
+	}
+	return false
+}
+
+func (x *DataFrame) GetDfcoOn() bool {
+	if x != nil && x.DfcoOn != nil {
+		return *x.DfcoOn
+	}
+	return false
+}
+
+func (x *DataFrame) GetSync() bool {
+	if x != nil && x.Sync != nil {
+		return *x.Sync
+	}
+	return false
+}
+
+func (x *DataFrame) GetFanStatus() bool {
+	if x != nil && x.FanStatus != nil {
+		return *x.FanStatus
+	}
+	return false
+}
+
+func (x *DataFrame) GetErrors() uint32 {
+	if x != nil && x.Errors != nil {
+		return *x.Errors
+	}
+	return 0
+}
+
+func (x *DataFrame) GetSyncLoss() uint32 {
+	if x != nil && x.SyncLoss != nil {
+		return *x.SyncLoss
+	}
+	return 0
+}
+
+func (x *DataFrame) GetLoopsPerSecond() uint32 {
+	if x != nil && x.LoopsPerSecond != nil {
+		return *x.LoopsPerSecond
+	}
+	return 0
+}
+
+func (x *DataFrame) GetFreeRam() uint32 {
+	if x != nil && x.FreeRam != nil {
+		return *x.FreeRam
+	}
+	return 0
+}
+
+func (x *DataFrame) GetSdStatus() uint32 {
+	if x != nil && x.SdStatus != nil {
+		return *x.SdStatus
+	}
+	return 0
+}
+
+func (x *DataFrame) GetSecl() uint32 {
+	if x != nil && x.Secl != nil {
+		return *x.Secl
+	}
+	return 0
+}
+
+func (x *DataFrame) GetAuxIn() []uint32 {
+	if x != nil {
+		return x.AuxIn
+	}
+	return nil
+}
+
+func (x *DataFrame) GetAux() map[string]float64 {
+	if x != nil {
+		return x.Aux
+	}
+	return nil
+}
+
+type GPS struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid      bool    `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`          // Fix is valid
+	Latitude   float64 `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`   // Decimal degrees
+	Longitude  float64 `protobuf:"fixed64,3,opt,name=longitude,proto3" json:"longitude,omitempty"` // Decimal degrees
+	SpeedKph   float64 `protobuf:"fixed64,4,opt,name=speed_kph,json=speedKph,proto3" json:"speed_kph,omitempty"`
+	Heading    float64 `protobuf:"fixed64,5,opt,name=heading,proto3" json:"heading,omitempty"`                        // Degrees true
+	Altitude   float64 `protobuf:"fixed64,6,opt,name=altitude,proto3" json:"altitude,omitempty"`                      // Meters
+	Satellites int32   `protobuf:"varint,7,opt,name=satellites,proto3" json:"satellites,omitempty"`                   // In use
+	FixQuality int32   `protobuf:"varint,8,opt,name=fix_quality,json=fixQuality,proto3" json:"fix_quality,omitempty"` // 0 = none, 1 = GPS, 2 = DGPS
+	Hdop       float64 `protobuf:"fixed64,9,opt,name=hdop,proto3" json:"hdop,omitempty"`
+	Time       int64   `protobuf:"varint,10,opt,name=time,proto3" json:"time,omitempty"` // Fix time, Unix ms (0 = no date yet)
+	// Zero when the receiver doesn't report them
+	FixType    int32   `protobuf:"varint,11,opt,name=fix_type,json=fixType,proto3" json:"fix_type,omitempty"` // 2 = 2D, 3 = 3D, 4 = GNSS+DR
+	Pdop       float64 `protobuf:"fixed64,12,opt,name=pdop,proto3" json:"pdop,omitempty"`
+	Vdop       float64 `protobuf:"fixed64,13,opt,name=vdop,proto3" json:"vdop,omitempty"`
+	HAcc       float64 `protobuf:"fixed64,14,opt,name=h_acc,json=hAcc,proto3" json:"h_acc,omitempty"`                   // Horizontal accuracy, m
+	SpeedAcc   float64 `protobuf:"fixed64,15,opt,name=speed_acc,json=speedAcc,proto3" json:"speed_acc,omitempty"`       // km/h
+	HeadingAcc float64 `protobuf:"fixed64,16,opt,name=heading_acc,json=headingAcc,proto3" json:"heading_acc,omitempty"` // Degrees
+}
+
+func (x *GPS) Reset() {
+	*x = GPS{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dash_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GPS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPS) ProtoMessage() {}
+
+func (x *GPS) ProtoReflect() protoreflect.Message {
+	mi := &file_dash_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPS.ProtoReflect.Descriptor instead.
+func (*GPS) Descriptor() ([]byte, []int) {
+	return file_dash_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GPS) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *GPS) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GPS) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GPS) GetSpeedKph() float64 {
+	if x != nil {
+		return x.SpeedKph
+	}
+	return 0
+}
+
+func (x *GPS) GetHeading() float64 {
+	if x != nil {
+		return x.Heading
+	}
+	return 0
+}
+
+func (x *GPS) GetAltitude() float64 {
+	if x != nil {
+		return x.Altitude
+	}
+	return 0
+}
+
+func (x *GPS) GetSatellites() int32 {
+	if x != nil {
+		return x.Satellites
+	}
+	return 0
+}
+
+func (x *GPS) GetFixQuality() int32 {
+	if x != nil {
+		return x.FixQuality
+	}
+	return 0
+}
+
+func (x *GPS) GetHdop() float64 {
+	if x != nil {
+		return x.Hdop
+	}
+	return 0
+}
+
+func (x *GPS) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *GPS) GetFixType() int32 {
+	if x != nil {
+		return x.FixType
+	}
+	return 0
+}
+
+func (x *GPS) GetPdop() float64 {
+	if x != nil {
+		return x.Pdop
+	}
+	return 0
+}
+
+func (x *GPS) GetVdop() float64 {
+	if x != nil {
+		return x.Vdop
+	}
+	return 0
+}
+
+func (x *GPS) GetHAcc() float64 {
+	if x != nil {
+		return x.HAcc
+	}
+	return 0
+}
+
+func (x *GPS) GetSpeedAcc() float64 {
+	if x != nil {
+		return x.SpeedAcc
+	}
+	return 0
+}
+
+func (x *GPS) GetHeadingAcc() float64 {
+	if x != nil {
+		return x.HeadingAcc
+	}
+	return 0
+}
+
+type Speed struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`                          // km/h
+	Source    string  `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`                          // "gps", "vss", "fusion", "hold" or "none"
+	GpsWeight float64 `protobuf:"fixed64,3,opt,name=gps_weight,json=gpsWeight,proto3" json:"gps_weight,omitempty"` // Share of value from GPS: 0 = VSS only, 1 = GPS only
+}
+
+func (x *Speed) Reset() {
+	*x = Speed{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dash_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Speed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Speed) ProtoMessage() {}
+
+func (x *Speed) ProtoReflect() protoreflect.Message {
+	mi := &file_dash_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Speed.ProtoReflect.Descriptor instead.
+func (*Speed) Descriptor() ([]byte, []int) {
+	return file_dash_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Speed) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Speed) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Speed) GetGpsWeight() float64 {
+	if x != nil {
+		return x.GpsWeight
+	}
+	return 0
+}
+
+type IMU struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LatG    float64 `protobuf:"fixed64,1,opt,name=lat_g,json=latG,proto3" json:"lat_g,omitempty"`          // + = accelerating to the right
+	LongG   float64 `protobuf:"fixed64,2,opt,name=long_g,json=longG,proto3" json:"long_g,omitempty"`       // + = accelerating, - = braking
+	VertG   float64 `protobuf:"fixed64,3,opt,name=vert_g,json=vertG,proto3" json:"vert_g,omitempty"`       // + = up
+	YawRate float64 `protobuf:"fixed64,4,opt,name=yaw_rate,json=yawRate,proto3" json:"yaw_rate,omitempty"` // °/s, + = turning right
+	Roll    float64 `protobuf:"fixed64,5,opt,name=roll,proto3" json:"roll,omitempty"`                      // °, + = right side down
+	Pitch   float64 `protobuf:"fixed64,6,opt,name=pitch,proto3" json:"pitch,omitempty"`                    // °, + = nose up
+	Stamp   int64   `protobuf:"varint,7,opt,name=stamp,proto3" json:"stamp,omitempty"`                     // Unix ms
+}
+
+func (x *IMU) Reset() {
+	*x = IMU{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dash_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IMU) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IMU) ProtoMessage() {}
+
+func (x *IMU) ProtoReflect() protoreflect.Message {
+	mi := &file_dash_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IMU.ProtoReflect.Descriptor instead.
+func (*IMU) Descriptor() ([]byte, []int) {
+	return file_dash_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *IMU) GetLatG() float64 {
+	if x != nil {
+		return x.LatG
+	}
+	return 0
+}
+
+func (x *IMU) GetLongG() float64 {
+	if x != nil {
+		return x.LongG
+	}
+	return 0
+}
+
+func (x *IMU) GetVertG() float64 {
+	if x != nil {
+		return x.VertG
+	}
+	return 0
+}
+
+func (x *IMU) GetYawRate() float64 {
+	if x != nil {
+		return x.YawRate
+	}
+	return 0
+}
+
+func (x *IMU) GetRoll() float64 {
+	if x != nil {
+		return x.Roll
+	}
+	return 0
+}
+
+func (x *IMU) GetPitch() float64 {
+	if x != nil {
+		return x.Pitch
+	}
+	return 0
+}
+
+func (x *IMU) GetStamp() int64 {
+	if x != nil {
+		return x.Stamp
+	}
+	return 0
+}
+
+var File_dash_proto protoreflect.FileDescriptor
+
+var file_dash_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x64, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x67, 0x6f,
+	0x65, 0x66, 0x69, 0x64, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x22, 0x30, 0x0a, 0x12, 0x47, 0x65,
+	0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x47, 0x0a, 0x10,
+	0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x68, 0x7a, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x06, 0x72, 0x61, 0x74, 0x65, 0x48, 0x7a, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0xd7, 0x04, 0x0a, 0x05, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x29, 0x0a, 0x03, 0x65, 0x63, 0x75, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64, 0x61, 0x73, 0x68, 0x2e, 0x76,
+	0x31, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x52, 0x03, 0x65, 0x63, 0x75,
+	0x12, 0x28, 0x0a, 0x0d, 0x65, 0x63, 0x75, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0c, 0x65, 0x63, 0x75, 0x43, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x03, 0x67, 0x70,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64,
+	0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x50, 0x53, 0x52, 0x03, 0x67, 0x70, 0x73, 0x12,
+	0x29, 0x0a, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x70,
+	0x65, 0x65, 0x64, 0x52, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x03, 0x69, 0x6d,
+	0x75, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64,
+	0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x4d, 0x55, 0x52, 0x03, 0x69, 0x6d, 0x75, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x67, 0x74, 0x18, 0x07, 0x20, 0x03, 0x28, 0x01, 0x52, 0x03, 0x65, 0x67,
+	0x74, 0x12, 0x31, 0x0a, 0x04, 0x65, 0x63, 0x75, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46,
+	0x72, 0x61, 0x6d, 0x65, 0x2e, 0x45, 0x63, 0x75, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04,
+	0x65, 0x63, 0x75, 0x73, 0x12, 0x4d, 0x0a, 0x0e, 0x65, 0x63, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x67,
+	0x6f, 0x65, 0x66, 0x69, 0x64, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d,
+	0x65, 0x2e, 0x45, 0x63, 0x75, 0x73, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x65, 0x63, 0x75, 0x73, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6e, 0x6f, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1b, 0x0a, 0x09,
+	0x6c, 0x61, 0x73, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x6c, 0x61, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x50, 0x0a, 0x09, 0x45, 0x63, 0x75,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2d, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64,
+	0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x46, 0x72, 0x61, 0x6d, 0x65,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x40, 0x0a, 0x12, 0x45,
+	0x63, 0x75, 0x73, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x10, 0x0a,
+	0x0e, 0x5f, 0x65, 0x63, 0x75, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x22,
+	0x94, 0x1c, 0x0a, 0x09, 0x44, 0x61, 0x74, 0x61, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x15, 0x0a,
+	0x03, 0x72, 0x70, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x03, 0x72, 0x70,
+	0x6d, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x6d, 0x61, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x48, 0x01, 0x52, 0x03, 0x6d, 0x61, 0x70, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x74,
+	0x70, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x03, 0x74, 0x70, 0x73, 0x88,
+	0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x61, 0x66, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x03, 0x52, 0x03, 0x61, 0x66, 0x72, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x63, 0x6f, 0x6f,
+	0x6c, 0x61, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x04, 0x52, 0x07, 0x63, 0x6f,
+	0x6f, 0x6c, 0x61, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x69, 0x61, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x01, 0x48, 0x05, 0x52, 0x03, 0x69, 0x61, 0x74, 0x88, 0x01, 0x01, 0x12,
+	0x2c, 0x0a, 0x0f, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x5f, 0x76, 0x6f, 0x6c, 0x74, 0x61,
+	0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x48, 0x06, 0x52, 0x0e, 0x62, 0x61, 0x74, 0x74,
+	0x65, 0x72, 0x79, 0x56, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a,
+	0x07, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x11, 0x48, 0x07,
+	0x52, 0x07, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06,
+	0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x48, 0x08, 0x52, 0x06,
+	0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x76, 0x73, 0x73,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x09, 0x52, 0x03, 0x76, 0x73, 0x73, 0x88, 0x01, 0x01,
+	0x12, 0x17, 0x0a, 0x04, 0x67, 0x65, 0x61, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x0a,
+	0x52, 0x04, 0x67, 0x65, 0x61, 0x72, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x6f, 0x69, 0x6c,
+	0x5f, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0d, 0x48,
+	0x0b, 0x52, 0x0b, 0x6f, 0x69, 0x6c, 0x50, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x28, 0x0a, 0x0d, 0x66, 0x75, 0x65, 0x6c, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75,
+	0x72, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x0c, 0x52, 0x0c, 0x66, 0x75, 0x65, 0x6c,
+	0x50, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x61,
+	0x66, 0x72, 0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x0d, 0x52, 0x09, 0x61, 0x66, 0x72, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x88, 0x01, 0x01, 0x12,
+	0x22, 0x0a, 0x0a, 0x64, 0x75, 0x74, 0x79, 0x5f, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x0e, 0x52, 0x09, 0x64, 0x75, 0x74, 0x79, 0x43, 0x79, 0x63, 0x6c, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x31, 0x18,
+	0x10, 0x20, 0x01, 0x28, 0x11, 0x48, 0x0f, 0x52, 0x08, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x31, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x32,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x11, 0x48, 0x10, 0x52, 0x08, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63,
+	0x65, 0x32, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09, 0x66, 0x75, 0x65, 0x6c, 0x5f, 0x6c, 0x6f,
+	0x61, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x01, 0x48, 0x11, 0x52, 0x08, 0x66, 0x75, 0x65, 0x6c,
+	0x4c, 0x6f, 0x61, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x69, 0x67, 0x6e, 0x5f, 0x6c,
+	0x6f, 0x61, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x01, 0x48, 0x12, 0x52, 0x07, 0x69, 0x67, 0x6e,
+	0x4c, 0x6f, 0x61, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x07, 0x6d, 0x61, 0x70, 0x5f, 0x64,
+	0x6f, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x11, 0x48, 0x13, 0x52, 0x06, 0x6d, 0x61, 0x70, 0x44,
+	0x6f, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x07, 0x72, 0x70, 0x6d, 0x5f, 0x64, 0x6f, 0x74,
+	0x18, 0x15, 0x20, 0x01, 0x28, 0x11, 0x48, 0x14, 0x52, 0x06, 0x72, 0x70, 0x6d, 0x44, 0x6f, 0x74,
+	0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x65, 0x6d, 0x61, 0x70, 0x18, 0x16, 0x20, 0x01, 0x28,
+	0x0d, 0x48, 0x15, 0x52, 0x04, 0x65, 0x6d, 0x61, 0x70, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04,
+	0x62, 0x61, 0x72, 0x6f, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x16, 0x52, 0x04, 0x62, 0x61,
+	0x72, 0x6f, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x61, 0x66, 0x72, 0x32, 0x18, 0x18, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x17, 0x52, 0x04, 0x61, 0x66, 0x72, 0x32, 0x88, 0x01, 0x01, 0x12, 0x26,
+	0x0a, 0x0c, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x31, 0x18, 0x1e,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x18, 0x52, 0x0b, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x57, 0x69, 0x64,
+	0x74, 0x68, 0x31, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x5f,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x32, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x01, 0x48, 0x19, 0x52, 0x0b,
+	0x70, 0x75, 0x6c, 0x73, 0x65, 0x57, 0x69, 0x64, 0x74, 0x68, 0x32, 0x88, 0x01, 0x01, 0x12, 0x26,
+	0x0a, 0x0c, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x33, 0x18, 0x20,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x1a, 0x52, 0x0b, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x57, 0x69, 0x64,
+	0x74, 0x68, 0x33, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x5f,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x34, 0x18, 0x21, 0x20, 0x01, 0x28, 0x01, 0x48, 0x1b, 0x52, 0x0b,
+	0x70, 0x75, 0x6c, 0x73, 0x65, 0x57, 0x69, 0x64, 0x74, 0x68, 0x34, 0x88, 0x01, 0x01, 0x12, 0x15,
+	0x0a, 0x03, 0x76, 0x65, 0x31, 0x18, 0x22, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x1c, 0x52, 0x03, 0x76,
+	0x65, 0x31, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x76, 0x65, 0x32, 0x18, 0x23, 0x20, 0x01,
+	0x28, 0x0d, 0x48, 0x1d, 0x52, 0x03, 0x76, 0x65, 0x32, 0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x07,
+	0x76, 0x65, 0x5f, 0x63, 0x75, 0x72, 0x72, 0x18, 0x24, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x1e, 0x52,
+	0x06, 0x76, 0x65, 0x43, 0x75, 0x72, 0x72, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x70, 0x77,
+	0x5f, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x25, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x1f, 0x52, 0x0b, 0x70, 0x77, 0x49, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x88,
+	0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x66, 0x6c, 0x65, 0x78, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x26,
+	0x20, 0x01, 0x28, 0x0d, 0x48, 0x20, 0x52, 0x07, 0x66, 0x6c, 0x65, 0x78, 0x50, 0x63, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x27, 0x0a, 0x0d, 0x66, 0x6c, 0x65, 0x78, 0x5f, 0x66, 0x75, 0x65, 0x6c, 0x5f,
+	0x63, 0x6f, 0x72, 0x18, 0x27, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x21, 0x52, 0x0b, 0x66, 0x6c, 0x65,
+	0x78, 0x46, 0x75, 0x65, 0x6c, 0x43, 0x6f, 0x72, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0c, 0x66,
+	0x6c, 0x65, 0x78, 0x5f, 0x69, 0x67, 0x6e, 0x5f, 0x63, 0x6f, 0x72, 0x18, 0x28, 0x20, 0x01, 0x28,
+	0x11, 0x48, 0x22, 0x52, 0x0a, 0x66, 0x6c, 0x65, 0x78, 0x49, 0x67, 0x6e, 0x43, 0x6f, 0x72, 0x88,
+	0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x67, 0x61, 0x6d, 0x6d, 0x61, 0x5f, 0x65, 0x6e, 0x72, 0x69,
+	0x63, 0x68, 0x18, 0x32, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x23, 0x52, 0x0b, 0x67, 0x61, 0x6d, 0x6d,
+	0x61, 0x45, 0x6e, 0x72, 0x69, 0x63, 0x68, 0x88, 0x01, 0x01, 0x12, 0x2a, 0x0a, 0x0e, 0x65, 0x67,
+	0x6f, 0x5f, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x33, 0x20, 0x01,
+	0x28, 0x0d, 0x48, 0x24, 0x52, 0x0d, 0x65, 0x67, 0x6f, 0x43, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x2a, 0x0a, 0x0e, 0x61, 0x69, 0x72, 0x5f, 0x63, 0x6f,
+	0x72, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x34, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x25,
+	0x52, 0x0d, 0x61, 0x69, 0x72, 0x43, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x88,
+	0x01, 0x01, 0x12, 0x28, 0x0a, 0x0d, 0x77, 0x61, 0x72, 0x6d, 0x75, 0x70, 0x5f, 0x65, 0x6e, 0x72,
+	0x69, 0x63, 0x68, 0x18, 0x35, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x26, 0x52, 0x0c, 0x77, 0x61, 0x72,
+	0x6d, 0x75, 0x70, 0x45, 0x6e, 0x72, 0x69, 0x63, 0x68, 0x88, 0x01, 0x01, 0x12, 0x2a, 0x0a, 0x0e,
+	0x62, 0x61, 0x74, 0x5f, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x36,
+	0x20, 0x01, 0x28, 0x0d, 0x48, 0x27, 0x52, 0x0d, 0x62, 0x61, 0x74, 0x43, 0x6f, 0x72, 0x72, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x61, 0x73, 0x65, 0x5f,
+	0x63, 0x75, 0x72, 0x72, 0x18, 0x37, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x28, 0x52, 0x07, 0x61, 0x73,
+	0x65, 0x43, 0x75, 0x72, 0x72, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x0f, 0x62, 0x61, 0x72, 0x6f,
+	0x5f, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x38, 0x20, 0x01, 0x28,
+	0x0d, 0x48, 0x29, 0x52, 0x0e, 0x62, 0x61, 0x72, 0x6f, 0x43, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x6c, 0x5f,
+	0x65, 0x6e, 0x72, 0x69, 0x63, 0x68, 0x18, 0x39, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x2a, 0x52, 0x0b,
+	0x61, 0x63, 0x63, 0x65, 0x6c, 0x45, 0x6e, 0x72, 0x69, 0x63, 0x68, 0x88, 0x01, 0x01, 0x12, 0x19,
+	0x0a, 0x05, 0x64, 0x77, 0x65, 0x6c, 0x6c, 0x18, 0x3c, 0x20, 0x01, 0x28, 0x01, 0x48, 0x2b, 0x52,
+	0x05, 0x64, 0x77, 0x65, 0x6c, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x64, 0x77, 0x65,
+	0x6c, 0x6c, 0x5f, 0x61, 0x63, 0x74, 0x75, 0x61, 0x6c, 0x18, 0x3d, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x2c, 0x52, 0x0b, 0x64, 0x77, 0x65, 0x6c, 0x6c, 0x41, 0x63, 0x74, 0x75, 0x61, 0x6c, 0x88, 0x01,
+	0x01, 0x12, 0x24, 0x0a, 0x0b, 0x6b, 0x6e, 0x6f, 0x63, 0x6b, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x3e, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x2d, 0x52, 0x0a, 0x6b, 0x6e, 0x6f, 0x63, 0x6b, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09, 0x6b, 0x6e, 0x6f, 0x63, 0x6b,
+	0x5f, 0x63, 0x6f, 0x72, 0x18, 0x3f, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x2e, 0x52, 0x08, 0x6b, 0x6e,
+	0x6f, 0x63, 0x6b, 0x43, 0x6f, 0x72, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x62, 0x6f, 0x6f,
+	0x73, 0x74, 0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x46, 0x20, 0x01, 0x28, 0x0d, 0x48,
+	0x2f, 0x52, 0x0b, 0x62, 0x6f, 0x6f, 0x73, 0x74, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x88, 0x01,
+	0x01, 0x12, 0x22, 0x0a, 0x0a, 0x62, 0x6f, 0x6f, 0x73, 0x74, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x18,
+	0x47, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x30, 0x52, 0x09, 0x62, 0x6f, 0x6f, 0x73, 0x74, 0x44, 0x75,
+	0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x6c, 0x6f,
+	0x61, 0x64, 0x18, 0x48, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x31, 0x52, 0x08, 0x69, 0x64, 0x6c, 0x65,
+	0x4c, 0x6f, 0x61, 0x64, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0e, 0x63, 0x6c, 0x5f, 0x69, 0x64,
+	0x6c, 0x65, 0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x49, 0x20, 0x01, 0x28, 0x0d, 0x48,
+	0x32, 0x52, 0x0c, 0x63, 0x6c, 0x49, 0x64, 0x6c, 0x65, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x66, 0x61, 0x6e, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x18, 0x4a,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x33, 0x52, 0x07, 0x66, 0x61, 0x6e, 0x44, 0x75, 0x74, 0x79, 0x88,
+	0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x76, 0x76, 0x74, 0x31, 0x5f, 0x61, 0x6e, 0x67, 0x6c, 0x65,
+	0x18, 0x50, 0x20, 0x01, 0x28, 0x01, 0x48, 0x34, 0x52, 0x09, 0x76, 0x76, 0x74, 0x31, 0x41, 0x6e,
+	0x67, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0b, 0x76, 0x76, 0x74, 0x31, 0x5f, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x51, 0x20, 0x01, 0x28, 0x01, 0x48, 0x35, 0x52, 0x0a, 0x76,
+	0x76, 0x74, 0x31, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09,
+	0x76, 0x76, 0x74, 0x31, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x18, 0x52, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x36, 0x52, 0x08, 0x76, 0x76, 0x74, 0x31, 0x44, 0x75, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x22,
+	0x0a, 0x0a, 0x76, 0x76, 0x74, 0x31, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x53, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x37, 0x52, 0x09, 0x76, 0x76, 0x74, 0x31, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x88,
+	0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x76, 0x76, 0x74, 0x32, 0x5f, 0x61, 0x6e, 0x67, 0x6c, 0x65,
+	0x18, 0x54, 0x20, 0x01, 0x28, 0x01, 0x48, 0x38, 0x52, 0x09, 0x76, 0x76, 0x74, 0x32, 0x41, 0x6e,
+	0x67, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0b, 0x76, 0x76, 0x74, 0x32, 0x5f, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x55, 0x20, 0x01, 0x28, 0x01, 0x48, 0x39, 0x52, 0x0a, 0x76,
+	0x76, 0x74, 0x32, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09,
+	0x76, 0x76, 0x74, 0x32, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x18, 0x56, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x3a, 0x52, 0x08, 0x76, 0x76, 0x74, 0x32, 0x44, 0x75, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x22,
+	0x0a, 0x0a, 0x76, 0x76, 0x74, 0x32, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x57, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x3b, 0x52, 0x09, 0x76, 0x76, 0x74, 0x32, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x88,
+	0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x5a, 0x20,
+	0x01, 0x28, 0x08, 0x48, 0x3c, 0x52, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x88, 0x01,
+	0x01, 0x12, 0x1f, 0x0a, 0x08, 0x63, 0x72, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x18, 0x5b, 0x20,
+	0x01, 0x28, 0x08, 0x48, 0x3d, 0x52, 0x08, 0x63, 0x72, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x88,
+	0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x61, 0x73, 0x65, 0x18, 0x5c, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x3e, 0x52, 0x03, 0x61, 0x73, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x77, 0x61, 0x72,
+	0x6d, 0x75, 0x70, 0x18, 0x5d, 0x20, 0x01, 0x28, 0x08, 0x48, 0x3f, 0x52, 0x06, 0x77, 0x61, 0x72,
+	0x6d, 0x75, 0x70, 0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x07, 0x64, 0x66, 0x63, 0x6f, 0x5f, 0x6f,
+	0x6e, 0x18, 0x5e, 0x20, 0x01, 0x28, 0x08, 0x48, 0x40, 0x52, 0x06, 0x64, 0x66, 0x63, 0x6f, 0x4f,
+	0x6e, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x18, 0x5f, 0x20, 0x01,
+	0x28, 0x08, 0x48, 0x41, 0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a,
+	0x0a, 0x66, 0x61, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x60, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x42, 0x52, 0x09, 0x66, 0x61, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x88, 0x01,
+	0x01, 0x12, 0x1b, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x64, 0x20, 0x01, 0x28,
+	0x0d, 0x48, 0x43, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x88, 0x01, 0x01, 0x12, 0x20,
+	0x0a, 0x09, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x6c, 0x6f, 0x73, 0x73, 0x18, 0x65, 0x20, 0x01, 0x28,
+	0x0d, 0x48, 0x44, 0x52, 0x08, 0x73, 0x79, 0x6e, 0x63, 0x4c, 0x6f, 0x73, 0x73, 0x88, 0x01, 0x01,
+	0x12, 0x2d, 0x0a, 0x10, 0x6c, 0x6f, 0x6f, 0x70, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x18, 0x66, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x45, 0x52, 0x0e, 0x6c, 0x6f,
+	0x6f, 0x70, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x88, 0x01, 0x01, 0x12,
+	0x1e, 0x0a, 0x08, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x72, 0x61, 0x6d, 0x18, 0x67, 0x20, 0x01, 0x28,
+	0x0d, 0x48, 0x46, 0x52, 0x07, 0x66, 0x72, 0x65, 0x65, 0x52, 0x41, 0x4d, 0x88, 0x01, 0x01, 0x12,
+	0x20, 0x0a, 0x09, 0x73, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x68, 0x20, 0x01,
+	0x28, 0x0d, 0x48, 0x47, 0x52, 0x08, 0x73, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x88, 0x01,
+	0x01, 0x12, 0x17, 0x0a, 0x04, 0x73, 0x65, 0x63, 0x6c, 0x18, 0x69, 0x20, 0x01, 0x28, 0x0d, 0x48,
+	0x48, 0x52, 0x04, 0x73, 0x65, 0x63, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x06, 0x61, 0x75,
+	0x78, 0x5f, 0x69, 0x6e, 0x18, 0x6e, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x05, 0x61, 0x75, 0x78, 0x49,
+	0x6e, 0x12, 0x32, 0x0a, 0x03, 0x61, 0x75, 0x78, 0x18, 0x6f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61,
+	0x74, 0x61, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x2e, 0x41, 0x75, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x03, 0x61, 0x75, 0x78, 0x1a, 0x36, 0x0a, 0x08, 0x41, 0x75, 0x78, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x06, 0x0a,
+	0x04, 0x5f, 0x72, 0x70, 0x6d, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6d, 0x61, 0x70, 0x42, 0x06, 0x0a,
+	0x04, 0x5f, 0x74, 0x70, 0x73, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x61, 0x66, 0x72, 0x42, 0x0a, 0x0a,
+	0x08, 0x5f, 0x63, 0x6f, 0x6f, 0x6c, 0x61, 0x6e, 0x74, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x69, 0x61,
+	0x74, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x5f, 0x76, 0x6f,
+	0x6c, 0x74, 0x61, 0x67, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63,
+	0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x61, 0x6d, 0x62, 0x64, 0x61, 0x42, 0x06, 0x0a, 0x04,
+	0x5f, 0x76, 0x73, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x67, 0x65, 0x61, 0x72, 0x42, 0x0f, 0x0a,
+	0x0d, 0x5f, 0x6f, 0x69, 0x6c, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x42, 0x10,
+	0x0a, 0x0e, 0x5f, 0x66, 0x75, 0x65, 0x6c, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65,
+	0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x61, 0x66, 0x72, 0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x42,
+	0x0d, 0x0a, 0x0b, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x5f, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x42, 0x0b,
+	0x0a, 0x09, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x31, 0x42, 0x0b, 0x0a, 0x09, 0x5f,
+	0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x32, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x66, 0x75, 0x65,
+	0x6c, 0x5f, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x69, 0x67, 0x6e, 0x5f, 0x6c,
+	0x6f, 0x61, 0x64, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x64, 0x6f, 0x74, 0x42,
+	0x0a, 0x0a, 0x08, 0x5f, 0x72, 0x70, 0x6d, 0x5f, 0x64, 0x6f, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x5f,
+	0x65, 0x6d, 0x61, 0x70, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x62, 0x61, 0x72, 0x6f, 0x42, 0x07, 0x0a,
+	0x05, 0x5f, 0x61, 0x66, 0x72, 0x32, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x75, 0x6c, 0x73, 0x65,
+	0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x31, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x75, 0x6c, 0x73,
+	0x65, 0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x32, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x75, 0x6c,
+	0x73, 0x65, 0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x33, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x75,
+	0x6c, 0x73, 0x65, 0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x34, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x76,
+	0x65, 0x31, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x76, 0x65, 0x32, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x76,
+	0x65, 0x5f, 0x63, 0x75, 0x72, 0x72, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x77, 0x5f, 0x69, 0x6d,
+	0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x66, 0x6c, 0x65, 0x78,
+	0x5f, 0x70, 0x63, 0x74, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x66, 0x6c, 0x65, 0x78, 0x5f, 0x66, 0x75,
+	0x65, 0x6c, 0x5f, 0x63, 0x6f, 0x72, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x66, 0x6c, 0x65, 0x78, 0x5f,
+	0x69, 0x67, 0x6e, 0x5f, 0x63, 0x6f, 0x72, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x67, 0x61, 0x6d, 0x6d,
+	0x61, 0x5f, 0x65, 0x6e, 0x72, 0x69, 0x63, 0x68, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x65, 0x67, 0x6f,
+	0x5f, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x11, 0x0a, 0x0f, 0x5f,
+	0x61, 0x69, 0x72, 0x5f, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x10,
+	0x0a, 0x0e, 0x5f, 0x77, 0x61, 0x72, 0x6d, 0x75, 0x70, 0x5f, 0x65, 0x6e, 0x72, 0x69, 0x63, 0x68,
+	0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x62, 0x61, 0x74, 0x5f, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x61, 0x73, 0x65, 0x5f, 0x63, 0x75, 0x72, 0x72,
+	0x42, 0x12, 0x0a, 0x10, 0x5f, 0x62, 0x61, 0x72, 0x6f, 0x5f, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x61, 0x63, 0x63, 0x65, 0x6c, 0x5f, 0x65,
+	0x6e, 0x72, 0x69, 0x63, 0x68, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x64, 0x77, 0x65, 0x6c, 0x6c, 0x42,
+	0x0f, 0x0a, 0x0d, 0x5f, 0x64, 0x77, 0x65, 0x6c, 0x6c, 0x5f, 0x61, 0x63, 0x74, 0x75, 0x61, 0x6c,
+	0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6b, 0x6e, 0x6f, 0x63, 0x6b, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6b, 0x6e, 0x6f, 0x63, 0x6b, 0x5f, 0x63, 0x6f, 0x72, 0x42, 0x0f,
+	0x0a, 0x0d, 0x5f, 0x62, 0x6f, 0x6f, 0x73, 0x74, 0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x42,
+	0x0d, 0x0a, 0x0b, 0x5f, 0x62, 0x6f, 0x6f, 0x73, 0x74, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x42, 0x0c,
+	0x0a, 0x0a, 0x5f, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x11, 0x0a, 0x0f,
+	0x5f, 0x63, 0x6c, 0x5f, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x42,
+	0x0b, 0x0a, 0x09, 0x5f, 0x66, 0x61, 0x6e, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x42, 0x0d, 0x0a, 0x0b,
+	0x5f, 0x76, 0x76, 0x74, 0x31, 0x5f, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f,
+	0x76, 0x76, 0x74, 0x31, 0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x42, 0x0c, 0x0a, 0x0a, 0x5f,
+	0x76, 0x76, 0x74, 0x31, 0x5f, 0x64, 0x75, 0x74, 0x79, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x76, 0x76,
+	0x74, 0x31, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x76, 0x76, 0x74,
+	0x32, 0x5f, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x76, 0x76, 0x74, 0x32,
+	0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x76, 0x76, 0x74, 0x32,
+	0x5f, 0x64, 0x75, 0x74, 0x79, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x76, 0x76, 0x74, 0x32, 0x5f, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67,
+	0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x63, 0x72, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x42, 0x06, 0x0a,
+	0x04, 0x5f, 0x61, 0x73, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x77, 0x61, 0x72, 0x6d, 0x75, 0x70,
+	0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x64, 0x66, 0x63, 0x6f, 0x5f, 0x6f, 0x6e, 0x42, 0x07, 0x0a, 0x05,
+	0x5f, 0x73, 0x79, 0x6e, 0x63, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x66, 0x61, 0x6e, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x42,
+	0x0c, 0x0a, 0x0a, 0x5f, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x6c, 0x6f, 0x73, 0x73, 0x42, 0x13, 0x0a,
+	0x11, 0x5f, 0x6c, 0x6f, 0x6f, 0x70, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x72, 0x61, 0x6d, 0x42,
+	0x0c, 0x0a, 0x0a, 0x5f, 0x73, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x42, 0x07, 0x0a,
+	0x05, 0x5f, 0x73, 0x65, 0x63, 0x6c, 0x22, 0xa7, 0x03, 0x0a, 0x03, 0x47, 0x50, 0x53, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x6b, 0x70, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x73, 0x70, 0x65, 0x65, 0x64, 0x4b, 0x70, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x68,
+	0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x68, 0x65,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x61, 0x74, 0x65, 0x6c, 0x6c, 0x69, 0x74, 0x65, 0x73, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61, 0x74, 0x65, 0x6c, 0x6c, 0x69, 0x74, 0x65,
+	0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x78, 0x5f, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x66, 0x69, 0x78, 0x51, 0x75, 0x61, 0x6c, 0x69,
+	0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x64, 0x6f, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x04, 0x68, 0x64, 0x6f, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x69,
+	0x78, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x66, 0x69,
+	0x78, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x64, 0x6f, 0x70, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x04, 0x70, 0x64, 0x6f, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x64, 0x6f,
+	0x70, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x76, 0x64, 0x6f, 0x70, 0x12, 0x13, 0x0a,
+	0x05, 0x68, 0x5f, 0x61, 0x63, 0x63, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x68, 0x41,
+	0x63, 0x63, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x61, 0x63, 0x63, 0x18,
+	0x0f, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x73, 0x70, 0x65, 0x65, 0x64, 0x41, 0x63, 0x63, 0x12,
+	0x1f, 0x0a, 0x0b, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x63, 0x63, 0x18, 0x10,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x63,
+	0x22, 0x54, 0x0a, 0x05, 0x53, 0x70, 0x65, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x67, 0x70, 0x73, 0x5f, 0x77,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x67, 0x70, 0x73,
+	0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0xa3, 0x01, 0x0a, 0x03, 0x49, 0x4d, 0x55, 0x12, 0x13,
+	0x0a, 0x05, 0x6c, 0x61, 0x74, 0x5f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6c,
+	0x61, 0x74, 0x47, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x6e, 0x67, 0x5f, 0x67, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x05, 0x6c, 0x6f, 0x6e, 0x67, 0x47, 0x12, 0x15, 0x0a, 0x06, 0x76, 0x65,
+	0x72, 0x74, 0x5f, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x65, 0x72, 0x74,
+	0x47, 0x12, 0x19, 0x0a, 0x08, 0x79, 0x61, 0x77, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x07, 0x79, 0x61, 0x77, 0x52, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x72, 0x6f, 0x6c, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x6c,
+	0x12, 0x14, 0x0a, 0x05, 0x70, 0x69, 0x74, 0x63, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x05, 0x70, 0x69, 0x74, 0x63, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x32, 0x90, 0x01, 0x0a,
+	0x04, 0x44, 0x61, 0x73, 0x68, 0x12, 0x44, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x12, 0x20, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64, 0x61, 0x73, 0x68,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69, 0x64, 0x61,
+	0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x53,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69,
+	0x64, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x67, 0x6f, 0x65, 0x66, 0x69,
+	0x64, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x30, 0x01, 0x42,
+	0x3a, 0x5a, 0x38, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x68,
+	0x61, 0x75, 0x6e, 0x61, 0x67, 0x6f, 0x73, 0x74, 0x69, 0x6e, 0x68, 0x6f, 0x2f, 0x73, 0x70, 0x65,
+	0x65, 0x64, 0x75, 0x69, 0x6e, 0x6f, 0x2d, 0x64, 0x61, 0x73, 0x68, 0x2f, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x64, 0x61, 0x73, 0x68, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_dash_proto_rawDescOnce sync.Once
+	file_dash_proto_rawDescData = file_dash_proto_rawDesc
+)
+
+func file_dash_proto_rawDescGZIP() []byte {
+	file_dash_proto_rawDescOnce.Do(func() {
+		file_dash_proto_rawDescData = protoimpl.X.CompressGZIP(file_dash_proto_rawDescData)
+	})
+	return file_dash_proto_rawDescData
+}
+
+var file_dash_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_dash_proto_goTypes = []any{
+	(*GetSnapshotRequest)(nil), // 0: goefidash.v1.GetSnapshotRequest
+	(*SubscribeRequest)(nil),   // 1: goefidash.v1.SubscribeRequest
+	(*Frame)(nil),              // 2: goefidash.v1.Frame
+	(*DataFrame)(nil),          // 3: goefidash.v1.DataFrame
+	(*GPS)(nil),                // 4: goefidash.v1.GPS
+	(*Speed)(nil),              // 5: goefidash.v1.Speed
+	(*IMU)(nil),                // 6: goefidash.v1.IMU
+	nil,                        // 7: goefidash.v1.Frame.EcusEntry
+	nil,                        // 8: goefidash.v1.Frame.EcusConnectedEntry
+	nil,                        // 9: goefidash.v1.DataFrame.AuxEntry
+}
+var file_dash_proto_depIdxs = []int32{
+	3,  // 0: goefidash.v1.Frame.ecu:type_name -> goefidash.v1.DataFrame
+	4,  // 1: goefidash.v1.Frame.gps:type_name -> goefidash.v1.GPS
+	5,  // 2: goefidash.v1.Frame.speed:type_name -> goefidash.v1.Speed
+	6,  // 3: goefidash.v1.Frame.imu:type_name -> goefidash.v1.IMU
+	7,  // 4: goefidash.v1.Frame.ecus:type_name -> goefidash.v1.Frame.EcusEntry
+	8,  // 5: goefidash.v1.Frame.ecus_connected:type_name -> goefidash.v1.Frame.EcusConnectedEntry
+	9,  // 6: goefidash.v1.DataFrame.aux:type_name -> goefidash.v1.DataFrame.AuxEntry
+	3,  // 7: goefidash.v1.Frame.EcusEntry.value:type_name -> goefidash.v1.DataFrame
+	0,  // 8: goefidash.v1.Dash.GetSnapshot:input_type -> goefidash.v1.GetSnapshotRequest
+	1,  // 9: goefidash.v1.Dash.Subscribe:input_type -> goefidash.v1.SubscribeRequest
+	2,  // 10: goefidash.v1.Dash.GetSnapshot:output_type -> goefidash.v1.Frame
+	2,  // 11: goefidash.v1.Dash.Subscribe:output_type -> goefidash.v1.Frame
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_dash_proto_init() }
+func file_dash_proto_init() {
+	if File_dash_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_dash_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*GetSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dash_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*SubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dash_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Frame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dash_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*DataFrame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dash_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GPS); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dash_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Speed); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dash_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*IMU); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_dash_proto_msgTypes[2].OneofWrappers = []any{}
+	file_dash_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_dash_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dash_proto_goTypes,
+		DependencyIndexes: file_dash_proto_depIdxs,
+		MessageInfos:      file_dash_proto_msgTypes,
+	}.Build()
+	File_dash_proto = out.File
+	file_dash_proto_rawDesc = nil
+	file_dash_proto_goTypes = nil
+	file_dash_proto_depIdxs = nil
+}
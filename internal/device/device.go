@@ -0,0 +1,147 @@
+// Package device resolves configured serial/network transport paths and
+// waits for them to become available at boot.
+//
+// Port paths in config may be given as:
+//
+//   - a plain device path            — /dev/ttyUSB0, /dev/ttySpeeduino
+//   - a glob                         — /dev/serial/by-id/usb-FTDI_*
+//   - a by-id shorthand              — by-id:FTDI_FT232R (substring match
+//     against the entries of /dev/serial/by-id, like a udev rule would)
+//   - a network transport            — tcp://192.168.4.1:2000
+//   - a Bluetooth serial (RFCOMM)    — bt://AA:BB:CC:DD:EE:FF, or
+//     bt://AA:BB:CC:DD:EE:FF/2 for a channel other than 1
+package device
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ByIDDir is where udev publishes persistent serial device symlinks.
+const ByIDDir = "/dev/serial/by-id"
+
+const (
+	byIDPrefix   = "by-id:"
+	tcpPrefix    = "tcp://"
+	btPrefix     = "bt://"
+	pollInterval = 250 * time.Millisecond
+	dialTimeout  = 1 * time.Second
+)
+
+// IsNetwork reports whether path names a network transport rather than
+// a local device node.
+func IsNetwork(path string) bool {
+	return strings.HasPrefix(path, tcpPrefix)
+}
+
+// NetworkAddr returns the host:port portion of a network transport path.
+func NetworkAddr(path string) string {
+	return strings.TrimPrefix(path, tcpPrefix)
+}
+
+// Resolve maps a configured port path to a concrete device path.
+// Network and Bluetooth transports are returned unchanged.
+func Resolve(path string) (string, error) {
+	switch {
+	case path == "":
+		return "", fmt.Errorf("device: empty port path")
+	case IsRemote(path):
+		return path, nil
+	case strings.HasPrefix(path, byIDPrefix):
+		return resolveByID(strings.TrimPrefix(path, byIDPrefix))
+	case strings.ContainsAny(path, "*?["):
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return "", fmt.Errorf("device: bad pattern %q: %w", path, err)
+		}
+		if len(matches) == 0 {
+			return "", fmt.Errorf("device: no device matches %q", path)
+		}
+		sort.Strings(matches)
+		return matches[0], nil
+	default:
+		return path, nil
+	}
+}
+
+// resolveByID finds the first /dev/serial/by-id entry whose name contains id.
+func resolveByID(id string) (string, error) {
+	entries, err := os.ReadDir(ByIDDir)
+	if err != nil {
+		return "", fmt.Errorf("device: %s: %w", ByIDDir, err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), id) {
+			return filepath.Join(ByIDDir, e.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("device: no entry in %s matches %q", ByIDDir, id)
+}
+
+// Ready reports whether the configured path currently resolves to an
+// existing device node, or for network transports, accepts a connection.
+// Bluetooth transports are ready once the address parses and there's an
+// adapter.
+func Ready(path string) error {
+	resolved, err := Resolve(path)
+	if err != nil {
+		return err
+	}
+	if IsBluetooth(resolved) {
+		if _, _, err := parseBluetooth(resolved); err != nil {
+			return err
+		}
+		return bluetoothReady()
+	}
+	if IsNetwork(resolved) {
+		conn, err := net.DialTimeout("tcp", NetworkAddr(resolved), dialTimeout)
+		if err != nil {
+			return fmt.Errorf("device: %w", err)
+		}
+		conn.Close()
+		return nil
+	}
+	if _, err := os.Stat(resolved); err != nil {
+		return fmt.Errorf("device: %w", err)
+	}
+	return nil
+}
+
+// WaitReady blocks until path is Ready, timeout elapses, or ctx is cancelled.
+// It logs once when it starts waiting and once when the wait ends, instead
+// of the per-attempt failure spam a slow USB enumeration would otherwise cause.
+// A timeout <= 0 checks once without waiting.
+func WaitReady(ctx context.Context, name, path string, timeout time.Duration) error {
+	err := Ready(path)
+	if err == nil || timeout <= 0 {
+		return err
+	}
+
+	log.Printf("[%s] waiting up to %v for %s (%v)", name, timeout, path, err)
+	start := time.Now()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("device: %s not ready after %v: %w", path, timeout, err)
+		case <-ticker.C:
+			if err = Ready(path); err == nil {
+				log.Printf("[%s] %s ready after %v", name, path, time.Since(start).Round(time.Millisecond))
+				return nil
+			}
+		}
+	}
+}
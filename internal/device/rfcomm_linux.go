@@ -0,0 +1,29 @@
+//go:build linux
+
+package device
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dialRFCOMM connects an RFCOMM socket to addr (little-endian) on
+// channel. The device must be paired (bluetoothctl pair/trust) if it asks
+// for a PIN.
+func dialRFCOMM(addr [6]byte, channel uint8) (streamConn, error) {
+	fd, err := unix.Socket(unix.AF_BLUETOOTH, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, unix.BTPROTO_RFCOMM)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Connect(fd, &unix.SockaddrRFCOMM{Addr: addr, Channel: channel}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	// Non-blocking so the runtime poller handles it and read deadlines work
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "rfcomm"), nil
+}
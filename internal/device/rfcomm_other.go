@@ -0,0 +1,10 @@
+//go:build !linux
+
+package device
+
+import "fmt"
+
+// dialRFCOMM is unavailable off Linux; bt:// ports fail to open.
+func dialRFCOMM(addr [6]byte, channel uint8) (streamConn, error) {
+	return nil, fmt.Errorf("RFCOMM is only supported on Linux")
+}
@@ -0,0 +1,150 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// streamConn is a connected stream socket: TCP or RFCOMM.
+type streamConn interface {
+	io.ReadWriteCloser
+	SetReadDeadline(t time.Time) error
+}
+
+// Open opens a resolved port path: a local serial device, or a TCP or
+// Bluetooth RFCOMM serial bridge. mode only applies to local devices;
+// the bridge sets the line to the device itself.
+func Open(path string, mode *serial.Mode) (serial.Port, error) {
+	switch {
+	case IsNetwork(path):
+		conn, err := net.DialTimeout("tcp", NetworkAddr(path), dialTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("device: %w", err)
+		}
+		return &connPort{conn: conn, timeout: serial.NoTimeout}, nil
+	case IsBluetooth(path):
+		addr, channel, err := parseBluetooth(path)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := dialRFCOMM(addr, channel)
+		if err != nil {
+			return nil, fmt.Errorf("device: bluetooth %s channel %d: %w", formatBDAddr(addr), channel, err)
+		}
+		return &connPort{conn: conn, timeout: serial.NoTimeout}, nil
+	}
+	return serial.Open(path, mode)
+}
+
+// IsBluetooth reports whether path names a Bluetooth RFCOMM device.
+func IsBluetooth(path string) bool {
+	return strings.HasPrefix(path, btPrefix)
+}
+
+// IsRemote reports whether path is a serial bridge (TCP or Bluetooth)
+// rather than a local UART, so the far end's baud rate can't be changed
+// from here.
+func IsRemote(path string) bool {
+	return IsNetwork(path) || IsBluetooth(path)
+}
+
+// parseBluetooth splits bt://AA:BB:CC:DD:EE:FF[/channel] into the
+// address, in the little-endian byte order the kernel uses, and the
+// RFCOMM channel (default 1, where SPP devices almost always listen).
+func parseBluetooth(path string) (addr [6]byte, channel uint8, err error) {
+	s := strings.TrimPrefix(path, btPrefix)
+	channel = 1
+	if mac, ch, ok := strings.Cut(s, "/"); ok {
+		n, perr := strconv.Atoi(ch)
+		if perr != nil || n < 1 || n > 30 {
+			return addr, 0, fmt.Errorf("device: bad RFCOMM channel %q in %s (1-30)", ch, path)
+		}
+		s, channel = mac, uint8(n)
+	}
+	hw, perr := net.ParseMAC(s)
+	if perr != nil || len(hw) != 6 {
+		return addr, 0, fmt.Errorf("device: bad Bluetooth address %q in %s", s, path)
+	}
+	for i := range addr {
+		addr[i] = hw[5-i]
+	}
+	return addr, channel, nil
+}
+
+func formatBDAddr(addr [6]byte) string {
+	return net.HardwareAddr{addr[5], addr[4], addr[3], addr[2], addr[1], addr[0]}.String()
+}
+
+// bluetoothReady reports whether there's a Bluetooth adapter. The device
+// itself isn't probed: many accept only one connection, and connecting
+// takes seconds.
+func bluetoothReady() error {
+	adapters, _ := os.ReadDir("/sys/class/bluetooth")
+	if len(adapters) == 0 {
+		return fmt.Errorf("device: no Bluetooth adapter")
+	}
+	return nil
+}
+
+// connPort adapts a stream socket to serial.Port, so providers use a
+// network or Bluetooth serial bridge like a local UART. Line settings
+// and modem bits don't apply and are ignored.
+type connPort struct {
+	conn    streamConn
+	timeout time.Duration // serial.NoTimeout blocks
+}
+
+// Read returns 0, nil when the read timeout passes, as a serial port does.
+func (p *connPort) Read(b []byte) (int, error) {
+	var deadline time.Time
+	if p.timeout >= 0 {
+		deadline = time.Now().Add(p.timeout)
+	}
+	p.conn.SetReadDeadline(deadline)
+	n, err := p.conn.Read(b)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return n, nil
+	}
+	return n, err
+}
+
+func (p *connPort) Write(b []byte) (int, error) { return p.conn.Write(b) }
+func (p *connPort) Close() error                { return p.conn.Close() }
+
+func (p *connPort) SetReadTimeout(t time.Duration) error {
+	p.timeout = t
+	return nil
+}
+
+// ResetInputBuffer discards whatever has already arrived.
+func (p *connPort) ResetInputBuffer() error {
+	buf := make([]byte, 256)
+	for {
+		p.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+		n, err := p.conn.Read(buf)
+		if n == 0 || err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (p *connPort) SetMode(*serial.Mode) error { return nil }
+func (p *connPort) Drain() error               { return nil }
+func (p *connPort) ResetOutputBuffer() error   { return nil }
+func (p *connPort) SetDTR(bool) error          { return nil }
+func (p *connPort) SetRTS(bool) error          { return nil }
+func (p *connPort) Break(time.Duration) error  { return nil }
+func (p *connPort) GetModemStatusBits() (*serial.ModemStatusBits, error) {
+	return &serial.ModemStatusBits{}, nil
+}
@@ -0,0 +1,204 @@
+// Package diag collects per-provider serial link statistics — requests,
+// timeouts, CRC failures, reconnects, throughput and round-trip latency —
+// so serial trouble can be told apart from WebSocket trouble.
+package diag
+
+import (
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// rateWindow is the interval over which bytes/sec is measured.
+const rateWindow = time.Second
+
+// rttAlpha weights the newest sample in the round-trip moving average.
+const rttAlpha = 0.1
+
+// Reporter is implemented by providers that keep link statistics.
+type Reporter interface {
+	Diagnostics() Snapshot
+}
+
+// Snapshot is a point-in-time copy of a provider's counters.
+type Snapshot struct {
+	Requests       uint64  `json:"requests"`      // Polls sent / reads attempted
+	Responses      uint64  `json:"responses"`     // Good responses
+	Timeouts       uint64  `json:"timeouts"`      // No (complete) response in time
+	CRCErrors      uint64  `json:"crcErrors"`     // CRC / checksum failures
+	Errors         uint64  `json:"errors"`        // Other protocol or I/O errors
+	Connects       uint64  `json:"connects"`      // Successful connects
+	Reconnects     uint64  `json:"reconnects"`    // Connects after the first
+	ConnectFails   uint64  `json:"connectFails"`  // Failed connect attempts
+	BytesRx        uint64  `json:"bytesRx"`       // Total bytes read
+	BytesTx        uint64  `json:"bytesTx"`       // Total bytes written
+	RxBytesPerSec  float64 `json:"rxBytesPerSec"` // Over the last second
+	TxBytesPerSec  float64 `json:"txBytesPerSec"` // Over the last second
+	AvgRTTMs       float64 `json:"avgRttMs"`      // Moving average round trip
+	MaxRTTMs       float64 `json:"maxRttMs"`      // Worst round trip seen
+	LastError      string  `json:"lastError,omitempty"`
+	LastErrorAt    int64   `json:"lastErrorAt,omitempty"`    // Unix ms
+	LastResponseAt int64   `json:"lastResponseAt,omitempty"` // Unix ms
+}
+
+// Counters accumulates link statistics. The zero value is ready to use
+// and all methods are safe for concurrent use.
+type Counters struct {
+	mu sync.Mutex
+	s  Snapshot
+
+	// Throughput windows
+	winStart     time.Time
+	winRx, winTx uint64
+	rxRate       float64
+	txRate       float64
+}
+
+// Request counts a poll sent (or a read attempted, for streams).
+func (c *Counters) Request() {
+	c.mu.Lock()
+	c.s.Requests++
+	c.mu.Unlock()
+}
+
+// Response counts a good response that took rtt to arrive.
+func (c *Counters) Response(rtt time.Duration) {
+	ms := float64(rtt) / float64(time.Millisecond)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.s.Responses++
+	c.s.LastResponseAt = time.Now().UnixMilli()
+	if c.s.Responses == 1 {
+		c.s.AvgRTTMs = ms
+	} else {
+		c.s.AvgRTTMs += rttAlpha * (ms - c.s.AvgRTTMs)
+	}
+	if ms > c.s.MaxRTTMs {
+		c.s.MaxRTTMs = ms
+	}
+}
+
+// Timeout counts a request that got no complete response in time.
+func (c *Counters) Timeout(err error) {
+	c.mu.Lock()
+	c.s.Timeouts++
+	c.lastErrLocked(err)
+	c.mu.Unlock()
+}
+
+// CRCError counts a response that failed its CRC or checksum.
+func (c *Counters) CRCError(err error) {
+	c.mu.Lock()
+	c.s.CRCErrors++
+	c.lastErrLocked(err)
+	c.mu.Unlock()
+}
+
+// Error counts any other protocol or I/O error.
+func (c *Counters) Error(err error) {
+	c.mu.Lock()
+	c.s.Errors++
+	c.lastErrLocked(err)
+	c.mu.Unlock()
+}
+
+// Connect records the outcome of a connect attempt.
+func (c *Counters) Connect(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.s.ConnectFails++
+		c.lastErrLocked(err)
+		return
+	}
+	if c.s.Connects > 0 {
+		c.s.Reconnects++
+	}
+	c.s.Connects++
+}
+
+func (c *Counters) lastErrLocked(err error) {
+	if err == nil {
+		return
+	}
+	c.s.LastError = err.Error()
+	c.s.LastErrorAt = time.Now().UnixMilli()
+}
+
+// Rx counts n bytes read.
+func (c *Counters) Rx(n int) {
+	if n <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.s.BytesRx += uint64(n)
+	c.winRx += uint64(n)
+	c.rollLocked(time.Now())
+	c.mu.Unlock()
+}
+
+// Tx counts n bytes written.
+func (c *Counters) Tx(n int) {
+	if n <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.s.BytesTx += uint64(n)
+	c.winTx += uint64(n)
+	c.rollLocked(time.Now())
+	c.mu.Unlock()
+}
+
+// rollLocked closes the throughput window once it's rateWindow old.
+func (c *Counters) rollLocked(now time.Time) {
+	if c.winStart.IsZero() {
+		c.winStart = now
+		return
+	}
+	el := now.Sub(c.winStart)
+	if el < rateWindow {
+		return
+	}
+	c.rxRate = float64(c.winRx) / el.Seconds()
+	c.txRate = float64(c.winTx) / el.Seconds()
+	c.winRx, c.winTx = 0, 0
+	c.winStart = now
+}
+
+// Snapshot returns a copy of the counters.
+func (c *Counters) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := c.s
+	// A link that has gone quiet has no current throughput
+	if time.Since(c.winStart) < 2*rateWindow {
+		out.RxBytesPerSec = c.rxRate
+		out.TxBytesPerSec = c.txRate
+	}
+	return out
+}
+
+// Port wraps a serial port so that every byte read or written is
+// counted. It is itself a serial.Port.
+type Port struct {
+	serial.Port
+	c *Counters
+}
+
+// NewPort returns p with byte counting into c.
+func NewPort(p serial.Port, c *Counters) *Port {
+	return &Port{Port: p, c: c}
+}
+
+func (p *Port) Read(b []byte) (int, error) {
+	n, err := p.Port.Read(b)
+	p.c.Rx(n)
+	return n, err
+}
+
+func (p *Port) Write(b []byte) (int, error) {
+	n, err := p.Port.Write(b)
+	p.c.Tx(n)
+	return n, err
+}
@@ -0,0 +1,55 @@
+package ecu
+
+import "encoding/binary"
+
+// AuxInputs is the number of aux/CAN input words (canin[0..15]) in the
+// Speeduino realtime data set. Spare analog inputs configured as
+// "Local Aux In" in TunerStudio land here too.
+const AuxInputs = 16
+
+// Offsets of canin[0] in each realtime layout.
+const (
+	auxOffsetSecondary = 41 // 'A'/'n' data set
+	auxOffsetPrimary   = 42 // TunerStudio OCH block
+)
+
+// AuxChannel names and scales one aux input word for display:
+// value = raw × Scale + Offset. Scale 0 is treated as 1.
+type AuxChannel struct {
+	Name   string  `yaml:"name" json:"name"`     // e.g. "fuel_level"
+	Input  int     `yaml:"input" json:"input"`   // canin index, 0-15
+	Scale  float64 `yaml:"scale" json:"scale"`   // Multiplier
+	Offset float64 `yaml:"offset" json:"offset"` // Added after scaling
+	Unit   string  `yaml:"unit" json:"unit"`     // Display unit, e.g. "%", "°C"
+}
+
+// parseAuxInputs reads the 16 little-endian aux words starting at off.
+// Returns nil if the payload is too short (e.g. a truncated frame).
+func parseAuxInputs(d []byte, off int) []uint16 {
+	if len(d) < off+AuxInputs*2 {
+		return nil
+	}
+	in := make([]uint16, AuxInputs)
+	for i := range in {
+		in[i] = binary.LittleEndian.Uint16(d[off+i*2:])
+	}
+	return in
+}
+
+// applyAux fills f.Aux from f.AuxIn using the configured channels.
+func applyAux(f *DataFrame, chans []AuxChannel) {
+	if len(chans) == 0 || f.AuxIn == nil {
+		return
+	}
+	f.Aux = make(map[string]float64, len(chans))
+	for _, c := range chans {
+		if c.Input < 0 || c.Input >= len(f.AuxIn) || c.Name == "" {
+			continue
+		}
+		scale := c.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		f.Aux[c.Name] = float64(f.AuxIn[c.Input])*scale + c.Offset
+	}
+}
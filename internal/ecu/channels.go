@@ -0,0 +1,186 @@
+package ecu
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// ChannelMask is a set of DataFrame channels, one bit per JSON field in
+// declaration order. The zero mask means "unknown", which callers treat
+// as every channel.
+type ChannelMask [2]uint64
+
+// ChannelReporter is implemented by providers that know which channels a
+// raw response carries — e.g. Speeduino's 'A' data set has no VSS, gear
+// or VVT, and only the TunerStudio block has knock and EMAP. Providers
+// that don't implement it are assumed to populate everything.
+type ChannelReporter interface {
+	Channels(raw *RawData) ChannelMask
+}
+
+type frameField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// frameFields lists the encoded DataFrame fields; a channel's bit is its
+// position here.
+var frameFields, channelIndex = buildFrameFields()
+
+func buildFrameFields() ([]frameField, map[string]int) {
+	t := reflect.TypeOf(DataFrame{})
+	var fields []frameField
+	idx := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		idx[name] = len(fields)
+		fields = append(fields, frameField{name: name, index: i, omitEmpty: opts == "omitempty"})
+	}
+	if len(fields) > 128 {
+		panic("ecu: DataFrame has more channels than ChannelMask can hold")
+	}
+	return fields, idx
+}
+
+// AllChannels returns the mask of every DataFrame channel.
+func AllChannels() ChannelMask {
+	var m ChannelMask
+	for i := range frameFields {
+		m[i/64] |= 1 << (i % 64)
+	}
+	return m
+}
+
+// ChannelNames returns every DataFrame channel's JSON name, in mask order.
+func ChannelNames() []string {
+	names := make([]string, len(frameFields))
+	for i, ff := range frameFields {
+		names[i] = ff.name
+	}
+	return names
+}
+
+// MaskOf returns the mask of the named channels (DataFrame JSON names).
+// Unknown names are returned separately so config typos can be reported.
+func MaskOf(names ...string) (m ChannelMask, unknown []string) {
+	for _, n := range names {
+		i, ok := channelIndex[n]
+		if !ok {
+			unknown = append(unknown, n)
+			continue
+		}
+		m[i/64] |= 1 << (i % 64)
+	}
+	return m, unknown
+}
+
+func mustMask(names ...string) ChannelMask {
+	m, unknown := MaskOf(names...)
+	if len(unknown) > 0 {
+		panic("ecu: unknown channels " + strings.Join(unknown, ", "))
+	}
+	return m
+}
+
+// IsZero reports whether m is the zero ("unknown") mask.
+func (m ChannelMask) IsZero() bool { return m == ChannelMask{} }
+
+// Has reports whether the named channel is in m. Every channel is in the
+// zero mask.
+func (m ChannelMask) Has(name string) bool {
+	if m.IsZero() {
+		return true
+	}
+	i, ok := channelIndex[name]
+	return ok && m.bit(i)
+}
+
+// Union returns the channels in either mask.
+func (m ChannelMask) Union(o ChannelMask) ChannelMask {
+	return ChannelMask{m[0] | o[0], m[1] | o[1]}
+}
+
+// Without returns m minus the channels in o. A zero m is taken as every
+// channel.
+func (m ChannelMask) Without(o ChannelMask) ChannelMask {
+	if m.IsZero() {
+		m = AllChannels()
+	}
+	return ChannelMask{m[0] &^ o[0], m[1] &^ o[1]}
+}
+
+func (m ChannelMask) bit(i int) bool { return m[i/64]&(1<<(i%64)) != 0 }
+
+// Value returns the named channel as a number, by DataFrame JSON name or
+// configured aux channel name, with booleans as 0 and 1. It reports false
+// for unknown names, non-numeric channels and channels f doesn't carry.
+func (f *DataFrame) Value(name string) (float64, bool) {
+	if v, ok := f.Aux[name]; ok {
+		return v, true
+	}
+	i, ok := channelIndex[name]
+	if !ok || !f.Channels.Has(name) {
+		return 0, false
+	}
+	fv := reflect.ValueOf(f).Elem().Field(frameFields[i].index)
+	switch fv.Kind() {
+	case reflect.Bool:
+		if fv.Bool() {
+			return 1, true
+		}
+		return 0, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), true
+	}
+	return 0, false
+}
+
+// frameJSON has DataFrame's fields without its MarshalJSON.
+type frameJSON DataFrame
+
+// MarshalJSON encodes the frame, leaving out any channel not in
+// f.Channels when that is set.
+func (f *DataFrame) MarshalJSON() ([]byte, error) {
+	if f.Channels.IsZero() {
+		return json.Marshal((*frameJSON)(f))
+	}
+
+	v := reflect.ValueOf(f).Elem()
+	var b bytes.Buffer
+	b.WriteByte('{')
+	first := true
+	for i, ff := range frameFields {
+		if !f.Channels.bit(i) {
+			continue
+		}
+		fv := v.Field(ff.index)
+		if ff.omitEmpty && (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Map) && fv.Len() == 0 {
+			continue
+		}
+		val, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		b.WriteByte('"')
+		b.WriteString(ff.name)
+		b.WriteString(`":`)
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,152 @@
+package ecu
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Diagnostic commands. Only these names are accepted by Command; none of
+// them writes, burns or resets anything on the ECU.
+const (
+	CmdVersion   = "version"   // 'Q' — firmware version string
+	CmdSignature = "signature" // 'S' — INI signature string
+	CmdLoops     = "loops"     // 'c' — main loop count per second
+	CmdReset     = "reset"     // Re-open and re-handshake the serial link
+)
+
+// commandBytes maps query commands to their TunerStudio command byte.
+var commandBytes = map[string]byte{
+	CmdVersion:   'Q',
+	CmdSignature: 'S',
+	CmdLoops:     'c',
+}
+
+// ErrUnknownCommand is returned for commands outside the allowlist.
+var ErrUnknownCommand = errors.New("ecu: command not allowed")
+
+// CommandResult is a diagnostic command's reply.
+type CommandResult struct {
+	Command string  `json:"command"`
+	Text    string  `json:"text,omitempty"`  // ASCII replies (version, signature)
+	Value   *uint16 `json:"value,omitempty"` // Numeric replies (loops/s)
+	Raw     string  `json:"raw,omitempty"`   // Payload as hex, for anything else
+}
+
+// Commander is implemented by providers that support diagnostic commands.
+type Commander interface {
+	Commands() []string
+	Command(ctx context.Context, name string) (*CommandResult, error)
+}
+
+// Commands lists the diagnostic commands usable in the current protocol.
+// The secondary-serial protocols ("generic", "msdroid", "push") don't carry the
+// TunerStudio query commands, so only reset is available there.
+func (s *Speeduino) Commands() []string {
+	cmds := []string{CmdReset}
+	if s.proto == protoTunerStudio {
+		for name := range commandBytes {
+			cmds = append(cmds, name)
+		}
+	}
+	sort.Strings(cmds)
+	return cmds
+}
+
+// Command runs one allowlisted diagnostic command.
+//
+// "reset" is a soft reset of the dashboard's link — close, re-open and
+// re-handshake — not an ECU reboot: this driver never sends reset commands
+// to the ECU. Cancelling ctx abandons the reconnect.
+func (s *Speeduino) Command(ctx context.Context, name string) (*CommandResult, error) {
+	if name == CmdReset {
+		log.Printf("[speeduino] link reset requested")
+		// Connect closes the old port before re-opening
+		if err := s.Connect(ctx); err != nil {
+			return nil, err
+		}
+		return &CommandResult{Command: name, Text: "link re-established (" + s.protoName() + ")"}, nil
+	}
+
+	cmd, ok := commandBytes[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCommand, name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected || s.port == nil {
+		return nil, fmt.Errorf("speeduino: not connected")
+	}
+	if s.proto != protoTunerStudio {
+		return nil, fmt.Errorf("speeduino: %q needs protocol=tunerstudio (have %s)", name, s.protoName())
+	}
+
+	s.port.ResetInputBuffer()
+	if _, err := s.port.Write(s.wrapMsEnvelope([]byte{cmd})); err != nil {
+		s.connected = false
+		return nil, fmt.Errorf("speeduino: write failed: %w", err)
+	}
+	payload, err := s.readMsEnvelopeResponse()
+	if err != nil {
+		return nil, fmt.Errorf("speeduino: %s: %w", name, err)
+	}
+
+	res := &CommandResult{Command: name, Raw: fmt.Sprintf("% X", payload)}
+	switch name {
+	case CmdLoops:
+		// Optional status byte, then U16 LE
+		if len(payload) >= 2 {
+			v := binary.LittleEndian.Uint16(payload[len(payload)-2:])
+			res.Value = &v
+		}
+	default:
+		res.Text = printable(payload)
+	}
+	return res, nil
+}
+
+// printable returns the printable ASCII in b, which drops the status
+// byte and any NUL padding.
+func printable(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if c >= 0x20 && c <= 0x7E {
+			sb.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Commands lists the diagnostic commands the demo answers.
+func (d *DemoProvider) Commands() []string {
+	return []string{CmdLoops, CmdReset, CmdSignature, CmdVersion}
+}
+
+// Command answers diagnostic commands with fixed, Speeduino-like replies.
+func (d *DemoProvider) Command(ctx context.Context, name string) (*CommandResult, error) {
+	res := &CommandResult{Command: name}
+	switch name {
+	case CmdVersion:
+		res.Text = "speeduino 202402-demo"
+	case CmdSignature:
+		res.Text = "speeduino 202402"
+	case CmdLoops:
+		v := uint16(1800 + rand.Intn(200))
+		res.Value = &v
+	case CmdReset:
+		if err := d.Connect(ctx); err != nil {
+			return nil, err
+		}
+		res.Text = "link re-established (demo)"
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCommand, name)
+	}
+	return res, nil
+}
@@ -1,6 +1,7 @@
 package ecu
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"sync"
@@ -13,29 +14,57 @@ type DemoProvider struct {
 	running bool
 	t       float64 // virtual time accumulator
 	stoich  float64
+	aux     []AuxChannel
+	vvt     vvtError
 }
 
 func NewDemoProvider() *DemoProvider {
 	return &DemoProvider{stoich: 14.7}
 }
 
+// SetAux configures named aux channels, scaled from the simulated
+// aux inputs (0: fuel level sender, 1: trans temp, 0-1023 ADC counts).
+func (d *DemoProvider) SetAux(chans []AuxChannel) {
+	d.mu.Lock()
+	d.aux = chans
+	d.mu.Unlock()
+}
+
 func (d *DemoProvider) Name() string      { return "Demo (Simulated)" }
-func (d *DemoProvider) Connect() error    { d.running = true; return nil }
 func (d *DemoProvider) Close() error      { d.running = false; return nil }
 func (d *DemoProvider) IsConnected() bool { return d.running }
 
+// Connect for Demo has nothing to open; it only honours ctx.
+func (d *DemoProvider) Connect(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d.running = true
+	return nil
+}
+
 // RequestRawData for Demo returns a dummy RawData — no real serial I/O.
-func (d *DemoProvider) RequestRawData() (*RawData, error) {
+func (d *DemoProvider) RequestRawData(ctx context.Context) (*RawData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return &RawData{Tag: "demo"}, nil
 }
 
 // ParseRawData for Demo ignores the raw data and generates simulated values.
 func (d *DemoProvider) ParseRawData(raw *RawData) *DataFrame {
-	f, _ := d.RequestData()
-	return f
+	return d.generate()
+}
+
+func (d *DemoProvider) RequestData(ctx context.Context) (*DataFrame, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.generate(), nil
 }
 
-func (d *DemoProvider) RequestData() (*DataFrame, error) {
+// generate advances the simulation one tick and returns its frame.
+func (d *DemoProvider) generate() *DataFrame {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -209,6 +238,12 @@ func (d *DemoProvider) RequestData() (*DataFrame, error) {
 		SyncLoss:       0,
 	}
 
+	// Aux inputs: fuel level slowly draining, trans temp following load
+	f.AuxIn = make([]uint16, AuxInputs)
+	f.AuxIn[0] = uint16(800 - math.Mod(d.t*0.5, 600))
+	f.AuxIn[1] = uint16(350 + tps*1.5 + rand.Float64()*10)
+	applyAux(f, d.aux)
+
 	// Fan control simulation
 	if coolant > 90 {
 		f.FanStatus = true
@@ -230,6 +265,9 @@ func (d *DemoProvider) RequestData() (*DataFrame, error) {
 		f.ASECurr = uint8(120 - d.t*2)
 	}
 
+	f.PWImbalance = pwImbalance(f.PulseWidth1, f.PulseWidth2, f.PulseWidth3, f.PulseWidth4)
+	d.vvt.update(time.Now(), f)
+
 	// Simulate occasional knock at high load/high RPM
 	if tps > 85 && rpm > 5000 && rand.Float64() < 0.08 {
 		f.KnockCount = uint8(1 + rand.Float64()*3)
@@ -241,5 +279,5 @@ func (d *DemoProvider) RequestData() (*DataFrame, error) {
 		f.IAT = 55 + rand.Float64()*15
 	}
 
-	return f, nil
+	return f
 }
@@ -0,0 +1,114 @@
+package ecu
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// The driver logs every envelope and resync; replays and fuzzing would drown in it
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// addCorpusSeeds seeds f with every reply, boot banner and stream in the
+// conformance recordings.
+func addCorpusSeeds(f *testing.F) {
+	files, err := filepath.Glob("testdata/conformance/*.json")
+	if err != nil || len(files) == 0 {
+		f.Fatalf("no conformance recordings: %v", err)
+	}
+	f.Add([]byte(nil))
+	for _, path := range files {
+		cases, err := loadReplayCases(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, c := range cases {
+			hexes := []string{c.Boot, c.Stream}
+			for _, x := range c.Exchanges {
+				hexes = append(hexes, x.Reply)
+			}
+			for _, h := range hexes {
+				if b, err := decodeHex(h); err == nil && len(b) > 0 {
+					f.Add(b)
+				}
+			}
+		}
+	}
+}
+
+func FuzzParseSecondaryData(f *testing.F) {
+	addCorpusSeeds(f)
+	s := NewSpeeduino(SpeeduinoConfig{})
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if err := checkRanges(s.parseSecondaryData(in)); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func FuzzParsePrimaryData(f *testing.F) {
+	addCorpusSeeds(f)
+	s := NewSpeeduino(SpeeduinoConfig{})
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if err := checkRanges(s.parsePrimaryData(in)); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func FuzzReadMsEnvelopeResponse(f *testing.F) {
+	addCorpusSeeds(f)
+	f.Fuzz(func(t *testing.T, in []byte) {
+		s := NewSpeeduino(SpeeduinoConfig{})
+		s.port = &replayPort{pending: in, eof: true}
+		payload, err := s.readMsEnvelopeResponse()
+		if err != nil {
+			return
+		}
+		switch {
+		case len(payload) == 0 || len(payload) > msMaxPayload:
+			t.Errorf("%d-byte payload, want 1-%d", len(payload), msMaxPayload)
+		case len(payload)+6 > len(in):
+			t.Errorf("%d-byte payload from %d bytes of input", len(payload), len(in))
+		}
+	})
+}
+
+// checkRanges verifies the clamped channels and that no float is NaN or
+// infinite.
+func checkRanges(f *DataFrame) error {
+	switch {
+	case f.RPM > maxRPM:
+		return fmt.Errorf("rpm %d", f.RPM)
+	case f.VSS > maxVSS:
+		return fmt.Errorf("vss %d", f.VSS)
+	case f.FlexPct > 100 || f.BoostDuty > 100:
+		return fmt.Errorf("flex %d%% / boost duty %d%%", f.FlexPct, f.BoostDuty)
+	}
+	for _, pct := range []float64{f.TPS, f.DutyCycle, f.FanDuty, f.VVT1Duty, f.VVT2Duty} {
+		if pct < 0 || pct > 100 {
+			return fmt.Errorf("percentage %v out of 0-100", pct)
+		}
+	}
+	v := reflect.ValueOf(f).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if fv := v.Field(i); fv.Kind() == reflect.Float64 {
+			if x := fv.Float(); math.IsNaN(x) || math.IsInf(x, 0) {
+				return fmt.Errorf("%s = %v", v.Type().Field(i).Name, x)
+			}
+		}
+	}
+	return nil
+}
@@ -1,13 +1,19 @@
 package ecu
 
+import "context"
+
 // Provider is the interface that all ECU backends must implement.
 // Speeduino is the first implementation; RuSEFI can be added later
 // by implementing this same interface.
+//
+// The I/O methods take a context: cancelling it (shutdown) or passing its
+// deadline (a poll taking too long) must abort any serial exchange in
+// flight, rather than leaving it to run out the port's read timeout.
 type Provider interface {
 	// Name returns the human-readable name of this ECU provider.
 	Name() string
 	// Connect opens the serial port and verifies communication.
-	Connect() error
+	Connect(ctx context.Context) error
 	// Close cleanly shuts down the serial connection.
 	Close() error
 	// IsConnected returns whether the provider has an active connection.
@@ -16,7 +22,7 @@ type Provider interface {
 	// RequestRawData performs serial I/O only: sends the poll command
 	// and reads the raw response bytes. No parsing is done.
 	// This should be called from the dedicated serial goroutine.
-	RequestRawData() (*RawData, error)
+	RequestRawData(ctx context.Context) (*RawData, error)
 
 	// ParseRawData parses raw bytes into a DataFrame.
 	// This is CPU-only (no I/O) and safe to call from any goroutine.
@@ -24,7 +30,7 @@ type Provider interface {
 
 	// RequestData is a convenience that calls RequestRawData + ParseRawData.
 	// Prefer the split methods for async pipelines.
-	RequestData() (*DataFrame, error)
+	RequestData(ctx context.Context) (*DataFrame, error)
 }
 
 // RawData carries the raw serial response for deferred async parsing.
@@ -60,6 +66,7 @@ type DataFrame struct {
 	VECurr      uint8   `json:"veCurr"`      // Current VE
 	AFRTarget   float64 `json:"afrTarget"`   // Target AFR
 	DutyCycle   float64 `json:"dutyCycle"`   // Calculated injector duty %
+	PWImbalance float64 `json:"pwImbalance"` // Spread of pulse widths 1-4, % of their mean
 
 	// Corrections
 	GammaEnrich    uint16 `json:"gammaEnrich"`    // Total gamma %
@@ -95,6 +102,8 @@ type DataFrame struct {
 	VVT2Angle  float64 `json:"vvt2Angle"`
 	VVT2Target float64 `json:"vvt2Target"`
 	VVT2Duty   float64 `json:"vvt2Duty"`
+	VVT1Error  float64 `json:"vvt1Error"` // Target - angle, smoothed (deg)
+	VVT2Error  float64 `json:"vvt2Error"` // Target - angle, smoothed (deg)
 
 	// Flex fuel
 	FlexPct     uint8 `json:"flexPct"`     // Ethanol %
@@ -141,4 +150,12 @@ type DataFrame struct {
 
 	// Seconds counter
 	Secl uint8 `json:"secl"`
+
+	// Aux/CAN inputs: raw words and the configured, scaled channels
+	AuxIn []uint16           `json:"auxIn,omitempty"` // canin[0..15]
+	Aux   map[string]float64 `json:"aux,omitempty"`   // Keyed by AuxChannel.Name
+
+	// Channels, when set, limits the JSON encoding and CSV log to these
+	// channels (see ChannelMask). Zero encodes every channel.
+	Channels ChannelMask `json:"-"`
 }
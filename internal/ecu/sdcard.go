@@ -0,0 +1,256 @@
+package ecu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Speeduino SD card access over the TunerStudio protocol. The firmware
+// multiplexes SD requests onto the 'r'/'w' commands using two reserved
+// pages, with the offset/length fields (big-endian here) selecting the
+// request rather than addressing memory:
+//
+//	r 0x11 0000/0010          — card status
+//	w 0x11 0001/0002 <chunk>  — select a 16-entry directory chunk
+//	r 0x11 0000/0202          — read the selected directory chunk
+//	w 0x11 0005/0008 <start> <count>  — select sectors to read
+//	r 0x14 <block>/0800       — read 2048 bytes (4 sectors) of them
+//
+// Every reply starts with a return code byte (0 = OK).
+const (
+	sdPageControl = 0x11 // Status, directory and read setup
+	sdPageRead    = 0x14 // File data
+
+	sdStatusArg1, sdStatusArg2 = 0x0000, 0x0010
+	sdDirSelArg1, sdDirSelArg2 = 0x0001, 0x0002
+	sdDirArg1, sdDirArg2       = 0x0000, 0x0202
+	sdReadSelArg1              = 0x0005
+	sdReadSelArg2              = 0x0008
+	sdReadArg2                 = 0x0800
+
+	sdRCOK        = 0x00
+	sdSectorSize  = 512
+	sdReadBlock   = 2048 // Bytes per read reply
+	sdDirEntries  = 16   // Entries per directory chunk
+	sdDirEntrySz  = 32   // FAT directory entry
+	sdMaxPayload  = 3 + sdReadBlock
+	sdMaxDirChunk = 64 // 1024 files — stops a confused ECU looping forever
+)
+
+// TS_SD_Status bits.
+const (
+	sdBitPresent = 1 << 0
+	sdBitReady   = 1 << 2
+	sdBitLogging = 1 << 3
+	sdBitError   = 1 << 4
+)
+
+// SDStatus is the ECU's SD card state.
+type SDStatus struct {
+	Present bool   `json:"present"`
+	Ready   bool   `json:"ready"`
+	Logging bool   `json:"logging"` // ECU is currently writing a log
+	Error   bool   `json:"error"`
+	Files   int    `json:"files"`   // Log files on the card
+	Sectors uint32 `json:"sectors"` // Card capacity in 512-byte sectors
+}
+
+// SDFile is one log file on the ECU's SD card.
+type SDFile struct {
+	Index    int    `json:"index"` // 1-based, in directory order
+	Name     string `json:"name"`
+	Size     uint32 `json:"size"`               // Bytes
+	Modified int64  `json:"modified,omitempty"` // Unix ms, ECU clock
+	Sector   uint32 `json:"sector"`             // First sector on the card
+}
+
+// SDCard is implemented by providers that can list and read back logs
+// stored on the ECU's own SD card.
+type SDCard interface {
+	SDStatus() (*SDStatus, error)
+	SDLogs() ([]SDFile, error)
+	// SDDownload streams f's contents to w.
+	SDDownload(f SDFile, w io.Writer) error
+}
+
+// sdRequest sends one SD 'r' or 'w' request and returns the reply after
+// its return code. The lock is held per request, so live polling carries
+// on between the chunks of a long download.
+func (s *Speeduino) sdRequest(cmd, page byte, arg1, arg2 uint16, data []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected || s.port == nil {
+		return nil, fmt.Errorf("speeduino: not connected")
+	}
+	if s.proto != protoTunerStudio {
+		return nil, fmt.Errorf("speeduino: SD card access needs protocol=tunerstudio (have %s)", s.protoName())
+	}
+
+	msg := []byte{cmd, s.canID, page, byte(arg1 >> 8), byte(arg1), byte(arg2 >> 8), byte(arg2)}
+	msg = append(msg, data...)
+
+	s.port.ResetInputBuffer()
+	if _, err := s.port.Write(s.wrapMsEnvelope(msg)); err != nil {
+		s.connected = false
+		return nil, fmt.Errorf("speeduino: write failed: %w", err)
+	}
+	payload, err := s.readMsEnvelope(sdMaxPayload)
+	if err != nil {
+		return nil, fmt.Errorf("speeduino: sd: %w", err)
+	}
+	if payload[0] != sdRCOK {
+		return nil, fmt.Errorf("speeduino: sd: return code 0x%02X", payload[0])
+	}
+	return payload[1:], nil
+}
+
+// SDStatus reads the SD card state.
+func (s *Speeduino) SDStatus() (*SDStatus, error) {
+	b, err := s.sdRequest('r', sdPageControl, sdStatusArg1, sdStatusArg2, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 10 {
+		return nil, fmt.Errorf("speeduino: sd status: short reply (%d bytes)", len(b))
+	}
+	return &SDStatus{
+		Present: b[0]&sdBitPresent != 0,
+		Ready:   b[0]&sdBitReady != 0,
+		Logging: b[0]&sdBitLogging != 0,
+		Error:   b[0]&sdBitError != 0,
+		Sectors: binary.BigEndian.Uint32(b[4:8]),
+		Files:   int(binary.BigEndian.Uint16(b[8:10])),
+	}, nil
+}
+
+// SDLogs lists the log files on the SD card.
+func (s *Speeduino) SDLogs() ([]SDFile, error) {
+	var files []SDFile
+	for chunk := uint16(0); chunk < sdMaxDirChunk; chunk++ {
+		if _, err := s.sdRequest('w', sdPageControl, sdDirSelArg1, sdDirSelArg2,
+			[]byte{byte(chunk >> 8), byte(chunk)}); err != nil {
+			return nil, err
+		}
+		b, err := s.sdRequest('r', sdPageControl, sdDirArg1, sdDirArg2, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// Entries, then the 2-byte chunk number
+		n := (len(b) - 2) / sdDirEntrySz
+		for i := 0; i < n; i++ {
+			f, ok := parseSDDirEntry(b[i*sdDirEntrySz : (i+1)*sdDirEntrySz])
+			if !ok {
+				return files, nil
+			}
+			f.Index = len(files) + 1
+			files = append(files, f)
+		}
+		if n < sdDirEntries {
+			return files, nil
+		}
+	}
+	return files, nil
+}
+
+// SDDownload streams f's contents to w, 2048 bytes per request.
+func (s *Speeduino) SDDownload(f SDFile, w io.Writer) error {
+	sectors := (f.Size + sdSectorSize - 1) / sdSectorSize
+	sel := make([]byte, 8)
+	binary.BigEndian.PutUint32(sel[0:4], f.Sector)
+	binary.BigEndian.PutUint32(sel[4:8], sectors)
+	if _, err := s.sdRequest('w', sdPageControl, sdReadSelArg1, sdReadSelArg2, sel); err != nil {
+		return err
+	}
+
+	remaining := f.Size
+	for block := uint16(0); remaining > 0; block++ {
+		b, err := s.sdRequest('r', sdPageRead, block, sdReadArg2, nil)
+		if err != nil {
+			return fmt.Errorf("%w (block %d of %s)", err, block, f.Name)
+		}
+		// Block number echoed back, then data
+		if len(b) < 3 || binary.BigEndian.Uint16(b[0:2]) != block {
+			return fmt.Errorf("speeduino: sd: bad reply for block %d of %s", block, f.Name)
+		}
+		data := b[2:]
+		if uint32(len(data)) > remaining {
+			data = data[:remaining]
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		remaining -= uint32(len(data))
+	}
+	return nil
+}
+
+// parseSDDirEntry decodes a FAT-style 32-byte directory entry. ok is
+// false at the end-of-directory marker.
+func parseSDDirEntry(e []byte) (f SDFile, ok bool) {
+	if e[0] == 0 {
+		return f, false
+	}
+	name := strings.TrimSpace(string(e[0:8]))
+	if ext := strings.TrimSpace(string(e[8:11])); ext != "" {
+		name += "." + ext
+	}
+	f.Name = name
+	f.Sector = uint32(binary.LittleEndian.Uint16(e[20:22]))<<16 | uint32(binary.LittleEndian.Uint16(e[26:28]))
+	f.Size = binary.LittleEndian.Uint32(e[28:32])
+	if t := fatTime(binary.LittleEndian.Uint16(e[24:26]), binary.LittleEndian.Uint16(e[22:24])); !t.IsZero() {
+		f.Modified = t.UnixMilli()
+	}
+	return f, true
+}
+
+// fatTime decodes a FAT date/time pair (zero time if unset).
+func fatTime(date, tod uint16) time.Time {
+	if date == 0 {
+		return time.Time{}
+	}
+	return time.Date(1980+int(date>>9), time.Month(date>>5&0x0F), int(date&0x1F),
+		int(tod>>11), int(tod>>5&0x3F), int(tod&0x1F)*2, 0, time.Local)
+}
+
+// demoSDLog generates a short CSV log for the demo SD card.
+func demoSDLog(n int) []byte {
+	var sb strings.Builder
+	sb.WriteString("Time,RPM,MAP,TPS,CLT,AFR\n")
+	for i := 0; i < 200*n; i++ {
+		t := float64(i) * 0.1
+		fmt.Fprintf(&sb, "%.1f,%d,%d,%d,%d,%.1f\n", t, 850+i*17%5000, 30+i%150, i%100, 85, 14.7-float64(i%20)/10)
+	}
+	return []byte(sb.String())
+}
+
+// SDStatus reports a simulated card holding two logs.
+func (d *DemoProvider) SDStatus() (*SDStatus, error) {
+	return &SDStatus{Present: true, Ready: true, Files: 2, Sectors: 15523840}, nil
+}
+
+// SDLogs lists the simulated logs.
+func (d *DemoProvider) SDLogs() ([]SDFile, error) {
+	mod := time.Now().Add(-24 * time.Hour).Truncate(time.Minute)
+	var files []SDFile
+	for i := 1; i <= 2; i++ {
+		files = append(files, SDFile{
+			Index:    i,
+			Name:     fmt.Sprintf("SPD_%04d.CSV", i),
+			Size:     uint32(len(demoSDLog(i))),
+			Modified: mod.Add(time.Duration(i) * time.Hour).UnixMilli(),
+			Sector:   uint32(i * 1000),
+		})
+	}
+	return files, nil
+}
+
+// SDDownload writes a simulated log.
+func (d *DemoProvider) SDDownload(f SDFile, w io.Writer) error {
+	_, err := w.Write(demoSDLog(f.Index))
+	return err
+}
@@ -1,13 +1,19 @@
 package ecu
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"log"
+	"math"
 	"sync"
 	"time"
 
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"github.com/shaunagostinho/speeduino-dash/internal/diag"
 	"go.bug.st/serial"
 )
 
@@ -24,33 +30,60 @@ const (
 	// Uses the framed 'r' command to fetch the full 130-byte OCH block.
 	// For secondarySerialProtocol = Tuner Studio, or the primary/USB port.
 	protoTunerStudio
+	// protoMsDroid is the msDroid streaming variant: the ECU pushes
+	// 'A'-style frames (0x41 + 75-byte simple data set) continuously
+	// without being polled. The dashboard only listens, so it can share
+	// the port with an ECU already configured for msDroid.
+	// For secondarySerialProtocol = msDroid.
+	protoMsDroid
+	// protoPush is the periodic broadcast variant of the generic protocol:
+	// the ECU pushes 'n'-style frames (0x6E 0x32 <len> + data) on its own
+	// schedule. Listen-only, like msDroid, so there is no poll round trip.
+	protoPush
 )
 
 const (
 	// TunerStudio / primary OCH block constants (from INI)
 	ochBlockSize = 130
 	rCommandType = 0x30
+	msMaxPayload = 1024 // Sanity limit on msEnvelope responses
 
 	// Generic / secondary data sizes
 	genericNDataSize = 119 // Bytes returned by 'n' command (firmware 202409+)
 	genericADataSize = 75  // Bytes returned by 'A' command (legacy)
 
+	// msDroid stream frame: 0x41 marker + simple data set
+	msDroidFrameSize = 1 + genericADataSize
+
+	// Push stream frame header: 0x6E 0x32 <data length>
+	pushHeaderSize = 3
+
 	// Timing constants
 	drainSilenceMs = 100                     // silence threshold for drain loop
 	drainTimeout   = 1500 * time.Millisecond // max time to spend draining
 	readTimeout    = 2 * time.Second         // per INI blockReadTimeout=2000
 )
 
+// Error classes, for link diagnostics.
+var (
+	errReadTimeout = errors.New("incomplete")
+	errCRC         = errors.New("CRC mismatch")
+)
+
 // Speeduino implements the Provider interface for Speeduino ECUs.
 //
-// Two explicit protocol modes, selected via config (no auto-detection):
+// Three explicit protocol modes, selected via config (no auto-detection):
 //
 //   - "generic"      — plain n/A commands on the secondary serial port
 //   - "tunerstudio"  — msEnvelope CRC32-framed r command (primary/USB or
 //     secondary port with secondarySerialProtocol="Tuner Studio")
+//   - "msdroid"      — listen-only decoding of the msDroid frame stream
+//   - "push"         — listen-only decoding of periodically pushed 'n' frames
 //
 // This driver is strictly read-only. It never sends write/burn/reset
 // commands to the ECU, eliminating any risk of modifying ECU settings.
+// The only 'w' commands it sends select SD card log chunks to read back
+// (see sdcard.go); they never touch tune pages.
 type Speeduino struct {
 	portPath string
 	baudRate int
@@ -60,6 +93,14 @@ type Speeduino struct {
 	stoich   float64      // Stoichiometric ratio for lambda calc
 	proto    protocolMode // Protocol mode
 	useNCmd  bool         // true if generic mode uses 'n', false for 'A' fallback
+	stream   []byte       // msDroid: unconsumed bytes from the frame stream
+	aux      []AuxChannel // Named aux input channels
+	vvt      vvtError     // Smoothed VVT tracking error
+	stats    diag.Counters
+
+	// Port opening, swapped out when replaying recorded sessions
+	openPort  func(path string, mode *serial.Mode) (serial.Port, error)
+	openDelay time.Duration
 
 	connected bool // True only after Connect() successfully handshakes
 }
@@ -70,7 +111,9 @@ type SpeeduinoConfig struct {
 	BaudRate int     `yaml:"baud_rate" json:"baudRate"`
 	CanID    byte    `yaml:"can_id" json:"canId"`
 	Stoich   float64 `yaml:"stoich" json:"stoich"`     // e.g. 14.7 for gasoline
-	Protocol string  `yaml:"protocol" json:"protocol"` // "tunerstudio" or "generic"
+	Protocol string  `yaml:"protocol" json:"protocol"` // "tunerstudio", "generic", "msdroid" or "push"
+
+	Aux []AuxChannel `yaml:"aux" json:"aux"` // Named aux/CAN input channels
 }
 
 // NewSpeeduino creates a new Speeduino ECU provider.
@@ -83,8 +126,13 @@ func NewSpeeduino(cfg SpeeduinoConfig) *Speeduino {
 	}
 
 	proto := protoGeneric
-	if cfg.Protocol == "tunerstudio" {
+	switch cfg.Protocol {
+	case "tunerstudio":
 		proto = protoTunerStudio
+	case "msdroid":
+		proto = protoMsDroid
+	case "push":
+		proto = protoPush
 	}
 
 	return &Speeduino{
@@ -93,7 +141,11 @@ func NewSpeeduino(cfg SpeeduinoConfig) *Speeduino {
 		canID:    cfg.CanID,
 		stoich:   cfg.Stoich,
 		proto:    proto,
+		aux:      cfg.Aux,
 		useNCmd:  true, // default to 'n' for generic, may fallback to 'A'
+
+		openPort:  device.Open,
+		openDelay: time.Second, // Per Speeduino INI delayAfterPortOpen=1000
 	}
 }
 
@@ -117,11 +169,17 @@ func (s *Speeduino) IsConnected() bool {
 //  1. Open port, wait 1s, drain boot garbage
 //  2. Send msEnvelope-framed 'Q' command, validate CRC32 response → success
 //
-// On failure, the port is closed and an error is returned.
+// For "msdroid" mode:
+//  1. Open port, wait 1s (no drain — the stream IS the data)
+//  2. Listen for two consecutive frame markers one frame apart → success
+//
+// On failure, the port is closed and an error is returned. If ctx ends
+// mid-handshake the port is closed at once and ctx's error returned.
 // The caller (main.go connectWithRetry) handles retry with backoff.
-func (s *Speeduino) Connect() error {
+func (s *Speeduino) Connect(ctx context.Context) (err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	defer func() { s.stats.Connect(err) }()
 
 	// Close any existing connection
 	if s.port != nil {
@@ -136,46 +194,82 @@ func (s *Speeduino) Connect() error {
 		Parity:   serial.NoParity,
 		StopBits: serial.OneStopBit,
 	}
-	port, err := serial.Open(s.portPath, mode)
+	portPath, err := device.Resolve(s.portPath)
+	if err != nil {
+		return fmt.Errorf("speeduino: %w", err)
+	}
+	port, err := s.openPort(portPath, mode)
 	if err != nil {
-		return fmt.Errorf("speeduino: failed to open %s: %w", s.portPath, err)
+		return fmt.Errorf("speeduino: failed to open %s: %w", portPath, err)
 	}
 	if err := port.SetReadTimeout(readTimeout); err != nil {
 		port.Close()
 		return fmt.Errorf("speeduino: failed to set timeout: %w", err)
 	}
-	s.port = port
+	s.port = diag.NewPort(port, &s.stats)
 
-	protoName := "generic"
-	if s.proto == protoTunerStudio {
-		protoName = "tunerstudio"
+	protoName := s.protoName()
+	log.Printf("[speeduino] opened %s at %d baud (protocol=%s)", portPath, s.baudRate, protoName)
+
+	stop := s.abortOnDone(ctx)
+	err = s.handshake(ctx)
+	if cerr := stop(); cerr != nil {
+		err = fmt.Errorf("speeduino: connect: %w", cerr)
+	}
+	if err != nil {
+		s.port.Close()
+		s.port = nil
+		return err
 	}
-	log.Printf("[speeduino] opened %s at %d baud (protocol=%s)", s.portPath, s.baudRate, protoName)
 
+	s.connected = true
+	log.Printf("[speeduino] connected to %s (protocol=%s)", s.portPath, protoName)
+	return nil
+}
+
+// handshake waits out the post-open delay, drains boot output and runs
+// the protocol's handshake on the freshly opened port. Caller holds s.mu.
+func (s *Speeduino) handshake(ctx context.Context) error {
 	// Required post-open delay per Speeduino INI (delayAfterPortOpen=1000)
-	time.Sleep(1 * time.Second)
+	select {
+	case <-time.After(s.openDelay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
-	// Passively drain any boot garbage or unsolicited ECU output
-	s.drainSerial("boot")
+	// Passively drain any boot garbage or unsolicited ECU output.
+	// Streaming modes push continuously, so draining would only discard data.
+	if s.proto != protoMsDroid && s.proto != protoPush {
+		s.drainSerial("boot")
+	}
 
 	switch s.proto {
-	case protoGeneric:
-		if err := s.connectGeneric(); err != nil {
-			s.port.Close()
-			s.port = nil
-			return err
-		}
 	case protoTunerStudio:
-		if err := s.connectTunerStudio(); err != nil {
-			s.port.Close()
-			s.port = nil
-			return err
-		}
+		return s.connectTunerStudio()
+	case protoMsDroid:
+		return s.connectMsDroid()
+	case protoPush:
+		return s.connectPush()
+	default:
+		return s.connectGeneric()
 	}
+}
 
-	s.connected = true
-	log.Printf("[speeduino] connected to %s (protocol=%s)", s.portPath, protoName)
-	return nil
+// abortOnDone closes the port if ctx ends before the returned stop is
+// called, so a Read blocked mid-exchange returns at once rather than at
+// the port's read timeout. stop returns ctx's error if it did end the
+// exchange; the link is then down and the next Connect reopens the port.
+// Caller holds s.mu.
+func (s *Speeduino) abortOnDone(ctx context.Context) (stop func() error) {
+	port := s.port
+	cancel := context.AfterFunc(ctx, func() { port.Close() })
+	return func() error {
+		if cancel() {
+			return nil
+		}
+		s.connected = false
+		return ctx.Err()
+	}
 }
 
 // connectGeneric handshakes using the plain secondary serial protocol.
@@ -282,6 +376,114 @@ func (s *Speeduino) connectTunerStudio() error {
 	return nil
 }
 
+// connectMsDroid listens for the msDroid frame stream and aligns to it.
+// Nothing is written to the port.
+func (s *Speeduino) connectMsDroid() error {
+	log.Printf("[speeduino] listening for msDroid stream on %s...", s.portPath)
+
+	s.stream = s.stream[:0]
+	resp, err := s.readResponse(3*msDroidFrameSize, readTimeout)
+	if err != nil {
+		return fmt.Errorf("speeduino: msDroid stream: %w", err)
+	}
+
+	off := findMsDroidSync(resp)
+	if off < 0 {
+		return fmt.Errorf("speeduino: msDroid handshake failed on %s — no frame sync in %d bytes (check secondarySerialProtocol is set to msDroid)", s.portPath, len(resp))
+	}
+	log.Printf("[speeduino] msDroid stream sync at offset %d", off)
+	s.stream = append(s.stream, resp[off:]...)
+	return nil
+}
+
+// findMsDroidSync returns the offset of the first frame marker that is
+// followed by another marker exactly one frame later, or -1.
+func findMsDroidSync(b []byte) int {
+	for i := 0; i+msDroidFrameSize < len(b); i++ {
+		if b[i] == 0x41 && b[i+msDroidFrameSize] == 0x41 {
+			return i
+		}
+	}
+	return -1
+}
+
+// connectPush listens for pushed 'n' frames and aligns to them.
+// Nothing is written to the port.
+func (s *Speeduino) connectPush() error {
+	log.Printf("[speeduino] listening for pushed frames on %s...", s.portPath)
+
+	s.stream = s.stream[:0]
+	resp, err := s.readResponse(3*(pushHeaderSize+genericNDataSize), readTimeout)
+	if err != nil {
+		return fmt.Errorf("speeduino: push stream: %w", err)
+	}
+
+	off := findPushSync(resp)
+	if off < 0 {
+		return fmt.Errorf("speeduino: push handshake failed on %s — no frame sync in %d bytes (is the ECU broadcasting 'n' frames?)", s.portPath, len(resp))
+	}
+	log.Printf("[speeduino] push stream sync at offset %d, data length=%d", off, resp[off+2])
+	s.stream = append(s.stream, resp[off:]...)
+	return nil
+}
+
+// findPushSync returns the offset of the first frame header that is
+// followed by a matching header exactly one frame later, or -1.
+func findPushSync(b []byte) int {
+	for i := 0; i+pushHeaderSize <= len(b); i++ {
+		if !isPushHeader(b[i:]) {
+			continue
+		}
+		next := i + pushHeaderSize + int(b[i+2])
+		if next+pushHeaderSize <= len(b) && isPushHeader(b[next:]) && b[next+2] == b[i+2] {
+			return i
+		}
+	}
+	return -1
+}
+
+// isPushHeader reports whether b starts with a pushed frame header.
+func isPushHeader(b []byte) bool {
+	return len(b) >= pushHeaderSize && b[0] == 0x6E && b[1] == 0x32 && b[2] != 0
+}
+
+// pushFrame returns the size of the complete frame at the start of b.
+func pushFrame(b []byte) (size int, ok bool) {
+	if !isPushHeader(b) {
+		return 0, false
+	}
+	size = pushHeaderSize + int(b[2])
+	return size, len(b) >= size
+}
+
+// pushResync returns the offset of the first frame header in b, or of a
+// trailing partial header still being received.
+func pushResync(b []byte) int {
+	for i := range b {
+		switch rest := b[i:]; {
+		case isPushHeader(rest),
+			len(rest) == 1 && rest[0] == 0x6E,
+			len(rest) == 2 && rest[0] == 0x6E && rest[1] == 0x32:
+			return i
+		}
+	}
+	return len(b)
+}
+
+// protoName returns the config name of the active protocol mode.
+func (s *Speeduino) protoName() string {
+	switch s.proto {
+	case protoTunerStudio:
+		return "tunerstudio"
+	case protoMsDroid:
+		return "msdroid"
+	case protoPush:
+		return "push"
+	default:
+		return "generic"
+	}
+}
+
 // Close cleanly shuts down the serial connection.
 func (s *Speeduino) Close() error {
 	s.mu.Lock()
@@ -298,7 +500,10 @@ func (s *Speeduino) Close() error {
 // RequestRawData performs serial I/O only: sends the poll command and reads
 // the raw response bytes. No parsing is done. This keeps the serial goroutine
 // as tight as possible — it's back ready for the next cycle immediately.
-func (s *Speeduino) RequestRawData() (*RawData, error) {
+//
+// ctx bounds the whole exchange: when it ends, the port is closed to
+// abort the read and the link must be reconnected.
+func (s *Speeduino) RequestRawData(ctx context.Context) (*RawData, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -306,6 +511,35 @@ func (s *Speeduino) RequestRawData() (*RawData, error) {
 		return nil, fmt.Errorf("speeduino: not connected")
 	}
 
+	s.stats.Request()
+	start := time.Now()
+	stop := s.abortOnDone(ctx)
+	raw, err := s.requestRaw()
+	if cerr := stop(); cerr != nil {
+		raw, err = nil, fmt.Errorf("speeduino: poll: %w", cerr)
+	}
+	switch {
+	case err == nil:
+		s.stats.Response(time.Since(start))
+	case errors.Is(err, errReadTimeout), errors.Is(err, context.DeadlineExceeded):
+		s.stats.Timeout(err)
+	case errors.Is(err, context.Canceled):
+		// Shutting down, not a link fault
+	case errors.Is(err, errCRC):
+		s.stats.CRCError(err)
+	default:
+		s.stats.Error(err)
+	}
+	return raw, err
+}
+
+// Diagnostics returns the serial link statistics.
+func (s *Speeduino) Diagnostics() diag.Snapshot {
+	return s.stats.Snapshot()
+}
+
+// requestRaw dispatches to the protocol's poll. Caller holds s.mu.
+func (s *Speeduino) requestRaw() (*RawData, error) {
 	switch s.proto {
 	case protoGeneric:
 		if s.useNCmd {
@@ -314,6 +548,10 @@ func (s *Speeduino) RequestRawData() (*RawData, error) {
 		return s.rawGenericA()
 	case protoTunerStudio:
 		return s.rawTunerStudio()
+	case protoMsDroid:
+		return s.rawMsDroid()
+	case protoPush:
+		return s.rawPush()
 	default:
 		return nil, fmt.Errorf("speeduino: unknown protocol mode")
 	}
@@ -323,7 +561,7 @@ func (s *Speeduino) RequestRawData() (*RawData, error) {
 // This is CPU-only (no I/O) and safe to call from any goroutine.
 func (s *Speeduino) ParseRawData(raw *RawData) *DataFrame {
 	switch raw.Tag {
-	case "generic-n", "generic-a":
+	case "generic-n", "generic-a", "msdroid", "push":
 		return s.parseSecondaryData(raw.Data)
 	case "tunerstudio":
 		return s.parsePrimaryData(raw.Data)
@@ -332,9 +570,24 @@ func (s *Speeduino) ParseRawData(raw *RawData) *DataFrame {
 	}
 }
 
+// Channels returns the channels ParseRawData fills from raw.
+func (s *Speeduino) Channels(raw *RawData) ChannelMask {
+	switch raw.Tag {
+	case "generic-n", "generic-a", "msdroid", "push":
+		if len(raw.Data) > 75 {
+			return secondaryChannels.Union(secondaryEnhancedChannels)
+		}
+		return secondaryChannels
+	case "tunerstudio":
+		return primaryChannels
+	default:
+		return ChannelMask{}
+	}
+}
+
 // RequestData is a convenience that calls RequestRawData + ParseRawData.
-func (s *Speeduino) RequestData() (*DataFrame, error) {
-	raw, err := s.RequestRawData()
+func (s *Speeduino) RequestData(ctx context.Context) (*DataFrame, error) {
+	raw, err := s.RequestRawData(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -449,6 +702,99 @@ func (s *Speeduino) rawTunerStudio() (*RawData, error) {
 	return &RawData{Tag: "tunerstudio", Data: data}, nil
 }
 
+// ============================================================================
+// msDroid Protocol — unsolicited frame stream, listen only
+// ============================================================================
+
+// rawMsDroid returns the newest complete frame from the msDroid stream.
+// Serial I/O only — no parsing. Nothing is written to the port; if we
+// lose alignment (dropped byte, line noise) we rescan for the marker.
+func (s *Speeduino) rawMsDroid() (*RawData, error) {
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(readTimeout)
+
+	for {
+		// Resync: drop anything before the next frame marker
+		if i := bytes.IndexByte(s.stream, 0x41); i != 0 {
+			if i < 0 {
+				s.stream = s.stream[:0]
+			} else {
+				s.stream = s.stream[i:]
+			}
+		}
+
+		// Skip stale frames so we always hand back the newest one
+		for len(s.stream) >= 2*msDroidFrameSize && s.stream[msDroidFrameSize] == 0x41 {
+			s.stream = s.stream[msDroidFrameSize:]
+		}
+
+		if len(s.stream) >= msDroidFrameSize {
+			data := make([]byte, genericADataSize)
+			copy(data, s.stream[1:msDroidFrameSize])
+			s.stream = append(s.stream[:0], s.stream[msDroidFrameSize:]...)
+			return &RawData{Tag: "msdroid", Data: data}, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			s.connected = false
+			return nil, fmt.Errorf("speeduino: msDroid stream stalled (%d bytes buffered): %w", len(s.stream), errReadTimeout)
+		}
+		n, err := s.port.Read(buf)
+		if err != nil && n == 0 {
+			s.connected = false
+			return nil, fmt.Errorf("speeduino: msDroid read: %w", err)
+		}
+		s.stream = append(s.stream, buf[:n]...)
+	}
+}
+
+// ============================================================================
+// Push Protocol — periodic 'n' frames, listen only
+// ============================================================================
+
+// rawPush returns the newest complete pushed frame. Serial I/O only — no
+// parsing. As with msDroid, nothing is written and alignment is regained
+// by rescanning for a frame header.
+func (s *Speeduino) rawPush() (*RawData, error) {
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(readTimeout)
+
+	for {
+		// Resync: drop anything before the next frame header
+		s.stream = s.stream[pushResync(s.stream):]
+
+		// Skip stale frames so we always hand back the newest one
+		for {
+			size, ok := pushFrame(s.stream)
+			if !ok {
+				break
+			}
+			if _, next := pushFrame(s.stream[size:]); !next {
+				break
+			}
+			s.stream = s.stream[size:]
+		}
+
+		if size, ok := pushFrame(s.stream); ok {
+			data := make([]byte, size-pushHeaderSize)
+			copy(data, s.stream[pushHeaderSize:size])
+			s.stream = append(s.stream[:0], s.stream[size:]...)
+			return &RawData{Tag: "push", Data: data}, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			s.connected = false
+			return nil, fmt.Errorf("speeduino: push stream stalled (%d bytes buffered): %w", len(s.stream), errReadTimeout)
+		}
+		n, err := s.port.Read(buf)
+		if err != nil && n == 0 {
+			s.connected = false
+			return nil, fmt.Errorf("speeduino: push read: %w", err)
+		}
+		s.stream = append(s.stream, buf[:n]...)
+	}
+}
+
 // ============================================================================
 // msEnvelope framing helpers
 // ============================================================================
@@ -486,6 +832,12 @@ func (s *Speeduino) wrapMsEnvelope(payload []byte) []byte {
 //
 // Returns the payload bytes with CRC validated.
 func (s *Speeduino) readMsEnvelopeResponse() ([]byte, error) {
+	return s.readMsEnvelope(msMaxPayload)
+}
+
+// readMsEnvelope is readMsEnvelopeResponse with a caller-chosen payload
+// size limit, for the larger SD card transfers.
+func (s *Speeduino) readMsEnvelope(maxPayload int) ([]byte, error) {
 	// Step 1: Read the 2-byte size header
 	sizeHeader := make([]byte, 2)
 	if err := s.readExact(sizeHeader, readTimeout); err != nil {
@@ -494,7 +846,7 @@ func (s *Speeduino) readMsEnvelopeResponse() ([]byte, error) {
 	respPayloadSize := int(binary.BigEndian.Uint16(sizeHeader))
 	log.Printf("[speeduino] response envelope: payload size = %d", respPayloadSize)
 
-	if respPayloadSize == 0 || respPayloadSize > 1024 {
+	if respPayloadSize == 0 || respPayloadSize > maxPayload {
 		return nil, fmt.Errorf("invalid payload size: %d (raw header: %02X %02X)", respPayloadSize, sizeHeader[0], sizeHeader[1])
 	}
 
@@ -509,7 +861,7 @@ func (s *Speeduino) readMsEnvelopeResponse() ([]byte, error) {
 	calcCRC := crc32.ChecksumIEEE(payload)
 
 	if respCRC != calcCRC {
-		return nil, fmt.Errorf("CRC mismatch: got 0x%08X, want 0x%08X (payload %d bytes)", respCRC, calcCRC, respPayloadSize)
+		return nil, fmt.Errorf("%w: got 0x%08X, want 0x%08X (payload %d bytes)", errCRC, respCRC, calcCRC, respPayloadSize)
 	}
 
 	return payload, nil
@@ -560,7 +912,7 @@ func (s *Speeduino) readExact(buf []byte, timeout time.Duration) error {
 		got += n
 	}
 	if got < len(buf) {
-		return fmt.Errorf("incomplete: got %d bytes, want %d", got, len(buf))
+		return fmt.Errorf("%w: got %d bytes, want %d", errReadTimeout, got, len(buf))
 	}
 	return nil
 }
@@ -594,107 +946,152 @@ func (s *Speeduino) readResponse(maxBytes int, timeout time.Duration) ([]byte, e
 // Parsers
 // ============================================================================
 
+// rawBytes reads fields from a raw data block. Reads past the end return
+// zero, so a truncated or short block parses partially instead of
+// panicking.
+type rawBytes []byte
+
+func (d rawBytes) u8(off int) uint8 {
+	if off >= 0 && off < len(d) {
+		return d[off]
+	}
+	return 0
+}
+
+func (d rawBytes) s8(off int) int8 { return int8(d.u8(off)) }
+
+// u16 reads a little-endian uint16.
+func (d rawBytes) u16(off int) uint16 {
+	if off >= 0 && off+1 < len(d) {
+		return binary.LittleEndian.Uint16(d[off : off+2])
+	}
+	return 0
+}
+
+func (d rawBytes) s16(off int) int16 { return int16(d.u16(off)) }
+
+// Plausibility limits. Line noise that survives framing can decode to
+// values no engine produces; these stop it reaching the gauges, the
+// odometer and the logs.
+const (
+	maxRPM = 20000 // rpm
+	maxVSS = 400   // km/h
+)
+
+// Channels populated by each data layout, for ChannelReporter. Lambda,
+// dutyCycle and aux are derived and always present; pwImbalance and the
+// VVT errors are derived from bytes 75+, so only come with them.
+var (
+	derivedChannels = mustMask("lambda", "dutyCycle", "aux")
+
+	// Secondary serial bytes 0-74 ('A' and the start of 'n')
+	secondaryChannels = derivedChannels.Union(mustMask(
+		"secl", "dfcoOn", "running", "cranking", "ase", "warmup", "dwell",
+		"map", "iat", "coolant", "batCorrection", "batteryVoltage", "afr",
+		"egoCorrection", "airCorrection", "warmupEnrich", "rpm", "accelEnrich",
+		"gammaEnrich", "veCurr", "ve1", "afrTarget", "pulseWidth1", "advance",
+		"tps", "loopsPerSecond", "freeRAM", "boostTarget", "boostDuty", "sync",
+		"rpmDot", "flexPct", "flexFuelCor", "flexIgnCor", "idleLoad", "afr2",
+		"baro", "auxIn", "errors",
+	))
+
+	// Secondary serial bytes 75+ ('n' only)
+	secondaryEnhancedChannels = mustMask(
+		"pulseWidth2", "pulseWidth3", "pulseWidth4", "pwImbalance", "fuelLoad", "ignLoad",
+		"clIdleTarget", "mapDot", "vvt1Angle", "vvt1Target", "vvt1Duty",
+		"baroCorrection", "aseCurr", "vss", "gear", "fuelPressure",
+		"oilPressure", "fanStatus", "vvt2Angle", "vvt2Target", "vvt2Duty",
+		"vvt1Error", "vvt2Error",
+		"ve2", "advance1", "advance2", "sdStatus",
+	)
+
+	// TunerStudio OCH block
+	primaryChannels = secondaryChannels.Union(secondaryEnhancedChannels).Union(mustMask(
+		"syncLoss", "emap", "fanDuty", "dwellActual", "knockCount", "knockCor",
+	))
+)
+
 // parseSecondaryData decodes the secondary serial data layout into a DataFrame.
 // Used by Generic mode ('n' and 'A' commands). Layout per docs/SPEEDUINO_SECONDARY_SERIAL_PROTOCOL.md
 func (s *Speeduino) parseSecondaryData(d []byte) *DataFrame {
 	f := &DataFrame{}
+	r := rawBytes(d)
 	n := len(d)
 
-	u8 := func(off int) uint8 {
-		if off < n {
-			return d[off]
-		}
-		return 0
-	}
-	s8 := func(off int) int8 {
-		if off < n {
-			return int8(d[off])
-		}
-		return 0
-	}
-	u16le := func(off int) uint16 {
-		if off+1 < n {
-			return binary.LittleEndian.Uint16(d[off : off+2])
-		}
-		return 0
-	}
-	s16le := func(off int) int16 {
-		return int16(u16le(off))
-	}
-
-	f.Secl = u8(0)
-	f.DFCOOn = u8(1)&(1<<4) != 0
-
-	f.Running = u8(2)&(1<<0) != 0
-	f.Cranking = u8(2)&(1<<1) != 0
-	f.ASE = u8(2)&(1<<2) != 0
-	f.Warmup = u8(2)&(1<<3) != 0
-
-	f.Dwell = float64(u8(3)) * 0.1
-
-	f.MAP = u16le(4)
-	f.IAT = float64(u8(6)) - 40
-	f.Coolant = float64(u8(7)) - 40
-
-	f.BatCorrection = u8(8)
-	f.BatteryVoltage = float64(u8(9)) * 0.1
-	f.AFR = float64(u8(10)) * 0.1
-	f.EGOCorrection = u8(11)
-	f.AirCorrection = u8(12)
-	f.WarmupEnrich = u8(13)
-
-	f.RPM = u16le(14)
-	f.AccelEnrich = u8(16)
-	f.GammaEnrich = uint16(u8(17))
-	f.VECurr = u8(18)
-	f.VE1 = u8(18)
-	f.AFRTarget = float64(u8(19)) * 0.1
-	f.PulseWidth1 = float64(u16le(20)) * 0.1
-
-	f.Advance = s8(23)
-	f.TPS = float64(u8(24))
-	f.LoopsPerSecond = u16le(25)
-	f.FreeRAM = u16le(27)
-	f.BoostTarget = u8(29)
-	f.BoostDuty = u8(30)
-	f.Sync = u8(31)&(1<<7) != 0
-
-	f.RPMdot = s16le(32)
-	f.FlexPct = u8(34)
-	f.FlexFuelCor = u8(35)
-	f.FlexIgnCor = s8(36)
-	f.IdleLoad = u8(37)
-	f.AFR2 = float64(u8(39)) * 0.1
-	f.Baro = u8(40)
-	f.Errors = u8(74)
+	f.Secl = r.u8(0)
+	f.DFCOOn = r.u8(1)&(1<<4) != 0
+
+	f.Running = r.u8(2)&(1<<0) != 0
+	f.Cranking = r.u8(2)&(1<<1) != 0
+	f.ASE = r.u8(2)&(1<<2) != 0
+	f.Warmup = r.u8(2)&(1<<3) != 0
+
+	f.Dwell = float64(r.u8(3)) * 0.1
+
+	f.MAP = r.u16(4)
+	f.IAT = float64(r.u8(6)) - 40
+	f.Coolant = float64(r.u8(7)) - 40
+
+	f.BatCorrection = r.u8(8)
+	f.BatteryVoltage = float64(r.u8(9)) * 0.1
+	f.AFR = float64(r.u8(10)) * 0.1
+	f.EGOCorrection = r.u8(11)
+	f.AirCorrection = r.u8(12)
+	f.WarmupEnrich = r.u8(13)
+
+	f.RPM = r.u16(14)
+	f.AccelEnrich = r.u8(16)
+	f.GammaEnrich = uint16(r.u8(17))
+	f.VECurr = r.u8(18)
+	f.VE1 = r.u8(18)
+	f.AFRTarget = float64(r.u8(19)) * 0.1
+	f.PulseWidth1 = float64(r.u16(20)) * 0.1
+
+	f.Advance = r.s8(23)
+	f.TPS = float64(r.u8(24))
+	f.LoopsPerSecond = r.u16(25)
+	f.FreeRAM = r.u16(27)
+	f.BoostTarget = r.u8(29)
+	f.BoostDuty = r.u8(30)
+	f.Sync = r.u8(31)&(1<<7) != 0
+
+	f.RPMdot = r.s16(32)
+	f.FlexPct = r.u8(34)
+	f.FlexFuelCor = r.u8(35)
+	f.FlexIgnCor = r.s8(36)
+	f.IdleLoad = r.u8(37)
+	f.AFR2 = float64(r.u8(39)) * 0.1
+	f.Baro = r.u8(40)
+	f.AuxIn = parseAuxInputs(d, auxOffsetSecondary)
+	f.Errors = r.u8(74)
 
 	// Enhanced data (bytes 75+, from 'n' command)
 	if n > 75 {
-		f.PulseWidth2 = float64(u16le(76)) * 0.1
-		f.PulseWidth3 = float64(u16le(78)) * 0.1
-		f.PulseWidth4 = float64(u16le(80)) * 0.1
-		f.FuelLoad = float64(s16le(84))
-		f.IgnLoad = float64(s16le(86))
-		f.CLIdleTarget = uint16(u8(91)) * 10
-		f.MAPdot = int16(s8(92))
-		f.VVT1Angle = float64(s8(93))
-		f.VVT1Target = float64(u8(94))
-		f.VVT1Duty = float64(u8(95))
-		f.BaroCorrection = u8(98)
-		f.ASECurr = u8(99)
-		f.VSS = u16le(100)
-		f.Gear = u8(102)
-		f.FuelPressure = u8(103)
-		f.OilPressure = u8(104)
-		f.FanStatus = u8(106)&(1<<3) != 0
-		f.VVT2Angle = float64(s8(107))
-		f.VVT2Target = float64(u8(108))
-		f.VVT2Duty = float64(u8(109))
-		f.VE1 = u8(113)
-		f.VE2 = u8(114)
-		f.Advance1 = s8(115)
-		f.Advance2 = s8(116)
-		f.SDStatus = u8(118)
+		f.PulseWidth2 = float64(r.u16(76)) * 0.1
+		f.PulseWidth3 = float64(r.u16(78)) * 0.1
+		f.PulseWidth4 = float64(r.u16(80)) * 0.1
+		f.FuelLoad = float64(r.s16(84))
+		f.IgnLoad = float64(r.s16(86))
+		f.CLIdleTarget = uint16(r.u8(91)) * 10
+		f.MAPdot = int16(r.s8(92))
+		f.VVT1Angle = float64(r.s8(93))
+		f.VVT1Target = float64(r.u8(94))
+		f.VVT1Duty = float64(r.u8(95))
+		f.BaroCorrection = r.u8(98)
+		f.ASECurr = r.u8(99)
+		f.VSS = r.u16(100)
+		f.Gear = r.u8(102)
+		f.FuelPressure = r.u8(103)
+		f.OilPressure = r.u8(104)
+		f.FanStatus = r.u8(106)&(1<<3) != 0
+		f.VVT2Angle = float64(r.s8(107))
+		f.VVT2Target = float64(r.u8(108))
+		f.VVT2Duty = float64(r.u8(109))
+		f.VE1 = r.u8(113)
+		f.VE2 = r.u8(114)
+		f.Advance1 = r.s8(115)
+		f.Advance2 = r.s8(116)
+		f.SDStatus = r.u8(118)
 	}
 
 	s.computeDerived(f)
@@ -705,104 +1102,152 @@ func (s *Speeduino) parseSecondaryData(d []byte) *DataFrame {
 // Layout per speeduino.ini [OutputChannels] section.
 func (s *Speeduino) parsePrimaryData(d []byte) *DataFrame {
 	f := &DataFrame{}
-
-	f.Secl = d[0]
-	f.DFCOOn = d[1]&(1<<4) != 0
-
-	f.Running = d[2]&(1<<0) != 0
-	f.Cranking = d[2]&(1<<1) != 0
-	f.ASE = d[2]&(1<<2) != 0
-	f.Warmup = d[2]&(1<<3) != 0
-
-	f.SyncLoss = d[3]
-	f.MAP = binary.LittleEndian.Uint16(d[4:6])
-	f.IAT = float64(d[6]) - 40
-	f.Coolant = float64(d[7]) - 40
-
-	f.BatCorrection = d[8]
-	f.BatteryVoltage = float64(d[9]) * 0.1
-	f.AFR = float64(d[10]) * 0.1
-	f.EGOCorrection = d[11]
-	f.AirCorrection = d[12]
-	f.WarmupEnrich = d[13]
-
-	f.RPM = binary.LittleEndian.Uint16(d[14:16])
-	f.AccelEnrich = d[16]
-	f.GammaEnrich = binary.LittleEndian.Uint16(d[17:19])
-	f.VE1 = d[19]
-	f.VE2 = d[20]
-	f.AFRTarget = float64(d[21]) * 0.1
-
-	f.Advance = int8(d[24])
-	f.TPS = float64(d[25]) * 0.5
-
-	f.LoopsPerSecond = binary.LittleEndian.Uint16(d[26:28])
-	f.FreeRAM = binary.LittleEndian.Uint16(d[28:30])
-	f.BoostTarget = d[30]
-	f.BoostDuty = d[31]
-	f.Sync = d[32]&(1<<7) != 0
-
-	f.RPMdot = int16(binary.LittleEndian.Uint16(d[33:35]))
-	f.FlexPct = d[35]
-	f.FlexFuelCor = d[36]
-	f.FlexIgnCor = int8(d[37])
-	f.IdleLoad = d[38]
-	f.AFR2 = float64(d[40]) * 0.1
-	f.Baro = d[41]
-	f.Errors = d[75]
-
-	f.PulseWidth1 = float64(binary.LittleEndian.Uint16(d[76:78])) * 0.001
-	f.PulseWidth2 = float64(binary.LittleEndian.Uint16(d[78:80])) * 0.001
-	f.PulseWidth3 = float64(binary.LittleEndian.Uint16(d[80:82])) * 0.001
-	f.PulseWidth4 = float64(binary.LittleEndian.Uint16(d[82:84])) * 0.001
-
-	f.FuelLoad = float64(int16(binary.LittleEndian.Uint16(d[86:88])))
-	f.IgnLoad = float64(int16(binary.LittleEndian.Uint16(d[88:90])))
-	f.Dwell = float64(binary.LittleEndian.Uint16(d[90:92])) * 0.001
-	f.CLIdleTarget = uint16(d[92]) * 10
-	f.MAPdot = int16(binary.LittleEndian.Uint16(d[93:95]))
-
-	f.VVT1Angle = float64(int16(binary.LittleEndian.Uint16(d[95:97]))) * 0.5
-	f.VVT1Target = float64(d[97]) * 0.5
-	f.VVT1Duty = float64(d[98]) * 0.5
-
-	f.BaroCorrection = d[101]
-	f.VECurr = d[102]
-	f.ASECurr = d[103]
-
-	f.VSS = binary.LittleEndian.Uint16(d[104:106])
-	f.Gear = d[106]
-	f.FuelPressure = d[107]
-	f.OilPressure = d[108]
-	f.FanStatus = d[110]&(1<<3) != 0
-
-	f.VVT2Angle = float64(int16(binary.LittleEndian.Uint16(d[111:113]))) * 0.5
-	f.VVT2Target = float64(d[113]) * 0.5
-	f.VVT2Duty = float64(d[114]) * 0.5
-
-	f.Advance1 = int8(d[118])
-	f.Advance2 = int8(d[119])
-	f.SDStatus = d[120]
-
-	f.EMAP = binary.LittleEndian.Uint16(d[121:123])
-	f.FanDuty = float64(d[123]) * 0.5
-	f.DwellActual = float64(binary.LittleEndian.Uint16(d[125:127])) * 0.001
-	f.KnockCount = d[128]
-	f.KnockCor = d[129]
+	r := rawBytes(d)
+
+	f.Secl = r.u8(0)
+	f.DFCOOn = r.u8(1)&(1<<4) != 0
+
+	f.Running = r.u8(2)&(1<<0) != 0
+	f.Cranking = r.u8(2)&(1<<1) != 0
+	f.ASE = r.u8(2)&(1<<2) != 0
+	f.Warmup = r.u8(2)&(1<<3) != 0
+
+	f.SyncLoss = r.u8(3)
+	f.MAP = r.u16(4)
+	f.IAT = float64(r.u8(6)) - 40
+	f.Coolant = float64(r.u8(7)) - 40
+
+	f.BatCorrection = r.u8(8)
+	f.BatteryVoltage = float64(r.u8(9)) * 0.1
+	f.AFR = float64(r.u8(10)) * 0.1
+	f.EGOCorrection = r.u8(11)
+	f.AirCorrection = r.u8(12)
+	f.WarmupEnrich = r.u8(13)
+
+	f.RPM = r.u16(14)
+	f.AccelEnrich = r.u8(16)
+	f.GammaEnrich = r.u16(17)
+	f.VE1 = r.u8(19)
+	f.VE2 = r.u8(20)
+	f.AFRTarget = float64(r.u8(21)) * 0.1
+
+	f.Advance = r.s8(24)
+	f.TPS = float64(r.u8(25)) * 0.5
+
+	f.LoopsPerSecond = r.u16(26)
+	f.FreeRAM = r.u16(28)
+	f.BoostTarget = r.u8(30)
+	f.BoostDuty = r.u8(31)
+	f.Sync = r.u8(32)&(1<<7) != 0
+
+	f.RPMdot = r.s16(33)
+	f.FlexPct = r.u8(35)
+	f.FlexFuelCor = r.u8(36)
+	f.FlexIgnCor = r.s8(37)
+	f.IdleLoad = r.u8(38)
+	f.AFR2 = float64(r.u8(40)) * 0.1
+	f.Baro = r.u8(41)
+	f.AuxIn = parseAuxInputs(d, auxOffsetPrimary)
+	f.Errors = r.u8(75)
+
+	f.PulseWidth1 = float64(r.u16(76)) * 0.001
+	f.PulseWidth2 = float64(r.u16(78)) * 0.001
+	f.PulseWidth3 = float64(r.u16(80)) * 0.001
+	f.PulseWidth4 = float64(r.u16(82)) * 0.001
+
+	f.FuelLoad = float64(r.s16(86))
+	f.IgnLoad = float64(r.s16(88))
+	f.Dwell = float64(r.u16(90)) * 0.001
+	f.CLIdleTarget = uint16(r.u8(92)) * 10
+	f.MAPdot = r.s16(93)
+
+	f.VVT1Angle = float64(r.s16(95)) * 0.5
+	f.VVT1Target = float64(r.u8(97)) * 0.5
+	f.VVT1Duty = float64(r.u8(98)) * 0.5
+
+	f.BaroCorrection = r.u8(101)
+	f.VECurr = r.u8(102)
+	f.ASECurr = r.u8(103)
+
+	f.VSS = r.u16(104)
+	f.Gear = r.u8(106)
+	f.FuelPressure = r.u8(107)
+	f.OilPressure = r.u8(108)
+	f.FanStatus = r.u8(110)&(1<<3) != 0
+
+	f.VVT2Angle = float64(r.s16(111)) * 0.5
+	f.VVT2Target = float64(r.u8(113)) * 0.5
+	f.VVT2Duty = float64(r.u8(114)) * 0.5
+
+	f.Advance1 = r.s8(118)
+	f.Advance2 = r.s8(119)
+	f.SDStatus = r.u8(120)
+
+	f.EMAP = r.u16(121)
+	f.FanDuty = float64(r.u8(123)) * 0.5
+	f.DwellActual = float64(r.u16(125)) * 0.001
+	f.KnockCount = r.u8(128)
+	f.KnockCor = r.u8(129)
 
 	s.computeDerived(f)
 	return f
 }
 
-// computeDerived calculates lambda and duty cycle from raw data.
+// computeDerived calculates lambda, duty cycle, pulse width imbalance,
+// VVT tracking error and the named aux channels from raw data.
 func (s *Speeduino) computeDerived(f *DataFrame) {
+	clampFrame(f)
+	applyAux(f, s.aux)
 	if s.stoich > 0 {
 		f.Lambda = f.AFR / s.stoich
 	}
 	if f.RPM > 0 {
 		cycleTimeMs := 60000.0 / float64(f.RPM) * 2
 		if cycleTimeMs > 0 {
-			f.DutyCycle = (f.PulseWidth1 / cycleTimeMs) * 100
+			f.DutyCycle = math.Min((f.PulseWidth1/cycleTimeMs)*100, 100)
+		}
+	}
+	f.PWImbalance = pwImbalance(f.PulseWidth1, f.PulseWidth2, f.PulseWidth3, f.PulseWidth4)
+	s.vvt.update(time.Now(), f)
+}
+
+// clampFrame limits decoded channels to their physical range.
+func clampFrame(f *DataFrame) {
+	f.RPM = min(f.RPM, maxRPM)
+	f.VSS = min(f.VSS, maxVSS)
+	f.TPS = clampPct(f.TPS)
+	f.FanDuty = clampPct(f.FanDuty)
+	f.VVT1Duty = clampPct(f.VVT1Duty)
+	f.VVT2Duty = clampPct(f.VVT2Duty)
+	f.FlexPct = min(f.FlexPct, 100)
+	f.BoostDuty = min(f.BoostDuty, 100)
+}
+
+func clampPct(v float64) float64 {
+	return math.Max(0, math.Min(v, 100))
+}
+
+// pwImbalance returns the spread of the injector pulse widths in use as a
+// percentage of their mean. Channels reading zero aren't wired (or the
+// engine is off) and are left out; fewer than two in use reads 0.
+func pwImbalance(pw ...float64) float64 {
+	var n int
+	var sum, lo, hi float64
+	for _, v := range pw {
+		if v <= 0 {
+			continue
+		}
+		if n == 0 || v < lo {
+			lo = v
+		}
+		if n == 0 || v > hi {
+			hi = v
 		}
+		sum += v
+		n++
+	}
+	if n < 2 {
+		return 0
 	}
+	return math.Round((hi-lo)/(sum/float64(n))*1000) / 10
 }
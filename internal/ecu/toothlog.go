@@ -0,0 +1,192 @@
+package ecu
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// Speeduino trigger loggers over the TunerStudio protocol, as in the
+// [LoggerDefinition] of speeduino.ini:
+//
+//	H / h  — start / stop the tooth logger
+//	J / j  — start / stop the composite logger
+//	T      — read the buffer once the ECU has filled it
+//
+// Each reply starts with a return code byte. 'T' answers busy until the
+// buffer holds toothLogSize entries, then sends them all and starts a new
+// one. Tooth log entries are a 4-byte tooth gap in µs; composite entries
+// are a 4-byte µs time stamp and a byte of Composite* flags. All
+// big-endian.
+const (
+	toothLogSize   = 127 // Entries per buffer
+	toothLogPoll   = 100 * time.Millisecond
+	toothRCOK      = 0x00
+	toothRCBusy    = 0x85 // Buffer not full yet
+	toothEntrySize = 4
+	compEntrySize  = 5
+)
+
+// Composite log flags.
+const (
+	CompositePri     = 1 << 0 // Primary (crank) input level
+	CompositeSec     = 1 << 1 // Secondary (cam) input level
+	CompositeThird   = 1 << 2 // Third input level
+	CompositeTrigger = 1 << 3 // Logged by a primary trigger
+	CompositeSync    = 1 << 4 // ECU has sync
+)
+
+// ToothEntry is one entry of a trigger log.
+type ToothEntry struct {
+	Time  uint32 // µs: gap since the previous tooth, or in a composite log the ECU's clock
+	Flags uint8  // Composite log only: Composite* bits
+}
+
+// ToothLogger is implemented by providers that can capture the ECU's
+// trigger logs, for diagnosing sync loss.
+type ToothLogger interface {
+	// ToothLog runs the tooth logger (or the composite logger) until ctx
+	// ends, passing fn each buffer of entries as the ECU fills it.
+	ToothLog(ctx context.Context, composite bool, fn func([]ToothEntry)) error
+}
+
+var errToothLogBusy = errors.New("tooth log not ready")
+
+// toothRequest sends one logger command and returns the reply after its
+// return code. Like sdRequest it holds the lock per request, so live
+// polling carries on while a log runs.
+func (s *Speeduino) toothRequest(cmd byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected || s.port == nil {
+		return nil, fmt.Errorf("speeduino: not connected")
+	}
+	if s.proto != protoTunerStudio {
+		return nil, fmt.Errorf("speeduino: tooth logging needs protocol=tunerstudio (have %s)", s.protoName())
+	}
+
+	s.port.ResetInputBuffer()
+	if _, err := s.port.Write(s.wrapMsEnvelope([]byte{cmd})); err != nil {
+		s.connected = false
+		return nil, fmt.Errorf("speeduino: write failed: %w", err)
+	}
+	payload, err := s.readMsEnvelopeResponse()
+	if err != nil {
+		return nil, fmt.Errorf("speeduino: tooth log %c: %w", cmd, err)
+	}
+	switch payload[0] {
+	case toothRCOK:
+		return payload[1:], nil
+	case toothRCBusy:
+		return nil, errToothLogBusy
+	}
+	return nil, fmt.Errorf("speeduino: tooth log %c: return code 0x%02X", cmd, payload[0])
+}
+
+// ToothLog runs the tooth or composite logger until ctx ends. The logger
+// is stopped again on the way out, whatever the outcome.
+func (s *Speeduino) ToothLog(ctx context.Context, composite bool, fn func([]ToothEntry)) error {
+	start, stop, size := byte('H'), byte('h'), toothEntrySize
+	if composite {
+		start, stop, size = 'J', 'j', compEntrySize
+	}
+	if _, err := s.toothRequest(start); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := s.toothRequest(stop); err != nil {
+			log.Printf("[speeduino] stopping tooth logger: %v", err)
+		}
+	}()
+
+	tick := time.NewTicker(toothLogPoll)
+	defer tick.Stop()
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			continue
+		case <-tick.C:
+		}
+		b, err := s.toothRequest('T')
+		if errors.Is(err, errToothLogBusy) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if entries := parseToothLog(b, size); len(entries) > 0 {
+			fn(entries)
+		}
+	}
+	return nil
+}
+
+// parseToothLog splits a 'T' reply into entries of size bytes, ignoring
+// a trailing partial entry.
+func parseToothLog(b []byte, size int) []ToothEntry {
+	entries := make([]ToothEntry, 0, len(b)/size)
+	for ; len(b) >= size; b = b[size:] {
+		e := ToothEntry{Time: binary.BigEndian.Uint32(b)}
+		if size == compEntrySize {
+			e.Flags = b[4]
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// demoTeeth is the simulated trigger wheel: 36-1 on the crank, with a cam
+// tooth once per engine cycle.
+const demoTeeth = 36
+
+// ToothLog simulates a 36-1 wheel turning at the demo's current RPM.
+func (d *DemoProvider) ToothLog(ctx context.Context, composite bool, fn func([]ToothEntry)) error {
+	tick := time.NewTicker(toothLogPoll)
+	defer tick.Stop()
+
+	var clock uint32 // µs
+	tooth := 0       // Position in the engine cycle, 0-71
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+		d.mu.Lock()
+		rpm := 850.0 + 4000.0*math.Pow(math.Sin(d.t*0.3), 2)
+		d.mu.Unlock()
+
+		gap := 60e6 / rpm / demoTeeth
+		n := min(int(float64(toothLogPoll/time.Microsecond)/gap), toothLogSize)
+		entries := make([]ToothEntry, 0, n)
+		for len(entries) < n {
+			tooth = (tooth + 1) % (2 * demoTeeth)
+			if tooth%demoTeeth == demoTeeth-1 {
+				continue // The missing tooth
+			}
+			g := gap
+			if tooth%demoTeeth == 0 {
+				g *= 2 // The gap across it
+			}
+			clock += uint32(g)
+			e := ToothEntry{Time: uint32(g)}
+			if composite {
+				e.Time = clock
+				e.Flags = CompositeTrigger | CompositeSync
+				if tooth%2 == 0 {
+					e.Flags |= CompositePri
+				}
+				if tooth == 0 {
+					e.Flags |= CompositeSec
+				}
+			}
+			entries = append(entries, e)
+		}
+		fn(entries)
+	}
+}
@@ -0,0 +1,60 @@
+package ecu
+
+import (
+	"context"
+	"testing"
+)
+
+func TestToothLog(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		composite   bool
+		start, stop byte
+		data        []byte
+		want        []ToothEntry
+	}{
+		{"tooth", false, 'H', 'h',
+			[]byte{0, 0, 0x03, 0xE8, 0, 0, 0x07, 0xD0},
+			[]ToothEntry{{Time: 1000}, {Time: 2000}}},
+		{"composite", true, 'J', 'j',
+			[]byte{0, 0x01, 0x86, 0xA0, CompositeTrigger | CompositePri, 0, 0x01, 0x8A, 0x88, CompositeSync, 0xFF},
+			[]ToothEntry{{Time: 100000, Flags: CompositeTrigger | CompositePri}, {Time: 101000, Flags: CompositeSync}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewSpeeduino(SpeeduinoConfig{Protocol: "tunerstudio"})
+			envelope := func(payload ...byte) []byte { return s.wrapMsEnvelope(payload) }
+			p := &replayPort{exchanges: []portExchange{
+				{send: envelope(tc.start), reply: envelope(toothRCOK)},
+				{send: envelope('T'), reply: envelope(toothRCBusy)},
+				{send: envelope('T'), reply: envelope(append([]byte{toothRCOK}, tc.data...)...)},
+				{send: envelope(tc.stop), reply: envelope(toothRCOK)},
+			}}
+			s.port, s.connected, s.proto = p, true, protoTunerStudio
+
+			// Stop after the first buffer
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var got []ToothEntry
+			err := s.ToothLog(ctx, tc.composite, func(e []ToothEntry) {
+				got = append(got, e...)
+				cancel()
+			})
+			switch {
+			case err != nil:
+				t.Fatal(err)
+			case p.err != nil:
+				t.Fatal(p.err)
+			case len(p.exchanges) > 0:
+				t.Fatalf("logger not stopped: next exchange %X", p.exchanges[0].send)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(tc.want))
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
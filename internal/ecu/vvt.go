@@ -0,0 +1,51 @@
+package ecu
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// vvtErrorTau is the time constant of the VVT tracking-error smoothing.
+// A healthy phaser reaches a new target in a few hundred ms, so this is
+// long enough that a target step doesn't read as an error but short
+// enough to catch a phaser that sticks or lags for a second or two.
+const vvtErrorTau = 1 * time.Second
+
+// vvtError smooths the VVT target minus angle for each cam. A cam whose
+// target and duty are both zero isn't under control (not fitted, or
+// disabled at this load) and reads 0.
+type vvtError struct {
+	mu  sync.Mutex
+	at  time.Time
+	err [2]float64
+}
+
+// update sets f.VVT1Error and f.VVT2Error at now.
+func (v *vvtError) update(now time.Time, f *DataFrame) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	alpha := 1.0
+	if !v.at.IsZero() {
+		dt := now.Sub(v.at)
+		if dt <= 0 {
+			dt = 0
+		}
+		alpha = 1 - math.Exp(-dt.Seconds()/vvtErrorTau.Seconds())
+	}
+	v.at = now
+
+	cams := [2]struct{ target, angle, duty float64 }{
+		{f.VVT1Target, f.VVT1Angle, f.VVT1Duty},
+		{f.VVT2Target, f.VVT2Angle, f.VVT2Duty},
+	}
+	for i, c := range cams {
+		if c.target == 0 && c.duty == 0 {
+			v.err[i] = 0
+			continue
+		}
+		v.err[i] += (c.target - c.angle - v.err[i]) * alpha
+	}
+	f.VVT1Error = math.Round(v.err[0]*10) / 10
+	f.VVT2Error = math.Round(v.err[1]*10) / 10
+}
@@ -0,0 +1,159 @@
+// Package gauge implements the compact protocol the dash uses to feed
+// small auxiliary displays, such as an ESP32 driving an OLED gauge pod,
+// over UDP or a serial line. See docs/GAUGE_PROTOCOL.md.
+//
+// Every packet is framed the same way on both transports:
+//
+//	0xA5 0x5A | type | length | payload (length bytes) | checksum
+//
+// where checksum is the XOR of the type, length and payload bytes.
+// Multi-byte values are little-endian.
+package gauge
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Version is the protocol version sent in Hello and Welcome.
+const Version = 1
+
+// Packet types. Gauge to dash below 0x80, dash to gauge from 0x80.
+const (
+	TypeHello   byte = 0x01 // Register, with the gauge's ID and channels
+	TypePing    byte = 0x02 // Keepalive, no payload
+	TypeBye     byte = 0x03 // Stop sending, no payload
+	TypeWelcome byte = 0x81 // Registration accepted
+	TypeData    byte = 0x82 // Channel values
+)
+
+const (
+	sync0 = 0xA5
+	sync1 = 0x5A
+
+	// MaxPayload is the largest payload a length byte allows.
+	MaxPayload = 255
+
+	// MaxChannels fits a Data payload: 3 header bytes and 4 per value.
+	MaxChannels = (MaxPayload - 3) / 4
+)
+
+// Data flag bits.
+const (
+	FlagECU = 1 << 0 // ECU data is live; without it ECU channels are NaN
+	FlagGPS = 1 << 1 // GPS has a fix
+)
+
+// Hello is a gauge registering: who it is, how often it wants data (0
+// for the dash's default) and which channels, by dash channel name.
+type Hello struct {
+	Version  int
+	RateHz   int
+	ID       string
+	Channels []string
+}
+
+// Encode frames a packet.
+func Encode(typ byte, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayload {
+		return nil, fmt.Errorf("gauge: payload too long (%d bytes)", len(payload))
+	}
+	out := make([]byte, 0, len(payload)+5)
+	out = append(out, sync0, sync1, typ, byte(len(payload)))
+	out = append(out, payload...)
+	return append(out, checksum(typ, payload)), nil
+}
+
+func checksum(typ byte, payload []byte) byte {
+	c := typ ^ byte(len(payload))
+	for _, b := range payload {
+		c ^= b
+	}
+	return c
+}
+
+// ErrChecksum is returned for a packet that arrived damaged.
+var ErrChecksum = errors.New("gauge: bad checksum")
+
+// Decode parses the first packet in b, returning its type, payload and
+// the bytes of b used. Bytes before the sync pair are skipped and counted
+// in n, so a serial reader can resynchronise; typ is 0 when b holds no
+// complete packet.
+func Decode(b []byte) (typ byte, payload []byte, n int, err error) {
+	start := 0
+	for start+1 < len(b) && (b[start] != sync0 || b[start+1] != sync1) {
+		start++
+	}
+	if start+4 > len(b) {
+		return 0, nil, start, nil
+	}
+	typ, size := b[start+2], int(b[start+3])
+	end := start + 4 + size
+	if end >= len(b) {
+		return 0, nil, start, nil
+	}
+	payload = b[start+4 : end]
+	if checksum(typ, payload) != b[end] {
+		return 0, nil, start + 2, ErrChecksum // Skip this sync pair
+	}
+	return typ, payload, end + 1, nil
+}
+
+// ParseHello reads a Hello payload: version, rate in Hz, then ASCII
+// "id:channel,channel,...".
+func ParseHello(payload []byte) (Hello, error) {
+	if len(payload) < 3 {
+		return Hello{}, errors.New("gauge: short hello")
+	}
+	h := Hello{Version: int(payload[0]), RateHz: int(payload[1])}
+	id, chans, ok := strings.Cut(string(payload[2:]), ":")
+	if !ok || id == "" {
+		return Hello{}, errors.New("gauge: hello without an id")
+	}
+	h.ID = id
+	for _, c := range strings.Split(chans, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			h.Channels = append(h.Channels, c)
+		}
+	}
+	if len(h.Channels) > MaxChannels {
+		return Hello{}, fmt.Errorf("gauge: %d channels (max %d)", len(h.Channels), MaxChannels)
+	}
+	return h, nil
+}
+
+// HelloPayload builds a Hello payload, for gauge simulators and tests.
+func HelloPayload(h Hello) []byte {
+	out := []byte{byte(h.Version), byte(h.RateHz)}
+	return append(out, h.ID+":"+strings.Join(h.Channels, ",")...)
+}
+
+// WelcomePayload answers a Hello with the rate granted and, per
+// requested channel, whether the dash knows it.
+func WelcomePayload(rateHz int, known []bool) []byte {
+	out := []byte{Version, byte(rateHz), byte(len(known))}
+	for _, k := range known {
+		if k {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+	}
+	return out
+}
+
+// DataPayload is a Data payload: sequence number, flags, then each value
+// as a float32 in the order of the gauge's channels, NaN when unknown or
+// unavailable.
+func DataPayload(seq uint16, flags byte, values []float64) []byte {
+	out := make([]byte, 3, 3+4*len(values))
+	binary.LittleEndian.PutUint16(out, seq)
+	out[2] = flags
+	for _, v := range values {
+		out = binary.LittleEndian.AppendUint32(out, math.Float32bits(float32(v)))
+	}
+	return out
+}
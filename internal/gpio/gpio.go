@@ -0,0 +1,89 @@
+// Package gpio connects the dash to hardware on the Pi's header: LEDs,
+// lamps and buttons on GPIO lines (through the Linux GPIO character
+// device, e.g. /dev/gpiochip0 on a Raspberry Pi) and WS2812 ("NeoPixel")
+// LED strips clocked out of an SPI port.
+package gpio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pull is an input's bias resistor.
+type Pull string
+
+const (
+	PullUp   Pull = "up"   // For a button to ground
+	PullDown Pull = "down" // For a button to 3.3 V
+	PullNone Pull = "none" // External resistor
+)
+
+// Color is an RGB LED colour.
+type Color struct {
+	R, G, B uint8
+}
+
+// Named colours for config.
+var colors = map[string]Color{
+	"off":    {},
+	"red":    {255, 0, 0},
+	"green":  {0, 255, 0},
+	"blue":   {0, 0, 255},
+	"amber":  {255, 120, 0},
+	"yellow": {255, 200, 0},
+	"white":  {255, 255, 255},
+	"purple": {160, 0, 255},
+	"cyan":   {0, 255, 255},
+}
+
+// ParseColor returns the colour for a name ("red", "amber", ...) or a
+// "#rrggbb" hex value.
+func ParseColor(s string) (Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := colors[s]; ok {
+		return c, nil
+	}
+	if hex, ok := strings.CutPrefix(s, "#"); ok && len(hex) == 6 {
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+		}
+	}
+	return Color{}, fmt.Errorf("gpio: unknown colour %q", s)
+}
+
+// Scale dims c by f, 0–1.
+func (c Color) Scale(f float64) Color {
+	f = max(0, min(f, 1))
+	return Color{uint8(float64(c.R) * f), uint8(float64(c.G) * f), uint8(float64(c.B) * f)}
+}
+
+// WS2812 timing over SPI: at ws2812SPIHz each data bit is sent as three
+// SPI bits, 110 for a 1 and 100 for a 0, giving the strip's 800 kHz bit
+// rate with the high time it expects. The line is then held low for the
+// latch.
+const (
+	ws2812SPIHz      = 2400000
+	ws2812LatchBytes = 90 // 300 µs low; newer WS2812B parts need 280 µs
+)
+
+// encodeWS2812 returns the SPI bytes that set a strip to leds, first LED
+// first. The strip takes green, red, blue, most significant bit first.
+func encodeWS2812(leds []Color) []byte {
+	out := make([]byte, 1, 1+9*len(leds)+ws2812LatchBytes) // Lead with a low byte: MOSI may idle high
+	for _, c := range leds {
+		for _, v := range [3]uint8{c.G, c.R, c.B} {
+			var bits uint32
+			for i := 7; i >= 0; i-- {
+				bits <<= 3
+				if v&(1<<i) != 0 {
+					bits |= 0b110
+				} else {
+					bits |= 0b100
+				}
+			}
+			out = append(out, byte(bits>>16), byte(bits>>8), byte(bits))
+		}
+	}
+	return append(out, make([]byte, ws2812LatchBytes)...)
+}
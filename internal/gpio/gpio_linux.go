@@ -0,0 +1,197 @@
+//go:build linux
+
+package gpio
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// GPIO character device v2 ioctls and flags (linux/gpio.h)
+const (
+	gpioV2GetLineIoctl         = 0xC250B407 // _IOWR(0xB4, 0x07, struct gpio_v2_line_request)
+	gpioV2LineGetValuesIoctl   = 0xC010B40E // _IOWR(0xB4, 0x0E, struct gpio_v2_line_values)
+	gpioV2LineSetValuesIoctl   = 0xC010B40F // _IOWR(0xB4, 0x0F, struct gpio_v2_line_values)
+	gpioV2LineFlagActiveLow    = 1 << 1
+	gpioV2LineFlagInput        = 1 << 2
+	gpioV2LineFlagOutput       = 1 << 3
+	gpioV2LineFlagBiasPullUp   = 1 << 8
+	gpioV2LineFlagBiasPullDown = 1 << 9
+	gpioV2LineFlagBiasDisabled = 1 << 10
+	gpioV2LineRequestSizeof    = 592
+)
+
+// gpioV2LineRequest mirrors struct gpio_v2_line_request. No config
+// attributes are set: requested outputs start inactive.
+type gpioV2LineRequest struct {
+	offsets  [64]uint32
+	consumer [32]byte
+	config   struct {
+		flags    uint64
+		numAttrs uint32
+		padding  [5]uint32
+		attrs    [10][3]uint64 // struct gpio_v2_line_config_attribute
+	}
+	numLines        uint32
+	eventBufferSize uint32
+	padding         [5]uint32
+	fd              int32
+}
+
+// gpioV2LineValues mirrors struct gpio_v2_line_values.
+type gpioV2LineValues struct {
+	bits uint64
+	mask uint64
+}
+
+// requestLine requests line on chip with flags and returns its file.
+func requestLine(chip string, line int, flags uint64) (*os.File, error) {
+	c, err := os.OpenFile(chip, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("gpio: %w", err)
+	}
+	defer c.Close()
+	req := gpioV2LineRequest{numLines: 1}
+	req.offsets[0] = uint32(line)
+	copy(req.consumer[:], "goefidash")
+	req.config.flags = flags
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, c.Fd(), gpioV2GetLineIoctl, uintptr(unsafe.Pointer(&req)))
+	if errno != 0 {
+		return nil, fmt.Errorf("gpio: line %d on %s: %w", line, chip, errno)
+	}
+	return os.NewFile(uintptr(req.fd), fmt.Sprintf("gpio%d", line)), nil
+}
+
+// Pin is a GPIO line requested as an output.
+type Pin struct {
+	f *os.File
+}
+
+// OpenPin requests line on chip (e.g. /dev/gpiochip0; on a Pi the line
+// is the BCM GPIO number) as an output, initially off. With activeLow,
+// on drives the line low.
+func OpenPin(chip string, line int, activeLow bool) (*Pin, error) {
+	flags := uint64(gpioV2LineFlagOutput)
+	if activeLow {
+		flags |= gpioV2LineFlagActiveLow
+	}
+	f, err := requestLine(chip, line, flags)
+	if err != nil {
+		return nil, err
+	}
+	return &Pin{f: f}, nil
+}
+
+// Set turns the output on or off.
+func (p *Pin) Set(on bool) error {
+	v := gpioV2LineValues{mask: 1}
+	if on {
+		v.bits = 1
+	}
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, p.f.Fd(), gpioV2LineSetValuesIoctl, uintptr(unsafe.Pointer(&v)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close releases the line, which returns to an input.
+func (p *Pin) Close() error { return p.f.Close() }
+
+// Input is a GPIO line requested as an input, such as a button.
+type Input struct {
+	f *os.File
+}
+
+// OpenInput requests line on chip as an input with the bias pull ("up",
+// "down" or "none"). With activeLow, a low line reads as on: a button to
+// ground with the pull-up.
+func OpenInput(chip string, line int, pull Pull, activeLow bool) (*Input, error) {
+	flags := uint64(gpioV2LineFlagInput)
+	switch pull {
+	case PullUp:
+		flags |= gpioV2LineFlagBiasPullUp
+	case PullDown:
+		flags |= gpioV2LineFlagBiasPullDown
+	case PullNone:
+		flags |= gpioV2LineFlagBiasDisabled
+	default:
+		return nil, fmt.Errorf("gpio: line %d: unknown pull %q", line, pull)
+	}
+	if activeLow {
+		flags |= gpioV2LineFlagActiveLow
+	}
+	f, err := requestLine(chip, line, flags)
+	if err != nil {
+		return nil, err
+	}
+	return &Input{f: f}, nil
+}
+
+// Get reads the input.
+func (in *Input) Get() (bool, error) {
+	v := gpioV2LineValues{mask: 1}
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, in.f.Fd(), gpioV2LineGetValuesIoctl, uintptr(unsafe.Pointer(&v)))
+	if errno != 0 {
+		return false, errno
+	}
+	return v.bits&1 != 0, nil
+}
+
+// Close releases the line.
+func (in *Input) Close() error { return in.f.Close() }
+
+// SPI ioctls (linux/spi/spidev.h)
+const (
+	spiIOCWrMode       = 0x40016b01 // _IOW('k', 1, __u8)
+	spiIOCWrMaxSpeedHz = 0x40046b04 // _IOW('k', 4, __u32)
+)
+
+// Strip is a WS2812 LED strip on an SPI port's MOSI pin (on a Pi,
+// /dev/spidev0.0 and GPIO 10).
+type Strip struct {
+	f *os.File
+	n int
+}
+
+// OpenStrip opens the spidev node dev for a strip of n LEDs.
+func OpenStrip(dev string, n int) (*Strip, error) {
+	f, err := os.OpenFile(dev, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("gpio: %w", err)
+	}
+	mode, speed := uint8(0), uint32(ws2812SPIHz)
+	fd := f.Fd()
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, spiIOCWrMode, uintptr(unsafe.Pointer(&mode))); errno != 0 {
+		f.Close()
+		return nil, fmt.Errorf("gpio: %s: set mode: %w", dev, errno)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, spiIOCWrMaxSpeedHz, uintptr(unsafe.Pointer(&speed))); errno != 0 {
+		f.Close()
+		return nil, fmt.Errorf("gpio: %s: set speed: %w", dev, errno)
+	}
+	return &Strip{f: f, n: n}, nil
+}
+
+// Len returns the number of LEDs.
+func (s *Strip) Len() int { return s.n }
+
+// Set sets the LEDs, first LED first. Missing LEDs are turned off.
+func (s *Strip) Set(leds []Color) error {
+	all := make([]Color, s.n)
+	copy(all, leds)
+	_, err := s.f.Write(encodeWS2812(all))
+	return err
+}
+
+// Close turns the strip off and closes the port.
+func (s *Strip) Close() error {
+	s.Set(nil)
+	return s.f.Close()
+}
+
+// Compile-time check that gpioV2LineRequest matches the kernel's layout.
+var _ = [1]struct{}{}[unsafe.Sizeof(gpioV2LineRequest{})-gpioV2LineRequestSizeof]
@@ -0,0 +1,43 @@
+//go:build !linux
+
+package gpio
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Pin is unavailable off Linux; outputs fail to open.
+type Pin struct{}
+
+func OpenPin(chip string, line int, activeLow bool) (*Pin, error) {
+	return nil, fmt.Errorf("GPIO is only supported on Linux")
+}
+
+func (p *Pin) Set(on bool) error { return errors.ErrUnsupported }
+
+func (p *Pin) Close() error { return nil }
+
+// Input is unavailable off Linux; inputs fail to open.
+type Input struct{}
+
+func OpenInput(chip string, line int, pull Pull, activeLow bool) (*Input, error) {
+	return nil, fmt.Errorf("GPIO is only supported on Linux")
+}
+
+func (in *Input) Get() (bool, error) { return false, errors.ErrUnsupported }
+
+func (in *Input) Close() error { return nil }
+
+// Strip is unavailable off Linux; strips fail to open.
+type Strip struct{}
+
+func OpenStrip(dev string, n int) (*Strip, error) {
+	return nil, fmt.Errorf("spidev is only supported on Linux")
+}
+
+func (s *Strip) Len() int { return 0 }
+
+func (s *Strip) Set(leds []Color) error { return errors.ErrUnsupported }
+
+func (s *Strip) Close() error { return nil }
@@ -0,0 +1,191 @@
+package gps
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Filter modes.
+const (
+	FilterKalman = "kalman"
+	FilterEMA    = "ema"
+	FilterOff    = "off"
+)
+
+const (
+	filterHeadingMinKph = 5.0 // Below this, course over ground is noise; hold the last heading
+	filterDefaultSpdAcc = 1.5 // km/h, assumed speed accuracy when the receiver doesn't say
+	filterMaxRejects    = 3   // Consecutive rejects before the filter re-anchors
+	filterJumpMarginKph = 30  // Slack added to the speed a position jump may imply
+)
+
+// FilterConfig configures GPS smoothing and glitch rejection.
+type FilterConfig struct {
+	Mode       string  `yaml:"mode" json:"mode"`              // "kalman", "ema" or "off"
+	Alpha      float64 `yaml:"alpha" json:"alpha"`            // EMA weight of each new fix (speed and heading)
+	AccelNoise float64 `yaml:"accel_noise" json:"accelNoise"` // Kalman process noise, km/h per second
+	MaxAccelG  float64 `yaml:"max_accel_g" json:"maxAccelG"`  // Speed changes implying more than this are rejected
+}
+
+// Filter smooths Speed and Heading from successive fixes and rejects
+// single-fix glitches — a position teleport or an impossible speed step —
+// before they reach the odometer and display. Create one per receiver
+// and feed it every fix in order; it is safe for concurrent use.
+//
+// The Kalman mode tracks speed as a constant-velocity state whose
+// process noise is AccelNoise; the measurement noise is the receiver's
+// own speed accuracy estimate when it has one (UBX), so better fixes pull
+// harder. Heading is always an EMA of the direction vector, which handles
+// the 359°→0° wrap.
+type Filter struct {
+	mu  sync.Mutex
+	cfg FilterConfig
+
+	out     *Data // Last output
+	lastAt  time.Time
+	lastKey fixKey // Last fix seen, to spot repeats
+
+	speedVar       float64 // Kalman estimate variance, (km/h)²
+	hdgSin, hdgCos float64
+	rejects        int
+	rejected       uint64
+}
+
+// NewFilter returns a filter with cfg, filling in defaults.
+func NewFilter(cfg FilterConfig) *Filter {
+	if cfg.Mode == "" {
+		cfg.Mode = FilterKalman
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = 0.3
+	}
+	if cfg.AccelNoise <= 0 {
+		cfg.AccelNoise = 3
+	}
+	if cfg.MaxAccelG <= 0 {
+		cfg.MaxAccelG = 1.5
+	}
+	return &Filter{cfg: cfg}
+}
+
+// Apply filters one fix and returns the result. d isn't modified. A fix
+// identical to the previous one (providers repeat their last fix when
+// nothing new arrived) returns the previous output unchanged.
+func (f *Filter) Apply(d *Data) *Data {
+	return f.apply(time.Now(), d)
+}
+
+// Rejected returns how many fixes have been discarded as glitches.
+func (f *Filter) Rejected() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rejected
+}
+
+func (f *Filter) apply(now time.Time, d *Data) *Data {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if d == nil || f.cfg.Mode == FilterOff || !d.Valid {
+		return d
+	}
+	key := fixKey{d.Timestamp, d.Latitude, d.Longitude}
+	if f.out != nil && key == f.lastKey {
+		return f.out
+	}
+	f.lastKey = key
+
+	out := *d
+	if f.out == nil || !f.out.Valid {
+		f.reset(now, &out)
+		return f.out
+	}
+	dt := now.Sub(f.lastAt).Seconds()
+	if dt <= 0 {
+		dt = 0.1
+	}
+
+	if f.glitch(d, dt) {
+		f.rejected++
+		if f.rejects++; f.rejects <= filterMaxRejects {
+			return f.out
+		}
+		// Persistently "wrong" means the old track was — e.g. the fix
+		// came back after a tunnel. Start over from here.
+		f.reset(now, &out)
+		return f.out
+	}
+	f.rejects = 0
+	f.lastAt = now
+
+	// Speed
+	prev := f.out.Speed
+	switch f.cfg.Mode {
+	case FilterEMA:
+		out.Speed = prev + f.cfg.Alpha*(d.Speed-prev)
+	default:
+		q := f.cfg.AccelNoise * dt
+		r := d.SpeedAcc
+		if r <= 0 {
+			r = filterDefaultSpdAcc
+		}
+		p := f.speedVar + q*q
+		k := p / (p + r*r)
+		out.Speed = prev + k*(d.Speed-prev)
+		f.speedVar = (1 - k) * p
+	}
+	if out.Speed < 0.1 {
+		out.Speed = 0
+	}
+
+	// Heading: hold while slow, else smooth the unit vector
+	if d.Speed < filterHeadingMinKph {
+		out.Heading = f.out.Heading
+	} else {
+		rad := d.Heading * math.Pi / 180
+		f.hdgSin += f.cfg.Alpha * (math.Sin(rad) - f.hdgSin)
+		f.hdgCos += f.cfg.Alpha * (math.Cos(rad) - f.hdgCos)
+		out.Heading = math.Mod(math.Atan2(f.hdgSin, f.hdgCos)*180/math.Pi+360, 360)
+	}
+
+	f.out = &out
+	return f.out
+}
+
+// glitch reports whether d is implausible given the last output: a speed
+// step beyond MaxAccelG, or a position jump faster than either speed
+// could explain.
+func (f *Filter) glitch(d *Data, dt float64) bool {
+	maxStep := f.cfg.MaxAccelG * 9.81 * 3.6 * dt // km/h
+	if math.Abs(d.Speed-f.out.Speed) > maxStep+filterDefaultSpdAcc*2 {
+		return true
+	}
+	jumpKph := haversineM(f.out.Latitude, f.out.Longitude, d.Latitude, d.Longitude) / dt * 3.6
+	return jumpKph > math.Max(d.Speed, f.out.Speed)*1.5+filterJumpMarginKph
+}
+
+func (f *Filter) reset(now time.Time, out *Data) {
+	f.out = out
+	f.lastAt = now
+	f.rejects = 0
+	f.speedVar = filterDefaultSpdAcc * filterDefaultSpdAcc
+	rad := out.Heading * math.Pi / 180
+	f.hdgSin, f.hdgCos = math.Sin(rad), math.Cos(rad)
+}
+
+// fixKey identifies a fix well enough to tell a repeat from a new one.
+type fixKey struct {
+	ts       string
+	lat, lon float64
+}
+
+// haversineM returns the great-circle distance between two points in metres.
+func haversineM(lat1, lon1, lat2, lon2 float64) float64 {
+	const r = 6371000.0
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * r * math.Asin(math.Sqrt(a))
+}
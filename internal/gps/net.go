@@ -0,0 +1,265 @@
+package gps
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/diag"
+)
+
+const (
+	netDefaultListen = ":10110"         // The registered NMEA-over-IP port
+	netReadTimeout   = 2 * time.Second  // Read gives up after this with no new fix
+	netMaxLine       = 4096             // Longest line accepted
+	netIdleTimeout   = 30 * time.Second // TCP senders silent this long are dropped
+)
+
+// NetProvider receives positions pushed over the network by a phone app
+// (GPS2IP, ShareGPS, a Tasker or web page script), for a quick start
+// without a GPS module. It listens on the same port for UDP datagrams and
+// TCP connections, each carrying lines of either NMEA 0183 sentences or
+// JSON positions (netPosition).
+//
+// Apps that run a server on the phone instead (GPS2IP's TCP mode) work
+// with the nmea provider and port_path: tcp://phone:port.
+type NetProvider struct {
+	listen string
+	nmea   *NMEAProvider // Sentence parsing and fix assembly
+
+	mu      sync.Mutex
+	udp     net.PacketConn
+	tcp     net.Listener
+	conns   map[net.Conn]bool
+	sender  string        // Address of the last sender, for logging
+	updated chan struct{} // Signalled when a fix arrives
+}
+
+// NetConfig holds configuration for the network GPS provider.
+type NetConfig struct {
+	Listen string `yaml:"listen" json:"listen"` // host:port, default :10110
+}
+
+// netPosition is the JSON position format. Fields are named as in the
+// browser Geolocation API, so a page can forward navigator.geolocation
+// fixes as they are.
+type netPosition struct {
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Altitude  float64  `json:"altitude"`
+	Accuracy  float64  `json:"accuracy"`  // m
+	Heading   *float64 `json:"heading"`   // Degrees true; null when stopped
+	Speed     *float64 `json:"speed"`     // m/s
+	Timestamp int64    `json:"timestamp"` // Unix ms
+}
+
+// NewNet creates a new network GPS provider.
+func NewNet(cfg NetConfig) *NetProvider {
+	if cfg.Listen == "" {
+		cfg.Listen = netDefaultListen
+	}
+	return &NetProvider{
+		listen:  cfg.Listen,
+		nmea:    NewNMEA(NMEAConfig{}),
+		updated: make(chan struct{}, 1),
+	}
+}
+
+func (p *NetProvider) Name() string { return "Network GPS" }
+
+func (p *NetProvider) Connect(ctx context.Context) (err error) {
+	defer func() { p.nmea.stats.Connect(err) }()
+	p.Close()
+
+	var lc net.ListenConfig
+	udp, err := lc.ListenPacket(ctx, "udp", p.listen)
+	if err != nil {
+		return fmt.Errorf("gps: %w", err)
+	}
+	tcp, err := lc.Listen(ctx, "tcp", p.listen)
+	if err != nil {
+		udp.Close()
+		return fmt.Errorf("gps: %w", err)
+	}
+	p.mu.Lock()
+	p.udp, p.tcp = udp, tcp
+	p.conns = map[net.Conn]bool{}
+	p.mu.Unlock()
+
+	go p.serveUDP(udp)
+	go p.serveTCP(tcp)
+	log.Printf("[gps] listening for NMEA/JSON positions on %s (UDP and TCP)", p.listen)
+	return nil
+}
+
+func (p *NetProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.udp == nil {
+		return nil
+	}
+	p.udp.Close()
+	p.tcp.Close()
+	for c := range p.conns {
+		c.Close()
+	}
+	p.udp, p.tcp, p.conns = nil, nil, nil
+	return nil
+}
+
+// Read waits for the next fix to arrive. With nothing for netReadTimeout
+// it returns an error, so the position goes stale rather than freezing.
+func (p *NetProvider) Read() (*Data, error) {
+	p.nmea.stats.Request()
+	start := time.Now()
+	select {
+	case <-p.updated:
+	case <-time.After(netReadTimeout):
+		err := fmt.Errorf("gps: no position received on %s", p.listen)
+		p.nmea.stats.Timeout(err)
+		return nil, err
+	}
+	p.nmea.stats.Response(time.Since(start))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d := *p.nmea.last
+	return &d, nil
+}
+
+// SetRawTee sends every NMEA line received to f. Must be called before
+// the provider is read.
+func (p *NetProvider) SetRawTee(f func(line string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nmea.tee = f
+}
+
+// Diagnostics returns the link statistics.
+func (p *NetProvider) Diagnostics() diag.Snapshot {
+	return p.nmea.stats.Snapshot()
+}
+
+func (p *NetProvider) serveUDP(conn net.PacketConn) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				p.nmea.stats.Error(fmt.Errorf("gps: %w", err))
+			}
+			return
+		}
+		p.nmea.stats.Rx(n)
+		for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+			p.handle(from.String(), string(line))
+		}
+	}
+}
+
+func (p *NetProvider) serveTCP(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		if p.conns == nil { // Closed meanwhile
+			p.mu.Unlock()
+			conn.Close()
+			return
+		}
+		p.conns[conn] = true
+		p.mu.Unlock()
+		go p.serveConn(conn)
+	}
+}
+
+func (p *NetProvider) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		p.mu.Lock()
+		delete(p.conns, conn)
+		p.mu.Unlock()
+	}()
+	from := conn.RemoteAddr().String()
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, netMaxLine), netMaxLine)
+	for {
+		conn.SetReadDeadline(time.Now().Add(netIdleTimeout))
+		if !sc.Scan() {
+			return
+		}
+		p.nmea.stats.Rx(len(sc.Bytes()) + 1)
+		p.handle(from, sc.Text())
+	}
+}
+
+// handle parses one line from a sender and signals Read when it
+// completes a fix.
+func (p *NetProvider) handle(from, line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fix := false
+	if strings.HasPrefix(line, "{") {
+		var pos netPosition
+		if err := json.Unmarshal([]byte(line), &pos); err != nil || pos.Latitude == nil || pos.Longitude == nil {
+			p.nmea.stats.CRCError(fmt.Errorf("gps: bad JSON position: %.40s", line))
+			return
+		}
+		p.nmea.last = pos.data()
+		fix = true
+	} else {
+		switch p.nmea.handleLine(line) {
+		case "RMC", "GGA":
+			fix = true
+		}
+	}
+	if !fix {
+		return
+	}
+	if from != p.sender {
+		log.Printf("[gps] receiving positions from %s", from)
+		p.sender = from
+	}
+	select {
+	case p.updated <- struct{}{}:
+	default:
+	}
+}
+
+// data converts a JSON position to a fix.
+func (pos *netPosition) data() *Data {
+	d := &Data{
+		Valid:      true,
+		Latitude:   *pos.Latitude,
+		Longitude:  *pos.Longitude,
+		Altitude:   pos.Altitude,
+		FixQuality: 1,
+		HAcc:       pos.Accuracy,
+		Time:       pos.Timestamp,
+	}
+	if pos.Speed != nil {
+		d.Speed = *pos.Speed * 3.6
+	}
+	if pos.Heading != nil {
+		d.Heading = *pos.Heading
+	}
+	if pos.Timestamp > 0 {
+		d.Timestamp = time.UnixMilli(pos.Timestamp).UTC().Format("150405.00")
+	}
+	return d
+}
@@ -2,6 +2,7 @@ package gps
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -11,24 +12,40 @@ import (
 	"sync"
 	"time"
 
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"github.com/shaunagostinho/speeduino-dash/internal/diag"
 	"go.bug.st/serial"
 )
 
 // NMEAProvider reads standard NMEA 0183 sentences from a UART GPS.
 // Compatible with u-blox NEO-M8N and any standard NMEA GPS.
 type NMEAProvider struct {
-	portPath string
-	baudRate int
-	port     serial.Port
-	scanner  *bufio.Scanner
-	mu       sync.Mutex
-	last     *Data
+	portPath  string
+	baudRate  int
+	rateHz    int
+	configure bool
+	port      serial.Port
+	scanner   *bufio.Scanner
+	mu        sync.Mutex
+	last      *Data
+	stats     diag.Counters
+	tee       func(line string) // Gets every line received (raw_log)
+
+	// Sky view assembly (sky.go)
+	gsv  map[string][]Satellite  // GSV sequences in progress, by talker+signal
+	sky  map[string][]Satellite  // Last complete sequence of each
+	used map[string]map[int]bool // PRNs in the fix, by system (GSA)
 }
 
 // NMEAConfig holds configuration for the NMEA GPS provider.
 type NMEAConfig struct {
 	PortPath string `yaml:"port_path" json:"portPath"`
 	BaudRate int    `yaml:"baud_rate" json:"baudRate"`
+
+	// u-blox only: switch the receiver to 115200 baud, RateHz and
+	// the sentences parsed here only on connect
+	Configure bool `yaml:"configure_receiver" json:"configureReceiver"`
+	RateHz    int  `yaml:"rate_hz" json:"rateHz"`
 }
 
 // NewNMEA creates a new NMEA GPS provider.
@@ -36,30 +53,45 @@ func NewNMEA(cfg NMEAConfig) *NMEAProvider {
 	if cfg.BaudRate == 0 {
 		cfg.BaudRate = 9600 // Standard NMEA default
 	}
+	if cfg.RateHz <= 0 {
+		cfg.RateHz = 10
+	}
 	return &NMEAProvider{
-		portPath: cfg.PortPath,
-		baudRate: cfg.BaudRate,
-		last:     &Data{},
+		portPath:  cfg.PortPath,
+		baudRate:  cfg.BaudRate,
+		rateHz:    min(cfg.RateHz, 25),
+		configure: cfg.Configure,
+		last:      &Data{},
 	}
 }
 
 func (n *NMEAProvider) Name() string { return "NMEA GPS" }
 
-func (n *NMEAProvider) Connect() error {
-	mode := &serial.Mode{
-		BaudRate: n.baudRate,
-		DataBits: 8,
-		Parity:   serial.NoParity,
-		StopBits: serial.OneStopBit,
+func (n *NMEAProvider) Connect(ctx context.Context) (err error) {
+	defer func() { n.stats.Connect(err) }()
+
+	portPath, err := device.Resolve(n.portPath)
+	if err != nil {
+		return fmt.Errorf("gps: %w", err)
+	}
+	var port serial.Port
+	baud := n.baudRate
+	if n.configure && !remoteSkipsConfigure(portPath) {
+		port, err = configureUblox(ctx, device.Open, portPath, n.baudRate, nmeaSetup(n.rateHz))
+		baud = ubxConfigBaud
+	} else {
+		port, err = device.Open(portPath, serialMode(n.baudRate))
+		if err != nil {
+			err = fmt.Errorf("gps: failed to open %s: %w", portPath, err)
+		}
 	}
-	port, err := serial.Open(n.portPath, mode)
 	if err != nil {
-		return fmt.Errorf("gps: failed to open %s: %w", n.portPath, err)
+		return err
 	}
 	port.SetReadTimeout(200 * time.Millisecond)
-	n.port = port
-	n.scanner = bufio.NewScanner(port)
-	log.Printf("[gps] connected to %s at %d baud", n.portPath, n.baudRate)
+	n.port = diag.NewPort(port, &n.stats)
+	n.scanner = bufio.NewScanner(n.port)
+	log.Printf("[gps] connected to %s at %d baud", portPath, baud)
 	return nil
 }
 
@@ -79,34 +111,83 @@ func (n *NMEAProvider) Read() (*Data, error) {
 		return n.last, fmt.Errorf("gps: not connected")
 	}
 
-	// Read up to 20 lines to find RMC + GGA
+	n.stats.Request()
+	start := time.Now()
+
+	// Read up to 40 lines to find RMC + GGA (GSV sequences are long)
 	gotRMC := false
 	gotGGA := false
-	for i := 0; i < 20 && !(gotRMC && gotGGA); i++ {
+	for i := 0; i < 40 && !(gotRMC && gotGGA); i++ {
 		if !n.scanner.Scan() {
 			break
 		}
-		line := strings.TrimSpace(n.scanner.Text())
-		if !strings.HasPrefix(line, "$") {
-			continue
-		}
-		// Validate checksum
-		if !validateNMEAChecksum(line) {
-			continue
-		}
-
-		if strings.HasPrefix(line, "$GPRMC") || strings.HasPrefix(line, "$GNRMC") {
-			n.parseRMC(line)
+		switch n.handleLine(strings.TrimSpace(n.scanner.Text())) {
+		case "RMC":
 			gotRMC = true
-		} else if strings.HasPrefix(line, "$GPGGA") || strings.HasPrefix(line, "$GNGGA") {
-			n.parseGGA(line)
+		case "GGA":
 			gotGGA = true
 		}
 	}
 
+	switch {
+	case gotRMC || gotGGA:
+		n.stats.Response(time.Since(start))
+	case n.scanner.Err() != nil:
+		n.stats.Error(fmt.Errorf("gps: %w", n.scanner.Err()))
+	default:
+		n.stats.Timeout(fmt.Errorf("gps: no RMC/GGA sentence"))
+	}
+
 	return n.last, nil
 }
 
+// handleLine parses one received line into n.last and returns the
+// sentence type it held ("RMC", "GGA", ...), or "" if it wasn't a valid
+// sentence.
+func (n *NMEAProvider) handleLine(line string) string {
+	if n.tee != nil && line != "" {
+		n.tee(line)
+	}
+	if !strings.HasPrefix(line, "$") || len(line) < 7 {
+		return ""
+	}
+	// Validate checksum
+	if !validateNMEAChecksum(line) {
+		n.stats.CRCError(fmt.Errorf("gps: bad checksum: %.20s", line))
+		return ""
+	}
+
+	kind := line[3:6]
+	switch {
+	case strings.HasPrefix(line, "$GPRMC") || strings.HasPrefix(line, "$GNRMC"):
+		n.parseRMC(line)
+	case strings.HasPrefix(line, "$GPGGA") || strings.HasPrefix(line, "$GNGGA"):
+		n.parseGGA(line)
+	case strings.HasPrefix(line, "$GPVTG") || strings.HasPrefix(line, "$GNVTG"):
+		n.parseVTG(line)
+	case kind == "GSA":
+		n.parseGSA(line[1:3], line)
+	case kind == "GSV":
+		n.parseGSV(line[1:3], line)
+	default:
+		return ""
+	}
+	return kind
+}
+
+// SetRawTee sends every line received, including ones that fail the
+// checksum, to f. Must be called before the provider is read.
+func (n *NMEAProvider) SetRawTee(f func(line string)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.tee = f
+}
+
+// Diagnostics returns the serial link statistics.
+func (n *NMEAProvider) Diagnostics() diag.Snapshot {
+	return n.stats.Snapshot()
+}
+
 func (n *NMEAProvider) parseRMC(line string) {
 	// $GPRMC,hhmmss.ss,A,llll.ll,a,yyyyy.yy,a,x.x,x.x,ddmmyy,x.x,a*hh
 	parts := splitNMEA(line)
@@ -116,6 +197,10 @@ func (n *NMEAProvider) parseRMC(line string) {
 
 	n.last.Timestamp = parts[1]
 	n.last.Valid = parts[2] == "A"
+	n.last.Time = 0
+	if t, err := parseNMEATime(parts[9], parts[1]); err == nil {
+		n.last.Time = t.UnixMilli()
+	}
 
 	if n.last.Valid {
 		n.last.Latitude = parseNMEACoord(parts[3], parts[4])
@@ -151,6 +236,17 @@ func (n *NMEAProvider) parseGGA(line string) {
 	}
 }
 
+func (n *NMEAProvider) parseVTG(line string) {
+	// $GPVTG,x.x,T,x.x,M,x.x,N,x.x,K,a*hh — speed in km/h directly
+	parts := splitNMEA(line)
+	if len(parts) < 9 || !n.last.Valid {
+		return
+	}
+	if spd, err := strconv.ParseFloat(parts[7], 64); err == nil {
+		n.last.Speed = spd
+	}
+}
+
 // splitNMEA splits a sentence and strips the checksum suffix.
 func splitNMEA(line string) []string {
 	// Strip checksum: everything after *
@@ -164,6 +260,23 @@ func splitNMEA(line string) []string {
 	return strings.Split(line, ",")
 }
 
+// parseNMEATime combines RMC's ddmmyy date and hhmmss.ss time.
+func parseNMEATime(date, clock string) (time.Time, error) {
+	if len(date) != 6 || len(clock) < 6 {
+		return time.Time{}, fmt.Errorf("gps: no date/time")
+	}
+	t, err := time.Parse("020106150405", date+clock[:6])
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(clock) > 7 && clock[6] == '.' {
+		if frac, err := strconv.ParseFloat("0"+clock[6:], 64); err == nil {
+			t = t.Add(time.Duration(frac * float64(time.Second)))
+		}
+	}
+	return t, nil
+}
+
 // parseNMEACoord converts NMEA ddmm.mmmm format to decimal degrees.
 func parseNMEACoord(raw, dir string) float64 {
 	if raw == "" || dir == "" {
@@ -209,9 +322,9 @@ type DemoGPS struct {
 
 func NewDemoGPS() *DemoGPS { return &DemoGPS{} }
 
-func (d *DemoGPS) Name() string   { return "Demo GPS (Simulated)" }
-func (d *DemoGPS) Connect() error { return nil }
-func (d *DemoGPS) Close() error   { return nil }
+func (d *DemoGPS) Name() string                      { return "Demo GPS (Simulated)" }
+func (d *DemoGPS) Connect(ctx context.Context) error { return nil }
+func (d *DemoGPS) Close() error                      { return nil }
 
 func (d *DemoGPS) Read() (*Data, error) {
 	d.mu.Lock()
@@ -234,5 +347,6 @@ func (d *DemoGPS) Read() (*Data, error) {
 		FixQuality: 1,
 		HDOP:       0.8,
 		Timestamp:  time.Now().UTC().Format("150405.00"),
+		Time:       time.Now().UnixMilli(),
 	}, nil
 }
@@ -1,24 +1,48 @@
 package gps
 
+import "context"
+
 // Provider is the interface for GPS data sources.
 type Provider interface {
 	Name() string
-	Connect() error
+	// Connect opens the source. Cancelling ctx abandons a connect still
+	// in progress.
+	Connect(ctx context.Context) error
 	Close() error
 	// Read returns the latest GPS fix. May block briefly.
 	Read() (*Data, error)
 }
 
+// RawTee is implemented by providers that can copy every line received
+// from the receiver, as is, to a sink (e.g. a raw NMEA log).
+type RawTee interface {
+	SetRawTee(func(line string))
+}
+
 // Data holds a single GPS fix.
 type Data struct {
-	Valid      bool    `json:"valid"`      // Fix is valid
-	Latitude   float64 `json:"latitude"`   // Decimal degrees
-	Longitude  float64 `json:"longitude"`  // Decimal degrees
-	Speed      float64 `json:"speed"`      // km/h
-	Heading    float64 `json:"heading"`    // Degrees true
-	Altitude   float64 `json:"altitude"`   // Meters
-	Satellites int     `json:"satellites"` // Sats in use
-	FixQuality int     `json:"fixQuality"` // 0=none, 1=GPS, 2=DGPS
-	HDOP       float64 `json:"hdop"`       // Horizontal dilution
-	Timestamp  string  `json:"timestamp"`  // UTC time string
+	Valid      bool    `json:"valid"`          // Fix is valid
+	Latitude   float64 `json:"latitude"`       // Decimal degrees
+	Longitude  float64 `json:"longitude"`      // Decimal degrees
+	Speed      float64 `json:"speed"`          // km/h
+	Heading    float64 `json:"heading"`        // Degrees true
+	Altitude   float64 `json:"altitude"`       // Meters
+	Satellites int     `json:"satellites"`     // Sats in use
+	FixQuality int     `json:"fixQuality"`     // 0=none, 1=GPS, 2=DGPS
+	HDOP       float64 `json:"hdop"`           // Horizontal dilution
+	Timestamp  string  `json:"timestamp"`      // UTC time string
+	Time       int64   `json:"time,omitempty"` // UTC date and time of the fix, Unix ms (0 = no date yet)
+
+	// Fix detail (zero when the receiver doesn't report it)
+	FixType int     `json:"fixType,omitempty"` // 2=2D, 3=3D (NMEA GSA or UBX), 4=GNSS+DR (UBX)
+	PDOP    float64 `json:"pdop,omitempty"`    // Position dilution
+	VDOP    float64 `json:"vdop,omitempty"`    // Vertical dilution (NMEA GSA)
+
+	// Receiver accuracy estimates (UBX only)
+	HAcc       float64 `json:"hAcc,omitempty"`       // Horizontal accuracy, m
+	SpeedAcc   float64 `json:"speedAcc,omitempty"`   // Speed accuracy, km/h
+	HeadingAcc float64 `json:"headingAcc,omitempty"` // Heading accuracy, degrees
+
+	// Satellites in view (NMEA GSV/GSA), sorted by system and PRN
+	Sky []Satellite `json:"sky,omitempty"`
 }
@@ -0,0 +1,171 @@
+package gps
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Satellite is one entry in the sky view, from GSV (position, signal)
+// and GSA (whether it's used in the fix).
+type Satellite struct {
+	System    string `json:"system"`    // "GPS", "GLONASS", "Galileo", "BeiDou", "QZSS"
+	PRN       int    `json:"prn"`       // Satellite ID as reported
+	Elevation int    `json:"elevation"` // Degrees above the horizon
+	Azimuth   int    `json:"azimuth"`   // Degrees true
+	SNR       int    `json:"snr"`       // Carrier to noise, dB-Hz (0 = not tracked)
+	Used      bool   `json:"used"`      // Used in the current fix
+}
+
+// GNSS names by NMEA talker ID.
+var talkerSystems = map[string]string{
+	"GP": "GPS",
+	"GL": "GLONASS",
+	"GA": "Galileo",
+	"GB": "BeiDou",
+	"BD": "BeiDou",
+	"GQ": "QZSS",
+}
+
+// GNSS names by NMEA 4.1 system ID (GSA field 18).
+var systemIDs = map[string]string{
+	"1": "GPS",
+	"2": "GLONASS",
+	"3": "Galileo",
+	"4": "BeiDou",
+	"5": "QZSS",
+}
+
+// gsaSystem works out which constellation a GSA sentence covers. "GN"
+// (combined) sentences carry a system ID from NMEA 4.1; older receivers
+// are told apart by PRN range, GLONASS being 65–96.
+func gsaSystem(talker string, parts []string) string {
+	if sys, ok := talkerSystems[talker]; ok {
+		return sys
+	}
+	if len(parts) > 18 {
+		if sys, ok := systemIDs[parts[18]]; ok {
+			return sys
+		}
+	}
+	for _, p := range parts[3:15] {
+		if prn, err := strconv.Atoi(p); err == nil {
+			if prn >= 65 && prn <= 96 {
+				return "GLONASS"
+			}
+			return "GPS"
+		}
+	}
+	return "GPS"
+}
+
+func (n *NMEAProvider) parseGSA(talker, line string) {
+	// $GPGSA,A,x,xx,xx,xx,xx,xx,xx,xx,xx,xx,xx,xx,xx,x.x,x.x,x.x[,x]*hh
+	parts := splitNMEA(line)
+	if len(parts) < 18 {
+		return
+	}
+
+	// 1 = no fix, 2 = 2D, 3 = 3D
+	if mode, err := strconv.Atoi(parts[2]); err == nil {
+		n.last.FixType = 0
+		if mode >= 2 {
+			n.last.FixType = mode
+		}
+	}
+	if v, err := strconv.ParseFloat(parts[15], 64); err == nil {
+		n.last.PDOP = v
+	}
+	if v, err := strconv.ParseFloat(parts[17], 64); err == nil {
+		n.last.VDOP = v
+	}
+
+	used := map[int]bool{}
+	for _, p := range parts[3:15] {
+		if prn, err := strconv.Atoi(p); err == nil {
+			used[prn] = true
+		}
+	}
+	if n.used == nil {
+		n.used = map[string]map[int]bool{}
+	}
+	n.used[gsaSystem(talker, parts)] = used
+	n.updateSky()
+}
+
+func (n *NMEAProvider) parseGSV(talker, line string) {
+	// $GPGSV,total,num,sats,{prn,elev,azim,snr}×1–4[,signal]*hh
+	parts := splitNMEA(line)
+	if len(parts) < 4 {
+		return
+	}
+	sys, ok := talkerSystems[talker]
+	if !ok {
+		return
+	}
+	total, err1 := strconv.Atoi(parts[1])
+	num, err2 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || num < 1 || num > total {
+		return
+	}
+
+	// NMEA 4.1 adds a trailing signal ID; each signal is its own sequence
+	key := talker
+	if (len(parts)-4)%4 == 1 {
+		key += parts[len(parts)-1]
+	}
+	if n.gsv == nil {
+		n.gsv = map[string][]Satellite{}
+		n.sky = map[string][]Satellite{}
+	}
+	if num == 1 {
+		n.gsv[key] = nil
+	}
+	for i := 4; i+3 < len(parts); i += 4 {
+		prn, err := strconv.Atoi(parts[i])
+		if err != nil {
+			continue
+		}
+		sat := Satellite{System: sys, PRN: prn}
+		sat.Elevation, _ = strconv.Atoi(parts[i+1])
+		sat.Azimuth, _ = strconv.Atoi(parts[i+2])
+		sat.SNR, _ = strconv.Atoi(parts[i+3]) // Empty when not tracked
+		n.gsv[key] = append(n.gsv[key], sat)
+	}
+	if num == total {
+		n.sky[key] = n.gsv[key]
+		delete(n.gsv, key)
+		n.updateSky()
+	}
+}
+
+// updateSky rebuilds Data.Sky from the latest complete GSV sequences and
+// GSA used lists. A fresh slice each time: Read's callers copy Data
+// shallowly and may still hold the old one.
+func (n *NMEAProvider) updateSky() {
+	type id struct {
+		sys string
+		prn int
+	}
+	merged := map[id]Satellite{}
+	for _, sats := range n.sky {
+		for _, s := range sats {
+			k := id{s.System, s.PRN}
+			// Same satellite on several signals: keep the strongest
+			if prev, ok := merged[k]; !ok || s.SNR > prev.SNR {
+				merged[k] = s
+			}
+		}
+	}
+	sky := make([]Satellite, 0, len(merged))
+	for _, s := range merged {
+		s.Used = n.used[s.System][s.PRN]
+		sky = append(sky, s)
+	}
+	sort.Slice(sky, func(i, j int) bool {
+		if sky[i].System != sky[j].System {
+			return sky[i].System < sky[j].System
+		}
+		return sky[i].PRN < sky[j].PRN
+	})
+	n.last.Sky = sky
+}
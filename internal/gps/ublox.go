@@ -0,0 +1,155 @@
+package gps
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"go.bug.st/serial"
+)
+
+// u-blox receiver auto-configuration (gps.configure_receiver), shared by
+// the NMEA and UBX providers. Configuration is sent blind — no ACK wait —
+// and only to RAM, so a power cycle restores whatever the module had.
+const (
+	ubxConfigBaud  = 115200 // Link speed after configuration
+	ubxFactoryBaud = 9600
+
+	ubxProtoUBX  = 0x0001
+	ubxProtoNMEA = 0x0002
+	ubxProtoIn   = 0x0007 // UBX + NMEA + RTCM
+
+	ubxClassNMEA = 0xF0 // Standard NMEA sentences, for CFG-MSG
+)
+
+// NMEA sentence IDs within ubxClassNMEA.
+const (
+	nmeaGGA = 0x00
+	nmeaGLL = 0x01
+	nmeaGSA = 0x02
+	nmeaGSV = 0x03
+	nmeaRMC = 0x04
+	nmeaVTG = 0x05
+	nmeaZDA = 0x08
+)
+
+// ubloxSetup is what configureUblox programs into the receiver.
+type ubloxSetup struct {
+	rateHz   int       // Navigation rate
+	outProto uint16    // UART1 output protocols
+	msgs     [][3]byte // CFG-MSG class, id, rate (per navigation solution)
+}
+
+// nmeaSetup outputs only the sentences NMEAProvider uses.
+func nmeaSetup(rateHz int) ubloxSetup {
+	return ubloxSetup{
+		rateHz:   rateHz,
+		outProto: ubxProtoNMEA,
+		msgs: [][3]byte{
+			{ubxClassNMEA, nmeaGGA, 1},
+			{ubxClassNMEA, nmeaRMC, 1},
+			{ubxClassNMEA, nmeaVTG, 1},
+			{ubxClassNMEA, nmeaGSA, 1},
+			{ubxClassNMEA, nmeaGSV, byte(rateHz)}, // Sky view once a second is plenty
+			{ubxClassNMEA, nmeaGLL, 0},
+			{ubxClassNMEA, nmeaZDA, 0},
+		},
+	}
+}
+
+// ubxSetup outputs NAV-PVT only.
+func ubxSetup(rateHz int) ubloxSetup {
+	return ubloxSetup{
+		rateHz:   rateHz,
+		outProto: ubxProtoUBX,
+		msgs:     [][3]byte{{ubxClassNAV, ubxNavPVT, 1}},
+	}
+}
+
+// remoteSkipsConfigure reports whether portPath is a TCP or Bluetooth
+// bridge, where configure_receiver is skipped: moving the receiver to
+// ubxConfigBaud would leave it out of step with the bridge's own UART.
+func remoteSkipsConfigure(portPath string) bool {
+	if !device.IsRemote(portPath) {
+		return false
+	}
+	log.Printf("[gps] %s is a serial bridge, not configuring the receiver", portPath)
+	return true
+}
+
+// configureUblox switches the receiver on portPath to ubxConfigBaud and
+// applies setup, returning the port opened at the new rate. The baud
+// switch is sent at the factory 9600, at knownBaud and at ubxConfigBaud,
+// so it lands whatever the module was left at. It stops between steps
+// once ctx ends.
+func configureUblox(ctx context.Context, open func(string, *serial.Mode) (serial.Port, error), portPath string, knownBaud int, setup ubloxSetup) (serial.Port, error) {
+	prt := ubxFrame(ubxClassCFG, ubxCfgPRT, ubxCfgPRTPayload(ubxConfigBaud, setup.outProto))
+	tried := map[int]bool{}
+	for _, baud := range []int{ubxFactoryBaud, knownBaud, ubxConfigBaud} {
+		if baud <= 0 || tried[baud] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		tried[baud] = true
+		p, err := open(portPath, serialMode(baud))
+		if err != nil {
+			return nil, fmt.Errorf("gps: failed to open %s: %w", portPath, err)
+		}
+		p.Write(prt)
+		p.Drain()
+		p.Close()
+	}
+	select { // Receiver applies CFG-PRT after sending
+	case <-time.After(100 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	port, err := open(portPath, serialMode(ubxConfigBaud))
+	if err != nil {
+		return nil, fmt.Errorf("gps: failed to open %s: %w", portPath, err)
+	}
+
+	rate := make([]byte, 6)
+	binary.LittleEndian.PutUint16(rate[0:2], uint16(1000/setup.rateHz)) // measRate ms
+	binary.LittleEndian.PutUint16(rate[2:4], 1)                         // navRate cycles
+	binary.LittleEndian.PutUint16(rate[4:6], 1)                         // timeRef UTC
+	cmds := [][]byte{ubxFrame(ubxClassCFG, ubxCfgRATE, rate)}
+	for _, m := range setup.msgs {
+		cmds = append(cmds, ubxFrame(ubxClassCFG, ubxCfgMSG, m[:]))
+	}
+	for _, c := range cmds {
+		if _, err := port.Write(c); err != nil {
+			port.Close()
+			return nil, fmt.Errorf("gps: configure: %w", err)
+		}
+	}
+	log.Printf("[gps] configured receiver: %d baud, %d Hz", ubxConfigBaud, setup.rateHz)
+	return port, nil
+}
+
+// ubxCfgPRTPayload sets UART1 to baud 8N1, accepting UBX/NMEA/RTCM in
+// and sending outProto out.
+func ubxCfgPRTPayload(baud int, outProto uint16) []byte {
+	p := make([]byte, 20)
+	p[0] = 1                                          // portID: UART1
+	binary.LittleEndian.PutUint32(p[4:8], 0x000008D0) // mode: 8N1
+	binary.LittleEndian.PutUint32(p[8:12], uint32(baud))
+	binary.LittleEndian.PutUint16(p[12:14], ubxProtoIn)
+	binary.LittleEndian.PutUint16(p[14:16], outProto)
+	return p
+}
+
+func serialMode(baud int) *serial.Mode {
+	return &serial.Mode{
+		BaudRate: baud,
+		DataBits: 8,
+		Parity:   serial.NoParity,
+		StopBits: serial.OneStopBit,
+	}
+}
@@ -0,0 +1,305 @@
+package gps
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"github.com/shaunagostinho/speeduino-dash/internal/diag"
+	"go.bug.st/serial"
+)
+
+// UBX framing: sync chars, class, id, U16 LE length, payload, then a
+// Fletcher-8 checksum over class..payload.
+const (
+	ubxSync1    = 0xB5
+	ubxSync2    = 0x62
+	ubxHeader   = 6 // Sync, class, id, length
+	ubxOverhead = ubxHeader + 2
+
+	ubxClassNAV = 0x01
+	ubxClassCFG = 0x06
+	ubxNavPVT   = 0x07 // NAV-PVT: position, velocity, time
+	ubxCfgPRT   = 0x00
+	ubxCfgMSG   = 0x01
+	ubxCfgRATE  = 0x08
+
+	ubxNavPVTLen  = 92
+	ubxMaxPayload = 512 // Longest message we'll buffer; anything bigger is noise
+)
+
+// NAV-PVT fixType values.
+const (
+	UBXNoFix    = 0
+	UBXDeadReck = 1
+	UBX2D       = 2
+	UBX3D       = 3
+	UBXGNSSDR   = 4 // GNSS + dead reckoning
+	UBXTimeOnly = 5
+)
+
+// UBXProvider reads UBX-NAV-PVT binary messages from a u-blox receiver.
+// One NAV-PVT carries position, speed, heading, fix type and accuracy
+// estimates, and the receiver can send it at up to 25 Hz — NMEA at
+// 9600 baud tops out around 1 Hz, too slow for lap timing.
+//
+// NAV-PVT is off in a factory-fresh module: either pre-program it with
+// u-center or set Configure, which switches it to 115200 baud, UBX output
+// only and NAV-PVT at RateHz on every connect.
+type UBXProvider struct {
+	portPath  string
+	baudRate  int
+	rateHz    int
+	configure bool
+	port      serial.Port
+	buf       []byte // Unparsed bytes carried between reads
+	mu        sync.Mutex
+	last      *Data
+	stats     diag.Counters
+
+	openPort func(string, *serial.Mode) (serial.Port, error)
+}
+
+// UBXConfig holds configuration for the UBX GPS provider.
+type UBXConfig struct {
+	PortPath  string `yaml:"port_path" json:"portPath"`
+	BaudRate  int    `yaml:"baud_rate" json:"baudRate"`
+	RateHz    int    `yaml:"rate_hz" json:"rateHz"`                       // Navigation rate, 1–25
+	Configure bool   `yaml:"configure_receiver" json:"configureReceiver"` // Program the receiver on connect
+}
+
+// NewUBX creates a new UBX GPS provider.
+func NewUBX(cfg UBXConfig) *UBXProvider {
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = 115200
+	}
+	if cfg.RateHz <= 0 {
+		cfg.RateHz = 10
+	}
+	if cfg.RateHz > 25 {
+		cfg.RateHz = 25
+	}
+	// A NAV-PVT is 100 bytes on the wire, 1000 bits with start/stop bits
+	if !cfg.Configure && cfg.RateHz*1000 > cfg.BaudRate {
+		log.Printf("[gps] warning: %d Hz NAV-PVT needs more than %d baud", cfg.RateHz, cfg.BaudRate)
+	}
+	return &UBXProvider{
+		portPath:  cfg.PortPath,
+		baudRate:  cfg.BaudRate,
+		rateHz:    cfg.RateHz,
+		configure: cfg.Configure,
+		last:      &Data{},
+		openPort:  device.Open,
+	}
+}
+
+func (u *UBXProvider) Name() string { return "u-blox UBX GPS" }
+
+func (u *UBXProvider) Connect(ctx context.Context) (err error) {
+	defer func() { u.stats.Connect(err) }()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.port != nil {
+		u.port.Close()
+		u.port = nil
+	}
+
+	portPath, err := device.Resolve(u.portPath)
+	if err != nil {
+		return fmt.Errorf("gps: %w", err)
+	}
+
+	var port serial.Port
+	baud := u.baudRate
+	if u.configure && !remoteSkipsConfigure(portPath) {
+		port, err = configureUblox(ctx, u.openPort, portPath, u.baudRate, ubxSetup(u.rateHz))
+		baud = ubxConfigBaud
+	} else {
+		port, err = u.openPort(portPath, serialMode(u.baudRate))
+	}
+	if err != nil {
+		return err
+	}
+	port.SetReadTimeout(50 * time.Millisecond)
+	u.port = diag.NewPort(port, &u.stats)
+	u.buf = u.buf[:0]
+
+	log.Printf("[gps] connected to %s at %d baud (UBX NAV-PVT)", portPath, baud)
+	return nil
+}
+
+func (u *UBXProvider) Close() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.port != nil {
+		err := u.port.Close()
+		u.port = nil
+		return err
+	}
+	return nil
+}
+
+// Read returns the newest NAV-PVT fix. It drains everything buffered so a
+// slow caller never falls behind the receiver, and waits up to 200ms for
+// a first message if none is buffered.
+func (u *UBXProvider) Read() (*Data, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.port == nil {
+		return u.last, fmt.Errorf("gps: not connected")
+	}
+
+	u.stats.Request()
+	start := time.Now()
+	deadline := start.Add(200 * time.Millisecond)
+
+	tmp := make([]byte, 1024)
+	got := false
+	for {
+		n, err := u.port.Read(tmp)
+		if err != nil {
+			u.stats.Error(fmt.Errorf("gps: %w", err))
+			return u.last, nil
+		}
+		u.buf = append(u.buf, tmp[:n]...)
+		if u.parseBuffered() {
+			got = true
+		}
+		// Keep going while data is flowing; stop once it's drained
+		if (got && n < len(tmp)) || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	if got {
+		u.stats.Response(time.Since(start))
+	} else {
+		u.stats.Timeout(fmt.Errorf("gps: no NAV-PVT message"))
+	}
+	return u.last, nil
+}
+
+// Diagnostics returns the serial link statistics.
+func (u *UBXProvider) Diagnostics() diag.Snapshot {
+	return u.stats.Snapshot()
+}
+
+// parseBuffered decodes every complete frame in u.buf, keeping any
+// partial frame for the next read. It reports whether a NAV-PVT was
+// decoded.
+func (u *UBXProvider) parseBuffered() bool {
+	got := false
+	b := u.buf
+	for {
+		// Find sync
+		i := 0
+		for i+1 < len(b) && !(b[i] == ubxSync1 && b[i+1] == ubxSync2) {
+			i++
+		}
+		b = b[i:]
+		if len(b) < ubxHeader {
+			break
+		}
+		n := int(binary.LittleEndian.Uint16(b[4:6]))
+		if n > ubxMaxPayload {
+			b = b[1:] // False sync
+			continue
+		}
+		if len(b) < ubxOverhead+n {
+			break // Partial frame
+		}
+		frame := b[:ubxOverhead+n]
+		ckA, ckB := ubxChecksum(frame[2 : ubxHeader+n])
+		if frame[ubxHeader+n] != ckA || frame[ubxHeader+n+1] != ckB {
+			u.stats.CRCError(fmt.Errorf("gps: UBX checksum mismatch (class 0x%02X id 0x%02X)", b[2], b[3]))
+			b = b[1:]
+			continue
+		}
+		if b[2] == ubxClassNAV && b[3] == ubxNavPVT {
+			if d, ok := parseNavPVT(frame[ubxHeader : ubxHeader+n]); ok {
+				u.last = d
+				got = true
+			}
+		}
+		b = b[len(frame):]
+	}
+	u.buf = append(u.buf[:0], b...)
+	return got
+}
+
+// parseNavPVT decodes a UBX-NAV-PVT payload.
+func parseNavPVT(p []byte) (*Data, bool) {
+	if len(p) < ubxNavPVTLen {
+		return nil, false
+	}
+	i32 := func(off int) int32 { return int32(binary.LittleEndian.Uint32(p[off:])) }
+	u32 := func(off int) uint32 { return binary.LittleEndian.Uint32(p[off:]) }
+
+	fixType := int(p[20])
+	flags := p[21]
+	gnssFixOK := flags&0x01 != 0
+	diffSoln := flags&0x02 != 0
+
+	d := &Data{
+		Valid:      gnssFixOK && (fixType == UBX2D || fixType == UBX3D || fixType == UBXGNSSDR),
+		Latitude:   float64(i32(28)) * 1e-7,
+		Longitude:  float64(i32(24)) * 1e-7,
+		Speed:      float64(i32(60)) * 0.0036, // mm/s to km/h
+		Heading:    float64(i32(64)) * 1e-5,
+		Altitude:   float64(i32(36)) / 1000, // Above mean sea level
+		Satellites: int(p[23]),
+		HDOP:       float64(binary.LittleEndian.Uint16(p[76:78])) * 0.01, // NAV-PVT only has PDOP
+		PDOP:       float64(binary.LittleEndian.Uint16(p[76:78])) * 0.01,
+		FixType:    fixType,
+		HAcc:       float64(u32(40)) / 1000,
+		SpeedAcc:   float64(u32(68)) * 0.0036,
+		HeadingAcc: float64(u32(72)) * 1e-5,
+	}
+	switch {
+	case gnssFixOK && diffSoln:
+		d.FixQuality = 2
+	case gnssFixOK:
+		d.FixQuality = 1
+	}
+
+	// NMEA-style hhmmss.ss when the time is valid, and the full time
+	// once the date is too and it's resolved (leap seconds known)
+	if p[11]&0x02 != 0 {
+		cs := int(i32(16)) / 10_000_000 // nano, may be negative
+		if cs < 0 {
+			cs = 0
+		}
+		d.Timestamp = fmt.Sprintf("%02d%02d%02d.%02d", p[8], p[9], p[10], cs)
+	}
+	if p[11]&0x07 == 0x07 {
+		year := int(binary.LittleEndian.Uint16(p[4:6]))
+		t := time.Date(year, time.Month(p[6]), int(p[7]), int(p[8]), int(p[9]), int(p[10]), 0, time.UTC)
+		d.Time = t.Add(time.Duration(i32(16))).UnixMilli()
+	}
+	return d, true
+}
+
+// ubxFrame wraps payload in UBX framing.
+func ubxFrame(class, id byte, payload []byte) []byte {
+	f := make([]byte, 0, ubxOverhead+len(payload))
+	f = append(f, ubxSync1, ubxSync2, class, id, byte(len(payload)), byte(len(payload)>>8))
+	f = append(f, payload...)
+	ckA, ckB := ubxChecksum(f[2:])
+	return append(f, ckA, ckB)
+}
+
+// ubxChecksum is the 8-bit Fletcher checksum UBX uses.
+func ubxChecksum(b []byte) (ckA, ckB byte) {
+	for _, c := range b {
+		ckA += c
+		ckB += ckA
+	}
+	return ckA, ckB
+}
@@ -0,0 +1,146 @@
+package imu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// chip is the register-level driver for one IMU part.
+type chip interface {
+	name() string
+	defaultAddr() int
+	// setup checks the part's identity and configures ±4 g / ±500 °/s
+	// at about rateHz.
+	setup(d *i2cDev, rateHz int) error
+	read(d *i2cDev) (Sample, error)
+}
+
+func newChip(name string) (chip, error) {
+	switch name {
+	case "mpu6050":
+		return mpu6050{}, nil
+	case "lsm6ds3":
+		return lsm6ds3{}, nil
+	}
+	return nil, fmt.Errorf("imu: unknown chip %q", name)
+}
+
+// ============================================================================
+// InvenSense MPU6050 (also MPU6500/9250 in 6050 mode)
+// ============================================================================
+
+type mpu6050 struct{}
+
+const (
+	mpuSmplrtDiv   = 0x19
+	mpuConfig      = 0x1A
+	mpuGyroConfig  = 0x1B
+	mpuAccelConfig = 0x1C
+	mpuAccelXOutH  = 0x3B
+	mpuPwrMgmt1    = 0x6B
+	mpuWhoAmI      = 0x75
+
+	mpuAccelLSB = 8192.0 // Per g at ±4 g
+	mpuGyroLSB  = 65.5   // Per °/s at ±500 °/s
+)
+
+func (mpu6050) name() string     { return "MPU6050" }
+func (mpu6050) defaultAddr() int { return 0x68 }
+
+func (mpu6050) setup(d *i2cDev, rateHz int) error {
+	id, err := d.readRegs(mpuWhoAmI, 1)
+	if err != nil {
+		return err
+	}
+	switch id[0] {
+	case 0x68, 0x70, 0x71, 0x73: // MPU6050, 6500, 9250, 9255
+	default:
+		return fmt.Errorf("unexpected WHO_AM_I 0x%02x", id[0])
+	}
+	div := byte(max(1, min(256, 1000/rateHz)) - 1) // 1 kHz internal rate with the DLPF on
+	for _, w := range [][2]byte{
+		{mpuPwrMgmt1, 0x01}, // Wake, gyro X PLL clock
+		{mpuConfig, 0x03},   // DLPF 44 Hz
+		{mpuSmplrtDiv, div},
+		{mpuGyroConfig, 0x08},  // ±500 °/s
+		{mpuAccelConfig, 0x08}, // ±4 g
+	} {
+		if err := d.writeReg(w[0], w[1]); err != nil {
+			return err
+		}
+	}
+	time.Sleep(50 * time.Millisecond) // PLL settling
+	return nil
+}
+
+func (mpu6050) read(d *i2cDev) (Sample, error) {
+	b, err := d.readRegs(mpuAccelXOutH, 14) // Accel XYZ, temperature, gyro XYZ
+	if err != nil {
+		return Sample{}, err
+	}
+	v := func(i int) float64 { return float64(int16(binary.BigEndian.Uint16(b[i:]))) }
+	return Sample{
+		Accel: [3]float64{v(0) / mpuAccelLSB, v(2) / mpuAccelLSB, v(4) / mpuAccelLSB},
+		Gyro:  [3]float64{v(8) / mpuGyroLSB, v(10) / mpuGyroLSB, v(12) / mpuGyroLSB},
+	}, nil
+}
+
+// ============================================================================
+// ST LSM6DS3 (also LSM6DS3TR-C, LSM6DSL, LSM6DSO)
+// ============================================================================
+
+type lsm6ds3 struct{}
+
+const (
+	lsmWhoAmI  = 0x0F
+	lsmCtrl1XL = 0x10
+	lsmCtrl2G  = 0x11
+	lsmCtrl3C  = 0x12
+	lsmOutXLG  = 0x22
+
+	lsmAccelG = 0.122e-3 // g per LSB at ±4 g
+	lsmGyroD  = 17.5e-3  // °/s per LSB at ±500 °/s
+)
+
+func (lsm6ds3) name() string     { return "LSM6DS3" }
+func (lsm6ds3) defaultAddr() int { return 0x6A }
+
+func (lsm6ds3) setup(d *i2cDev, rateHz int) error {
+	id, err := d.readRegs(lsmWhoAmI, 1)
+	if err != nil {
+		return err
+	}
+	switch id[0] {
+	case 0x69, 0x6A, 0x6C: // LSM6DS3, LSM6DS3TR-C/LSM6DSL, LSM6DSO
+	default:
+		return fmt.Errorf("unexpected WHO_AM_I 0x%02x", id[0])
+	}
+	odr := byte(0x40) // 104 Hz
+	if rateHz > 104 {
+		odr = 0x50 // 208 Hz
+	}
+	for _, w := range [][2]byte{
+		{lsmCtrl3C, 0x44},        // Block data update, register auto-increment
+		{lsmCtrl1XL, odr | 0x08}, // ±4 g
+		{lsmCtrl2G, odr | 0x04},  // ±500 °/s
+	} {
+		if err := d.writeReg(w[0], w[1]); err != nil {
+			return err
+		}
+	}
+	time.Sleep(50 * time.Millisecond) // First samples after power-up are invalid
+	return nil
+}
+
+func (lsm6ds3) read(d *i2cDev) (Sample, error) {
+	b, err := d.readRegs(lsmOutXLG, 12) // Gyro XYZ, accel XYZ
+	if err != nil {
+		return Sample{}, err
+	}
+	v := func(i int) float64 { return float64(int16(binary.LittleEndian.Uint16(b[i:]))) }
+	return Sample{
+		Accel: [3]float64{v(6) * lsmAccelG, v(8) * lsmAccelG, v(10) * lsmAccelG},
+		Gyro:  [3]float64{v(0) * lsmGyroD, v(2) * lsmGyroD, v(4) * lsmGyroD},
+	}, nil
+}
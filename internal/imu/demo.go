@@ -0,0 +1,47 @@
+package imu
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Demo simulates an IMU in a car lapping a loop: alternating corners,
+// acceleration out of them and braking into them.
+type Demo struct {
+	mu     sync.Mutex
+	filter *Filter
+	t      float64
+}
+
+// NewDemo returns a simulated IMU, run through the real filter so the
+// output behaves like the hardware's.
+func NewDemo() *Demo {
+	m, _ := ParseMount("x", "y")
+	f := NewFilter(m)
+	f.Calibrate([]Sample{{Accel: [3]float64{0, 0, 1}}}) // Parked level
+	return &Demo{filter: f}
+}
+
+func (d *Demo) Name() string   { return "Demo IMU (Simulated)" }
+func (d *Demo) Connect() error { return nil }
+func (d *Demo) Close() error   { return nil }
+
+func (d *Demo) Read() (*Data, error) {
+	time.Sleep(10 * time.Millisecond) // 100 Hz
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.t += 0.01
+
+	lat := 0.8 * math.Sin(d.t*0.4)         // g, + = right
+	long := 0.4 * math.Cos(d.t*0.8)        // g
+	yaw := lat * 9.81 / 25 * 180 / math.Pi // °/s at ~25 m/s
+	noise := func() float64 { return (rand.Float64() - 0.5) * 0.04 }
+	s := Sample{
+		Accel: [3]float64{long + noise(), -lat + noise(), 1 + noise()},
+		Gyro:  [3]float64{noise(), noise(), -yaw + noise()},
+	}
+	out := d.filter.Update(time.Now(), s)
+	return &out, nil
+}
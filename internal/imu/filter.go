@@ -0,0 +1,141 @@
+package imu
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// tiltTau is the complementary filter's time constant: the gyro is
+	// trusted over shorter spans, the accelerometer's gravity over longer.
+	// Long, so a launch or a long brake zone barely moves the estimate.
+	tiltTau = 10 * time.Second
+	// The accelerometer only corrects the tilt while it plausibly shows
+	// just gravity: close to 1 g and not turning.
+	tiltGateG   = 0.05
+	tiltGateDps = 3.0
+	// outputTau smooths the G outputs against engine vibration (~8 Hz).
+	outputTau = 20 * time.Millisecond
+)
+
+// Filter turns raw samples into vehicle-frame Data: gyro bias removed,
+// roll and pitch tracked by a complementary filter, gravity subtracted.
+type Filter struct {
+	mount    Mount
+	bias     [3]float64 // Gyro bias, vehicle frame, °/s
+	roll     float64    // rad, right side down = +
+	pitch    float64    // rad, nose up = +
+	primed   bool       // Tilt initialised
+	lastAt   time.Time
+	smoothed [3]float64 // Linear acceleration, vehicle frame, g
+}
+
+// NewFilter returns a filter for the given mounting.
+func NewFilter(m Mount) *Filter {
+	return &Filter{mount: m}
+}
+
+// Calibrate sets the gyro bias and initial tilt from samples taken at
+// rest. It reports false, changing nothing, if they show the car moving.
+func (f *Filter) Calibrate(samples []Sample) bool {
+	if len(samples) == 0 {
+		return false
+	}
+	var acc, gyr [3]float64
+	for _, s := range samples {
+		a, g := f.mount.apply(s.Accel), f.mount.apply(s.Gyro)
+		for i := range acc {
+			acc[i] += a[i]
+			gyr[i] += g[i]
+		}
+	}
+	n := float64(len(samples))
+	for i := range acc {
+		acc[i] /= n
+		gyr[i] /= n
+	}
+	for _, s := range samples {
+		g := f.mount.apply(s.Gyro)
+		for i := range g {
+			if math.Abs(g[i]-gyr[i]) > 3 {
+				return false // Turning or vibrating hard
+			}
+		}
+	}
+	if math.Abs(norm(acc)-1) > tiltGateG {
+		return false
+	}
+	f.bias = gyr
+	f.roll, f.pitch = tiltOf(acc)
+	f.primed = true
+	return true
+}
+
+// tiltOf returns the roll and pitch (rad) at which gravity reads as a.
+func tiltOf(a [3]float64) (roll, pitch float64) {
+	pitch = math.Atan2(a[0], math.Hypot(a[1], a[2]))
+	roll = math.Atan2(a[1], a[2])
+	return roll, pitch
+}
+
+func norm(v [3]float64) float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
+
+// Update feeds one sample taken at now and returns the filtered data.
+func (f *Filter) Update(now time.Time, s Sample) Data {
+	a := f.mount.apply(s.Accel)
+	g := f.mount.apply(s.Gyro)
+	for i := range g {
+		g[i] -= f.bias[i]
+	}
+	dt := now.Sub(f.lastAt).Seconds()
+	f.lastAt = now
+	if dt <= 0 || dt > 1 {
+		dt = 0 // First sample, or after a gap: nothing to integrate over
+	}
+
+	if !f.primed {
+		f.roll, f.pitch = tiltOf(a)
+		f.primed = true
+	} else if dt > 0 {
+		// Right-hand rotation about forward drops the right side; about
+		// left it drops the nose
+		f.roll += g[0] * dt * math.Pi / 180
+		f.pitch -= g[1] * dt * math.Pi / 180
+		if math.Abs(norm(a)-1) < tiltGateG && math.Abs(g[2]) < tiltGateDps {
+			k := dt / (tiltTau.Seconds() + dt)
+			r, p := tiltOf(a)
+			f.roll += k * (r - f.roll)
+			f.pitch += k * (p - f.pitch)
+		}
+	}
+
+	// Gravity in the vehicle frame at the current tilt, subtracted from
+	// the measured specific force
+	sr, cr := math.Sincos(f.roll)
+	sp, cp := math.Sincos(f.pitch)
+	lin := [3]float64{a[0] - sp, a[1] - sr*cp, a[2] - cr*cp}
+	k := 1.0
+	if dt > 0 {
+		k = dt / (outputTau.Seconds() + dt)
+	}
+	for i := range lin {
+		f.smoothed[i] += k * (lin[i] - f.smoothed[i])
+	}
+
+	return Data{
+		LongG:   round(f.smoothed[0], 3),
+		LatG:    round(-f.smoothed[1], 3),
+		VertG:   round(f.smoothed[2], 3),
+		YawRate: round(-g[2], 2),
+		Roll:    round(f.roll*180/math.Pi, 1),
+		Pitch:   round(f.pitch*180/math.Pi, 1),
+		Stamp:   now.UnixMilli(),
+	}
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p)/p + 0 // No "-0" in JSON
+}
@@ -0,0 +1,124 @@
+package imu
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// calibrationSamples are averaged at connect for the gyro bias (~1 s).
+const calibrationSamples = 100
+
+// I2C is an IMU on an I2C bus.
+type I2C struct {
+	cfg    Config
+	chip   chip
+	filter *Filter
+
+	mu   sync.Mutex
+	dev  *i2cDev
+	next time.Time // When the next sample is due
+}
+
+// NewI2C returns a provider for the chip cfg.Chip on cfg.Bus.
+func NewI2C(cfg Config) (*I2C, error) {
+	c, err := newChip(cfg.Chip)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseMount(cfg.Forward, cfg.Left)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Bus == "" {
+		cfg.Bus = "/dev/i2c-1"
+	}
+	if cfg.Address == 0 {
+		cfg.Address = c.defaultAddr()
+	}
+	if cfg.RateHz <= 0 {
+		cfg.RateHz = 100
+	}
+	return &I2C{cfg: cfg, chip: c, filter: NewFilter(m)}, nil
+}
+
+func (p *I2C) Name() string {
+	return fmt.Sprintf("%s at 0x%02x on %s", p.chip.name(), p.cfg.Address, p.cfg.Bus)
+}
+
+// Connect opens and configures the chip, then calibrates the gyro bias.
+// If the car is moving the bias from the last calibration is kept.
+func (p *I2C) Connect() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked()
+
+	d, err := openI2C(p.cfg.Bus, p.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("imu: open %s: %w", p.cfg.Bus, err)
+	}
+	if err := p.chip.setup(d, p.cfg.RateHz); err != nil {
+		d.Close()
+		return fmt.Errorf("imu: %s setup: %w", p.chip.name(), err)
+	}
+
+	interval := time.Second / time.Duration(p.cfg.RateHz)
+	samples := make([]Sample, 0, calibrationSamples)
+	for len(samples) < calibrationSamples {
+		s, err := p.chip.read(d)
+		if err != nil {
+			d.Close()
+			return fmt.Errorf("imu: %s read: %w", p.chip.name(), err)
+		}
+		samples = append(samples, s)
+		time.Sleep(interval)
+	}
+	if p.filter.Calibrate(samples) {
+		log.Printf("[imu] calibrated at rest")
+	} else {
+		log.Printf("[imu] moving at connect, gyro not calibrated")
+	}
+
+	p.dev = d
+	p.next = time.Now()
+	return nil
+}
+
+func (p *I2C) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked()
+	return nil
+}
+
+func (p *I2C) closeLocked() {
+	if p.dev != nil {
+		p.dev.Close()
+		p.dev = nil
+	}
+}
+
+// Read waits for the next sample time, then reads and filters a sample.
+func (p *I2C) Read() (*Data, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dev == nil {
+		return nil, fmt.Errorf("imu: not connected")
+	}
+
+	interval := time.Second / time.Duration(p.cfg.RateHz)
+	p.next = p.next.Add(interval)
+	if wait := time.Until(p.next); wait > 0 {
+		time.Sleep(wait)
+	} else if wait < -interval {
+		p.next = time.Now() // Fell behind; don't try to catch up
+	}
+
+	s, err := p.chip.read(p.dev)
+	if err != nil {
+		return nil, fmt.Errorf("imu: %s read: %w", p.chip.name(), err)
+	}
+	d := p.filter.Update(time.Now(), s)
+	return &d, nil
+}
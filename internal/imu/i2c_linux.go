@@ -0,0 +1,50 @@
+//go:build linux
+
+package imu
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const i2cSlave = 0x0703 // linux/i2c-dev.h
+
+// i2cDev is one device on an open /dev/i2c-N bus.
+type i2cDev struct {
+	f *os.File
+}
+
+// openI2C opens bus and addresses the device at addr.
+func openI2C(bus string, addr int) (*i2cDev, error) {
+	f, err := os.OpenFile(bus, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.IoctlSetInt(int(f.Fd()), i2cSlave, addr); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("set address 0x%02x: %w", addr, err)
+	}
+	return &i2cDev{f: f}, nil
+}
+
+// writeReg writes one register.
+func (d *i2cDev) writeReg(reg, val byte) error {
+	_, err := d.f.Write([]byte{reg, val})
+	return err
+}
+
+// readRegs reads n registers starting at reg.
+func (d *i2cDev) readRegs(reg byte, n int) ([]byte, error) {
+	if _, err := d.f.Write([]byte{reg}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := d.f.Read(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (d *i2cDev) Close() error { return d.f.Close() }
@@ -0,0 +1,97 @@
+// Package imu reads a 3-axis accelerometer/gyro (MPU6050 or LSM6DS3 on
+// the Pi's I2C bus) and turns it into vehicle-frame G and yaw rate.
+// Mounting tilt and body roll/pitch are tracked with a complementary
+// filter so gravity doesn't leak into lateral and longitudinal G, which
+// GPS-derived G is too laggy to show.
+package imu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Provider is the interface for IMU sources.
+type Provider interface {
+	Name() string
+	Connect() error
+	Close() error
+	// Read blocks until the next sample is due and returns the filtered
+	// vehicle-frame data.
+	Read() (*Data, error)
+}
+
+// Data is the vehicle-frame motion at one instant. G values have
+// gravity removed: a car at rest on a slope reads zero.
+type Data struct {
+	LatG    float64 `json:"latG"`    // + = accelerating to the right (right-hand corner)
+	LongG   float64 `json:"longG"`   // + = accelerating, - = braking
+	VertG   float64 `json:"vertG"`   // + = up (compression)
+	YawRate float64 `json:"yawRate"` // °/s, + = turning right (heading increasing)
+	Roll    float64 `json:"roll"`    // °, + = right side down
+	Pitch   float64 `json:"pitch"`   // °, + = nose up
+	Stamp   int64   `json:"stamp"`   // Unix ms
+}
+
+// Config holds IMU configuration.
+type Config struct {
+	Chip    string // "mpu6050" or "lsm6ds3"
+	Bus     string // I2C device, e.g. /dev/i2c-1
+	Address int    // 7-bit address; 0 = the chip's default
+	RateHz  int    // Sample rate (default 100)
+
+	// Mounting: which chip axis points forward and which to the left,
+	// e.g. "x" and "y", or "-y" and "x" for a board turned 90°
+	Forward string
+	Left    string
+}
+
+// Mount maps chip axes to the vehicle frame.
+type Mount struct {
+	fwd, left, up [3]float64 // Unit vectors in chip axes
+}
+
+var axisVec = map[string][3]float64{
+	"x": {1, 0, 0}, "-x": {-1, 0, 0},
+	"y": {0, 1, 0}, "-y": {0, -1, 0},
+	"z": {0, 0, 1}, "-z": {0, 0, -1},
+}
+
+// ParseMount builds a Mount from the forward and left chip axes (default
+// "x" and "y"); up follows from them.
+func ParseMount(forward, left string) (Mount, error) {
+	if forward == "" {
+		forward = "x"
+	}
+	if left == "" {
+		left = "y"
+	}
+	f, ok := axisVec[strings.ToLower(strings.TrimPrefix(forward, "+"))]
+	if !ok {
+		return Mount{}, fmt.Errorf("imu: bad forward axis %q", forward)
+	}
+	l, ok := axisVec[strings.ToLower(strings.TrimPrefix(left, "+"))]
+	if !ok {
+		return Mount{}, fmt.Errorf("imu: bad left axis %q", left)
+	}
+	if f[0]*l[0]+f[1]*l[1]+f[2]*l[2] != 0 {
+		return Mount{}, fmt.Errorf("imu: forward %q and left %q are the same axis", forward, left)
+	}
+	up := [3]float64{
+		f[1]*l[2] - f[2]*l[1],
+		f[2]*l[0] - f[0]*l[2],
+		f[0]*l[1] - f[1]*l[0],
+	}
+	return Mount{fwd: f, left: l, up: up}, nil
+}
+
+// apply rotates a chip-axis vector into (forward, left, up).
+func (m Mount) apply(v [3]float64) [3]float64 {
+	dot := func(a [3]float64) float64 { return a[0]*v[0] + a[1]*v[1] + a[2]*v[2] }
+	return [3]float64{dot(m.fwd), dot(m.left), dot(m.up)}
+}
+
+// Sample is one raw reading in chip axes.
+type Sample struct {
+	Accel [3]float64 // g
+	Gyro  [3]float64 // °/s
+}
@@ -0,0 +1,440 @@
+// Package influx writes data points to InfluxDB as line protocol, over the
+// v2 HTTP write API or as UDP datagrams (an InfluxDB 1.x or Telegraf UDP
+// listener).
+//
+// Points are batched in memory and written every flush interval. A batch
+// that can't be written is spooled to disk and retried, oldest first,
+// once writes succeed again, so a drive out of WiFi range is uploaded on
+// the way back into the garage.
+package influx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds sink configuration.
+type Config struct {
+	URL         string            // http(s)://host:8086 for the v2 API, or udp://host:8089
+	Org         string            // v2 organisation
+	Bucket      string            // v2 bucket
+	Token       string            // v2 API token
+	Measurement string            // Default "goefidash"
+	Tags        map[string]string // Added to every point (e.g. car=miata)
+	Interval    time.Duration     // Between points (default 1 s)
+	Flush       time.Duration     // Between writes (default 5 s)
+	BatchSize   int               // Points per write; a full batch is written early (default 500)
+	SpoolDir    string            // Batches waiting for the database; "" = drop them
+	SpoolBytes  int64             // Oldest spooled batches are dropped past this (default 64 MB)
+}
+
+// Stats summarises the sink's progress, for diagnostics.
+type Stats struct {
+	Points      uint64 `json:"points"`     // Written
+	Batches     uint64 `json:"batches"`    // Written, including spooled ones
+	Failures    uint64 `json:"failures"`   // Writes that failed
+	Dropped     uint64 `json:"dropped"`    // Points discarded: rejected, or spool over its limit
+	Pending     int    `json:"pending"`    // Points waiting for the next write
+	SpoolFiles  int    `json:"spoolFiles"` // Batches spooled to disk
+	SpoolBytes  int64  `json:"spoolBytes"`
+	LastError   string `json:"lastError,omitempty"`
+	LastWritten int64  `json:"lastWritten,omitempty"` // Unix ms
+}
+
+const (
+	udpPayload    = 1400 // Datagram size limit, under a typical MTU
+	spoolPerFlush = 8    // Spooled batches retried per flush, so the backlog doesn't hold up live data
+	writeTimeout  = 15 * time.Second
+)
+
+// errRejected is a batch the database refused as malformed; retrying it
+// won't help.
+var errRejected = errors.New("rejected")
+
+// Sink batches points and writes them to InfluxDB.
+type Sink struct {
+	cfg    Config
+	write  func(ctx context.Context, body []byte) error
+	client *http.Client
+	udp    net.Conn
+	prefix string // Escaped measurement and tags
+	last   time.Time
+	kick   chan struct{}
+
+	mu      sync.Mutex
+	pending [][]byte
+	stats   Stats
+}
+
+// New creates a sink. Nothing is sent until Run.
+func New(cfg Config) (*Sink, error) {
+	if cfg.Measurement == "" {
+		cfg.Measurement = "goefidash"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Flush <= 0 {
+		cfg.Flush = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.SpoolBytes <= 0 {
+		cfg.SpoolBytes = 64 << 20
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("influx: %w", err)
+	}
+	s := &Sink{cfg: cfg, kick: make(chan struct{}, 1)}
+	switch u.Scheme {
+	case "http", "https":
+		if cfg.Bucket == "" {
+			return nil, fmt.Errorf("influx: no bucket configured")
+		}
+		s.client = &http.Client{Timeout: writeTimeout}
+		s.write = s.writeHTTP
+	case "udp":
+		s.write = s.writeUDP
+	default:
+		return nil, fmt.Errorf("influx: unknown scheme %q (http, https or udp)", u.Scheme)
+	}
+
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(cfg.Measurement))
+	keys := make([]string, 0, len(cfg.Tags))
+	for k := range cfg.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // Tag order as InfluxDB stores it
+	for _, k := range keys {
+		if cfg.Tags[k] == "" {
+			continue
+		}
+		b.WriteString("," + keyEscaper.Replace(k) + "=" + keyEscaper.Replace(cfg.Tags[k]))
+	}
+	s.prefix = b.String()
+
+	if cfg.SpoolDir != "" {
+		files, _ := s.spooled()
+		if len(files) > 0 {
+			log.Printf("[influx] %d batch(es) spooled from before, to send", len(files))
+		}
+	}
+	return s, nil
+}
+
+// Due reports whether a point is wanted at now.
+func (s *Sink) Due(now time.Time) bool {
+	return now.Sub(s.last) >= s.cfg.Interval
+}
+
+// Record queues a point of fields at t. NaN and infinite values are left
+// out; InfluxDB can't store them.
+func (s *Sink) Record(t time.Time, fields map[string]float64) {
+	s.last = t
+	names := make([]string, 0, len(fields))
+	for k, v := range fields {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			names = append(names, k)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+
+	line := []byte(s.prefix)
+	for i, k := range names {
+		if i == 0 {
+			line = append(line, ' ')
+		} else {
+			line = append(line, ',')
+		}
+		line = append(line, keyEscaper.Replace(k)...)
+		line = append(line, '=')
+		line = strconv.AppendFloat(line, fields[k], 'g', -1, 64)
+	}
+	line = append(line, ' ')
+	line = strconv.AppendInt(line, t.UnixNano(), 10)
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	s.pending = append(s.pending, line)
+	full := len(s.pending) >= s.cfg.BatchSize
+	s.mu.Unlock()
+	if full {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run writes batches until ctx ends, then spools whatever is left.
+func (s *Sink) Run(ctx context.Context) {
+	if s.cfg.URL != "" {
+		log.Printf("[influx] writing to %s every %v", s.cfg.URL, s.cfg.Flush)
+	}
+	ticker := time.NewTicker(s.cfg.Flush)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			batch := s.take()
+			s.mu.Unlock()
+			if len(batch) > 0 {
+				s.spool(batch)
+			}
+			if s.udp != nil {
+				s.udp.Close()
+			}
+			return
+		case <-ticker.C:
+		case <-s.kick:
+		}
+		s.flush(ctx)
+	}
+}
+
+// Stats returns the sink's counters.
+func (s *Sink) Stats() Stats {
+	s.mu.Lock()
+	st := s.stats
+	st.Pending = len(s.pending)
+	s.mu.Unlock()
+	files, size := s.spooled()
+	st.SpoolFiles, st.SpoolBytes = len(files), size
+	return st
+}
+
+// take removes and returns the pending points as one body. Called with
+// mu held.
+func (s *Sink) take() []byte {
+	var b []byte
+	for _, line := range s.pending {
+		b = append(b, line...)
+	}
+	s.pending = nil
+	return b
+}
+
+// flush writes the pending batch, then retries spooled ones while writes
+// keep succeeding.
+func (s *Sink) flush(ctx context.Context) {
+	s.mu.Lock()
+	batch := s.take()
+	s.mu.Unlock()
+
+	if len(batch) > 0 {
+		if err := s.send(ctx, batch); err != nil {
+			if !errors.Is(err, errRejected) {
+				s.spool(batch)
+			}
+			return
+		}
+	}
+	if s.cfg.SpoolDir == "" {
+		return
+	}
+	files, _ := s.spooled()
+	for i, name := range files {
+		if i == spoolPerFlush || ctx.Err() != nil {
+			break
+		}
+		path := filepath.Join(s.cfg.SpoolDir, name)
+		body, err := os.ReadFile(path)
+		if err == nil {
+			err = s.send(ctx, body)
+			if err != nil && !errors.Is(err, errRejected) {
+				return // Still failing; keep it for next time
+			}
+		}
+		os.Remove(path)
+	}
+}
+
+// send writes body and records the outcome. Rejected points count as
+// dropped.
+func (s *Sink) send(ctx context.Context, body []byte) error {
+	n := uint64(bytes.Count(body, []byte{'\n'}))
+	err := s.write(ctx, body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err != nil:
+		if s.stats.LastError == "" {
+			log.Printf("[influx] write failed: %v", err)
+		}
+		s.stats.Failures++
+		s.stats.LastError = err.Error()
+		if errors.Is(err, errRejected) {
+			s.stats.Dropped += n
+		}
+	case s.stats.LastError != "":
+		log.Printf("[influx] writing again")
+		s.stats.LastError = ""
+	}
+	if err == nil {
+		s.stats.Points += n
+		s.stats.Batches++
+		s.stats.LastWritten = time.Now().UnixMilli()
+	}
+	return err
+}
+
+// writeHTTP posts body, gzipped, to the v2 write API.
+func (s *Sink) writeHTTP(ctx context.Context, body []byte) error {
+	var zb bytes.Buffer
+	zw := gzip.NewWriter(&zb)
+	zw.Write(body)
+	zw.Close()
+
+	q := url.Values{"bucket": {s.cfg.Bucket}, "precision": {"ns"}}
+	if s.cfg.Org != "" {
+		q.Set("org", s.cfg.Org)
+	}
+	u := strings.TrimSuffix(s.cfg.URL, "/") + "/api/v2/write?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, &zb)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Encoding", "gzip")
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Token "+s.cfg.Token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusRequestEntityTooLarge {
+		// Malformed or a field type conflict; auth and bucket errors
+		// are kept, since fixing the config makes them writable
+		return fmt.Errorf("%w: %v", errRejected, err)
+	}
+	return err
+}
+
+// writeUDP sends body in datagrams of whole lines. UDP has no replies, so
+// only local errors (no route while the WiFi is down) are seen.
+func (s *Sink) writeUDP(ctx context.Context, body []byte) error {
+	if s.udp == nil {
+		u, _ := url.Parse(s.cfg.URL)
+		conn, err := (&net.Dialer{}).DialContext(ctx, "udp", u.Host)
+		if err != nil {
+			return err
+		}
+		s.udp = conn
+	}
+	for len(body) > 0 {
+		n := len(body)
+		if n > udpPayload {
+			n = bytes.LastIndexByte(body[:udpPayload], '\n') + 1
+			if n == 0 {
+				n = bytes.IndexByte(body, '\n') + 1 // One oversized line
+			}
+		}
+		if _, err := s.udp.Write(body[:n]); err != nil {
+			s.udp.Close()
+			s.udp = nil
+			return err
+		}
+		body = body[n:]
+	}
+	return nil
+}
+
+// spool saves a batch that couldn't be written, then drops the oldest
+// batches while the spool is over its limit.
+func (s *Sink) spool(batch []byte) {
+	if s.cfg.SpoolDir == "" {
+		s.mu.Lock()
+		s.stats.Dropped += uint64(bytes.Count(batch, []byte{'\n'}))
+		s.mu.Unlock()
+		return
+	}
+	if err := os.MkdirAll(s.cfg.SpoolDir, 0755); err != nil {
+		log.Printf("[influx] %v", err)
+		return
+	}
+	name := filepath.Join(s.cfg.SpoolDir, fmt.Sprintf("%d.lp", time.Now().UnixNano()))
+	if err := os.WriteFile(name, batch, 0644); err != nil {
+		log.Printf("[influx] spool: %v", err)
+		return
+	}
+
+	files, size := s.spooled()
+	for _, f := range files {
+		if size <= s.cfg.SpoolBytes {
+			break
+		}
+		path := filepath.Join(s.cfg.SpoolDir, f)
+		body, err := os.ReadFile(path)
+		if err != nil || os.Remove(path) != nil {
+			break
+		}
+		size -= int64(len(body))
+		s.mu.Lock()
+		s.stats.Dropped += uint64(bytes.Count(body, []byte{'\n'}))
+		s.mu.Unlock()
+		log.Printf("[influx] spool over %d MB, dropped %s", s.cfg.SpoolBytes>>20, f)
+	}
+}
+
+// spooled lists the spooled batches, oldest first, and their total size.
+func (s *Sink) spooled() ([]string, int64) {
+	if s.cfg.SpoolDir == "" {
+		return nil, 0
+	}
+	entries, err := os.ReadDir(s.cfg.SpoolDir)
+	if err != nil {
+		return nil, 0
+	}
+	var names []string
+	var size int64
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".lp") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			size += info.Size()
+		}
+		names = append(names, e.Name())
+	}
+	// Names are Unix ns of equal length until 2286, so they sort by age
+	sort.Strings(names)
+	return names, size
+}
+
+// Line protocol escaping: measurement names, and tag keys, tag values and
+// field keys.
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
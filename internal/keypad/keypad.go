@@ -0,0 +1,208 @@
+// Package keypad reads CAN keypads (Blink Marine PKP series and keypads
+// speaking the same CANopen layout, such as Grayhill's CANopen models)
+// over SocketCAN, reporting key presses and setting the key LEDs.
+package keypad
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/canbus"
+)
+
+// Provider is the interface for keypads.
+type Provider interface {
+	Name() string
+	Connect() error
+	Close() error
+	// Read blocks until keys change and returns the changes, or returns
+	// none after a read timeout so the caller can refresh the LEDs.
+	Read() ([]Event, error)
+	// SetLEDs sets every key's LED, leds[0] being key 1.
+	SetLEDs(leds []LED) error
+}
+
+// Event is a key going down or up. Keys are numbered from 1, left to
+// right and top to bottom as printed on the keypad.
+type Event struct {
+	Key     int
+	Pressed bool
+}
+
+// LED is a key's light, as a mix of the keypad's colours.
+type LED uint8
+
+const (
+	LEDOff   LED = 0
+	LEDRed   LED = 1
+	LEDGreen LED = 2
+	LEDBlue  LED = 4
+	LEDAmber     = LEDRed | LEDGreen
+	LEDWhite     = LEDRed | LEDGreen | LEDBlue
+)
+
+// ParseLED returns the LED for a colour name.
+func ParseLED(name string) (LED, error) {
+	switch name {
+	case "", "off":
+		return LEDOff, nil
+	case "red":
+		return LEDRed, nil
+	case "green":
+		return LEDGreen, nil
+	case "blue":
+		return LEDBlue, nil
+	case "amber", "yellow":
+		return LEDAmber, nil
+	case "white":
+		return LEDWhite, nil
+	}
+	return LEDOff, fmt.Errorf("keypad: unknown colour %q", name)
+}
+
+// Config holds keypad configuration.
+type Config struct {
+	Interface string // SocketCAN interface, e.g. can0 (bitrate set with ip link)
+	NodeID    int    // CANopen node ID (PKP default 0x15)
+	Keys      int    // Number of keys, up to 16 (default 12)
+}
+
+// CANopen messages used, by function code; the node ID is added.
+const (
+	cobNMT       = 0x000 // Network management (no node ID)
+	cobTPDO1     = 0x180 // Key states from the keypad
+	cobRPDO1     = 0x200 // LED states to the keypad
+	cobHeartbeat = 0x700 // Boot-up and heartbeat
+
+	nmtStart = 0x01
+
+	readTimeout = 500 * time.Millisecond
+)
+
+// PKP is a keypad using the Blink Marine PKP CANopen layout: key states
+// as a bitmask (key 1 in bit 0 of byte 0) in TPDO1, and LEDs as red,
+// green and blue bitmasks of the same width in RPDO1.
+type PKP struct {
+	cfg Config
+
+	mu   sync.Mutex
+	bus  *canbus.Bus
+	keys uint16 // Last key states
+}
+
+// NewPKP returns a keypad on cfg's interface.
+func NewPKP(cfg Config) *PKP {
+	if cfg.NodeID == 0 {
+		cfg.NodeID = 0x15
+	}
+	if cfg.Keys <= 0 || cfg.Keys > 16 {
+		cfg.Keys = 12
+	}
+	return &PKP{cfg: cfg}
+}
+
+func (k *PKP) Name() string {
+	return fmt.Sprintf("PKP keypad on %s (node 0x%02x)", k.cfg.Interface, k.cfg.NodeID)
+}
+
+// Connect opens the CAN interface and starts the keypad, which stays
+// pre-operational (no key messages) until told to start.
+func (k *PKP) Connect() error {
+	bus, err := canbus.Open(k.cfg.Interface)
+	if err != nil {
+		return fmt.Errorf("keypad: %s: %w", k.cfg.Interface, err)
+	}
+	k.mu.Lock()
+	k.bus, k.keys = bus, 0
+	k.mu.Unlock()
+	return k.start()
+}
+
+// start sends the NMT start command.
+func (k *PKP) start() error {
+	return k.write(cobNMT, []byte{nmtStart, byte(k.cfg.NodeID)})
+}
+
+func (k *PKP) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.bus == nil {
+		return nil
+	}
+	err := k.bus.Close()
+	k.bus = nil
+	return err
+}
+
+func (k *PKP) Read() ([]Event, error) {
+	k.mu.Lock()
+	bus := k.bus
+	k.mu.Unlock()
+	if bus == nil {
+		return nil, fmt.Errorf("keypad: not connected")
+	}
+	deadline := time.Now().Add(readTimeout)
+	for {
+		id, data, err := bus.Read(deadline)
+		if err != nil {
+			if canbus.IsTimeout(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		switch int(id) {
+		case cobHeartbeat + k.cfg.NodeID:
+			if len(data) > 0 && data[0] == 0 { // Boot-up: power-cycled
+				if err := k.start(); err != nil {
+					return nil, err
+				}
+			}
+		case cobTPDO1 + k.cfg.NodeID:
+			var keys uint16
+			for i := 0; i < len(data) && i < 2; i++ {
+				keys |= uint16(data[i]) << (8 * i)
+			}
+			keys &= 1<<k.cfg.Keys - 1
+			k.mu.Lock()
+			changed := keys ^ k.keys
+			k.keys = keys
+			k.mu.Unlock()
+			var out []Event
+			for i := 0; i < k.cfg.Keys; i++ {
+				if changed&(1<<i) != 0 {
+					out = append(out, Event{Key: i + 1, Pressed: keys&(1<<i) != 0})
+				}
+			}
+			if len(out) > 0 {
+				return out, nil
+			}
+		}
+	}
+}
+
+func (k *PKP) SetLEDs(leds []LED) error {
+	n := (k.cfg.Keys + 7) / 8 // Bytes per colour
+	data := make([]byte, 8)
+	for i, led := range leds {
+		if i >= k.cfg.Keys {
+			break
+		}
+		for c, colour := range []LED{LEDRed, LEDGreen, LEDBlue} {
+			if led&colour != 0 {
+				data[c*n+i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+	return k.write(cobRPDO1+k.cfg.NodeID, data)
+}
+
+func (k *PKP) write(id int, data []byte) error {
+	k.mu.Lock()
+	bus := k.bus
+	k.mu.Unlock()
+	if bus == nil {
+		return fmt.Errorf("keypad: not connected")
+	}
+	return bus.Write(uint32(id), data)
+}
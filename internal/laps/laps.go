@@ -0,0 +1,245 @@
+// Package laps implements circuit lap timing: laps start and end at a
+// start/finish line, with the crossing time interpolated between GPS
+// fixes, and optional sector splits.
+package laps
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// DefaultMinLap is the shortest lap accepted when none is configured. A
+// second crossing sooner than this is GPS jitter at the line, not a lap.
+const DefaultMinLap = 10 * time.Second
+
+// MaxLaps is how many laps are kept; older ones are dropped.
+const MaxLaps = 500
+
+// Lap is one completed lap.
+type Lap struct {
+	Number  int     `json:"number"`            // 1 = first timed lap of the session
+	Track   string  `json:"track,omitempty"`   // Track name when the lap was driven
+	Start   int64   `json:"start"`             // Unix ms, interpolated line crossing
+	TimeMs  int64   `json:"timeMs"`            // Lap time
+	Sectors []int64 `json:"sectors,omitempty"` // Sector times, ms, in order (last ends at the line)
+}
+
+// Status is the live lap timing state sent to clients.
+type Status struct {
+	Timing    bool    `json:"timing"`              // A lap is being timed (the line has been crossed)
+	Lap       int     `json:"lap"`                 // Number of the lap in progress (0 = out lap)
+	Count     int     `json:"count"`               // Completed laps
+	CurrentMs int64   `json:"currentMs,omitempty"` // Time so far in the current lap
+	Sectors   []int64 `json:"sectors,omitempty"`   // Sector times so far in the current lap
+	DistanceM float64 `json:"distanceM,omitempty"` // Distance so far in the current lap
+	Last      *Lap    `json:"last,omitempty"`
+	Best      *Lap    `json:"best,omitempty"` // Fastest lap on the current track
+}
+
+// Timer tracks the lap in progress and the lap list. Update is called
+// from the broadcast loop; everything else from HTTP handlers.
+type Timer struct {
+	mu     sync.Mutex
+	minLap time.Duration
+	laps   []Lap
+	next   int
+
+	track     string    // Name of the track being timed
+	start     time.Time // Crossing that started the current lap (zero = out lap)
+	sectorAt  time.Time // Start of the current sector
+	sector    int       // Next sector line expected
+	splits    []int64
+	prevPos   track.Point
+	prevTime  time.Time
+	havePrev  bool
+	lineCross time.Time // Last start/finish crossing, for debouncing
+
+	dist  float64 // Metres since the start line
+	trace *Trace  // Current lap's channels
+}
+
+// Sample is one broadcast tick's worth of vehicle state.
+type Sample struct {
+	Pos     *track.Point       // nil without a valid GPS fix
+	Heading float64            // Degrees true
+	Values  map[string]float64 // TraceChannels values, recorded into the lap trace
+}
+
+// New returns a timer. minLap <= 0 uses DefaultMinLap.
+func New(minLap time.Duration) *Timer {
+	if minLap <= 0 {
+		minLap = DefaultMinLap
+	}
+	return &Timer{minLap: minLap, next: 1}
+}
+
+// Restore replaces the lap list (e.g. loaded from disk). Numbering
+// carries on from the highest lap.
+func (t *Timer) Restore(laps []Lap) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.laps = laps
+	t.next = 1
+	for _, l := range laps {
+		if l.Number >= t.next {
+			t.next = l.Number + 1
+		}
+	}
+}
+
+// Update feeds one fix. A nil smp.Pos leaves the lap running: the next
+// fix picks up from the last good one. sf is the start/finish line (nil =
+// not timing); name labels completed laps. It returns the lap this update
+// completed, if any, with its trace.
+func (t *Timer) Update(now time.Time, smp Sample, name string, sf *track.Line, sectors []track.Line) (*Lap, *Trace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pos, heading := smp.Pos, smp.Heading
+
+	if sf == nil || name != t.track {
+		// Line removed or a different track: whatever was running isn't a lap
+		t.track = name
+		t.start = time.Time{}
+	}
+	if pos == nil || sf == nil {
+		return nil, nil
+	}
+	if !t.havePrev || *pos == t.prevPos {
+		if !t.havePrev {
+			t.prevPos, t.prevTime, t.havePrev = *pos, now, true
+		}
+		return nil, nil
+	}
+	a, at := t.prevPos, t.prevTime
+	t.prevPos, t.prevTime = *pos, now
+
+	at2 := func(frac float64) time.Time {
+		return at.Add(time.Duration(frac * float64(now.Sub(at))))
+	}
+
+	// Sector lines, in order, while a lap is running
+	if !t.start.IsZero() && t.sector < len(sectors) {
+		if ok, frac := sectors[t.sector].Crossing(a, *pos, heading); ok {
+			c := at2(frac)
+			t.splits = append(t.splits, c.Sub(t.sectorAt).Milliseconds())
+			t.sectorAt = c
+			t.sector++
+		}
+	}
+
+	seg := track.DistanceM(a, *pos)
+	ok, frac := sf.Crossing(a, *pos, heading)
+	c := at2(frac)
+	if ok && !t.lineCross.IsZero() && c.Sub(t.lineCross) < t.minLap {
+		ok = false
+	}
+	if !ok {
+		if !t.start.IsZero() {
+			t.dist += seg
+			t.trace.Add(t.dist, now.Sub(t.start).Milliseconds(), smp.Values)
+		}
+		return nil, nil
+	}
+	t.lineCross = c
+
+	var done *Lap
+	var trace *Trace
+	if !t.start.IsZero() {
+		t.dist += frac * seg
+		t.trace.Add(t.dist, c.Sub(t.start).Milliseconds(), smp.Values)
+		trace = t.trace
+		lap := Lap{
+			Number: t.next,
+			Track:  name,
+			Start:  t.start.UnixMilli(),
+			TimeMs: c.Sub(t.start).Milliseconds(),
+		}
+		if len(sectors) > 0 {
+			lap.Sectors = append(t.splits, c.Sub(t.sectorAt).Milliseconds())
+		}
+		t.next++
+		t.laps = append(t.laps, lap)
+		if len(t.laps) > MaxLaps {
+			t.laps = t.laps[len(t.laps)-MaxLaps:]
+		}
+		done = &lap
+	}
+	t.start, t.sectorAt, t.sector, t.splits = c, c, 0, nil
+
+	// The new lap starts at the line, part-way through this segment
+	t.dist = (1 - frac) * seg
+	t.trace = NewTrace(TraceChannels...)
+	t.trace.Add(0, 0, smp.Values)
+	t.trace.Add(t.dist, now.Sub(c).Milliseconds(), smp.Values)
+	return done, trace
+}
+
+// Status returns the live state.
+func (t *Timer) Status(now time.Time) Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := Status{Timing: !t.start.IsZero(), Count: len(t.laps)}
+	if st.Timing {
+		st.Lap = t.next
+		st.CurrentMs = now.Sub(t.start).Milliseconds()
+		st.Sectors = append([]int64(nil), t.splits...)
+		st.DistanceM = math.Round(t.dist)
+	}
+	if n := len(t.laps); n > 0 {
+		last := t.laps[n-1]
+		st.Last = &last
+	}
+	for i := range t.laps {
+		l := t.laps[i]
+		if l.Track != t.track {
+			continue
+		}
+		if st.Best == nil || l.TimeMs < st.Best.TimeMs {
+			st.Best = &l
+		}
+	}
+	return st
+}
+
+// Position returns the distance and time into the lap being timed; ok is
+// false on an out lap.
+func (t *Timer) Position(now time.Time) (distM float64, elapsedMs int64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.start.IsZero() {
+		return 0, 0, false
+	}
+	return t.dist, now.Sub(t.start).Milliseconds(), true
+}
+
+// Active reports whether there's anything to show: a lap being timed or
+// laps recorded.
+func (t *Timer) Active() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.start.IsZero() || len(t.laps) > 0
+}
+
+// Laps returns a copy of the lap list, oldest first.
+func (t *Timer) Laps() []Lap {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Lap(nil), t.laps...)
+}
+
+// Clear deletes all laps and abandons the lap in progress; timing starts
+// again at the next line crossing.
+func (t *Timer) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.laps = nil
+	t.next = 1
+	t.start = time.Time{}
+	t.lineCross = time.Time{}
+	t.splits = nil
+	t.dist, t.trace = 0, nil
+}
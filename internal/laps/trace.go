@@ -0,0 +1,138 @@
+package laps
+
+import (
+	"math"
+	"sort"
+)
+
+// Trace channels recorded with every lap.
+var TraceChannels = []string{"speed", "rpm", "tps", "map", "afr", "gear", "lat", "lon"}
+
+// Trace is a run's channels indexed by distance, stored column-wise:
+// sample i is DistM[i], TimeMs[i] and Channels[name][i]. DistM is
+// non-decreasing.
+type Trace struct {
+	DistM    []float64            `json:"distM"`  // From the start line
+	TimeMs   []int64              `json:"timeMs"` // From the start line
+	Channels map[string][]float64 `json:"channels"`
+}
+
+// Overlay is a trace resampled at a fixed distance step, for drawing a
+// past run against a live one.
+type Overlay struct {
+	StepM    float64              `json:"stepM"`
+	DistM    []float64            `json:"distM"`
+	TimeMs   []int64              `json:"timeMs"`
+	Channels map[string][]float64 `json:"channels"`
+}
+
+// TracePoint is one point of a trace at a given distance.
+type TracePoint struct {
+	DistM    float64            `json:"distM"`
+	TimeMs   int64              `json:"timeMs"`
+	Channels map[string]float64 `json:"channels,omitempty"`
+}
+
+// NewTrace returns an empty trace recording the named channels.
+func NewTrace(channels ...string) *Trace {
+	t := &Trace{Channels: make(map[string][]float64, len(channels))}
+	for _, c := range channels {
+		t.Channels[c] = nil
+	}
+	return t
+}
+
+// Len returns the number of samples.
+func (t *Trace) Len() int { return len(t.DistM) }
+
+// Add appends a sample; channels missing from vals are recorded as 0.
+func (t *Trace) Add(distM float64, timeMs int64, vals map[string]float64) {
+	t.DistM = append(t.DistM, distM)
+	t.TimeMs = append(t.TimeMs, timeMs)
+	for c := range t.Channels {
+		t.Channels[c] = append(t.Channels[c], vals[c])
+	}
+}
+
+// At interpolates the trace at distM. ok is false for an empty trace or
+// a distance past its end.
+func (t *Trace) At(distM float64, channels []string) (p TracePoint, ok bool) {
+	n := t.Len()
+	if n == 0 || distM > t.DistM[n-1] {
+		return TracePoint{}, false
+	}
+	i, frac := t.locate(distM)
+	p = TracePoint{DistM: distM, TimeMs: lerpInt(t.TimeMs, i, frac)}
+	if len(channels) > 0 {
+		p.Channels = make(map[string]float64, len(channels))
+		for _, c := range channels {
+			if col, ok := t.Channels[c]; ok {
+				p.Channels[c] = lerp(col, i, frac)
+			}
+		}
+	}
+	return p, true
+}
+
+// Resample returns the named channels (all if none) every stepM metres.
+// Unknown channel names are left out.
+func (t *Trace) Resample(stepM float64, channels []string) Overlay {
+	if stepM <= 0 {
+		stepM = 5
+	}
+	if len(channels) == 0 {
+		for c := range t.Channels {
+			channels = append(channels, c)
+		}
+		sort.Strings(channels)
+	}
+	o := Overlay{StepM: stepM, Channels: make(map[string][]float64, len(channels))}
+	n := t.Len()
+	if n == 0 {
+		return o
+	}
+	for d := t.DistM[0]; d <= t.DistM[n-1]; d += stepM {
+		i, frac := t.locate(d)
+		o.DistM = append(o.DistM, math.Round(d*10)/10)
+		o.TimeMs = append(o.TimeMs, lerpInt(t.TimeMs, i, frac))
+		for _, c := range channels {
+			if col, ok := t.Channels[c]; ok {
+				o.Channels[c] = append(o.Channels[c], math.Round(lerp(col, i, frac)*1000)/1000)
+			}
+		}
+	}
+	return o
+}
+
+// locate returns the sample at or before distM and how far (0..1) distM
+// is towards the next.
+func (t *Trace) locate(distM float64) (int, float64) {
+	n := t.Len()
+	i := sort.SearchFloat64s(t.DistM, distM) // First >= distM
+	if i >= n {
+		return n - 1, 0
+	}
+	if i == 0 || t.DistM[i] == distM {
+		return i, 0
+	}
+	i--
+	span := t.DistM[i+1] - t.DistM[i]
+	if span <= 0 {
+		return i, 0
+	}
+	return i, (distM - t.DistM[i]) / span
+}
+
+func lerp(col []float64, i int, frac float64) float64 {
+	if frac == 0 || i+1 >= len(col) {
+		return col[i]
+	}
+	return col[i] + frac*(col[i+1]-col[i])
+}
+
+func lerpInt(col []int64, i int, frac float64) int64 {
+	if frac == 0 || i+1 >= len(col) {
+		return col[i]
+	}
+	return col[i] + int64(math.Round(frac*float64(col[i+1]-col[i])))
+}
@@ -6,24 +6,41 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
 	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/imu"
 )
 
 // Logger records timestamped ECU + GPS data to CSV files with automatic rotation.
 type Logger struct {
 	mu       sync.Mutex
 	dir      string
-	interval time.Duration
+	interval time.Duration // Row interval: the fastest column's
+	base     time.Duration // Configured row interval, for columns without a rate
+	slow     time.Duration // Longer row interval while set (off-peak logging)
 	enabled  bool
+	prefix   string        // File name prefix
+	offset   time.Duration // Added to the system clock for names and timestamps
+	colCfg   []column      // Per csvHeader column, then per aux column
+	auxCfg   map[string]ChannelConfig
 
 	file   *os.File
 	writer *csv.Writer
 	lastTs time.Time
 	rows   int
+
+	channels ecu.ChannelMask // ECU channels the current file has columns for
+	aux      []string        // Aux channel columns of the current file, after csvHeader's
+	cols     []int           // Row indexes written, in order
+
+	// Raw NMEA sentences (gps.raw_log), in their own file
+	nmea      *os.File
+	nmeaLines int
 }
 
 // Config holds logger configuration.
@@ -31,10 +48,37 @@ type Config struct {
 	Enabled    bool   `yaml:"enabled" json:"enabled"`
 	Path       string `yaml:"path" json:"path"`
 	IntervalMs int    `yaml:"interval_ms" json:"intervalMs"`
+	Prefix     string `yaml:"-" json:"-"` // File name prefix (default "speeduino")
+
+	// Per-column overrides, keyed by CSV column name (e.g. "afr") or aux
+	// channel name
+	Channels map[string]ChannelConfig `yaml:"channels" json:"channels"`
+}
+
+// ChannelConfig overrides one column's logging rate and precision. A
+// column slower than the rows is left empty in rows where it isn't due;
+// one faster than IntervalMs speeds up the rows.
+type ChannelConfig struct {
+	RateHz   float64 `yaml:"rate_hz" json:"rateHz"`    // 0 = IntervalMs
+	Decimals *int    `yaml:"decimals" json:"decimals"` // Digits after the point; nil = default
+}
+
+// Slip is driven-wheel slip against GPS ground speed.
+type Slip struct {
+	Percent   float64
+	Wheelspin bool
+}
+
+// column is a CSV column's rate and precision.
+type column struct {
+	interval time.Duration
+	decimals int // -1 = default formatting
+	last     time.Time
 }
 
 const (
-	maxRowsPerFile = 100_000 // Rotate after 100k rows (~2.7 hrs at 10 Hz)
+	maxRowsPerFile = 100_000   // Rotate after 100k rows (~2.7 hrs at 10 Hz)
+	maxNMEAPerFile = 1_000_000 // Raw NMEA lines per file (~3.5 hrs at 10 Hz)
 )
 
 var csvHeader = []string{
@@ -47,8 +91,33 @@ var csvHeader = []string{
 	"fan_on", "sync", "running",
 	"gps_valid", "gps_lat", "gps_lon", "gps_speed_kph",
 	"gps_heading", "gps_alt_m", "gps_sats",
+	"egt1_c", "egt2_c", "egt3_c", "egt4_c",
+	"egt5_c", "egt6_c", "egt7_c", "egt8_c",
+	"lat_g", "long_g", "vert_g", "yaw_dps",
+	"slip_pct", "wheelspin",
+}
+
+// csvChannel is the DataFrame channel behind each ECU column of
+// csvHeader, for leaving out pruned channels. Columns past the end are
+// GPS, EGT, IMU and slip and always written.
+var csvChannel = []string{
+	"", "rpm", "map", "tps", "afr", "lambda",
+	"coolant", "iat", "advance", "batteryVoltage",
+	"pulseWidth1", "pulseWidth2", "dutyCycle", "veCurr",
+	"boostTarget", "boostDuty", "vss", "gear",
+	"fuelPressure", "oilPressure", "dwell",
+	"egoCorrection", "warmupEnrich", "gammaEnrich",
+	"fanStatus", "sync", "running",
 }
 
+// egtCol, imuCol and slipCol are the first EGT, IMU and slip columns in
+// csvHeader.
+const (
+	egtCol  = 34
+	imuCol  = 42
+	slipCol = 46
+)
+
 // New creates a new Logger.
 func New(cfg Config) *Logger {
 	if cfg.Path == "" {
@@ -58,13 +127,51 @@ func New(cfg Config) *Logger {
 	if interval < 50*time.Millisecond {
 		interval = 100 * time.Millisecond // Default 10 Hz
 	}
-	return &Logger{
+	l := &Logger{
 		dir:      cfg.Path,
 		interval: interval,
+		base:     interval,
 		enabled:  cfg.Enabled,
+		colCfg:   make([]column, len(csvHeader)),
+		auxCfg:   make(map[string]ChannelConfig),
+	}
+	l.SetPrefix(cfg.Prefix)
+	idx := make(map[string]int, len(csvHeader))
+	for i, h := range csvHeader {
+		idx[h] = i
+		l.colCfg[i] = column{interval: interval, decimals: -1}
+	}
+	for name, cc := range cfg.Channels {
+		if cc.RateHz > 0 {
+			l.interval = min(l.interval, time.Duration(float64(time.Second)/cc.RateHz))
+		}
+		switch i, ok := idx[name]; {
+		case i == 0 && ok:
+			log.Printf("[logger] can't set the timestamp column in logging.channels")
+		case !ok:
+			l.auxCfg[name] = cc // An aux channel, known once it shows up
+		default:
+			l.colCfg[i] = l.column(cc)
+		}
+	}
+	return l
+}
+
+// column resolves a column's rate and precision.
+func (l *Logger) column(cc ChannelConfig) column {
+	c := column{interval: l.base, decimals: -1}
+	if cc.RateHz > 0 {
+		c.interval = time.Duration(float64(time.Second) / cc.RateHz)
+	}
+	if cc.Decimals != nil && *cc.Decimals >= 0 {
+		c.decimals = *cc.Decimals
 	}
+	return c
 }
 
+// Dir returns the directory log files are written to.
+func (l *Logger) Dir() string { return l.dir }
+
 // SetEnabled allows toggling logging at runtime.
 func (l *Logger) SetEnabled(on bool) {
 	l.mu.Lock()
@@ -75,6 +182,40 @@ func (l *Logger) SetEnabled(on bool) {
 	}
 }
 
+// SetPrefix sets the file name prefix (e.g. the car's name) for files
+// opened from now on. "" restores the default.
+func (l *Logger) SetPrefix(p string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if p == "" {
+		p = "speeduino"
+	}
+	l.prefix = p
+}
+
+// SetClockOffset sets a correction added to the system clock for file
+// names and row timestamps, for when the clock is wrong and can't be set
+// (e.g. no RTC, no network). A new file is started so its name uses the
+// corrected time (raw NMEA too); call with 0 after the system clock has
+// been fixed.
+func (l *Logger) SetClockOffset(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.offset = d
+	l.closeFile()
+	l.closeNMEA()
+}
+
+// SetSlowInterval stretches the row interval to d while d is longer than
+// the configured one, for logging at a low rate when nothing interesting
+// is expected; 0 restores the configured rate. Columns keep their own
+// slower rates.
+func (l *Logger) SetSlowInterval(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.slow = d
+}
+
 // IsEnabled returns whether logging is active.
 func (l *Logger) IsEnabled() bool {
 	l.mu.Lock()
@@ -82,8 +223,9 @@ func (l *Logger) IsEnabled() bool {
 	return l.enabled
 }
 
-// Record writes an ECU + GPS snapshot if the minimum interval has elapsed.
-func (l *Logger) Record(ecuData *ecu.DataFrame, gpsData *gps.Data) {
+// Record writes an ECU + GPS (+ per-cylinder EGT, IMU, slip) snapshot if
+// the minimum interval has elapsed. egt, imuData and slip may be nil.
+func (l *Logger) Record(ecuData *ecu.DataFrame, gpsData *gps.Data, egt []float64, imuData *imu.Data, slip *Slip) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -91,12 +233,25 @@ func (l *Logger) Record(ecuData *ecu.DataFrame, gpsData *gps.Data) {
 		return
 	}
 
-	now := time.Now()
-	if now.Sub(l.lastTs) < l.interval {
+	now := time.Now().Add(l.offset)
+	if now.Sub(l.lastTs) < max(l.interval, l.slow) {
 		return
 	}
 	l.lastTs = now
 
+	// A frame with a different channel set, or a new aux channel, needs a
+	// new header. Aux channels that drop out keep their (empty) columns.
+	if ecuData != nil && ecuData.Channels != l.channels {
+		l.channels = ecuData.Channels
+		l.closeFile()
+	}
+	if ecuData != nil {
+		if aux, added := addAux(l.aux, ecuData.Aux); added {
+			l.aux = aux
+			l.closeFile()
+		}
+	}
+
 	// Open/rotate file if needed
 	if l.writer == nil || l.rows >= maxRowsPerFile {
 		if err := l.rotateFile(now); err != nil {
@@ -105,8 +260,11 @@ func (l *Logger) Record(ecuData *ecu.DataFrame, gpsData *gps.Data) {
 		}
 	}
 
-	row := l.buildRow(now, ecuData, gpsData)
-	if err := l.writer.Write(row); err != nil {
+	row := l.buildRow(now, ecuData, gpsData, egt, imuData, slip)
+	if !l.thin(now, row) {
+		return // No column due
+	}
+	if err := l.writer.Write(pick(row, l.cols)); err != nil {
 		log.Printf("[logger] write failed: %v", err)
 		return
 	}
@@ -114,11 +272,53 @@ func (l *Logger) Record(ecuData *ecu.DataFrame, gpsData *gps.Data) {
 	l.rows++
 }
 
-// Close flushes and closes the current log file.
+// NMEA appends a raw GPS sentence, as received, to a .nmea file next to
+// the CSV logs, named like them. It's independent of Enabled, so the raw
+// stream can be kept for debugging a GPS parser on its own.
+func (l *Logger) NMEA(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.nmea == nil || l.nmeaLines >= maxNMEAPerFile {
+		l.closeNMEA()
+		if err := os.MkdirAll(l.dir, 0755); err != nil {
+			log.Printf("[logger] mkdir %s: %v", l.dir, err)
+			return
+		}
+		now := time.Now().Add(l.offset)
+		path := filepath.Join(l.dir, fmt.Sprintf("%s_%s.nmea", l.prefix, now.Format("2006-01-02_150405")))
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("[logger] create %s: %v", path, err)
+			return
+		}
+		l.nmea, l.nmeaLines = f, 0
+		markOpen(path)
+		log.Printf("[logger] opened %s", path)
+	}
+	if _, err := l.nmea.WriteString(line + "\r\n"); err != nil {
+		log.Printf("[logger] nmea write failed: %v", err)
+		l.closeNMEA()
+		return
+	}
+	l.nmeaLines++
+}
+
+// Close flushes and closes the current log files.
 func (l *Logger) Close() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.closeFile()
+	l.closeNMEA()
+}
+
+func (l *Logger) closeNMEA() {
+	if l.nmea != nil {
+		if l.nmea.Close() == nil {
+			markClosed(l.nmea.Name())
+		}
+		l.nmea = nil
+	}
 }
 
 func (l *Logger) rotateFile(now time.Time) error {
@@ -128,8 +328,15 @@ func (l *Logger) rotateFile(now time.Time) error {
 		return fmt.Errorf("mkdir %s: %w", l.dir, err)
 	}
 
-	filename := fmt.Sprintf("speeduino_%s.csv", now.Format("2006-01-02_150405"))
+	filename := fmt.Sprintf("%s_%s.csv", l.prefix, now.Format("2006-01-02_150405"))
 	path := filepath.Join(l.dir, filename)
+	for i := 2; ; i++ {
+		// A channel change can reopen within the same second
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		path = filepath.Join(l.dir, fmt.Sprintf("%s_%s_%d.csv", l.prefix, now.Format("2006-01-02_150405"), i))
+	}
 
 	f, err := os.Create(path)
 	if err != nil {
@@ -139,9 +346,30 @@ func (l *Logger) rotateFile(now time.Time) error {
 	l.file = f
 	l.writer = csv.NewWriter(f)
 	l.rows = 0
+	markOpen(path)
+
+	// Every column starts each file with a value
+	l.colCfg = l.colCfg[:len(csvHeader)]
+	for i := range l.colCfg {
+		l.colCfg[i].last = time.Time{}
+	}
+	for _, name := range l.aux {
+		l.colCfg = append(l.colCfg, l.column(l.auxCfg[name]))
+	}
+
+	l.cols = l.cols[:0]
+	for i := range csvHeader {
+		if i >= len(csvChannel) || csvChannel[i] == "" || l.channels.Has(csvChannel[i]) {
+			l.cols = append(l.cols, i)
+		}
+	}
+	for i := range l.aux {
+		l.cols = append(l.cols, len(csvHeader)+i)
+	}
 
 	// Write header
-	if err := l.writer.Write(csvHeader); err != nil {
+	header := append(append([]string(nil), csvHeader...), l.aux...)
+	if err := l.writer.Write(pick(header, l.cols)); err != nil {
 		return err
 	}
 	l.writer.Flush()
@@ -156,13 +384,15 @@ func (l *Logger) closeFile() {
 		l.writer = nil
 	}
 	if l.file != nil {
-		l.file.Close()
+		if l.file.Close() == nil {
+			markClosed(l.file.Name())
+		}
 		l.file = nil
 	}
 }
 
-func (l *Logger) buildRow(ts time.Time, e *ecu.DataFrame, g *gps.Data) []string {
-	row := make([]string, len(csvHeader))
+func (l *Logger) buildRow(ts time.Time, e *ecu.DataFrame, g *gps.Data, egt []float64, m *imu.Data, sl *Slip) []string {
+	row := make([]string, len(csvHeader)+len(l.aux))
 
 	row[0] = ts.Format(time.RFC3339Nano)
 
@@ -193,6 +423,11 @@ func (l *Logger) buildRow(ts time.Time, e *ecu.DataFrame, g *gps.Data) []string
 		row[24] = boolStr(e.FanStatus)
 		row[25] = boolStr(e.Sync)
 		row[26] = boolStr(e.Running)
+		for i, name := range l.aux {
+			if v, ok := e.Aux[name]; ok {
+				row[len(csvHeader)+i] = strconv.FormatFloat(v, 'f', 2, 64)
+			}
+		}
 	}
 
 	if g != nil {
@@ -205,9 +440,85 @@ func (l *Logger) buildRow(ts time.Time, e *ecu.DataFrame, g *gps.Data) []string
 		row[33] = fmt.Sprintf("%d", g.Satellites)
 	}
 
+	for i, v := range egt {
+		if egtCol+i >= len(row) {
+			break
+		}
+		if v != 0 {
+			row[egtCol+i] = fmt.Sprintf("%.0f", v)
+		}
+	}
+
+	if m != nil {
+		row[imuCol] = fmt.Sprintf("%.3f", m.LatG)
+		row[imuCol+1] = fmt.Sprintf("%.3f", m.LongG)
+		row[imuCol+2] = fmt.Sprintf("%.3f", m.VertG)
+		row[imuCol+3] = fmt.Sprintf("%.1f", m.YawRate)
+	}
+
+	if sl != nil {
+		row[slipCol] = fmt.Sprintf("%.1f", sl.Percent)
+		row[slipCol+1] = boolStr(sl.Wheelspin)
+	}
+
 	return row
 }
 
+// thin applies per-column rates and precision to the written columns of
+// row, in place: columns not yet due are blanked. It reports whether any
+// column was due. The timestamp column is always kept.
+func (l *Logger) thin(now time.Time, row []string) bool {
+	due := false
+	for _, i := range l.cols {
+		if i == 0 {
+			continue
+		}
+		c := &l.colCfg[i]
+		// Half a row of slack, so a column at the row rate isn't skipped
+		// by tick jitter
+		if !c.last.IsZero() && now.Sub(c.last) < c.interval-l.interval/2 {
+			row[i] = ""
+			continue
+		}
+		c.last = now
+		due = true
+		if c.decimals >= 0 && row[i] != "" {
+			if v, err := strconv.ParseFloat(row[i], 64); err == nil {
+				row[i] = strconv.FormatFloat(v, 'f', c.decimals, 64)
+			}
+		}
+	}
+	return due
+}
+
+// addAux returns cols with any of aux's channels it's missing added in
+// name order, and whether there were any.
+func addAux(cols []string, aux map[string]float64) ([]string, bool) {
+	var added []string
+	for name := range aux {
+		if !slices.Contains(cols, name) {
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return cols, false
+	}
+	slices.Sort(added)
+	return append(slices.Clip(cols), added...), true
+}
+
+// pick returns row's columns at cols.
+func pick(row []string, cols []int) []string {
+	if len(cols) == len(row) {
+		return row
+	}
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = row[c]
+	}
+	return out
+}
+
 func boolStr(v bool) string {
 	if v {
 		return "1"
@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A log file has an openSuffix marker next to it while it's being
+// written; one left over at startup means it wasn't closed cleanly
+// (usually power pulled with the ignition). Recovery repairs the file and
+// replaces the marker with a recoveredSuffix summary.
+const (
+	openSuffix      = ".open"
+	recoveredSuffix = ".recovered"
+	recoverTail     = 64 << 10 // Bytes from the end checked for damage
+)
+
+// Recovered summarises a log repaired after an unclean shutdown.
+type Recovered struct {
+	Rows    int    `json:"rows"`            // Data rows kept
+	Dropped int64  `json:"dropped"`         // Bytes of partial rows or garbage removed from the end
+	First   string `json:"first,omitempty"` // First row's timestamp (CSV)
+	Last    string `json:"last,omitempty"`  // Last row's timestamp (CSV)
+	At      int64  `json:"at"`              // Unix ms of the recovery
+}
+
+// FileInfo is one log file in the log directory.
+type FileInfo struct {
+	Name      string     `json:"name"`
+	Size      int64      `json:"size"`
+	Modified  int64      `json:"modified"`            // Unix ms
+	Open      bool       `json:"open,omitempty"`      // Being written now
+	Recovered *Recovered `json:"recovered,omitempty"` // Repaired after an unclean shutdown
+}
+
+// markOpen flags path as being written.
+func markOpen(path string) {
+	if err := os.WriteFile(path+openSuffix, nil, 0644); err != nil {
+		log.Printf("[logger] %v", err)
+	}
+}
+
+// markClosed clears the flag once path has been closed cleanly.
+func markClosed(path string) {
+	if err := os.Remove(path + openSuffix); err != nil && !os.IsNotExist(err) {
+		log.Printf("[logger] %v", err)
+	}
+}
+
+// Recover repairs logs that weren't closed cleanly: trailing garbage and
+// a partial last row are cut off, and a summary is written next to each.
+// Call it before anything is logged.
+func (l *Logger) Recover() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	markers, err := filepath.Glob(filepath.Join(l.dir, "*"+openSuffix))
+	if err != nil {
+		return
+	}
+	for _, m := range markers {
+		path := strings.TrimSuffix(m, openSuffix)
+		rec, err := recoverFile(path)
+		if err != nil {
+			log.Printf("[logger] recovering %s: %v", path, err)
+			os.Remove(m)
+			continue
+		}
+		data, _ := json.Marshal(rec)
+		if err := os.WriteFile(path+recoveredSuffix, data, 0644); err != nil {
+			log.Printf("[logger] %v", err)
+			continue
+		}
+		os.Remove(m)
+		log.Printf("[logger] recovered %s: %d rows, %d bytes dropped", filepath.Base(path), rec.Rows, rec.Dropped)
+	}
+}
+
+// recoverFile repairs and summarises one log.
+func recoverFile(path string) (*Recovered, error) {
+	valid := validNMEA
+	if strings.HasSuffix(path, ".csv") {
+		fields, err := headerFields(path)
+		if err != nil {
+			return nil, err
+		}
+		valid = func(line []byte) bool { return validCSV(line, fields) }
+	}
+	dropped, err := repairTail(path, valid)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := summarise(path)
+	if err != nil {
+		return nil, err
+	}
+	rec.Dropped = dropped
+	rec.At = time.Now().UnixMilli()
+	return rec, nil
+}
+
+// repairTail truncates path after its last valid line. Unwritten blocks
+// (NULs), a partial line and complete lines valid rejects are removed,
+// looking back at most recoverTail bytes. It returns the bytes removed.
+func repairTail(path string, valid func(line []byte) bool) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := st.Size()
+	off := max(size-recoverTail, 0)
+	buf := make([]byte, size-off)
+	if _, err := f.ReadAt(buf, off); err != nil {
+		return 0, err
+	}
+
+	end := len(buf)
+	for end > 0 && buf[end-1] == 0 {
+		end--
+	}
+	for end > 0 {
+		if buf[end-1] != '\n' {
+			end = bytes.LastIndexByte(buf[:end], '\n') + 1
+			continue
+		}
+		start := bytes.LastIndexByte(buf[:end-1], '\n') + 1
+		if start == 0 && off > 0 {
+			break // Line starts before the checked tail; assume it's fine
+		}
+		if valid(bytes.TrimSuffix(buf[start:end-1], []byte("\r"))) {
+			break
+		}
+		end = start
+	}
+
+	keep := off + int64(end)
+	if keep == size {
+		return 0, nil
+	}
+	if err := f.Truncate(keep); err != nil {
+		return 0, err
+	}
+	return size - keep, f.Sync()
+}
+
+// headerFields returns the column count of a CSV log's header.
+func headerFields(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil {
+		return 0, fmt.Errorf("no complete header")
+	}
+	return strings.Count(line, ",") + 1, nil
+}
+
+// validCSV reports whether line is a whole CSV row of fields columns.
+func validCSV(line []byte, fields int) bool {
+	if bytes.IndexByte(line, 0) >= 0 {
+		return false
+	}
+	rec, err := csv.NewReader(bytes.NewReader(line)).Read()
+	return err == nil && len(rec) == fields
+}
+
+// validNMEA reports whether line looks like a whole NMEA sentence.
+func validNMEA(line []byte) bool {
+	return len(line) > 0 && (line[0] == '$' || line[0] == '!') && bytes.IndexByte(line, 0) < 0 &&
+		(bytes.IndexByte(line, '*') < 0 || bytes.IndexByte(line, '*') == len(line)-3)
+}
+
+// summarise counts a repaired log's rows; for CSV, the header isn't one,
+// and the first and last timestamps are noted.
+func summarise(path string) (*Recovered, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	isCSV := strings.HasSuffix(path, ".csv")
+	rec := &Recovered{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 4096), 1<<20)
+	for n := 0; sc.Scan(); n++ {
+		if !isCSV {
+			rec.Rows++
+			continue
+		}
+		if n == 0 {
+			continue // Header
+		}
+		rec.Rows++
+		ts, _, _ := strings.Cut(sc.Text(), ",")
+		if rec.First == "" {
+			rec.First = ts
+		}
+		rec.Last = ts
+	}
+	return rec, sc.Err()
+}
+
+// List returns the log files (CSV and raw NMEA), newest first, noting
+// which are being written and which were recovered.
+func (l *Logger) List() ([]FileInfo, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []FileInfo{}, nil
+		}
+		return nil, err
+	}
+	l.mu.Lock()
+	var open []string
+	for _, f := range []*os.File{l.file, l.nmea} {
+		if f != nil {
+			open = append(open, filepath.Base(f.Name()))
+		}
+	}
+	l.mu.Unlock()
+
+	out := []FileInfo{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || (!strings.HasSuffix(name, ".csv") && !strings.HasSuffix(name, ".nmea")) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		fi := FileInfo{Name: name, Size: info.Size(), Modified: info.ModTime().UnixMilli()}
+		for _, o := range open {
+			fi.Open = fi.Open || o == name
+		}
+		if data, err := os.ReadFile(filepath.Join(l.dir, name+recoveredSuffix)); err == nil {
+			var rec Recovered
+			if json.Unmarshal(data, &rec) == nil {
+				fi.Recovered = &rec
+			}
+		}
+		out = append(out, fi)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Modified > out[j].Modified })
+	return out, nil
+}
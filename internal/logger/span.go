@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Span is the rows logged over a stretch of time, across however many
+// files they were written to, under one set of columns.
+type Span struct {
+	Columns  []string // Every column of the files, without the timestamp
+	from, to time.Time
+	files    []spanFile
+}
+
+type spanFile struct {
+	path  string
+	first time.Time // First row's timestamp
+}
+
+// Span finds the CSV logs with rows between from and to. Known columns
+// come in csvHeader order, then aux channels in the order first seen.
+func (l *Logger) Span(from, to time.Time) (*Span, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	sp := &Span{from: from, to: to}
+	var aux []string
+	known := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".csv") {
+			continue
+		}
+		if info, err := e.Info(); err != nil || info.ModTime().Before(from) {
+			continue // Finished before the span began
+		}
+		path := filepath.Join(l.dir, e.Name())
+		header, first, err := logStart(path)
+		if err != nil || first.After(to) {
+			continue
+		}
+		sp.files = append(sp.files, spanFile{path: path, first: first})
+		for _, h := range header[1:] {
+			if slices.Contains(csvHeader, h) {
+				known[h] = true
+			} else if !slices.Contains(aux, h) {
+				aux = append(aux, h)
+			}
+		}
+	}
+	sort.Slice(sp.files, func(i, j int) bool { return sp.files[i].first.Before(sp.files[j].first) })
+	for _, h := range csvHeader[1:] {
+		if known[h] {
+			sp.Columns = append(sp.Columns, h)
+		}
+	}
+	sp.Columns = append(sp.Columns, aux...)
+	return sp, nil
+}
+
+// logStart returns a log's header and the timestamp of its first row.
+func logStart(path string) ([]string, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	row, err := r.Read()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, row[0])
+	return header, t, err
+}
+
+// Each calls fn with every row in the span, oldest first: its time and
+// its values in Columns order, "" where a file has no such column. row is
+// reused between calls. A row cut short by a power loss ends its file.
+func (sp *Span) Each(fn func(t time.Time, row []string) error) error {
+	row := make([]string, len(sp.Columns))
+	for _, sf := range sp.files {
+		if err := sp.eachIn(sf.path, row, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sp *Span) eachIn(path string, row []string, fn func(time.Time, []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil // Rotated away since Span
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil
+	}
+	cols := make([]int, len(header)) // Column of each field; -1 = timestamp
+	for i, h := range header {
+		cols[i] = slices.Index(sp.Columns, h)
+	}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil || len(rec) != len(header) {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, rec[0])
+		switch {
+		case err != nil || t.Before(sp.from):
+			continue
+		case t.After(sp.to):
+			return nil
+		}
+		clear(row)
+		for i, v := range rec[1:] {
+			if c := cols[i+1]; c >= 0 {
+				row[c] = v
+			}
+		}
+		if err := fn(t, row); err != nil {
+			return err
+		}
+	}
+}
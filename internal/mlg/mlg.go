@@ -0,0 +1,140 @@
+// Package mlg writes logs in MegaLogViewer's binary format (MLVLG,
+// format version 1), which MegaLogViewer and TunerStudio open directly
+// and which is far smaller than CSV for long sessions.
+//
+// Every field is written as a float32. All numbers are big-endian.
+package mlg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	headerSize = 22
+	fieldSize  = 55
+	nameSize   = 34
+	unitsSize  = 10
+	markerSize = 50
+
+	typeF32     = 7 // Field types 0-6 are the integer sizes
+	styleFloat  = 0 // Display style
+	blockRecord = 0
+	blockMarker = 1
+
+	maxFields    = math.MaxInt16 / 4 // Record length is an int16
+	defaultDigit = 2
+)
+
+// Field describes one logged channel.
+type Field struct {
+	Name   string // Up to 34 bytes
+	Units  string // Up to 10 bytes
+	Digits int    // Shown after the point; 0 = defaultDigit, -1 = none
+}
+
+// Writer writes one log: the header, then records and markers in time
+// order.
+type Writer struct {
+	w       *bufio.Writer
+	n       int // Fields per record
+	start   time.Time
+	counter byte
+	buf     []byte
+}
+
+// NewWriter writes the header for fields, with info as the log's
+// description. start is the log's time zero.
+func NewWriter(w io.Writer, start time.Time, fields []Field, info string) (*Writer, error) {
+	if len(fields) == 0 || len(fields) > maxFields {
+		return nil, fmt.Errorf("mlg: need 1-%d fields", maxFields)
+	}
+	infoStart := headerSize + fieldSize*len(fields)
+	dataStart := infoStart + len(info) + 1
+
+	bw := bufio.NewWriter(w)
+	h := make([]byte, 0, dataStart)
+	h = append(h, "MLVLG\x00"...)
+	h = binary.BigEndian.AppendUint16(h, 1) // Format version
+	h = binary.BigEndian.AppendUint32(h, uint32(start.Unix()))
+	h = binary.BigEndian.AppendUint16(h, uint16(infoStart))
+	h = binary.BigEndian.AppendUint32(h, uint32(dataStart))
+	h = binary.BigEndian.AppendUint16(h, uint16(4*len(fields))) // Record length
+	h = binary.BigEndian.AppendUint16(h, uint16(len(fields)))
+	for _, f := range fields {
+		digits := f.Digits
+		switch {
+		case digits == 0:
+			digits = defaultDigit
+		case digits < 0:
+			digits = 0
+		}
+		h = append(h, typeF32)
+		h = appendFixed(h, f.Name, nameSize)
+		h = appendFixed(h, f.Units, unitsSize)
+		h = append(h, styleFloat)
+		h = binary.BigEndian.AppendUint32(h, math.Float32bits(1)) // Scale
+		h = binary.BigEndian.AppendUint32(h, math.Float32bits(0)) // Transform
+		h = append(h, byte(int8(digits)))
+	}
+	h = append(h, info...)
+	h = append(h, 0)
+	if _, err := bw.Write(h); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw, n: len(fields), start: start, buf: make([]byte, 0, 5+4*len(fields))}, nil
+}
+
+// appendFixed appends s truncated or NUL-padded to n bytes.
+func appendFixed(b []byte, s string, n int) []byte {
+	if len(s) > n {
+		s = s[:n]
+	}
+	b = append(b, s...)
+	for i := len(s); i < n; i++ {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// block starts a block of type typ at t.
+func (w *Writer) block(typ byte, t time.Time) {
+	stamp := uint16(t.Sub(w.start).Microseconds() / 10) // 10 µs units, wrapping
+	w.buf = append(w.buf[:0], typ, w.counter)
+	w.buf = binary.BigEndian.AppendUint16(w.buf, stamp)
+	w.counter++
+}
+
+// Record writes one record of values, one per field.
+func (w *Writer) Record(t time.Time, values []float32) error {
+	if len(values) != w.n {
+		return errors.New("mlg: record has the wrong number of values")
+	}
+	w.block(blockRecord, t)
+	var sum byte
+	for _, v := range values {
+		w.buf = binary.BigEndian.AppendUint32(w.buf, math.Float32bits(v))
+	}
+	for _, b := range w.buf[4:] {
+		sum += b
+	}
+	w.buf = append(w.buf, sum)
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+// Marker writes a marker, shown as a labelled line across the graphs.
+func (w *Writer) Marker(t time.Time, msg string) error {
+	w.block(blockMarker, t)
+	w.buf = appendFixed(w.buf, msg, markerSize)
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+// Flush writes any buffered data.
+func (w *Writer) Flush() error { return w.w.Flush() }
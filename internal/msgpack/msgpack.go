@@ -0,0 +1,335 @@
+// Package msgpack converts JSON to MessagePack (msgpack.org), for clients
+// that take the dash's frames in the smaller binary encoding. The result
+// has the same structure as the JSON — objects become maps with their keys
+// in the same order, arrays stay arrays — so a client decodes it to the
+// objects it would get from JSON.
+//
+// Integers are encoded in the fewest bytes; other numbers as float32 when
+// that's exact and float64 otherwise.
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FromJSON converts a JSON document to MessagePack.
+func FromJSON(src []byte) ([]byte, error) {
+	t := transcoder{src: src}
+	out, err := t.value(make([]byte, 0, len(src)/2))
+	if err != nil {
+		return nil, err
+	}
+	t.space()
+	if t.pos < len(src) {
+		return nil, t.errorf("data after the value")
+	}
+	return out, nil
+}
+
+type transcoder struct {
+	src     []byte
+	pos     int
+	scratch []byte // Unescaped strings
+}
+
+func (t *transcoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("msgpack: offset %d: %s", t.pos, fmt.Sprintf(format, args...))
+}
+
+func (t *transcoder) space() {
+	for t.pos < len(t.src) {
+		switch t.src[t.pos] {
+		case ' ', '\t', '\r', '\n':
+			t.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value appends the next JSON value to dst.
+func (t *transcoder) value(dst []byte) ([]byte, error) {
+	t.space()
+	if t.pos >= len(t.src) {
+		return nil, t.errorf("unexpected end")
+	}
+	switch c := t.src[t.pos]; {
+	case c == '{':
+		return t.object(dst)
+	case c == '[':
+		return t.array(dst)
+	case c == '"':
+		return t.str(dst)
+	case c == '-' || c >= '0' && c <= '9':
+		return t.number(dst)
+	default:
+		for _, lit := range []struct {
+			s string
+			b byte
+		}{{"true", 0xc3}, {"false", 0xc2}, {"null", 0xc0}} {
+			if len(t.src)-t.pos >= len(lit.s) && string(t.src[t.pos:t.pos+len(lit.s)]) == lit.s {
+				t.pos += len(lit.s)
+				return append(dst, lit.b), nil
+			}
+		}
+		return nil, t.errorf("unexpected %q", c)
+	}
+}
+
+// object and array don't know their length until the end, so they reserve
+// the largest header and close the gap once it's known.
+func (t *transcoder) object(dst []byte) ([]byte, error) {
+	t.pos++ // {
+	at := len(dst)
+	dst = append(dst, 0, 0, 0, 0, 0)
+	n := 0
+	for first := true; ; first = false {
+		t.space()
+		if t.pos < len(t.src) && t.src[t.pos] == '}' {
+			t.pos++
+			return closeContainer(dst, at, n, 0x80, 0xde, 0xdf), nil
+		}
+		if !first {
+			if err := t.expect(','); err != nil {
+				return nil, err
+			}
+			t.space()
+		}
+		if t.pos >= len(t.src) || t.src[t.pos] != '"' {
+			return nil, t.errorf("expected a key")
+		}
+		var err error
+		if dst, err = t.str(dst); err != nil {
+			return nil, err
+		}
+		t.space()
+		if err := t.expect(':'); err != nil {
+			return nil, err
+		}
+		if dst, err = t.value(dst); err != nil {
+			return nil, err
+		}
+		n++
+	}
+}
+
+func (t *transcoder) array(dst []byte) ([]byte, error) {
+	t.pos++ // [
+	at := len(dst)
+	dst = append(dst, 0, 0, 0, 0, 0)
+	n := 0
+	for first := true; ; first = false {
+		t.space()
+		if t.pos < len(t.src) && t.src[t.pos] == ']' {
+			t.pos++
+			return closeContainer(dst, at, n, 0x90, 0xdc, 0xdd), nil
+		}
+		if !first {
+			if err := t.expect(','); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if dst, err = t.value(dst); err != nil {
+			return nil, err
+		}
+		n++
+	}
+}
+
+func (t *transcoder) expect(c byte) error {
+	if t.pos >= len(t.src) || t.src[t.pos] != c {
+		return t.errorf("expected %q", c)
+	}
+	t.pos++
+	return nil
+}
+
+// closeContainer writes the header for n entries into the 5 bytes
+// reserved at dst[at:], moving the entries down over what's left over.
+func closeContainer(dst []byte, at, n int, fix, b16, b32 byte) []byte {
+	var h []byte
+	switch {
+	case n < 16:
+		h = []byte{fix | byte(n)}
+	case n <= math.MaxUint16:
+		h = []byte{b16, byte(n >> 8), byte(n)}
+	default:
+		h = []byte{b32, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+	copy(dst[at:], h)
+	if gap := 5 - len(h); gap > 0 {
+		copy(dst[at+len(h):], dst[at+5:])
+		dst = dst[:len(dst)-gap]
+	}
+	return dst
+}
+
+// str appends the JSON string at t.pos as a MessagePack str.
+func (t *transcoder) str(dst []byte) ([]byte, error) {
+	t.pos++ // "
+	start := t.pos
+	for t.pos < len(t.src) {
+		switch t.src[t.pos] {
+		case '"':
+			s := t.src[start:t.pos]
+			t.pos++
+			return append(strHeader(dst, len(s)), s...), nil
+		case '\\':
+			return t.escaped(dst, start)
+		}
+		t.pos++
+	}
+	return nil, t.errorf("unterminated string")
+}
+
+// escaped finishes a string with escapes, from its start.
+func (t *transcoder) escaped(dst []byte, start int) ([]byte, error) {
+	s := append(t.scratch[:0], t.src[start:t.pos]...)
+	for t.pos < len(t.src) {
+		c := t.src[t.pos]
+		if c == '"' {
+			t.pos++
+			t.scratch = s
+			return append(strHeader(dst, len(s)), s...), nil
+		}
+		if c != '\\' {
+			s = append(s, c)
+			t.pos++
+			continue
+		}
+		if t.pos+1 >= len(t.src) {
+			break
+		}
+		t.pos += 2
+		switch e := t.src[t.pos-1]; e {
+		case '"', '\\', '/':
+			s = append(s, e)
+		case 'b':
+			s = append(s, '\b')
+		case 'f':
+			s = append(s, '\f')
+		case 'n':
+			s = append(s, '\n')
+		case 'r':
+			s = append(s, '\r')
+		case 't':
+			s = append(s, '\t')
+		case 'u':
+			r, ok := t.hex4()
+			if !ok {
+				return nil, t.errorf("bad \\u escape")
+			}
+			if utf16.IsSurrogate(r) {
+				lo := utf8.RuneError
+				if t.pos+1 < len(t.src) && t.src[t.pos] == '\\' && t.src[t.pos+1] == 'u' {
+					t.pos += 2
+					if lo, ok = t.hex4(); !ok {
+						return nil, t.errorf("bad \\u escape")
+					}
+				}
+				r = utf16.DecodeRune(r, lo)
+			}
+			s = utf8.AppendRune(s, r)
+		default:
+			return nil, t.errorf("bad escape \\%c", e)
+		}
+	}
+	return nil, t.errorf("unterminated string")
+}
+
+func (t *transcoder) hex4() (rune, bool) {
+	if len(t.src)-t.pos < 4 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(string(t.src[t.pos:t.pos+4]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	t.pos += 4
+	return rune(v), true
+}
+
+func strHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 32:
+		return append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, 0xda, byte(n>>8), byte(n))
+	default:
+		return append(dst, 0xdb, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// number appends the JSON number at t.pos as the smallest int that holds
+// it, or a float.
+func (t *transcoder) number(dst []byte) ([]byte, error) {
+	start := t.pos
+	isFloat := false
+	for t.pos < len(t.src) {
+		c := t.src[t.pos]
+		if c == '.' || c == 'e' || c == 'E' {
+			isFloat = true
+		} else if c != '-' && c != '+' && (c < '0' || c > '9') {
+			break
+		}
+		t.pos++
+	}
+	s := string(t.src[start:t.pos])
+	if !isFloat {
+		if s[0] == '-' {
+			if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return appendInt(dst, v), nil
+			}
+		} else if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return appendUint(dst, v), nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, t.errorf("bad number %q", s)
+	}
+	if f32 := float32(f); float64(f32) == f {
+		return binary.BigEndian.AppendUint32(append(dst, 0xca), math.Float32bits(f32)), nil
+	}
+	return binary.BigEndian.AppendUint64(append(dst, 0xcb), math.Float64bits(f)), nil
+}
+
+func appendUint(dst []byte, v uint64) []byte {
+	switch {
+	case v < 128:
+		return append(dst, byte(v))
+	case v <= math.MaxUint8:
+		return append(dst, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), v)
+	}
+}
+
+func appendInt(dst []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendUint(dst, uint64(v))
+	case v >= -32:
+		return append(dst, byte(v))
+	case v >= math.MinInt8:
+		return append(dst, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(v))
+	}
+}
@@ -0,0 +1,334 @@
+// Package perf implements drag-strip style performance timers: armed
+// automatically at a standstill, started on launch, measuring time to
+// set speeds (0–60, 0–100 km/h) and over the 60 ft, 1/8 mile and 1/4 mile
+// with trap speeds. Distance is integrated from the (fused) speed, so it
+// works through GPS dropouts on a car with VSS.
+package perf
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Timer states.
+const (
+	StateIdle    = "idle"    // Moving, not timing
+	StateArmed   = "armed"   // Stationary, waiting for launch
+	StateRunning = "running" // Timing a launch
+)
+
+// Distance marks, metres.
+const (
+	SixtyFootM   = 18.288
+	EighthMileM  = 201.168
+	QuarterMileM = 402.336
+	trapM        = 20.1168 // 66 ft: trap speed is the average over this before a mark
+)
+
+const (
+	standstillKph = 1.0                    // At or below = stationary
+	armHold       = 500 * time.Millisecond // Stationary this long arms
+	plateauHold   = 1500 * time.Millisecond
+	liftKph       = 3.0 // Speed this far below the peak = lifted, run over
+	maxRun        = 60 * time.Second
+	maxResults    = 200
+)
+
+// DefaultSpeeds are the speed targets, km/h, when none are configured.
+var DefaultSpeeds = []float64{60, 100}
+
+// SpeedSplit is the time from launch to a target speed.
+type SpeedSplit struct {
+	Kph float64 `json:"kph"`
+	Ms  int64   `json:"ms"`
+}
+
+// DistSplit is the time from launch over a distance, with trap speed.
+type DistSplit struct {
+	Name    string  `json:"name"` // "60ft", "1/8", "1/4"
+	M       float64 `json:"m"`
+	Ms      int64   `json:"ms"`
+	TrapKph float64 `json:"trapKph,omitempty"` // Average over the last 66 ft (1/8 and 1/4)
+}
+
+// Result is one launch.
+type Result struct {
+	ID        int          `json:"id"`
+	Session   string       `json:"session,omitempty"` // Drive the launch was in
+	Start     int64        `json:"start"`             // Unix ms
+	Speeds    []SpeedSplit `json:"speeds,omitempty"`
+	Distances []DistSplit  `json:"distances,omitempty"`
+	TopKph    float64      `json:"topKph"`
+	DistanceM float64      `json:"distanceM"`
+}
+
+// Split returns the time over the named distance, or 0.
+func (r *Result) Split(name string) int64 {
+	for _, d := range r.Distances {
+		if d.Name == name {
+			return d.Ms
+		}
+	}
+	return 0
+}
+
+// Status is the live state sent to clients.
+type Status struct {
+	State     string       `json:"state"`
+	ElapsedMs int64        `json:"elapsedMs,omitempty"`
+	DistanceM float64      `json:"distanceM,omitempty"`
+	Speeds    []SpeedSplit `json:"speeds,omitempty"`    // Reached so far
+	Distances []DistSplit  `json:"distances,omitempty"` // Reached so far
+	Last      *Result      `json:"last,omitempty"`
+	Best      *Result      `json:"best,omitempty"` // Quickest 1/4 mile, else quickest first speed target
+}
+
+var marks = []struct {
+	name string
+	m    float64
+	trap bool
+}{
+	{"60ft", SixtyFootM, false},
+	{"1/8", EighthMileM, true},
+	{"1/4", QuarterMileM, true},
+}
+
+// Timer runs the launch state machine and keeps the results. Update is
+// called from the broadcast loop; everything else from HTTP handlers.
+type Timer struct {
+	mu      sync.Mutex
+	speeds  []float64
+	results []Result
+	next    int
+
+	state    string
+	stillAt  time.Time // When the car came to rest (zero if moving)
+	start    time.Time
+	prevAt   time.Time
+	prevKph  float64
+	dist     float64
+	peak     float64
+	peakAt   time.Time
+	session  string
+	cur      Result
+	trapTime map[float64]time.Time // Time the trap start before each mark was passed
+}
+
+// New returns a timer for the given speed targets (km/h, nil =
+// DefaultSpeeds).
+func New(speeds []float64) *Timer {
+	if len(speeds) == 0 {
+		speeds = DefaultSpeeds
+	}
+	sp := append([]float64(nil), speeds...)
+	sort.Float64s(sp)
+	return &Timer{speeds: sp, state: StateIdle, next: 1}
+}
+
+// Restore replaces the result list (e.g. loaded from disk).
+func (t *Timer) Restore(results []Result) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.results = results
+	t.next = 1
+	for _, r := range results {
+		if r.ID >= t.next {
+			t.next = r.ID + 1
+		}
+	}
+}
+
+// Update feeds one speed sample (km/h) and returns the result of a
+// launch this update completed, if any. session labels new results.
+func (t *Timer) Update(now time.Time, kph float64, session string) *Result {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prevAt, prevKph := t.prevAt, t.prevKph
+	t.prevAt, t.prevKph = now, kph
+
+	still := kph <= standstillKph
+	if !still {
+		t.stillAt = time.Time{}
+	} else if t.stillAt.IsZero() {
+		t.stillAt = now
+	}
+
+	switch t.state {
+	case StateIdle:
+		if still && now.Sub(t.stillAt) >= armHold {
+			t.state = StateArmed
+		}
+
+	case StateArmed:
+		if !still {
+			// Launched. The last sample may already be creeping below the
+			// standstill threshold: extrapolate back to zero speed.
+			start := prevAt
+			if prevKph > 0 && kph > prevKph {
+				back := time.Duration(prevKph / (kph - prevKph) * float64(now.Sub(prevAt)))
+				start = prevAt.Add(-min(back, time.Second))
+			}
+			t.state = StateRunning
+			t.start = start
+			t.dist = 0
+			t.peak, t.peakAt = 0, now
+			t.session = session
+			t.cur = Result{Start: start.UnixMilli()}
+			t.trapTime = make(map[float64]time.Time)
+			t.advance(start, 0, prevAt, prevKph)
+			t.advance(prevAt, prevKph, now, kph)
+		}
+
+	case StateRunning:
+		if prevAt.IsZero() {
+			break
+		}
+		t.advance(prevAt, prevKph, now, kph)
+		done := len(t.cur.Distances) == len(marks)
+		lifted := kph < t.peak-liftKph || now.Sub(t.peakAt) >= plateauHold
+		if done || lifted || still || now.Sub(t.start) >= maxRun {
+			return t.finishLocked()
+		}
+	}
+	return nil
+}
+
+// advance integrates from (t0, v0) to (t1, v1), recording the speed and
+// distance marks passed, interpolated between the samples.
+func (t *Timer) advance(t0 time.Time, v0 float64, t1 time.Time, v1 float64) {
+	dt := t1.Sub(t0).Seconds()
+	if dt <= 0 {
+		return
+	}
+	at := func(frac float64) time.Time {
+		return t0.Add(time.Duration(frac * float64(t1.Sub(t0))))
+	}
+
+	for _, target := range t.speeds[len(t.cur.Speeds):] {
+		if v1 >= target && v0 < target {
+			c := at((target - v0) / (v1 - v0))
+			t.cur.Speeds = append(t.cur.Speeds, SpeedSplit{Kph: target, Ms: c.Sub(t.start).Milliseconds()})
+		}
+	}
+
+	// Trapezoidal distance, with mark times interpolated within the step
+	d0 := t.dist
+	step := (v0 + v1) / 2 / 3.6 * dt
+	d1 := d0 + step
+	t.dist = d1
+	crossing := func(m float64) (time.Time, bool) {
+		if d0 < m && d1 >= m && step > 0 {
+			return at((m - d0) / step), true
+		}
+		return time.Time{}, false
+	}
+	for _, mk := range marks {
+		if mk.trap {
+			if c, ok := crossing(mk.m - trapM); ok {
+				t.trapTime[mk.m] = c
+			}
+		}
+		c, ok := crossing(mk.m)
+		if !ok {
+			continue
+		}
+		split := DistSplit{Name: mk.name, M: mk.m, Ms: c.Sub(t.start).Milliseconds()}
+		if ts, ok := t.trapTime[mk.m]; ok && mk.trap && c.After(ts) {
+			split.TrapKph = math.Round(trapM/c.Sub(ts).Seconds()*3.6*10) / 10
+		}
+		t.cur.Distances = append(t.cur.Distances, split)
+	}
+
+	if v1 > t.peak {
+		t.peak, t.peakAt = v1, t1
+	}
+}
+
+// finishLocked ends the launch, keeping it if it reached the first speed
+// target or the 1/8 mile (pulling away in traffic doesn't count).
+func (t *Timer) finishLocked() *Result {
+	t.state = StateIdle
+	r := t.cur
+	if len(r.Speeds) == 0 && r.Split("1/8") == 0 {
+		return nil
+	}
+	r.ID = t.next
+	r.Session = t.session
+	r.TopKph = math.Round(t.peak*10) / 10
+	r.DistanceM = math.Round(t.dist)
+	t.next++
+	t.results = append(t.results, r)
+	if len(t.results) > maxResults {
+		t.results = t.results[len(t.results)-maxResults:]
+	}
+	return &r
+}
+
+// Status returns the live state.
+func (t *Timer) Status(now time.Time) Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := Status{State: t.state}
+	if t.state == StateRunning {
+		st.ElapsedMs = now.Sub(t.start).Milliseconds()
+		st.DistanceM = math.Round(t.dist*10) / 10
+		st.Speeds = append([]SpeedSplit(nil), t.cur.Speeds...)
+		st.Distances = append([]DistSplit(nil), t.cur.Distances...)
+	}
+	if n := len(t.results); n > 0 {
+		last := t.results[n-1]
+		st.Last = &last
+	}
+	st.Best = t.bestLocked()
+	return st
+}
+
+// bestLocked returns the quickest quarter mile, or without one the
+// quickest time to the lowest speed target.
+func (t *Timer) bestLocked() *Result {
+	var best *Result
+	for i := range t.results {
+		r := t.results[i]
+		if q := r.Split("1/4"); q > 0 && (best == nil || q < best.Split("1/4")) {
+			best = &r
+		}
+	}
+	if best != nil {
+		return best
+	}
+	for i := range t.results {
+		r := t.results[i]
+		if len(r.Speeds) == 0 || r.Speeds[0].Kph != t.speeds[0] {
+			continue
+		}
+		if best == nil || r.Speeds[0].Ms < best.Speeds[0].Ms {
+			best = &r
+		}
+	}
+	return best
+}
+
+// Results returns the results, oldest first; with session set, only
+// that session's.
+func (t *Timer) Results(session string) []Result {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []Result
+	for _, r := range t.results {
+		if session == "" || r.Session == session {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Clear deletes all results.
+func (t *Timer) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.results = nil
+	t.next = 1
+}
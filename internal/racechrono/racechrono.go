@@ -0,0 +1,123 @@
+// Package racechrono encodes data for RaceChrono's "DIY" device input: the
+// $RC3 sentence carrying an accelerometer, gyro, RPM and up to 15 analog
+// channels, and NMEA RMC and GGA sentences so RaceChrono can use the
+// dash's GPS instead of the phone's. Sentences are CRLF-terminated text
+// with NMEA checksums, read by RaceChrono over a TCP or UDP connection.
+//
+//	$RC3,time,count,xacc,yacc,zacc,gyrox,gyroy,gyroz,rpm,d2,a1,...,a15*cs
+package racechrono
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxAnalog is the number of analog inputs in an $RC3 sentence.
+const MaxAnalog = 15
+
+// Inertial is accelerometer (G) and gyro (°/s) data, in RaceChrono's
+// axes: x lateral, y longitudinal, z vertical.
+type Inertial struct {
+	AccX, AccY, AccZ    float64
+	GyroX, GyroY, GyroZ float64
+}
+
+// RC3 encodes an $RC3 sentence. count is the sentence counter, which
+// RaceChrono uses to spot lost sentences and wraps at 65536. inertial may
+// be nil and rpm NaN; analog values that are NaN are left empty, and
+// analog past MaxAnalog are ignored. The time field is left empty, for
+// RaceChrono to stamp the data on arrival.
+func RC3(count uint16, inertial *Inertial, rpm float64, analog []float64) []byte {
+	f := make([]string, 0, 25)
+	f = append(f, "RC3", "", strconv.Itoa(int(count)))
+	if inertial != nil {
+		for _, v := range []float64{inertial.AccX, inertial.AccY, inertial.AccZ} {
+			f = append(f, num(v, 3))
+		}
+		for _, v := range []float64{inertial.GyroX, inertial.GyroY, inertial.GyroZ} {
+			f = append(f, num(v, 2))
+		}
+	} else {
+		f = append(f, "", "", "", "", "", "")
+	}
+	f = append(f, num(rpm, 0), "") // d2 unused
+	for i := 0; i < MaxAnalog; i++ {
+		if i < len(analog) {
+			f = append(f, num(analog[i], -1))
+		} else {
+			f = append(f, "")
+		}
+	}
+	return sentence(strings.Join(f, ","))
+}
+
+// Fix is a GPS fix for RMC and GGA.
+type Fix struct {
+	Time       time.Time // UTC
+	Lat, Lon   float64   // Decimal degrees
+	SpeedKph   float64
+	Heading    float64 // Degrees true
+	Altitude   float64 // Meters
+	Satellites int
+	Quality    int // GGA fix quality: 1 = GPS, 2 = DGPS
+	HDOP       float64
+}
+
+// RMC encodes a $GPRMC sentence for fix.
+func RMC(fix Fix) []byte {
+	t := fix.Time.UTC()
+	lat, ns := nmeaCoord(fix.Lat, 2, "N", "S")
+	lon, ew := nmeaCoord(fix.Lon, 3, "E", "W")
+	return sentence(fmt.Sprintf("GPRMC,%s,A,%s,%s,%s,%s,%.2f,%.1f,%s,,,A",
+		nmeaTime(t), lat, ns, lon, ew, fix.SpeedKph/1.852, fix.Heading, t.Format("020106")))
+}
+
+// GGA encodes a $GPGGA sentence for fix.
+func GGA(fix Fix) []byte {
+	lat, ns := nmeaCoord(fix.Lat, 2, "N", "S")
+	lon, ew := nmeaCoord(fix.Lon, 3, "E", "W")
+	q := fix.Quality
+	if q <= 0 {
+		q = 1
+	}
+	return sentence(fmt.Sprintf("GPGGA,%s,%s,%s,%s,%s,%d,%02d,%.1f,%.1f,M,,M,,",
+		nmeaTime(fix.Time.UTC()), lat, ns, lon, ew, q, fix.Satellites, fix.HDOP, fix.Altitude))
+}
+
+// sentence wraps body in $ and a checksum.
+func sentence(body string) []byte {
+	var cs byte
+	for i := 0; i < len(body); i++ {
+		cs ^= body[i]
+	}
+	return []byte(fmt.Sprintf("$%s*%02X\r\n", body, cs))
+}
+
+// num formats v with prec decimals (-1 = as many as needed), or empty
+// for NaN.
+func num(v float64, prec int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', prec, 64)
+}
+
+// nmeaTime formats hhmmss.ss.
+func nmeaTime(t time.Time) string {
+	return fmt.Sprintf("%02d%02d%02d.%02d", t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1e7)
+}
+
+// nmeaCoord formats decimal degrees as NMEA's degrees and minutes, with
+// deg digits of degrees, and the hemisphere.
+func nmeaCoord(v float64, deg int, pos, neg string) (string, string) {
+	hemi := pos
+	if v < 0 {
+		hemi, v = neg, -v
+	}
+	d := math.Floor(v)
+	m := (v - d) * 60
+	return fmt.Sprintf("%0*d%08.5f", deg, int(d), m), hemi
+}
@@ -0,0 +1,115 @@
+package sensors
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// AEM reads the ASCII serial output of AEM X-Series / 30-0300 / 30-4100
+// wideband controllers: 9600 baud 8N1, one value per line ("14.70\r\n").
+// Depending on the gauge setting the value is AFR or lambda; anything
+// below 3.0 is treated as lambda.
+type AEM struct {
+	portPath string
+	baudRate int
+	stoich   float64
+	port     serial.Port
+	scanner  *bufio.Scanner
+	mu       sync.Mutex
+}
+
+const (
+	aemBaud    = 9600
+	aemTimeout = 500 * time.Millisecond
+)
+
+// NewAEM creates an AEM wideband provider.
+func NewAEM(cfg Config) *AEM {
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = aemBaud
+	}
+	if cfg.Stoich == 0 {
+		cfg.Stoich = 14.7
+	}
+	return &AEM{portPath: cfg.PortPath, baudRate: cfg.BaudRate, stoich: cfg.Stoich}
+}
+
+func (a *AEM) Name() string { return "AEM Wideband" }
+
+func (a *AEM) Connect() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.port != nil {
+		a.port.Close()
+		a.port = nil
+	}
+	port, resolved, err := openSerial("aem", a.portPath, a.baudRate, aemTimeout)
+	if err != nil {
+		return err
+	}
+	a.port = port
+	a.scanner = bufio.NewScanner(port)
+	log.Printf("[aem] connected to %s at %d baud", resolved, a.baudRate)
+	return nil
+}
+
+func (a *AEM) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scanner = nil
+	if a.port != nil {
+		err := a.port.Close()
+		a.port = nil
+		return err
+	}
+	return nil
+}
+
+// Read returns the next parseable line.
+func (a *AEM) Read() (*Reading, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.scanner == nil {
+		return nil, fmt.Errorf("aem: not connected")
+	}
+	for i := 0; i < 10; i++ {
+		if !a.scanner.Scan() {
+			if err := a.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("aem: read: %w", err)
+			}
+			// Timeout with no data: bufio.Scanner stops after EOF-like reads,
+			// so re-arm it on the same port.
+			a.scanner = bufio.NewScanner(a.port)
+			return nil, fmt.Errorf("aem: no data")
+		}
+		if r := parseAEMLine(a.scanner.Text(), a.stoich); r != nil {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("aem: no valid value in 10 lines")
+}
+
+// parseAEMLine converts one ASCII line into a reading, or nil if unparseable.
+func parseAEMLine(line string, stoich float64) *Reading {
+	line = strings.TrimSpace(line)
+	v, err := strconv.ParseFloat(line, 64)
+	if err != nil || v <= 0 {
+		return nil
+	}
+	r := &Reading{Channels: map[string]float64{}, Status: "ok", Stamp: time.Now().UnixMilli()}
+	if v < 3.0 {
+		r.Channels["lambda"] = v
+		r.Channels["afr"] = v * stoich
+	} else {
+		r.Channels["afr"] = v
+		r.Channels["lambda"] = v / stoich
+	}
+	return r
+}
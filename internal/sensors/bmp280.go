@@ -0,0 +1,196 @@
+package sensors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// BMP280 reads a Bosch BMP280 (or the pressure and temperature of a
+// BME280) on the Pi's I2C bus: barometric pressure in kPa as input
+// "pressure" and temperature in °C as "temperature". By default they're
+// reported as "ambient_kpa" and "ambient_temp".
+type BMP280 struct {
+	bus   string
+	addr  int
+	chans []Channel
+	dev   *i2cDev
+	cal   bmp280Cal
+	mu    sync.Mutex
+}
+
+// BMP280 registers and settings
+const (
+	bmp280Addr       = 0x76
+	bmp280RegCalib   = 0x88 // 24 bytes of trimming parameters
+	bmp280RegID      = 0xD0
+	bmp280RegCtrl    = 0xF4
+	bmp280RegConfig  = 0xF5
+	bmp280RegData    = 0xF7 // press_msb .. temp_xlsb
+	bmp280IDBMP      = 0x58
+	bmp280IDBME      = 0x60
+	bmp280CtrlNormal = 0x57 // Temperature ×2, pressure ×16 oversampling, normal mode
+	bmp280ConfigIIR  = 0x10 // 0.5 ms standby, IIR filter 16
+)
+
+// bmp280Cal holds the chip's trimming parameters.
+type bmp280Cal struct {
+	t1                             uint16
+	t2, t3                         int16
+	p1                             uint16
+	p2, p3, p4, p5, p6, p7, p8, p9 int16
+}
+
+// NewBMP280 creates a BMP280 provider on I2C bus cfg.PortPath (e.g.
+// /dev/i2c-1).
+func NewBMP280(cfg Config) *BMP280 {
+	if cfg.PortPath == "" {
+		cfg.PortPath = "/dev/i2c-1"
+	}
+	if cfg.Address == 0 {
+		cfg.Address = bmp280Addr
+	}
+	if len(cfg.Channels) == 0 {
+		cfg.Channels = []Channel{
+			{Name: "ambient_kpa", Input: "pressure", Unit: "kPa"},
+			{Name: "ambient_temp", Input: "temperature", Unit: "°C"},
+		}
+	}
+	return &BMP280{bus: cfg.PortPath, addr: cfg.Address, chans: cfg.Channels}
+}
+
+func (b *BMP280) Name() string { return "BMP280 Barometer" }
+
+func (b *BMP280) Connect() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dev != nil {
+		b.dev.Close()
+		b.dev = nil
+	}
+	for _, c := range b.chans {
+		if c.Input != "pressure" && c.Input != "temperature" {
+			return fmt.Errorf("bmp280: channel %q: input must be pressure or temperature", c.Name)
+		}
+		if err := c.checkCurve(); err != nil {
+			return fmt.Errorf("bmp280: %w", err)
+		}
+	}
+	d, err := openI2C(b.bus, b.addr)
+	if err != nil {
+		return fmt.Errorf("bmp280: %s: %w", b.bus, err)
+	}
+	id, err := d.readRegs(bmp280RegID, 1)
+	if err != nil {
+		d.Close()
+		return fmt.Errorf("bmp280: no response at 0x%02x on %s: %w", b.addr, b.bus, err)
+	}
+	if id[0] != bmp280IDBMP && id[0] != bmp280IDBME {
+		d.Close()
+		return fmt.Errorf("bmp280: unexpected chip ID 0x%02x at 0x%02x", id[0], b.addr)
+	}
+	raw, err := d.readRegs(bmp280RegCalib, 24)
+	if err != nil {
+		d.Close()
+		return fmt.Errorf("bmp280: read calibration: %w", err)
+	}
+	b.cal = parseBMP280Cal(raw)
+	if err := d.writeReg(bmp280RegConfig, bmp280ConfigIIR); err != nil {
+		d.Close()
+		return fmt.Errorf("bmp280: configure: %w", err)
+	}
+	if err := d.writeReg(bmp280RegCtrl, bmp280CtrlNormal); err != nil {
+		d.Close()
+		return fmt.Errorf("bmp280: configure: %w", err)
+	}
+	b.dev = d
+	log.Printf("[bmp280] connected at 0x%02x on %s", b.addr, b.bus)
+	return nil
+}
+
+func (b *BMP280) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dev != nil {
+		err := b.dev.Close()
+		b.dev = nil
+		return err
+	}
+	return nil
+}
+
+// Read samples the latest conversion, paced to 10 Hz; the chip converts
+// continuously at about 25 Hz with these settings.
+func (b *BMP280) Read() (*Reading, error) {
+	time.Sleep(100 * time.Millisecond)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dev == nil {
+		return nil, fmt.Errorf("bmp280: not connected")
+	}
+	d, err := b.dev.readRegs(bmp280RegData, 6)
+	if err != nil {
+		return nil, fmt.Errorf("bmp280: %w", err)
+	}
+	adcP := int32(d[0])<<12 | int32(d[1])<<4 | int32(d[2])>>4
+	adcT := int32(d[3])<<12 | int32(d[4])<<4 | int32(d[5])>>4
+	tempC, pa, ok := b.cal.compensate(adcT, adcP)
+	if !ok {
+		return nil, fmt.Errorf("bmp280: no conversion yet")
+	}
+
+	r := &Reading{Channels: make(map[string]float64, len(b.chans)), Status: "ok", Stamp: time.Now().UnixMilli()}
+	for _, c := range b.chans {
+		raw := tempC
+		if c.Input == "pressure" {
+			raw = pa / 1000
+		}
+		r.Channels[c.Name] = c.value(raw)
+	}
+	return r, nil
+}
+
+// parseBMP280Cal decodes the little-endian trimming parameters at 0x88.
+func parseBMP280Cal(b []byte) bmp280Cal {
+	u := func(i int) uint16 { return binary.LittleEndian.Uint16(b[i:]) }
+	s := func(i int) int16 { return int16(u(i)) }
+	return bmp280Cal{
+		t1: u(0), t2: s(2), t3: s(4),
+		p1: u(6), p2: s(8), p3: s(10), p4: s(12), p5: s(14),
+		p6: s(16), p7: s(18), p8: s(20), p9: s(22),
+	}
+}
+
+// compensate converts raw readings to °C and Pa with the datasheet's
+// floating-point formulas. It reports false for the chip's reset value,
+// before the first conversion.
+func (c bmp280Cal) compensate(adcT, adcP int32) (tempC, pa float64, ok bool) {
+	if adcP == 0x80000 || adcT == 0x80000 {
+		return 0, 0, false
+	}
+	t, p := float64(adcT), float64(adcP)
+
+	v1 := (t/16384 - float64(c.t1)/1024) * float64(c.t2)
+	v2 := (t/131072 - float64(c.t1)/8192) * (t/131072 - float64(c.t1)/8192) * float64(c.t3)
+	tFine := v1 + v2
+	tempC = tFine / 5120
+
+	v1 = tFine/2 - 64000
+	v2 = v1 * v1 * float64(c.p6) / 32768
+	v2 += v1 * float64(c.p5) * 2
+	v2 = v2/4 + float64(c.p4)*65536
+	v1 = (float64(c.p3)*v1*v1/524288 + float64(c.p2)*v1) / 524288
+	v1 = (1 + v1/32768) * float64(c.p1)
+	if v1 == 0 {
+		return 0, 0, false // Avoid dividing by zero
+	}
+	pa = 1048576 - p
+	pa = (pa - v2/4096) * 6250 / v1
+	v1 = float64(c.p9) * pa * pa / 2147483648
+	v2 = pa * float64(c.p8) / 32768
+	pa += (v1 + v2 + float64(c.p7)) / 16
+	return tempC, pa, true
+}
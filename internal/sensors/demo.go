@@ -0,0 +1,114 @@
+package sensors
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Demo generates simulated wideband, EGT or Pi-attached sensor readings
+// for development and testing.
+type Demo struct {
+	mu     sync.Mutex
+	t      float64
+	stoich float64
+	egt    int       // number of EGT channels; 0 = simulate a wideband
+	kind   string    // "bmp280", "ds18b20", "mcp3008" or "tpms" to simulate one of those
+	chans  []Channel // Their channels
+}
+
+// NewDemo creates a simulated wideband provider.
+func NewDemo(cfg Config) *Demo {
+	if cfg.Stoich == 0 {
+		cfg.Stoich = 14.7
+	}
+	return &Demo{stoich: cfg.Stoich}
+}
+
+// NewDemoEGT creates a simulated n-channel EGT provider.
+func NewDemoEGT(n int) *Demo {
+	if n <= 0 || n > MaxEGT {
+		n = 4
+	}
+	return &Demo{stoich: 14.7, egt: n}
+}
+
+// NewDemoAux creates a simulated provider of sensor type kind ("bmp280",
+// "ds18b20", "mcp3008" or "tpms") reporting the channels cfg configures,
+// or the sensor's defaults. The simulated right rear tire slowly leaks.
+func NewDemoAux(kind string, cfg Config) *Demo {
+	d := &Demo{stoich: 14.7, kind: kind, chans: cfg.Channels}
+	switch {
+	case len(d.chans) > 0:
+	case kind == "bmp280":
+		d.chans = NewBMP280(cfg).chans
+	case kind == "mcp3008":
+		d.chans = NewMCP3008(cfg).chans
+	case kind == "tpms":
+		for _, c := range []string{"fl", "fr", "rl", "rr"} {
+			d.chans = append(d.chans, Channel{Name: c})
+		}
+	default:
+		d.chans = []Channel{{Name: "28-00000demo01", Input: "28-00000demo01"}, {Name: "28-00000demo02", Input: "28-00000demo02"}}
+	}
+	return d
+}
+
+func (d *Demo) Name() string   { return "Demo Sensor (Simulated)" }
+func (d *Demo) Connect() error { return nil }
+func (d *Demo) Close() error   { return nil }
+
+func (d *Demo) Read() (*Reading, error) {
+	time.Sleep(50 * time.Millisecond) // ~20 Hz, like a real stream
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.t += 0.05
+
+	load := math.Sin(d.t*0.3) * math.Sin(d.t*0.3)
+	if d.kind != "" {
+		r := &Reading{Channels: map[string]float64{}, Status: "ok", Stamp: time.Now().UnixMilli()}
+		for i, c := range d.chans {
+			if d.kind == "tpms" {
+				kpa := 230 + 15*load + float64(i)
+				if c.Name == "rr" {
+					kpa -= math.Mod(d.t, 300) / 3 // Leaks 100 kPa over ~5 min
+				}
+				r.Channels["tire_"+c.Name+"_kpa"] = kpa
+				r.Channels["tire_"+c.Name+"_temp"] = 30 + 20*load + float64(i)
+				continue
+			}
+			var raw float64
+			switch {
+			case d.kind == "bmp280" && c.Input == "pressure":
+				raw = 101.3 + rand.Float64()*0.05
+			case d.kind == "bmp280":
+				raw = 22 + 3*load
+			case d.kind == "mcp3008":
+				raw = 0.5 + 2*(0.5+0.5*math.Sin(d.t*0.05+float64(i))) + rand.Float64()*0.01
+			default:
+				raw = 60 + 25*load + float64(i*5) + rand.Float64()*0.2
+			}
+			r.Channels[c.Name] = c.value(raw)
+		}
+		return r, nil
+	}
+	if d.egt > 0 {
+		r := &Reading{Channels: map[string]float64{}, Status: "ok", Stamp: time.Now().UnixMilli()}
+		for i := 1; i <= d.egt; i++ {
+			r.Channels[fmt.Sprintf("egt%d", i)] = 350 + 500*load + float64(i*8) + rand.Float64()*10
+		}
+		return r, nil
+	}
+
+	lambda := 1.0 - 0.12*load + rand.Float64()*0.01
+	return &Reading{
+		Channels: map[string]float64{
+			"lambda": lambda,
+			"afr":    lambda * d.stoich,
+		},
+		Status: "ok",
+		Stamp:  time.Now().UnixMilli(),
+	}, nil
+}
@@ -0,0 +1,153 @@
+package sensors
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DS18B20 reads DS18B20 (and DS18S20, DS1822) 1-Wire temperature probes
+// through the kernel's w1-therm driver (dtoverlay=w1-gpio, data on GPIO 4
+// by default). Inputs are probe IDs as listed in /sys/bus/w1/devices
+// (e.g. "28-0316a2790cff"); with no channels configured, every probe
+// found is reported under its ID.
+//
+// Each probe takes ~750 ms to convert, so Read reads one probe per call
+// in turn and reports the latest temperature of all of them.
+type DS18B20 struct {
+	dir   string
+	chans []Channel
+	found []Channel // chans, or one per probe found
+	last  map[string]float64
+	next  int
+	mu    sync.Mutex
+}
+
+// ds18b20Families are the 1-Wire family codes of the supported probes.
+var ds18b20Families = []string{"28-", "10-", "22-"}
+
+// NewDS18B20 creates a 1-Wire temperature provider. cfg.PortPath
+// overrides the sysfs device directory.
+func NewDS18B20(cfg Config) *DS18B20 {
+	if cfg.PortPath == "" {
+		cfg.PortPath = "/sys/bus/w1/devices"
+	}
+	return &DS18B20{dir: cfg.PortPath, chans: cfg.Channels}
+}
+
+func (d *DS18B20) Name() string { return "DS18B20 1-Wire" }
+
+func (d *DS18B20) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, c := range d.chans {
+		if err := c.checkCurve(); err != nil {
+			return fmt.Errorf("ds18b20: %w", err)
+		}
+	}
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return fmt.Errorf("ds18b20: %w (is the w1-gpio overlay enabled?)", err)
+	}
+	var ids []string
+	for _, e := range entries {
+		for _, fam := range ds18b20Families {
+			if strings.HasPrefix(e.Name(), fam) {
+				ids = append(ids, e.Name())
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("ds18b20: no probes in %s", d.dir)
+	}
+
+	d.found = d.chans
+	if len(d.found) == 0 {
+		d.found = nil
+		for _, id := range ids {
+			d.found = append(d.found, Channel{Name: id, Input: id, Unit: "°C"})
+		}
+	}
+	for _, c := range d.found {
+		if _, err := os.Stat(filepath.Join(d.dir, c.Input, "w1_slave")); err != nil {
+			log.Printf("[ds18b20] %s: probe %s not found", c.Name, c.Input)
+		}
+	}
+	d.last = make(map[string]float64, len(d.found))
+	d.next = 0
+	log.Printf("[ds18b20] %d probe(s) on the bus: %s", len(ids), strings.Join(ids, ", "))
+	return nil
+}
+
+func (d *DS18B20) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.found = nil
+	return nil
+}
+
+// Read converts the next probe and returns the latest temperature of
+// every probe that has one. A probe that fails its CRC or has gone
+// missing drops out until it reads again.
+func (d *DS18B20) Read() (*Reading, error) {
+	d.mu.Lock()
+	if len(d.found) == 0 {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("ds18b20: not connected")
+	}
+	c := d.found[d.next%len(d.found)]
+	d.next++
+	dir := d.dir
+	d.mu.Unlock()
+
+	// The sysfs read blocks for the conversion; don't hold the lock
+	temp, err := readW1Therm(filepath.Join(dir, c.Input, "w1_slave"))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		delete(d.last, c.Name)
+	} else {
+		d.last[c.Name] = c.value(temp)
+	}
+	if len(d.last) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no readings")
+		}
+		return nil, fmt.Errorf("ds18b20: %s: %w", c.Input, err)
+	}
+	r := &Reading{Channels: make(map[string]float64, len(d.last)), Status: "ok", Stamp: time.Now().UnixMilli()}
+	for k, v := range d.last {
+		r.Channels[k] = v
+	}
+	return r, nil
+}
+
+// readW1Therm parses w1-therm's w1_slave file:
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+func readW1Therm(path string) (float64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	crc, data, _ := strings.Cut(strings.TrimSpace(string(b)), "\n")
+	if !strings.HasSuffix(strings.TrimSpace(crc), "YES") {
+		return 0, fmt.Errorf("CRC error")
+	}
+	_, t, ok := strings.Cut(data, "t=")
+	if !ok {
+		return 0, fmt.Errorf("malformed reading %q", data)
+	}
+	milli, err := strconv.Atoi(strings.TrimSpace(t))
+	if err != nil {
+		return 0, fmt.Errorf("malformed reading %q", data)
+	}
+	return float64(milli) / 1000, nil
+}
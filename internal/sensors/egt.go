@@ -0,0 +1,279 @@
+package sensors
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// MaxEGT is the number of EGT channels carried in frames and logs.
+const MaxEGT = 8
+
+// ============================================================================
+// MAX31855 / MAX31856 thermocouple amplifiers on the Pi's SPI bus
+// ============================================================================
+
+// MAX318xx reads one thermocouple amplifier per spidev node, one node per
+// cylinder: ports[0] → "egt1", ports[1] → "egt2", ...
+//
+//   - MAX31855: read-only, 14-bit signed at 0.25 °C, K-type fixed at build
+//   - MAX31856: configured for continuous conversion, 19-bit at 0.0078125 °C,
+//     thermocouple type selectable (B, E, J, K, N, R, S, T)
+type MAX318xx struct {
+	chip   string // "max31855" or "max31856"
+	ports  []string
+	tcType byte
+	devs   []*spiDev
+	mu     sync.Mutex
+}
+
+// MAX31856 registers
+const (
+	max31856WriteCR0 = 0x80
+	max31856WriteCR1 = 0x81
+	max31856RegLTCBH = 0x0C
+	max31856RegSR    = 0x0F
+	max31856CMode    = 0x80 // CR0: automatic conversion
+)
+
+var max31856Types = map[string]byte{
+	"B": 0x00, "E": 0x01, "J": 0x02, "K": 0x03,
+	"N": 0x04, "R": 0x05, "S": 0x06, "T": 0x07,
+}
+
+// NewMAX318xx creates an SPI thermocouple provider for chip "max31855"
+// or "max31856". tcType is the thermocouple letter (MAX31856 only).
+func NewMAX318xx(chip string, ports []string, tcType string) *MAX318xx {
+	t, ok := max31856Types[strings.ToUpper(tcType)]
+	if !ok {
+		t = max31856Types["K"]
+	}
+	if len(ports) > MaxEGT {
+		ports = ports[:MaxEGT]
+	}
+	return &MAX318xx{chip: chip, ports: ports, tcType: t}
+}
+
+func (m *MAX318xx) Name() string { return strings.ToUpper(m.chip) + " EGT" }
+
+func (m *MAX318xx) Connect() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeLocked()
+
+	if len(m.ports) == 0 {
+		return fmt.Errorf("%s: no spidev ports configured", m.chip)
+	}
+	mode := uint8(0)
+	if m.chip == "max31856" {
+		mode = 1
+	}
+	for _, p := range m.ports {
+		d, err := openSPI(p, mode, 1000000)
+		if err != nil {
+			m.closeLocked()
+			return fmt.Errorf("%s: open %s: %w", m.chip, p, err)
+		}
+		if m.chip == "max31856" {
+			if _, err := d.transfer([]byte{max31856WriteCR0, max31856CMode}); err != nil {
+				d.Close()
+				m.closeLocked()
+				return fmt.Errorf("%s: configure %s: %w", m.chip, p, err)
+			}
+			if _, err := d.transfer([]byte{max31856WriteCR1, m.tcType}); err != nil {
+				d.Close()
+				m.closeLocked()
+				return fmt.Errorf("%s: configure %s: %w", m.chip, p, err)
+			}
+		}
+		m.devs = append(m.devs, d)
+	}
+	log.Printf("[egt] %s connected, %d channel(s)", m.chip, len(m.devs))
+	return nil
+}
+
+func (m *MAX318xx) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeLocked()
+	return nil
+}
+
+func (m *MAX318xx) closeLocked() {
+	for _, d := range m.devs {
+		d.Close()
+	}
+	m.devs = nil
+}
+
+// Read samples every channel. Conversions take ~100 ms, so this paces
+// itself to 10 Hz. Faulted channels (open/shorted thermocouple) are
+// reported as "egtNFault" instead of "egtN".
+func (m *MAX318xx) Read() (*Reading, error) {
+	time.Sleep(100 * time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.devs) == 0 {
+		return nil, fmt.Errorf("%s: not connected", m.chip)
+	}
+
+	r := &Reading{Channels: map[string]float64{}, Status: "error", Stamp: time.Now().UnixMilli()}
+	for i, d := range m.devs {
+		key := fmt.Sprintf("egt%d", i+1)
+		var (
+			temp  float64
+			fault byte
+			err   error
+		)
+		if m.chip == "max31856" {
+			temp, fault, err = readMAX31856(d)
+		} else {
+			temp, fault, err = readMAX31855(d)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", m.chip, m.ports[i], err)
+		}
+		if fault != 0 {
+			r.Channels[key+"Fault"] = float64(fault)
+			continue
+		}
+		r.Channels[key] = temp
+		r.Status = "ok"
+	}
+	return r, nil
+}
+
+// readMAX31855 decodes one 32-bit MAX31855 frame.
+func readMAX31855(d *spiDev) (float64, byte, error) {
+	b, err := d.transfer(make([]byte, 4))
+	if err != nil {
+		return 0, 0, err
+	}
+	v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	if v&(1<<16) != 0 {
+		return 0, byte(v & 0x07), nil // SCV, SCG, OC
+	}
+	raw := int32(v) >> 18 // 14-bit signed, sign-extended
+	return float64(raw) * 0.25, 0, nil
+}
+
+// readMAX31856 reads the linearised thermocouple temperature and fault status.
+func readMAX31856(d *spiDev) (float64, byte, error) {
+	sr, err := d.transfer([]byte{max31856RegSR, 0})
+	if err != nil {
+		return 0, 0, err
+	}
+	if sr[1] != 0 {
+		return 0, sr[1], nil
+	}
+	b, err := d.transfer([]byte{max31856RegLTCBH, 0, 0, 0})
+	if err != nil {
+		return 0, 0, err
+	}
+	raw := int32(uint32(b[1])<<24|uint32(b[2])<<16|uint32(b[3])<<8) >> 13 // 19-bit signed
+	return float64(raw) * 0.0078125, 0, nil
+}
+
+// ============================================================================
+// Serial EGT amplifiers — ASCII, comma-separated °C per line
+// ============================================================================
+
+// EGTSerial reads multi-channel EGT amplifiers that print one line per
+// sample with comma- or space-separated temperatures in °C, e.g.
+// "812.5,799,805,820\r\n" → egt1..egt4.
+type EGTSerial struct {
+	portPath string
+	baudRate int
+	port     serial.Port
+	scanner  *bufio.Scanner
+	mu       sync.Mutex
+}
+
+// NewEGTSerial creates a serial EGT provider.
+func NewEGTSerial(cfg Config) *EGTSerial {
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = 9600
+	}
+	return &EGTSerial{portPath: cfg.PortPath, baudRate: cfg.BaudRate}
+}
+
+func (e *EGTSerial) Name() string { return "Serial EGT" }
+
+func (e *EGTSerial) Connect() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.port != nil {
+		e.port.Close()
+		e.port = nil
+	}
+	port, resolved, err := openSerial("egt", e.portPath, e.baudRate, 500*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	e.port = port
+	e.scanner = bufio.NewScanner(port)
+	log.Printf("[egt] connected to %s at %d baud", resolved, e.baudRate)
+	return nil
+}
+
+func (e *EGTSerial) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scanner = nil
+	if e.port != nil {
+		err := e.port.Close()
+		e.port = nil
+		return err
+	}
+	return nil
+}
+
+func (e *EGTSerial) Read() (*Reading, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.scanner == nil {
+		return nil, fmt.Errorf("egt: not connected")
+	}
+	for i := 0; i < 10; i++ {
+		if !e.scanner.Scan() {
+			if err := e.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("egt: read: %w", err)
+			}
+			e.scanner = bufio.NewScanner(e.port)
+			return nil, fmt.Errorf("egt: no data")
+		}
+		if r := parseEGTLine(e.scanner.Text()); r != nil {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("egt: no valid line in 10 lines")
+}
+
+// parseEGTLine parses a separated list of temperatures, or nil.
+func parseEGTLine(line string) *Reading {
+	fields := strings.FieldsFunc(line, func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t' || c == ';'
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+	r := &Reading{Channels: map[string]float64{}, Status: "ok", Stamp: time.Now().UnixMilli()}
+	for i, f := range fields {
+		if i >= MaxEGT {
+			break
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil
+		}
+		r.Channels[fmt.Sprintf("egt%d", i+1)] = v
+	}
+	return r
+}
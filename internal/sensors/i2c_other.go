@@ -0,0 +1,22 @@
+//go:build !linux
+
+package sensors
+
+import "fmt"
+
+// i2cDev is unavailable off Linux; I2C sensors fail to connect.
+type i2cDev struct{}
+
+func openI2C(bus string, addr int) (*i2cDev, error) {
+	return nil, fmt.Errorf("i2c-dev is only supported on Linux")
+}
+
+func (d *i2cDev) writeReg(reg, val byte) error {
+	return fmt.Errorf("i2c-dev is only supported on Linux")
+}
+
+func (d *i2cDev) readRegs(reg byte, n int) ([]byte, error) {
+	return nil, fmt.Errorf("i2c-dev is only supported on Linux")
+}
+
+func (d *i2cDev) Close() error { return nil }
@@ -0,0 +1,176 @@
+package sensors
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// Innovate reads the Innovate MTS serial stream (LC-1, LC-2, LM-2, MTX-L
+// and chained aux devices). 19200 baud 8N1, packets pushed continuously.
+//
+// Packet layout (16-bit big-endian words):
+//
+//	header:  1 R 1 1 x x 1 L7   1 x L6..L0     — L = length in words
+//	LC-1:    0 1 0 F2 F1 F0 1 AF7   0 AF6..AF0  — function + AFR multiplier
+//	         0 0 L12..L7            0 L6..L0    — lambda/O2/error value
+//	aux:     0 0 0 A9 A8 A7 ...     0 A6..A0    — 10-bit aux channel
+//
+// Lambda words yield "lambda" and "afr"; aux words yield "aux1".."auxN"
+// (raw 0-1023, used e.g. by TC-4 thermocouple modules).
+type Innovate struct {
+	portPath string
+	baudRate int
+	port     serial.Port
+	mu       sync.Mutex
+	buf      []byte
+}
+
+const (
+	innovateBaud    = 19200
+	innovateTimeout = 500 * time.Millisecond
+	innovateMaxLen  = 64 // words; anything larger is a framing error
+)
+
+// NewInnovate creates an Innovate MTS provider.
+func NewInnovate(cfg Config) *Innovate {
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = innovateBaud
+	}
+	return &Innovate{portPath: cfg.PortPath, baudRate: cfg.BaudRate}
+}
+
+func (in *Innovate) Name() string { return "Innovate MTS" }
+
+func (in *Innovate) Connect() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.port != nil {
+		in.port.Close()
+		in.port = nil
+	}
+	port, resolved, err := openSerial("innovate", in.portPath, in.baudRate, innovateTimeout)
+	if err != nil {
+		return err
+	}
+	in.port = port
+	in.buf = in.buf[:0]
+	log.Printf("[innovate] connected to %s at %d baud", resolved, in.baudRate)
+	return nil
+}
+
+func (in *Innovate) Close() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.port != nil {
+		err := in.port.Close()
+		in.port = nil
+		return err
+	}
+	return nil
+}
+
+// Read returns the next complete MTS packet.
+func (in *Innovate) Read() (*Reading, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.port == nil {
+		return nil, fmt.Errorf("innovate: not connected")
+	}
+
+	chunk := make([]byte, 128)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		r, rest, ok := parseMTS(in.buf)
+		in.buf = append(in.buf[:0], rest...)
+		if ok {
+			return r, nil
+		}
+		n, err := in.port.Read(chunk)
+		if err != nil && n == 0 {
+			return nil, fmt.Errorf("innovate: read: %w", err)
+		}
+		in.buf = append(in.buf, chunk[:n]...)
+	}
+	return nil, fmt.Errorf("innovate: no packet within 2s (%d bytes buffered)", len(in.buf))
+}
+
+// isMTSHeader reports whether hi/lo form an MTS packet header word.
+func isMTSHeader(hi, lo byte) bool {
+	return hi&0xA2 == 0xA2 && lo&0x80 == 0x80
+}
+
+// parseMTS scans b for a complete packet. It returns the decoded reading and
+// the bytes following it, or ok=false and the bytes worth keeping (from the
+// first plausible header onward).
+func parseMTS(b []byte) (r *Reading, rest []byte, ok bool) {
+	for i := 0; i+1 < len(b); i++ {
+		if !isMTSHeader(b[i], b[i+1]) {
+			continue
+		}
+		words := int(b[i]&0x01)<<7 | int(b[i+1]&0x7F)
+		if words == 0 || words > innovateMaxLen {
+			continue
+		}
+		end := i + 2 + 2*words
+		if end > len(b) {
+			return nil, b[i:], false // wait for the rest
+		}
+		return decodeMTS(b[i+2 : end]), b[end:], true
+	}
+	if len(b) > 0 && b[len(b)-1]&0xA2 == 0xA2 {
+		return nil, b[len(b)-1:], false // possible header split across reads
+	}
+	return nil, b[:0], false
+}
+
+// decodeMTS decodes the body words of an MTS packet.
+func decodeMTS(p []byte) *Reading {
+	r := &Reading{Channels: map[string]float64{}, Status: "ok", Stamp: time.Now().UnixMilli()}
+	lambdaSeen := 0
+	aux := 0
+	for i := 0; i+1 < len(p); {
+		hi, lo := p[i], p[i+1]
+		if hi&0xE2 == 0x42 && i+3 < len(p) {
+			// LC-1 style lambda sub-packet: 2 words
+			fn := (hi >> 2) & 0x07
+			afMul := float64(int(hi&0x01)<<7|int(lo&0x7F)) / 10
+			val := int(p[i+2]&0x3F)<<7 | int(p[i+3]&0x7F)
+			lambdaSeen++
+			suffix := ""
+			if lambdaSeen > 1 {
+				suffix = fmt.Sprintf("%d", lambdaSeen)
+			}
+			switch fn {
+			case 0: // lambda valid
+				lambda := float64(val+500) / 1000
+				r.Channels["lambda"+suffix] = lambda
+				r.Channels["afr"+suffix] = lambda * afMul
+			case 1: // O2 level (free air), 1/10 %
+				r.Channels["o2pct"+suffix] = float64(val) / 10
+				r.Status = "free-air"
+			case 2:
+				r.Status = "calibrating"
+			case 3:
+				r.Status = "needs-calibration"
+			case 4:
+				r.Status = "warmup"
+			case 5:
+				r.Status = "heater-calibration"
+			case 6:
+				r.Status = "error"
+				r.Channels["errorCode"+suffix] = float64(val)
+			}
+			i += 4
+			continue
+		}
+		// Aux channel word
+		aux++
+		r.Channels[fmt.Sprintf("aux%d", aux)] = float64(int(hi&0x07)<<7 | int(lo&0x7F))
+		i += 2
+	}
+	return r
+}
@@ -0,0 +1,128 @@
+package sensors
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MCP3008 reads a Microchip MCP3008 8-channel 10-bit ADC on the Pi's SPI
+// bus, for analog senders the ECU has no spare inputs for: fuel level,
+// trans or diff temperature, pressures. Inputs are channel numbers "0" to
+// "7" in volts, before scaling; with no channels configured all eight are
+// reported as "adc0" to "adc7".
+type MCP3008 struct {
+	port  string
+	vref  float64
+	chans []Channel
+	dev   *spiDev
+	mu    sync.Mutex
+}
+
+const (
+	mcp3008Channels = 8
+	mcp3008SpeedHz  = 1000000 // Within spec down to 2.7 V
+	mcp3008Samples  = 4       // Averaged per reading, against sender noise
+)
+
+// NewMCP3008 creates an MCP3008 provider on spidev node cfg.PortPath
+// (e.g. /dev/spidev0.1).
+func NewMCP3008(cfg Config) *MCP3008 {
+	if cfg.VRef <= 0 {
+		cfg.VRef = 3.3
+	}
+	if len(cfg.Channels) == 0 {
+		for i := 0; i < mcp3008Channels; i++ {
+			cfg.Channels = append(cfg.Channels, Channel{Name: fmt.Sprintf("adc%d", i), Input: strconv.Itoa(i), Unit: "V"})
+		}
+	}
+	return &MCP3008{port: cfg.PortPath, vref: cfg.VRef, chans: cfg.Channels}
+}
+
+func (m *MCP3008) Name() string { return "MCP3008 ADC" }
+
+func (m *MCP3008) Connect() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dev != nil {
+		m.dev.Close()
+		m.dev = nil
+	}
+	if m.port == "" {
+		return fmt.Errorf("mcp3008: no spidev port configured")
+	}
+	for _, c := range m.chans {
+		if _, err := mcp3008Input(c.Input); err != nil {
+			return fmt.Errorf("mcp3008: channel %q: %w", c.Name, err)
+		}
+		if err := c.checkCurve(); err != nil {
+			return fmt.Errorf("mcp3008: %w", err)
+		}
+	}
+	d, err := openSPI(m.port, 0, mcp3008SpeedHz)
+	if err != nil {
+		return fmt.Errorf("mcp3008: open %s: %w", m.port, err)
+	}
+	m.dev = d
+	log.Printf("[mcp3008] connected on %s, %d channel(s)", m.port, len(m.chans))
+	return nil
+}
+
+func (m *MCP3008) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dev != nil {
+		err := m.dev.Close()
+		m.dev = nil
+		return err
+	}
+	return nil
+}
+
+// Read samples every channel, paced to 20 Hz.
+func (m *MCP3008) Read() (*Reading, error) {
+	time.Sleep(50 * time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dev == nil {
+		return nil, fmt.Errorf("mcp3008: not connected")
+	}
+	r := &Reading{Channels: make(map[string]float64, len(m.chans)), Status: "ok", Stamp: time.Now().UnixMilli()}
+	for _, c := range m.chans {
+		ch, _ := mcp3008Input(c.Input)
+		sum := 0
+		for i := 0; i < mcp3008Samples; i++ {
+			v, err := readMCP3008(m.dev, ch)
+			if err != nil {
+				return nil, fmt.Errorf("mcp3008: %s: %w", m.port, err)
+			}
+			sum += v
+		}
+		volts := float64(sum) / mcp3008Samples / 1023 * m.vref
+		r.Channels[c.Name] = c.value(volts)
+	}
+	return r, nil
+}
+
+// mcp3008Input parses a channel number.
+func mcp3008Input(s string) (int, error) {
+	ch, err := strconv.Atoi(s)
+	if err != nil || ch < 0 || ch >= mcp3008Channels {
+		return 0, fmt.Errorf("input %q is not a channel 0-7", s)
+	}
+	return ch, nil
+}
+
+// readMCP3008 converts single-ended channel ch: start bit, then SGL/DIFF
+// and the channel in the top nibble of the second byte; the 10-bit result
+// comes back in the low bits of the last two.
+func readMCP3008(d *spiDev, ch int) (int, error) {
+	rx, err := d.transfer([]byte{0x01, 0x80 | byte(ch)<<4, 0})
+	if err != nil {
+		return 0, err
+	}
+	return int(rx[1]&0x03)<<8 | int(rx[2]), nil
+}
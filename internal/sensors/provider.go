@@ -0,0 +1,131 @@
+// Package sensors provides auxiliary sensor sources that sit alongside the
+// ECU — standalone wideband controllers, EGT amplifiers, and sensors wired
+// to the Pi itself such as a barometer, 1-Wire temperature probes or an
+// ADC for analog senders — and whose readings are merged into the
+// broadcast frame.
+package sensors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"go.bug.st/serial"
+)
+
+// Provider is the interface for auxiliary sensor sources.
+type Provider interface {
+	Name() string
+	Connect() error
+	Close() error
+	// Read blocks until the next reading is available, or times out.
+	Read() (*Reading, error)
+}
+
+// Reading is one sample from an auxiliary sensor source.
+// Channels use the same names as the ECU DataFrame JSON where they
+// overlap (e.g. "afr", "lambda"), so they can override ECU values.
+type Reading struct {
+	Channels map[string]float64 `json:"channels"`
+	Status   string             `json:"status"` // "ok", "warmup", "calibrating", "error", ...
+	Stamp    int64              `json:"stamp"`  // Unix ms
+}
+
+// OK reports whether the reading carries valid data.
+func (r *Reading) OK() bool {
+	return r != nil && r.Status == "ok"
+}
+
+// Config holds connection configuration for a sensor provider.
+type Config struct {
+	PortPath string  `yaml:"port_path" json:"portPath"`
+	BaudRate int     `yaml:"baud_rate" json:"baudRate"`
+	Stoich   float64 `yaml:"stoich" json:"stoich"` // For lambda↔AFR conversion
+
+	Address  int       `yaml:"address" json:"address"`   // I2C sensors: 7-bit address; 0 = chip default
+	VRef     float64   `yaml:"vref" json:"vref"`         // ADCs: reference voltage; 0 = 3.3
+	Channels []Channel `yaml:"channels" json:"channels"` // Multi-input sensors: inputs to report; nil = sensor default
+
+	// tpms: the rtl_433 receiver's frequency and device
+	FrequencyMHz float64 `yaml:"frequency_mhz" json:"frequencyMhz"` // 0 = 433.92; 315 in North America
+	Device       string  `yaml:"device" json:"device"`              // rtl_433 -d: index or :serial; "" = the first
+}
+
+// Channel names one input of a multi-input sensor and scales it:
+// value = raw × Scale + Offset, Scale 0 being treated as 1, or from Curve
+// when it has two or more points. What Input means and the raw unit
+// depend on the sensor, e.g. an ADC channel number in volts.
+type Channel struct {
+	Name   string  `yaml:"name" json:"name"`     // e.g. "trans_temp"
+	Input  string  `yaml:"input" json:"input"`   // e.g. "3", "28-0316a2790cff", "pressure"
+	Scale  float64 `yaml:"scale" json:"scale"`   // Multiplier
+	Offset float64 `yaml:"offset" json:"offset"` // Added after scaling
+	Unit   string  `yaml:"unit" json:"unit"`     // Display unit, e.g. "%", "°C"
+
+	// Calibration points [raw, value] in order of raw, interpolated
+	// linearly and held at the ends, for non-linear senders such as a
+	// resistive pressure sender on a pull-up
+	Curve [][2]float64 `yaml:"curve" json:"curve"`
+}
+
+// value scales a raw reading.
+func (c Channel) value(raw float64) float64 {
+	if len(c.Curve) >= 2 {
+		return interpolate(c.Curve, raw)
+	}
+	scale := c.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return raw*scale + c.Offset
+}
+
+// interpolate looks x up in points, sorted by x.
+func interpolate(points [][2]float64, x float64) float64 {
+	if x <= points[0][0] {
+		return points[0][1]
+	}
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		if x > p1[0] {
+			continue
+		}
+		if p1[0] == p0[0] {
+			return p1[1]
+		}
+		return p0[1] + (x-p0[0])*(p1[1]-p0[1])/(p1[0]-p0[0])
+	}
+	return points[len(points)-1][1]
+}
+
+// checkCurve reports a curve that isn't in order of raw value.
+func (c Channel) checkCurve() error {
+	for i := 1; i < len(c.Curve); i++ {
+		if c.Curve[i][0] < c.Curve[i-1][0] {
+			return fmt.Errorf("channel %q: curve points must be in order of raw value", c.Name)
+		}
+	}
+	return nil
+}
+
+// openSerial resolves and opens a sensor's serial port with 8N1 framing.
+func openSerial(tag, path string, baud int, timeout time.Duration) (serial.Port, string, error) {
+	resolved, err := device.Resolve(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", tag, err)
+	}
+	port, err := device.Open(resolved, &serial.Mode{
+		BaudRate: baud,
+		DataBits: 8,
+		Parity:   serial.NoParity,
+		StopBits: serial.OneStopBit,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: failed to open %s: %w", tag, resolved, err)
+	}
+	if err := port.SetReadTimeout(timeout); err != nil {
+		port.Close()
+		return nil, "", fmt.Errorf("%s: failed to set timeout: %w", tag, err)
+	}
+	return port, resolved, nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package sensors
+
+import "fmt"
+
+// spiDev is unavailable off Linux; SPI sensors fail to connect.
+type spiDev struct{}
+
+func openSPI(path string, mode uint8, speedHz uint32) (*spiDev, error) {
+	return nil, fmt.Errorf("spidev is only supported on Linux")
+}
+
+func (d *spiDev) transfer(tx []byte) ([]byte, error) {
+	return nil, fmt.Errorf("spidev is only supported on Linux")
+}
+
+func (d *spiDev) Close() error { return nil }
@@ -0,0 +1,257 @@
+package sensors
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TPMS reads tire pressure monitoring sensors through rtl_433 and a USB
+// RTL-SDR receiver, which prints one JSON object per line for each
+// transmission heard ("id", "pressure_kPa"/"pressure_PSI"/"pressure_bar",
+// "temperature_C"/"temperature_F"). Only rtl_433 is run, with arguments
+// built from the frequency and device settings, never a command line from
+// the config.
+//
+// Inputs are sensor IDs as rtl_433 prints them, one per corner, and
+// names the corner: channel {Name: "fl", Input: "0c6b4cc2"} reports
+// "tire_fl_kpa" and "tire_fl_temp" (°C). Sensors not mapped, such as
+// other cars', are logged once so their IDs can be found, then ignored.
+//
+// Sensors transmit every few seconds to a minute or more, so Read reports
+// each corner's latest values once a second until they're older than
+// tpmsMaxAge.
+type TPMS struct {
+	command []string
+	err     error // Bad settings, returned by Connect
+	chans   []Channel
+	cmd     *exec.Cmd
+	last    map[string]tpmsTire // By corner
+	heard   map[string]bool     // Unmapped IDs already logged
+	done    chan struct{}       // Closed when the command exits
+	exitErr error
+	mu      sync.Mutex
+}
+
+// tpmsTire is a corner's latest transmission.
+type tpmsTire struct {
+	kpa, tempC float64
+	hasTemp    bool
+	at         time.Time
+}
+
+const (
+	tpmsProgram   = "rtl_433"
+	tpmsFrequency = 433.92 // MHz
+	tpmsMaxAge    = 10 * time.Minute
+)
+
+// NewTPMS creates a TPMS provider running rtl_433 on cfg.FrequencyMHz
+// (default 433.92; 315 for North American sensors) with the RTL-SDR
+// cfg.Device.
+func NewTPMS(cfg Config) *TPMS {
+	command, err := tpmsCommand(cfg)
+	return &TPMS{command: command, err: err, chans: cfg.Channels, heard: make(map[string]bool)}
+}
+
+// tpmsCommand builds the rtl_433 command line.
+func tpmsCommand(cfg Config) ([]string, error) {
+	mhz := cfg.FrequencyMHz
+	if mhz == 0 {
+		mhz = tpmsFrequency
+	}
+	// RTL-SDR tuners cover about 24 MHz to 1.8 GHz
+	if !(mhz >= 24 && mhz <= 1800) {
+		return nil, fmt.Errorf("tpms: frequency %g MHz out of range", mhz)
+	}
+	command := []string{tpmsProgram, "-F", "json", "-M", "level", "-f", strconv.FormatFloat(mhz, 'f', -1, 64) + "M"}
+	if d := cfg.Device; d != "" {
+		if strings.HasPrefix(d, "-") || strings.ContainsAny(d, " \t\n") {
+			return nil, fmt.Errorf("tpms: bad device %q", d)
+		}
+		command = append(command, "-d", d)
+	}
+	return command, nil
+}
+
+func (t *TPMS) Name() string { return "TPMS (" + tpmsProgram + ")" }
+
+func (t *TPMS) Connect() error {
+	if t.err != nil {
+		return t.err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopLocked()
+
+	cmd := exec.Command(t.command[0], t.command[1:]...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("tpms: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("tpms: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("tpms: %w", err)
+	}
+	t.cmd, t.done, t.exitErr = cmd, make(chan struct{}), nil
+	t.last = make(map[string]tpmsTire)
+
+	// Keep the last line of stderr for when the command exits, e.g.
+	// rtl_433's "No supported devices found."
+	var lastErr string
+	errDone := make(chan struct{})
+	go func() {
+		defer close(errDone)
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() {
+			if line := strings.TrimSpace(sc.Text()); line != "" {
+				lastErr = line
+			}
+		}
+	}()
+	go func(done chan struct{}) {
+		t.scan(stdout)
+		<-errDone
+		why := "exited"
+		if err := cmd.Wait(); err != nil {
+			why = err.Error() // e.g. "exit status 1"
+		}
+		if lastErr != "" {
+			why += ": " + lastErr
+		}
+		t.mu.Lock()
+		t.exitErr = fmt.Errorf("tpms: %s %s", t.command[0], why)
+		t.mu.Unlock()
+		close(done)
+	}(t.done)
+
+	log.Printf("[tpms] started %s, %d sensor(s) mapped", strings.Join(t.command, " "), len(t.chans))
+	return nil
+}
+
+func (t *TPMS) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopLocked()
+	return nil
+}
+
+// stopLocked kills the command and waits for it to be reaped.
+func (t *TPMS) stopLocked() {
+	if t.cmd == nil {
+		return
+	}
+	t.cmd.Process.Kill()
+	done := t.done
+	t.cmd = nil
+	t.mu.Unlock()
+	<-done
+	t.mu.Lock()
+}
+
+// scan records each transmission from the command's output.
+func (t *TPMS) scan(r io.Reader) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		id, tire, ok := parseTPMS(sc.Bytes())
+		if !ok {
+			continue
+		}
+		t.mu.Lock()
+		corner := ""
+		for _, c := range t.chans {
+			if strings.EqualFold(c.Input, id) {
+				corner = c.Name
+				break
+			}
+		}
+		switch {
+		case corner != "":
+			t.last[corner] = tire
+		case !t.heard[id]:
+			t.heard[id] = true
+			log.Printf("[tpms] heard unmapped sensor %s: %.0f kPa", id, tire.kpa)
+		}
+		t.mu.Unlock()
+	}
+}
+
+// Read waits a second and returns each corner's latest values.
+func (t *TPMS) Read() (*Reading, error) {
+	t.mu.Lock()
+	done := t.done
+	t.mu.Unlock()
+	if done == nil {
+		return nil, fmt.Errorf("tpms: not connected")
+	}
+	select {
+	case <-done:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return nil, t.exitErr
+	case <-time.After(time.Second):
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := &Reading{Channels: make(map[string]float64), Status: "waiting", Stamp: time.Now().UnixMilli()}
+	for corner, tire := range t.last {
+		if time.Since(tire.at) > tpmsMaxAge {
+			continue
+		}
+		r.Channels["tire_"+corner+"_kpa"] = tire.kpa
+		if tire.hasTemp {
+			r.Channels["tire_"+corner+"_temp"] = tire.tempC
+		}
+		r.Status = "ok"
+	}
+	return r, nil
+}
+
+// parseTPMS decodes one line of rtl_433-style JSON, reporting false for
+// anything without an ID and a pressure.
+func parseTPMS(line []byte) (string, tpmsTire, bool) {
+	var m map[string]any
+	if json.Unmarshal(line, &m) != nil {
+		return "", tpmsTire{}, false
+	}
+	var id string
+	switch v := m["id"].(type) {
+	case string:
+		id = v
+	case float64:
+		id = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return "", tpmsTire{}, false
+	}
+	num := func(key string) (float64, bool) {
+		v, ok := m[key].(float64)
+		return v, ok
+	}
+	tire := tpmsTire{at: time.Now()}
+	if v, ok := num("pressure_kPa"); ok {
+		tire.kpa = v
+	} else if v, ok := num("pressure_PSI"); ok {
+		tire.kpa = v * 6.894757
+	} else if v, ok := num("pressure_bar"); ok {
+		tire.kpa = v * 100
+	} else {
+		return "", tpmsTire{}, false
+	}
+	if v, ok := num("temperature_C"); ok {
+		tire.tempC, tire.hasTemp = v, true
+	} else if v, ok := num("temperature_F"); ok {
+		tire.tempC, tire.hasTemp = (v-32)/1.8, true
+	}
+	return id, tire, true
+}
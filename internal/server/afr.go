@@ -0,0 +1,124 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/sensors"
+)
+
+// AFR source modes (afr.mode).
+const (
+	afrExternal = "external" // External wideband whenever it has a fresh reading
+	afrECU      = "ecu"      // Always the ECU's own AFR
+	afrLoad     = "load"     // External at or above load_kpa, ECU below (blended across blend_kpa)
+)
+
+// afrCrossCheck raises an alert when the ECU and external AFR disagree
+// for longer than the hold time — a failing sensor, a wiring fault or a
+// mis-set ECU calibration.
+type afrCrossCheck struct {
+	since time.Time // Disagreement started (zero = agreeing)
+}
+
+// externalAFR returns the first override_afr sensor with a fresh, valid
+// AFR reading.
+func (s *Server) externalAFR(readings map[string]*sensors.Reading) (name string, afr, lambda float64, ok bool) {
+	now := time.Now().UnixMilli()
+	for _, a := range s.sensors {
+		if !a.cfg.OverrideAFR {
+			continue
+		}
+		r := readings[a.cfg.Name]
+		if !r.OK() || now-r.Stamp > sensorStale.Milliseconds() {
+			continue
+		}
+		v, ok := r.Channels["afr"]
+		if !ok {
+			continue
+		}
+		lambda, ok := r.Channels["lambda"]
+		if !ok && s.cfg.ECU.Stoich > 0 {
+			lambda = v / s.cfg.ECU.Stoich
+		}
+		return a.cfg.Name, v, lambda, true
+	}
+	return "", 0, 0, false
+}
+
+// selectAFR fills e's AFR/lambda from the ECU, the external wideband or a
+// load-weighted blend of the two, per afr.mode. It returns the frame to
+// use (e is not modified), the source ("ecu", "external:<name>" or
+// "blend:<name>"), and a mismatch alert if the sources disagree.
+func (s *Server) selectAFR(now time.Time, e *ecu.DataFrame, readings map[string]*sensors.Reading) (*ecu.DataFrame, string, *Alert) {
+	if e == nil {
+		return nil, "", nil
+	}
+	name, extAFR, extLambda, ok := s.externalAFR(readings)
+	if !ok {
+		s.afrCheck.since = time.Time{}
+		return e, "ecu", nil
+	}
+	cfg := s.cfg.AFRSnapshot()
+	alert := s.afrCheck.check(now, e, extAFR, cfg)
+
+	w := 1.0 // Weight of the external reading
+	switch cfg.Mode {
+	case afrECU:
+		w = 0
+	case afrLoad:
+		w = loadWeight(float64(e.MAP), cfg.LoadKPa, cfg.BlendKPa)
+	}
+
+	out := *e
+	switch {
+	case w <= 0:
+		return e, "ecu", alert
+	case w >= 1:
+		out.AFR, out.Lambda = extAFR, extLambda
+		return &out, "external:" + name, alert
+	}
+	out.AFR = e.AFR + w*(extAFR-e.AFR)
+	if s.cfg.ECU.Stoich > 0 {
+		out.Lambda = out.AFR / s.cfg.ECU.Stoich
+	} else {
+		out.Lambda = e.Lambda + w*(extLambda-e.Lambda)
+	}
+	return &out, "blend:" + name, alert
+}
+
+// loadWeight is the external sensor's share at mapKPa: 0 well below
+// threshold, 1 at and above it, ramping linearly over the blend width
+// below it.
+func loadWeight(mapKPa, threshold, blend float64) float64 {
+	if blend <= 0 {
+		if mapKPa >= threshold {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(0, math.Min(1, (mapKPa-(threshold-blend))/blend))
+}
+
+// check updates the disagreement timer and returns an alert once the
+// ECU and external AFR have differed by more than MaxDiff for HoldSec
+// with the engine running.
+func (c *afrCrossCheck) check(now time.Time, e *ecu.DataFrame, extAFR float64, cfg AFRConfig) *Alert {
+	if cfg.MaxDiff <= 0 || e.RPM <= 500 || e.AFR <= 0 || math.Abs(e.AFR-extAFR) <= cfg.MaxDiff {
+		c.since = time.Time{}
+		return nil
+	}
+	if c.since.IsZero() {
+		c.since = now
+	}
+	if now.Sub(c.since).Seconds() < cfg.HoldSec {
+		return nil
+	}
+	return &Alert{
+		ID:    "afr_mismatch",
+		Level: alertWarning,
+		Text:  fmt.Sprintf("AFR MISMATCH ECU %.1f / WB %.1f", e.AFR, extAFR),
+	}
+}
@@ -0,0 +1,389 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/gpio"
+)
+
+const (
+	alarmTick  = 20 * time.Millisecond // Pattern resolution
+	alarmRetry = 5 * time.Second       // Before reopening a failed output
+	alarmRate  = 48000                 // aplay sample rate, Hz
+)
+
+// alarmPattern is a sound: alternating on and off times, starting on.
+type alarmPattern struct {
+	steps  []time.Duration
+	repeat bool // Loop until the alert ends; otherwise play once as it starts
+}
+
+func alarmSteps(ms ...int) []time.Duration {
+	out := make([]time.Duration, len(ms))
+	for i, v := range ms {
+		out[i] = time.Duration(v) * time.Millisecond
+	}
+	return out
+}
+
+// alarmPatterns are the sounds config can name.
+var alarmPatterns = map[string]alarmPattern{
+	"beep":       {steps: alarmSteps(150)},
+	"double":     {steps: alarmSteps(120, 100, 120)},
+	"triple":     {steps: alarmSteps(120, 100, 120, 100, 120)},
+	"slow":       {steps: alarmSteps(200, 800), repeat: true},
+	"pulse":      {steps: alarmSteps(250, 250), repeat: true},
+	"fast":       {steps: alarmSteps(100, 100), repeat: true},
+	"continuous": {steps: alarmSteps(1000), repeat: true},
+}
+
+// on reports whether the pattern sounds at elapsed since it started.
+func (p alarmPattern) on(elapsed time.Duration) bool {
+	var total time.Duration
+	for _, d := range p.steps {
+		total += d
+	}
+	if total == 0 || (!p.repeat && elapsed >= total) {
+		return false
+	}
+	elapsed %= total
+	for i, d := range p.steps {
+		if elapsed < d {
+			return i%2 == 0
+		}
+		elapsed -= d
+	}
+	return false
+}
+
+// AlarmStatus is the alarm's state, for GET /api/alarm.
+type AlarmStatus struct {
+	Output     string `json:"output"`               // alerts.alarm.output, "" when off
+	Alert      string `json:"alert,omitempty"`      // ID of the alert being sounded
+	Pattern    string `json:"pattern,omitempty"`    // Its pattern
+	MutedUntil int64  `json:"mutedUntil,omitempty"` // Unix ms; everything silent until then
+}
+
+// alarmState is what the alarm is doing, shared with the HTTP handlers.
+type alarmState struct {
+	mu         sync.Mutex
+	alert      string
+	pattern    string
+	mutedUntil time.Time
+}
+
+// alarmOutput makes the alarm's sound.
+type alarmOutput interface {
+	// play sounds, or stays silent, for the next d.
+	play(on bool, d time.Duration) error
+	Close() error
+}
+
+// runAlarm sounds the loudest unacknowledged, unmuted alert's pattern
+// until ctx ends. Acknowledging an alert (alert_ack) silences it too.
+func (s *Server) runAlarm(ctx context.Context) {
+	var (
+		out      alarmOutput
+		failAt   time.Time
+		failMsg  string    // Last open or play error, logged once
+		playing  string    // Alert ID and start of the pattern playing
+		started  time.Time // When it started
+		lastPlay time.Time
+	)
+	checkAlarmPatterns(s.cfg.AlarmSnapshot(), s.cfg.AlertRules())
+	defer func() {
+		if out != nil {
+			out.play(false, 0)
+			out.Close()
+		}
+	}()
+	ticker := time.NewTicker(alarmTick)
+	defer ticker.Stop()
+	for {
+		if out == nil && time.Since(failAt) >= alarmRetry {
+			var err error
+			if out, err = openAlarm(s.cfg.AlarmSnapshot(), s.cfg.GPIOSnapshot().Chip); err != nil {
+				if err.Error() != failMsg {
+					log.Printf("[alarm] %v (retrying every %v)", err, alarmRetry)
+					failMsg = err.Error()
+				}
+				out, failAt = nil, time.Now()
+			} else {
+				failMsg = ""
+			}
+		}
+
+		now := time.Now()
+		id, start, name := s.alarmSound(s.cfg.AlarmSnapshot(), s.cfg.AlertRules())
+		if key := id + "@" + start.String(); key != playing {
+			playing, started = key, now
+		}
+		s.alarm.mu.Lock()
+		if now.Before(s.alarm.mutedUntil) {
+			id, name = "", ""
+		}
+		s.alarm.alert, s.alarm.pattern = id, name
+		s.alarm.mu.Unlock()
+		on := id != "" && alarmPatterns[name].on(now.Sub(started))
+
+		// Sound for the time since the last tick: aplay's share of a
+		// dropped tick still has to be written, or it would underrun
+		if out != nil {
+			d := min(max(now.Sub(lastPlay), alarmTick), 5*alarmTick)
+			if err := out.play(on, d); err != nil {
+				log.Printf("[alarm] %v (reopening in %v)", err, alarmRetry)
+				out.Close()
+				out, failAt = nil, time.Now()
+			}
+		}
+		lastPlay = now
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// alarmSound picks what to sound: of the active alerts neither
+// acknowledged nor muted and with a known pattern, the highest level,
+// then the earliest. It returns the alert's ID and start, and the
+// pattern's name, or "" for silence.
+func (s *Server) alarmSound(cfg AlarmConfig, rules []AlertRule) (id string, start time.Time, pattern string) {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	best := -1
+	for aid, sp := range s.alertSpans {
+		if !sp.cleared.IsZero() || sp.acked || sp.muted {
+			continue
+		}
+		name := alarmPatternFor(cfg, rules, sp.alert)
+		if _, ok := alarmPatterns[name]; !ok {
+			continue
+		}
+		rank := alertRank(sp.alert.Level)
+		if rank > best || (rank == best && sp.start.Before(start)) {
+			best, id, start, pattern = rank, aid, sp.start, name
+		}
+	}
+	return id, start, pattern
+}
+
+// checkAlarmPatterns logs the pattern names in config that aren't
+// patterns; alerts given them stay silent.
+func checkAlarmPatterns(cfg AlarmConfig, rules []AlertRule) {
+	names := map[string]string{"warning": cfg.Warning, "danger": cfg.Danger, "critical": cfg.Critical}
+	for id, name := range cfg.Sounds {
+		names["sounds."+id] = name
+	}
+	for _, r := range rules {
+		id := r.ID
+		if id == "" {
+			id = r.Field
+		}
+		names["rule "+id] = r.Sound
+	}
+	for where, name := range names {
+		if _, ok := alarmPatterns[name]; name != "" && !ok {
+			log.Printf("[alarm] %s: unknown pattern %q", where, name)
+		}
+	}
+}
+
+// alarmPatternFor returns the name of a's pattern.
+func alarmPatternFor(cfg AlarmConfig, rules []AlertRule, a Alert) string {
+	if ruleID, ok := strings.CutPrefix(a.ID, ruleAlertPrefix); ok {
+		for _, r := range rules {
+			if id := r.ID; (id == ruleID || (id == "" && r.Field == ruleID)) && r.Sound != "" {
+				return r.Sound
+			}
+		}
+	}
+	if p, ok := cfg.Sounds[a.ID]; ok {
+		return p
+	}
+	switch a.Level {
+	case alertCritical:
+		return cfg.Critical
+	case alertDanger:
+		return cfg.Danger
+	}
+	return cfg.Warning
+}
+
+// muteAlarm silences the alerts sounding now until they end or reach a
+// higher level, and returns how many there were.
+func (s *Server) muteAlarm() int {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	n := 0
+	for _, sp := range s.alertSpans {
+		if sp.cleared.IsZero() && !sp.muted {
+			sp.muted, sp.mutedRank = true, alertRank(sp.alert.Level)
+			n++
+		}
+	}
+	return n
+}
+
+// openAlarm opens the configured output.
+func openAlarm(cfg AlarmConfig, chip string) (alarmOutput, error) {
+	switch cfg.Output {
+	case "gpio":
+		p, err := gpio.OpenPin(chip, cfg.Line, cfg.ActiveLow)
+		if err != nil {
+			return nil, err
+		}
+		return &gpioAlarm{pin: p}, nil
+	case "aplay":
+		return openAplay(cfg.Device, cfg.ToneHz)
+	}
+	return nil, fmt.Errorf("unknown output %q", cfg.Output)
+}
+
+// gpioAlarm is an active buzzer on a GPIO line.
+type gpioAlarm struct {
+	pin *gpio.Pin
+	on  bool
+}
+
+func (g *gpioAlarm) play(on bool, d time.Duration) error {
+	if on == g.on {
+		return nil
+	}
+	if err := g.pin.Set(on); err != nil {
+		return err
+	}
+	g.on = on
+	return nil
+}
+
+func (g *gpioAlarm) Close() error {
+	g.pin.Set(false)
+	return g.pin.Close()
+}
+
+// aplayAlarm plays a tone through ALSA's aplay, fed raw samples on stdin.
+// Short pipe and ALSA buffers keep the sound within about 150 ms of the
+// alerts.
+type aplayAlarm struct {
+	cmd   *exec.Cmd
+	in    *os.File
+	step  float64 // Phase per sample
+	phase float64
+	buf   []byte
+}
+
+func openAplay(device string, hz float64) (*aplayAlarm, error) {
+	args := []string{"-q", "-t", "raw", "-f", "S16_LE", "-c", "1", "-r", strconv.Itoa(alarmRate), "-B", "100000"}
+	if device != "" {
+		args = append(args, "-D", device)
+	}
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	shrinkPipe(pw)
+	cmd := exec.Command("aplay", args...)
+	cmd.Stdin = pr
+	err = cmd.Start()
+	pr.Close()
+	if err != nil {
+		pw.Close()
+		return nil, fmt.Errorf("aplay: %w", err)
+	}
+	return &aplayAlarm{cmd: cmd, in: pw, step: 2 * math.Pi * hz / alarmRate}, nil
+}
+
+func (a *aplayAlarm) play(on bool, d time.Duration) error {
+	n := int(d.Seconds() * alarmRate)
+	a.buf = a.buf[:0]
+	for i := 0; i < n; i++ {
+		var v int16
+		if on {
+			v = int16(0.9 * math.MaxInt16 * math.Sin(a.phase))
+			a.phase = math.Mod(a.phase+a.step, 2*math.Pi)
+		} else {
+			a.phase = 0 // Each beep starts at a zero crossing
+		}
+		a.buf = binary.LittleEndian.AppendUint16(a.buf, uint16(v))
+	}
+	if _, err := a.in.Write(a.buf); err != nil {
+		return fmt.Errorf("aplay: %w", err)
+	}
+	return nil
+}
+
+func (a *aplayAlarm) Close() error {
+	a.in.Close()
+	return a.cmd.Wait()
+}
+
+// handleAlarm reports what the alarm is sounding.
+//
+//	GET /api/alarm — AlarmStatus
+func (s *Server) handleAlarm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	st := AlarmStatus{Output: s.cfg.AlarmSnapshot().Output}
+	s.alarm.mu.Lock()
+	st.Alert, st.Pattern = s.alarm.alert, s.alarm.pattern
+	if time.Now().Before(s.alarm.mutedUntil) {
+		st.MutedUntil = s.alarm.mutedUntil.UnixMilli()
+	}
+	s.alarm.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+// handleAlarmMute silences the alarm.
+//
+//	POST   /api/alarm/mute  — mute the alerts sounding now until they end or
+//	                          get worse; {"minutes": N} mutes everything for N minutes
+//	DELETE /api/alarm/mute  — end a timed mute
+func (s *Server) handleAlarmMute(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req struct {
+			Minutes float64 `json:"minutes"`
+		}
+		if body, err := io.ReadAll(r.Body); err == nil && len(body) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+		if req.Minutes > 0 {
+			s.alarm.mu.Lock()
+			s.alarm.mutedUntil = time.Now().Add(time.Duration(req.Minutes * float64(time.Minute)))
+			s.alarm.mu.Unlock()
+			log.Printf("[alarm] muted for %g min", req.Minutes)
+		} else {
+			s.muteAlarm()
+		}
+	case http.MethodDelete:
+		s.alarm.mu.Lock()
+		s.alarm.mutedUntil = time.Time{}
+		s.alarm.mu.Unlock()
+	default:
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// shrinkPipe cuts f's pipe buffer to a page, which at alarmRate is 40 ms
+// of sound, so the alarm isn't heard a pipe's worth late.
+func shrinkPipe(f *os.File) {
+	unix.FcntlInt(f.Fd(), unix.F_SETPIPE_SZ, os.Getpagesize())
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// alertsFile is the alert history inside the data directory.
+const alertsFile = storage.DirState + "/alerts.json"
+
+const (
+	alertHistoryMax = 500         // Events kept, oldest dropped first
+	alertEventsMax  = 50          // Events held for the next full frame
+	alertRejoin     = time.Second // Cleared this long before an alert ends
+)
+
+// AlertEvent is an alert starting or ending.
+type AlertEvent struct {
+	Alert
+	Event      string `json:"event"`                // "start" or "end"
+	At         int64  `json:"at"`                   // Unix ms
+	DurationMs int64  `json:"durationMs,omitempty"` // Time active, on "end"
+}
+
+// alertSpan is an alert from its start until it has been clear for
+// alertRejoin.
+type alertSpan struct {
+	alert     Alert
+	start     time.Time
+	cleared   time.Time // Zero while active
+	acked     bool      // Acknowledged, until it ends or gets worse
+	ackedRank int       // alertRank when acknowledged
+	muted     bool      // Alarm muted, until it ends or gets worse
+	mutedRank int       // alertRank when muted
+}
+
+// alertEventLocked queues ev for the next frame and the notifier, and
+// adds it to the history. Called with alertMu held.
+func (s *Server) alertEventLocked(ev AlertEvent) {
+	s.alertEvents = append(s.alertEvents, ev)
+	if n := len(s.alertEvents) - alertEventsMax; n > 0 {
+		s.alertEvents = s.alertEvents[n:]
+	}
+	s.alertLog = append(s.alertLog, ev)
+	if n := len(s.alertLog) - alertHistoryMax; n > 0 {
+		s.alertLog = append([]AlertEvent(nil), s.alertLog[n:]...)
+	}
+	s.alertLogDirty = true
+	s.queueNotifyLocked(ev)
+}
+
+// takeAlertEvents returns the events since the last call, for a frame.
+func (s *Server) takeAlertEvents() []AlertEvent {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	ev := s.alertEvents
+	s.alertEvents = nil
+	return ev
+}
+
+// loadAlertHistory restores the alert history from disk.
+func (s *Server) loadAlertHistory() {
+	var events []AlertEvent
+	if err := s.store.ReadJSON(alertsFile, &events); err != nil {
+		return
+	}
+	if n := len(events) - alertHistoryMax; n > 0 {
+		events = events[n:]
+	}
+	s.alertMu.Lock()
+	s.alertLog = events
+	s.alertMu.Unlock()
+}
+
+// saveAlertHistory persists the alert history, if it has changed.
+func (s *Server) saveAlertHistory() {
+	s.alertMu.Lock()
+	if !s.alertLogDirty {
+		s.alertMu.Unlock()
+		return
+	}
+	s.alertLogDirty = false
+	events := append([]AlertEvent(nil), s.alertLog...)
+	s.alertMu.Unlock()
+	if err := s.store.WriteJSON(alertsFile, events); err != nil {
+		log.Printf("[alerts] save failed: %v", err)
+	}
+}
+
+// handleAlerts serves the active alerts and their history.
+//
+//	GET    /api/alerts[?limit=N] — active alerts, and events newest first
+//	DELETE /api/alerts           — clear the history
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		limit := alertHistoryMax
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, "limit must be a positive number", 400)
+				return
+			}
+			limit = n
+		}
+		s.alertMu.Lock()
+		active := append([]Alert{}, s.alerts...)
+		history := make([]AlertEvent, 0, min(limit, len(s.alertLog)))
+		for i := len(s.alertLog) - 1; i >= 0 && len(history) < limit; i-- {
+			history = append(history, s.alertLog[i])
+		}
+		s.alertMu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Active  []Alert      `json:"active"`
+			History []AlertEvent `json:"history"`
+		}{active, history})
+
+	case http.MethodDelete:
+		s.alertMu.Lock()
+		s.alertLog, s.alertLogDirty = nil, false
+		s.alertMu.Unlock()
+		if err := s.store.Remove(alertsFile); err != nil {
+			log.Printf("[alerts] %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
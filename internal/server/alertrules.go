@@ -0,0 +1,145 @@
+package server
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// ruleAlertPrefix starts the Alert.ID of alerts raised by rules, so they
+// can't clash with the built-in ones.
+const ruleAlertPrefix = "rule:"
+
+// ruleEngine evaluates the configured alert rules against each frame.
+type ruleEngine struct {
+	mu     sync.Mutex
+	state  map[string]*ruleState // By rule ID
+	warned map[string]bool       // Rules already reported as broken
+}
+
+// ruleState is one rule's progress towards, or time in, its alert.
+type ruleState struct {
+	since  time.Time // Condition has held since, zero when it doesn't
+	active bool
+}
+
+// eval returns the alerts of the rules currently raised. e is nil when
+// the ECU data is stale, which holds no rule.
+func (r *ruleEngine) eval(now time.Time, e *ecu.DataFrame, rules []AlertRule) []Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state == nil {
+		r.state = make(map[string]*ruleState)
+		r.warned = make(map[string]bool)
+	}
+	var out []Alert
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		id := rule.ID
+		if id == "" {
+			id = rule.Field
+		}
+		if seen[id] {
+			r.warnLocked(id, "duplicate rule id")
+			continue
+		}
+		seen[id] = true
+		st := r.state[id]
+		if st == nil {
+			st = &ruleState{}
+			r.state[id] = st
+		}
+
+		v, holds := r.checkLocked(id, rule, e, st.active)
+		if !holds {
+			st.since, st.active = time.Time{}, false
+			continue
+		}
+		if st.since.IsZero() {
+			st.since = now
+		}
+		if !st.active && now.Sub(st.since).Seconds() < rule.DurationS {
+			continue
+		}
+		st.active = true
+		out = append(out, ruleAlert(id, rule, v))
+	}
+	for id := range r.state {
+		if !seen[id] {
+			delete(r.state, id) // Rule removed from the config
+		}
+	}
+	return out
+}
+
+// checkLocked returns rule's channel value and whether the rule's
+// condition holds. An active rule's threshold is moved back by the
+// hysteresis.
+func (r *ruleEngine) checkLocked(id string, rule AlertRule, e *ecu.DataFrame, active bool) (float64, bool) {
+	if e == nil || e.RPM < rule.MinRPM {
+		return 0, false
+	}
+	v, ok := e.Value(rule.Field)
+	if !ok {
+		r.warnLocked(id, "no channel "+strconv.Quote(rule.Field))
+		return 0, false
+	}
+	th := rule.Value
+	if active {
+		switch rule.Op {
+		case ">", ">=":
+			th -= rule.Hysteresis
+		case "<", "<=":
+			th += rule.Hysteresis
+		}
+	}
+	switch rule.Op {
+	case ">":
+		return v, v > th
+	case ">=":
+		return v, v >= th
+	case "<":
+		return v, v < th
+	case "<=":
+		return v, v <= th
+	case "==":
+		return v, v == th
+	case "!=":
+		return v, v != th
+	}
+	r.warnLocked(id, "unknown op "+strconv.Quote(rule.Op))
+	return v, false
+}
+
+// warnLocked logs a broken rule once.
+func (r *ruleEngine) warnLocked(id, msg string) {
+	if r.warned[id+msg] {
+		return
+	}
+	r.warned[id+msg] = true
+	log.Printf("[alerts] rule %q: %s", id, msg)
+}
+
+// ruleAlert is the alert raised by rule at channel value v.
+func ruleAlert(id string, rule AlertRule, v float64) Alert {
+	level := rule.Level
+	switch level {
+	case alertCritical, alertDanger, alertWarning:
+	default:
+		level = alertWarning
+	}
+	label := rule.Label
+	if label == "" {
+		label = strings.ToUpper(rule.Field)
+	}
+	return Alert{
+		ID:    ruleAlertPrefix + id,
+		Level: level,
+		Text:  label + " " + strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64),
+	}
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/tune"
+)
+
+// Alert levels, matching the dash warning banner.
+const (
+	alertCritical = "critical"
+	alertDanger   = "danger"
+	alertWarning  = "warning"
+)
+
+// Alert is an active threshold alert.
+type Alert struct {
+	ID    string `json:"id"`              // Stable key, e.g. "clt"
+	Level string `json:"level"`           // "critical", "danger" or "warning"
+	Text  string `json:"text"`            // Human-readable, e.g. "COOLANT 106°C"
+	Tune  string `json:"tune,omitempty"`  // What the uploaded tune says, e.g. "tune limit 7000"
+	Acked bool   `json:"acked,omitempty"` // Acknowledged (alert_ack): the banner leaves it out
+}
+
+// evalAlerts checks an ECU frame against the configured thresholds.
+// The rules mirror the dash's warning banner (dash.js) and, like it,
+// only apply while the engine is running. With a tune loaded, reaching
+// its rev limiter or boost cut also alerts, and alerts are annotated with
+// the tune's values.
+func evalAlerts(e *ecu.DataFrame, t ThresholdConfig, ref *tune.Reference) []Alert {
+	if e == nil || e.RPM <= 500 {
+		return nil
+	}
+	var out []Alert
+	add := func(id, level, format string, args ...any) {
+		out = append(out, Alert{ID: id, Level: level, Text: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case e.Coolant >= t.CLTDanger:
+		add("clt", alertCritical, "COOLANT %.0f°C", e.Coolant)
+	case e.Coolant >= t.CLTWarn:
+		add("clt", alertWarning, "COOLANT %.0f°C", e.Coolant)
+	}
+	switch {
+	case e.IAT >= t.IATDanger:
+		add("iat", alertCritical, "INTAKE HOT %.0f°C", e.IAT)
+	case e.IAT >= t.IATWarn:
+		add("iat", alertWarning, "INTAKE %.0f°C", e.IAT)
+	}
+	if e.OilPressure < t.OilPWarn && e.RPM > 1000 {
+		add("oil", alertCritical, "LOW OIL %d PSI", e.OilPressure)
+	}
+	if t.KnockWarn > 0 && e.KnockCor >= t.KnockWarn {
+		add("knock", alertCritical, "KNOCK -%d°", e.KnockCor)
+	}
+	if e.AFR > 16.5 || e.AFR < 10.5 {
+		add("afr", alertDanger, "AFR %.1f", e.AFR)
+	}
+	if t.PWImbalanceWarn > 0 && e.PWImbalance >= t.PWImbalanceWarn {
+		add("pw", alertWarning, "INJ IMBALANCE %.0f%%", e.PWImbalance)
+	}
+	cam, vvtErr := 1, e.VVT1Error
+	if math.Abs(e.VVT2Error) > math.Abs(vvtErr) {
+		cam, vvtErr = 2, e.VVT2Error
+	}
+	switch {
+	case t.VVTErrorDanger > 0 && math.Abs(vvtErr) >= t.VVTErrorDanger:
+		add("vvt", alertDanger, "VVT%d ERROR %+.0f°", cam, vvtErr)
+	case t.VVTErrorWarn > 0 && math.Abs(vvtErr) >= t.VVTErrorWarn:
+		add("vvt", alertWarning, "VVT%d ERROR %+.0f°", cam, vvtErr)
+	}
+	switch {
+	case e.BatteryVoltage < t.BattLow:
+		add("batt", alertWarning, "LOW BATT %.1fV", e.BatteryVoltage)
+	case e.BatteryVoltage > t.BattHigh:
+		add("batt", alertWarning, "HIGH BATT %.1fV", e.BatteryVoltage)
+	}
+	if ref != nil {
+		if lim := revLimit(ref); lim > 0 && float64(e.RPM) >= lim {
+			add("rev", alertDanger, "REV LIMIT %d RPM", e.RPM)
+		}
+		if ref.BoostLimit > 0 && float64(e.MAP) >= ref.BoostLimit {
+			add("boost", alertCritical, "BOOST CUT %d kPa", e.MAP)
+		}
+	}
+	return annotateAlerts(out, ref, float64(e.RPM), float64(e.MAP), e.TPS)
+}
+
+// setAlerts records the active alerts, logs their starts and ends, and
+// returns those newly raised (not active on the previous frame). An alert
+// that comes back within alertRejoin carries on rather than ending, so a
+// reading flickering across a threshold is one event, not dozens.
+func (s *Server) setAlerts(now time.Time, alerts []Alert) []Alert {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	if s.alertSpans == nil {
+		s.alertSpans = make(map[string]*alertSpan)
+	}
+	var raised []Alert
+	active := make(map[string]bool, len(alerts))
+	for i, a := range alerts {
+		active[a.ID] = true
+		if sp := s.alertSpans[a.ID]; sp != nil {
+			if sp.acked && alertRank(a.Level) > sp.ackedRank {
+				sp.acked = false // Worse than when acknowledged
+			}
+			if sp.muted && alertRank(a.Level) > sp.mutedRank {
+				sp.muted = false
+			}
+			alerts[i].Acked = sp.acked
+			sp.alert, sp.cleared = alerts[i], time.Time{}
+			continue
+		}
+		s.alertSpans[a.ID] = &alertSpan{alert: a, start: now}
+		raised = append(raised, a)
+		s.alertEventLocked(AlertEvent{Alert: a, Event: "start", At: now.UnixMilli()})
+	}
+	for id, sp := range s.alertSpans {
+		switch {
+		case active[id]:
+		case sp.cleared.IsZero():
+			sp.cleared = now
+		case now.Sub(sp.cleared) >= alertRejoin:
+			delete(s.alertSpans, id)
+			s.alertEventLocked(AlertEvent{
+				Alert:      sp.alert,
+				Event:      "end",
+				At:         sp.cleared.UnixMilli(),
+				DurationMs: sp.cleared.Sub(sp.start).Milliseconds(),
+			})
+		}
+	}
+	s.alerts = alerts
+	return raised
+}
+
+// ackAlerts acknowledges the active alerts, so the dash stops showing
+// them until they end or reach a higher level. It returns how many there
+// were.
+func (s *Server) ackAlerts() int {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	n := 0
+	for _, sp := range s.alertSpans {
+		if sp.cleared.IsZero() {
+			sp.acked, sp.ackedRank = true, alertRank(sp.alert.Level)
+			n++
+		}
+	}
+	return n
+}
+
+// activeAlerts returns the alerts active on the latest frame.
+func (s *Server) activeAlerts() []Alert {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	return append([]Alert(nil), s.alerts...)
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/autox"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// autoxFile is the autocross run list inside the data directory.
+const autoxFile = storage.DirRecords + "/autocross.json"
+
+// loadAutox restores the autocross run list from disk.
+func (s *Server) loadAutox() {
+	var runs []autox.Run
+	if err := s.store.ReadJSON(autoxFile, &runs); err != nil {
+		return
+	}
+	s.autox.Restore(runs)
+	log.Printf("[autox] loaded %d runs", len(runs))
+}
+
+// saveAutox persists the autocross run list.
+func (s *Server) saveAutox() {
+	if err := s.store.WriteJSON(autoxFile, s.autox.Runs()); err != nil {
+		log.Printf("[autox] save failed: %v", err)
+	}
+}
+
+// updateAutox feeds the latest speed/position to the run state machine
+// and returns the status for the outgoing frame (nil when idle and no
+// runs have been recorded).
+func (s *Server) updateAutox(now time.Time, speed *SpeedData, g *gps.Data) *autox.Status {
+	smp := autox.Sample{SpeedKph: speed.Value}
+	if g != nil && g.Valid {
+		smp.Pos = &track.Point{Lat: g.Latitude, Lon: g.Longitude}
+		smp.Heading = g.Heading
+		smp.AltM = g.Altitude
+	}
+	var finish *track.Line
+	if t := s.activeTrack(); t != nil {
+		finish = t.StartFinish
+	}
+
+	if run := s.autox.Update(now, smp, finish); run != nil {
+		log.Printf("[autox] %s run %d: %.3fs (%s), %.0f m, +%.1f m climb",
+			run.Mode, run.ID, float64(run.RawMs)/1000, run.End, run.DistanceM, run.ClimbM)
+		s.saveAutox()
+	}
+
+	st := s.autox.Status(now)
+	if st.State == autox.StateIdle && st.Last == nil {
+		return nil
+	}
+	return &st
+}
+
+// handleAutocross serves the live autocross state.
+//
+//	GET /api/autocross — state, last and best run
+func (s *Server) handleAutocross(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.autox.Status(time.Now()))
+}
+
+// handleAutocrossArm arms a run (POST) or disarms/abandons it (DELETE).
+// Optional JSON body: {"mode": "hillclimb"} (default "autocross").
+func (s *Server) handleAutocrossArm(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req struct {
+			Mode string `json:"mode"`
+		}
+		if body, err := io.ReadAll(r.Body); err == nil && len(body) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+		if err := s.autox.Arm(req.Mode); err != nil {
+			http.Error(w, err.Error(), 409)
+			return
+		}
+		log.Printf("[autox] armed (%s)", s.autox.Status(time.Now()).Mode)
+	case http.MethodDelete:
+		s.autox.Cancel()
+		log.Printf("[autox] disarmed")
+	default:
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// handleAutocrossPenalty records cone penalties against a run.
+// JSON body: {"run": 3, "cones": 2, "dnf": false}. "run" omitted or 0
+// means the latest run; "add" increments the cone count instead of
+// setting it (e.g. a "+1 cone" button).
+func (s *Server) handleAutocrossPenalty(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	var req struct {
+		Run   int   `json:"run"`
+		Cones *int  `json:"cones"`
+		Add   int   `json:"add"`
+		DNF   *bool `json:"dnf"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	cones, dnf, err := s.autox.Penalty(req.Run)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	if req.Cones != nil {
+		cones = *req.Cones
+	}
+	cones += req.Add
+	if req.DNF != nil {
+		dnf = *req.DNF
+	}
+
+	run, err := s.autox.SetPenalty(req.Run, cones, dnf)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	s.saveAutox()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// handleAutocrossRuns serves the run-list comparison.
+//
+//	GET    /api/autocross/runs[?mode=hillclimb][&ids=1,4,5] — runs ranked by total time
+//	DELETE /api/autocross/runs             — clear the run list
+func (s *Server) handleAutocrossRuns(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		var ids []int
+		if v := r.URL.Query().Get("ids"); v != "" {
+			for _, f := range strings.Split(v, ",") {
+				id, err := strconv.Atoi(strings.TrimSpace(f))
+				if err != nil {
+					http.Error(w, "bad run id: "+f, 400)
+					return
+				}
+				ids = append(ids, id)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.autox.Compare(r.URL.Query().Get("mode"), ids))
+
+	case http.MethodDelete:
+		s.autox.Clear()
+		s.saveAutox()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
+
+// handleAutocrossProfile serves one run's elevation-versus-distance
+// profile with its distance and vertical metres climbed.
+//
+//	GET /api/autocross/profile[?id=3] — latest run if id is omitted
+func (s *Server) handleAutocrossProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	id := 0
+	if v := r.URL.Query().Get("id"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "bad run id: "+v, 400)
+			return
+		}
+		id = n
+	}
+	run, err := s.autox.Profile(id)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+const (
+	boostMinTPS     = 80.0                   // %; below this the target isn't expected to be reached
+	boostErrorTau   = 500 * time.Millisecond // Smoothing of the tracking error
+	boostAlertHold  = 2 * time.Second        // Saturated and off target this long to alert
+	boostMaxTickGap = time.Second            // Longer gaps (ECU dropouts) aren't counted
+)
+
+// BoostStatus is boost control diagnostics for the current drive:
+// how well MAP tracks the ECU's boost target and how often the solenoid
+// duty sits at an end stop, where the controller has no authority left.
+// Statistics only count time under boost control (target above baro at
+// wide throttle).
+type BoostStatus struct {
+	Active     bool    `json:"active"`     // Under boost control now
+	Error      float64 `json:"error"`      // Target - MAP, smoothed, kPa (+ = under target)
+	ActiveSec  float64 `json:"activeSec"`  // Time under boost control this drive
+	SatLowPct  float64 `json:"satLowPct"`  // % of ActiveSec at 0% duty
+	SatHighPct float64 `json:"satHighPct"` // % of ActiveSec at 100% duty
+	MeanAbsErr float64 `json:"meanAbsErr"` // Mean |target - MAP|, kPa
+	MaxOver    float64 `json:"maxOver"`    // Largest MAP over target, kPa
+}
+
+// boostMonitor accumulates BoostStatus from ECU frames.
+type boostMonitor struct {
+	mu      sync.Mutex
+	last    time.Time // Previous active tick, zero when not under control
+	err     float64   // Smoothed target - MAP
+	active  time.Duration
+	satLow  time.Duration
+	satHigh time.Duration
+	absErr  float64 // Integral of |error| over active time, kPa·s
+	maxOver float64
+	since   time.Time // When duty saturated off target, zero otherwise
+}
+
+// update feeds one broadcast tick and returns the status (nil before
+// boost control has ever been active) and any alert. warn is the
+// tracking error in kPa that, with the duty saturated for
+// boostAlertHold, raises an alert; 0 never alerts.
+func (m *boostMonitor) update(now time.Time, e *ecu.DataFrame, warn float64) (*BoostStatus, *Alert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e == nil || !boostControlled(e) {
+		m.last, m.since = time.Time{}, time.Time{}
+		return m.statusLocked(false), nil
+	}
+
+	target := float64(e.BoostTarget) * 2
+	errKPa := target - float64(e.MAP)
+	if m.last.IsZero() {
+		m.err = errKPa
+	} else if dt := now.Sub(m.last); dt > 0 && dt <= boostMaxTickGap {
+		m.err += (errKPa - m.err) * (1 - math.Exp(-dt.Seconds()/boostErrorTau.Seconds()))
+		m.active += dt
+		m.absErr += math.Abs(errKPa) * dt.Seconds()
+		switch {
+		case e.BoostDuty == 0:
+			m.satLow += dt
+		case e.BoostDuty >= 100:
+			m.satHigh += dt
+		}
+	}
+	m.last = now
+	m.maxOver = math.Max(m.maxOver, -errKPa)
+
+	// Off target with the duty already at the end stop that should
+	// correct it: the solenoid or wastegate isn't responding, or (under
+	// target) there's a boost leak
+	var alert *Alert
+	under := warn > 0 && m.err >= warn && e.BoostDuty >= 100
+	over := warn > 0 && -m.err >= warn && e.BoostDuty == 0
+	if !under && !over {
+		m.since = time.Time{}
+	} else if m.since.IsZero() {
+		m.since = now
+	} else if now.Sub(m.since) >= boostAlertHold {
+		if under {
+			alert = &Alert{ID: "boost_ctl", Level: alertWarning,
+				Text: fmt.Sprintf("BOOST LOW -%.0f kPa AT 100%% DUTY", m.err)}
+		} else {
+			alert = &Alert{ID: "boost_ctl", Level: alertDanger,
+				Text: fmt.Sprintf("OVERBOOST +%.0f kPa AT 0%% DUTY", -m.err)}
+		}
+	}
+	return m.statusLocked(true), alert
+}
+
+// boostControlled reports whether the ECU is asking for boost at a
+// throttle where it should get it.
+func boostControlled(e *ecu.DataFrame) bool {
+	baro := float64(e.Baro)
+	if baro == 0 {
+		baro = 100
+	}
+	return e.RPM > 500 && e.TPS >= boostMinTPS && float64(e.BoostTarget)*2 > baro
+}
+
+func (m *boostMonitor) statusLocked(active bool) *BoostStatus {
+	if m.active == 0 && !active {
+		return nil
+	}
+	st := &BoostStatus{
+		Active:    active,
+		ActiveSec: math.Round(m.active.Seconds()*10) / 10,
+		MaxOver:   math.Round(math.Max(0, m.maxOver)),
+	}
+	if active {
+		st.Error = math.Round(m.err*10) / 10
+	}
+	if secs := m.active.Seconds(); secs > 0 {
+		st.SatLowPct = math.Round(m.satLow.Seconds()/secs*1000) / 10
+		st.SatHighPct = math.Round(m.satHigh.Seconds()/secs*1000) / 10
+		st.MeanAbsErr = math.Round(m.absErr/secs*10) / 10
+	}
+	return st
+}
+
+// reset starts a new drive's statistics.
+func (m *boostMonitor) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last, m.since = time.Time{}, time.Time{}
+	m.err, m.absErr, m.maxOver = 0, 0, 0
+	m.active, m.satLow, m.satHigh = 0, 0, 0
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/canbus"
+)
+
+const (
+	canOutTick  = 5 * time.Millisecond // Sender resolution
+	canOutRetry = 5 * time.Second      // Between attempts to open the interface
+)
+
+// canOutMessage is a configured CAN message and its schedule.
+type canOutMessage struct {
+	cfg      CANMessageConfig
+	signals  []canbus.Signal
+	length   int
+	interval time.Duration
+	next     time.Time
+}
+
+// newCANOutMessage resolves mc's defaults and checks its signals fit.
+func newCANOutMessage(mc CANMessageConfig) (*canOutMessage, error) {
+	m := &canOutMessage{cfg: mc, length: mc.Length}
+	if m.length == 0 {
+		m.length = 8
+	}
+	if m.length < 1 || m.length > 8 {
+		return nil, fmt.Errorf("length %d bytes (1-8)", m.length)
+	}
+	if (!mc.Extended && mc.ID > 0x7FF) || mc.ID > 0x1FFFFFFF {
+		return nil, fmt.Errorf("ID out of range (set extended for 29-bit IDs)")
+	}
+	rate := mc.RateHz
+	if rate <= 0 {
+		rate = 10
+	}
+	m.interval = time.Second / time.Duration(rate)
+	scratch := make([]byte, m.length)
+	for _, sc := range mc.Signals {
+		sig := canbus.Signal{
+			Start:     sc.Start,
+			Length:    sc.Length,
+			BigEndian: sc.BigEndian,
+			Signed:    sc.Signed,
+			Factor:    sc.Factor,
+			Offset:    sc.Offset,
+		}
+		if err := sig.Put(scratch, 0); err != nil {
+			return nil, fmt.Errorf("%s: %w", sc.Channel, err)
+		}
+		m.signals = append(m.signals, sig)
+	}
+	return m, nil
+}
+
+// data packs the message from the tick's values, or reports false if one
+// of its channels has none.
+func (m *canOutMessage) data(in *gaugeInput) ([]byte, bool) {
+	out := make([]byte, m.length)
+	for i, sc := range m.cfg.Signals {
+		v, ok := in.value(sc.Channel)
+		if !ok {
+			return nil, false
+		}
+		m.signals[i].Put(out, v)
+	}
+	return out, true
+}
+
+// runCANOut sends the configured messages at their rates until ctx ends,
+// from the same per-tick data as the auxiliary gauges.
+func (s *Server) runCANOut(ctx context.Context) {
+	cfg := s.cfg.CANOutSnapshot()
+	var msgs []*canOutMessage
+	for _, mc := range cfg.Messages {
+		m, err := newCANOutMessage(mc)
+		if err != nil {
+			log.Printf("[canout] message 0x%X: %v", mc.ID, err)
+			continue
+		}
+		msgs = append(msgs, m)
+	}
+	if len(msgs) == 0 {
+		return
+	}
+
+	var bus *canbus.Bus
+	for logged := false; bus == nil; {
+		b, err := canbus.Open(cfg.Interface)
+		if err == nil {
+			bus = b
+			break
+		}
+		if !logged {
+			log.Printf("[canout] %s: %v (retrying every %v)", cfg.Interface, err, canOutRetry)
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(canOutRetry):
+		}
+	}
+	defer bus.Close()
+	log.Printf("[canout] sending %d message(s) on %s", len(msgs), cfg.Interface)
+
+	ticker := time.NewTicker(canOutTick)
+	defer ticker.Stop()
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			in := s.gauges.latest.Load()
+			if in == nil {
+				continue
+			}
+			for _, m := range msgs {
+				if now.Before(m.next) {
+					continue
+				}
+				m.next = m.next.Add(m.interval)
+				if m.next.Before(now) {
+					m.next = now.Add(m.interval) // Fell behind; don't burst
+				}
+				data, ok := m.data(in)
+				if !ok {
+					continue
+				}
+				var err error
+				if m.cfg.Extended {
+					err = bus.WriteExtended(m.cfg.ID, data)
+				} else {
+					err = bus.Write(m.cfg.ID, data)
+				}
+				// A bus with nothing else on it fails every write (no ACKs)
+				switch {
+				case err != nil && !failing:
+					log.Printf("[canout] send failed: %v", err)
+				case err == nil && failing:
+					log.Printf("[canout] sending again")
+				}
+				failing = err != nil
+			}
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/msgpack"
+)
+
+// clientQueueLen is how many frames may wait for a client (~0.4 s at
+// 20 Hz). A client further behind than that gets the newest frames, not
+// a growing backlog.
+const clientQueueLen = 8
+
+const (
+	clientWriteTimeout = 5 * time.Second        // A write stuck this long drops the client
+	clientSlowWrite    = 500 * time.Millisecond // A write this slow counts against the client
+	rateWindow         = 5 * time.Second        // Strikes are counted over this window
+	demoteStrikes      = 5                      // Coalesced frames or slow writes in a window to step down
+	promoteAfter       = 30 * time.Second       // Clean running before stepping back up
+)
+
+// clientRates are the data frame intervals a struggling client is
+// stepped down through: full rate, 5 Hz, 1 Hz.
+var clientRates = []time.Duration{0, 200 * time.Millisecond, time.Second}
+
+// LinkStatus tells a client its frame rate has changed.
+type LinkStatus struct {
+	Reduced bool    `json:"reduced"`          // Below full rate
+	RateHz  float64 `json:"rateHz,omitempty"` // Data frame rate when reduced
+	Reason  string  `json:"reason"`
+}
+
+// clientMessage is a queued frame. Keep frames (settings) are never
+// coalesced away: a client that misses one shows stale units until it
+// reconnects.
+type clientMessage struct {
+	data []byte
+	keep bool
+
+	// For delta clients' data frames: data is the JSON, sent as a patch
+	// against the last data frame the writer sent (see delta.go)
+	frame map[string]any
+}
+
+// frameClient is a browser or tool receiving the broadcast frames, over a
+// WebSocket (/ws) or Server-Sent Events (/events). Both get the same
+// encoded frames, queueing and rate stepping; only write differs.
+type frameClient struct {
+	kind  string             // "ws" or "sse", for logs
+	addr  string             // Remote address
+	write func([]byte) error // Sends one frame, giving up after clientWriteTimeout
+
+	msgpack bool // Frames as MessagePack rather than JSON (WebSocket only)
+	delta   bool // Data frames as changes from the last one
+
+	// The writer's delta state
+	last    map[string]any // Last data frame sent
+	lastKey time.Time      // When it last sent a whole one
+
+	mu     sync.Mutex
+	queue  []clientMessage // Oldest first
+	wake   chan struct{}   // Signalled when queue gains a frame
+	done   chan struct{}   // Closed when the client goes away
+	closed bool
+	sub    *subscription // Channels and rate the client asked for, or nil
+
+	// Rate stepping for slow links
+	level       int       // Index into clientRates
+	lastData    time.Time // Last data frame queued
+	strikes     int       // Coalesced frames and slow writes this window
+	windowStart time.Time
+	calmSince   time.Time // Start of the current strike-free run
+}
+
+func newFrameClient(kind, addr string, write func([]byte) error) *frameClient {
+	return &frameClient{
+		kind:  kind,
+		addr:  addr,
+		write: write,
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+}
+
+// encode returns a JSON frame in the client's encoding, or nil if it
+// can't be converted.
+func (c *frameClient) encode(data []byte) []byte {
+	if !c.msgpack {
+		return data
+	}
+	packed, err := msgpack.FromJSON(data)
+	if err != nil {
+		log.Printf("[%s] %s: %v", c.kind, c.addr, err)
+		return nil
+	}
+	return packed
+}
+
+// enqueue queues data for the writer. With the queue full, the oldest
+// frame that isn't a keep frame is dropped to make room, so a slow client
+// always gets the latest data; it reports whether that happened.
+func (c *frameClient) enqueue(data []byte, keep bool) (coalesced bool) {
+	return c.push(clientMessage{data: data, keep: keep})
+}
+
+func (c *frameClient) push(msg clientMessage) (coalesced bool) {
+	c.mu.Lock()
+	if c.closed || msg.data == nil {
+		c.mu.Unlock()
+		return false
+	}
+	if len(c.queue) >= clientQueueLen {
+		for i, m := range c.queue {
+			if !m.keep {
+				c.queue = append(c.queue[:i], c.queue[i+1:]...)
+				coalesced = true
+				c.strikes++
+				break
+			}
+		}
+	}
+	c.queue = append(c.queue, msg)
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default: // Writer already woken
+	}
+	return coalesced
+}
+
+// subscribe replaces the client's subscription.
+func (c *frameClient) subscribe(sub *subscription) {
+	c.mu.Lock()
+	c.sub = sub
+	c.mu.Unlock()
+	log.Printf("[%s] %s: subscribed to %s", c.kind, c.addr, sub)
+}
+
+func (c *frameClient) subscription() *subscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sub
+}
+
+// due reports whether a data frame should go to the client now, given
+// its current rate and the rate it subscribed to, and if so notes it as
+// sent.
+func (c *frameClient) due(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	iv := clientRates[c.level]
+	if c.sub != nil {
+		iv = max(iv, c.sub.every)
+	}
+	if iv > 0 && now.Sub(c.lastData) < iv-iv/10 {
+		return false
+	}
+	c.lastData = now
+	return true
+}
+
+// adjust steps the client's rate down after a window with too many
+// strikes, or back up after promoteAfter without any. It returns the new
+// status when the rate changed.
+func (c *frameClient) adjust(now time.Time) *LinkStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.windowStart.IsZero() {
+		c.windowStart, c.calmSince = now, now
+	}
+	if now.Sub(c.windowStart) < rateWindow {
+		return nil
+	}
+	strikes := c.strikes
+	c.strikes, c.windowStart = 0, now
+
+	var st *LinkStatus
+	switch {
+	case strikes >= demoteStrikes && c.level < len(clientRates)-1:
+		c.level++
+		st = &LinkStatus{Reason: "slow connection"}
+	case strikes == 0 && c.level > 0 && now.Sub(c.calmSince) >= promoteAfter:
+		c.level--
+		st = &LinkStatus{Reason: "connection recovered"}
+	}
+	if strikes > 0 || st != nil {
+		c.calmSince = now
+	}
+	if st != nil {
+		if iv := clientRates[c.level]; iv > 0 {
+			st.Reduced, st.RateHz = true, float64(time.Second)/float64(iv)
+		}
+		log.Printf("[%s] %s: %s, frame rate %s", c.kind, c.addr, st.Reason, rateName(clientRates[c.level]))
+	}
+	return st
+}
+
+// reduced reports whether the client is below full rate.
+func (c *frameClient) reduced() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level > 0
+}
+
+func rateName(iv time.Duration) string {
+	if iv == 0 {
+		return "full"
+	}
+	return fmt.Sprintf("%g Hz", float64(time.Second)/float64(iv))
+}
+
+// next waits for queued frames and takes them all; ok is false once the
+// client is closed.
+func (c *frameClient) next() (msgs []clientMessage, ok bool) {
+	select {
+	case <-c.wake:
+	case <-c.done:
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msgs, c.queue = c.queue, nil
+	return msgs, true
+}
+
+// close stops the writer; frames still queued are discarded.
+func (c *frameClient) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+}
+
+// writeLoop sends queued frames until the client is closed or a write
+// fails.
+func (c *frameClient) writeLoop() {
+	for {
+		msgs, ok := c.next()
+		if !ok {
+			return
+		}
+		for _, m := range msgs {
+			start := time.Now()
+			data := m.data
+			if m.frame != nil {
+				if data = c.deltaFrame(start, m); data == nil {
+					continue
+				}
+			}
+			if err := c.write(data); err != nil {
+				log.Printf("[%s] %s: write failed: %v", c.kind, c.addr, err)
+				return
+			}
+			if time.Since(start) > clientSlowWrite {
+				c.mu.Lock()
+				c.strikes++
+				c.mu.Unlock()
+			}
+		}
+	}
+}
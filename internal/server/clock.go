@@ -0,0 +1,53 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+)
+
+const (
+	clockMaxSkew   = 2 * time.Second // Correct the clock when GPS disagrees by more than this
+	clockCheckGap  = time.Minute     // Between corrections
+	clockEarliestY = 2024            // GPS dates before this are receiver defaults or week rollover
+)
+
+// syncClock corrects the clock from a GPS fix's date and time, for Pis
+// with no RTC and no network, whose logs are otherwise dated 1970 until
+// NTP syncs. The system clock is stepped if the process may (root or
+// CAP_SYS_TIME); otherwise the logger is given the offset so log file
+// names and rows carry GPS time. readAt is when the fix was read.
+func (s *Server) syncClock(d *gps.Data, readAt time.Time) {
+	if !d.Valid || d.Time == 0 || time.Since(s.clockChecked) < clockCheckGap {
+		return
+	}
+	fix := time.UnixMilli(d.Time)
+	if fix.Year() < clockEarliestY {
+		return
+	}
+	s.clockChecked = time.Now()
+
+	// The fix was for readAt, give or take the serial latency
+	skew := fix.Sub(readAt.Round(0))
+	if skew.Abs() <= clockMaxSkew {
+		if s.clockOffset != 0 {
+			// The clock has caught up (NTP, or set elsewhere)
+			s.clockOffset = 0
+			s.logger.SetClockOffset(0)
+		}
+		return
+	}
+
+	if err := setSystemClock(time.Now().Add(skew)); err != nil {
+		if s.clockOffset == 0 || (skew-s.clockOffset).Abs() > clockMaxSkew {
+			log.Printf("[clock] system clock off by %v, can't set it (%v): stamping logs with GPS time", skew.Round(time.Second), err)
+			s.clockOffset = skew
+			s.logger.SetClockOffset(skew)
+		}
+		return
+	}
+	log.Printf("[clock] system clock stepped %v to GPS time", skew.Round(time.Second))
+	s.clockOffset = 0
+	s.logger.SetClockOffset(0)
+}
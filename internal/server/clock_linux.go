@@ -0,0 +1,13 @@
+package server
+
+import (
+	"syscall"
+	"time"
+)
+
+// setSystemClock steps the system clock to t. It needs root or
+// CAP_SYS_TIME.
+func setSystemClock(t time.Time) error {
+	tv := syscall.NsecToTimeval(t.UnixNano())
+	return syscall.Settimeofday(&tv)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// setSystemClock is unsupported off Linux; logs get the GPS offset.
+func setSystemClock(t time.Time) error {
+	return fmt.Errorf("setting the clock is only supported on Linux")
+}
@@ -4,12 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/logger"
+	"github.com/shaunagostinho/speeduino-dash/internal/sensors"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,21 +29,121 @@ type Config struct {
 	ECU ECUConfig `yaml:"ecu" json:"ecu"`
 	GPS GPSConfig `yaml:"gps" json:"gps"`
 
+	// Additional ECU providers, broadcast under Frame.ECUs[name]
+	ExtraECUs []NamedECUConfig `yaml:"extra_ecus" json:"extraEcus"`
+
+	// Auxiliary sensors, broadcast under Frame.Sensors[name]
+	Sensors []SensorConfig `yaml:"sensors" json:"sensors"`
+
+	// Accelerometer/gyro, broadcast as Frame.IMU
+	IMU IMUConfig `yaml:"imu" json:"imu"`
+
+	// CAN keypad for dash actions, with key LEDs
+	Keypad KeypadConfig `yaml:"keypad" json:"keypad"`
+
+	// Auxiliary gauges (ESP32 + OLED pods) fed over UDP or serial
+	Gauges GaugesConfig `yaml:"gauges" json:"gauges"`
+
+	// Shift light and warning lamps on GPIO lines or a WS2812 strip
+	GPIO GPIOConfig `yaml:"gpio" json:"gpio"`
+
+	// Channels re-broadcast as CAN frames
+	CANOut CANOutConfig `yaml:"can_out" json:"canOut"`
+
+	// Live channels published to an MQTT broker
+	Telemetry TelemetryConfig `yaml:"telemetry" json:"telemetry"`
+
+	// RaceChrono DIY feed over UDP or TCP
+	RaceChrono RaceChronoConfig `yaml:"racechrono" json:"raceChrono"`
+
+	// RealDash CAN feed over TCP
+	RealDash RealDashConfig `yaml:"realdash" json:"realDash"`
+
+	// SignalK deltas for marine installs
+	SignalK SignalKConfig `yaml:"signalk" json:"signalK"`
+
+	// Compact binary packets of chosen channels over UDP
+	UDPOut UDPOutConfig `yaml:"udp_out" json:"udpOut"`
+
+	// Typed live data over gRPC
+	GRPC GRPCConfig `yaml:"grpc" json:"grpc"`
+
+	// Other goefidash instances, merged under Frame.Remotes[name]
+	Remotes []RemoteConfig `yaml:"remotes" json:"remotes"`
+
+	// AFR channel source when an external wideband is present
+	AFR AFRConfig `yaml:"afr" json:"afr"`
+
 	// Display preferences
 	Display DisplayConfig `yaml:"display" json:"display"`
 
+	// Alert rules, on top of the display thresholds
+	Alerts AlertsConfig `yaml:"alerts" json:"alerts"`
+
 	// Drivetrain (gear detection)
 	Drivetrain DrivetrainConfig `yaml:"drivetrain" json:"drivetrain"`
 
+	// Speed source (GPS/VSS fusion)
+	Speed SpeedConfig `yaml:"speed" json:"speed"`
+
+	// Vehicle identity (name, plate, engine, tune)
+	Identity IdentityConfig `yaml:"identity" json:"identity"`
+
 	// Vehicle physics (HP estimation)
 	Vehicle VehicleConfig `yaml:"vehicle" json:"vehicle"`
 
+	// Injectors (fuel flow estimates)
+	Fuel FuelConfig `yaml:"fuel" json:"fuel"`
+
 	// Logging
 	Logging LoggingConfig `yaml:"logging" json:"logging"`
 
+	// Points written to InfluxDB, alongside the CSV logs
+	Influx InfluxConfig `yaml:"influx" json:"influx"`
+
 	// Server
 	Server ServerConfig `yaml:"server" json:"server"`
 
+	// Startup (boot-time device readiness)
+	Startup StartupConfig `yaml:"startup" json:"startup"`
+
+	// Storage (persistent data directory)
+	Storage StorageConfig `yaml:"storage" json:"storage"`
+
+	// Off-dash backup of the odometer, engine hours and records
+	Sync SyncConfig `yaml:"sync" json:"sync"`
+
+	// Autocross (single timed runs)
+	Autocross AutocrossConfig `yaml:"autocross" json:"autocross"`
+
+	// Circuit lap timing
+	Laps LapsConfig `yaml:"laps" json:"laps"`
+
+	// Drag-strip performance timers (0-100, 1/4 mile, ...)
+	Perf PerfConfig `yaml:"perf" json:"perf"`
+
+	// Track library and automatic track detection
+	Tracks TracksConfig `yaml:"tracks" json:"tracks"`
+
+	// Per-drive peaks and channel statistics
+	Stats StatsConfig `yaml:"stats" json:"stats"`
+
+	// Snapshot bundles (on demand or on alert)
+	Snapshots SnapshotConfig `yaml:"snapshots" json:"snapshots"`
+
+	// Data staleness (noData heartbeat frames)
+	Stale StaleConfig `yaml:"stale" json:"stale"`
+
+	// Engine-off reduced frames and polling sleep
+	Quiescent QuiescentConfig `yaml:"quiescent" json:"quiescent"`
+
+	// Post-shutdown coolant countdown ("turbo timer")
+	Cooldown CooldownConfig `yaml:"cooldown" json:"cooldown"`
+
+	// OS maintenance (reboot, shutdown, hotspot). Kept out of the config
+	// API so it can only be enabled from the file or environment.
+	System SystemConfig `yaml:"system" json:"-"`
+
 	path string // file path for save/load
 }
 
@@ -46,13 +154,333 @@ type ECUConfig struct {
 	CanID    int     `yaml:"can_id" json:"canId"`
 	Stoich   float64 `yaml:"stoich" json:"stoich"`
 	PollHz   int     `yaml:"poll_hz" json:"pollHz"`    // ECU polling rate
-	Protocol string  `yaml:"protocol" json:"protocol"` // "generic" or "tunerstudio"
+	Protocol string  `yaml:"protocol" json:"protocol"` // "generic", "tunerstudio", "msdroid" or "push"
+
+	// Leave channels the provider never populates out of frames and logs,
+	// along with OmitChannels (DataFrame JSON names, e.g. "vvt2Angle")
+	PruneChannels bool     `yaml:"prune_channels" json:"pruneChannels"`
+	OmitChannels  []string `yaml:"omit_channels" json:"omitChannels"`
+
+	// Named, scaled aux/CAN input channels (fuel level, trans temp, ...)
+	Aux []ecu.AuxChannel `yaml:"aux" json:"aux"`
+}
+
+// NamedECUConfig is an additional ECU provider. Name namespaces its data
+// in the broadcast frame (e.g. "trans", "wideband") and must be unique.
+type NamedECUConfig struct {
+	Name      string `yaml:"name" json:"name"`
+	ECUConfig `yaml:",inline"`
+}
+
+// SensorConfig is an auxiliary sensor source such as a standalone
+// wideband controller.
+type SensorConfig struct {
+	Name        string  `yaml:"name" json:"name"`
+	Type        string  `yaml:"type" json:"type"`          // "innovate", "aem", "max31855", "max31856", "egt-serial", "bmp280", "ds18b20", "mcp3008", "tpms" or "demo"
+	PortPath    string  `yaml:"port_path" json:"portPath"` // Serial port, I2C bus (bmp280), spidev node (mcp3008) or 1-Wire sysfs directory (ds18b20)
+	BaudRate    int     `yaml:"baud_rate" json:"baudRate"`
+	Stoich      float64 `yaml:"stoich" json:"stoich"`
+	OverrideAFR bool    `yaml:"override_afr" json:"overrideAfr"` // Replace ECU AFR/lambda with this sensor's
+
+	// SPI thermocouple amplifiers: one spidev node per cylinder, in order
+	Ports        []string `yaml:"ports" json:"ports"`               // e.g. [/dev/spidev0.0, /dev/spidev0.1]
+	Thermocouple string   `yaml:"thermocouple" json:"thermocouple"` // MAX31856 type letter, default "K"
+
+	// Pi-attached sensors: their inputs as named, scaled channels (see
+	// IsAux), the I2C address and the ADC reference voltage. For tpms,
+	// each channel maps a sensor ID to a corner name.
+	Channels []sensors.Channel `yaml:"channels" json:"channels"`
+	Address  int               `yaml:"address" json:"address"` // 0 = chip default (BMP280 0x76)
+	VRef     float64           `yaml:"vref" json:"vref"`       // 0 = 3.3 V
+
+	// tpms: the rtl_433 receiver's frequency and device
+	FrequencyMHz float64 `yaml:"frequency_mhz" json:"frequencyMhz"` // 0 = 433.92; 315 in North America
+	Device       string  `yaml:"device" json:"device"`              // rtl_433 -d: index or :serial; "" = the first
+}
+
+// IMUConfig describes an I2C accelerometer/gyro and how it's mounted.
+type IMUConfig struct {
+	Type    string `yaml:"type" json:"type"`       // "mpu6050", "lsm6ds3", "demo" or "disabled"
+	Bus     string `yaml:"bus" json:"bus"`         // e.g. /dev/i2c-1
+	Address int    `yaml:"address" json:"address"` // 7-bit I2C address; 0 = chip default (0x68, 0x6A)
+	RateHz  int    `yaml:"rate_hz" json:"rateHz"`
+
+	// Chip axes pointing forward and to the left: x, -x, y, -y, z or -z
+	Forward string `yaml:"forward" json:"forward"`
+	Left    string `yaml:"left" json:"left"`
+}
+
+// KeypadConfig describes a CANopen keypad (Blink Marine PKP, or a
+// Grayhill keypad in PKP mode) on a SocketCAN interface.
+type KeypadConfig struct {
+	Type      string      `yaml:"type" json:"type"`           // "pkp" or "disabled"
+	Interface string      `yaml:"interface" json:"interface"` // e.g. can0, brought up with ip link
+	NodeID    int         `yaml:"node_id" json:"nodeId"`      // CANopen node ID (PKP default 0x15)
+	Size      int         `yaml:"size" json:"size"`           // Keys on the keypad (e.g. 12 for a PKP-2600)
+	Keys      []KeypadKey `yaml:"keys" json:"keys"`
+}
+
+// KeypadKey binds a key, numbered from 1, to an action and its LED.
+type KeypadKey struct {
+	Key    int    `yaml:"key" json:"key"`
+	Action string `yaml:"action" json:"action"` // See keypadAction, or layout_next, layout_prev, layout:<name>
+	LED    string `yaml:"led" json:"led"`       // "alerts", "logging", "autox", "page", a colour, or "" for off
+}
+
+// GaugesConfig sets up feeds for small auxiliary displays speaking the
+// gauge protocol (docs/GAUGE_PROTOCOL.md). Gauges register with the
+// channels they want; Devices overrides that by gauge ID.
+type GaugesConfig struct {
+	UDPListen string              `yaml:"udp_listen" json:"udpListen"` // e.g. ":5606"; "" = no UDP gauges
+	Serial    []GaugeSerialConfig `yaml:"serial" json:"serial"`        // Ports with a gauge on the other end
+	RateHz    int                 `yaml:"rate_hz" json:"rateHz"`       // For gauges that ask for 0
+	MaxRateHz int                 `yaml:"max_rate_hz" json:"maxRateHz"`
+	Devices   []GaugeDeviceConfig `yaml:"devices" json:"devices"`
+}
+
+// GaugeSerialConfig is a serial port with a gauge on it.
+type GaugeSerialConfig struct {
+	Port     string `yaml:"port" json:"port"` // Device path, by-id:, tcp:// or bt://
+	BaudRate int    `yaml:"baud_rate" json:"baudRate"`
+}
+
+// GaugeDeviceConfig overrides what a gauge asked for, so its channels can
+// change without reflashing it.
+type GaugeDeviceConfig struct {
+	ID       string   `yaml:"id" json:"id"`             // As sent in the gauge's hello
+	Channels []string `yaml:"channels" json:"channels"` // Empty = as requested
+	RateHz   int      `yaml:"rate_hz" json:"rateHz"`    // 0 = as requested
+}
+
+// GPIOConfig drives hardware lights from RPM and alerts: LEDs or lamps
+// (through a transistor or relay) on GPIO lines, and a WS2812 shift light
+// strip on SPI. They follow the broadcast loop directly, without the
+// browser's latency.
+type GPIOConfig struct {
+	Chip       string             `yaml:"chip" json:"chip"` // GPIO character device, e.g. /dev/gpiochip0
+	Pins       []GPIOPinConfig    `yaml:"pins" json:"pins"`
+	Strip      GPIOStripConfig    `yaml:"strip" json:"strip"`
+	Buttons    []GPIOButtonConfig `yaml:"buttons" json:"buttons"`
+	DebounceMs int                `yaml:"debounce_ms" json:"debounceMs"` // A button must hold still this long to count
+}
+
+// GPIOPinConfig is a light on one GPIO line.
+type GPIOPinConfig struct {
+	Line      int    `yaml:"line" json:"line"`            // Line on the chip (BCM GPIO number on a Pi)
+	When      string `yaml:"when" json:"when"`            // "rpm", "alert", "danger" or "alert:<id>"
+	RPM       uint16 `yaml:"rpm" json:"rpm"`              // "rpm": on at or above (0 = thresholds.rpm_warn)
+	FlashRPM  uint16 `yaml:"flash_rpm" json:"flashRpm"`   // "rpm": flashes at or above (0 = never)
+	ActiveLow bool   `yaml:"active_low" json:"activeLow"` // On drives the line low
+}
+
+// GPIOButtonConfig is a button on one GPIO line, bound to an action as
+// a keypad key is.
+type GPIOButtonConfig struct {
+	Line       int    `yaml:"line" json:"line"`
+	Action     string `yaml:"action" json:"action"`          // See keypadAction, or layout_next, layout_prev, layout:<name>
+	Pull       string `yaml:"pull" json:"pull"`              // "up" (default), "down" or "none"
+	ActiveHigh bool   `yaml:"active_high" json:"activeHigh"` // Pressed drives the line high (a button to 3.3 V)
+}
+
+// GPIOStripConfig is a WS2812 shift light strip. LEDs light one by one
+// from StartRPM to ShiftRPM, and the whole strip flashes from FlashRPM.
+type GPIOStripConfig struct {
+	Device     string   `yaml:"device" json:"device"` // spidev node, e.g. /dev/spidev0.0 ("" = no strip)
+	LEDs       int      `yaml:"leds" json:"leds"`
+	StartRPM   uint16   `yaml:"start_rpm" json:"startRpm"`     // First LED (0 = 2/3 of shift_rpm)
+	ShiftRPM   uint16   `yaml:"shift_rpm" json:"shiftRpm"`     // All lit (0 = thresholds.rpm_warn)
+	FlashRPM   uint16   `yaml:"flash_rpm" json:"flashRpm"`     // Flashing (0 = thresholds.rpm_danger)
+	Colors     []string `yaml:"colors" json:"colors"`          // Per LED; empty = green, amber, then red
+	FlashColor string   `yaml:"flash_color" json:"flashColor"` // "" = blue
+	Brightness float64  `yaml:"brightness" json:"brightness"`  // 0-1
+	Alerts     bool     `yaml:"alerts" json:"alerts"`          // Flash red on a danger or critical alert
+}
+
+// CANOutConfig re-broadcasts channels on a CAN bus for other devices (a
+// boost controller, gauges, a PDM), each message a set of signals laid
+// out as in a DBC file.
+type CANOutConfig struct {
+	Interface string             `yaml:"interface" json:"interface"` // e.g. can0, brought up with ip link ("" = off)
+	Messages  []CANMessageConfig `yaml:"messages" json:"messages"`
+}
+
+// TelemetryConfig publishes live data off the car to an MQTT broker, for
+// Home Assistant, Node-RED and the like: each channel as a plain number on
+// <topic>/<channel>, the whole frame as JSON on <topic>/frame, and
+// "online" or "offline" (the broker's last will) on <topic>/status.
+type TelemetryConfig struct {
+	Broker   string   `yaml:"broker" json:"-"`    // host:port, tcp://host:1883 or tls://host:8883 ("" = off); config file only, since the password goes there
+	Topic    string   `yaml:"topic" json:"topic"` // Prefix, default goefidash
+	Username string   `yaml:"username" json:"username"`
+	Password string   `yaml:"password" json:"-"`         // Config file only, never sent to clients
+	ClientID string   `yaml:"client_id" json:"clientId"` // Default goefidash-<hostname>-telemetry
+	RateHz   float64  `yaml:"rate_hz" json:"rateHz"`     // Publishes per second (0 = 1)
+	QoS      int      `yaml:"qos" json:"qos"`            // 0 or 1
+	Retain   bool     `yaml:"retain" json:"retain"`      // Broker keeps the last value for new subscribers
+	Frame    bool     `yaml:"frame" json:"frame"`        // Publish the whole frame too
+	Channels []string `yaml:"channels" json:"channels"`  // DataFrame JSON names, aux channels or "speed"
+}
+
+// RaceChronoConfig feeds RaceChrono Pro on a phone with $RC3 sentences
+// (RPM, up to 15 analog channels, the IMU) and optionally the dash's GPS
+// as NMEA, so its video and lap analysis get the ECU channels. RaceChrono
+// adds it as a "RaceChrono DIY" device over WiFi, TCP or UDP.
+type RaceChronoConfig struct {
+	UDP    string   `yaml:"udp" json:"udp"`        // host:port sent to; a broadcast address such as 192.168.4.255:7700 reaches every phone ("" = off)
+	Listen string   `yaml:"listen" json:"listen"`  // TCP address RaceChrono connects to, e.g. :7700 ("" = off)
+	RateHz int      `yaml:"rate_hz" json:"rateHz"` // 0 = 20
+	Analog []string `yaml:"analog" json:"analog"`  // Channels for a1 to a15: DataFrame JSON names, aux channels or "speed"
+	GPS    bool     `yaml:"gps" json:"gps"`        // Also send GPS fixes as RMC and GGA
+}
+
+// RealDashConfig serves channels to RealDash on a phone or tablet in its
+// CAN-over-TCP protocol, so passengers can run their own screens while
+// the dash keeps the ECU's serial port. RealDash reads the frames with
+// the channel description from GET /api/realdash.xml.
+type RealDashConfig struct {
+	Listen   string   `yaml:"listen" json:"listen"`     // TCP address, e.g. :35000 ("" = off)
+	RateHz   int      `yaml:"rate_hz" json:"rateHz"`    // 0 = 20
+	Channels []string `yaml:"channels" json:"channels"` // DataFrame JSON names, aux channels or "speed"
+}
+
+// SignalKConfig publishes SignalK delta messages for boats: engine RPM,
+// temperatures and pressures under propulsion.<engine>, the battery under
+// electrical.batteries.<battery> and the GPS under navigation, in SI
+// units, plus any extra Paths. A SignalK server takes them as a TCP or
+// UDP data connection; chartplotters and SignalK apps can also connect
+// straight to /signalk/v1/stream.
+type SignalKConfig struct {
+	Listen    string        `yaml:"listen" json:"listen"`       // TCP address, e.g. :8375 ("" = off)
+	UDP       string        `yaml:"udp" json:"udp"`             // host:port sent to ("" = off)
+	WebSocket bool          `yaml:"websocket" json:"websocket"` // Serve /signalk and /signalk/v1/stream
+	RateHz    float64       `yaml:"rate_hz" json:"rateHz"`      // 0 = 1
+	Engine    string        `yaml:"engine" json:"engine"`       // Propulsion instance (default "main")
+	Battery   string        `yaml:"battery" json:"battery"`     // Battery instance (default "starter")
+	Paths     []SignalKPath `yaml:"paths" json:"paths"`
+}
+
+// SignalKPath publishes a channel at a SignalK path, as channel × Scale +
+// Offset, which should come out in the path's SI unit.
+type SignalKPath struct {
+	Channel string  `yaml:"channel" json:"channel"` // DataFrame JSON name, aux channel or "speed"
+	Path    string  `yaml:"path" json:"path"`       // e.g. tanks.fuel.0.currentLevel
+	Scale   float64 `yaml:"scale" json:"scale"`     // 0 = 1
+	Offset  float64 `yaml:"offset" json:"offset"`
+}
+
+// UDPOutConfig broadcasts a compact binary packet of chosen channels on
+// the LAN for overlay tools, pit laptops and microcontrollers that can't
+// afford JSON (docs/UDP_TELEMETRY.md). GET /api/udp/schema describes the
+// layout.
+type UDPOutConfig struct {
+	Target string           `yaml:"target" json:"target"`  // host:port, e.g. 192.168.4.255:5607 to broadcast ("" = off)
+	RateHz int              `yaml:"rate_hz" json:"rateHz"` // 0 = 20
+	Fields []UDPFieldConfig `yaml:"fields" json:"fields"`
+}
+
+// UDPFieldConfig is one value in the packet, stored as raw where the
+// value is raw × Factor + Offset, as in a DBC signal.
+type UDPFieldConfig struct {
+	Channel string  `yaml:"channel" json:"channel"` // DataFrame JSON name, aux channel or "speed"
+	Type    string  `yaml:"type" json:"type"`       // "f32" (default), "i16", "u16" or "u8"
+	Factor  float64 `yaml:"factor" json:"factor"`   // 0 = 1; integer types only
+	Offset  float64 `yaml:"offset" json:"offset"`   // Integer types only
+}
+
+// GRPCConfig serves the gRPC API (internal/dashpb/dash.proto): the
+// latest frame and a stream of frames as protobuf, for analysis tools
+// that want a typed, versioned API instead of the WebSocket's JSON.
+type GRPCConfig struct {
+	Listen string `yaml:"listen" json:"listen"` // e.g. :50051 ("" = off)
+}
+
+// CANMessageConfig is one CAN frame sent at a fixed rate. It is held back
+// while any of its channels has no value, so receivers' timeouts see
+// stale ECU data rather than zeroes.
+type CANMessageConfig struct {
+	ID       uint32            `yaml:"id" json:"id"`
+	Extended bool              `yaml:"extended" json:"extended"` // 29-bit ID
+	RateHz   int               `yaml:"rate_hz" json:"rateHz"`    // 0 = 10
+	Length   int               `yaml:"length" json:"length"`     // Data bytes (0 = 8)
+	Signals  []CANSignalConfig `yaml:"signals" json:"signals"`
+}
+
+// CANSignalConfig places a channel in a message.
+type CANSignalConfig struct {
+	Channel   string  `yaml:"channel" json:"channel"`      // DataFrame JSON name, aux channel or "speed" (fused km/h)
+	Start     int     `yaml:"start" json:"start"`          // Start bit as in a DBC: the LSB, or the MSB with big_endian
+	Length    int     `yaml:"length" json:"length"`        // Bits
+	BigEndian bool    `yaml:"big_endian" json:"bigEndian"` // Motorola byte order
+	Signed    bool    `yaml:"signed" json:"signed"`
+	Factor    float64 `yaml:"factor" json:"factor"` // Value = raw × factor + offset (0 = 1)
+	Offset    float64 `yaml:"offset" json:"offset"`
+}
+
+// RemoteConfig is another goefidash instance to subscribe to, such as the
+// race car on a trailer behind the tow vehicle.
+type RemoteConfig struct {
+	Name string `yaml:"name" json:"name"` // Namespace in the frame, e.g. "trailer"
+	URL  string `yaml:"url" json:"url"`   // e.g. ws://10.0.0.2:8080/ws, or just host:port
+}
+
+// AFRConfig chooses between the ECU's AFR and an override_afr sensor's.
+type AFRConfig struct {
+	Mode     string  `yaml:"mode" json:"mode"`          // "external" (default), "ecu" or "load"
+	LoadKPa  float64 `yaml:"load_kpa" json:"loadKpa"`   // "load": external at or above this MAP
+	BlendKPa float64 `yaml:"blend_kpa" json:"blendKpa"` // "load": crossfade width below load_kpa (0 = hard switch)
+	MaxDiff  float64 `yaml:"max_diff" json:"maxDiff"`   // Alert when sources differ by more AFR than this (0 = off)
+	HoldSec  float64 `yaml:"hold_s" json:"holdSec"`     // ...for at least this long
+}
+
+// IsEGT reports whether the sensor is an EGT amplifier.
+func (c SensorConfig) IsEGT() bool {
+	switch c.Type {
+	case "max31855", "max31856", "egt-serial":
+		return true
+	}
+	return false
+}
+
+// IsAux reports whether the sensor is wired to the Pi itself, with
+// channels that join the ECU's aux channels.
+func (c SensorConfig) IsAux() bool {
+	switch c.Type {
+	case "bmp280", "ds18b20", "mcp3008", "tpms":
+		return true
+	}
+	return false
+}
+
+// DevicePaths returns the device nodes the sensor needs before it can connect.
+func (c SensorConfig) DevicePaths() []string {
+	if len(c.Ports) > 0 {
+		return c.Ports
+	}
+	if c.PortPath != "" {
+		return []string{c.PortPath}
+	}
+	return nil
 }
 
 type GPSConfig struct {
-	Type     string `yaml:"type" json:"type"`          // "nmea" or "demo" or "disabled"
+	Type     string `yaml:"type" json:"type"`          // "nmea", "ubx", "udp", "demo" or "disabled"
 	PortPath string `yaml:"port_path" json:"portPath"` // e.g. /dev/ttyGPS
 	BaudRate int    `yaml:"baud_rate" json:"baudRate"`
+	RateHz   int    `yaml:"rate_hz" json:"rateHz"` // Navigation rate (1–25); also the GPS poll rate
+	Listen   string `yaml:"listen" json:"listen"`  // udp: where positions are pushed to, e.g. :10110
+
+	// Program u-blox modules (115200 baud, RateHz, needed sentences only)
+	// on connect instead of relying on u-center setup
+	ConfigureReceiver bool `yaml:"configure_receiver" json:"configureReceiver"`
+
+	// Set the system clock from GPS time (no RTC, no network)
+	SetClock bool `yaml:"set_clock" json:"setClock"`
+
+	// Copy every received NMEA sentence to .nmea files next to the CSV logs
+	RawLog bool `yaml:"raw_log" json:"rawLog"`
+
+	// Speed/heading smoothing and glitch rejection
+	Filter gps.FilterConfig `yaml:"filter" json:"filter"`
 }
 
 type DisplayConfig struct {
@@ -82,6 +510,93 @@ type ThresholdConfig struct {
 	BattLow     float64 `yaml:"batt_low" json:"battLow"`
 	BattHigh    float64 `yaml:"batt_high" json:"battHigh"`
 	KnockWarn   uint8   `yaml:"knock_warn" json:"knockWarn"` // degrees retard
+
+	PWImbalanceWarn float64 `yaml:"pw_imbalance_warn" json:"pwImbalanceWarn"` // % spread of pulse widths 1-4 (0 = off)
+	VVTErrorWarn    float64 `yaml:"vvt_error_warn" json:"vvtErrorWarn"`       // degrees off target, smoothed (0 = off)
+	VVTErrorDanger  float64 `yaml:"vvt_error_danger" json:"vvtErrorDanger"`   // degrees off target, smoothed (0 = off)
+	BoostErrorWarn  float64 `yaml:"boost_error_warn" json:"boostErrorWarn"`   // kPa off boost target with duty at 0/100% (0 = off)
+
+	// Lean under boost: MAP at or above LeanBoostKPa with AFR more than
+	// LeanBoostMargin leaner than the ECU's target for LeanBoostFrames
+	// ECU frames in a row
+	LeanBoostKPa    float64 `yaml:"lean_boost_kpa" json:"leanBoostKpa"`       // 0 = off
+	LeanBoostMargin float64 `yaml:"lean_boost_margin" json:"leanBoostMargin"` // AFR points
+	LeanBoostFrames int     `yaml:"lean_boost_frames" json:"leanBoostFrames"`
+
+	// Tire pressure (tpms sensors), in kPa whatever the display unit
+	TireLowKPa  float64 `yaml:"tire_low_kpa" json:"tireLowKpa"`   // Warning below this (0 = off)
+	TireFlatKPa float64 `yaml:"tire_flat_kpa" json:"tireFlatKpa"` // Critical below this (0 = off)
+	TireHotC    float64 `yaml:"tire_hot_c" json:"tireHotC"`       // Warning at or above this air temperature (0 = off)
+}
+
+// AlertsConfig adds alert rules on any ECU channel to the built-in
+// threshold alerts.
+type AlertsConfig struct {
+	Rules  []AlertRule  `yaml:"rules" json:"rules"`
+	Notify NotifyConfig `yaml:"notify" json:"notify"`
+	Alarm  AlarmConfig  `yaml:"alarm" json:"alarm"`
+}
+
+// AlarmConfig sounds alerts on a buzzer or the Pi's audio output. Each
+// alert's pattern comes from its rule's sound, then Sounds, then its
+// level's; of the alerts neither acknowledged nor muted, the highest
+// level with a sound is played.
+type AlarmConfig struct {
+	Output    string            `yaml:"output" json:"output"`        // "gpio", "aplay" or "" for off
+	Line      int               `yaml:"line" json:"line"`            // "gpio": line with an active buzzer (one with its own oscillator) on gpio.chip
+	ActiveLow bool              `yaml:"active_low" json:"activeLow"` // "gpio": sounding drives the line low
+	Device    string            `yaml:"device" json:"device"`        // "aplay": ALSA device, e.g. plughw:1,0 ("" = default)
+	ToneHz    float64           `yaml:"tone_hz" json:"toneHz"`       // "aplay": pitch
+	Warning   string            `yaml:"warning" json:"warning"`      // Pattern for warnings ("" = silent)
+	Danger    string            `yaml:"danger" json:"danger"`
+	Critical  string            `yaml:"critical" json:"critical"`
+	Sounds    map[string]string `yaml:"sounds" json:"sounds"` // Pattern by alert ID, e.g. clt: pulse
+}
+
+// NotifyConfig sends alerts off the car as they're raised, to any of a
+// webhook, an MQTT broker and a Telegram chat. Empty targets are off.
+type NotifyConfig struct {
+	Level    string         `yaml:"level" json:"level"` // Lowest level sent: "warning" (default), "danger" or "critical"
+	Ends     bool           `yaml:"ends" json:"ends"`   // Also send when an alert clears
+	Webhook  string         `yaml:"webhook" json:"-"`   // Config file only, since the URL usually holds a secret
+	MQTT     MQTTConfig     `yaml:"mqtt" json:"mqtt"`
+	Telegram TelegramConfig `yaml:"telegram" json:"telegram"`
+}
+
+// MQTTConfig is a broker to publish alert events to, QoS 0.
+type MQTTConfig struct {
+	Broker   string `yaml:"broker" json:"-"`    // host:port, tcp://host:1883 or tls://host:8883; config file only, since the password goes there
+	Topic    string `yaml:"topic" json:"topic"` // Default goefidash/alerts
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"-"`         // Config file only, never sent to clients
+	ClientID string `yaml:"client_id" json:"clientId"` // Default goefidash-<hostname>
+}
+
+// TelegramConfig is a bot and the chat it messages.
+type TelegramConfig struct {
+	BotToken string `yaml:"bot_token" json:"-"` // From @BotFather; config file only, never sent to clients
+	ChatID   string `yaml:"chat_id" json:"chatId"`
+}
+
+// Enabled reports whether any target is set.
+func (c NotifyConfig) Enabled() bool {
+	return c.Webhook != "" || c.MQTT.Broker != "" || (c.Telegram.BotToken != "" && c.Telegram.ChatID != "")
+}
+
+// AlertRule raises an alert while a channel compares true against Value
+// for DurationS. Once raised it clears only when the channel is back past
+// Value by Hysteresis, so a reading hovering at the limit doesn't flap.
+type AlertRule struct {
+	ID         string  `yaml:"id" json:"id"`                 // Stable key (default: the field)
+	Field      string  `yaml:"field" json:"field"`           // DataFrame JSON name (e.g. "oilPressure") or aux channel
+	Op         string  `yaml:"op" json:"op"`                 // ">", ">=", "<", "<=", "==" or "!="
+	Value      float64 `yaml:"value" json:"value"`           // Threshold
+	DurationS  float64 `yaml:"duration_s" json:"durationS"`  // Must hold this long first (0 = at once)
+	Hysteresis float64 `yaml:"hysteresis" json:"hysteresis"` // Clears this far back past Value
+	Level      string  `yaml:"level" json:"level"`           // "warning" (default), "danger" or "critical"
+	Label      string  `yaml:"label" json:"label"`           // Alert text before the value (default: the field)
+	MinRPM     uint16  `yaml:"min_rpm" json:"minRpm"`        // Only above this RPM (0 = engine on or off)
+	Sound      string  `yaml:"sound" json:"sound"`           // Alarm pattern (default: alerts.alarm's for the level)
 }
 
 // DrivetrainConfig holds gear ratios for RPM-based gear detection.
@@ -93,6 +608,31 @@ type DrivetrainConfig struct {
 	FinalDrive    float64   `yaml:"final_drive" json:"finalDrive"`       // Diff ratio
 	TireCircumM   float64   `yaml:"tire_circum_m" json:"tireCircumM"`    // Tire circumference in meters
 	GearTolerance float64   `yaml:"gear_tolerance" json:"gearTolerance"` // Match tolerance (0.0-1.0), default 0.15
+	ReverseRatio  float64   `yaml:"reverse_ratio" json:"reverseRatio"`   // Reverse gear ratio (0 = unknown)
+	LearnGears    bool      `yaml:"learn_gears" json:"learnGears"`       // Learn gear ratios while driving, applied from settings
+}
+
+// SpeedConfig chooses how GPS speed and ECU VSS combine into Frame.Speed.
+type SpeedConfig struct {
+	Source   string  `yaml:"source" json:"source"`      // "fusion" (default), "vss" or "gps"; the other is the fallback
+	VSSScale float64 `yaml:"vss_scale" json:"vssScale"` // Fixed VSS correction factor (0 = learn it from GPS)
+	HoldSec  float64 `yaml:"hold_s" json:"holdSec"`     // Keep the last speed this long when both sources drop out
+
+	WheelspinPct float64 `yaml:"wheelspin_pct" json:"wheelspinPct"` // Driven-wheel slip that flags wheelspin (0 = never)
+}
+
+// IdentityConfig names the car, so a multi-car garage can tell logs,
+// sessions and snapshots apart.
+type IdentityConfig struct {
+	Name   string `yaml:"name" json:"name"`     // e.g. "Blue Miata"
+	Plate  string `yaml:"plate" json:"plate"`   // Registration plate
+	Engine string `yaml:"engine" json:"engine"` // e.g. "BP-4W, BBM turbo"
+	Tune   string `yaml:"tune" json:"tune"`     // Tune revision, e.g. "v14 E85"
+}
+
+// IsZero reports whether no identity field is set.
+func (i IdentityConfig) IsZero() bool {
+	return i == IdentityConfig{}
 }
 
 // VehicleConfig holds physical parameters for HP estimation.
@@ -103,15 +643,175 @@ type VehicleConfig struct {
 	RollingResist float64 `yaml:"rolling_resist" json:"rollingResist"`  // Rolling resistance coefficient
 }
 
+// FuelConfig describes the injectors and tank, to turn pulse width into
+// fuel flow for consumption, range and the DFCO fuel saved estimate.
+// Without injector_cc_min only DFCO time is reported.
+type FuelConfig struct {
+	InjectorCCMin float64 `yaml:"injector_cc_min" json:"injectorCcMin"` // Flow of one injector, cc/min
+	Injectors     int     `yaml:"injectors" json:"injectors"`           // Number of injectors
+	OpenTimeMs    float64 `yaml:"open_time_ms" json:"openTimeMs"`       // Injector opening time included in the pulse width
+	Squirts       int     `yaml:"squirts" json:"squirts"`               // Squirts per injector per engine cycle (1 sequential, 2 batch)
+	TankL         float64 `yaml:"tank_l" json:"tankL"`                  // Tank capacity, L (0 = no remaining/range)
+	IdleLPH       float64 `yaml:"idle_lph" json:"idleLph"`              // Idle fuel flow, L/h (0 = measure it)
+}
+
 type LoggingConfig struct {
 	Enabled  bool   `yaml:"enabled" json:"enabled"`
 	Path     string `yaml:"path" json:"path"`
 	Interval int    `yaml:"interval_ms" json:"intervalMs"` // ms between log entries
+
+	// Per-column rate and precision, keyed by CSV column name
+	Channels map[string]logger.ChannelConfig `yaml:"channels" json:"channels"`
+
+	// Full rate only at set times or at a track, slower otherwise
+	Schedule LogScheduleConfig `yaml:"schedule" json:"schedule"`
+}
+
+// InfluxConfig writes every ECU and aux channel, fused speed and the GPS
+// position to InfluxDB as one point per interval, batched. Batches that
+// can't be written are spooled under the data directory and sent once the
+// database is reachable again.
+type InfluxConfig struct {
+	URL         string            `yaml:"url" json:"-"` // http(s)://host:8086 (v2 API) or udp://host:8089 ("" = off); config file only, since the token goes there
+	Org         string            `yaml:"org" json:"org"`
+	Bucket      string            `yaml:"bucket" json:"bucket"`
+	Token       string            `yaml:"token" json:"-"`                 // Config file only, never sent to clients
+	Measurement string            `yaml:"measurement" json:"measurement"` // Default goefidash
+	Tags        map[string]string `yaml:"tags" json:"tags"`               // Default car=<identity.name>
+	IntervalMs  int               `yaml:"interval_ms" json:"intervalMs"`  // Between points (0 = 1000)
+	FlushSec    float64           `yaml:"flush_s" json:"flushSec"`        // Between writes (0 = 5)
+	SpoolMB     int               `yaml:"spool_mb" json:"spoolMb"`        // Spool limit; oldest batches dropped past it (0 = 64)
+}
+
+// LogScheduleConfig logs at the full rate inside any window or near the
+// active track, and every SlowIntervalMs otherwise.
+type LogScheduleConfig struct {
+	SlowIntervalMs int         `yaml:"slow_interval_ms" json:"slowIntervalMs"` // 0 = always full rate
+	OnTrack        bool        `yaml:"on_track" json:"onTrack"`                // Full rate within tracks.detect_radius_m of the active track
+	Windows        []LogWindow `yaml:"windows" json:"windows"`
+}
+
+// LogWindow is a daily full-rate logging period, in local time.
+type LogWindow struct {
+	Days  []string `yaml:"days" json:"days"`   // "mon" … "sun" (none = every day)
+	Start string   `yaml:"start" json:"start"` // "HH:MM", "sunrise" or "sunset", with an optional offset ("sunset-30m")
+	End   string   `yaml:"end" json:"end"`     // Same; before start runs past midnight
 }
 
 type ServerConfig struct {
 	ListenAddr string `yaml:"listen_addr" json:"listenAddr"`
 	Kiosk      bool   `yaml:"kiosk" json:"kiosk"` // Auto-launch Chromium
+	Debug      bool   `yaml:"debug" json:"-"`     // Enable /api/debug/* endpoints; config file, --debug or DEBUG_API only
+}
+
+// StartupConfig controls how long the dashboard waits at boot for
+// configured serial devices (or network transports) to appear before
+// the first connection attempt.
+type StartupConfig struct {
+	PortWaitSec int `yaml:"port_wait_s" json:"portWaitSec"` // 0 = don't wait
+}
+
+// StorageConfig sets the root directory for all runtime-written data
+// (state, odometer, records, layouts, tracks, captures).
+type StorageConfig struct {
+	DataDir string `yaml:"data_dir" json:"dataDir"`
+}
+
+// SyncConfig backs the odometer, engine hours, trip statistics and the
+// lap, performance and autocross records up to a remote endpoint, so a
+// replacement SD card can be restored from it. The backup is a JSON
+// document PUT to URL and fetched back with GET: a WebDAV share, an
+// object store URL or a few lines of web app will do.
+type SyncConfig struct {
+	URL         string `yaml:"url" json:"-"`                    // e.g. https://example.com/dash/miata.json; empty = off. Config file only, since the token goes there
+	Token       string `yaml:"token" json:"-"`                  // Sent as a bearer token; config file only, never sent to clients
+	IntervalMin int    `yaml:"interval_min" json:"intervalMin"` // Minutes between uploads while anything changes
+}
+
+// AutocrossConfig controls autocross run timing.
+type AutocrossConfig struct {
+	ConePenaltySec float64 `yaml:"cone_penalty_s" json:"conePenaltySec"` // Seconds added per cone
+}
+
+// LapsConfig configures circuit lap timing. Without StartFinish the
+// active track's start/finish line (POST /api/track/startfinish) is used.
+type LapsConfig struct {
+	StartFinish []track.Point `yaml:"start_finish" json:"startFinish"` // Two points, one each side of the line
+	MinLapSec   float64       `yaml:"min_lap_s" json:"minLapSec"`      // Shorter "laps" are line jitter
+}
+
+// PerfConfig configures the performance timers, which arm at a
+// standstill and time each launch.
+type PerfConfig struct {
+	Enabled   bool      `yaml:"enabled" json:"enabled"`
+	SpeedsKph []float64 `yaml:"speeds_kph" json:"speedsKph"` // Speed targets timed from rest
+}
+
+// SystemConfig enables managing the car computer from the dash: reboot,
+// shutdown, restarting the service and toggling the WiFi hotspot. The
+// commands run through "sudo -n", so the service user needs a sudoers
+// rule for them (deploy/install.sh offers one).
+type SystemConfig struct {
+	Enabled bool          `yaml:"enabled" json:"enabled"` // Off: /api/system/* return 404
+	Service string        `yaml:"service" json:"service"` // systemd unit for restart-service
+	Hotspot HotspotConfig `yaml:"hotspot" json:"hotspot"`
+}
+
+// HotspotConfig says how the WiFi hotspot is switched: a NetworkManager
+// connection (nmcli) or the hostapd service.
+type HotspotConfig struct {
+	Method     string `yaml:"method" json:"method"`         // "nmcli" or "hostapd"
+	Connection string `yaml:"connection" json:"connection"` // nmcli connection name
+	Interface  string `yaml:"interface" json:"interface"`   // WiFi interface for network status; "" = first wireless
+}
+
+// TracksConfig configures the track library. Library files (YAML or
+// JSON, or directories of them) add to the built-in tracks; on the first
+// GPS fix the nearest track within DetectRadiusM becomes the active one.
+type TracksConfig struct {
+	Library       []string `yaml:"library" json:"library"`
+	AutoDetect    bool     `yaml:"auto_detect" json:"autoDetect"`
+	DetectRadiusM float64  `yaml:"detect_radius_m" json:"detectRadiusM"`
+}
+
+// StatsConfig chooses the channels summarised per drive at /api/stats,
+// besides the fixed peaks.
+type StatsConfig struct {
+	Channels []string `yaml:"channels" json:"channels"` // DataFrame JSON names or aux channels: min, max, mean
+	InFrame  bool     `yaml:"in_frame" json:"inFrame"`  // Also send them in a frame once a second
+}
+
+// SnapshotConfig controls snapshot bundles: the current frame, the last
+// 10 s of history, active alerts and GPS position in one JSON file.
+type SnapshotConfig struct {
+	OnAlert     bool `yaml:"on_alert" json:"onAlert"`       // Capture when an alert is raised
+	CooldownSec int  `yaml:"cooldown_s" json:"cooldownSec"` // Min seconds between alert captures
+	Keep        int  `yaml:"keep" json:"keep"`              // Bundles kept (oldest pruned; 0 = all)
+}
+
+// StaleConfig sets how old ECU and GPS data may get before it is dropped
+// from frames. With both stale, clients get noData heartbeat frames.
+type StaleConfig struct {
+	ECUSec float64 `yaml:"ecu_s" json:"ecuSec"`
+	GPSSec float64 `yaml:"gps_s" json:"gpsSec"`
+}
+
+// QuiescentConfig controls engine-off behaviour. After AfterSec with the
+// engine off, full frames give way to a reduced set (battery, coolant,
+// odometer) at RateHz; after SleepAfterSec ECU polling drops to 1 Hz until
+// the engine starts, the car moves or POST /api/wake.
+type QuiescentConfig struct {
+	Enabled       bool    `yaml:"enabled" json:"enabled"`
+	AfterSec      float64 `yaml:"after_s" json:"afterSec"`
+	RateHz        float64 `yaml:"rate_hz" json:"rateHz"`
+	SleepAfterSec float64 `yaml:"sleep_after_s" json:"sleepAfterSec"` // 0 = never sleep
+}
+
+// CooldownConfig sets the coolant temperature the post-shutdown countdown
+// runs down to.
+type CooldownConfig struct {
+	Enabled bool    `yaml:"enabled" json:"enabled"`
+	TargetC float64 `yaml:"target_c" json:"targetC"`
 }
 
 // DefaultConfig returns a config with sensible defaults.
@@ -126,10 +826,75 @@ func DefaultConfig() *Config {
 			PollHz:   20,
 			Protocol: "generic",
 		},
+		IMU: IMUConfig{
+			Type:    "disabled",
+			Bus:     "/dev/i2c-1",
+			RateHz:  100,
+			Forward: "x",
+			Left:    "y",
+		},
+		Keypad: KeypadConfig{
+			Type:      "disabled",
+			Interface: "can0",
+			NodeID:    0x15,
+			Size:      12,
+			Keys: []KeypadKey{
+				{Key: 1, Action: "layout:classic", LED: "page"},
+				{Key: 2, Action: "layout:sweep", LED: "page"},
+				{Key: 3, Action: "layout:race", LED: "page"},
+				{Key: 4, Action: "layout:minimal", LED: "page"},
+				{Key: 5, Action: "snapshot", LED: "alerts"},
+				{Key: 6, Action: "logging", LED: "logging"},
+				{Key: 7, Action: "autox_arm", LED: "autox"},
+				{Key: 8, Action: "autox_cone"},
+				{Key: 9, Action: "trip_reset"},
+				{Key: 10, Action: "trip_b_reset"},
+				{Key: 11, Action: "fillup"},
+				{Key: 12, Action: "wake"},
+			},
+		},
+		Gauges: GaugesConfig{
+			RateHz:    10,
+			MaxRateHz: 30,
+		},
+		RealDash: RealDashConfig{
+			RateHz: 20,
+			Channels: []string{
+				"rpm", "speed", "map", "tps", "afr", "lambda", "coolant", "iat",
+				"advance", "batteryVoltage", "oilPressure", "fuelPressure", "gear", "boostTarget",
+			},
+		},
+		RaceChrono: RaceChronoConfig{
+			RateHz: 20,
+			Analog: []string{"tps", "map", "coolant", "iat", "afr", "oilPressure", "batteryVoltage"},
+			GPS:    true,
+		},
+		Alerts: AlertsConfig{
+			Alarm: AlarmConfig{
+				ToneHz:   2800,
+				Danger:   "double",
+				Critical: "fast",
+			},
+		},
+		GPIO: GPIOConfig{
+			Chip:       "/dev/gpiochip0",
+			DebounceMs: 30,
+			Strip: GPIOStripConfig{
+				LEDs:       8,
+				Brightness: 0.3,
+				Alerts:     true,
+			},
+		},
 		GPS: GPSConfig{
 			Type:     "demo",
 			PortPath: "/dev/ttyGPS",
 			BaudRate: 9600,
+			Filter: gps.FilterConfig{
+				Mode:       gps.FilterKalman,
+				Alpha:      0.3,
+				AccelNoise: 3,
+				MaxAccelG:  1.5,
+			},
 		},
 		Display: DisplayConfig{
 			Units: UnitsConfig{
@@ -152,6 +917,16 @@ func DefaultConfig() *Config {
 				BattLow:     12.0,
 				BattHigh:    15.5,
 				KnockWarn:   3,
+
+				PWImbalanceWarn: 10,
+				VVTErrorWarn:    5,
+				VVTErrorDanger:  10,
+				BoostErrorWarn:  20,
+				LeanBoostKPa:    130,
+				LeanBoostMargin: 1.0,
+				LeanBoostFrames: 5,
+				TireLowKPa:      160,
+				TireFlatKPa:     100,
 			},
 			Layout: "classic",
 		},
@@ -168,6 +943,10 @@ func DefaultConfig() *Config {
 			FrontalAreaM2: 2.2,
 			RollingResist: 0.012,
 		},
+		Fuel: FuelConfig{
+			Injectors: 4,
+			Squirts:   1,
+		},
 		Logging: LoggingConfig{
 			Enabled:  false,
 			Path:     "/var/log/speeduino-dash",
@@ -177,6 +956,70 @@ func DefaultConfig() *Config {
 			ListenAddr: ":8080",
 			Kiosk:      false,
 		},
+		Startup: StartupConfig{
+			PortWaitSec: 30,
+		},
+		Storage: StorageConfig{
+			DataDir: storage.DefaultDir,
+		},
+		Sync: SyncConfig{
+			IntervalMin: 60,
+		},
+		Autocross: AutocrossConfig{
+			ConePenaltySec: 2,
+		},
+		Laps: LapsConfig{
+			MinLapSec: 10,
+		},
+		Perf: PerfConfig{
+			Enabled:   true,
+			SpeedsKph: []float64{60, 100},
+		},
+		Tracks: TracksConfig{
+			AutoDetect:    true,
+			DetectRadiusM: 3000,
+		},
+		System: SystemConfig{
+			Service: "speeduino-dash",
+			Hotspot: HotspotConfig{
+				Method:     "nmcli",
+				Connection: "Hotspot",
+			},
+		},
+		Stats: StatsConfig{
+			Channels: []string{"rpm", "map", "tps", "coolant", "iat", "afr", "oilPressure", "batteryVoltage"},
+		},
+		Snapshots: SnapshotConfig{
+			OnAlert:     true,
+			CooldownSec: 60,
+			Keep:        50,
+		},
+		AFR: AFRConfig{
+			Mode:     "external",
+			LoadKPa:  100,
+			BlendKPa: 20,
+			MaxDiff:  1.5,
+			HoldSec:  3,
+		},
+		Stale: StaleConfig{
+			ECUSec: 2,
+			GPSSec: 5,
+		},
+		Quiescent: QuiescentConfig{
+			Enabled:       true,
+			AfterSec:      5,
+			RateHz:        1,
+			SleepAfterSec: 0, // Opt in: sleep slows ECU polling to 1 Hz
+		},
+		Cooldown: CooldownConfig{
+			Enabled: true,
+			TargetC: 70,
+		},
+		Speed: SpeedConfig{
+			Source:       "fusion",
+			HoldSec:      3,
+			WheelspinPct: 10,
+		},
 	}
 }
 
@@ -269,9 +1112,38 @@ func (c *Config) applyEnvOverrides() {
 			c.GPS.BaudRate = n
 		}
 	}
+	if v := os.Getenv("GPS_RATE_HZ"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.GPS.RateHz = n
+		}
+	}
+	if v := os.Getenv("GPS_LISTEN"); v != "" {
+		c.GPS.Listen = v
+	}
+	if v := os.Getenv("GPS_CONFIGURE"); v != "" {
+		c.GPS.ConfigureReceiver = v == "1" || v == "true" || v == "yes"
+	}
+	if v := os.Getenv("GPS_SET_CLOCK"); v != "" {
+		c.GPS.SetClock = v == "1" || v == "true" || v == "yes"
+	}
+	if v := os.Getenv("GPS_RAW_LOG"); v != "" {
+		c.GPS.RawLog = v == "1" || v == "true" || v == "yes"
+	}
+	if v := os.Getenv("VEHICLE_NAME"); v != "" {
+		c.Identity.Name = v
+	}
+	if v := os.Getenv("IMU_TYPE"); v != "" {
+		c.IMU.Type = v
+	}
+	if v := os.Getenv("IMU_BUS"); v != "" {
+		c.IMU.Bus = v
+	}
 	if v := os.Getenv("LISTEN_ADDR"); v != "" {
 		c.Server.ListenAddr = v
 	}
+	if v := os.Getenv("DEBUG_API"); v != "" {
+		c.Server.Debug = v == "1" || v == "true" || v == "yes"
+	}
 	if v := os.Getenv("TEMP_UNIT"); v != "" {
 		c.Display.Units.Temperature = v
 	}
@@ -284,6 +1156,12 @@ func (c *Config) applyEnvOverrides() {
 	if v := os.Getenv("ECU_PROTOCOL"); v != "" {
 		c.ECU.Protocol = v
 	}
+	if v := os.Getenv("ECU_PRUNE_CHANNELS"); v != "" {
+		c.ECU.PruneChannels = v == "1" || v == "true" || v == "yes"
+	}
+	if v := os.Getenv("SPEED_SOURCE"); v != "" {
+		c.Speed.Source = v
+	}
 	// Logging
 	if v := os.Getenv("LOG_ENABLED"); v != "" {
 		c.Logging.Enabled = v == "1" || v == "true" || v == "yes"
@@ -296,6 +1174,43 @@ func (c *Config) applyEnvOverrides() {
 			c.Logging.Interval = n
 		}
 	}
+	// Storage
+	if v := os.Getenv("DATA_DIR"); v != "" {
+		c.Storage.DataDir = v
+	}
+	// Startup
+	if v := os.Getenv("PORT_WAIT_S"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.Startup.PortWaitSec = n
+		}
+	}
+	// Autocross
+	if v := os.Getenv("CONE_PENALTY_S"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.Autocross.ConePenaltySec = f
+		}
+	}
+	// Track library
+	if v := os.Getenv("TRACK_LIBRARY"); v != "" {
+		c.Tracks.Library = strings.Split(v, ",")
+	}
+	if v := os.Getenv("TRACK_AUTO_DETECT"); v != "" {
+		c.Tracks.AutoDetect = v == "1" || v == "true" || v == "yes"
+	}
+	// Snapshots
+	if v := os.Getenv("SNAPSHOT_ON_ALERT"); v != "" {
+		c.Snapshots.OnAlert = v == "1" || v == "true" || v == "yes"
+	}
+	// System maintenance
+	if v := os.Getenv("SYSTEM_API"); v != "" {
+		c.System.Enabled = v == "1" || v == "true" || v == "yes"
+	}
+	if v := os.Getenv("HOTSPOT_METHOD"); v != "" {
+		c.System.Hotspot.Method = v
+	}
+	if v := os.Getenv("HOTSPOT_CONNECTION"); v != "" {
+		c.System.Hotspot.Connection = v
+	}
 }
 
 // Save writes the config to its YAML file.
@@ -311,7 +1226,260 @@ func (c *Config) Save() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(c.path, data, 0644)
+	return storage.WriteFileAtomic(c.path, data, 0644)
+}
+
+// Thresholds returns the current warning thresholds.
+func (c *Config) Thresholds() ThresholdConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Display.Thresholds
+}
+
+// StaleLimits returns how old ECU and GPS data may be before it's stale.
+func (c *Config) StaleLimits() (ecuLimit, gpsLimit time.Duration) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Duration(c.Stale.ECUSec * float64(time.Second)),
+		time.Duration(c.Stale.GPSSec * float64(time.Second))
+}
+
+// QuiescentSnapshot returns a copy of the engine-off settings.
+func (c *Config) QuiescentSnapshot() QuiescentConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Quiescent
+}
+
+// AFRSnapshot returns a copy of the AFR source settings.
+func (c *Config) AFRSnapshot() AFRConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AFR
+}
+
+// CooldownSnapshot returns a copy of the cooldown settings.
+func (c *Config) CooldownSnapshot() CooldownConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Cooldown
+}
+
+// DrivetrainSnapshot returns a copy of the drivetrain settings.
+func (c *Config) DrivetrainSnapshot() DrivetrainConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	d := c.Drivetrain
+	d.GearRatios = append([]float64(nil), d.GearRatios...)
+	return d
+}
+
+// AlertRules returns a copy of the alert rules.
+func (c *Config) AlertRules() []AlertRule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]AlertRule(nil), c.Alerts.Rules...)
+}
+
+// AlarmSnapshot returns a copy of the audible alarm settings.
+func (c *Config) AlarmSnapshot() AlarmConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	a := c.Alerts.Alarm
+	a.Sounds = maps.Clone(a.Sounds)
+	return a
+}
+
+// NotifySnapshot returns a copy of the alert notification settings.
+func (c *Config) NotifySnapshot() NotifyConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Alerts.Notify
+}
+
+// SyncSnapshot returns a copy of the backup sync settings.
+func (c *Config) SyncSnapshot() SyncConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Sync
+}
+
+// StatsSnapshot returns a copy of the drive statistics settings.
+func (c *Config) StatsSnapshot() StatsConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	st := c.Stats
+	st.Channels = append([]string(nil), st.Channels...)
+	return st
+}
+
+// KeypadSnapshot returns a copy of the keypad settings.
+func (c *Config) KeypadSnapshot() KeypadConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	k := c.Keypad
+	k.Keys = append([]KeypadKey(nil), k.Keys...)
+	return k
+}
+
+// GPIOSnapshot returns a copy of the GPIO light settings.
+func (c *Config) GPIOSnapshot() GPIOConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	g := c.GPIO
+	g.Pins = append([]GPIOPinConfig(nil), g.Pins...)
+	g.Buttons = append([]GPIOButtonConfig(nil), g.Buttons...)
+	g.Strip.Colors = append([]string(nil), g.Strip.Colors...)
+	return g
+}
+
+// CANOutSnapshot returns a copy of the CAN output settings.
+func (c *Config) CANOutSnapshot() CANOutConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	o := c.CANOut
+	o.Messages = append([]CANMessageConfig(nil), o.Messages...)
+	for i := range o.Messages {
+		o.Messages[i].Signals = append([]CANSignalConfig(nil), o.Messages[i].Signals...)
+	}
+	return o
+}
+
+// TelemetrySnapshot returns a copy of the MQTT telemetry settings.
+func (c *Config) TelemetrySnapshot() TelemetryConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	t := c.Telemetry
+	t.Channels = append([]string(nil), t.Channels...)
+	return t
+}
+
+// RaceChronoSnapshot returns a copy of the RaceChrono feed settings.
+func (c *Config) RaceChronoSnapshot() RaceChronoConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r := c.RaceChrono
+	r.Analog = append([]string(nil), r.Analog...)
+	return r
+}
+
+// RealDashSnapshot returns a copy of the RealDash feed settings.
+func (c *Config) RealDashSnapshot() RealDashConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r := c.RealDash
+	r.Channels = append([]string(nil), r.Channels...)
+	return r
+}
+
+// SignalKSnapshot returns a copy of the SignalK settings.
+func (c *Config) SignalKSnapshot() SignalKConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	k := c.SignalK
+	k.Paths = append([]SignalKPath(nil), k.Paths...)
+	return k
+}
+
+// UDPOutSnapshot returns a copy of the UDP telemetry settings.
+func (c *Config) UDPOutSnapshot() UDPOutConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	u := c.UDPOut
+	u.Fields = append([]UDPFieldConfig(nil), u.Fields...)
+	return u
+}
+
+// GRPCSnapshot returns the gRPC API settings.
+func (c *Config) GRPCSnapshot() GRPCConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.GRPC
+}
+
+// GaugesSnapshot returns a copy of the gauge settings.
+func (c *Config) GaugesSnapshot() GaugesConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	g := c.Gauges
+	g.Serial = append([]GaugeSerialConfig(nil), g.Serial...)
+	g.Devices = make([]GaugeDeviceConfig, len(c.Gauges.Devices))
+	for i, d := range c.Gauges.Devices {
+		d.Channels = append([]string(nil), d.Channels...)
+		g.Devices[i] = d
+	}
+	return g
+}
+
+// FuelSnapshot returns a copy of the injector settings.
+func (c *Config) FuelSnapshot() FuelConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Fuel
+}
+
+// SpeedSnapshot returns a copy of the speed source settings.
+func (c *Config) SpeedSnapshot() SpeedConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Speed
+}
+
+// LapsSnapshot returns a copy of the lap timing settings.
+func (c *Config) LapsSnapshot() LapsConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	l := c.Laps
+	l.StartFinish = append([]track.Point(nil), l.StartFinish...)
+	return l
+}
+
+// PerfSnapshot returns a copy of the performance timer settings.
+func (c *Config) PerfSnapshot() PerfConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p := c.Perf
+	p.SpeedsKph = append([]float64(nil), p.SpeedsKph...)
+	return p
+}
+
+// IdentitySnapshot returns a copy of the vehicle identity.
+func (c *Config) IdentitySnapshot() IdentityConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Identity
+}
+
+// SystemSnapshot returns a copy of the OS maintenance settings.
+func (c *Config) SystemSnapshot() SystemConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.System
+}
+
+// LogScheduleSnapshot returns a copy of the logging rate schedule.
+func (c *Config) LogScheduleSnapshot() LogScheduleConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	sc := c.Logging.Schedule
+	sc.Windows = append([]LogWindow(nil), sc.Windows...)
+	return sc
+}
+
+// TracksSnapshot returns a copy of the track library settings.
+func (c *Config) TracksSnapshot() TracksConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	t := c.Tracks
+	t.Library = append([]string(nil), t.Library...)
+	return t
+}
+
+// DisplaySnapshot returns a copy of the display preferences.
+func (c *Config) DisplaySnapshot() DisplayConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Display
 }
 
 // ToJSON serializes config for the API.
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+const (
+	cooldownSampleEvery = 5 * time.Second  // CLT is whole degrees; sample slowly
+	cooldownWindow      = 60 * time.Second // Slope is measured over this much history
+	cooldownMinRate     = 0.005            // °C/s; slower than this is "not cooling"
+)
+
+// CooldownStatus is the post-shutdown coolant countdown, broadcast as
+// Frame.Cooldown from engine-off until the engine restarts.
+type CooldownStatus struct {
+	Active       bool    `json:"active"`                 // Counting down
+	Done         bool    `json:"done"`                   // Target reached: safe to cover / shut off
+	CoolantC     float64 `json:"coolantC"`               // Current CLT
+	StartC       float64 `json:"startC"`                 // CLT at engine-off
+	TargetC      float64 `json:"targetC"`                // Configured target
+	RateCPerMin  float64 `json:"rateCPerMin"`            // Current change, negative = cooling
+	RemainingSec float64 `json:"remainingSec,omitempty"` // Estimate; 0 = unknown (e.g. heat soak)
+	ElapsedSec   float64 `json:"elapsedSec"`             // Since engine-off
+}
+
+type cltSample struct {
+	at time.Time
+	c  float64
+}
+
+// cooldownTracker watches coolant temperature after the engine stops and
+// estimates the time until it reaches the target from the recent slope.
+// CLT commonly rises for a minute or two after shutdown (heat soak); no
+// estimate is given until it is actually falling.
+type cooldownTracker struct {
+	mu      sync.Mutex
+	running bool // Engine seen running since the last countdown
+	status  *CooldownStatus
+	startAt time.Time
+	samples []cltSample
+}
+
+// update feeds the latest ECU frame (nil when there is none) and returns
+// the current status, or nil when there's nothing to report.
+func (t *cooldownTracker) update(now time.Time, f *ecu.DataFrame, cfg CooldownConfig) *CooldownStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !cfg.Enabled {
+		t.status = nil
+		return nil
+	}
+	if f == nil {
+		return t.snapshotLocked()
+	}
+	if f.RPM > 0 {
+		t.running = true
+		t.status = nil
+		return nil
+	}
+
+	if t.running {
+		// Engine just stopped: start a countdown
+		t.running = false
+		t.startAt = now
+		t.samples = t.samples[:0]
+		t.status = &CooldownStatus{Active: true, StartC: f.Coolant, TargetC: cfg.TargetC}
+		log.Printf("[cooldown] engine off at %.0f°C, target %.0f°C", f.Coolant, cfg.TargetC)
+	}
+	st := t.status
+	if st == nil || !st.Active {
+		return t.snapshotLocked()
+	}
+
+	st.CoolantC = f.Coolant
+	st.ElapsedSec = math.Round(now.Sub(t.startAt).Seconds())
+	if n := len(t.samples); n == 0 || now.Sub(t.samples[n-1].at) >= cooldownSampleEvery {
+		t.samples = append(t.samples, cltSample{now, f.Coolant})
+		for len(t.samples) > 2 && now.Sub(t.samples[0].at) > cooldownWindow {
+			t.samples = t.samples[1:]
+		}
+	}
+
+	st.RateCPerMin, st.RemainingSec = 0, 0
+	if n := len(t.samples); n >= 2 {
+		first, last := t.samples[0], t.samples[n-1]
+		rate := (last.c - first.c) / last.at.Sub(first.at).Seconds()
+		st.RateCPerMin = math.Round(rate*60*10) / 10
+		if rate < -cooldownMinRate {
+			st.RemainingSec = math.Round((f.Coolant - cfg.TargetC) / -rate)
+		}
+	}
+
+	if f.Coolant <= cfg.TargetC {
+		st.Active, st.Done, st.RemainingSec = false, true, 0
+		log.Printf("[cooldown] reached %.0f°C after %.0fs", f.Coolant, st.ElapsedSec)
+	}
+	return t.snapshotLocked()
+}
+
+// cooling reports whether a countdown is running.
+func (t *cooldownTracker) cooling() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status != nil && t.status.Active
+}
+
+func (t *cooldownTracker) snapshotLocked() *CooldownStatus {
+	if t.status == nil {
+		return nil
+	}
+	st := *t.status
+	return &st
+}
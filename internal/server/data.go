@@ -0,0 +1,32 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// handleData returns the latest frame, as sent over the WebSocket, for
+// scripts, widgets and health checks that want one reading without
+// holding a connection open. While ECU and GPS are both stale it's the
+// latest heartbeat, with noData set; before the first frame it's a 503.
+//
+//	GET /api/data
+func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	f, _ := s.frames.latest()
+	if f == nil {
+		http.Error(w, "no data yet", 503)
+		return
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
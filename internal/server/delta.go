@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// deltaKeyframe is how often a delta client gets a whole data frame, so
+// it recovers from anything it got wrong.
+const deltaKeyframe = 5 * time.Second
+
+// deltaFrame encodes a data frame for a delta client (?delta=1): as a
+// keyframe, {"key":true,"delta":<frame>}, when one is due, otherwise as
+// {"delta":<patch>}, a JSON Merge Patch (RFC 7396) against the last data
+// frame sent. Working from what was actually sent, rather than what was
+// queued, keeps coalesced and rate-stepped frames out of the chain.
+//
+// Only the writer calls it, so the state needs no lock.
+func (c *frameClient) deltaFrame(now time.Time, m clientMessage) []byte {
+	var out []byte
+	if c.last == nil || now.Sub(c.lastKey) >= deltaKeyframe {
+		out = append(append([]byte(`{"key":true,"delta":`), m.data...), '}')
+		c.lastKey = now
+	} else {
+		var err error
+		if out, err = json.Marshal(map[string]any{"delta": mergePatch(c.last, m.frame)}); err != nil {
+			return nil
+		}
+	}
+	c.last = m.frame
+	return c.encode(out)
+}
+
+// mergePatch returns the JSON Merge Patch that turns old into new: the
+// members that changed, objects recursively, and null for those removed.
+func mergePatch(old, new map[string]any) map[string]any {
+	patch := make(map[string]any)
+	for k, nv := range new {
+		ov, ok := old[k]
+		switch {
+		case !ok:
+			patch[k] = nv
+		case isObject(ov) && isObject(nv):
+			if p := mergePatch(ov.(map[string]any), nv.(map[string]any)); len(p) > 0 {
+				patch[k] = p
+			}
+		case !reflect.DeepEqual(ov, nv):
+			patch[k] = nv
+		}
+	}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
+
+func isObject(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// dfcoFile is the trip's DFCO totals and the learned idle flow inside the
+// data directory.
+const dfcoFile = storage.DirState + "/dfco.json"
+
+const (
+	dfcoMaxTick    = time.Second      // Longer gaps (ECU dropouts) aren't counted
+	dfcoIdleTau    = 30 * time.Second // Smoothing of the idle flow
+	dfcoIdleMaxRPM = 1500
+	dfcoIdleMaxTPS = 2.0  // %
+	dfcoIdleMinCLT = 60.0 // °C; colder idle is enriched
+)
+
+// DFCOStats is time spent in deceleration fuel cut and the fuel it saved,
+// estimated as what the engine would have burnt idling for that time.
+type DFCOStats struct {
+	Seconds float64 `json:"seconds"`          // Time in DFCO
+	SavedL  float64 `json:"savedL,omitempty"` // Fuel saved, L (0 until the idle flow is known)
+}
+
+// dfcoTracker totals DFCO for the trip and the current drive, and learns
+// the idle fuel flow the savings are measured against.
+type dfcoTracker struct {
+	mu      sync.Mutex
+	last    time.Time
+	idleLPH float64 // Fuel flow at warm idle, smoothed, L/h
+	trip    DFCOStats
+	drive   DFCOStats
+	dirty   bool // Changed since last saved
+}
+
+// dfcoState is the persisted form of dfcoTracker.
+type dfcoState struct {
+	Trip    DFCOStats `json:"trip"`
+	IdleLPH float64   `json:"idleLph"`
+}
+
+// update adds one broadcast tick. speed is km/h, for telling idle from
+// coasting; flowLPH is the fuel flow (fuelFlowLPH).
+func (d *dfcoTracker) update(now time.Time, e *ecu.DataFrame, speed, flowLPH float64, fuel FuelConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if e == nil || !e.Running {
+		d.last = time.Time{}
+		return
+	}
+	prev := d.last
+	d.last = now
+	dt := now.Sub(prev)
+	if prev.IsZero() || dt <= 0 || dt > dfcoMaxTick {
+		return
+	}
+
+	if e.DFCOOn {
+		saved := d.idleLPHLocked(fuel) * dt.Hours()
+		d.trip.Seconds += dt.Seconds()
+		d.trip.SavedL += saved
+		d.drive.Seconds += dt.Seconds()
+		d.drive.SavedL += saved
+		d.dirty = true
+		return
+	}
+	if e.RPM < dfcoIdleMaxRPM && e.TPS < dfcoIdleMaxTPS && e.Coolant >= dfcoIdleMinCLT &&
+		speed < 3 && flowLPH > 0 {
+		if d.idleLPH == 0 {
+			d.idleLPH = flowLPH
+		} else {
+			d.idleLPH += (flowLPH - d.idleLPH) * (1 - math.Exp(-dt.Seconds()/dfcoIdleTau.Seconds()))
+		}
+		d.dirty = true
+	}
+}
+
+// idleLPHLocked returns the idle fuel flow in L/h: fuel.idle_lph if set,
+// else as measured, else 0.
+func (d *dfcoTracker) idleLPHLocked(fuel FuelConfig) float64 {
+	if fuel.IdleLPH > 0 {
+		return fuel.IdleLPH
+	}
+	return d.idleLPH
+}
+
+// tripStats returns the trip's totals, rounded for display.
+func (d *dfcoTracker) tripStats() *DFCOStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return roundDFCO(d.trip)
+}
+
+// driveStats returns the current drive's totals, or nil if it had none.
+func (d *dfcoTracker) driveStats() *DFCOStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.drive.Seconds == 0 {
+		return nil
+	}
+	return roundDFCO(d.drive)
+}
+
+func roundDFCO(st DFCOStats) *DFCOStats {
+	return &DFCOStats{
+		Seconds: math.Round(st.Seconds),
+		SavedL:  math.Round(st.SavedL*1000) / 1000,
+	}
+}
+
+// resetTrip zeroes the trip totals, with the trip odometer.
+func (d *dfcoTracker) resetTrip() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.trip, d.dirty = DFCOStats{}, true
+}
+
+// resetDrive starts a new drive's totals.
+func (d *dfcoTracker) resetDrive() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.drive, d.last = DFCOStats{}, time.Time{}
+}
+
+// loadDFCO restores the trip's DFCO totals and the idle flow from disk.
+func (s *Server) loadDFCO() {
+	var st dfcoState
+	if err := s.store.ReadJSON(dfcoFile, &st); err != nil {
+		return
+	}
+	s.dfco.mu.Lock()
+	s.dfco.trip, s.dfco.idleLPH = st.Trip, st.IdleLPH
+	s.dfco.mu.Unlock()
+	log.Printf("[dfco] loaded: trip %.0f s, %.2f L saved", st.Trip.Seconds, st.Trip.SavedL)
+}
+
+// saveDFCO persists the trip's DFCO totals, if they have changed.
+func (s *Server) saveDFCO() {
+	d := &s.dfco
+	d.mu.Lock()
+	if !d.dirty {
+		d.mu.Unlock()
+		return
+	}
+	d.dirty = false
+	st := dfcoState{Trip: d.trip, IdleLPH: d.idleLPH}
+	d.mu.Unlock()
+	if err := s.store.WriteJSON(dfcoFile, st); err != nil {
+		log.Printf("[dfco] save failed: %v", err)
+	}
+}
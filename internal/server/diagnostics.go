@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/diag"
+	"github.com/shaunagostinho/speeduino-dash/internal/influx"
+)
+
+// wsStats counts WebSocket delivery, to tell a stuttering dash caused by
+// slow clients apart from serial trouble.
+type wsStats struct {
+	sent      atomic.Uint64 // Frames queued to clients
+	dropped   atomic.Uint64 // Stale frames discarded from a full client queue for a newer one
+	lastBytes atomic.Int64  // Size of the last broadcast frame
+}
+
+// providerDiag is one provider's entry in the diagnostics report.
+type providerDiag struct {
+	Name      string         `json:"name"`
+	Connected *bool          `json:"connected,omitempty"`
+	Link      *diag.Snapshot `json:"link,omitempty"`     // nil if the provider keeps no link stats
+	Rejected  uint64         `json:"rejected,omitempty"` // GPS fixes dropped as glitches by the filter
+}
+
+// newProviderDiag reports on p, which may implement diag.Reporter.
+func newProviderDiag(name string, p any, connected *bool) providerDiag {
+	d := providerDiag{Name: name, Connected: connected}
+	if r, ok := p.(diag.Reporter); ok {
+		snap := r.Diagnostics()
+		d.Link = &snap
+	}
+	return d
+}
+
+// handleDiagnostics reports serial link statistics for each provider,
+// poll and broadcast loop timing, and WebSocket delivery statistics.
+//
+//	GET /api/diagnostics
+func (s *Server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+
+	clients, reduced := s.clientCounts()
+	resp := struct {
+		UptimeSec float64                 `json:"uptimeSec"`
+		Providers map[string]providerDiag `json:"providers"`
+		Loops     map[string]LoopTiming   `json:"loops"`
+		WebSocket struct {
+			Clients        int    `json:"clients"`
+			ReducedClients int    `json:"reducedClients"` // Stepped down to a lower frame rate
+			FramesSent     uint64 `json:"framesSent"`
+			FramesDropped  uint64 `json:"framesDropped"`
+			LastFrameBytes int64  `json:"lastFrameBytes"`
+		} `json:"websocket"`
+		Network *NetworkStatus `json:"network,omitempty"`
+		Influx  *influx.Stats  `json:"influx,omitempty"`
+	}{
+		UptimeSec: time.Since(s.started).Round(time.Second).Seconds(),
+		Providers: s.providerDiags(),
+		Loops:     s.loops.snapshot(),
+	}
+	resp.WebSocket.Clients = clients
+	resp.WebSocket.ReducedClients = reduced
+	resp.WebSocket.FramesSent = s.ws.sent.Load()
+	resp.WebSocket.FramesDropped = s.ws.dropped.Load()
+	resp.WebSocket.LastFrameBytes = s.ws.lastBytes.Load()
+	resp.Network = s.network.Load()
+	if s.influx != nil {
+		st := s.influx.Stats()
+		resp.Influx = &st
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// providerDiags reports on every provider, keyed "ecu", "ecu:<name>",
+// "gps" and "sensor:<name>".
+func (s *Server) providerDiags() map[string]providerDiag {
+	providers := make(map[string]providerDiag)
+	if s.ecuProv != nil {
+		c := s.ecuProv.IsConnected()
+		providers["ecu"] = newProviderDiag(s.ecuProv.Name(), s.ecuProv, &c)
+	}
+	for _, x := range s.extraECUs {
+		c := x.prov.IsConnected()
+		providers["ecu:"+x.name] = newProviderDiag(x.prov.Name(), x.prov, &c)
+	}
+	if s.gpsProv != nil {
+		d := newProviderDiag(s.gpsProv.Name(), s.gpsProv, nil)
+		d.Rejected = s.gpsFilter.Rejected()
+		providers["gps"] = d
+	}
+	for _, a := range s.sensors {
+		providers["sensor:"+a.cfg.Name] = newProviderDiag(a.prov.Name(), a.prov, nil)
+	}
+	return providers
+}
+
+// clientCounts returns how many WebSocket and SSE clients are connected
+// and how many of them are stepped down to a lower frame rate.
+func (s *Server) clientCounts() (clients, reduced int) {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+	for c := range s.clients {
+		if c.reduced() {
+			reduced++
+		}
+	}
+	return len(s.clients), reduced
+}
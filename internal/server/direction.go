@@ -0,0 +1,159 @@
+package server
+
+import (
+	"math"
+	"sync"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+)
+
+// Direction of travel, broadcast as Frame.Direction.
+const (
+	dirStopped = "stopped"
+	dirForward = "forward"
+	dirReverse = "reverse"
+)
+
+const (
+	dirStopKph       = 2.0  // At or below = stopped
+	dirCourseKph     = 5.0  // Above = GPS course is trustworthy
+	dirDepartM       = 3.0  // Distance from the stop point before judging departure
+	dirFlipDeg       = 135  // Departure this far from the arrival course = flipped
+	dirMaxReverseKph = 30.0 // Faster than this can't be reverse
+)
+
+// directionTracker infers forward/reverse travel from GPS. Course over
+// ground is the direction of the velocity vector, so on its own it can't
+// tell reversing from driving forward. Instead the tracker remembers the
+// course on arrival at each standstill and, on departure, compares it with
+// the bearing from the stop point to the new position: a near-180° flip
+// means the car changed between forward and reverse (a three-point turn
+// flips twice). A reverse-gear hint from the drivetrain overrides.
+type directionTracker struct {
+	mu      sync.Mutex
+	reverse bool // Current sense of travel
+	moving  bool
+
+	course     float64 // Course while last moving
+	haveCourse bool
+
+	stopLat, stopLon float64 // Where the car last came to rest
+	departing        bool    // Moving again, sense not yet judged
+}
+
+// update feeds a GPS fix and returns the current direction.
+func (t *directionTracker) update(d *gps.Data, reverseGear bool) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if d == nil || !d.Valid {
+		return t.currentLocked()
+	}
+
+	if d.Speed <= dirStopKph {
+		if t.moving {
+			t.moving = false
+			t.departing = false
+			t.stopLat, t.stopLon = d.Latitude, d.Longitude
+		}
+		return dirStopped
+	}
+
+	if !t.moving {
+		t.moving = true
+		t.departing = t.haveCourse
+	}
+
+	if t.departing {
+		if haversineKm(t.stopLat, t.stopLon, d.Latitude, d.Longitude)*1000 >= dirDepartM {
+			brg := bearingDeg(t.stopLat, t.stopLon, d.Latitude, d.Longitude)
+			if courseDiff(brg, t.course) > dirFlipDeg {
+				t.reverse = !t.reverse
+			}
+			t.departing = false
+		}
+	}
+
+	switch {
+	case reverseGear:
+		t.reverse = true
+	case d.Speed > dirMaxReverseKph:
+		t.reverse = false
+	}
+
+	// Keep the arrival course until departure has been judged
+	if d.Speed > dirCourseKph && !t.departing {
+		t.course = d.Heading
+		t.haveCourse = true
+	}
+	return t.currentLocked()
+}
+
+// current returns the last inferred direction.
+func (t *directionTracker) current() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.currentLocked()
+}
+
+func (t *directionTracker) currentLocked() string {
+	switch {
+	case !t.moving:
+		return dirStopped
+	case t.reverse:
+		return dirReverse
+	default:
+		return dirForward
+	}
+}
+
+// reverseGearEngaged reports whether the RPM/speed ratio matches the
+// configured reverse gear better than any forward gear.
+func reverseGearEngaged(e *ecu.DataFrame, speedKph float64, dt DrivetrainConfig) bool {
+	if e == nil || dt.ReverseRatio <= 0 || dt.FinalDrive <= 0 || dt.TireCircumM <= 0 {
+		return false
+	}
+	actual := overallRatio(e.RPM, speedKph, dt.TireCircumM)
+	if actual == 0 {
+		return false
+	}
+
+	tol := dt.GearTolerance
+	if tol <= 0 {
+		tol = 0.15
+	}
+	errFor := func(ratio float64) float64 {
+		want := ratio * dt.FinalDrive
+		return math.Abs(actual-want) / want
+	}
+	revErr := errFor(dt.ReverseRatio)
+	if revErr >= tol {
+		return false
+	}
+	for _, r := range dt.GearRatios {
+		if r > 0 && errFor(r) < revErr {
+			return false
+		}
+	}
+	return true
+}
+
+// bearingDeg returns the initial great-circle bearing from 1 to 2.
+func bearingDeg(lat1, lon1, lat2, lon2 float64) float64 {
+	p1 := lat1 * math.Pi / 180
+	p2 := lat2 * math.Pi / 180
+	dl := (lon2 - lon1) * math.Pi / 180
+	y := math.Sin(dl) * math.Cos(p2)
+	x := math.Cos(p1)*math.Sin(p2) - math.Sin(p1)*math.Cos(p2)*math.Cos(dl)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// courseDiff returns the absolute difference between two bearings (0..180).
+func courseDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// ecuByName returns the primary ECU ("" or "ecu") or a namespaced extra.
+func (s *Server) ecuByName(name string) ecu.Provider {
+	if name == "" || name == "ecu" {
+		return s.ecuProv
+	}
+	for _, x := range s.extraECUs {
+		if x.name == name {
+			return x.prov
+		}
+	}
+	return nil
+}
+
+// handleECUCommand runs an allowlisted diagnostic command on an ECU.
+//
+//	GET  /api/ecu/command[?ecu=name] — commands available on that ECU
+//	POST /api/ecu/command            — {"command": "version", "ecu": "name"}
+//
+// Commands: version ('Q'), signature ('S'), loops ('c') and reset, which
+// re-opens and re-handshakes the serial link. Nothing is written to the ECU.
+func (s *Server) handleECUCommand(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Command string `json:"command"`
+		ECU     string `json:"ecu"`
+	}
+	switch r.Method {
+	case http.MethodGet:
+		req.ECU = r.URL.Query().Get("ecu")
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+
+	prov := s.ecuByName(req.ECU)
+	if prov == nil {
+		http.Error(w, "no such ecu: "+req.ECU, 404)
+		return
+	}
+	cmdr, ok := prov.(ecu.Commander)
+	if !ok {
+		http.Error(w, prov.Name()+" does not support diagnostic commands", 501)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(map[string][]string{"commands": cmdr.Commands()})
+		return
+	}
+
+	res, err := cmdr.Command(r.Context(), req.Command)
+	if err != nil {
+		code := 502
+		if errors.Is(err, ecu.ErrUnknownCommand) {
+			code = 400
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	log.Printf("[ecu] command %s: %s", req.Command, res.Text)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// ecuPollTimeout bounds one poll's serial exchange end to end. A poll is
+// a command and up to two block reads, each allowed the INI's 2 s
+// blockReadTimeout; past this the exchange is aborted and the link
+// reconnected rather than stalling the loop for both.
+const ecuPollTimeout = 2500 * time.Millisecond
+
+// ecuSleepPoll is the poll interval while the dash sleeps with the engine
+// off: slow enough to leave the ECU alone, fast enough that starting the
+// engine (RPM > 0) wakes it without GPS or a tap.
+const ecuSleepPoll = time.Second
+
+// extraECU is an additional ECU provider whose frames are broadcast under
+// Frame.ECUs[name] alongside the primary ECU.
+type extraECU struct {
+	name   string
+	prov   ecu.Provider
+	pollHz int
+	prune  *channelPruner
+}
+
+// AddECU registers an additional ECU provider (e.g. a wideband controller or
+// a second Speeduino running a transmission). Must be called before Run.
+func (s *Server) AddECU(name string, prov ecu.Provider, pollHz int) {
+	if pollHz <= 0 {
+		pollHz = 20
+	}
+	x := extraECU{name: name, prov: prov, pollHz: pollHz}
+	for _, xc := range s.cfg.ExtraECUs {
+		if xc.Name == name {
+			x.prune = newChannelPruner("ecu:"+name, xc.ECUConfig)
+		}
+	}
+	s.extraECUs = append(s.extraECUs, x)
+}
+
+// channelPruner drops the channels a provider never populates from its
+// frames, plus any the config names (e.g. VVT2 on an engine without it).
+// A nil pruner leaves frames whole.
+type channelPruner struct {
+	omit ecu.ChannelMask
+}
+
+// newChannelPruner returns a pruner for cfg, or nil if pruning is off.
+func newChannelPruner(tag string, cfg ECUConfig) *channelPruner {
+	if !cfg.PruneChannels {
+		return nil
+	}
+	omit, unknown := ecu.MaskOf(cfg.OmitChannels...)
+	if len(unknown) > 0 {
+		log.Printf("[%s] omit_channels: unknown channel(s) %s", tag, strings.Join(unknown, ", "))
+	}
+	return &channelPruner{omit: omit}
+}
+
+// apply limits f to the channels prov reports for raw, minus the omitted ones.
+func (p *channelPruner) apply(prov ecu.Provider, raw *ecu.RawData, f *ecu.DataFrame) {
+	if p == nil || f == nil {
+		return
+	}
+	var supported ecu.ChannelMask
+	if r, ok := prov.(ecu.ChannelReporter); ok {
+		supported = r.Channels(raw)
+	}
+	f.Channels = supported.Without(p.omit)
+}
+
+// runECUPipeline starts the serial and parser goroutines for one ECU provider,
+// pushing parsed frames to ecuCh. It returns immediately; both goroutines
+// exit when ctx is cancelled. tag is used as the log prefix ("ecu" for the primary, "ecu:<name>" for extras).
+func (s *Server) runECUPipeline(ctx context.Context, tag string, prov ecu.Provider, hz int, prune *channelPruner, ecuCh chan *ecu.DataFrame) {
+	// 3-stage async pipeline:
+	//   Serial goroutine → rawCh (*RawData) → Parser goroutine → ecuCh (*DataFrame) → Broadcast
+	//
+	// The serial goroutine only does wire I/O (send command → read bytes).
+	// Parsing happens async in a separate goroutine so the serial thread
+	// can immediately loop back for the next poll cycle.
+	rawCh := make(chan *ecu.RawData, 2) // serial → parser
+	timing := s.loops.add(tag, hz)
+
+	// Stage 1: Serial I/O goroutine — owns the serial port exclusively.
+	// Does ONLY wire I/O: send poll command → read raw bytes → push to rawCh.
+	// No parsing, no CPU work — gets back to the serial port ASAP.
+	go func() {
+		var (
+			lastErrLog     time.Time
+			consecErrors   int
+			reconnectDelay = 2 * time.Second
+			maxReconnDelay = 30 * time.Second
+			pollInterval   = time.Second / time.Duration(hz)
+		)
+		const maxConsecErrors = 10
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			if prov == nil {
+				timing.pause()
+				time.Sleep(pollInterval)
+				continue
+			}
+
+			// Engine off long enough to sleep: poll slowly, untimed
+			interval := pollInterval
+			asleep := s.quiet.asleep()
+			if asleep {
+				interval = ecuSleepPoll
+			}
+
+			// Reconnection — blocks here until connected
+			if !prov.IsConnected() {
+				timing.pause()
+				if time.Since(lastErrLog) > reconnectDelay {
+					log.Printf("[%s] attempting reconnection...", tag)
+					if err := prov.Connect(ctx); err != nil {
+						if ctx.Err() != nil {
+							return
+						}
+						log.Printf("[%s] reconnect failed: %v (retry in %v)", tag, err, reconnectDelay)
+						lastErrLog = time.Now()
+						reconnectDelay *= 2
+						if reconnectDelay > maxReconnDelay {
+							reconnectDelay = maxReconnDelay
+						}
+					} else {
+						log.Printf("[%s] reconnected successfully", tag)
+						consecErrors = 0
+						reconnectDelay = 2 * time.Second
+					}
+				}
+				time.Sleep(interval)
+				continue
+			}
+
+			// Serial I/O only — send command, read raw bytes
+			if asleep {
+				timing.pause()
+			} else {
+				timing.tick(time.Now())
+			}
+			pollCtx, cancel := context.WithTimeout(ctx, ecuPollTimeout)
+			raw, err := prov.RequestRawData(pollCtx)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				consecErrors = 0
+				// Non-blocking send to parser
+				select {
+				case rawCh <- raw:
+				default:
+					select {
+					case <-rawCh:
+					default:
+					}
+					rawCh <- raw
+				}
+			} else {
+				consecErrors++
+				if time.Since(lastErrLog) > 5*time.Second {
+					log.Printf("[%s] poll error (%d consecutive): %v", tag, consecErrors, err)
+					lastErrLog = time.Now()
+				}
+				if consecErrors >= maxConsecErrors {
+					log.Printf("[%s] %d consecutive errors, closing for reconnect", tag, consecErrors)
+					prov.Close()
+					consecErrors = 0
+					reconnectDelay = 2 * time.Second
+				}
+			}
+
+			time.Sleep(interval)
+		}
+	}()
+
+	// Stage 2: Parser goroutine — CPU-only, no I/O.
+	// Reads raw bytes from rawCh, parses into DataFrames, pushes to ecuCh.
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case raw := <-rawCh:
+				if prov == nil {
+					continue
+				}
+				frame := prov.ParseRawData(raw)
+				prune.apply(prov, raw, frame)
+				// Non-blocking send to broadcast
+				select {
+				case ecuCh <- frame:
+				default:
+					select {
+					case <-ecuCh:
+					default:
+					}
+					ecuCh <- frame
+				}
+			}
+		}
+	}()
+}
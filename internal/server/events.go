@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// handleEvents streams the frames /ws sends as Server-Sent Events, one
+// "data:" line of JSON each, for clients behind proxies or in embedded
+// browsers that mishandle WebSockets. Clients share the WebSocket
+// clients' queueing and rate stepping, so a slow one gets the newest
+// frames at a lower rate rather than a backlog.
+//
+//	GET /events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	sub, err := subscriptionQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+	// Reconnect quickly after a drop; EventSource's default is 3 s
+	fmt.Fprint(w, "retry: 1000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	client := newFrameClient("sse", r.RemoteAddr, func(data []byte) error {
+		rc.SetWriteDeadline(time.Now().Add(clientWriteTimeout))
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+	client.delta, _ = strconv.ParseBool(r.URL.Query().Get("delta"))
+	client.sub = sub
+	s.addClient(client)
+	defer s.removeClient(client)
+
+	go func() {
+		select {
+		case <-r.Context().Done():
+			client.close()
+		case <-client.done:
+		}
+	}()
+	client.writeLoop()
+}
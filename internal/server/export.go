@@ -0,0 +1,317 @@
+package server
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/breadcrumb"
+	"github.com/shaunagostinho/speeduino-dash/internal/logger"
+	"github.com/shaunagostinho/speeduino-dash/internal/mlg"
+)
+
+// exportEvent is something that happened during a session, merged into
+// its exported data.
+type exportEvent struct {
+	At   int64  `json:"at"`   // Unix ms
+	Kind string `json:"kind"` // "alert", "knock" or "lap"
+	Text string `json:"text"`
+}
+
+// exportUnits are the units of log columns, by name suffix.
+var exportUnits = []struct{ suffix, units string }{
+	{"_kpa", "kPa"}, {"_pct", "%"}, {"_c", "C"}, {"_deg", "deg"}, {"_v", "V"},
+	{"_ms", "ms"}, {"_kph", "km/h"}, {"_psi", "psi"}, {"_m", "m"}, {"_g", "G"},
+	{"_dps", "deg/s"}, {"rpm", "rpm"},
+}
+
+// handleExport streams a whole session in one file: the data log rows
+// (ECU, GPS and derived channels) logged during it, with the alerts,
+// knock events and laps merged in by time.
+//
+//	GET /api/export?session=20260102-150405&format=csv|json|mlg
+//
+// csv has an event column, filled on the first row at or after each
+// event. json has the rows as arrays under columns (null = not logged),
+// then the events and the GPS track. mlg is MegaLogViewer's binary log,
+// with events as markers and slow columns held between samples.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	id, format := r.URL.Query().Get("session"), r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" && format != "mlg" {
+		http.Error(w, "format must be csv, json or mlg", 400)
+		return
+	}
+	if !validSessionID(id) || !s.store.Exists(sessionFile(id)) {
+		http.Error(w, "session not found", 404)
+		return
+	}
+
+	f, err := os.Open(s.store.Path(sessionFile(id)))
+	if err != nil {
+		http.Error(w, "session not found", 404)
+		return
+	}
+	track, err := breadcrumb.Read(f)
+	f.Close()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	start, _ := time.ParseInLocation(sessionIDLayout, id, time.Local)
+	end := time.Now()
+	s.session.mu.Lock()
+	active := s.session.id == id
+	s.session.mu.Unlock()
+	if !active && len(track) > 0 {
+		end = time.UnixMilli(track[len(track)-1].Time)
+	}
+
+	span, err := s.logger.Span(start, end)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	events := s.sessionEvents(start, end)
+
+	base := id
+	if v := s.sessionVehicle(id); v != nil && v.Name != "" {
+		base = slug(v.Name) + "_" + id
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+base+"."+format+`"`)
+	bw := bufio.NewWriter(w)
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		err = exportCSV(bw, span, events)
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		err = exportJSON(bw, id, start, end, span, events, track)
+	case "mlg":
+		w.Header().Set("Content-Type", "application/octet-stream")
+		info := "goefidash session " + id
+		if v := s.sessionVehicle(id); v != nil && v.Name != "" {
+			info += ", " + v.Name
+		}
+		err = exportMLG(bw, start, info, span, events)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		// Headers are gone; the short body tells the client it failed
+		log.Printf("[export] session %s as %s: %v", id, format, err)
+	}
+}
+
+// sessionEvents returns the alerts, knock events and completed laps
+// between start and end, in time order.
+func (s *Server) sessionEvents(start, end time.Time) []exportEvent {
+	from, to := start.UnixMilli(), end.UnixMilli()
+	in := func(at int64) bool { return at >= from && at <= to }
+	var out []exportEvent
+
+	s.alertMu.Lock()
+	for _, ev := range s.alertLog {
+		if in(ev.At) {
+			text := ev.Text
+			if ev.Event == "end" {
+				text += " ended"
+			}
+			out = append(out, exportEvent{At: ev.At, Kind: "alert", Text: text})
+		}
+	}
+	s.alertMu.Unlock()
+
+	s.knockLog.mu.Lock()
+	for _, ev := range s.knockLog.events {
+		if in(ev.At) {
+			out = append(out, exportEvent{At: ev.At, Kind: "knock",
+				Text: fmt.Sprintf("knock x%d, %d deg retard at %d rpm %d kPa", ev.Count, ev.Retard, ev.RPM, ev.MAP)})
+		}
+	}
+	s.knockLog.mu.Unlock()
+
+	for _, l := range s.laps.Laps() {
+		if at := l.Start + l.TimeMs; in(at) {
+			d := time.Duration(l.TimeMs) * time.Millisecond
+			out = append(out, exportEvent{At: at, Kind: "lap",
+				Text: fmt.Sprintf("lap %d %d:%06.3f", l.Number, int(d.Minutes()), math.Mod(d.Seconds(), 60))})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At < out[j].At })
+	return out
+}
+
+// eventsUntil returns the events at or before t from the front of
+// *events, removing them.
+func eventsUntil(events *[]exportEvent, t time.Time) []exportEvent {
+	n := sort.Search(len(*events), func(i int) bool { return (*events)[i].At > t.UnixMilli() })
+	due := (*events)[:n]
+	*events = (*events)[n:]
+	return due
+}
+
+func eventText(events []exportEvent) string {
+	texts := make([]string, len(events))
+	for i, ev := range events {
+		texts[i] = ev.Kind + ": " + ev.Text
+	}
+	return strings.Join(texts, "; ")
+}
+
+func exportCSV(w io.Writer, span *logger.Span, events []exportEvent) error {
+	cw := csv.NewWriter(w)
+	header := append(append([]string{"timestamp"}, span.Columns...), "event")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	rec := make([]string, len(header))
+	err := span.Each(func(t time.Time, row []string) error {
+		rec[0] = t.UTC().Format(time.RFC3339Nano) // As in the data logs
+		copy(rec[1:], row)
+		rec[len(rec)-1] = eventText(eventsUntil(&events, t))
+		return cw.Write(rec)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Events after the last row get rows of their own
+	clear(rec)
+	for _, ev := range events {
+		rec[0] = time.UnixMilli(ev.At).UTC().Format(time.RFC3339Nano)
+		rec[len(rec)-1] = eventText([]exportEvent{ev})
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportJSON(w io.Writer, id string, start, end time.Time, span *logger.Span, events []exportEvent, track []breadcrumb.Point) error {
+	head, _ := json.Marshal(struct {
+		Session string   `json:"session"`
+		Start   int64    `json:"start"` // Unix ms
+		End     int64    `json:"end"`
+		Columns []string `json:"columns"` // After "t", each row's Unix ms
+	}{id, start.UnixMilli(), end.UnixMilli(), append([]string{"t"}, span.Columns...)})
+	io.WriteString(w, string(head[:len(head)-1])+`,"rows":[`)
+
+	enc := json.NewEncoder(w)
+	vals := make([]any, 1+len(span.Columns))
+	first := true
+	err := span.Each(func(t time.Time, row []string) error {
+		vals[0] = t.UnixMilli()
+		for i, v := range row {
+			vals[i+1] = jsonValue(v)
+		}
+		if !first {
+			io.WriteString(w, ",")
+		}
+		first = false
+		return enc.Encode(vals)
+	})
+	if err != nil {
+		return err
+	}
+
+	type point struct {
+		T     int64   `json:"t"`
+		Lat   float64 `json:"lat"`
+		Lon   float64 `json:"lon"`
+		Alt   float64 `json:"alt"`
+		Speed float64 `json:"speed"`
+	}
+	pts := make([]point, len(track))
+	for i, p := range track {
+		pts[i] = point{p.Time, p.Lat, p.Lon, p.Alt, p.Speed}
+	}
+	if events == nil {
+		events = []exportEvent{}
+	}
+	tail, err := json.Marshal(struct {
+		Events []exportEvent `json:"events"`
+		Track  []point       `json:"track"`
+	}{events, pts})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "],"+string(tail[1:])+"\n")
+	return err
+}
+
+// jsonValue returns a log cell as a number, or null when empty.
+func jsonValue(v string) any {
+	if v == "" {
+		return nil
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return json.Number(v)
+	}
+	return v
+}
+
+func exportMLG(w io.Writer, start time.Time, info string, span *logger.Span, events []exportEvent) error {
+	fields := []mlg.Field{{Name: "Time", Units: "s", Digits: 3}}
+	for _, c := range span.Columns {
+		f := mlg.Field{Name: c}
+		for _, u := range exportUnits {
+			if strings.HasSuffix(c, u.suffix) {
+				f.Units = u.units
+				break
+			}
+		}
+		fields = append(fields, f)
+	}
+	mw, err := mlg.NewWriter(w, start, fields, info)
+	if err != nil {
+		return err
+	}
+	marker := func(ev exportEvent) error {
+		return mw.Marker(time.UnixMilli(ev.At), ev.Kind+": "+ev.Text)
+	}
+
+	vals := make([]float32, len(fields)) // Held from row to row
+	err = span.Each(func(t time.Time, row []string) error {
+		for _, ev := range eventsUntil(&events, t) {
+			if err := marker(ev); err != nil {
+				return err
+			}
+		}
+		vals[0] = float32(t.Sub(start).Seconds())
+		for i, v := range row {
+			if f, err := strconv.ParseFloat(v, 32); err == nil {
+				vals[i+1] = float32(f)
+			}
+		}
+		return mw.Record(t, vals)
+	})
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		if err := marker(ev); err != nil {
+			return err
+		}
+	}
+	return mw.Flush()
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/logger"
+)
+
+func TestExportCSVEvents(t *testing.T) {
+	dir := t.TempDir()
+	log := "timestamp,rpm,map_kpa\n" +
+		"2026-10-16T20:00:00+10:00,900,30\n" + // Logged in local time
+		"2026-10-16T20:00:01+10:00,950,32\n"
+	if err := os.WriteFile(filepath.Join(dir, "ecu.csv"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l := logger.New(logger.Config{Path: dir})
+	defer l.Close()
+
+	// Session times come from local session IDs; the export is UTC throughout
+	zone := time.FixedZone("UTC+10", 10*3600)
+	start := time.Date(2026, 10, 16, 20, 0, 0, 0, zone)
+	span, err := l.Span(start, start.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := start.UnixMilli()
+	events := []exportEvent{
+		{At: t0 + 500, Kind: "alert", Text: "CLT"},  // Between the samples
+		{At: t0 + 2000, Kind: "lap", Text: "lap 1"}, // After the last
+	}
+	var buf bytes.Buffer
+	if err := exportCSV(&buf, span, events); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"timestamp", "rpm", "map_kpa", "event"},
+		{"2026-10-16T10:00:00Z", "900", "30", ""},
+		{"2026-10-16T10:00:01Z", "950", "32", "alert: CLT"},
+		{"2026-10-16T10:00:02Z", "", "", "lap: lap 1"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%s", len(rows), len(want), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("row %d column %d = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// fanoutWrite bounds a write to a feed client; one that can't keep up is
+// dropped rather than holding up the others.
+const fanoutWrite = time.Second
+
+// tcpFanout sends the same stream to every client of a TCP listener, for
+// feeds that apps connect to.
+type tcpFanout struct {
+	name    string // Log prefix
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+}
+
+// listenFanout listens on addr until ctx ends, when the listener and
+// every client are closed.
+func listenFanout(ctx context.Context, name, addr string) (*tcpFanout, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	f := &tcpFanout{name: name, clients: make(map[net.Conn]struct{})}
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		f.mu.Lock()
+		for c := range f.clients {
+			c.Close()
+		}
+		f.mu.Unlock()
+	}()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			log.Printf("[%s] %s connected", name, c.RemoteAddr())
+			f.mu.Lock()
+			f.clients[c] = struct{}{}
+			f.mu.Unlock()
+			go io.Copy(io.Discard, c) // Whatever the app sends is ignored
+		}
+	}()
+	return f, nil
+}
+
+// send writes b to every client, dropping those that fail.
+func (f *tcpFanout) send(b []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for c := range f.clients {
+		c.SetWriteDeadline(time.Now().Add(fanoutWrite))
+		if _, err := c.Write(b); err != nil {
+			log.Printf("[%s] %s gone: %v", f.name, c.RemoteAddr(), err)
+			c.Close()
+			delete(f.clients, c)
+		}
+	}
+}
+
+// idle reports whether no client is connected.
+func (f *tcpFanout) idle() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.clients) == 0
+}
@@ -0,0 +1,33 @@
+package server
+
+import "sync"
+
+// frameFeed holds the latest data frame for consumers that pace
+// themselves, waking them when the next one is published. Published
+// frames must not be changed afterwards.
+type frameFeed struct {
+	mu    sync.Mutex
+	frame *Frame
+	next  chan struct{} // Closed on the next publish
+}
+
+func (f *frameFeed) publish(frame *Frame) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frame = frame
+	if f.next != nil {
+		close(f.next)
+		f.next = nil
+	}
+}
+
+// latest returns the latest frame, nil before the first, and a channel
+// closed when the next is published.
+func (f *frameFeed) latest() (*Frame, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.next == nil {
+		f.next = make(chan struct{})
+	}
+	return f.frame, f.next
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// fuelFile is the fuel used since the last fill-up inside the data
+// directory.
+const fuelFile = storage.DirState + "/fuel.json"
+
+const (
+	fuelFlowTau  = time.Second // Smoothing of the instantaneous flow
+	fuelMaxTick  = time.Second // Longer gaps (ECU dropouts) aren't counted
+	fuelMinAvgKm = 5.0         // Distance since a fill-up before its average (and range) is shown
+	lPer100ToMPG = 235.215     // US MPG = this / (L/100 km)
+)
+
+// FuelData is fuel consumption sent to clients. Averages and range are
+// since the last full fill-up.
+type FuelData struct {
+	FlowLPH    float64 `json:"flowLph"`              // Now, L/h
+	L100km     float64 `json:"l100km,omitempty"`     // Now, 0 when stopped
+	MPG        float64 `json:"mpg,omitempty"`        // Now, US gallons
+	AvgL100km  float64 `json:"avgL100km,omitempty"`  // 0 until fuelMinAvgKm driven
+	AvgMPG     float64 `json:"avgMpg,omitempty"`     // US gallons
+	UsedL      float64 `json:"usedL"`                // Since the last full fill-up
+	TankL      float64 `json:"tankL,omitempty"`      // Configured capacity
+	RemainingL float64 `json:"remainingL,omitempty"` // In the tank, with tankL
+	RangeKm    float64 `json:"rangeKm,omitempty"`    // At the average, with tankL
+}
+
+// fuelFlowLPH estimates the fuel flow in L/h from the injector pulse
+// width, or 0 without the injector flow.
+func fuelFlowLPH(e *ecu.DataFrame, f FuelConfig) float64 {
+	if e == nil || e.RPM == 0 || f.InjectorCCMin <= 0 || f.Injectors <= 0 {
+		return 0
+	}
+	squirts := max(f.Squirts, 1)
+	cycleMs := 120000 / float64(e.RPM) // Two revs
+	open := math.Max(e.PulseWidth1-f.OpenTimeMs, 0)
+	duty := math.Min(open*float64(squirts)/cycleMs, 1)
+	// cc/min → L/h
+	return float64(f.Injectors) * f.InjectorCCMin * duty * 60 / 1000
+}
+
+// fuelTracker integrates fuel used.
+type fuelTracker struct {
+	mu    sync.Mutex
+	last  time.Time
+	flow  float64 // Smoothed L/h
+	st    fuelState
+	dirty bool // Changed since last saved
+}
+
+// fuelState is the persisted part of fuelTracker.
+type fuelState struct {
+	UsedL     float64 `json:"usedL"`     // Since the last full fill-up, for the average
+	TankUsedL float64 `json:"tankUsedL"` // Out of the tank, less partial fill-ups
+	FillOdoKm float64 `json:"fillOdoKm"` // Odometer at the last full fill-up
+	Filled    int64   `json:"filled"`    // Unix ms of the last full fill-up, 0 = never
+}
+
+// update adds one broadcast tick at flowLPH.
+func (t *fuelTracker) update(now time.Time, e *ecu.DataFrame, flowLPH float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e == nil || !e.Running {
+		t.last, t.flow = time.Time{}, 0
+		return
+	}
+	prev := t.last
+	t.last = now
+	dt := now.Sub(prev)
+	if prev.IsZero() || dt <= 0 || dt > fuelMaxTick {
+		t.flow = flowLPH
+		return
+	}
+	t.flow += (flowLPH - t.flow) * (1 - math.Exp(-dt.Seconds()/fuelFlowTau.Seconds()))
+	if used := flowLPH * dt.Hours(); used > 0 {
+		t.st.UsedL += used
+		t.st.TankUsedL += used
+		t.dirty = true
+	}
+}
+
+// fuelData returns consumption for a frame, or nil without the injector
+// flow configured. speed is km/h.
+func (s *Server) fuelData(speed float64) *FuelData {
+	f := s.cfg.FuelSnapshot()
+	if f.InjectorCCMin <= 0 {
+		return nil
+	}
+	s.odoMu.Lock()
+	total := s.odoTotal
+	s.odoMu.Unlock()
+
+	t := &s.fuel
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d := &FuelData{
+		FlowLPH: math.Round(t.flow*100) / 100,
+		UsedL:   math.Round(t.st.UsedL*100) / 100,
+		TankL:   f.TankL,
+	}
+	if speed >= tripMovingKph {
+		l100 := t.flow / speed * 100
+		d.L100km = math.Round(l100*10) / 10
+		if l100 > 0 {
+			d.MPG = math.Round(lPer100ToMPG/l100*10) / 10
+		}
+	}
+	avg := 0.0
+	if km := total - t.st.FillOdoKm; km >= fuelMinAvgKm && t.st.UsedL > 0 {
+		avg = t.st.UsedL / km * 100
+		d.AvgL100km = math.Round(avg*10) / 10
+		d.AvgMPG = math.Round(lPer100ToMPG/avg*10) / 10
+	}
+	if f.TankL > 0 {
+		left := math.Max(f.TankL-t.st.TankUsedL, 0)
+		d.RemainingL = math.Round(left*10) / 10
+		if avg > 0 {
+			d.RangeKm = math.Round(left / avg * 100)
+		}
+	}
+	return d
+}
+
+// fillUp records a fill-up: liters added, or a full tank with liters 0.
+func (s *Server) fillUp(liters float64) {
+	s.odoMu.Lock()
+	total := s.odoTotal
+	s.odoMu.Unlock()
+
+	t := &s.fuel
+	t.mu.Lock()
+	if liters > 0 {
+		t.st.TankUsedL = math.Max(t.st.TankUsedL-liters, 0)
+	} else {
+		t.st = fuelState{FillOdoKm: total, Filled: time.Now().UnixMilli()}
+	}
+	t.dirty = true
+	t.mu.Unlock()
+	s.saveFuel()
+}
+
+// loadFuel restores the fuel used since the last fill-up from disk. The
+// first run starts as if just filled up.
+func (s *Server) loadFuel() {
+	var st fuelState
+	if err := s.store.ReadJSON(fuelFile, &st); err != nil {
+		s.fuel.st.FillOdoKm = s.odoTotal
+		return
+	}
+	s.fuel.mu.Lock()
+	s.fuel.st = st
+	s.fuel.mu.Unlock()
+}
+
+// saveFuel persists the fuel used, if it has changed.
+func (s *Server) saveFuel() {
+	t := &s.fuel
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return
+	}
+	t.dirty = false
+	st := t.st
+	t.mu.Unlock()
+	if err := s.store.WriteJSON(fuelFile, st); err != nil {
+		log.Printf("[fuel] save failed: %v", err)
+	}
+}
+
+// handleFuel serves fuel consumption and records fill-ups.
+//
+//	GET  /api/fuel                   — consumption since the last fill-up
+//	POST /api/fuel/fillup[?liters=N] — filled to full, or added N liters
+func (s *Server) handleFuel(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/api/fuel" && r.Method == http.MethodGet:
+		d := s.fuelData(0)
+		if d == nil {
+			http.Error(w, "set fuel.injector_cc_min first", 404)
+			return
+		}
+		s.fuel.mu.Lock()
+		filled := s.fuel.st.Filled
+		s.fuel.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			*FuelData
+			Filled int64 `json:"filled"` // Unix ms of the last full fill-up, 0 = never
+		}{d, filled})
+
+	case r.URL.Path == "/api/fuel/fillup" && r.Method == http.MethodPost:
+		var liters float64
+		if v := r.URL.Query().Get("liters"); v != "" {
+			l, err := strconv.ParseFloat(v, 64)
+			if err != nil || l <= 0 {
+				http.Error(w, "liters must be a positive number", 400)
+				return
+			}
+			liters = l
+		}
+		s.fillUp(liters)
+		if liters > 0 {
+			log.Printf("[fuel] fill-up: %.1f L added", liters)
+		} else {
+			log.Printf("[fuel] fill-up: full tank")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	case r.URL.Path == "/api/fuel" || r.URL.Path == "/api/fuel/fillup":
+		http.Error(w, "method not allowed", 405)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
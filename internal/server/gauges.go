@@ -0,0 +1,376 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.bug.st/serial"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gauge"
+)
+
+const (
+	gaugeTimeout = 10 * time.Second      // UDP gauges not heard from this long are dropped
+	gaugeTick    = 10 * time.Millisecond // Sender resolution
+)
+
+// gaugeInput is what gauges are fed from, set on every broadcast tick.
+type gaugeInput struct {
+	ecu   *ecu.DataFrame // nil while stale
+	speed float64        // Fused km/h
+	gps   bool           // GPS has a fix
+}
+
+// value returns a channel for the tick: "speed", a DataFrame JSON name or
+// an aux channel. ECU channels have none while the data is stale.
+func (in *gaugeInput) value(name string) (float64, bool) {
+	if name == "speed" {
+		return in.speed, true
+	}
+	if in.ecu == nil {
+		return 0, false
+	}
+	return in.ecu.Value(name)
+}
+
+// gaugeHub tracks the registered gauges and feeds them.
+type gaugeHub struct {
+	latest atomic.Pointer[gaugeInput]
+
+	mu      sync.Mutex
+	devices map[string]*gaugeDevice // By transport address
+}
+
+// gaugeDevice is a registered gauge.
+type gaugeDevice struct {
+	id       string
+	addr     string // e.g. "udp:10.0.0.5:5606" or the serial port
+	channels []string
+	known    []bool
+	rateHz   int
+	serial   bool // Stays registered while the port is open
+	heard    time.Time
+	next     time.Time
+	seq      uint16
+	send     func([]byte) error
+}
+
+// GaugeStatus is a registered gauge, for /api/gauges.
+type GaugeStatus struct {
+	ID       string   `json:"id"`
+	Addr     string   `json:"addr"`
+	Channels []string `json:"channels"`
+	Unknown  []string `json:"unknown,omitempty"` // Channels sent as NaN
+	RateHz   int      `json:"rateHz"`
+	Heard    int64    `json:"heard"` // Unix ms of the last packet from it
+}
+
+// setGaugeInput records the tick's data for the gauge sender.
+func (s *Server) setGaugeInput(e *ecu.DataFrame, speed float64, gpsFix bool) {
+	s.gauges.latest.Store(&gaugeInput{ecu: e, speed: speed, gps: gpsFix})
+}
+
+// runGauges listens for gauges on UDP and the configured serial ports and
+// sends each its channels at its rate.
+func (s *Server) runGauges(ctx context.Context) {
+	cfg := s.cfg.GaugesSnapshot()
+	if cfg.UDPListen != "" {
+		conn, err := net.ListenPacket("udp", cfg.UDPListen)
+		if err != nil {
+			log.Printf("[gauges] %v", err)
+		} else {
+			log.Printf("[gauges] listening on udp %s", conn.LocalAddr())
+			go func() {
+				<-ctx.Done()
+				conn.Close()
+			}()
+			go s.readGaugesUDP(conn)
+		}
+	}
+	for _, sc := range cfg.Serial {
+		go s.runGaugeSerial(ctx, sc)
+	}
+
+	ticker := time.NewTicker(gaugeTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sendGauges(now)
+		}
+	}
+}
+
+// readGaugesUDP handles packets from UDP gauges until conn is closed.
+// Each datagram holds one packet.
+func (s *Server) readGaugesUDP(conn net.PacketConn) {
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("[gauges] udp: %v", err)
+			}
+			return
+		}
+		typ, payload, _, err := gauge.Decode(buf[:n])
+		if err != nil || typ == 0 {
+			continue
+		}
+		addr := from
+		s.gaugePacket("udp:"+from.String(), false, typ, payload, func(b []byte) error {
+			_, err := conn.WriteTo(b, addr)
+			return err
+		})
+	}
+}
+
+// runGaugeSerial keeps a serial gauge's port open, reopening it with
+// backoff, and handles its packets.
+func (s *Server) runGaugeSerial(ctx context.Context, sc GaugeSerialConfig) {
+	delay := 2 * time.Second
+	const maxDelay = 30 * time.Second
+	baud := sc.BaudRate
+	if baud <= 0 {
+		baud = 115200
+	}
+	for ctx.Err() == nil {
+		path, err := device.Resolve(sc.Port)
+		var port serial.Port
+		if err == nil {
+			port, err = device.Open(path, &serial.Mode{BaudRate: baud})
+		}
+		if err != nil {
+			log.Printf("[gauges] %s: %v (retry in %v)", sc.Port, err, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxDelay)
+			continue
+		}
+		delay = 2 * time.Second
+		log.Printf("[gauges] opened %s", sc.Port)
+		s.readGaugeSerial(ctx, sc.Port, port)
+		port.Close()
+		s.dropGauge(sc.Port)
+	}
+}
+
+// readGaugeSerial handles packets from port until it fails or ctx ends.
+func (s *Server) readGaugeSerial(ctx context.Context, name string, port serial.Port) {
+	port.SetReadTimeout(500 * time.Millisecond)
+	var wmu sync.Mutex
+	send := func(b []byte) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+		_, err := port.Write(b)
+		return err
+	}
+	var buf []byte
+	chunk := make([]byte, 256)
+	for ctx.Err() == nil {
+		n, err := port.Read(chunk)
+		if err != nil {
+			log.Printf("[gauges] %s: %v", name, err)
+			return
+		}
+		buf = append(buf, chunk[:n]...)
+		for {
+			typ, payload, used, err := gauge.Decode(buf)
+			buf = buf[used:]
+			if err != nil {
+				continue
+			}
+			if typ == 0 {
+				break
+			}
+			s.gaugePacket(name, true, typ, payload, send)
+		}
+		if len(buf) > 4*gauge.MaxPayload {
+			buf = nil // Not a gauge on this port
+		}
+	}
+}
+
+// gaugePacket handles a packet from the gauge at addr.
+func (s *Server) gaugePacket(addr string, isSerial bool, typ byte, payload []byte, send func([]byte) error) {
+	h := &s.gauges
+	now := time.Now()
+	switch typ {
+	case gauge.TypeHello:
+		hello, err := gauge.ParseHello(payload)
+		if err != nil {
+			log.Printf("[gauges] %s: %v", addr, err)
+			return
+		}
+		d := s.newGauge(hello)
+		d.addr, d.serial, d.heard, d.next, d.send = addr, isSerial, now, now, send
+		welcome, _ := gauge.Encode(gauge.TypeWelcome, gauge.WelcomePayload(d.rateHz, d.known))
+		if err := send(welcome); err != nil {
+			log.Printf("[gauges] %s: %v", addr, err)
+			return
+		}
+		h.mu.Lock()
+		if h.devices == nil {
+			h.devices = make(map[string]*gaugeDevice)
+		}
+		_, again := h.devices[addr]
+		h.devices[addr] = d
+		h.mu.Unlock()
+		if !again {
+			log.Printf("[gauges] %q registered at %s: %d channels at %d Hz", d.id, addr, len(d.channels), d.rateHz)
+		}
+
+	case gauge.TypePing:
+		h.mu.Lock()
+		if d := h.devices[addr]; d != nil {
+			d.heard = now
+		}
+		h.mu.Unlock()
+
+	case gauge.TypeBye:
+		s.dropGauge(addr)
+	}
+}
+
+// newGauge works out a registering gauge's channels and rate, with any
+// override from gauges.devices.
+func (s *Server) newGauge(hello gauge.Hello) *gaugeDevice {
+	cfg := s.cfg.GaugesSnapshot()
+	d := &gaugeDevice{id: hello.ID, channels: hello.Channels, rateHz: hello.RateHz}
+	for _, dc := range cfg.Devices {
+		if dc.ID != hello.ID {
+			continue
+		}
+		if len(dc.Channels) > 0 {
+			d.channels = dc.Channels[:min(len(dc.Channels), gauge.MaxChannels)]
+		}
+		if dc.RateHz > 0 {
+			d.rateHz = dc.RateHz
+		}
+	}
+	if d.rateHz <= 0 {
+		d.rateHz = max(cfg.RateHz, 1)
+	}
+	if cfg.MaxRateHz > 0 {
+		d.rateHz = min(d.rateHz, cfg.MaxRateHz)
+	}
+	var aux map[string]float64
+	if in := s.gauges.latest.Load(); in != nil && in.ecu != nil {
+		aux = in.ecu.Aux
+	}
+	d.known = make([]bool, len(d.channels))
+	for i, c := range d.channels {
+		_, unknown := ecu.MaskOf(c)
+		_, isAux := aux[c]
+		d.known[i] = c == "speed" || len(unknown) == 0 || isAux
+	}
+	return d
+}
+
+// dropGauge forgets the gauge at addr.
+func (s *Server) dropGauge(addr string) {
+	h := &s.gauges
+	h.mu.Lock()
+	d := h.devices[addr]
+	delete(h.devices, addr)
+	h.mu.Unlock()
+	if d != nil {
+		log.Printf("[gauges] %q at %s gone", d.id, addr)
+	}
+}
+
+// sendGauges sends Data to every gauge that is due.
+func (s *Server) sendGauges(now time.Time) {
+	in := s.gauges.latest.Load()
+	if in == nil {
+		in = &gaugeInput{}
+	}
+	var flags byte
+	if in.ecu != nil {
+		flags |= gauge.FlagECU
+	}
+	if in.gps {
+		flags |= gauge.FlagGPS
+	}
+
+	h := &s.gauges
+	h.mu.Lock()
+	var due []*gaugeDevice
+	var packets [][]byte
+	for addr, d := range h.devices {
+		if !d.serial && now.Sub(d.heard) > gaugeTimeout {
+			delete(h.devices, addr)
+			log.Printf("[gauges] %q at %s timed out", d.id, addr)
+			continue
+		}
+		if now.Before(d.next) {
+			continue
+		}
+		interval := time.Second / time.Duration(d.rateHz)
+		d.next = d.next.Add(interval)
+		if d.next.Before(now) {
+			d.next = now.Add(interval) // Fell behind; don't burst
+		}
+		values := make([]float64, len(d.channels))
+		for i, c := range d.channels {
+			values[i] = math.NaN()
+			if v, ok := in.value(c); ok && d.known[i] {
+				values[i] = v
+			}
+		}
+		pkt, err := gauge.Encode(gauge.TypeData, gauge.DataPayload(d.seq, flags, values))
+		if err != nil {
+			continue
+		}
+		d.seq++
+		due = append(due, d)
+		packets = append(packets, pkt)
+	}
+	h.mu.Unlock()
+
+	for i, d := range due {
+		d.send(packets[i]) // UDP drops silently; serial errors end its read loop
+	}
+}
+
+// handleGauges lists the registered gauges.
+//
+//	GET /api/gauges
+func (s *Server) handleGauges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	h := &s.gauges
+	h.mu.Lock()
+	out := make([]GaugeStatus, 0, len(h.devices))
+	for _, d := range h.devices {
+		st := GaugeStatus{ID: d.id, Addr: d.addr, Channels: d.channels, RateHz: d.rateHz, Heard: d.heard.UnixMilli()}
+		for i, c := range d.channels {
+			if !d.known[i] {
+				st.Unknown = append(st.Unknown, c)
+			}
+		}
+		out = append(out, st)
+	}
+	h.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
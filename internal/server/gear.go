@@ -0,0 +1,57 @@
+package server
+
+import (
+	"math"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+const (
+	gearMinKph = 5   // Slower, or below gearMinRPM, is neutral
+	gearMinRPM = 500 // Engine not running
+)
+
+// calcGear works out the gear from RPM and speed with the configured
+// ratios, matching the overall ratio to the nearest gear within
+// gear_tolerance (default 0.15). No match (clutch in, coasting, between
+// gears) is 0, neutral. It returns a copy of e with Gear replaced, and
+// false with e unchanged when ratios, final drive or tire size are
+// missing, leaving the ECU's own gear.
+func calcGear(e *ecu.DataFrame, speedKph float64, dt DrivetrainConfig) (*ecu.DataFrame, bool) {
+	if e == nil || len(dt.GearRatios) == 0 || dt.FinalDrive <= 0 || dt.TireCircumM <= 0 {
+		return e, false
+	}
+	tol := dt.GearTolerance
+	if tol <= 0 {
+		tol = 0.15
+	}
+	var gear uint8
+	if actual := overallRatio(e.RPM, speedKph, dt.TireCircumM); actual > 0 {
+		best := tol
+		for i, r := range dt.GearRatios {
+			if r <= 0 {
+				continue
+			}
+			want := r * dt.FinalDrive
+			if err := math.Abs(actual-want) / want; err < best {
+				best, gear = err, uint8(i+1)
+			}
+		}
+	}
+	out := *e
+	out.Gear = gear
+	if !out.Channels.IsZero() {
+		gm, _ := ecu.MaskOf("gear")
+		out.Channels = out.Channels.Union(gm)
+	}
+	return &out, true
+}
+
+// overallRatio is engine RPM over wheel RPM, or 0 when too slow to tell.
+func overallRatio(rpm uint16, speedKph, tireCircumM float64) float64 {
+	if speedKph < gearMinKph || rpm < gearMinRPM || tireCircumM <= 0 {
+		return 0
+	}
+	wheelRPM := speedKph / 3.6 / tireCircumM * 60
+	return float64(rpm) / wheelRPM
+}
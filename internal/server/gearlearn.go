@@ -0,0 +1,294 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// gearLearnFile is the learned ratio histogram inside the data directory,
+// so learning carries on across drives.
+const gearLearnFile = storage.DirState + "/gearlearn.json"
+
+// Overall (engine to wheel) ratios are binned on a log scale, each bin
+// gearLearnStep wider than the last, from gearLearnMinRatio up.
+const (
+	gearLearnMinRatio = 1.5
+	gearLearnStep     = 0.01
+	gearLearnBins     = 300 // Up to ~30:1
+	gearLearnMinKph   = 15.0
+	gearLearnMinRPM   = 1000
+	gearLearnSteady   = 0.02        // Tick-to-tick ratio change allowed while in gear
+	gearLearnHold     = time.Second // Steady this long before samples count
+	gearLearnMaxTick  = time.Second // Longer gaps (data dropouts) aren't counted
+	gearLearnMinSec   = 10.0        // Time in a cluster for it to be a gear
+	gearLearnSpan     = 5           // Bins either side of a peak in its cluster
+	gearLearnSeparate = 8           // Bins either side of a gear no other gear can be in
+	gearLearnMaxGears = 8
+)
+
+// GearLearnStatus is what gear learning has found so far.
+type GearLearnStatus struct {
+	Enabled   bool          `json:"enabled"`
+	Seconds   float64       `json:"seconds"`             // Steady driving sampled
+	Clusters  []GearCluster `json:"clusters"`            // Gears found, 1st first
+	Suggested []float64     `json:"suggested,omitempty"` // Gear ratios to apply, needs final_drive
+	Current   []float64     `json:"current"`             // Configured gear ratios
+}
+
+// GearCluster is one gear found in the driving data.
+type GearCluster struct {
+	Overall   float64 `json:"overall"`   // Engine revs per wheel rev
+	GearRatio float64 `json:"gearRatio"` // Overall / final drive, 0 without one
+	Seconds   float64 `json:"seconds"`   // Time driven in it
+}
+
+// gearLearner histograms the RPM/speed ratio over steady driving. Peaks
+// in the histogram are the gears.
+type gearLearner struct {
+	mu     sync.Mutex
+	bins   []float64 // Seconds per ratio bin
+	last   time.Time
+	ratio  float64   // Previous tick's ratio
+	steady time.Time // When the ratio became steady, zero if it isn't
+	dirty  bool      // Changed since last saved
+}
+
+// update adds one broadcast tick. speed is km/h.
+func (g *gearLearner) update(now time.Time, e *ecu.DataFrame, speed float64, dt DrivetrainConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if e == nil || !dt.LearnGears || dt.TireCircumM <= 0 ||
+		speed < gearLearnMinKph || e.RPM < gearLearnMinRPM || e.DFCOOn {
+		g.last, g.steady, g.ratio = time.Time{}, time.Time{}, 0
+		return
+	}
+	wheelRPM := speed / 3.6 / dt.TireCircumM * 60
+	ratio := float64(e.RPM) / wheelRPM
+
+	prev, prevRatio := g.last, g.ratio
+	g.last, g.ratio = now, ratio
+	tick := now.Sub(prev)
+	if prev.IsZero() || tick <= 0 || tick > gearLearnMaxTick ||
+		math.Abs(ratio-prevRatio)/ratio > gearLearnSteady {
+		// Shifting, clutch slipping or wheelspin
+		g.steady = time.Time{}
+		return
+	}
+	if g.steady.IsZero() {
+		g.steady = now
+	}
+	if now.Sub(g.steady) < gearLearnHold {
+		return
+	}
+	bin := ratioBin(ratio)
+	if bin < 0 {
+		return
+	}
+	if g.bins == nil {
+		g.bins = make([]float64, gearLearnBins)
+	}
+	g.bins[bin] += tick.Seconds()
+	g.dirty = true
+}
+
+// ratioBin returns the histogram bin of an overall ratio, or -1 outside
+// the range.
+func ratioBin(ratio float64) int {
+	if ratio < gearLearnMinRatio {
+		return -1
+	}
+	b := int(math.Log(ratio/gearLearnMinRatio) / math.Log1p(gearLearnStep))
+	if b >= gearLearnBins {
+		return -1
+	}
+	return b
+}
+
+// binRatio returns the overall ratio at the middle of bin b.
+func binRatio(b int) float64 {
+	return gearLearnMinRatio * math.Pow(1+gearLearnStep, float64(b)+0.5)
+}
+
+// clustersLocked finds the gears: the fullest bin and its neighbours are
+// the first gear found, then the fullest bin not near it, and so on,
+// while clusters have at least gearLearnMinSec. 1st gear first.
+func (g *gearLearner) clustersLocked(finalDrive float64) []GearCluster {
+	if g.bins == nil {
+		return nil
+	}
+	used := make([]bool, len(g.bins))
+	var out []GearCluster
+	for len(out) < gearLearnMaxGears {
+		peak := -1
+		for b, secs := range g.bins {
+			if !used[b] && secs > 0 && (peak < 0 || secs > g.bins[peak]) {
+				peak = b
+			}
+		}
+		if peak < 0 {
+			break
+		}
+		var secs, sum float64
+		for b := max(peak-gearLearnSpan, 0); b <= min(peak+gearLearnSpan, len(g.bins)-1); b++ {
+			if !used[b] {
+				secs += g.bins[b]
+				sum += g.bins[b] * binRatio(b)
+			}
+		}
+		for b := max(peak-gearLearnSeparate, 0); b <= min(peak+gearLearnSeparate, len(g.bins)-1); b++ {
+			used[b] = true
+		}
+		if secs < gearLearnMinSec {
+			continue // Too little to be a gear; a rarer peak may still be
+		}
+		c := GearCluster{Overall: math.Round(sum/secs*1000) / 1000, Seconds: math.Round(secs)}
+		if finalDrive > 0 {
+			c.GearRatio = math.Round(c.Overall/finalDrive*1000) / 1000
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Overall > out[j].Overall })
+	return out
+}
+
+// status returns what has been learned, against the drivetrain config.
+func (g *gearLearner) status(dt DrivetrainConfig) *GearLearnStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := &GearLearnStatus{
+		Enabled:  dt.LearnGears,
+		Clusters: g.clustersLocked(dt.FinalDrive),
+		Current:  dt.GearRatios,
+	}
+	for _, secs := range g.bins {
+		st.Seconds += secs
+	}
+	st.Seconds = math.Round(st.Seconds)
+	if st.Clusters == nil {
+		st.Clusters = []GearCluster{}
+	}
+	if st.Current == nil {
+		st.Current = []float64{}
+	}
+	if dt.FinalDrive > 0 {
+		for _, c := range st.Clusters {
+			st.Suggested = append(st.Suggested, c.GearRatio)
+		}
+	}
+	return st
+}
+
+// reset forgets everything learned.
+func (g *gearLearner) reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.bins, g.last, g.steady, g.ratio, g.dirty = nil, time.Time{}, time.Time{}, 0, false
+}
+
+// gearLearnState is the persisted form of gearLearner.
+type gearLearnState struct {
+	Bins []float64 `json:"bins"`
+}
+
+// loadGearLearn restores the ratio histogram from disk.
+func (s *Server) loadGearLearn() {
+	var st gearLearnState
+	if err := s.store.ReadJSON(gearLearnFile, &st); err != nil || len(st.Bins) != gearLearnBins {
+		return
+	}
+	s.gears.mu.Lock()
+	s.gears.bins = st.Bins
+	s.gears.mu.Unlock()
+}
+
+// saveGearLearn persists the ratio histogram, if it has changed.
+func (s *Server) saveGearLearn() {
+	g := &s.gears
+	g.mu.Lock()
+	if !g.dirty || g.bins == nil {
+		g.mu.Unlock()
+		return
+	}
+	g.dirty = false
+	st := gearLearnState{Bins: append([]float64(nil), g.bins...)}
+	g.mu.Unlock()
+	if err := s.store.WriteJSON(gearLearnFile, st); err != nil {
+		log.Printf("[gears] save failed: %v", err)
+	}
+}
+
+// applyGearRatios saves ratios as the configured gear ratios and pushes
+// them to clients.
+func (s *Server) applyGearRatios(ratios []float64) error {
+	patch, err := json.Marshal(map[string]any{"drivetrain": map[string]any{"gearRatios": ratios}})
+	if err != nil {
+		return err
+	}
+	if err := s.cfg.UpdateFromJSON(patch); err != nil {
+		return err
+	}
+	if err := s.cfg.Save(); err != nil {
+		log.Printf("[config] save failed: %v", err)
+	}
+	dt := s.cfg.DrivetrainSnapshot()
+	s.broadcast(Frame{Drivetrain: &dt, Stamp: time.Now().UnixMilli()})
+	log.Printf("[gears] applied learned ratios %v", ratios)
+	return nil
+}
+
+// handleGearLearn shows and applies gear ratios learned from driving.
+// Learning runs with drivetrain.learn_gears; nothing is written to the
+// config until it's applied.
+//
+//	GET    /api/gears/learn        — clusters found and suggested ratios
+//	POST   /api/gears/learn?apply=1 — save the suggested ratios
+//	DELETE /api/gears/learn        — start over
+func (s *Server) handleGearLearn(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.gears.status(s.cfg.DrivetrainSnapshot()))
+
+	case http.MethodPost:
+		if r.URL.Query().Get("apply") != "1" {
+			http.Error(w, "confirm with ?apply=1", 400)
+			return
+		}
+		dt := s.cfg.DrivetrainSnapshot()
+		if dt.FinalDrive <= 0 {
+			http.Error(w, "set drivetrain.final_drive first", 400)
+			return
+		}
+		st := s.gears.status(dt)
+		if len(st.Suggested) < 2 {
+			http.Error(w, fmt.Sprintf("only %d gears found so far; drive more", len(st.Suggested)), 409)
+			return
+		}
+		if err := s.applyGearRatios(st.Suggested); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(st.Suggested)
+
+	case http.MethodDelete:
+		s.gears.reset()
+		if err := s.store.Remove(gearLearnFile); err != nil {
+			log.Printf("[gears] %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,339 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gpio"
+)
+
+const (
+	gpioTick    = 10 * time.Millisecond  // Output refresh and button polling
+	gpioFlash   = 100 * time.Millisecond // Flash half-period
+	gpioRefresh = time.Second            // Strip resent this often even unchanged, in case of a glitch
+)
+
+// gpioInput is what the GPIO lights show, set on every broadcast tick.
+type gpioInput struct {
+	rpm    uint16          // 0 while the engine is off or the ECU is stale
+	level  int             // Highest alertRank of the active alerts, -1 with none
+	alerts map[string]bool // Active alert IDs
+}
+
+// gpioPin is an opened GPIO light.
+type gpioPin struct {
+	pin  *gpio.Pin
+	cfg  GPIOPinConfig
+	line string // For logs, e.g. "line 17"
+	on   bool
+}
+
+// gpioButton is an opened button.
+type gpioButton struct {
+	in      *gpio.Input
+	cfg     GPIOButtonConfig
+	pressed bool      // Debounced state
+	raw     bool      // Latest reading
+	rawAt   time.Time // When the reading last changed
+	failing bool      // Last read failed, already logged
+}
+
+// gpioStrip is an opened shift light strip, with defaults resolved.
+type gpioStrip struct {
+	strip            *gpio.Strip
+	start, shift, fl uint16 // StartRPM, ShiftRPM, FlashRPM
+	palette          []gpio.Color
+	flashColor       gpio.Color
+	alertColor       gpio.Color
+	alerts           bool
+	last             []gpio.Color
+	sentAt           time.Time
+	failing          bool // Last write failed, already logged
+}
+
+// setLights records the tick's RPM and alerts for the GPIO lights.
+func (s *Server) setLights(e *ecu.DataFrame, alerts []Alert) {
+	in := &gpioInput{level: -1, alerts: make(map[string]bool, len(alerts))}
+	if e != nil && e.RPM > 500 {
+		in.rpm = e.RPM
+	}
+	for _, a := range alerts {
+		in.alerts[a.ID] = true
+		in.level = max(in.level, alertRank(a.Level))
+	}
+	s.lights.Store(in)
+}
+
+// runGPIO opens the configured lights and buttons, drives the lights from
+// the latest tick and runs the buttons' actions until ctx ends, then turns
+// the lights off.
+func (s *Server) runGPIO(ctx context.Context) {
+	cfg := s.cfg.GPIOSnapshot()
+	th := s.cfg.Thresholds()
+
+	var pins []*gpioPin
+	for _, pc := range cfg.Pins {
+		switch {
+		case pc.When == "rpm", pc.When == "alert", pc.When == "danger", strings.HasPrefix(pc.When, "alert:"):
+		default:
+			log.Printf("[gpio] line %d: unknown when %q", pc.Line, pc.When)
+			continue
+		}
+		if pc.When == "rpm" && pc.RPM == 0 {
+			pc.RPM = th.RPMWarn
+		}
+		p, err := gpio.OpenPin(cfg.Chip, pc.Line, pc.ActiveLow)
+		if err != nil {
+			log.Printf("[gpio] %v", err)
+			continue
+		}
+		pins = append(pins, &gpioPin{pin: p, cfg: pc, line: fmt.Sprintf("line %d", pc.Line)})
+	}
+	var strip *gpioStrip
+	if sc := cfg.Strip; sc.Device != "" && sc.LEDs > 0 {
+		strip = newGPIOStrip(sc, th)
+		var err error
+		if strip.strip, err = gpio.OpenStrip(sc.Device, sc.LEDs); err != nil {
+			log.Printf("[gpio] strip: %v", err)
+			strip = nil
+		}
+	}
+	var buttons []*gpioButton
+	for _, bc := range cfg.Buttons {
+		pull := gpio.Pull(bc.Pull)
+		if pull == "" {
+			pull = gpio.PullUp
+		}
+		in, err := gpio.OpenInput(cfg.Chip, bc.Line, pull, !bc.ActiveHigh)
+		if err != nil {
+			log.Printf("[gpio] %v", err)
+			continue
+		}
+		buttons = append(buttons, &gpioButton{in: in, cfg: bc})
+	}
+
+	var using []string
+	if len(pins) > 0 {
+		using = append(using, fmt.Sprintf("%d light(s)", len(pins)))
+	}
+	if strip != nil {
+		using = append(using, fmt.Sprintf("a %d-LED shift light strip", len(strip.palette)))
+	}
+	if len(buttons) > 0 {
+		using = append(using, fmt.Sprintf("%d button(s)", len(buttons)))
+	}
+	if len(using) == 0 {
+		return
+	}
+	log.Printf("[gpio] using %s", strings.Join(using, ", "))
+	defer func() {
+		for _, p := range pins {
+			p.pin.Set(false)
+			p.pin.Close()
+		}
+		if strip != nil {
+			strip.strip.Close()
+		}
+		for _, b := range buttons {
+			b.in.Close()
+		}
+	}()
+
+	debounce := time.Duration(cfg.DebounceMs) * time.Millisecond
+	page := s.cfg.DisplaySnapshot().Layout
+
+	ticker := time.NewTicker(gpioTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			in := s.lights.Load()
+			if in == nil {
+				in = &gpioInput{level: -1}
+			}
+			flash := now.UnixMilli()/gpioFlash.Milliseconds()%2 == 0
+			for _, p := range pins {
+				on := p.cfg.lit(in, flash)
+				if on == p.on {
+					continue
+				}
+				if err := p.pin.Set(on); err != nil {
+					log.Printf("[gpio] %s: %v", p.line, err)
+					continue
+				}
+				p.on = on
+			}
+			if strip != nil {
+				strip.update(now, in, flash)
+			}
+			for _, b := range buttons {
+				if !b.poll(now, debounce) {
+					continue
+				}
+				act := s.inputAction(b.cfg.Action, &page)
+				if act.Action == "" {
+					continue
+				}
+				act.Line = b.cfg.Line
+				if act.Error != "" {
+					log.Printf("[gpio] button on line %d %s: %s", act.Line, act.Action, act.Error)
+				}
+				s.broadcast(Frame{Keypad: &act, Stamp: now.UnixMilli()})
+			}
+		}
+	}
+}
+
+// lit reports whether the light should be on.
+func (pc GPIOPinConfig) lit(in *gpioInput, flash bool) bool {
+	switch pc.When {
+	case "rpm":
+		switch {
+		case in.rpm == 0 || in.rpm < pc.RPM:
+			return false
+		case pc.FlashRPM > 0 && in.rpm >= pc.FlashRPM:
+			return flash
+		}
+		return true
+	case "alert":
+		return len(in.alerts) > 0
+	case "danger":
+		return in.level >= 1
+	}
+	id, _ := strings.CutPrefix(pc.When, "alert:")
+	return in.alerts[id]
+}
+
+// poll reads the button and reports whether it has just been pressed.
+func (b *gpioButton) poll(now time.Time, debounce time.Duration) bool {
+	v, err := b.in.Get()
+	if err != nil {
+		if !b.failing {
+			log.Printf("[gpio] button on line %d: %v", b.cfg.Line, err)
+		}
+		b.failing = true
+		return false
+	}
+	b.failing = false
+	return b.debounce(now, v, debounce)
+}
+
+// debounce takes reading v and reports a press once the line has held it
+// for the debounce time, so contact bounce and noise on a long run of
+// wire to the steering wheel don't count.
+func (b *gpioButton) debounce(now time.Time, v bool, d time.Duration) bool {
+	if v != b.raw {
+		b.raw, b.rawAt = v, now
+	}
+	if b.raw == b.pressed || now.Sub(b.rawAt) < d {
+		return false
+	}
+	b.pressed = b.raw
+	return b.pressed
+}
+
+// newGPIOStrip resolves a shift light strip's defaults and colours.
+func newGPIOStrip(sc GPIOStripConfig, th ThresholdConfig) *gpioStrip {
+	st := &gpioStrip{start: sc.StartRPM, shift: sc.ShiftRPM, fl: sc.FlashRPM, alerts: sc.Alerts}
+	if st.shift == 0 {
+		st.shift = th.RPMWarn
+	}
+	if st.fl == 0 {
+		st.fl = th.RPMDanger
+	}
+	if st.start == 0 || st.start >= st.shift {
+		st.start = st.shift * 2 / 3
+	}
+	bright := sc.Brightness
+	if bright <= 0 {
+		bright = 1
+	}
+	for i := 0; i < sc.LEDs; i++ {
+		name := "red"
+		switch frac := float64(i) / float64(sc.LEDs); {
+		case i < len(sc.Colors):
+			name = sc.Colors[i]
+		case frac < 0.5:
+			name = "green"
+		case frac < 0.8:
+			name = "amber"
+		}
+		c, err := gpio.ParseColor(name)
+		if err != nil {
+			log.Printf("[gpio] strip LED %d: %v", i+1, err)
+		}
+		st.palette = append(st.palette, c.Scale(bright))
+	}
+	st.flashColor = gpio.Color{B: 255}
+	if sc.FlashColor != "" {
+		c, err := gpio.ParseColor(sc.FlashColor)
+		if err != nil {
+			log.Printf("[gpio] strip flash_color: %v", err)
+		} else {
+			st.flashColor = c
+		}
+	}
+	st.flashColor = st.flashColor.Scale(bright)
+	st.alertColor = gpio.Color{R: 255}.Scale(bright)
+	return st
+}
+
+// frame returns the strip's LEDs for in.
+func (st *gpioStrip) frame(in *gpioInput, flash bool) []gpio.Color {
+	n := len(st.palette)
+	out := make([]gpio.Color, n)
+	switch {
+	case st.alerts && in.level >= 1:
+		if flash {
+			for i := range out {
+				out[i] = st.alertColor
+			}
+		}
+	case in.rpm == 0 || in.rpm < st.start:
+	case st.fl > 0 && in.rpm >= st.fl:
+		if flash {
+			for i := range out {
+				out[i] = st.flashColor
+			}
+		}
+	default:
+		lit := n
+		if in.rpm < st.shift {
+			lit = 1 + int(in.rpm-st.start)*(n-1)/int(st.shift-st.start)
+		}
+		copy(out, st.palette[:lit])
+	}
+	return out
+}
+
+// update writes the strip if what it shows has changed.
+func (st *gpioStrip) update(now time.Time, in *gpioInput, flash bool) {
+	leds := st.frame(in, flash)
+	if colorsEqual(leds, st.last) && now.Sub(st.sentAt) < gpioRefresh {
+		return
+	}
+	err := st.strip.Set(leds)
+	if err != nil && !st.failing {
+		log.Printf("[gpio] strip: %v", err)
+	}
+	st.failing = err != nil
+	st.last, st.sentAt = leds, now
+}
+
+func colorsEqual(a, b []gpio.Color) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
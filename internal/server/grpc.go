@@ -0,0 +1,221 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/dashpb"
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// grpcDash implements the gRPC API (internal/dashpb/dash.proto) from the
+// frame feed.
+type grpcDash struct {
+	dashpb.UnimplementedDashServer
+	s *Server
+}
+
+// runGRPC serves the gRPC API until ctx ends. Reflection is on, so tools
+// like grpcurl can list and call it without the .proto.
+func (s *Server) runGRPC(ctx context.Context) {
+	addr := s.cfg.GRPCSnapshot().Listen
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Printf("[grpc] listen %s: %v", addr, err)
+		return
+	}
+	g := grpc.NewServer()
+	dashpb.RegisterDashServer(g, &grpcDash{s: s})
+	reflection.Register(g)
+	go func() {
+		<-ctx.Done()
+		g.Stop() // Subscriptions never finish on their own
+	}()
+	log.Printf("[grpc] listening on %s", addr)
+	if err := g.Serve(ln); err != nil {
+		log.Printf("[grpc] %v", err)
+	}
+}
+
+func (d *grpcDash) GetSnapshot(ctx context.Context, req *dashpb.GetSnapshotRequest) (*dashpb.Frame, error) {
+	f, _ := d.s.frames.latest()
+	if f == nil {
+		return nil, status.Error(codes.NotFound, "no frame yet")
+	}
+	return protoFrame(f, channelSet(req.Channels)), nil
+}
+
+func (d *grpcDash) Subscribe(req *dashpb.SubscribeRequest, stream dashpb.Dash_SubscribeServer) error {
+	if req.RateHz < 0 {
+		return status.Error(codes.InvalidArgument, "rate_hz must not be negative")
+	}
+	var every time.Duration
+	if req.RateHz > 0 {
+		every = time.Duration(float64(time.Second) / req.RateHz)
+	}
+	keep := channelSet(req.Channels)
+	ctx := stream.Context()
+
+	var sent *Frame
+	for {
+		f, next := d.s.frames.latest()
+		if f != nil && f != sent {
+			if err := stream.Send(protoFrame(f, keep)); err != nil {
+				return err
+			}
+			sent = f
+			if every > 0 {
+				t := time.NewTimer(every)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return nil
+				case <-t.C:
+				}
+				continue // Then the newest frame, if there's been one
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-next:
+		}
+	}
+}
+
+// channelSet returns names as a set, or nil (every channel) if empty.
+func channelSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
+
+// protoFrame converts a frame, with only the ECU and aux channels in keep
+// (nil = all).
+func protoFrame(f *Frame, keep map[string]bool) *dashpb.Frame {
+	out := &dashpb.Frame{
+		Stamp:        f.Stamp,
+		EcuConnected: f.ECUConnected,
+		Egt:          f.EGT,
+		NoData:       f.NoData,
+		LastData:     f.LastData,
+	}
+	if f.ECU != nil {
+		out.Ecu = protoDataFrame(f.ECU, keep)
+	}
+	if g := f.GPS; g != nil {
+		out.Gps = &dashpb.GPS{
+			Valid:      g.Valid,
+			Latitude:   g.Latitude,
+			Longitude:  g.Longitude,
+			SpeedKph:   g.Speed,
+			Heading:    g.Heading,
+			Altitude:   g.Altitude,
+			Satellites: int32(g.Satellites),
+			FixQuality: int32(g.FixQuality),
+			Hdop:       g.HDOP,
+			Time:       g.Time,
+			FixType:    int32(g.FixType),
+			Pdop:       g.PDOP,
+			Vdop:       g.VDOP,
+			HAcc:       g.HAcc,
+			SpeedAcc:   g.SpeedAcc,
+			HeadingAcc: g.HeadingAcc,
+		}
+	}
+	if sp := f.Speed; sp != nil {
+		out.Speed = &dashpb.Speed{Value: sp.Value, Source: sp.Source, GpsWeight: sp.GPSWeight}
+	}
+	if m := f.IMU; m != nil {
+		out.Imu = &dashpb.IMU{
+			LatG:    m.LatG,
+			LongG:   m.LongG,
+			VertG:   m.VertG,
+			YawRate: m.YawRate,
+			Roll:    m.Roll,
+			Pitch:   m.Pitch,
+			Stamp:   m.Stamp,
+		}
+	}
+	if len(f.ECUs) > 0 {
+		out.Ecus = make(map[string]*dashpb.DataFrame, len(f.ECUs))
+		for name, e := range f.ECUs {
+			if e != nil {
+				out.Ecus[name] = protoDataFrame(e, keep)
+			}
+		}
+		out.EcusConnected = f.ECUsConnected
+	}
+	return out
+}
+
+// dataFrameFields maps each ecu.DataFrame channel to its typed field in
+// dashpb.DataFrame, which has the same JSON name. aux and auxIn aren't
+// single numbers and are copied separately.
+var dataFrameFields = func() map[string]protoreflect.FieldDescriptor {
+	fields := (&dashpb.DataFrame{}).ProtoReflect().Descriptor().Fields()
+	m := make(map[string]protoreflect.FieldDescriptor)
+	for _, name := range ecu.ChannelNames() {
+		if name == "aux" || name == "auxIn" {
+			continue
+		}
+		fd := fields.ByJSONName(name)
+		if fd == nil {
+			panic("server: dash.proto DataFrame has no field for channel " + name)
+		}
+		m[name] = fd
+	}
+	return m
+}()
+
+// protoDataFrame converts the channels e sends, and its aux channels.
+func protoDataFrame(e *ecu.DataFrame, keep map[string]bool) *dashpb.DataFrame {
+	out := &dashpb.DataFrame{}
+	msg := out.ProtoReflect()
+	for name, fd := range dataFrameFields {
+		if keep != nil && !keep[name] {
+			continue
+		}
+		v, ok := e.Value(name)
+		if !ok {
+			continue
+		}
+		switch fd.Kind() {
+		case protoreflect.BoolKind:
+			msg.Set(fd, protoreflect.ValueOfBool(v != 0))
+		case protoreflect.Sint32Kind:
+			msg.Set(fd, protoreflect.ValueOfInt32(int32(v)))
+		case protoreflect.Uint32Kind:
+			msg.Set(fd, protoreflect.ValueOfUint32(uint32(v)))
+		default:
+			msg.Set(fd, protoreflect.ValueOfFloat64(v))
+		}
+	}
+	if len(e.AuxIn) > 0 && (keep == nil || keep["auxIn"]) && e.Channels.Has("auxIn") {
+		out.AuxIn = make([]uint32, len(e.AuxIn))
+		for i, w := range e.AuxIn {
+			out.AuxIn[i] = uint32(w)
+		}
+	}
+	for name, v := range e.Aux {
+		if keep == nil || keep[name] {
+			if out.Aux == nil {
+				out.Aux = make(map[string]float64, len(e.Aux))
+			}
+			out.Aux[name] = v
+		}
+	}
+	return out
+}
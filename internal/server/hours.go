@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// engineHoursMaxTick is the longest gap counted; longer ones are ECU
+// dropouts.
+const engineHoursMaxTick = time.Second
+
+// updateEngineHours adds one broadcast tick while the engine turns. Saved
+// with the odometer, so a power cut loses at most a save interval.
+func (s *Server) updateEngineHours(now time.Time, e *ecu.DataFrame) {
+	s.odoMu.Lock()
+	defer s.odoMu.Unlock()
+	if e == nil || e.RPM == 0 {
+		s.engineAt = time.Time{}
+		return
+	}
+	prev := s.engineAt
+	s.engineAt = now
+	dt := now.Sub(prev)
+	if prev.IsZero() || dt <= 0 || dt > engineHoursMaxTick {
+		return
+	}
+	s.engineHours += dt.Hours()
+}
+
+// handleOdometer serves the odometer, trips and engine hours.
+//
+//	GET /api/odo
+func (s *Server) handleOdometer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.odoData())
+}
+
+// handleEngineHours sets the engine hours, to match an existing meter or
+// restart the count after a rebuild.
+//
+//	POST /api/odo/hours?set=N
+func (s *Server) handleEngineHours(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	hours, err := strconv.ParseFloat(r.URL.Query().Get("set"), 64)
+	if err != nil || hours < 0 || hours > 1e6 {
+		http.Error(w, "set must be hours, 0 or more", 400)
+		return
+	}
+	s.odoMu.Lock()
+	s.engineHours = hours
+	s.odoMu.Unlock()
+	s.saveOdometer()
+	log.Printf("[odo] engine hours set to %.1f", hours)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
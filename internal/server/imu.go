@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"github.com/shaunagostinho/speeduino-dash/internal/imu"
+)
+
+// imuStale is how old IMU data may be before it's left out of frames.
+const imuStale = 500 * time.Millisecond
+
+// SetIMU registers the accelerometer/gyro. Its data is broadcast as
+// Frame.IMU and logged. The server owns the provider's connection. Must
+// be called before Run.
+func (s *Server) SetIMU(prov imu.Provider) {
+	s.imuProv = prov
+}
+
+// runIMU connects the IMU and reads it continuously, keeping the latest
+// sample. After repeated errors it's closed and reopened with backoff.
+func (s *Server) runIMU(ctx context.Context) {
+	var (
+		connected      bool
+		consecErrors   int
+		lastErrLog     time.Time
+		reconnectDelay = 2 * time.Second
+		maxReconnDelay = 30 * time.Second
+	)
+	const maxConsecErrors = 10
+	prov := s.imuProv
+
+	if bus := s.cfg.IMU.Bus; s.cfg.IMU.Type != "demo" && bus != "" {
+		wait := time.Duration(s.cfg.Startup.PortWaitSec) * time.Second
+		if err := device.WaitReady(ctx, "imu", bus, wait); err != nil && ctx.Err() == nil {
+			log.Printf("[imu] %v — continuing with connect retries", err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			prov.Close()
+			return
+		default:
+		}
+
+		if !connected {
+			if err := prov.Connect(); err != nil {
+				log.Printf("[imu] connect failed: %v (retry in %v)", err, reconnectDelay)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectDelay):
+				}
+				reconnectDelay = min(reconnectDelay*2, maxReconnDelay)
+				continue
+			}
+			log.Printf("[imu] connected (%s)", prov.Name())
+			connected = true
+			consecErrors = 0
+			reconnectDelay = 2 * time.Second
+		}
+
+		d, err := prov.Read()
+		if err == nil {
+			consecErrors = 0
+			s.imuLast.Store(d)
+			continue
+		}
+
+		consecErrors++
+		if time.Since(lastErrLog) > 5*time.Second {
+			log.Printf("[imu] read error (%d consecutive): %v", consecErrors, err)
+			lastErrLog = time.Now()
+		}
+		if consecErrors >= maxConsecErrors {
+			log.Printf("[imu] %d consecutive errors, closing for reconnect", consecErrors)
+			prov.Close()
+			connected = false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// imuSnapshot returns the latest IMU data, or nil if there's none fresh.
+func (s *Server) imuSnapshot(now time.Time) *imu.Data {
+	d := s.imuLast.Load()
+	if d == nil || now.UnixMilli()-d.Stamp > imuStale.Milliseconds() {
+		return nil
+	}
+	return d
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/imu"
+	"github.com/shaunagostinho/speeduino-dash/internal/influx"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// newInfluxSink creates the InfluxDB sink, or returns nil if it's off or
+// misconfigured.
+func newInfluxSink(cfg InfluxConfig, vehicle string, store *storage.Store) *influx.Sink {
+	if cfg.URL == "" {
+		return nil
+	}
+	tags := cfg.Tags
+	if len(tags) == 0 && vehicle != "" {
+		tags = map[string]string{"car": vehicle}
+	}
+	spool, err := store.Dir(storage.DirInflux)
+	if err != nil {
+		log.Printf("[influx] %v; batches that fail are dropped", err)
+	}
+	sink, err := influx.New(influx.Config{
+		URL:         cfg.URL,
+		Org:         cfg.Org,
+		Bucket:      cfg.Bucket,
+		Token:       cfg.Token,
+		Measurement: cfg.Measurement,
+		Tags:        tags,
+		Interval:    time.Duration(cfg.IntervalMs) * time.Millisecond,
+		Flush:       time.Duration(cfg.FlushSec * float64(time.Second)),
+		SpoolDir:    spool,
+		SpoolBytes:  int64(cfg.SpoolMB) << 20,
+	})
+	if err != nil {
+		log.Printf("[influx] %v", err)
+		return nil
+	}
+	return sink
+}
+
+// recordInflux queues the tick's data for InfluxDB when a point is due.
+// Fields are DataFrame JSON names and aux channels as elsewhere, plus
+// fused speed, GPS, EGT and IMU data.
+func (s *Server) recordInflux(now time.Time, e *ecu.DataFrame, g *gps.Data, speed float64, egt []float64, m *imu.Data) {
+	if s.influx == nil || !s.influx.Due(now) {
+		return
+	}
+	fields := map[string]float64{"speed": speed}
+	if e != nil {
+		for _, name := range ecu.ChannelNames() {
+			if v, ok := e.Value(name); ok {
+				fields[name] = v
+			}
+		}
+		for name, v := range e.Aux {
+			fields[name] = v
+		}
+	}
+	if g != nil && g.Valid {
+		fields["gpsLat"] = g.Latitude
+		fields["gpsLon"] = g.Longitude
+		fields["gpsSpeed"] = g.Speed
+		fields["gpsHeading"] = g.Heading
+		fields["gpsAlt"] = g.Altitude
+		fields["gpsSats"] = float64(g.Satellites)
+	}
+	for i, v := range egt {
+		if v != 0 {
+			fields["egt"+strconv.Itoa(i+1)] = v
+		}
+	}
+	if m != nil {
+		fields["latG"] = m.LatG
+		fields["longG"] = m.LongG
+		fields["vertG"] = m.VertG
+		fields["yawRate"] = m.YawRate
+	}
+	s.influx.Record(now, fields)
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+)
+
+// Fault injection — debug-only synthetic channel values for testing alert
+// wiring (buzzer, LEDs, overlays, notifications) without abusing the engine.
+// Enabled with server.debug / --debug; the endpoint 404s otherwise.
+
+const (
+	defaultInjectDuration = 5 * time.Second
+	maxInjectDuration     = 60 * time.Second
+)
+
+// injectRequest is the body of POST /api/debug/inject.
+type injectRequest struct {
+	Scenario  string                 `json:"scenario,omitempty"`  // Named preset, see scenarioChannels
+	Channels  map[string]interface{} `json:"channels,omitempty"`  // ECU channels by JSON name, e.g. {"coolant": 118}
+	GPSLoss   bool                   `json:"gpsLoss,omitempty"`   // Report no GPS fix
+	ECULoss   bool                   `json:"ecuLoss,omitempty"`   // Drop ECU data and report disconnected
+	DurationS float64                `json:"durationS,omitempty"` // Default 5 s, max 60 s
+}
+
+// activeInjection is the currently applied fault, if any.
+type activeInjection struct {
+	Scenario string                 `json:"scenario,omitempty"`
+	Channels map[string]interface{} `json:"channels,omitempty"`
+	GPSLoss  bool                   `json:"gpsLoss,omitempty"`
+	ECULoss  bool                   `json:"ecuLoss,omitempty"`
+	Until    int64                  `json:"until"` // Unix ms
+}
+
+// faultInjector holds the active injection and applies it to outgoing data.
+type faultInjector struct {
+	mu     sync.Mutex
+	active *activeInjection
+}
+
+// scenarioChannels maps a named scenario to channel overrides derived from
+// the configured thresholds, so the preset always trips the relevant alert.
+func scenarioChannels(name string, t ThresholdConfig) (map[string]interface{}, bool, bool, error) {
+	switch name {
+	case "":
+		return nil, false, false, nil
+	case "overheat":
+		return map[string]interface{}{"coolant": t.CLTDanger + 5}, false, false, nil
+	case "iat":
+		return map[string]interface{}{"iat": t.IATDanger + 5}, false, false, nil
+	case "knock":
+		return map[string]interface{}{"knockCount": 5, "knockCor": int(t.KnockWarn) + 3}, false, false, nil
+	case "lean":
+		return map[string]interface{}{"afr": t.AFRLeanWarn + 1.5}, false, false, nil
+	case "rich":
+		return map[string]interface{}{"afr": t.AFRRichWarn - 1.5}, false, false, nil
+	case "low_oil":
+		return map[string]interface{}{"oilPressure": 0, "rpm": 3000, "running": true}, false, false, nil
+	case "low_battery":
+		return map[string]interface{}{"batteryVoltage": t.BattLow - 1}, false, false, nil
+	case "injector":
+		return map[string]interface{}{"pwImbalance": t.PWImbalanceWarn + 10}, false, false, nil
+	case "boost_leak":
+		return map[string]interface{}{"rpm": 5000, "tps": 100, "boostTarget": 90, "boostDuty": 100,
+			"map": int(180 - t.BoostErrorWarn - 10)}, false, false, nil
+	case "lean_boost":
+		return map[string]interface{}{"rpm": 5000, "tps": 100, "map": int(max(t.LeanBoostKPa, 130)) + 40,
+			"afrTarget": 11.5, "afr": 11.5 + t.LeanBoostMargin + 1.5}, false, false, nil
+	case "vvt":
+		return map[string]interface{}{"vvt1Error": t.VVTErrorDanger + 5}, false, false, nil
+	case "overrev":
+		return map[string]interface{}{"rpm": int(t.RPMDanger) + 200}, false, false, nil
+	case "gps_loss":
+		return nil, true, false, nil
+	case "ecu_loss":
+		return nil, false, true, nil
+	default:
+		return nil, false, false, fmt.Errorf("unknown scenario %q", name)
+	}
+}
+
+// set activates an injection, replacing any previous one.
+func (fi *faultInjector) set(a *activeInjection) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.active = a
+}
+
+// clear removes the active injection.
+func (fi *faultInjector) clear() {
+	fi.set(nil)
+}
+
+// current returns the active injection, expiring it if its time is up.
+func (fi *faultInjector) current() *activeInjection {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	if fi.active != nil && time.Now().UnixMilli() >= fi.active.Until {
+		log.Printf("[debug] injection %q expired", fi.active.Scenario)
+		fi.active = nil
+	}
+	return fi.active
+}
+
+// apply returns copies of the ECU/GPS data with the active injection
+// overlaid. ok reports whether an injection was applied.
+func (fi *faultInjector) apply(e *ecu.DataFrame, g *gps.Data) (*ecu.DataFrame, *gps.Data, bool) {
+	a := fi.current()
+	if a == nil {
+		return e, g, false
+	}
+
+	if a.ECULoss {
+		e = nil
+	} else if len(a.Channels) > 0 {
+		e = overlayChannels(e, a.Channels)
+	}
+	if a.GPSLoss && g != nil {
+		lost := *g
+		lost.Valid = false
+		lost.FixQuality = 0
+		lost.Satellites = 0
+		lost.Speed = 0
+		g = &lost
+	}
+	return e, g, true
+}
+
+// overlayChannels merges channel values (by JSON name) onto a copy of e.
+func overlayChannels(e *ecu.DataFrame, channels map[string]interface{}) *ecu.DataFrame {
+	base := map[string]interface{}{}
+	if e != nil {
+		if b, err := json.Marshal(e); err == nil {
+			json.Unmarshal(b, &base)
+		}
+	}
+	for k, v := range channels {
+		base[k] = v
+	}
+	out := &ecu.DataFrame{}
+	if b, err := json.Marshal(base); err == nil {
+		json.Unmarshal(b, out)
+	}
+	return out
+}
+
+// handleDebugInject manages fault injection:
+//
+//	GET    /api/debug/inject — active injection (or null)
+//	POST   /api/debug/inject — start one (see injectRequest)
+//	DELETE /api/debug/inject — stop it
+func (s *Server) handleDebugInject(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.Server.Debug {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.faults.current())
+
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "bad request", 400)
+			return
+		}
+		var req injectRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		channels, gpsLoss, ecuLoss, err := scenarioChannels(req.Scenario, s.cfg.Display.Thresholds)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		if channels == nil {
+			channels = map[string]interface{}{}
+		}
+		for k, v := range req.Channels {
+			channels[k] = v
+		}
+		gpsLoss = gpsLoss || req.GPSLoss
+		ecuLoss = ecuLoss || req.ECULoss
+		if len(channels) == 0 && !gpsLoss && !ecuLoss {
+			http.Error(w, "nothing to inject: set scenario, channels, gpsLoss or ecuLoss", 400)
+			return
+		}
+
+		dur := time.Duration(req.DurationS * float64(time.Second))
+		if dur <= 0 {
+			dur = defaultInjectDuration
+		}
+		if dur > maxInjectDuration {
+			dur = maxInjectDuration
+		}
+
+		a := &activeInjection{
+			Scenario: req.Scenario,
+			Channels: channels,
+			GPSLoss:  gpsLoss,
+			ECULoss:  ecuLoss,
+			Until:    time.Now().Add(dur).UnixMilli(),
+		}
+		s.faults.set(a)
+		log.Printf("[debug] injecting %q for %v (channels=%v gpsLoss=%v ecuLoss=%v)",
+			req.Scenario, dur, channels, gpsLoss, ecuLoss)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a)
+
+	case http.MethodDelete:
+		s.faults.clear()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/autox"
+	"github.com/shaunagostinho/speeduino-dash/internal/keypad"
+)
+
+const (
+	keypadLEDRefresh = 2 * time.Second        // LEDs are resent this often, in case the keypad rebooted
+	keypadFlash      = 800 * time.Millisecond // Key lit green (done) or red (failed) after a press
+)
+
+// keypadLayouts are the dash layouts, in the order layout_next steps
+// through them (dash.js).
+var keypadLayouts = []string{"classic", "sweep", "race", "minimal"}
+
+// KeypadAction tells the dash about a keypad or GPIO button press: a
+// layout to show, or a notice saying what the key did.
+type KeypadAction struct {
+	Key    int    `json:"key,omitempty"`  // Keypad key
+	Line   int    `json:"line,omitempty"` // GPIO button's line
+	Action string `json:"action"`
+	Layout string `json:"layout,omitempty"` // Switch to this layout
+	Text   string `json:"text,omitempty"`   // e.g. "TRIP A RESET"
+	Error  string `json:"error,omitempty"`  // Why the action failed
+}
+
+// keypadFlashing is a key's LED overridden briefly after a press.
+type keypadFlashing struct {
+	led   keypad.LED
+	until time.Time
+}
+
+// SetKeypad sets the CAN keypad, connected and read by Run.
+func (s *Server) SetKeypad(prov keypad.Provider) {
+	s.keypadProv = prov
+}
+
+// runKeypad connects the keypad, runs the bound action on each key press
+// and keeps the key LEDs up to date. After repeated errors it's closed and
+// reopened with backoff. Bindings are read per press, so changes in
+// settings apply at once.
+func (s *Server) runKeypad(ctx context.Context) {
+	var (
+		connected      bool
+		consecErrors   int
+		lastErrLog     time.Time
+		reconnectDelay = 2 * time.Second
+		maxReconnDelay = 30 * time.Second
+		leds           []keypad.LED
+		ledsAt         time.Time
+		flash          = make(map[int]keypadFlashing)
+	)
+	const maxConsecErrors = 10
+	prov := s.keypadProv
+	page := s.cfg.DisplaySnapshot().Layout
+
+	for {
+		select {
+		case <-ctx.Done():
+			prov.Close()
+			return
+		default:
+		}
+
+		if !connected {
+			if err := prov.Connect(); err != nil {
+				log.Printf("[keypad] connect failed: %v (retry in %v)", err, reconnectDelay)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectDelay):
+				}
+				reconnectDelay = min(reconnectDelay*2, maxReconnDelay)
+				continue
+			}
+			log.Printf("[keypad] connected (%s)", prov.Name())
+			connected = true
+			consecErrors = 0
+			reconnectDelay = 2 * time.Second
+			leds = nil
+		}
+
+		events, err := prov.Read()
+		if err == nil {
+			consecErrors = 0
+			now := time.Now()
+			for _, ev := range events {
+				if !ev.Pressed {
+					continue
+				}
+				act := s.keypadPress(ev.Key, &page)
+				if act.Action == "" {
+					continue
+				}
+				led := keypad.LEDGreen
+				if act.Error != "" {
+					led = keypad.LEDRed
+					log.Printf("[keypad] key %d %s: %s", act.Key, act.Action, act.Error)
+				}
+				flash[ev.Key] = keypadFlashing{led: led, until: now.Add(keypadFlash)}
+				s.broadcast(Frame{Keypad: &act, Stamp: now.UnixMilli()})
+			}
+			next := s.keypadLEDs(now, page, flash)
+			if !ledsEqual(next, leds) || now.Sub(ledsAt) >= keypadLEDRefresh {
+				err = prov.SetLEDs(next)
+				leds, ledsAt = next, now
+			}
+			if err == nil {
+				continue
+			}
+		}
+
+		consecErrors++
+		if time.Since(lastErrLog) > 5*time.Second {
+			log.Printf("[keypad] error (%d consecutive): %v", consecErrors, err)
+			lastErrLog = time.Now()
+		}
+		if consecErrors >= maxConsecErrors {
+			log.Printf("[keypad] %d consecutive errors, closing for reconnect", consecErrors)
+			prov.Close()
+			connected = false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// keypadPress runs the action bound to key. page is the layout last
+// chosen from the keypad (see inputAction). The result has no Action when
+// the key is unbound.
+func (s *Server) keypadPress(key int, page *string) KeypadAction {
+	var action string
+	for _, k := range s.cfg.KeypadSnapshot().Keys {
+		if k.Key == key {
+			action = k.Action
+			break
+		}
+	}
+	act := s.inputAction(action, page)
+	act.Key = key
+	return act
+}
+
+// inputAction runs action for a keypad key or GPIO button. page is the
+// layout last chosen from the same device, which layout actions step from
+// and update.
+func (s *Server) inputAction(action string, page *string) KeypadAction {
+	act := KeypadAction{Action: action}
+	var err error
+	switch name, ok := strings.CutPrefix(act.Action, "layout:"); {
+	case act.Action == "":
+		return act
+	case ok:
+		act.Layout = name
+	case act.Action == "layout_next", act.Action == "layout_prev":
+		step := 1
+		if act.Action == "layout_prev" {
+			step = len(keypadLayouts) - 1
+		}
+		i := 0
+		for j, l := range keypadLayouts {
+			if l == *page {
+				i = j
+			}
+		}
+		act.Layout = keypadLayouts[(i+step)%len(keypadLayouts)]
+	default:
+		act.Text, err = s.keypadAction(act.Action)
+	}
+	if act.Layout != "" {
+		*page = act.Layout
+	}
+	if err != nil {
+		act.Error = err.Error()
+	}
+	return act
+}
+
+// keypadAction runs a server-side action and returns the notice to show.
+func (s *Server) keypadAction(action string) (string, error) {
+	switch action {
+	case "trip_reset":
+		s.resetTrip(tripA)
+		return "TRIP A RESET", nil
+	case "trip_b_reset":
+		s.resetTrip(tripB)
+		return "TRIP B RESET", nil
+	case "fillup":
+		s.fillUp(0)
+		return "FILLED UP", nil
+	case "snapshot":
+		if _, err := s.captureSnapshot("button"); err != nil {
+			return "", err
+		}
+		return "SNAPSHOT SAVED", nil
+	case "logging":
+		if s.logger.IsEnabled() {
+			s.logger.SetEnabled(false)
+			log.Printf("[logger] stopped by a button press")
+			return "LOGGING OFF", nil
+		}
+		s.logger.SetEnabled(true)
+		log.Printf("[logger] started by a button press")
+		return "LOGGING ON", nil
+	case "autox_arm":
+		if s.autox.Status(time.Now()).State != autox.StateIdle {
+			s.autox.Cancel()
+			log.Printf("[autox] disarmed")
+			return "AUTOX DISARMED", nil
+		}
+		if err := s.autox.Arm(""); err != nil {
+			return "", err
+		}
+		log.Printf("[autox] armed (%s)", s.autox.Status(time.Now()).Mode)
+		return "AUTOX ARMED", nil
+	case "autox_cone":
+		cones, dnf, err := s.autox.Penalty(0)
+		if err != nil {
+			return "", err
+		}
+		run, err := s.autox.SetPenalty(0, cones+1, dnf)
+		if err != nil {
+			return "", err
+		}
+		s.saveAutox()
+		return fmt.Sprintf("RUN %d: %d CONES", run.ID, run.Cones), nil
+	case "lap_mark":
+		if _, err := s.setStartFinish("", 0); err != nil {
+			return "", err
+		}
+		return "START/FINISH SET", nil
+	case "wake":
+		s.quiet.requestWake()
+		return "", nil
+	case "alarm_mute":
+		if s.muteAlarm() == 0 {
+			return "NO ALARM", nil
+		}
+		return "ALARM MUTED", nil
+	case "alert_ack":
+		switch n := s.ackAlerts(); n {
+		case 0:
+			return "NO ALERTS", nil
+		case 1:
+			return "ALERT ACKNOWLEDGED", nil
+		default:
+			return fmt.Sprintf("%d ALERTS ACKNOWLEDGED", n), nil
+		}
+	}
+	return "", fmt.Errorf("unknown action %q", action)
+}
+
+// keypadLEDs works out every key's LED from its binding, with recent
+// presses flashing their result.
+func (s *Server) keypadLEDs(now time.Time, page string, flash map[int]keypadFlashing) []keypad.LED {
+	cfg := s.cfg.KeypadSnapshot()
+	leds := make([]keypad.LED, cfg.Size)
+	for _, k := range cfg.Keys {
+		if k.Key < 1 || k.Key > len(leds) {
+			continue
+		}
+		if f, ok := flash[k.Key]; ok {
+			if now.Before(f.until) {
+				leds[k.Key-1] = f.led
+				continue
+			}
+			delete(flash, k.Key)
+		}
+		var led keypad.LED
+		switch k.LED {
+		case "alerts":
+			led = s.alertLED()
+		case "logging":
+			if s.logger.IsEnabled() {
+				led = keypad.LEDGreen
+			}
+		case "autox":
+			switch s.autox.Status(now).State {
+			case autox.StateArmed:
+				led = keypad.LEDAmber
+			case autox.StateRunning:
+				led = keypad.LEDGreen
+			}
+		case "page":
+			if name, ok := strings.CutPrefix(k.Action, "layout:"); ok && name == page {
+				led = keypad.LEDBlue
+			}
+		default:
+			led, _ = keypad.ParseLED(k.LED)
+		}
+		leds[k.Key-1] = led
+	}
+	return leds
+}
+
+// alertLED is red while a critical or danger alert is active and amber
+// for warnings.
+func (s *Server) alertLED() keypad.LED {
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	led := keypad.LEDOff
+	for _, a := range s.alerts {
+		if alertRank(a.Level) > 0 {
+			return keypad.LEDRed
+		}
+		led = keypad.LEDAmber
+	}
+	return led
+}
+
+func ledsEqual(a, b []keypad.LED) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// Knock map grid: RPM columns and MAP rows, each cell starting at its
+// axis value. The last column and row take everything above.
+const (
+	knockRPMStep  = 500
+	knockRPMCols  = 17 // 0–8000+
+	knockLoadStep = 10 // kPa
+	knockLoadRows = 26 // 0–250+ kPa
+	knockMaxTick  = time.Second
+)
+
+// sessionKnockFile returns the file holding session id's knock map.
+func sessionKnockFile(id string) string {
+	return sessionDir + "/" + id + ".knock.json"
+}
+
+// KnockMap is knock activity over an RPM × MAP grid for one drive, to
+// show where in the ignition table the engine knocks. Z arrays are
+// indexed [load row][rpm column].
+type KnockMap struct {
+	RPM       []int       `json:"rpm"`       // Column start, RPM
+	Load      []int       `json:"load"`      // Row start, MAP kPa
+	Events    [][]int     `json:"events"`    // Knock events counted by the ECU
+	Seconds   [][]float64 `json:"seconds"`   // Time spent in the cell, engine running
+	MaxRetard [][]int     `json:"maxRetard"` // Most knock retard seen, degrees
+	Total     int         `json:"total"`     // Events in all cells
+	Updated   int64       `json:"updated"`   // Unix ms of the last sample
+}
+
+func newKnockMap() *KnockMap {
+	m := &KnockMap{
+		RPM:       make([]int, knockRPMCols),
+		Load:      make([]int, knockLoadRows),
+		Events:    make([][]int, knockLoadRows),
+		Seconds:   make([][]float64, knockLoadRows),
+		MaxRetard: make([][]int, knockLoadRows),
+	}
+	for c := range m.RPM {
+		m.RPM[c] = c * knockRPMStep
+	}
+	for r := range m.Load {
+		m.Load[r] = r * knockLoadStep
+		m.Events[r] = make([]int, knockRPMCols)
+		m.Seconds[r] = make([]float64, knockRPMCols)
+		m.MaxRetard[r] = make([]int, knockRPMCols)
+	}
+	return m
+}
+
+// knockMapper accumulates the current drive's KnockMap.
+type knockMapper struct {
+	mu    sync.Mutex
+	m     *KnockMap
+	last  time.Time
+	count uint8 // KnockCount on the previous tick
+	dirty bool  // Changed since last saved
+}
+
+// update adds one broadcast tick. New events are the rise in the ECU's
+// knock count since the last tick (a drop means the count was reset, so
+// the new value is all new).
+func (k *knockMapper) update(now time.Time, e *ecu.DataFrame) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if e == nil || e.RPM <= 500 {
+		k.last = time.Time{}
+		return
+	}
+	if k.m == nil {
+		// The ECU's count may carry over from before this drive
+		k.m, k.count = newKnockMap(), e.KnockCount
+	}
+	r := min(int(e.MAP)/knockLoadStep, knockLoadRows-1)
+	c := min(int(e.RPM)/knockRPMStep, knockRPMCols-1)
+
+	events := int(e.KnockCount)
+	if e.KnockCount >= k.count {
+		events -= int(k.count)
+	}
+	k.count = e.KnockCount
+	if !k.last.IsZero() {
+		if dt := now.Sub(k.last); dt > 0 && dt <= knockMaxTick {
+			k.m.Seconds[r][c] += dt.Seconds()
+		}
+	}
+	k.last = now
+	if events > 0 {
+		k.m.Events[r][c] += events
+		k.m.Total += events
+	}
+	k.m.MaxRetard[r][c] = max(k.m.MaxRetard[r][c], int(e.KnockCor))
+	k.m.Updated = now.UnixMilli()
+	k.dirty = true
+}
+
+// snapshot returns a copy of the current map, or nil before the engine
+// has run.
+func (k *knockMapper) snapshot() *KnockMap {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.m == nil {
+		return nil
+	}
+	out := *k.m
+	out.Events = make([][]int, len(k.m.Events))
+	out.Seconds = make([][]float64, len(k.m.Seconds))
+	out.MaxRetard = make([][]int, len(k.m.MaxRetard))
+	for r := range k.m.Events {
+		out.Events[r] = append([]int(nil), k.m.Events[r]...)
+		out.Seconds[r] = append([]float64(nil), k.m.Seconds[r]...)
+		out.MaxRetard[r] = append([]int(nil), k.m.MaxRetard[r]...)
+	}
+	return &out
+}
+
+// saveKnock writes the current map to session id's knock file, if it has
+// changed.
+func (s *Server) saveKnock(id string) {
+	if id == "" {
+		return
+	}
+	k := &s.knock
+	k.mu.Lock()
+	if !k.dirty || k.m == nil {
+		k.mu.Unlock()
+		return
+	}
+	k.dirty = false
+	k.mu.Unlock()
+	if err := s.store.WriteJSON(sessionKnockFile(id), s.knock.snapshot()); err != nil {
+		log.Printf("[knock] save failed: %v", err)
+	}
+}
+
+// reset starts a new drive's map.
+func (k *knockMapper) reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.m, k.last, k.count, k.dirty = nil, time.Time{}, 0, false
+}
+
+// writeKnock sends a map as JSON, or with ?format=csv as a grid of event
+// counts.
+func writeKnock(w http.ResponseWriter, r *http.Request, m *KnockMap, name string) {
+	if r.URL.Query().Get("format") != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m)
+		return
+	}
+	writeGridCSV(w, name, m.RPM, m.Load, func(row, c int) string {
+		return strconv.Itoa(m.Events[row][c])
+	})
+}
+
+// writeGridCSV sends an RPM × MAP grid as CSV, rows MAP kPa and columns
+// RPM, so it pastes into a spreadsheet. cell formats one value.
+func writeGridCSV(w http.ResponseWriter, name string, rpm, load []int, cell func(row, c int) string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+	cw := csv.NewWriter(w)
+	head := []string{"map_kpa \\ rpm"}
+	for _, v := range rpm {
+		head = append(head, strconv.Itoa(v))
+	}
+	cw.Write(head)
+	// Highest load first, as tuning software shows tables
+	for row := len(load) - 1; row >= 0; row-- {
+		rec := []string{strconv.Itoa(load[row])}
+		for c := range rpm {
+			rec = append(rec, cell(row, c))
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+}
+
+// handleKnock serves the current drive's knock map.
+//
+//	GET /api/knock[?format=csv]
+func (s *Server) handleKnock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	m := s.knock.snapshot()
+	if m == nil {
+		http.Error(w, "no knock data yet", 404)
+		return
+	}
+	writeKnock(w, r, m, fmt.Sprintf("knock_%s", time.UnixMilli(m.Updated).Format(sessionIDLayout)))
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// knockEventsFile is the knock event history inside the data directory.
+const knockEventsFile = storage.DirState + "/knock_events.json"
+
+const (
+	knockEventsMax   = 100                    // Events kept, oldest dropped first
+	knockContext     = 2 * time.Second        // Data kept either side of an event
+	knockSampleEvery = 100 * time.Millisecond // Context sample rate
+)
+
+// KnockEvent is a rise in the ECU's knock count, with what the engine was
+// doing either side of it — the knock map says where knock happens, this
+// says under what conditions.
+type KnockEvent struct {
+	At      int64         `json:"at"`     // Unix ms
+	Count   int           `json:"count"`  // Knock events counted by the ECU, including any within knockContext after
+	Retard  uint8         `json:"retard"` // Most knock retard seen, degrees
+	RPM     uint16        `json:"rpm"`
+	MAP     uint16        `json:"map"`     // kPa
+	Advance int8          `json:"advance"` // Degrees
+	AFR     float64       `json:"afr"`
+	IAT     float64       `json:"iat"`     // °C
+	Samples []KnockSample `json:"samples"` // ±knockContext, oldest first
+}
+
+// KnockSample is one context sample of a KnockEvent.
+type KnockSample struct {
+	T       int64   `json:"t"` // ms from the event, negative before it
+	RPM     uint16  `json:"rpm"`
+	MAP     uint16  `json:"map"`
+	Advance int8    `json:"advance"`
+	AFR     float64 `json:"afr"`
+	IAT     float64 `json:"iat"`
+}
+
+// knockSample is a context sample before it's tied to an event.
+type knockSample struct {
+	at time.Time
+	KnockSample
+}
+
+// knockRecorder keeps the last knockContext of samples and turns rises
+// in the ECU's knock count into KnockEvents, each finished once
+// knockContext has passed after it.
+type knockRecorder struct {
+	mu       sync.Mutex
+	ring     []knockSample // Last knockContext, oldest first
+	count    uint8         // KnockCount on the previous tick
+	counted  bool          // count is set
+	open     *KnockEvent   // Collecting samples after the event
+	openAt   time.Time
+	events   []KnockEvent // History, oldest first
+	dirty    bool         // History changed since last saved
+	sampleAt time.Time
+}
+
+// update adds one broadcast tick. As with the knock map, new events are
+// the rise in the count, and a drop means the count was reset so the new
+// value is all new.
+func (k *knockRecorder) update(now time.Time, e *ecu.DataFrame) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if e == nil || e.RPM <= 500 {
+		k.finishLocked()
+		k.ring, k.counted = nil, false
+		return
+	}
+
+	events := int(e.KnockCount)
+	if e.KnockCount >= k.count {
+		events -= int(k.count)
+	}
+	if !k.counted {
+		events = 0 // The ECU's count may carry over from before
+	}
+	k.count, k.counted = e.KnockCount, true
+
+	if events > 0 || now.Sub(k.sampleAt) >= knockSampleEvery {
+		k.sampleAt = now
+		k.ring = append(k.ring, knockSample{at: now, KnockSample: KnockSample{
+			RPM: e.RPM, MAP: e.MAP, Advance: e.Advance, AFR: e.AFR, IAT: e.IAT,
+		}})
+		i := 0
+		for i < len(k.ring) && now.Sub(k.ring[i].at) > knockContext {
+			i++
+		}
+		k.ring = k.ring[i:]
+		if ev := k.open; ev != nil {
+			ev.Samples = append(ev.Samples, k.ring[len(k.ring)-1].KnockSample)
+			ev.Samples[len(ev.Samples)-1].T = now.Sub(k.openAt).Milliseconds()
+		}
+	}
+
+	switch {
+	case events > 0 && k.open != nil:
+		k.open.Count += events
+		k.open.Retard = max(k.open.Retard, e.KnockCor)
+	case events > 0:
+		k.open, k.openAt = &KnockEvent{
+			At: now.UnixMilli(), Count: events, Retard: e.KnockCor,
+			RPM: e.RPM, MAP: e.MAP, Advance: e.Advance, AFR: e.AFR, IAT: e.IAT,
+		}, now
+		for _, smp := range k.ring {
+			smp.T = smp.at.Sub(now).Milliseconds()
+			k.open.Samples = append(k.open.Samples, smp.KnockSample)
+		}
+		log.Printf("[knock] %d event(s) at %d RPM, %d kPa, %d° advance, AFR %.1f, IAT %.0f°C",
+			events, e.RPM, e.MAP, e.Advance, e.AFR, e.IAT)
+	case k.open != nil:
+		k.open.Retard = max(k.open.Retard, e.KnockCor)
+		if now.Sub(k.openAt) >= knockContext {
+			k.finishLocked()
+		}
+	}
+}
+
+// finishLocked adds the open event, if any, to the history.
+func (k *knockRecorder) finishLocked() {
+	if k.open == nil {
+		return
+	}
+	k.events = append(k.events, *k.open)
+	if n := len(k.events) - knockEventsMax; n > 0 {
+		k.events = append([]KnockEvent(nil), k.events[n:]...)
+	}
+	k.open, k.dirty = nil, true
+}
+
+// reset finishes any open event and starts counting afresh, for a new
+// drive.
+func (k *knockRecorder) reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.finishLocked()
+	k.ring, k.count, k.counted = nil, 0, false
+}
+
+// loadKnockEvents restores the knock event history from disk.
+func (s *Server) loadKnockEvents() {
+	var events []KnockEvent
+	if err := s.store.ReadJSON(knockEventsFile, &events); err != nil {
+		return
+	}
+	if n := len(events) - knockEventsMax; n > 0 {
+		events = events[n:]
+	}
+	s.knockLog.mu.Lock()
+	s.knockLog.events = events
+	s.knockLog.mu.Unlock()
+}
+
+// saveKnockEvents persists the knock event history, if it has changed.
+func (s *Server) saveKnockEvents() {
+	k := &s.knockLog
+	k.mu.Lock()
+	if !k.dirty {
+		k.mu.Unlock()
+		return
+	}
+	k.dirty = false
+	events := append([]KnockEvent(nil), k.events...)
+	k.mu.Unlock()
+	if err := s.store.WriteJSON(knockEventsFile, events); err != nil {
+		log.Printf("[knock] events save failed: %v", err)
+	}
+}
+
+// handleKnockEvents serves the knock event history.
+//
+//	GET    /api/knock/events[?limit=N] — events newest first, with context samples
+//	DELETE /api/knock/events           — clear the history
+func (s *Server) handleKnockEvents(w http.ResponseWriter, r *http.Request) {
+	k := &s.knockLog
+	switch r.Method {
+	case http.MethodGet:
+		limit := knockEventsMax
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, "limit must be a positive number", 400)
+				return
+			}
+			limit = n
+		}
+		k.mu.Lock()
+		events := make([]KnockEvent, 0, min(limit, len(k.events)))
+		for i := len(k.events) - 1; i >= 0 && len(events) < limit; i-- {
+			events = append(events, k.events[i])
+		}
+		k.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(events)
+
+	case http.MethodDelete:
+		k.mu.Lock()
+		k.events, k.dirty = nil, false
+		k.mu.Unlock()
+		if err := s.store.Remove(knockEventsFile); err != nil {
+			log.Printf("[knock] %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
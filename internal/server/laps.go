@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/laps"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// lapsFile is the lap list inside the data directory.
+const lapsFile = storage.DirRecords + "/laps.json"
+
+// lapTraceDir holds each lap's channel trace, one file per lap number.
+const lapTraceDir = storage.DirRecords + "/laps"
+
+func lapTraceFile(number int) string {
+	return lapTraceDir + "/" + strconv.Itoa(number) + ".json"
+}
+
+// configuredTrack is the name laps get when the line comes from config.
+const configuredTrack = "Configured"
+
+// loadLaps restores the lap list from disk.
+func (s *Server) loadLaps() {
+	var list []laps.Lap
+	if err := s.store.ReadJSON(lapsFile, &list); err != nil {
+		return
+	}
+	s.laps.Restore(list)
+	log.Printf("[laps] loaded %d laps", len(list))
+}
+
+// saveLaps persists the lap list.
+func (s *Server) saveLaps() {
+	if err := s.store.WriteJSON(lapsFile, s.laps.Laps()); err != nil {
+		log.Printf("[laps] save failed: %v", err)
+	}
+}
+
+// lapLines returns the start/finish line and sectors to time against:
+// the configured two-point line if there is one, else the active track's.
+func (s *Server) lapLines() (name string, sf *track.Line, sectors []track.Line) {
+	if cfg := s.cfg.LapsSnapshot(); len(cfg.StartFinish) >= 2 {
+		l := track.LineBetween(cfg.StartFinish[0], cfg.StartFinish[1])
+		return configuredTrack, &l, nil
+	}
+	if t := s.activeTrack(); t != nil && t.StartFinish != nil {
+		return t.Name, t.StartFinish, t.Sectors
+	}
+	return "", nil, nil
+}
+
+// lapSample collects the tick's position and trace channels.
+func lapSample(speed *SpeedData, e *ecu.DataFrame, g *gps.Data) laps.Sample {
+	smp := laps.Sample{Values: make(map[string]float64, len(laps.TraceChannels))}
+	if speed != nil {
+		smp.Values["speed"] = speed.Value
+	}
+	if e != nil {
+		smp.Values["rpm"] = float64(e.RPM)
+		smp.Values["tps"] = e.TPS
+		smp.Values["map"] = float64(e.MAP)
+		smp.Values["afr"] = e.AFR
+		smp.Values["gear"] = float64(e.Gear)
+	}
+	if g != nil && g.Valid {
+		smp.Pos = &track.Point{Lat: g.Latitude, Lon: g.Longitude}
+		smp.Heading = g.Heading
+		smp.Values["lat"] = g.Latitude
+		smp.Values["lon"] = g.Longitude
+	}
+	return smp
+}
+
+// updateLaps feeds the latest fix to the lap timer and returns the status
+// for the outgoing frame (nil with no line set and no laps recorded), and
+// the ghost lap comparison if one is set.
+func (s *Server) updateLaps(now time.Time, speed *SpeedData, e *ecu.DataFrame, g *gps.Data) (*laps.Status, *GhostData) {
+	name, sf, sectors := s.lapLines()
+
+	if lap, trace := s.laps.Update(now, lapSample(speed, e, g), name, sf, sectors); lap != nil {
+		log.Printf("[laps] %s lap %d: %.3fs", lap.Track, lap.Number, float64(lap.TimeMs)/1000)
+		s.saveLaps()
+		s.saveLapTrace(*lap, trace)
+	}
+
+	if sf == nil && !s.laps.Active() {
+		return nil, nil
+	}
+	st := s.laps.Status(now)
+	return &st, s.ghostData(now, name)
+}
+
+// saveLapTrace persists a completed lap's trace, drops the trace of the
+// lap that has just fallen off the list, and moves a best-lap ghost on
+// to it if it's the new best.
+func (s *Server) saveLapTrace(lap laps.Lap, trace *laps.Trace) {
+	if trace == nil {
+		return
+	}
+	if err := s.store.WriteJSON(lapTraceFile(lap.Number), trace); err != nil {
+		log.Printf("[laps] save trace failed: %v", err)
+	}
+	if old := lap.Number - laps.MaxLaps; old > 0 {
+		s.store.Remove(lapTraceFile(old))
+	}
+	s.ghost.lapDone(lap, trace)
+}
+
+// clearLapTraces deletes every saved lap trace.
+func (s *Server) clearLapTraces() {
+	names, err := s.store.List(lapTraceDir)
+	if err != nil {
+		log.Printf("[laps] list traces failed: %v", err)
+		return
+	}
+	for _, n := range names {
+		s.store.Remove(lapTraceDir + "/" + n)
+	}
+}
+
+// handleLaps serves lap timing.
+//
+//	GET    /api/laps — live status and every recorded lap
+//	DELETE /api/laps — clear the laps and start a new session
+func (s *Server) handleLaps(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		resp := struct {
+			laps.Status
+			Laps []laps.Lap `json:"laps"`
+		}{s.laps.Status(time.Now()), s.laps.Laps()}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+
+	case http.MethodDelete:
+		s.laps.Clear()
+		s.saveLaps()
+		s.clearLapTraces()
+		s.ghost.clear()
+		log.Printf("[laps] cleared")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
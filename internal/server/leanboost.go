@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+const (
+	leanBoostPost     = 5 * time.Second  // History kept after the alarm before the snapshot is taken
+	leanBoostSnapWait = 60 * time.Second // Between lean-boost snapshots
+)
+
+// leanBoostCheck raises a critical alarm when the engine runs leaner than
+// its AFR target by more than a margin under boost for several ECU frames
+// in a row — the condition that melts pistons. Unlike the other alerts it
+// always captures a snapshot bundle, with data from either side of the
+// event. Used from the broadcast loop only.
+type leanBoostCheck struct {
+	lastAt   time.Time // ECU frame last counted
+	lean     int       // Consecutive lean frames (while inactive) or good ones (while active)
+	active   bool
+	peak     leanBoostPeak
+	lastSnap time.Time
+}
+
+// leanBoostPeak is the leanest reading of an event.
+type leanBoostPeak struct {
+	afr, target float64
+	mapKPa      uint16
+	rpm         uint16
+}
+
+// checkLeanBoost counts the ECU frame received at ecuAt and returns the
+// alarm while raised. It clears after as many good frames as it took to
+// raise. A fresh alarm logs the event and schedules a snapshot.
+func (s *Server) checkLeanBoost(ecuAt time.Time, e *ecu.DataFrame, t ThresholdConfig) *Alert {
+	c := &s.leanBoost
+	if t.LeanBoostKPa <= 0 || e == nil {
+		c.lean, c.active = 0, false
+		return nil
+	}
+	if !ecuAt.Equal(c.lastAt) {
+		c.lastAt = ecuAt
+		frames := max(t.LeanBoostFrames, 1)
+		over := e.AFR - e.AFRTarget
+		lean := e.RPM > 500 && float64(e.MAP) >= t.LeanBoostKPa && e.AFRTarget > 0 && over > t.LeanBoostMargin
+		switch {
+		case lean && !c.active:
+			c.lean++
+			if c.lean == 1 || over > c.peak.afr-c.peak.target {
+				c.peak = leanBoostPeak{afr: e.AFR, target: e.AFRTarget, mapKPa: e.MAP, rpm: e.RPM}
+			}
+			if c.lean >= frames {
+				c.active, c.lean = true, 0
+				s.leanBoostEvent(c.peak, frames)
+			}
+		case lean:
+			c.lean = 0
+			if over > c.peak.afr-c.peak.target {
+				c.peak = leanBoostPeak{afr: e.AFR, target: e.AFRTarget, mapKPa: e.MAP, rpm: e.RPM}
+			}
+		case c.active:
+			c.lean++
+			if c.lean >= frames {
+				c.active, c.lean = false, 0
+			}
+		default:
+			c.lean = 0
+		}
+	}
+	if !c.active {
+		return nil
+	}
+	return &Alert{
+		ID:    "lean_boost",
+		Level: alertCritical,
+		Text:  fmt.Sprintf("LEAN UNDER BOOST %.1f / %.1f @ %d kPa", c.peak.afr, c.peak.target, c.peak.mapKPa),
+	}
+}
+
+// leanBoostEvent logs a fresh alarm and, at most once a minute, captures
+// a snapshot once leanBoostPost has passed, so the bundle's history holds
+// the run-up and what followed.
+func (s *Server) leanBoostEvent(p leanBoostPeak, frames int) {
+	reason := fmt.Sprintf("lean under boost: AFR %.1f vs target %.1f at %d kPa, %d RPM", p.afr, p.target, p.mapKPa, p.rpm)
+	log.Printf("[alerts] %s (%d frames)", reason, frames)
+	now := time.Now()
+	c := &s.leanBoost
+	if !c.lastSnap.IsZero() && now.Sub(c.lastSnap) < leanBoostSnapWait {
+		return
+	}
+	c.lastSnap = now
+	go func() {
+		time.Sleep(leanBoostPost)
+		if _, err := s.captureSnapshot(reason); err != nil {
+			log.Printf("[snapshot] capture failed: %v", err)
+		}
+	}()
+}
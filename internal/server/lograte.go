@@ -0,0 +1,202 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// logRateCheck is how often the logging schedule is re-evaluated.
+const logRateCheck = 5 * time.Second
+
+// logRate switches the CSV log between its full rate and
+// logging.schedule.slow_interval_ms. Used from the broadcast loop only.
+type logRate struct {
+	checked time.Time
+	full    bool
+	known   bool            // full has been applied
+	pos     *track.Point    // Last fix, for sunrise and sunset
+	warned  map[string]bool // Bad window times already logged
+}
+
+// updateLogRate re-evaluates the schedule every logRateCheck and applies
+// any change to the logger.
+func (s *Server) updateLogRate(now time.Time, g *gps.Data) {
+	lr := &s.logRate
+	if g != nil && g.Valid {
+		lr.pos = &track.Point{Lat: g.Latitude, Lon: g.Longitude}
+	}
+	if now.Sub(lr.checked) < logRateCheck {
+		return
+	}
+	lr.checked = now
+	cfg := s.cfg.LogScheduleSnapshot()
+	full, why := true, "no schedule"
+	if cfg.SlowIntervalMs > 0 {
+		full, why = s.logFullRate(now, g, cfg)
+	}
+	if lr.known && full == lr.full {
+		return
+	}
+	lr.full, lr.known = full, true
+	if full {
+		s.logger.SetSlowInterval(0)
+		if cfg.SlowIntervalMs > 0 {
+			log.Printf("[logger] full rate: %s", why)
+		}
+		return
+	}
+	s.logger.SetSlowInterval(time.Duration(cfg.SlowIntervalMs) * time.Millisecond)
+	log.Printf("[logger] slow rate (%d ms): %s", cfg.SlowIntervalMs, why)
+}
+
+// logFullRate reports whether the schedule calls for full-rate logging
+// now, and why.
+func (s *Server) logFullRate(now time.Time, g *gps.Data, cfg LogScheduleConfig) (bool, string) {
+	if cfg.OnTrack && g != nil && g.Valid {
+		if t := s.activeTrack(); t != nil {
+			d := track.DistanceM(track.Point{Lat: g.Latitude, Lon: g.Longitude}, t.Location())
+			if d <= s.cfg.TracksSnapshot().DetectRadiusM {
+				return true, "at " + t.Name
+			}
+		}
+	}
+	for i, w := range cfg.Windows {
+		in, err := s.logRate.inWindow(now, w)
+		if err != nil {
+			if key := fmt.Sprint(i, w); !s.logRate.warned[key] {
+				if s.logRate.warned == nil {
+					s.logRate.warned = make(map[string]bool)
+				}
+				s.logRate.warned[key] = true
+				log.Printf("[logger] schedule window %d: %v", i+1, err)
+			}
+			continue
+		}
+		if in {
+			return true, "window " + w.Start + "–" + w.End
+		}
+	}
+	return false, "outside the schedule"
+}
+
+// inWindow reports whether now falls in w, on one of its days. A window
+// ending before it starts runs past midnight, and counts as the day it
+// started.
+func (lr *logRate) inWindow(now time.Time, w LogWindow) (bool, error) {
+	start, err := lr.windowTime(now, w.Start)
+	if err != nil {
+		return false, err
+	}
+	end, err := lr.windowTime(now, w.End)
+	if err != nil {
+		return false, err
+	}
+	day := now
+	switch {
+	case !end.Before(start):
+		if now.Before(start) || !now.Before(end) {
+			return false, nil
+		}
+	case !now.Before(start):
+	case now.Before(end):
+		day = now.AddDate(0, 0, -1) // Started yesterday
+	default:
+		return false, nil
+	}
+	return onDay(day, w.Days)
+}
+
+// windowTime is a window bound on now's day: "HH:MM", or "sunrise" or
+// "sunset" with an optional offset such as "sunset-30m". Sunrise and
+// sunset need a GPS fix since startup.
+func (lr *logRate) windowTime(now time.Time, spec string) (time.Time, error) {
+	y, m, d := now.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	name, offset := spec, time.Duration(0)
+	if i := strings.IndexAny(spec, "+-"); i > 0 {
+		var err error
+		if offset, err = time.ParseDuration(spec[i:]); err != nil {
+			return time.Time{}, fmt.Errorf("bad offset in %q", spec)
+		}
+		name = spec[:i]
+	}
+	switch name {
+	case "sunrise", "sunset":
+		if lr.pos == nil {
+			return time.Time{}, fmt.Errorf("%s needs a GPS fix", name)
+		}
+		rise, set, ok := sunTimes(midnight, lr.pos.Lat, lr.pos.Lon)
+		if !ok {
+			return time.Time{}, fmt.Errorf("no %s today at this latitude", name)
+		}
+		if name == "sunrise" {
+			return rise.Add(offset), nil
+		}
+		return set.Add(offset), nil
+	}
+	hh, mm, ok := strings.Cut(spec, ":")
+	h, err1 := strconv.Atoi(hh)
+	mi, err2 := strconv.Atoi(mm)
+	if !ok || err1 != nil || err2 != nil || h < 0 || h > 24 || mi < 0 || mi > 59 {
+		return time.Time{}, fmt.Errorf("bad time %q (want HH:MM, sunrise or sunset)", spec)
+	}
+	return midnight.Add(time.Duration(h)*time.Hour + time.Duration(mi)*time.Minute), nil
+}
+
+// onDay reports whether t falls on one of days ("mon" … "sun"); none
+// means every day.
+func onDay(t time.Time, days []string) (bool, error) {
+	if len(days) == 0 {
+		return true, nil
+	}
+	for _, d := range days {
+		wd, ok := weekdays[strings.ToLower(d)]
+		if !ok {
+			return false, fmt.Errorf("bad day %q", d)
+		}
+		if wd == t.Weekday() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// sunTimes returns sunrise and sunset on day's date at lat, lon (degrees,
+// east positive), in day's location, to within a minute or two. ok is
+// false when the sun doesn't rise or set that day.
+func sunTimes(day time.Time, lat, lon float64) (rise, set time.Time, ok bool) {
+	const rad = math.Pi / 180
+	j2000 := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	y, m, d := day.Date()
+	n := math.Round(time.Date(y, m, d, 12, 0, 0, 0, time.UTC).Sub(j2000).Hours() / 24)
+
+	noon := n - lon/360 // Mean solar noon, days since J2000
+	anomaly := math.Mod(357.5291+0.98560028*noon, 360) * rad
+	center := 1.9148*math.Sin(anomaly) + 0.02*math.Sin(2*anomaly) + 0.0003*math.Sin(3*anomaly)
+	eclLon := math.Mod(anomaly/rad+center+180+102.9372, 360) * rad
+	transit := noon + 0.0053*math.Sin(anomaly) - 0.0069*math.Sin(2*eclLon)
+	decl := math.Asin(math.Sin(eclLon) * math.Sin(23.4397*rad))
+
+	// Hour angle of the sun's upper limb at the horizon, with refraction
+	cosH := (math.Sin(-0.833*rad) - math.Sin(lat*rad)*math.Sin(decl)) / (math.Cos(lat*rad) * math.Cos(decl))
+	if cosH < -1 || cosH > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	h := math.Acos(cosH) / rad / 360
+	at := func(days float64) time.Time {
+		return j2000.Add(time.Duration(days * 24 * float64(time.Hour))).In(day.Location())
+	}
+	return at(transit - h), at(transit + h), true
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// loopWindow is how many recent intervals each loop's rate and jitter
+// are worked out over.
+const loopWindow = 256
+
+// loopLate is how far over its intended interval a loop may run before
+// the interval counts as a missed tick.
+const loopLate = 1.5
+
+// LoopTiming is how closely a periodic loop (ECU poll, GPS read,
+// broadcast) keeps to its configured rate. Rates and intervals cover the
+// last loopWindow intervals; the counts and MaxMs are since start.
+type LoopTiming struct {
+	TargetHz float64 `json:"targetHz"`
+	ActualHz float64 `json:"actualHz"` // From the mean interval
+	MeanMs   float64 `json:"meanMs"`   // Mean interval
+	JitterMs float64 `json:"jitterMs"` // Standard deviation of the interval
+	P99Ms    float64 `json:"p99Ms"`    // 99th percentile interval
+	MaxMs    float64 `json:"maxMs"`    // Longest interval
+	Ticks    uint64  `json:"ticks"`
+	Late     uint64  `json:"late"` // Intervals over 1.5× the target
+}
+
+// loopTimer records the intervals between one loop's iterations.
+type loopTimer struct {
+	mu     sync.Mutex
+	target time.Duration
+	last   time.Time // Zero after a pause
+	ring   [loopWindow]time.Duration
+	n      int // Intervals in ring
+	next   int // Ring slot for the next interval
+	ticks  uint64
+	late   uint64
+	max    time.Duration
+}
+
+// tick records an iteration starting at now.
+func (t *loopTimer) tick(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ticks++
+	if !t.last.IsZero() {
+		d := now.Sub(t.last)
+		t.ring[t.next] = d
+		t.next = (t.next + 1) % loopWindow
+		t.n = min(t.n+1, loopWindow)
+		t.max = max(t.max, d)
+		if float64(d) > loopLate*float64(t.target) {
+			t.late++
+		}
+	}
+	t.last = now
+}
+
+// pause stops the time until the next tick counting as an interval, for
+// a loop that idles on purpose (engine off, reconnecting).
+func (t *loopTimer) pause() {
+	t.mu.Lock()
+	t.last = time.Time{}
+	t.mu.Unlock()
+}
+
+// snapshot summarises the recorded intervals.
+func (t *loopTimer) snapshot() LoopTiming {
+	t.mu.Lock()
+	out := LoopTiming{
+		TargetHz: float64(time.Second) / float64(t.target),
+		MaxMs:    loopMs(t.max),
+		Ticks:    t.ticks,
+		Late:     t.late,
+	}
+	recent := append([]time.Duration(nil), t.ring[:t.n]...)
+	t.mu.Unlock()
+	if len(recent) == 0 {
+		return out
+	}
+
+	var sum float64
+	for _, d := range recent {
+		sum += d.Seconds() * 1000
+	}
+	mean := sum / float64(len(recent))
+	var sq float64
+	for _, d := range recent {
+		dev := d.Seconds()*1000 - mean
+		sq += dev * dev
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i] < recent[j] })
+	out.MeanMs = round2(mean)
+	out.JitterMs = round2(math.Sqrt(sq / float64(len(recent))))
+	out.P99Ms = loopMs(recent[(len(recent)*99)/100])
+	if mean > 0 {
+		out.ActualHz = round2(1000 / mean)
+	}
+	return out
+}
+
+// loopMs is d in milliseconds, to 0.01 ms.
+func loopMs(d time.Duration) float64 {
+	return round2(d.Seconds() * 1000)
+}
+
+// round2 rounds v to two decimal places.
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+// loopTimers is every timed loop, by name: "ecu", "ecu:<name>", "gps"
+// and "broadcast".
+type loopTimers struct {
+	mu     sync.Mutex
+	timers map[string]*loopTimer
+}
+
+// add returns a timer for the loop name running at hz.
+func (l *loopTimers) add(name string, hz int) *loopTimer {
+	t := &loopTimer{target: time.Second / time.Duration(max(hz, 1))}
+	l.mu.Lock()
+	if l.timers == nil {
+		l.timers = make(map[string]*loopTimer)
+	}
+	l.timers[name] = t
+	l.mu.Unlock()
+	return t
+}
+
+// snapshot returns every loop's timing.
+func (l *loopTimers) snapshot() map[string]LoopTiming {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]LoopTiming, len(l.timers))
+	for name, t := range l.timers {
+		out[name] = t.snapshot()
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// metricsWriter builds a Prometheus text exposition.
+type metricsWriter struct {
+	b bytes.Buffer
+}
+
+// family starts a metric family; its samples must follow.
+func (m *metricsWriter) family(name, typ, help string) {
+	m.b.WriteString("# HELP " + name + " " + help + "\n")
+	m.b.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// sample writes one sample, with labels as name, value pairs.
+func (m *metricsWriter) sample(name string, v float64, labels ...string) {
+	m.b.WriteString(name)
+	if len(labels) > 0 {
+		m.b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.b.WriteByte(',')
+			}
+			m.b.WriteString(labels[i] + `="` + labelEscaper.Replace(labels[i+1]) + `"`)
+		}
+		m.b.WriteByte('}')
+	}
+	m.b.WriteByte(' ')
+	switch {
+	case math.IsNaN(v):
+		m.b.WriteString("NaN")
+	case math.IsInf(v, 1):
+		m.b.WriteString("+Inf")
+	case math.IsInf(v, -1):
+		m.b.WriteString("-Inf")
+	default:
+		m.b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	m.b.WriteByte('\n')
+}
+
+// gauge writes a family with a single unlabelled sample.
+func (m *metricsWriter) gauge(name, help string, v float64) {
+	m.family(name, "gauge", help)
+	m.sample(name, v)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// handleMetrics exposes the latest engine data and the dash's own health
+// (serial links, loop timing, WebSocket delivery) in the Prometheus text
+// format. Engine channels are left out while the ECU data is stale, so
+// graphs show gaps rather than a flat line.
+//
+//	GET /metrics
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	var m metricsWriter
+
+	if in := s.gauges.latest.Load(); in != nil {
+		if e := in.ecu; e != nil {
+			m.family("goefidash_ecu_channel", "gauge", "Latest ECU channel value, by DataFrame JSON name.")
+			for _, name := range ecu.ChannelNames() {
+				if !e.Channels.Has(name) {
+					continue
+				}
+				if v, ok := e.Value(name); ok {
+					m.sample("goefidash_ecu_channel", v, "channel", name)
+				}
+			}
+			if len(e.Aux) > 0 {
+				names := make([]string, 0, len(e.Aux))
+				for name := range e.Aux {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				m.family("goefidash_aux_channel", "gauge", "Latest aux channel value, from the ECU or a Pi-attached sensor.")
+				for _, name := range names {
+					m.sample("goefidash_aux_channel", e.Aux[name], "channel", name)
+				}
+			}
+		}
+		m.gauge("goefidash_speed_kph", "Fused vehicle speed in km/h.", in.speed)
+		m.gauge("goefidash_gps_fix", "1 while the GPS has a fix.", boolMetric(in.gps))
+	}
+
+	provs := s.providerDiags()
+	names := make([]string, 0, len(provs))
+	for name := range provs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	m.family("goefidash_provider_connected", "gauge", "1 while the provider is connected.")
+	for _, name := range names {
+		if c := provs[name].Connected; c != nil {
+			m.sample("goefidash_provider_connected", boolMetric(*c), "provider", name)
+		}
+	}
+	links := []struct {
+		name, typ, help string
+		v               func(p providerDiag) float64
+	}{
+		{"goefidash_link_requests_total", "counter", "Polls sent or reads attempted.", func(p providerDiag) float64 { return float64(p.Link.Requests) }},
+		{"goefidash_link_responses_total", "counter", "Good responses.", func(p providerDiag) float64 { return float64(p.Link.Responses) }},
+		{"goefidash_link_timeouts_total", "counter", "Requests with no complete response in time.", func(p providerDiag) float64 { return float64(p.Link.Timeouts) }},
+		{"goefidash_link_crc_errors_total", "counter", "Responses that failed their CRC or checksum.", func(p providerDiag) float64 { return float64(p.Link.CRCErrors) }},
+		{"goefidash_link_errors_total", "counter", "Other protocol or I/O errors.", func(p providerDiag) float64 { return float64(p.Link.Errors) }},
+		{"goefidash_link_reconnects_total", "counter", "Connects after the first.", func(p providerDiag) float64 { return float64(p.Link.Reconnects) }},
+		{"goefidash_link_rtt_seconds", "gauge", "Moving average poll round trip.", func(p providerDiag) float64 { return p.Link.AvgRTTMs / 1000 }},
+	}
+	for _, l := range links {
+		m.family(l.name, l.typ, l.help)
+		for _, name := range names {
+			if p := provs[name]; p.Link != nil {
+				m.sample(l.name, l.v(p), "provider", name)
+			}
+		}
+	}
+
+	loops := s.loops.snapshot()
+	loopNames := make([]string, 0, len(loops))
+	for name := range loops {
+		loopNames = append(loopNames, name)
+	}
+	sort.Strings(loopNames)
+	loopMetrics := []struct {
+		name, typ, help string
+		v               func(t LoopTiming) float64
+	}{
+		{"goefidash_loop_rate_hz", "gauge", "Loop rate over its recent intervals.", func(t LoopTiming) float64 { return t.ActualHz }},
+		{"goefidash_loop_target_hz", "gauge", "Loop's configured rate.", func(t LoopTiming) float64 { return t.TargetHz }},
+		{"goefidash_loop_jitter_seconds", "gauge", "Standard deviation of the loop interval.", func(t LoopTiming) float64 { return t.JitterMs / 1000 }},
+		{"goefidash_loop_interval_p99_seconds", "gauge", "99th percentile loop interval.", func(t LoopTiming) float64 { return t.P99Ms / 1000 }},
+		{"goefidash_loop_ticks_total", "counter", "Loop iterations.", func(t LoopTiming) float64 { return float64(t.Ticks) }},
+		{"goefidash_loop_late_total", "counter", "Loop intervals over 1.5 times the target.", func(t LoopTiming) float64 { return float64(t.Late) }},
+	}
+	for _, l := range loopMetrics {
+		m.family(l.name, l.typ, l.help)
+		for _, name := range loopNames {
+			m.sample(l.name, l.v(loops[name]), "loop", name)
+		}
+	}
+
+	clients, reduced := s.clientCounts()
+	m.gauge("goefidash_ws_clients", "Connected WebSocket and SSE clients.", float64(clients))
+	m.gauge("goefidash_ws_reduced_clients", "WebSocket and SSE clients stepped down to a lower frame rate.", float64(reduced))
+	m.family("goefidash_ws_frames_sent_total", "counter", "Frames queued to WebSocket and SSE clients.")
+	m.sample("goefidash_ws_frames_sent_total", float64(s.ws.sent.Load()))
+	m.family("goefidash_ws_frames_dropped_total", "counter", "Stale frames dropped from a full client queue for a newer one.")
+	m.sample("goefidash_ws_frames_dropped_total", float64(s.ws.dropped.Load()))
+	m.gauge("goefidash_ws_frame_bytes", "Size of the last broadcast frame.", float64(s.ws.lastBytes.Load()))
+	m.gauge("goefidash_uptime_seconds", "Time since the dash started.", time.Since(s.started).Seconds())
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(m.b.Bytes())
+}
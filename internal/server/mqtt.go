@@ -0,0 +1,292 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	telemetryRetry   = 5 * time.Second  // Between attempts to reach the broker
+	telemetryTimeout = 10 * time.Second // Connecting, and each write
+	telemetryPing    = 30 * time.Second // PINGREQ interval; half the CONNECT keepalive
+)
+
+// runTelemetry publishes the configured channels to the telemetry broker
+// until ctx ends, reconnecting as the car's link to it comes and goes.
+// Values are sent fresh at each tick rather than queued while the link is
+// down: a phone hotspot dropping for a minute shouldn't replay a minute of
+// old data.
+func (s *Server) runTelemetry(ctx context.Context) {
+	cfg := s.cfg.TelemetrySnapshot()
+	if cfg.QoS < 0 || cfg.QoS > 1 {
+		log.Printf("[telemetry] qos %d unsupported, using 1", cfg.QoS)
+		cfg.QoS = 1
+	}
+	logged := false
+	for {
+		connected, err := s.telemetrySession(ctx, cfg)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected || !logged {
+			log.Printf("[telemetry] %s: %v (retrying every %v)", cfg.Broker, err, telemetryRetry)
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(telemetryRetry):
+		}
+	}
+}
+
+// telemetrySession connects once and publishes until the connection fails
+// or ctx ends. It reports whether it got as far as connecting.
+func (s *Server) telemetrySession(ctx context.Context, cfg TelemetryConfig) (bool, error) {
+	topic := strings.TrimSuffix(cfg.Topic, "/")
+	if topic == "" {
+		topic = "goefidash"
+	}
+	clientID := cfg.ClientID
+	if clientID == "" {
+		// Not the alert notifier's ID: the broker would drop this session
+		// each time it connected
+		host, _ := os.Hostname()
+		clientID = "goefidash-" + host + "-telemetry"
+	}
+	qos := byte(cfg.QoS)
+	will := &mqttWill{topic: topic + "/status", payload: []byte("offline"), qos: qos, retain: true}
+
+	dctx, cancel := context.WithTimeout(ctx, telemetryTimeout)
+	conn, err := mqttDial(dctx, cfg.Broker, mqttConnect(clientID, cfg.Username, cfg.Password, will))
+	cancel()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Time{})
+	log.Printf("[telemetry] connected to %s, publishing under %s/", cfg.Broker, topic)
+
+	// Acks and ping responses are read and discarded; the broker answers
+	// every ping, so hearing nothing for two means the link is gone
+	readErr := make(chan error, 1)
+	go func() { readErr <- mqttDrain(conn, 2*telemetryPing) }()
+
+	var id uint16
+	publish := func(name string, payload []byte, retain bool) error {
+		if qos > 0 {
+			if id++; id == 0 {
+				id = 1
+			}
+		}
+		conn.SetWriteDeadline(time.Now().Add(telemetryTimeout))
+		_, err := conn.Write(mqttPublish(topic+"/"+name, payload, qos, retain, id))
+		return err
+	}
+	if err := publish("status", []byte("online"), true); err != nil {
+		return true, err
+	}
+
+	rate := cfg.RateHz
+	if rate <= 0 {
+		rate = 1
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+	ping := time.NewTicker(telemetryPing)
+	defer ping.Stop()
+	var lastFrame time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			// A clean disconnect doesn't trigger the will
+			publish("status", []byte("offline"), true)
+			conn.Write([]byte{0xE0, 0}) // DISCONNECT
+			return true, nil
+		case err := <-readErr:
+			return true, err
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(telemetryTimeout))
+			if _, err := conn.Write([]byte{0xC0, 0}); err != nil { // PINGREQ
+				return true, err
+			}
+		case <-ticker.C:
+			if in := s.gauges.latest.Load(); in != nil {
+				for _, ch := range cfg.Channels {
+					v, ok := in.value(ch)
+					if !ok {
+						continue
+					}
+					if err := publish(ch, strconv.AppendFloat(nil, v, 'f', -1, 64), cfg.Retain); err != nil {
+						return true, err
+					}
+				}
+			}
+			if cfg.Frame {
+				if data, at := s.history.latest(); data != nil && at.After(lastFrame) {
+					lastFrame = at
+					if err := publish("frame", data, cfg.Retain); err != nil {
+						return true, err
+					}
+				}
+			}
+		}
+	}
+}
+
+// mqttDial connects to broker and sends connect, returning the connection
+// once the broker accepts it. ctx bounds the whole handshake; the
+// connection keeps its deadline.
+func mqttDial(ctx context.Context, broker string, connect []byte) (net.Conn, error) {
+	addr, useTLS, err := mqttAddr(broker)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	if useTLS {
+		conn, err = (&tls.Dialer{}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(connect); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	var ack [4]byte
+	if _, err := io.ReadFull(conn, ack[:]); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connack: %w", err)
+	}
+	if ack[0] != 0x20 || ack[1] != 2 {
+		conn.Close()
+		return nil, fmt.Errorf("connack: unexpected packet % x", ack)
+	}
+	if ack[3] != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("connect refused (code %d)", ack[3])
+	}
+	return conn, nil
+}
+
+// mqttDrain reads and discards packets until the connection fails or
+// nothing arrives for timeout.
+func mqttDrain(conn net.Conn, timeout time.Duration) error {
+	buf := make([]byte, 256)
+	for {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		if _, err := conn.Read(buf); err != nil {
+			return err
+		}
+	}
+}
+
+// mqttAddr turns a configured broker into host:port and whether to use
+// TLS: "host" and "tcp://host" dial 1883, "tls://host" or "ssl://host"
+// dial 8883.
+func mqttAddr(broker string) (string, bool, error) {
+	useTLS := false
+	if scheme, rest, ok := strings.Cut(broker, "://"); ok {
+		switch scheme {
+		case "tcp", "mqtt":
+		case "tls", "ssl", "mqtts":
+			useTLS = true
+		default:
+			return "", false, fmt.Errorf("mqtt: unknown scheme %q", scheme)
+		}
+		broker = rest
+	}
+	broker = strings.TrimSuffix(broker, "/")
+	if _, _, err := net.SplitHostPort(broker); err != nil {
+		port := "1883"
+		if useTLS {
+			port = "8883"
+		}
+		broker = net.JoinHostPort(broker, port)
+	}
+	return broker, useTLS, nil
+}
+
+// mqttWill is the message the broker publishes for a client that drops
+// off without disconnecting.
+type mqttWill struct {
+	topic   string
+	payload []byte
+	qos     byte
+	retain  bool
+}
+
+// mqttConnect is a CONNECT packet for a clean session, with an optional
+// will.
+func mqttConnect(clientID, user, pass string, will *mqttWill) []byte {
+	flags := byte(0x02) // Clean session
+	payload := mqttString(clientID)
+	if will != nil {
+		flags |= 0x04 | will.qos<<3
+		if will.retain {
+			flags |= 0x20
+		}
+		payload = append(payload, mqttString(will.topic)...)
+		payload = append(payload, mqttString(string(will.payload))...)
+	}
+	if user != "" {
+		flags |= 0x80
+		payload = append(payload, mqttString(user)...)
+		if pass != "" {
+			flags |= 0x40
+			payload = append(payload, mqttString(pass)...)
+		}
+	}
+	body := append(mqttString("MQTT"), 4, flags, 0, 60) // Level 4 (3.1.1), 60 s keepalive
+	return mqttPacket(0x10, append(body, payload...))
+}
+
+// mqttPublish is a PUBLISH packet; id is the packet identifier, only
+// sent with QoS 1 or 2.
+func mqttPublish(topic string, payload []byte, qos byte, retain bool, id uint16) []byte {
+	typ := 0x30 | qos<<1
+	if retain {
+		typ |= 0x01
+	}
+	body := mqttString(topic)
+	if qos > 0 {
+		body = append(body, byte(id>>8), byte(id))
+	}
+	return mqttPacket(typ, append(body, payload...))
+}
+
+// mqttPacket prefixes body with the fixed header.
+func mqttPacket(typ byte, body []byte) []byte {
+	out := []byte{typ}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+// mqttString is a length-prefixed UTF-8 string.
+func mqttString(s string) []byte {
+	return append([]byte{byte(len(s) >> 8), byte(len(s))}, s...)
+}
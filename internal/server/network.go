@@ -0,0 +1,171 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// networkPoll is how often network status is refreshed; it's also sent
+// in a frame this often.
+const networkPoll = 5 * time.Second
+
+// WiFi modes.
+const (
+	WiFiHotspot      = "hotspot"      // Access point for phones
+	WiFiClient       = "client"       // Joined a network
+	WiFiDisconnected = "disconnected" // Client mode, no network
+	WiFiOff          = "off"          // Interface down or missing
+)
+
+// WiFiStatus is the WiFi interface's state.
+type WiFiStatus struct {
+	Interface string `json:"interface,omitempty"`
+	Mode      string `json:"mode"`
+	SSID      string `json:"ssid,omitempty"`
+	Stations  *int   `json:"stations,omitempty"` // Connected phones (hotspot only)
+	Error     string `json:"error,omitempty"`    // Why mode or SSID couldn't be read
+}
+
+// NetInterface is one network interface that's up.
+type NetInterface struct {
+	Name  string   `json:"name"`
+	Addrs []string `json:"addrs"` // CIDR
+}
+
+// NetworkStatus tells the dash how phones can reach it.
+type NetworkStatus struct {
+	WiFi       WiFiStatus     `json:"wifi"`
+	Interfaces []NetInterface `json:"interfaces"`
+	Stamp      int64          `json:"stamp"` // Unix ms
+}
+
+// runNetwork refreshes the network status every networkPoll.
+func (s *Server) runNetwork(ctx context.Context) {
+	t := time.NewTicker(networkPoll)
+	defer t.Stop()
+	for {
+		st := readNetworkStatus(s.cfg.SystemSnapshot().Hotspot.Interface)
+		s.network.Store(&st)
+		s.networkNew.Store(true)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// networkFrame returns the status once after each refresh, for the next
+// broadcast frame, and nil otherwise.
+func (s *Server) networkFrame() *NetworkStatus {
+	if !s.networkNew.Swap(false) {
+		return nil
+	}
+	return s.network.Load()
+}
+
+func readNetworkStatus(wifiIface string) NetworkStatus {
+	st := NetworkStatus{Interfaces: []NetInterface{}, Stamp: time.Now().UnixMilli()}
+	ifaces, _ := net.Interfaces()
+	for _, ifc := range ifaces {
+		if ifc.Flags&net.FlagUp == 0 || ifc.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ni := NetInterface{Name: ifc.Name, Addrs: []string{}}
+		addrs, _ := ifc.Addrs()
+		for _, a := range addrs {
+			if ipn, ok := a.(*net.IPNet); ok && !ipn.IP.IsLinkLocalUnicast() {
+				ni.Addrs = append(ni.Addrs, ipn.String())
+			}
+		}
+		st.Interfaces = append(st.Interfaces, ni)
+	}
+
+	if wifiIface == "" {
+		wifiIface = firstWireless()
+	}
+	st.WiFi = readWiFi(wifiIface)
+	return st
+}
+
+// firstWireless returns the first wireless interface, or "".
+func firstWireless() string {
+	dirs, _ := filepath.Glob("/sys/class/net/*/wireless")
+	sort.Strings(dirs)
+	if len(dirs) == 0 {
+		return ""
+	}
+	return filepath.Base(filepath.Dir(dirs[0]))
+}
+
+// readWiFi reads an interface's mode, SSID and stations with iw.
+func readWiFi(iface string) WiFiStatus {
+	w := WiFiStatus{Interface: iface, Mode: WiFiOff}
+	if iface == "" {
+		w.Error = "no wireless interface"
+		return w
+	}
+	if state, err := os.ReadFile("/sys/class/net/" + iface + "/operstate"); err != nil {
+		w.Error = "interface not found"
+		return w
+	} else if strings.TrimSpace(string(state)) == "down" {
+		return w
+	}
+
+	info, err := runCommand("iw", "dev", iface, "info")
+	if err != nil {
+		w.Error = err.Error()
+		return w
+	}
+	var kind string
+	sc := bufio.NewScanner(strings.NewReader(info))
+	for sc.Scan() {
+		key, val, _ := strings.Cut(strings.TrimSpace(sc.Text()), " ")
+		switch key {
+		case "ssid":
+			w.SSID = val
+		case "type":
+			kind = val
+		}
+	}
+
+	switch {
+	case kind == "AP":
+		w.Mode = WiFiHotspot
+		if dump, err := runCommand("iw", "dev", iface, "station", "dump"); err == nil {
+			n := strings.Count(dump, "Station ")
+			w.Stations = &n
+		}
+	case w.SSID != "":
+		w.Mode = WiFiClient
+	default:
+		w.Mode = WiFiDisconnected
+	}
+	return w
+}
+
+// handleNetwork reports network status: WiFi mode, SSID, connected
+// stations and addresses.
+//
+//	GET /api/network
+func (s *Server) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	st := s.network.Load()
+	if st == nil {
+		fresh := readNetworkStatus(s.cfg.SystemSnapshot().Hotspot.Interface)
+		st = &fresh
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
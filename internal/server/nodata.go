@@ -0,0 +1,48 @@
+package server
+
+import (
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/diag"
+)
+
+// heartbeatInterval is how often a noData frame goes out while neither
+// the ECU nor GPS has fresh data, so clients can tell "link lost" apart
+// from "dashboard gone".
+const heartbeatInterval = time.Second
+
+// gpsUpdatedAt returns when the GPS last delivered real data. Providers
+// with link statistics report their last good sentence; NMEA's Read keeps
+// returning the previous fix when the receiver goes quiet, so a
+// successful Read alone doesn't mean fresh data.
+func (s *Server) gpsUpdatedAt() time.Time {
+	if r, ok := s.gpsProv.(diag.Reporter); ok {
+		if ms := r.Diagnostics().LastResponseAt; ms > 0 {
+			return time.UnixMilli(ms)
+		}
+		return time.Time{}
+	}
+	s.gpsMu.Lock()
+	defer s.gpsMu.Unlock()
+	return s.lastGPSAt
+}
+
+// stale reports whether data last seen at t is older than limit. A zero
+// limit disables the check; data never seen is always stale.
+func stale(now, t time.Time, limit time.Duration) bool {
+	if t.IsZero() {
+		return true
+	}
+	return limit > 0 && now.Sub(t) > limit
+}
+
+// lastDataAt returns the later of the two times as Unix ms, or 0.
+func lastDataAt(a, b time.Time) int64 {
+	if b.After(a) {
+		a = b
+	}
+	if a.IsZero() {
+		return 0
+	}
+	return a.UnixMilli()
+}
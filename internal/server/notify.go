@@ -0,0 +1,246 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	notifyQueue   = 64               // Events waiting to be sent; more are dropped
+	notifyTimeout = 10 * time.Second // Per attempt, per target
+	notifyTries   = 3                // Attempts per target, notifyTimeout apart
+)
+
+// notifyClient sends webhook and Telegram requests.
+var notifyClient = &http.Client{Timeout: notifyTimeout}
+
+// queueNotifyLocked hands ev to the notifier without blocking the
+// broadcast loop. Called with alertMu held.
+func (s *Server) queueNotifyLocked(ev AlertEvent) {
+	select {
+	case s.notify <- ev:
+	default:
+		log.Printf("[notify] queue full, dropping %s %s", ev.ID, ev.Event)
+	}
+}
+
+// runNotify sends alert events to the configured targets as they come. The
+// settings are read per event, so targets changed in settings apply at
+// once.
+func (s *Server) runNotify(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-s.notify:
+			cfg := s.cfg.NotifySnapshot()
+			if !cfg.Enabled() || !notifyWanted(cfg, ev) {
+				continue
+			}
+			s.sendNotify(ctx, cfg, ev, notifyTries)
+		}
+	}
+}
+
+// notifyWanted reports whether ev passes cfg's level and ends filters.
+func notifyWanted(cfg NotifyConfig, ev AlertEvent) bool {
+	if ev.Event == "end" && !cfg.Ends {
+		return false
+	}
+	return alertRank(ev.Level) >= alertRank(cfg.Level)
+}
+
+// alertRank orders alert levels; unknown ones rank as warnings.
+func alertRank(level string) int {
+	switch level {
+	case alertCritical:
+		return 2
+	case alertDanger:
+		return 1
+	}
+	return 0
+}
+
+// sendNotify delivers ev to every configured target, making up to tries
+// attempts at each: a phone hotspot in the paddock comes and goes.
+func (s *Server) sendNotify(ctx context.Context, cfg NotifyConfig, ev AlertEvent, tries int) error {
+	name := s.cfg.IdentitySnapshot().Name
+	type target struct {
+		name string
+		send func(context.Context) error
+	}
+	var targets []target
+	if cfg.Webhook != "" {
+		targets = append(targets, target{"webhook", func(ctx context.Context) error {
+			return sendWebhook(ctx, cfg.Webhook, name, ev)
+		}})
+	}
+	if cfg.MQTT.Broker != "" {
+		targets = append(targets, target{"mqtt", func(ctx context.Context) error {
+			return publishMQTT(ctx, cfg.MQTT, name, ev)
+		}})
+	}
+	if cfg.Telegram.BotToken != "" && cfg.Telegram.ChatID != "" {
+		targets = append(targets, target{"telegram", func(ctx context.Context) error {
+			return sendTelegram(ctx, cfg.Telegram, notifyText(name, ev))
+		}})
+	}
+
+	var errs []error
+	for _, t := range targets {
+		var err error
+		for try := 1; try <= tries; try++ {
+			if err = t.send(ctx); err == nil {
+				break
+			}
+			log.Printf("[notify] %s %s (try %d/%d): %v", t.name, ev.ID, try, tries, err)
+			if try < tries {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(notifyTimeout):
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyPayload is the JSON body of webhook and MQTT messages.
+type notifyPayload struct {
+	AlertEvent
+	Vehicle string `json:"vehicle,omitempty"`
+	Message string `json:"message"`
+}
+
+// notifyText is the one-line message for ev, e.g.
+// "Race car: ⚠️ COOLANT 106°C".
+func notifyText(vehicle string, ev AlertEvent) string {
+	var b strings.Builder
+	if vehicle != "" {
+		b.WriteString(vehicle + ": ")
+	}
+	switch {
+	case ev.Event == "end":
+		b.WriteString("✅ cleared: " + ev.Text)
+		if ev.DurationMs > 0 {
+			b.WriteString(" (after " + (time.Duration(ev.DurationMs) * time.Millisecond).Round(time.Second).String() + ")")
+		}
+	case ev.Level == alertCritical:
+		b.WriteString("🚨 " + ev.Text)
+	default:
+		b.WriteString("⚠️ " + ev.Text)
+	}
+	return b.String()
+}
+
+// sendWebhook POSTs ev as JSON to u.
+func sendWebhook(ctx context.Context, u, vehicle string, ev AlertEvent) error {
+	body, _ := json.Marshal(notifyPayload{AlertEvent: ev, Vehicle: vehicle, Message: notifyText(vehicle, ev)})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doNotify(req)
+}
+
+// sendTelegram sends text to the chat through the Bot API.
+func sendTelegram(ctx context.Context, cfg TelegramConfig, text string) error {
+	form := url.Values{"chat_id": {cfg.ChatID}, "text": {text}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		"https://api.telegram.org/bot"+cfg.BotToken+"/sendMessage", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doNotify(req)
+}
+
+// doNotify sends req and fails on a non-2xx reply. Errors leave out the
+// URL (Telegram's holds the bot token, and webhook URLs often hold one
+// too) and the reply body, which is the target's business.
+func doNotify(req *http.Request) error {
+	resp, err := notifyClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err
+		}
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// publishMQTT publishes ev as JSON to the broker with QoS 0 over a
+// short-lived MQTT 3.1.1 connection. Alerts are rare enough that holding
+// a session open isn't worth its keepalives.
+func publishMQTT(ctx context.Context, cfg MQTTConfig, vehicle string, ev AlertEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	clientID := cfg.ClientID
+	if clientID == "" {
+		host, _ := os.Hostname()
+		clientID = "goefidash-" + host
+	}
+	conn, err := mqttDial(ctx, cfg.Broker, mqttConnect(clientID, cfg.Username, cfg.Password, nil))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	topic := cfg.Topic
+	if topic == "" {
+		topic = "goefidash/alerts"
+	}
+	payload, _ := json.Marshal(notifyPayload{AlertEvent: ev, Vehicle: vehicle, Message: notifyText(vehicle, ev)})
+	if _, err := conn.Write(mqttPublish(topic, payload, 0, false, 0)); err != nil {
+		return err
+	}
+	_, err = conn.Write([]byte{0xE0, 0}) // DISCONNECT
+	return err
+}
+
+// handleNotifyTest sends a test alert to every configured target, to check
+// the setup from the settings page. Each is tried once and waited for, so
+// the reply says whether they worked.
+//
+//	POST /api/alerts/test
+func (s *Server) handleNotifyTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	cfg := s.cfg.NotifySnapshot()
+	if !cfg.Enabled() {
+		http.Error(w, "no notification targets configured", 409)
+		return
+	}
+	ev := AlertEvent{
+		Alert: Alert{ID: "test", Level: alertWarning, Text: "TEST ALERT"},
+		Event: "start",
+		At:    time.Now().UnixMilli(),
+	}
+	if err := s.sendNotify(r.Context(), cfg, ev, 1); err != nil {
+		http.Error(w, err.Error(), 502)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
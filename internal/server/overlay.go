@@ -0,0 +1,371 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/laps"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// GhostData compares the lap in progress with the ghost lap at the same
+// distance from the line.
+type GhostData struct {
+	Lap      int                `json:"lap"`               // Ghost lap number
+	TimeMs   int64              `json:"timeMs"`            // Ghost lap time
+	DeltaMs  *int64             `json:"deltaMs,omitempty"` // Live minus ghost; positive = behind. Absent past the ghost's end
+	Channels map[string]float64 `json:"channels,omitempty"`
+}
+
+// ghost is the past lap overlaid on the live one.
+type ghost struct {
+	mu       sync.Mutex
+	best     bool // Follow the best lap: a faster one replaces it
+	lap      laps.Lap
+	trace    *laps.Trace
+	channels []string
+}
+
+// set makes lap the ghost.
+func (g *ghost) set(lap laps.Lap, trace *laps.Trace, best bool, channels []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lap, g.trace, g.best, g.channels = lap, trace, best, channels
+}
+
+func (g *ghost) clear() {
+	g.set(laps.Lap{}, nil, false, nil)
+}
+
+// lapDone moves a best-lap ghost on to lap if it's quicker, or is on a
+// different track.
+func (g *ghost) lapDone(lap laps.Lap, trace *laps.Trace) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.best {
+		return
+	}
+	if g.trace == nil || lap.Track != g.lap.Track || lap.TimeMs < g.lap.TimeMs {
+		g.lap, g.trace = lap, trace
+	}
+}
+
+// ghostData returns the ghost comparison for the lap in progress on
+// track name, or nil without a ghost for it.
+func (s *Server) ghostData(now time.Time, name string) *GhostData {
+	g := &s.ghost
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.trace == nil || g.lap.Track != name {
+		return nil
+	}
+	out := &GhostData{Lap: g.lap.Number, TimeMs: g.lap.TimeMs}
+	dist, elapsed, ok := s.laps.Position(now)
+	if !ok {
+		return out
+	}
+	if p, ok := g.trace.At(dist, g.channels); ok {
+		delta := elapsed - p.TimeMs
+		out.DeltaMs = &delta
+		for c, v := range p.Channels {
+			p.Channels[c] = math.Round(v*1000) / 1000
+		}
+		out.Channels = p.Channels
+	}
+	return out
+}
+
+// findLap resolves a lap reference: a lap number, "last", or "best" (the
+// fastest on the current track, else overall).
+func (s *Server) findLap(ref string) (laps.Lap, bool) {
+	list := s.laps.Laps()
+	if len(list) == 0 {
+		return laps.Lap{}, false
+	}
+	switch ref {
+	case "last":
+		return list[len(list)-1], true
+	case "best", "", "0":
+		if st := s.laps.Status(time.Now()); st.Best != nil {
+			return *st.Best, true
+		}
+		best := list[0]
+		for _, l := range list[1:] {
+			if l.TimeMs < best.TimeMs {
+				best = l
+			}
+		}
+		return best, true
+	}
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return laps.Lap{}, false
+	}
+	for _, l := range list {
+		if l.Number == n {
+			return l, true
+		}
+	}
+	return laps.Lap{}, false
+}
+
+// loadLapTrace reads a saved lap trace.
+func (s *Server) loadLapTrace(number int) (*laps.Trace, error) {
+	var t laps.Trace
+	if err := s.store.ReadJSON(lapTraceFile(number), &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// logTraceColumns maps CSV log columns to lap trace channel names, so a
+// log overlays a lap with the same names. Other numeric columns keep
+// their CSV name.
+var logTraceColumns = map[string]string{
+	"rpm":           "rpm",
+	"map_kpa":       "map",
+	"tps_pct":       "tps",
+	"afr":           "afr",
+	"gear":          "gear",
+	"gps_lat":       "lat",
+	"gps_lon":       "lon",
+	"gps_speed_kph": "speed",
+}
+
+// traceFromLog builds a trace from a CSV data log, with distance measured
+// from the first GPS fix. Rows without a new fix are skipped; a column
+// logged at a lower rate holds its last value.
+func traceFromLog(r io.Reader) (*laps.Trace, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("overlay: read header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	var names []string
+	for i, h := range header {
+		col[h] = i
+		switch h {
+		case "timestamp", "gps_valid":
+			continue
+		}
+		name := h
+		if n, ok := logTraceColumns[h]; ok {
+			name = n
+		}
+		names = append(names, name)
+	}
+	for _, need := range []string{"timestamp", "gps_valid", "gps_lat", "gps_lon"} {
+		if _, ok := col[need]; !ok {
+			return nil, fmt.Errorf("overlay: log has no %s column", need)
+		}
+	}
+
+	t := laps.NewTrace(names...)
+	vals := make(map[string]float64, len(header))
+	var start time.Time
+	var prev track.Point
+	var dist float64
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("overlay: read log: %w", err)
+		}
+		if len(row) != len(header) {
+			continue
+		}
+		for i, h := range header {
+			v, err := strconv.ParseFloat(row[i], 64)
+			if err != nil {
+				continue
+			}
+			if n, ok := logTraceColumns[h]; ok {
+				h = n
+			}
+			vals[h] = v
+		}
+		if vals["gps_valid"] != 1 || row[col["gps_lat"]] == "" || row[col["gps_lon"]] == "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, row[col["timestamp"]])
+		if err != nil {
+			continue
+		}
+		pos := track.Point{Lat: vals["lat"], Lon: vals["lon"]}
+		if start.IsZero() {
+			start = ts
+		} else {
+			dist += track.DistanceM(prev, pos)
+		}
+		prev = pos
+		t.Add(dist, ts.Sub(start).Milliseconds(), vals)
+	}
+	if t.Len() == 0 {
+		return nil, errors.New("overlay: log has no GPS fixes")
+	}
+	return t, nil
+}
+
+// logFilePath returns the path of a data log by file name, refusing
+// anything that isn't a plain .csv name in the log directory.
+func (s *Server) logFilePath(name string) (string, bool) {
+	if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, ".csv") {
+		return "", false
+	}
+	return filepath.Join(s.logger.Dir(), name), true
+}
+
+// splitChannels parses a comma-separated channel list.
+func splitChannels(v string) []string {
+	var out []string
+	for _, c := range strings.Split(v, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// handleOverlay serves a past run resampled by distance, for drawing it
+// against live data.
+//
+//	GET /api/overlay?lap=<n|best|last>&channels=speed,rpm&step=5
+//	GET /api/overlay?log=<file.csv>&channels=speed,rpm&step=5
+//
+// Channels default to all; step is metres between points (default 5).
+func (s *Server) handleOverlay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	q := r.URL.Query()
+	channels := splitChannels(q.Get("channels"))
+	step, _ := strconv.ParseFloat(q.Get("step"), 64)
+	if math.IsNaN(step) || step < 0.5 {
+		step = 0 // Default
+	}
+
+	resp := struct {
+		Lap     *laps.Lap    `json:"lap,omitempty"`
+		Log     string       `json:"log,omitempty"`
+		Overlay laps.Overlay `json:"overlay"`
+	}{}
+
+	if name := q.Get("log"); name != "" {
+		path, ok := s.logFilePath(name)
+		if !ok {
+			http.Error(w, "bad log name", 400)
+			return
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			http.Error(w, "log not found", 404)
+			return
+		}
+		defer f.Close()
+		trace, err := traceFromLog(f)
+		if err != nil {
+			http.Error(w, err.Error(), 422)
+			return
+		}
+		resp.Log = name
+		resp.Overlay = trace.Resample(step, channels)
+	} else {
+		lap, ok := s.findLap(q.Get("lap"))
+		if !ok {
+			http.Error(w, "lap not found", 404)
+			return
+		}
+		trace, err := s.loadLapTrace(lap.Number)
+		if err != nil {
+			http.Error(w, "no trace for lap", 404)
+			return
+		}
+		resp.Lap = &lap
+		resp.Overlay = trace.Resample(step, channels)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGhost sets the ghost lap compared against live in every frame.
+//
+//	GET    /api/overlay/ghost — current ghost
+//	POST   /api/overlay/ghost — {"lap": "best"|"last"|"<n>", "channels": ["speed","rpm"]}
+//	DELETE /api/overlay/ghost — no ghost
+//
+// A "best" ghost moves on to each new best lap.
+func (s *Server) handleGhost(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		g := &s.ghost
+		g.mu.Lock()
+		resp := struct {
+			Lap      *laps.Lap `json:"lap,omitempty"`
+			Best     bool      `json:"best"`
+			Channels []string  `json:"channels,omitempty"`
+		}{Best: g.best, Channels: g.channels}
+		if g.trace != nil {
+			lap := g.lap
+			resp.Lap = &lap
+		}
+		g.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+
+	case http.MethodPost:
+		var req struct {
+			Lap      string   `json:"lap"`
+			Channels []string `json:"channels"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request: "+err.Error(), 400)
+			return
+		}
+		best := req.Lap == "" || req.Lap == "best"
+		lap, ok := s.findLap(req.Lap)
+		if !ok {
+			if !best {
+				http.Error(w, "lap not found", 404)
+				return
+			}
+			// No laps yet: the first one completed becomes the ghost
+			s.ghost.set(laps.Lap{}, nil, true, req.Channels)
+		} else {
+			trace, err := s.loadLapTrace(lap.Number)
+			if err != nil {
+				http.Error(w, "no trace for lap", 404)
+				return
+			}
+			s.ghost.set(lap, trace, best, req.Channels)
+			log.Printf("[laps] ghost set to lap %d (%.3fs)", lap.Number, float64(lap.TimeMs)/1000)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	case http.MethodDelete:
+		s.ghost.clear()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/perf"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// perfFile is the performance timer results inside the data directory.
+const perfFile = storage.DirRecords + "/perf.json"
+
+// loadPerf restores the performance timer results from disk.
+func (s *Server) loadPerf() {
+	var results []perf.Result
+	if err := s.store.ReadJSON(perfFile, &results); err != nil {
+		return
+	}
+	s.perf.Restore(results)
+	log.Printf("[perf] loaded %d results", len(results))
+}
+
+// savePerf persists the performance timer results.
+func (s *Server) savePerf() {
+	if err := s.store.WriteJSON(perfFile, s.perf.Results("")); err != nil {
+		log.Printf("[perf] save failed: %v", err)
+	}
+}
+
+// sessionID returns the drive being recorded, or "".
+func (s *Server) sessionID() string {
+	s.session.mu.Lock()
+	defer s.session.mu.Unlock()
+	return s.session.id
+}
+
+// updatePerf feeds the fused speed to the performance timers and returns
+// the status for the outgoing frame (nil when disabled, or moving with no
+// results recorded).
+func (s *Server) updatePerf(now time.Time, speed *SpeedData) *perf.Status {
+	if !s.cfg.PerfSnapshot().Enabled {
+		return nil
+	}
+	if r := s.perf.Update(now, speed.Value, s.sessionID()); r != nil {
+		msg := ""
+		for _, sp := range r.Speeds {
+			msg += fmt.Sprintf(" 0-%g %.2fs", sp.Kph, float64(sp.Ms)/1000)
+		}
+		for _, d := range r.Distances {
+			msg += fmt.Sprintf(" %s %.2fs", d.Name, float64(d.Ms)/1000)
+		}
+		log.Printf("[perf] launch %d:%s, top %.0f km/h", r.ID, msg, r.TopKph)
+		s.savePerf()
+	}
+	st := s.perf.Status(now)
+	if st.State == perf.StateIdle && st.Last == nil {
+		return nil
+	}
+	return &st
+}
+
+// handlePerf serves the performance timers.
+//
+//	GET    /api/perf[?session=<id>|current] — live status and results
+//	DELETE /api/perf — clear the results
+func (s *Server) handlePerf(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		session := r.URL.Query().Get("session")
+		if session == "current" {
+			session = s.sessionID()
+			if session == "" {
+				session = "-" // No drive yet: nothing matches
+			}
+		}
+		resp := struct {
+			perf.Status
+			Results []perf.Result `json:"results"`
+		}{s.perf.Status(time.Now()), s.perf.Results(session)}
+		if resp.Results == nil {
+			resp.Results = []perf.Result{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+
+	case http.MethodDelete:
+		s.perf.Clear()
+		s.savePerf()
+		log.Printf("[perf] cleared")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// Power states, broadcast as Frame.Power.
+const (
+	powerOn    = ""      // Engine running (or quiescence disabled): full frames
+	powerOff   = "off"   // Key on, engine off: reduced frames at a low rate
+	powerSleep = "sleep" // Engine off too long: ECU polled at 1 Hz
+)
+
+// quietWakeKph is the speed (GPS while asleep) that counts as being driven.
+const quietWakeKph = 5.0
+
+// QuietData is the reduced set broadcast while the engine is off — what
+// an OEM cluster keeps showing with key on, engine off.
+type QuietData struct {
+	BatteryVoltage float64 `json:"batteryVoltage"`
+	Coolant        float64 `json:"coolant"` // °C, for watching the cooldown
+}
+
+// quiescence tracks engine-off time and decides between full frames,
+// reduced frames and sleep. Any sign of life — RPM, speed or an explicit
+// wake — goes straight back to full frames.
+type quiescence struct {
+	mu       sync.Mutex
+	state    string
+	offSince time.Time // Engine first seen off (zero while running)
+	lastSent time.Time // Last reduced or sleep frame
+	wake     bool      // Wake requested (POST /api/wake)
+}
+
+// update feeds the latest ECU frame and speed and returns the power state.
+// f is nil when there's no fresh ECU data. That doesn't start the engine-off
+// clock (a missing ECU is the noData path's business), but once the engine
+// is off it keeps running: key-off usually takes the ECU with it.
+func (q *quiescence) update(now time.Time, f *ecu.DataFrame, speed float64, cfg QuiescentConfig) string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !cfg.Enabled || q.wake || speed >= quietWakeKph || (f != nil && f.RPM > 0) {
+		if q.state != powerOn {
+			log.Printf("[quiet] engine on, resuming full frames")
+		}
+		q.state, q.offSince, q.wake = powerOn, time.Time{}, false
+		return q.state
+	}
+	if q.state == powerSleep || (f == nil && q.offSince.IsZero()) {
+		return q.state
+	}
+
+	if q.offSince.IsZero() {
+		q.offSince = now
+	}
+	off := now.Sub(q.offSince).Seconds()
+	switch {
+	case cfg.SleepAfterSec > 0 && off >= cfg.SleepAfterSec:
+		log.Printf("[quiet] engine off for %.0fs, slowing ECU polling", off)
+		q.state = powerSleep
+	case off >= cfg.AfterSec && q.state == powerOn:
+		log.Printf("[quiet] engine off, sending reduced frames")
+		q.state = powerOff
+	}
+	return q.state
+}
+
+// due reports whether a reduced frame should go out now, at most every
+// interval.
+func (q *quiescence) due(now time.Time, interval time.Duration) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if now.Sub(q.lastSent) < interval {
+		return false
+	}
+	q.lastSent = now
+	return true
+}
+
+// asleep reports whether ECU polling is slowed to ecuSleepPoll.
+func (q *quiescence) asleep() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.state == powerSleep
+}
+
+// requestWake returns to full frames on the next broadcast tick.
+func (q *quiescence) requestWake() {
+	q.mu.Lock()
+	q.wake = true
+	q.mu.Unlock()
+}
+
+// quietFrame builds a reduced frame from the latest ECU data.
+func quietFrame(now time.Time, state string, f *ecu.DataFrame, odo *OdoData) Frame {
+	frame := Frame{Odo: odo, Stamp: now.UnixMilli(), Power: state}
+	if f != nil {
+		frame.Quiet = &QuietData{BatteryVoltage: f.BatteryVoltage, Coolant: f.Coolant}
+	}
+	return frame
+}
+
+// handleWake wakes the dashboard from engine-off sleep (e.g. a tap on the
+// dash), restarting ECU polling.
+func (s *Server) handleWake(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	s.quiet.requestWake()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+func TestQuiescenceWakesOnRPM(t *testing.T) {
+	cfg := QuiescentConfig{Enabled: true, AfterSec: 5, RateHz: 1, SleepAfterSec: 60}
+	var q quiescence
+	now := time.Unix(0, 0)
+	for _, step := range []struct {
+		after time.Duration
+		rpm   uint16
+		want  string
+	}{
+		{0, 0, powerOn}, // Engine-off clock starts
+		{5 * time.Second, 0, powerOff},
+		{60 * time.Second, 0, powerSleep},
+		{61 * time.Second, 0, powerSleep}, // Slow poll while asleep
+		{62 * time.Second, 900, powerOn},  // Engine started
+	} {
+		got := q.update(now.Add(step.after), &ecu.DataFrame{RPM: step.rpm}, 0, cfg)
+		if got != step.want {
+			t.Fatalf("at %v with %d rpm: state %q, want %q", step.after, step.rpm, got, step.want)
+		}
+		if asleep := q.asleep(); asleep != (step.want == powerSleep) {
+			t.Fatalf("at %v: asleep = %v", step.after, asleep)
+		}
+	}
+}
+
+// A sleeping dash still polls the ECU, so an engine start is seen without
+// GPS or a tap.
+func TestSleepKeepsPolling(t *testing.T) {
+	cfg := QuiescentConfig{Enabled: true, SleepAfterSec: 1}
+	s := &Server{}
+	now := time.Now()
+	s.quiet.update(now, &ecu.DataFrame{}, 0, cfg)
+	if s.quiet.update(now.Add(time.Second), &ecu.DataFrame{}, 0, cfg) != powerSleep {
+		t.Fatal("not asleep")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prov := ecu.NewDemoProvider() // Engine running
+	if err := prov.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ecuCh := make(chan *ecu.DataFrame, 1)
+	s.runECUPipeline(ctx, "ecu", prov, 20, nil, ecuCh)
+	select {
+	case f := <-ecuCh:
+		if got := s.quiet.update(time.Now(), f, 0, cfg); got != powerOn {
+			t.Fatalf("frame with %d rpm left the dash in state %q", f.RPM, got)
+		}
+	case <-time.After(3 * ecuSleepPoll):
+		t.Fatal("no ECU frame while asleep")
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/racechrono"
+)
+
+// runRaceChrono sends RaceChrono DIY sentences at the configured rate to
+// the UDP target and every client of the TCP listener until ctx ends.
+func (s *Server) runRaceChrono(ctx context.Context) {
+	cfg := s.cfg.RaceChronoSnapshot()
+	if len(cfg.Analog) > racechrono.MaxAnalog {
+		log.Printf("[racechrono] only the first %d analog channels are sent", racechrono.MaxAnalog)
+		cfg.Analog = cfg.Analog[:racechrono.MaxAnalog]
+	}
+
+	var udp net.Conn
+	if cfg.UDP != "" {
+		c, err := net.Dial("udp", cfg.UDP)
+		if err != nil {
+			log.Printf("[racechrono] udp %s: %v", cfg.UDP, err)
+		} else {
+			udp = c
+			defer udp.Close()
+			log.Printf("[racechrono] sending to udp %s", cfg.UDP)
+		}
+	}
+
+	var tcp *tcpFanout
+	if cfg.Listen != "" {
+		f, err := listenFanout(ctx, "racechrono", cfg.Listen)
+		if err != nil {
+			log.Printf("[racechrono] listen %s: %v", cfg.Listen, err)
+		} else {
+			tcp = f
+			log.Printf("[racechrono] listening on tcp %s", cfg.Listen)
+		}
+	}
+	if udp == nil && tcp == nil {
+		return
+	}
+
+	rate := cfg.RateHz
+	if rate <= 0 {
+		rate = 20
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	var count uint16
+	var lastFix gps.Data
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			in := s.gauges.latest.Load()
+			if in == nil {
+				continue
+			}
+			rpm := math.NaN()
+			if v, ok := in.value("rpm"); ok {
+				rpm = v
+			}
+			analog := make([]float64, len(cfg.Analog))
+			for i, ch := range cfg.Analog {
+				analog[i] = math.NaN()
+				if v, ok := in.value(ch); ok {
+					analog[i] = v
+				}
+			}
+			var inertial *racechrono.Inertial
+			if m := s.imuSnapshot(now); m != nil {
+				inertial = &racechrono.Inertial{
+					AccX: m.LatG, AccY: m.LongG, AccZ: m.VertG,
+					GyroX: math.NaN(), GyroY: math.NaN(), GyroZ: m.YawRate,
+				}
+			}
+			out := racechrono.RC3(count, inertial, rpm, analog)
+			count++
+
+			// Each fix once, as it arrives
+			if g := s.latestGPS(); cfg.GPS && g != nil && g.Valid && !sameFix(g, &lastFix) {
+				lastFix = *g
+				fix := racechrono.Fix{
+					Time:       now,
+					Lat:        g.Latitude,
+					Lon:        g.Longitude,
+					SpeedKph:   g.Speed,
+					Heading:    g.Heading,
+					Altitude:   g.Altitude,
+					Satellites: g.Satellites,
+					Quality:    g.FixQuality,
+					HDOP:       g.HDOP,
+				}
+				if g.Time > 0 {
+					fix.Time = time.UnixMilli(g.Time)
+				}
+				out = append(out, racechrono.RMC(fix)...)
+				out = append(out, racechrono.GGA(fix)...)
+			}
+			if udp != nil {
+				udp.Write(out) // Nobody listening is fine
+			}
+			if tcp != nil {
+				tcp.send(out)
+			}
+		}
+	}
+}
+
+// sameFix reports whether a and b are the same fix, read twice.
+func sameFix(a, b *gps.Data) bool {
+	return a.Time == b.Time && a.Timestamp == b.Timestamp && a.Latitude == b.Latitude && a.Longitude == b.Longitude
+}
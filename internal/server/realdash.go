@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"log"
+	"math"
+	"net/http"
+	"time"
+)
+
+// RealDash's CAN-over-TCP protocol: each frame is a 4-byte tag, the
+// 32-bit frame ID and 8 data bytes, all little-endian. Channels go two to
+// a frame, as signed 32-bit thousandths.
+const (
+	realDashBaseID = 0xC80 // First frame's ID
+	realDashScale  = 1000
+	realDashLen    = 16 // Bytes per frame
+)
+
+// realDashTag starts every frame.
+var realDashTag = []byte{0x44, 0x33, 0x22, 0x11}
+
+// realDashUnits are RealDash's unit names for channels whose unit is
+// fixed, so its own unit conversions work on them.
+var realDashUnits = map[string]string{
+	"speed":          "kmh",
+	"vss":            "kmh",
+	"map":            "kpa",
+	"baro":           "kpa",
+	"coolant":        "C",
+	"iat":            "C",
+	"batteryVoltage": "V",
+	"oilPressure":    "psi",
+	"fuelPressure":   "psi",
+	"tps":            "%",
+	"dutyCycle":      "%",
+}
+
+// runRealDash sends the configured channels to every RealDash client at
+// the configured rate until ctx ends.
+func (s *Server) runRealDash(ctx context.Context) {
+	cfg := s.cfg.RealDashSnapshot()
+	tcp, err := listenFanout(ctx, "realdash", cfg.Listen)
+	if err != nil {
+		log.Printf("[realdash] listen %s: %v", cfg.Listen, err)
+		return
+	}
+	log.Printf("[realdash] listening on tcp %s, %d channel(s)", cfg.Listen, len(cfg.Channels))
+
+	rate := cfg.RateHz
+	if rate <= 0 {
+		rate = 20
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			in := s.gauges.latest.Load()
+			if in == nil || tcp.idle() {
+				continue
+			}
+			if out := realDashFrames(in, cfg.Channels); len(out) > 0 {
+				tcp.send(out)
+			}
+		}
+	}
+}
+
+// realDashFrames packs channels into frames. A frame is held back while
+// either of its channels has no value, as with CAN output.
+func realDashFrames(in *gaugeInput, channels []string) []byte {
+	var out []byte
+	for i := 0; i < len(channels); i += 2 {
+		frame := make([]byte, realDashLen)
+		copy(frame, realDashTag)
+		binary.LittleEndian.PutUint32(frame[4:], uint32(realDashBaseID+i/2))
+		ok := true
+		for j, ch := range channels[i:min(i+2, len(channels))] {
+			v, has := in.value(ch)
+			if !has {
+				ok = false
+				break
+			}
+			v = math.Round(v * realDashScale)
+			v = max(math.MinInt32, min(math.MaxInt32, v))
+			binary.LittleEndian.PutUint32(frame[8+4*j:], uint32(int32(v)))
+		}
+		if ok {
+			out = append(out, frame...)
+		}
+	}
+	return out
+}
+
+// realDashXML is RealDash's channel description file.
+type realDashXML struct {
+	XMLName xml.Name        `xml:"RealDashCAN"`
+	Version int             `xml:"version,attr"`
+	Frames  []realDashFrame `xml:"frames>frame"`
+}
+
+type realDashFrame struct {
+	ID         int             `xml:"id,attr"`
+	Endianness string          `xml:"endianness,attr"`
+	Values     []realDashValue `xml:"value"`
+}
+
+type realDashValue struct {
+	Name       string `xml:"name,attr"`
+	Units      string `xml:"units,attr,omitempty"`
+	Offset     int    `xml:"offset,attr"`
+	Length     int    `xml:"length,attr"`
+	Signed     bool   `xml:"signed,attr"`
+	Conversion string `xml:"conversion,attr"`
+}
+
+// handleRealDashXML serves the channel description for the configured
+// channels, to load in RealDash's connection settings.
+//
+//	GET /api/realdash.xml
+func (s *Server) handleRealDashXML(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	cfg := s.cfg.RealDashSnapshot()
+	doc := realDashXML{Version: 2}
+	for i, ch := range cfg.Channels {
+		if i%2 == 0 {
+			doc.Frames = append(doc.Frames, realDashFrame{ID: realDashBaseID + i/2, Endianness: "little"})
+		}
+		f := &doc.Frames[len(doc.Frames)-1]
+		f.Values = append(f.Values, realDashValue{
+			Name:       ch,
+			Units:      realDashUnits[ch],
+			Offset:     4 * (i % 2),
+			Length:     4,
+			Signed:     true,
+			Conversion: "V/1000",
+		})
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", `attachment; filename="goefidash_realdash.xml"`)
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+	w.Write([]byte("\n"))
+}
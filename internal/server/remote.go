@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	remoteReadTimeout = 10 * time.Second // Instances send at least a 1 Hz heartbeat
+	remoteStale       = 5 * time.Second  // Drop a remote's data when it's older than this
+)
+
+// remoteSkip lists frame fields not forwarded from a remote: its client
+// setup (the local instance's applies) and its own remotes, so two
+// instances subscribed to each other don't nest without end.
+var remoteSkip = map[string]bool{
+	"config":     true,
+	"drivetrain": true,
+	"vehicle":    true,
+	"remotes":    true,
+}
+
+// remote is another goefidash instance whose frames are merged into ours
+// under Frame.Remotes[name] — e.g. a tow vehicle showing the race car on
+// the trailer, or a dyno cart.
+type remote struct {
+	name string
+	url  string
+
+	mu        sync.Mutex
+	connected bool
+	last      map[string]json.RawMessage // Latest data frame
+	lastAt    time.Time
+}
+
+// remoteURL normalises a configured address to a WebSocket URL:
+// "host:8080", "http://host:8080" and "ws://host:8080" all become
+// "ws://host:8080/ws".
+func remoteURL(addr string) (string, error) {
+	if !strings.Contains(addr, "://") {
+		addr = "ws://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/ws"
+	}
+	return u.String(), nil
+}
+
+// addRemotes sets up a subscription per configured remote, skipping ones
+// with a missing or duplicate name or a bad URL.
+func (s *Server) addRemotes(cfgs []RemoteConfig) {
+	seen := map[string]bool{}
+	for _, rc := range cfgs {
+		if rc.Name == "" || seen[rc.Name] {
+			log.Printf("[remote] skipping remote with missing or duplicate name %q", rc.Name)
+			continue
+		}
+		u, err := remoteURL(rc.URL)
+		if err != nil || rc.URL == "" {
+			log.Printf("[remote] skipping %q: bad url %q", rc.Name, rc.URL)
+			continue
+		}
+		seen[rc.Name] = true
+		s.remotes = append(s.remotes, &remote{name: rc.Name, url: u})
+	}
+}
+
+// runRemote keeps a subscription to r open until ctx is cancelled,
+// reconnecting with backoff.
+func (s *Server) runRemote(ctx context.Context, r *remote) {
+	tag := "[remote:" + r.name + "]"
+	delay := 2 * time.Second
+	const maxDelay = 30 * time.Second
+
+	for ctx.Err() == nil {
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, r.url, nil)
+		if err != nil {
+			log.Printf("%s connect to %s failed: %v (retry in %v)", tag, r.url, err, delay)
+		} else {
+			log.Printf("%s connected to %s", tag, r.url)
+			delay = 2 * time.Second
+			r.read(ctx, conn)
+			log.Printf("%s disconnected", tag)
+		}
+		r.mu.Lock()
+		r.connected = false
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// read consumes frames until the connection fails or ctx is cancelled.
+func (r *remote) read(ctx context.Context, conn *websocket.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	r.mu.Lock()
+	r.connected = true
+	r.mu.Unlock()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(remoteReadTimeout))
+		kind, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if kind != websocket.TextMessage {
+			continue
+		}
+		var frame map[string]json.RawMessage
+		if err := json.Unmarshal(msg, &frame); err != nil {
+			continue
+		}
+		if _, setup := frame["config"]; setup {
+			continue // Connect-time settings frame, no data
+		}
+		for k := range frame {
+			if remoteSkip[k] {
+				delete(frame, k)
+			}
+		}
+		r.mu.Lock()
+		r.last, r.lastAt = frame, time.Now()
+		r.mu.Unlock()
+	}
+}
+
+// snapshot returns the fields to broadcast: the remote's latest frame if
+// fresh, plus "connected".
+func (r *remote) snapshot(now time.Time) map[string]json.RawMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]json.RawMessage, len(r.last)+1)
+	if r.connected && now.Sub(r.lastAt) < remoteStale {
+		for k, v := range r.last {
+			out[k] = v
+		}
+	}
+	out["connected"] = json.RawMessage(boolJSON(r.connected))
+	return out
+}
+
+// remoteSnapshot returns every remote's fields keyed by name, or nil
+// without remotes.
+func (s *Server) remoteSnapshot(now time.Time) map[string]map[string]json.RawMessage {
+	if len(s.remotes) == 0 {
+		return nil
+	}
+	out := make(map[string]map[string]json.RawMessage, len(s.remotes))
+	for _, r := range s.remotes {
+		out[r.name] = r.snapshot(now)
+	}
+	return out
+}
+
+// remotesLive reports whether any remote in snap has fresh data.
+func remotesLive(snap map[string]map[string]json.RawMessage) bool {
+	for _, f := range snap {
+		if len(f) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func boolJSON(v bool) string {
+	if v {
+		return "true"
+	}
+	return "false"
+}
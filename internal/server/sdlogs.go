@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+// handleSDLogs lists and downloads logs stored on the ECU's SD card.
+//
+//	GET /api/ecu/sdlogs[?ecu=name]          — card status and file list
+//	GET /api/ecu/sdlogs?file=3[&ecu=name]   — download file 3
+//
+// Downloads run at serial speed (roughly 10 KB/s at 115200 baud); live
+// data keeps flowing between chunks.
+func (s *Server) handleSDLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	name := r.URL.Query().Get("ecu")
+	prov := s.ecuByName(name)
+	if prov == nil {
+		http.Error(w, "no such ecu: "+name, 404)
+		return
+	}
+	sd, ok := prov.(ecu.SDCard)
+	if !ok {
+		http.Error(w, prov.Name()+" has no SD card access", 501)
+		return
+	}
+
+	files, err := sd.SDLogs()
+	if err != nil {
+		http.Error(w, err.Error(), 502)
+		return
+	}
+
+	if v := r.URL.Query().Get("file"); v != "" {
+		idx, err := strconv.Atoi(v)
+		if err != nil || idx < 1 || idx > len(files) {
+			http.Error(w, "no such file: "+v, 404)
+			return
+		}
+		f := files[idx-1]
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", f.Name))
+		w.Header().Set("Content-Length", strconv.FormatUint(uint64(f.Size), 10))
+		log.Printf("[sdlogs] downloading %s (%d bytes)", f.Name, f.Size)
+		if err := sd.SDDownload(f, w); err != nil {
+			// Headers are gone; the short body tells the client it failed
+			log.Printf("[sdlogs] download %s failed: %v", f.Name, err)
+		}
+		return
+	}
+
+	status, err := sd.SDStatus()
+	if err != nil {
+		http.Error(w, err.Error(), 502)
+		return
+	}
+	if files == nil {
+		files = []ecu.SDFile{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Status *ecu.SDStatus `json:"status"`
+		Files  []ecu.SDFile  `json:"files"`
+	}{status, files})
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/device"
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/sensors"
+)
+
+// sensorStale is how old a reading may be before it's no longer used to
+// override ECU channels. Pi-attached sensors get longer: a 1-Wire probe
+// takes most of a second to convert.
+const (
+	sensorStale    = 1 * time.Second
+	auxSensorStale = 3 * time.Second
+)
+
+// auxSensor is an auxiliary sensor source registered with the server.
+type auxSensor struct {
+	cfg  SensorConfig
+	prov sensors.Provider
+}
+
+// AddSensor registers an auxiliary sensor provider. Its readings are
+// broadcast under Frame.Sensors[c.Name]; with c.OverrideAFR set, a valid
+// reading also feeds the AFR channel (see selectAFR). The server owns the
+// provider's connection. Must be called before Run.
+func (s *Server) AddSensor(c SensorConfig, prov sensors.Provider) {
+	s.sensors = append(s.sensors, auxSensor{cfg: c, prov: prov})
+}
+
+// runSensor connects one sensor and reads it continuously, keeping the
+// latest reading. After repeated errors the port is closed and reopened
+// with backoff.
+func (s *Server) runSensor(ctx context.Context, a auxSensor) {
+	var (
+		connected      bool
+		consecErrors   int
+		lastErrLog     time.Time
+		reconnectDelay = 2 * time.Second
+		maxReconnDelay = 30 * time.Second
+	)
+	const maxConsecErrors = 10
+	tag := "sensor:" + a.cfg.Name
+
+	if _, demo := a.prov.(*sensors.Demo); !demo {
+		wait := time.Duration(s.cfg.Startup.PortWaitSec) * time.Second
+		for _, p := range a.cfg.DevicePaths() {
+			if err := device.WaitReady(ctx, tag, p, wait); err != nil && ctx.Err() == nil {
+				log.Printf("[%s] %v — continuing with connect retries", tag, err)
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.prov.Close()
+			return
+		default:
+		}
+
+		if !connected {
+			if err := a.prov.Connect(); err != nil {
+				log.Printf("[%s] connect failed: %v (retry in %v)", tag, err, reconnectDelay)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectDelay):
+				}
+				reconnectDelay *= 2
+				if reconnectDelay > maxReconnDelay {
+					reconnectDelay = maxReconnDelay
+				}
+				continue
+			}
+			log.Printf("[%s] connected (%s)", tag, a.prov.Name())
+			connected = true
+			consecErrors = 0
+			reconnectDelay = 2 * time.Second
+		}
+
+		r, err := a.prov.Read()
+		if err == nil {
+			consecErrors = 0
+			s.sensorMu.Lock()
+			s.sensorLast[a.cfg.Name] = r
+			s.sensorMu.Unlock()
+			continue
+		}
+
+		consecErrors++
+		if time.Since(lastErrLog) > 5*time.Second {
+			log.Printf("[%s] read error (%d consecutive): %v", tag, consecErrors, err)
+			lastErrLog = time.Now()
+		}
+		if consecErrors >= maxConsecErrors {
+			log.Printf("[%s] %d consecutive errors, closing for reconnect", tag, consecErrors)
+			a.prov.Close()
+			connected = false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// sensorSnapshot returns a copy of the latest reading per sensor.
+func (s *Server) sensorSnapshot() map[string]*sensors.Reading {
+	s.sensorMu.Lock()
+	defer s.sensorMu.Unlock()
+	if len(s.sensorLast) == 0 {
+		return nil
+	}
+	out := make(map[string]*sensors.Reading, len(s.sensorLast))
+	for k, v := range s.sensorLast {
+		out[k] = v
+	}
+	return out
+}
+
+// mergeAuxSensors adds the channels of fresh readings from Pi-attached
+// sensors (see SensorConfig.IsAux) to e's aux channels, so alert rules,
+// gauges, CAN output and the log pick them up like the ECU's own. A
+// sensor channel replaces an ECU aux channel of the same name, and one
+// named after a sensorFields field replaces that field; sources says
+// which sensor each replaced field came from.
+func (s *Server) mergeAuxSensors(e *ecu.DataFrame, readings map[string]*sensors.Reading) (out *ecu.DataFrame, sources map[string]string) {
+	if e == nil {
+		return nil, nil
+	}
+	now := time.Now().UnixMilli()
+	for _, a := range s.sensors {
+		r := readings[a.cfg.Name]
+		if !a.cfg.IsAux() || !r.OK() || now-r.Stamp > auxSensorStale.Milliseconds() {
+			continue
+		}
+		if out == nil {
+			c := *e
+			out = &c
+			out.Aux = maps.Clone(e.Aux)
+		}
+		for name, v := range r.Channels {
+			if set, ok := sensorFields[name]; ok {
+				set(out, v)
+				out.Channels = withChannel(out.Channels, name)
+				if sources == nil {
+					sources = make(map[string]string)
+				}
+				sources[name] = a.cfg.Name
+				continue
+			}
+			if out.Aux == nil {
+				out.Aux = make(map[string]float64, len(r.Channels))
+			}
+			out.Aux[name] = v
+			out.Channels = withChannel(out.Channels, "aux")
+		}
+	}
+	if out == nil {
+		return e, nil
+	}
+	return out, sources
+}
+
+// sensorFields are the frame fields a Pi-attached sensor channel of the
+// same name replaces, for senders wired to the Pi's ADC instead of the
+// ECU. Values are in the field's unit.
+var sensorFields = map[string]func(e *ecu.DataFrame, v float64){
+	"oilPressure":  func(e *ecu.DataFrame, v float64) { e.OilPressure = psi(v) },
+	"fuelPressure": func(e *ecu.DataFrame, v float64) { e.FuelPressure = psi(v) },
+}
+
+// psi rounds a pressure into a uint8 PSI field.
+func psi(v float64) uint8 {
+	return uint8(math.Round(max(0, min(v, 255))))
+}
+
+// withChannel adds name to a pruned frame's channel mask.
+func withChannel(m ecu.ChannelMask, name string) ecu.ChannelMask {
+	if m.IsZero() {
+		return m // Not pruned: everything's there
+	}
+	add, _ := ecu.MaskOf(name)
+	return m.Union(add)
+}
+
+// collectEGT gathers per-cylinder EGT channels (egt1..egtN) from fresh,
+// valid sensor readings. The first configured sensor providing a channel
+// wins. Returns nil if no sensor reports EGT.
+func (s *Server) collectEGT(readings map[string]*sensors.Reading) []float64 {
+	var egt []float64
+	now := time.Now().UnixMilli()
+	for _, a := range s.sensors {
+		r := readings[a.cfg.Name]
+		if !r.OK() || now-r.Stamp > sensorStale.Milliseconds() {
+			continue
+		}
+		for i := 1; i <= sensors.MaxEGT; i++ {
+			v, ok := r.Channels[fmt.Sprintf("egt%d", i)]
+			if !ok {
+				continue
+			}
+			for len(egt) < i {
+				egt = append(egt, 0)
+			}
+			if egt[i-1] == 0 {
+				egt[i-1] = v
+			}
+		}
+	}
+	return egt
+}
@@ -4,22 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"io/fs"
 	"log"
 	"math"
+	"net"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/shaunagostinho/speeduino-dash/internal/autox"
 	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
 	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/imu"
+	"github.com/shaunagostinho/speeduino-dash/internal/influx"
+	"github.com/shaunagostinho/speeduino-dash/internal/keypad"
+	"github.com/shaunagostinho/speeduino-dash/internal/laps"
 	"github.com/shaunagostinho/speeduino-dash/internal/logger"
+	"github.com/shaunagostinho/speeduino-dash/internal/perf"
+	"github.com/shaunagostinho/speeduino-dash/internal/sensors"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+	"github.com/shaunagostinho/speeduino-dash/internal/tune"
 )
 
 // Server coordinates ECU/GPS polling and broadcasts data to WebSocket clients.
@@ -29,26 +41,134 @@ type Server struct {
 	gpsProv gps.Provider
 	webFS   fs.FS
 	logger  *logger.Logger
+	influx  *influx.Sink // nil when off
+	store   *storage.Store
 
-	clients   map[*wsClient]struct{}
+	extraECUs []extraECU // Additional namespaced ECU providers
+	remotes   []*remote  // Other goefidash instances merged under Frame.Remotes
+
+	// Auxiliary sensors (wideband controllers, EGT, ...)
+	sensors    []auxSensor
+	sensorMu   sync.Mutex
+	sensorLast map[string]*sensors.Reading
+	afrCheck   afrCrossCheck  // ECU vs external AFR disagreement (broadcast loop only)
+	leanBoost  leanBoostCheck // Lean-under-boost alarm (broadcast loop only)
+	logRate    logRate        // Scheduled CSV log rate (broadcast loop only)
+
+	// Accelerometer/gyro
+	imuProv imu.Provider
+	imuLast atomic.Pointer[imu.Data]
+
+	// CAN keypad, for actions and key LEDs
+	keypadProv keypad.Provider
+
+	// Auxiliary gauges fed over UDP or serial
+	gauges gaugeHub
+
+	// GPIO shift light and warning lamps
+	lights atomic.Pointer[gpioInput]
+
+	// Network status, refreshed by runNetwork
+	network    atomic.Pointer[NetworkStatus]
+	networkNew atomic.Bool // Not yet sent in a frame
+
+	faults faultInjector // Debug-only synthetic fault injection
+
+	clients   map[*frameClient]struct{} // WebSocket and SSE
 	clientsMu sync.RWMutex
+	ws        wsStats
+	loops     loopTimers // Poll and broadcast loop timing
+	started   time.Time
 
 	upgrader websocket.Upgrader
 
 	// Odometer — persistent distance tracking
 	odoMu        sync.Mutex
-	odoTotal     float64 // Total km
-	odoTrip      float64 // Trip km (resettable)
+	odoTotal     float64      // Total km
+	odoTrip      float64      // Trip A km (resettable)
+	odoTripB     float64      // Trip B km (resettable)
+	odoReverse   float64      // Km reversed this trip A (not in total/trips)
+	engineHours  float64      // Time running, for maintenance
+	engineAt     time.Time    // Previous engine hours tick, zero while stopped
+	trips        [2]tripMeter // Trip A and B statistics besides distance
+	tripsAt      time.Time    // Previous trip statistics tick
+	tripsDirty   bool         // Statistics changed since last saved
 	lastGPSLat   float64
 	lastGPSLon   float64
 	lastGPSValid bool
-	odoPath      string // File path for persistence
+	odoVSSAt     time.Time     // Previous VSS odometer tick, zero while GPS is counting
+	odoSaved     float64       // Total + reverse km when last saved
+	odoFlush     chan struct{} // Signalled when odoFlushKm is unsaved
+	odoFresh     bool          // No saved odometer at start and no backup restored yet
 	odoTicker    *time.Ticker
-}
 
-type wsClient struct {
-	conn *websocket.Conn
-	send chan []byte
+	// Forward/reverse detection (feeds the odometer)
+	direction   directionTracker
+	reverseGear atomic.Bool // RPM/speed ratio matches reverse gear
+
+	toothLogging atomic.Bool // A trigger log capture is running
+
+	// Latest GPS fix, shared with HTTP handlers
+	gpsMu     sync.Mutex
+	lastGPS   *gps.Data
+	lastGPSAt time.Time   // Last successful Read
+	gpsFilter *gps.Filter // Smoothing and glitch rejection, before odometer and display
+
+	// Clock correction from GPS time (GPS goroutine only)
+	clockChecked time.Time
+	clockOffset  time.Duration // Given to the logger while the system clock can't be set
+
+	// Active track definition (start/finish, sectors)
+	trackMu sync.Mutex
+	track   *track.Track
+
+	trackLib      atomic.Pointer[track.Library] // Tracks for detection and selection
+	trackDetected atomic.Bool                   // Detection ran on the first fix
+
+	autox   *autox.Session                 // Autocross run timing
+	laps    *laps.Timer                    // Circuit lap timing
+	perf    *perf.Timer                    // Drag-strip performance timers
+	tuneRef atomic.Pointer[tune.Reference] // Reference values from the uploaded tune
+	ghost   ghost                          // Past lap compared against the live one
+
+	speed    speedFuser      // GPS + VSS speed, with VSS calibration
+	tireCal  tireCalibrator  // VSS vs GPS distance, for the tire circumference
+	slip     slipDetector    // Driven-wheel slip against GPS
+	boost    boostMonitor    // Boost control tracking and duty saturation
+	knock    knockMapper     // Knock events by RPM and load, this drive
+	knockLog knockRecorder   // Knock events with the data either side
+	trim     fuelTrimLearner // Long-term EGO correction by RPM and load
+	dfco     dfcoTracker     // Decel fuel cut time and fuel saved
+	fuel     fuelTracker     // Fuel used since the last fill-up
+	stats    statsTracker    // Peaks and channel ranges this drive
+	gears    gearLearner     // Gear ratios learned from RPM/speed
+	session  session         // Drive being recorded as GPS breadcrumbs
+	quiet    quiescence      // Engine-off reduced frames and sleep
+	cooldown cooldownTracker // Post-shutdown coolant countdown
+
+	// Threshold alerts, their history and snapshot bundles
+	alertMu       sync.Mutex
+	alerts        []Alert
+	alertSpans    map[string]*alertSpan // By Alert.ID, until ended
+	alertEvents   []AlertEvent          // Not yet sent in a frame
+	alertLog      []AlertEvent          // History, oldest first
+	alertLogDirty bool                  // History changed since last saved
+	rules         ruleEngine
+	notify        chan AlertEvent // To runNotify
+	history       frameHistory
+	lastAlertSnap time.Time
+
+	// Off-dash backup (runSync)
+	sync syncState
+
+	// Audible alarm (runAlarm)
+	alarm alarmState
+
+	// SignalK stream clients (runSignalK)
+	signalK signalKHub
+
+	// Latest data frame, for /api/data and the gRPC API
+	frames frameFeed
 }
 
 // Frame is the JSON structure sent to all WebSocket clients.
@@ -58,29 +178,106 @@ type Frame struct {
 	Config       *DisplayConfig    `json:"config,omitempty"`
 	Drivetrain   *DrivetrainConfig `json:"drivetrain,omitempty"`
 	Vehicle      *VehicleConfig    `json:"vehicle,omitempty"`
+	Identity     *IdentityConfig   `json:"identity,omitempty"` // Which car this is (initial frame)
 	Odo          *OdoData          `json:"odo,omitempty"`
 	Speed        *SpeedData        `json:"speed,omitempty"` // Calculated best-available speed
+	Slip         *SlipData         `json:"slip,omitempty"`  // Driven-wheel slip and wheelspin
+	Boost        *BoostStatus      `json:"boost,omitempty"` // Boost control diagnostics
+	Fuel         *FuelData         `json:"fuel,omitempty"`  // Consumption, tank and range
+	Stats        *SessionStats     `json:"stats,omitempty"` // Drive peaks, once a second with stats.in_frame
 	ECUConnected *bool             `json:"ecuConnected,omitempty"`
-	Stamp        int64             `json:"stamp"` // Unix ms
+	CalcGear     bool              `json:"calculatedGear,omitempty"` // ECU.Gear is from the drivetrain ratios, not the ECU
+	Stamp        int64             `json:"stamp"`                    // Unix ms
+	Injected     bool              `json:"injected,omitempty"`       // Debug fault injection active
+
+	// Additional ECU providers, keyed by their configured name
+	ECUs          map[string]*ecu.DataFrame `json:"ecus,omitempty"`
+	ECUsConnected map[string]bool           `json:"ecusConnected,omitempty"`
+
+	// Other instances' frames, keyed by their configured name
+	Remotes map[string]map[string]json.RawMessage `json:"remotes,omitempty"`
+
+	// Auxiliary sensor readings, keyed by their configured name
+	Sensors map[string]*sensors.Reading `json:"sensors,omitempty"`
+	EGT     []float64                   `json:"egt,omitempty"` // Per-cylinder EGT °C (egt[0] = cyl 1)
+	IMU     *imu.Data                   `json:"imu,omitempty"` // Accelerometer G and yaw rate
+
+	AFRSource string            `json:"afrSource,omitempty"` // "ecu", "external:<name>" or "blend:<name>"
+	Sources   map[string]string `json:"sources,omitempty"`   // ECU fields a sensor supplies instead (e.g. "oilPressure"), to the sensor's name
+
+	Autocross   *autox.Status `json:"autocross,omitempty"`   // Autocross run state
+	Laps        *laps.Status  `json:"laps,omitempty"`        // Lap timing
+	Perf        *perf.Status  `json:"perf,omitempty"`        // Performance timers
+	Ghost       *GhostData    `json:"ghost,omitempty"`       // Ghost lap comparison
+	Alerts      []Alert       `json:"alerts,omitempty"`      // Active threshold alerts
+	AlertEvents []AlertEvent  `json:"alertEvents,omitempty"` // Alerts started or ended since the last frame
+	Direction   string        `json:"direction,omitempty"`   // "forward", "reverse" or "stopped"
+
+	// Engine-off state: "off" frames carry only Quiet, Odo and Stamp
+	Power    string          `json:"power,omitempty"`
+	Quiet    *QuietData      `json:"quiet,omitempty"`
+	Cooldown *CooldownStatus `json:"cooldown,omitempty"` // Coolant countdown after engine-off
+
+	// Network status, in one frame every few seconds
+	Network *NetworkStatus `json:"network,omitempty"`
+
+	// Sent alone to one client when its frame rate is stepped down or up
+	Link *LinkStatus `json:"link,omitempty"`
+
+	// Sent alone to all clients when a keypad key or GPIO button is
+	// pressed, or a WebSocket client runs an action
+	Keypad *KeypadAction `json:"keypad,omitempty"`
+
+	// Sent alone to one WebSocket client in answer to its request
+	Reply *Reply `json:"reply,omitempty"`
+
+	// Set on heartbeat frames sent while both ECU and GPS are stale
+	NoData   bool  `json:"noData,omitempty"`
+	LastData int64 `json:"lastData,omitempty"` // Unix ms of the last ECU or GPS data
 }
 
 // OdoData is the odometer info sent to clients.
 type OdoData struct {
-	Total float64 `json:"total"` // km
-	Trip  float64 `json:"trip"`  // km
+	Total   float64    `json:"total"`          // km
+	Trip    float64    `json:"trip"`           // km, trip A
+	Reverse float64    `json:"reverse"`        // km reversed this trip A
+	DFCO    *DFCOStats `json:"dfco,omitempty"` // Decel fuel cut this trip A
+	TripA   *TripStats `json:"tripA"`
+	TripB   *TripStats `json:"tripB"`
+
+	EngineHours float64 `json:"engineHours"` // Total time with the engine turning
 }
 
 // SpeedData provides a unified speed value from the best available source.
 type SpeedData struct {
-	Value  float64 `json:"value"`  // km/h
-	Source string  `json:"source"` // "gps", "vss", or "none"
+	Value     float64 `json:"value"`              // km/h
+	Source    string  `json:"source"`             // "gps", "vss", "fusion", "hold" or "none"
+	GPSWeight float64 `json:"gpsWeight"`          // Share of Value from GPS: 0 = VSS only, 1 = GPS only
+	VSSScale  float64 `json:"vssScale,omitempty"` // Correction applied to VSS (learned or configured)
 }
 
+// odoFile is the odometer's name inside the data directory.
+const odoFile = storage.DirState + "/odometer.dat"
+
+// odoShadowFile is a second copy of odoFile, written after it, for when
+// the card corrupts the first.
+const odoShadowFile = odoFile + ".bak"
+
+// odoFlushKm is the distance after which the odometer is saved without
+// waiting for the 30 s tick.
+const odoFlushKm = 0.25
+
 // New creates a new Server.
 func New(cfg *Config, ecuProv ecu.Provider, gpsProv gps.Provider, webFS fs.FS) *Server {
-	odoPath := filepath.Join(filepath.Dir(cfg.path), "odometer.dat")
+	store := storage.New(cfg.Storage.DataDir)
+
+	// Pre-storage releases kept the odometer next to the config file
+	legacyOdo := filepath.Join(filepath.Dir(cfg.path), "odometer.dat")
 	if cfg.path == "" {
-		odoPath = "/etc/speeduino-dash/odometer.dat"
+		legacyOdo = "/etc/speeduino-dash/odometer.dat"
+	}
+	if err := store.Migrate(odoFile, legacyOdo); err != nil {
+		log.Printf("[odo] %v", err)
 	}
 
 	s := &Server{
@@ -92,14 +289,51 @@ func New(cfg *Config, ecuProv ecu.Provider, gpsProv gps.Provider, webFS fs.FS) *
 			Enabled:    cfg.Logging.Enabled,
 			Path:       cfg.Logging.Path,
 			IntervalMs: cfg.Logging.Interval,
+			Channels:   cfg.Logging.Channels,
+			Prefix:     slug(cfg.Identity.Name), // Tells cars' logs apart
 		}),
-		clients: make(map[*wsClient]struct{}),
+		clients:    make(map[*frameClient]struct{}),
+		odoFlush:   make(chan struct{}, 1),
+		notify:     make(chan AlertEvent, notifyQueue),
+		sensorLast: make(map[string]*sensors.Reading),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool { return true },
 		},
-		odoPath: odoPath,
+		store:   store,
+		started: time.Now(),
+		autox:   autox.New(time.Duration(cfg.Autocross.ConePenaltySec * float64(time.Second))),
+		laps:    laps.New(time.Duration(cfg.Laps.MinLapSec * float64(time.Second))),
+		perf:    perf.New(cfg.Perf.SpeedsKph),
+
+		gpsFilter: gps.NewFilter(cfg.GPS.Filter),
+	}
+	if cfg.GPS.RawLog && gpsProv != nil {
+		if t, ok := gpsProv.(gps.RawTee); ok {
+			t.SetRawTee(s.logger.NMEA)
+			log.Printf("[gps] raw NMEA logging to %s", s.logger.Dir())
+		} else {
+			log.Printf("[gps] raw_log: %s has no NMEA stream to log", gpsProv.Name())
+		}
 	}
+	s.logger.Recover()
+	s.influx = newInfluxSink(cfg.Influx, cfg.Identity.Name, store)
+	s.addRemotes(cfg.Remotes)
 	s.loadOdometer()
+	s.loadSpeedCal()
+	s.loadTrack()
+	s.loadTrackLibrary()
+	s.loadAutox()
+	s.loadLaps()
+	s.loadPerf()
+	s.loadTune()
+	s.loadFuelTrim()
+	s.loadDFCO()
+	s.loadTrips()
+	s.loadGearLearn()
+	s.loadFuel()
+	s.loadTireCal()
+	s.loadAlertHistory()
+	s.loadKnockEvents()
 	return s
 }
 
@@ -110,109 +344,272 @@ func (s *Server) Run(ctx context.Context) error {
 	// Serve embedded web files
 	mux.Handle("/", http.FileServer(http.FS(s.webFS)))
 
-	// WebSocket endpoint
+	// WebSocket endpoint, and the same frames as Server-Sent Events
 	mux.HandleFunc("/ws", s.handleWS)
+	mux.HandleFunc("/events", s.handleEvents)
+
+	// Latest frame, for clients that only poll
+	mux.HandleFunc("/api/data", s.handleData)
 
 	// Config API
 	mux.HandleFunc("/api/config", s.handleConfig)
 
 	// Odometer API
+	mux.HandleFunc("/api/odo/reset", s.handleResetTrip)
 	mux.HandleFunc("/api/odo/reset-trip", s.handleResetTrip)
+	mux.HandleFunc("/api/odo", s.handleOdometer)
+	mux.HandleFunc("/api/odo/hours", s.handleEngineHours)
+
+	// Fuel consumption and fill-ups
+	mux.HandleFunc("/api/fuel", s.handleFuel)
+	mux.HandleFunc("/api/fuel/fillup", s.handleFuel)
+
+	// Gear ratios learned from driving
+	mux.HandleFunc("/api/gears/learn", s.handleGearLearn)
+
+	// Tire circumference / VSS calibration from GPS
+	mux.HandleFunc("/api/speed/tirecal", s.handleTireCal)
+
+	// Alert history
+	mux.HandleFunc("/api/alerts", s.handleAlerts)
+	mux.HandleFunc("/api/alerts/test", s.handleNotifyTest)
+	mux.HandleFunc("/api/alarm", s.handleAlarm)
+	mux.HandleFunc("/api/alarm/mute", s.handleAlarmMute)
+
+	// Drive peaks and channel statistics
+	mux.HandleFunc("/api/stats", s.handleStats)
+
+	// Data log files
+	mux.HandleFunc("/api/logs", s.handleLogs)
+
+	// Off-dash backup of the odometer and records
+	mux.HandleFunc("/api/sync", s.handleSync)
+	mux.HandleFunc("/api/sync/backup", s.handleSyncBackup)
+
+	// Wake from engine-off sleep
+	mux.HandleFunc("/api/wake", s.handleWake)
+
+	// Track API
+	mux.HandleFunc("/api/track", s.handleTrack)
+	mux.HandleFunc("/api/track/startfinish", s.handleTrackStartFinish)
+	mux.HandleFunc("/api/tracks", s.handleTracks)
+	mux.HandleFunc("/api/tracks/select", s.handleTrackSelect)
+
+	// Autocross API
+	mux.HandleFunc("/api/autocross", s.handleAutocross)
+	mux.HandleFunc("/api/autocross/arm", s.handleAutocrossArm)
+	mux.HandleFunc("/api/autocross/penalty", s.handleAutocrossPenalty)
+	mux.HandleFunc("/api/autocross/runs", s.handleAutocrossRuns)
+	mux.HandleFunc("/api/autocross/profile", s.handleAutocrossProfile)
+
+	// Lap timing API
+	mux.HandleFunc("/api/laps", s.handleLaps)
+
+	// Performance timers API
+	mux.HandleFunc("/api/perf", s.handlePerf)
+	mux.HandleFunc("/api/overlay", s.handleOverlay)
+	mux.HandleFunc("/api/overlay/ghost", s.handleGhost)
+
+	// Knock map of the current drive, and knock events with context
+	mux.HandleFunc("/api/knock", s.handleKnock)
+	mux.HandleFunc("/api/knock/events", s.handleKnockEvents)
+
+	// Long-term fuel trims
+	mux.HandleFunc("/api/fueltrim", s.handleFuelTrim)
+
+	// Session GPS tracks (GPX/KML export)
+	mux.HandleFunc("/api/sessions", s.handleSessions)
+	mux.HandleFunc("/api/sessions/", s.handleSession)
+	mux.HandleFunc("/api/export", s.handleExport)
+
+	// Snapshot bundles
+	mux.HandleFunc("/api/snapshot", s.handleSnapshot)
+
+	// Network status
+	mux.HandleFunc("/api/network", s.handleNetwork)
+
+	// Auxiliary gauges
+	mux.HandleFunc("/api/gauges", s.handleGauges)
+
+	// RealDash channel description
+	mux.HandleFunc("/api/realdash.xml", s.handleRealDashXML)
+
+	// SignalK discovery and stream
+	mux.HandleFunc("/signalk", s.handleSignalK)
+	mux.HandleFunc("/signalk/", s.handleSignalK)
+	mux.HandleFunc("/signalk/v1/stream", s.handleSignalKStream)
+
+	// UDP telemetry packet layout
+	mux.HandleFunc("/api/udp/schema", s.handleUDPSchema)
+
+	// Diagnostics API
+	mux.HandleFunc("/api/diagnostics", s.handleDiagnostics)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/api/ecu/command", s.handleECUCommand)
+	mux.HandleFunc("/api/ecu/sdlogs", s.handleSDLogs)
+	mux.HandleFunc("/api/ecu/toothlog", s.handleToothLog)
+	mux.HandleFunc("/api/ecu/toothlog/start", s.handleToothLog)
+
+	// Tune reference values (TunerStudio MSQ upload)
+	mux.HandleFunc("/api/tune", s.handleTune)
+
+	// OS maintenance API (only active with system.enabled)
+	mux.HandleFunc("/api/system", s.handleSystem)
+	mux.HandleFunc("/api/system/hotspot", s.handleHotspot)
+	mux.HandleFunc("/api/system/", s.handleSystemAction)
+
+	// Debug API (only active with server.debug)
+	mux.HandleFunc("/api/debug/inject", s.handleDebugInject)
 
 	// Start data polling — ECU and GPS are independent
 	go s.pollLoop(ctx)
 
-	// Persist odometer every 30 seconds
+	srv := &http.Server{
+		Addr:    s.cfg.Server.ListenAddr,
+		Handler: mux,
+		// Requests end with the server, so shutdown isn't held up by a
+		// slow one such as an ECU link reset
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	srv.RegisterOnShutdown(s.closeClients) // SSE streams would hold up Shutdown
+
+	// Persist state every 30 seconds, the odometer sooner after odoFlushKm,
+	// and everything once more on the way out
 	s.odoTicker = time.NewTicker(30 * time.Second)
 	go func() {
 		for {
 			select {
 			case <-ctx.Done():
-				s.saveOdometer()
+				s.saveState()
+				shutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				srv.Shutdown(shutCtx)
 				return
 			case <-s.odoTicker.C:
+				s.saveState()
+			case <-s.odoFlush:
 				s.saveOdometer()
 			}
 		}
 	}()
 
-	srv := &http.Server{
-		Addr:    s.cfg.Server.ListenAddr,
-		Handler: mux,
-	}
-
-	go func() {
-		<-ctx.Done()
-		s.saveOdometer()
-		shutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		srv.Shutdown(shutCtx)
-	}()
-
 	log.Printf("[server] listening on %s", s.cfg.Server.ListenAddr)
 	return srv.ListenAndServe()
 }
 
+// saveState writes everything kept across restarts.
+func (s *Server) saveState() {
+	s.saveOdometer()
+	s.saveSpeedCal()
+	s.saveKnock(s.sessionID())
+	s.saveFuelTrim()
+	s.saveDFCO()
+	s.saveTrips()
+	s.saveGearLearn()
+	s.saveFuel()
+	s.saveTireCal()
+	s.saveAlertHistory()
+	s.saveKnockEvents()
+}
+
+// wsMsgpack is the WebSocket subprotocol for frames in MessagePack;
+// ?encoding=msgpack does the same for clients that can't set one.
+const wsMsgpack = "msgpack"
+
 func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
-	conn, err := s.upgrader.Upgrade(w, r, nil)
+	sub, err := subscriptionQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	var header http.Header
+	packed := r.URL.Query().Get("encoding") == wsMsgpack
+	for _, p := range websocket.Subprotocols(r) {
+		if p == wsMsgpack {
+			header = http.Header{"Sec-Websocket-Protocol": {wsMsgpack}}
+			packed = true
+		}
+	}
+	conn, err := s.upgrader.Upgrade(w, r, header)
 	if err != nil {
 		log.Printf("[ws] upgrade error: %v", err)
 		return
 	}
 
-	client := &wsClient{
-		conn: conn,
-		send: make(chan []byte, 64),
+	msgType := websocket.TextMessage
+	if packed {
+		msgType = websocket.BinaryMessage
 	}
+	client := newFrameClient("ws", conn.RemoteAddr().String(), func(data []byte) error {
+		conn.SetWriteDeadline(time.Now().Add(clientWriteTimeout))
+		return conn.WriteMessage(msgType, data)
+	})
+	client.msgpack = packed
+	client.delta, _ = strconv.ParseBool(r.URL.Query().Get("delta"))
+	client.sub = sub
+	s.addClient(client)
 
+	// Writer goroutine
+	go func() {
+		client.writeLoop()
+		conn.Close()
+	}()
+
+	// Reader goroutine (requests, see wsmessage.go)
+	go func() {
+		defer s.removeClient(client)
+		page := s.cfg.DisplaySnapshot().Layout
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				break
+			}
+			s.wsMessage(client, msg, &page)
+		}
+	}()
+}
+
+// addClient registers a client for broadcasts and queues the initial
+// config and odometer frame.
+func (s *Server) addClient(client *frameClient) {
 	s.clientsMu.Lock()
 	s.clients[client] = struct{}{}
+	n := len(s.clients)
 	s.clientsMu.Unlock()
-
-	log.Printf("[ws] client connected (%d total)", len(s.clients))
-
-	// Send initial config + odometer
-	s.odoMu.Lock()
-	odo := &OdoData{Total: s.odoTotal, Trip: s.odoTrip}
-	s.odoMu.Unlock()
+	log.Printf("[%s] client connected (%d total)", client.kind, n)
 
 	cfgFrame := Frame{
 		Config:     &s.cfg.Display,
 		Drivetrain: &s.cfg.Drivetrain,
 		Vehicle:    &s.cfg.Vehicle,
-		Odo:        odo,
+		Odo:        s.odoData(),
 		Stamp:      time.Now().UnixMilli(),
 	}
+	if id := s.cfg.IdentitySnapshot(); !id.IsZero() {
+		cfgFrame.Identity = &id
+	}
 	if data, err := json.Marshal(cfgFrame); err == nil {
-		client.send <- data
+		client.enqueue(client.encode(data), true)
 	}
+}
 
-	// Writer goroutine
-	go func() {
-		defer conn.Close()
-		for msg := range client.send {
-			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
-				break
-			}
-		}
-	}()
+// removeClient stops broadcasts to a client and its writer.
+func (s *Server) removeClient(client *frameClient) {
+	s.clientsMu.Lock()
+	delete(s.clients, client)
+	n := len(s.clients)
+	s.clientsMu.Unlock()
+	client.close()
+	log.Printf("[%s] client disconnected (%d total)", client.kind, n)
+}
 
-	// Reader goroutine (handle incoming messages / keep-alive)
-	go func() {
-		defer func() {
-			s.clientsMu.Lock()
-			delete(s.clients, client)
-			s.clientsMu.Unlock()
-			close(client.send)
-			log.Printf("[ws] client disconnected (%d total)", len(s.clients))
-		}()
-		for {
-			_, _, err := conn.ReadMessage()
-			if err != nil {
-				break
-			}
-		}
-	}()
+// closeClients ends every client's writer, on shutdown.
+func (s *Server) closeClients() {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+	for c := range s.clients {
+		c.close()
+	}
 }
 
 func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
@@ -239,6 +636,7 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 		if err := s.cfg.Save(); err != nil {
 			log.Printf("[config] save failed: %v", err)
 		}
+		s.logger.SetPrefix(slug(s.cfg.IdentitySnapshot().Name))
 		// Broadcast updated config
 		cfgFrame := Frame{Config: &s.cfg.Display, Stamp: time.Now().UnixMilli()}
 		s.broadcast(cfgFrame)
@@ -251,19 +649,6 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) handleResetTrip(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", 405)
-		return
-	}
-	s.odoMu.Lock()
-	s.odoTrip = 0
-	s.odoMu.Unlock()
-	s.saveOdometer()
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"ok"}`))
-}
-
 // pollLoop continuously requests data from ECU and GPS independently,
 // then broadcasts combined frames. GPS continues even if ECU is unavailable.
 func (s *Server) pollLoop(ctx context.Context) {
@@ -272,24 +657,23 @@ func (s *Server) pollLoop(ctx context.Context) {
 		ecuHz = 20
 	}
 
-	gpsTicker := time.NewTicker(100 * time.Millisecond)                   // 10 Hz
+	gpsHz := 10
+	if s.cfg.GPS.RateHz > gpsHz {
+		gpsHz = s.cfg.GPS.RateHz // Keep up with fast UBX receivers
+	}
+
+	gpsTicker := time.NewTicker(time.Second / time.Duration(gpsHz))
 	broadcastTicker := time.NewTicker(time.Second / time.Duration(ecuHz)) // Match ECU rate
 	defer gpsTicker.Stop()
 	defer broadcastTicker.Stop()
+	gpsTiming := s.loops.add("gps", gpsHz)
+	broadcastTiming := s.loops.add("broadcast", ecuHz)
+
+	var lastECU *ecu.DataFrame // latest frame, updated from channel
+	var lastECUAt time.Time    // when lastECU arrived
+	var lastHeartbeat time.Time
+	var lastStats time.Time // Last frame carrying stats
 
-	var (
-		lastECU *ecu.DataFrame // latest frame, updated from channel
-		lastGPS *gps.Data
-		gpsMu   sync.Mutex
-	)
-
-	// 3-stage async pipeline:
-	//   Serial goroutine → rawCh (*RawData) → Parser goroutine → ecuCh (*DataFrame) → Broadcast
-	//
-	// The serial goroutine only does wire I/O (send command → read bytes).
-	// Parsing happens async in a separate goroutine so the serial thread
-	// can immediately loop back for the next poll cycle.
-	rawCh := make(chan *ecu.RawData, 2)   // serial → parser
 	ecuCh := make(chan *ecu.DataFrame, 2) // parser → broadcast
 
 	// GPS polling goroutine — runs independently
@@ -300,13 +684,23 @@ func (s *Server) pollLoop(ctx context.Context) {
 				return
 			case <-gpsTicker.C:
 				if s.gpsProv != nil {
+					gpsTiming.tick(time.Now())
 					if data, err := s.gpsProv.Read(); err == nil {
-						gpsMu.Lock()
-						lastGPS = data
-						gpsMu.Unlock()
+						readAt := time.Now()
+						data = s.gpsFilter.Apply(data)
+						// Copy: providers may reuse their fix struct
+						snap := *data
+						s.gpsMu.Lock()
+						s.lastGPS = &snap
+						s.lastGPSAt = readAt
+						s.gpsMu.Unlock()
+						if s.cfg.GPS.SetClock {
+							s.syncClock(&snap, readAt)
+						}
+						dir := s.direction.update(&snap, s.reverseGear.Load())
 						// Update odometer with GPS distance
 						if data.Valid && data.Speed > 1 { // Only accumulate if moving
-							s.updateOdometer(data)
+							s.updateOdometer(data, dir == dirReverse)
 						}
 					}
 				}
@@ -314,117 +708,79 @@ func (s *Server) pollLoop(ctx context.Context) {
 		}
 	}()
 
-	// Stage 1: Serial I/O goroutine — owns the serial port exclusively.
-	// Does ONLY wire I/O: send poll command → read raw bytes → push to rawCh.
-	// No parsing, no CPU work — gets back to the serial port ASAP.
-	go func() {
-		var (
-			lastErrLog     time.Time
-			consecErrors   int
-			reconnectDelay = 2 * time.Second
-			maxReconnDelay = 30 * time.Second
-			pollInterval   = time.Second / time.Duration(ecuHz)
-		)
-		const maxConsecErrors = 10
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-			}
-
-			if s.ecuProv == nil {
-				time.Sleep(pollInterval)
-				continue
-			}
+	// Primary ECU pipeline (serial → parser → ecuCh)
+	s.runECUPipeline(ctx, "ecu", s.ecuProv, ecuHz, newChannelPruner("ecu", s.cfg.ECU), ecuCh)
 
-			// Reconnection — blocks here until connected
-			if !s.ecuProv.IsConnected() {
-				if time.Since(lastErrLog) > reconnectDelay {
-					log.Printf("[ecu] attempting reconnection...")
-					if err := s.ecuProv.Connect(); err != nil {
-						log.Printf("[ecu] reconnect failed: %v (retry in %v)", err, reconnectDelay)
-						lastErrLog = time.Now()
-						reconnectDelay *= 2
-						if reconnectDelay > maxReconnDelay {
-							reconnectDelay = maxReconnDelay
-						}
-					} else {
-						log.Printf("[ecu] reconnected successfully")
-						consecErrors = 0
-						reconnectDelay = 2 * time.Second
-					}
-				}
-				time.Sleep(pollInterval)
-				continue
-			}
-
-			// Serial I/O only — send command, read raw bytes
-			raw, err := s.ecuProv.RequestRawData()
-			if err == nil {
-				consecErrors = 0
-				// Non-blocking send to parser
-				select {
-				case rawCh <- raw:
-				default:
-					select {
-					case <-rawCh:
-					default:
-					}
-					rawCh <- raw
-				}
-			} else {
-				consecErrors++
-				if time.Since(lastErrLog) > 5*time.Second {
-					log.Printf("[ecu] poll error (%d consecutive): %v", consecErrors, err)
-					lastErrLog = time.Now()
-				}
-				if consecErrors >= maxConsecErrors {
-					log.Printf("[ecu] %d consecutive errors, closing for reconnect", consecErrors)
-					s.ecuProv.Close()
-					consecErrors = 0
-					reconnectDelay = 2 * time.Second
-				}
-			}
+	// Additional ECU pipelines, one per configured provider
+	extraChs := make(map[string]chan *ecu.DataFrame, len(s.extraECUs))
+	lastExtra := make(map[string]*ecu.DataFrame, len(s.extraECUs))
+	for _, x := range s.extraECUs {
+		ch := make(chan *ecu.DataFrame, 2)
+		extraChs[x.name] = ch
+		s.runECUPipeline(ctx, "ecu:"+x.name, x.prov, x.pollHz, x.prune, ch)
+	}
 
-			time.Sleep(pollInterval)
-		}
-	}()
+	// Auxiliary sensor readers
+	for _, a := range s.sensors {
+		go s.runSensor(ctx, a)
+	}
+	if s.imuProv != nil {
+		go s.runIMU(ctx)
+	}
+	if s.keypadProv != nil {
+		go s.runKeypad(ctx)
+	}
+	if g := s.cfg.GaugesSnapshot(); g.UDPListen != "" || len(g.Serial) > 0 {
+		go s.runGauges(ctx)
+	}
+	if c := s.cfg.CANOutSnapshot(); c.Interface != "" && len(c.Messages) > 0 {
+		go s.runCANOut(ctx)
+	}
+	if s.cfg.TelemetrySnapshot().Broker != "" {
+		go s.runTelemetry(ctx)
+	}
+	if r := s.cfg.RaceChronoSnapshot(); r.UDP != "" || r.Listen != "" {
+		go s.runRaceChrono(ctx)
+	}
+	if r := s.cfg.RealDashSnapshot(); r.Listen != "" && len(r.Channels) > 0 {
+		go s.runRealDash(ctx)
+	}
+	if k := s.cfg.SignalKSnapshot(); k.Listen != "" || k.UDP != "" || k.WebSocket {
+		go s.runSignalK(ctx)
+	}
+	if s.cfg.UDPOutSnapshot().Target != "" {
+		go s.runUDPOut(ctx)
+	}
+	if s.cfg.GRPCSnapshot().Listen != "" {
+		go s.runGRPC(ctx)
+	}
+	if g := s.cfg.GPIOSnapshot(); len(g.Pins) > 0 || g.Strip.Device != "" || len(g.Buttons) > 0 {
+		go s.runGPIO(ctx)
+	}
+	go s.runNetwork(ctx)
+	go s.runNotify(ctx)
+	if s.cfg.AlarmSnapshot().Output != "" {
+		go s.runAlarm(ctx)
+	}
+	go s.runSync(ctx)
+	if s.influx != nil {
+		go s.influx.Run(ctx)
+	}
 
-	// Stage 2: Parser goroutine — CPU-only, no I/O.
-	// Reads raw bytes from rawCh, parses into DataFrames, pushes to ecuCh.
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case raw := <-rawCh:
-				if s.ecuProv == nil {
-					continue
-				}
-				frame := s.ecuProv.ParseRawData(raw)
-				// Non-blocking send to broadcast
-				select {
-				case ecuCh <- frame:
-				default:
-					select {
-					case <-ecuCh:
-					default:
-					}
-					ecuCh <- frame
-				}
-			}
-		}
-	}()
+	// Remote instance subscriptions
+	for _, r := range s.remotes {
+		go s.runRemote(ctx, r)
+	}
 
 	// Broadcast loop — combines latest ECU + GPS and sends to clients
 	for {
 		select {
 		case <-ctx.Done():
 			s.logger.Close()
+			s.endSession()
 			return
 		case <-broadcastTicker.C:
+			broadcastTiming.tick(time.Now()) // When handled, not when the ticker fired
 			// Drain the ECU channel for the latest frame (non-blocking).
 			// This ensures we always use the most recent data even if
 			// multiple frames arrived between broadcast ticks.
@@ -432,6 +788,7 @@ func (s *Server) pollLoop(ctx context.Context) {
 				select {
 				case frame := <-ecuCh:
 					lastECU = frame
+					lastECUAt = time.Now()
 				default:
 					goto DRAINED
 				}
@@ -439,24 +796,154 @@ func (s *Server) pollLoop(ctx context.Context) {
 		DRAINED:
 			ecuSnap := lastECU
 
-			gpsMu.Lock()
-			gpsSnap := lastGPS
-			gpsMu.Unlock()
+			// Same for each additional ECU
+			for name, ch := range extraChs {
+				for drained := false; !drained; {
+					select {
+					case frame := <-ch:
+						lastExtra[name] = frame
+					default:
+						drained = true
+					}
+				}
+			}
+
+			gpsSnap := s.latestGPS()
+
+			// Drop data that has gone stale rather than showing it as live
+			now := time.Now()
+			ecuLimit, gpsLimit := s.cfg.StaleLimits()
+			gpsAt := s.gpsUpdatedAt()
+			ecuStale := stale(now, lastECUAt, ecuLimit)
+			gpsStale := stale(now, gpsAt, gpsLimit)
+			if ecuStale {
+				ecuSnap = nil
+			}
+			if gpsStale {
+				gpsSnap = nil
+			}
+
+			// Merge auxiliary sensors (may override ECU AFR, add aux channels)
+			sensorSnap := s.sensorSnapshot()
+			ecuSnap, afrSource, afrAlert := s.selectAFR(now, ecuSnap, sensorSnap)
+			ecuSnap, sensorSources := s.mergeAuxSensors(ecuSnap, sensorSnap)
+			egt := s.collectEGT(sensorSnap)
+			imuSnap := s.imuSnapshot(now)
+
+			// Overlay any debug fault injection
+			ecuSnap, gpsSnap, injected := s.faults.apply(ecuSnap, gpsSnap)
 
 			// Calculate best-available speed
-			speed := s.calcSpeed(ecuSnap, gpsSnap)
+			speed := s.calcSpeed(now, ecuSnap, gpsSnap, injected)
+			slip := s.calcSlip(now, ecuSnap, gpsSnap, imuSnap, speed)
+
+			// Gear from the drivetrain ratios, over the ECU's
+			ecuSnap, calculatedGear := calcGear(ecuSnap, speed.Value, s.cfg.DrivetrainSnapshot())
+			s.setGaugeInput(ecuSnap, speed.Value, gpsSnap != nil && gpsSnap.Valid)
+
+			// Reverse gear hint for direction detection
+			s.reverseGear.Store(reverseGearEngaged(ecuSnap, speed.Value, s.cfg.DrivetrainSnapshot()))
+
+			// Odometer from VSS while there's no GPS fix
+			if !injected {
+				s.updateOdometerVSS(now, gpsSnap, speed, s.reverseGear.Load())
+			}
+
+			// Autocross run timing
+			autoxStatus := s.updateAutox(time.Now(), speed, gpsSnap)
+
+			// Circuit lap timing, on the track nearest the first fix
+			s.detectTrack(gpsSnap)
+			lapStatus, ghostData := s.updateLaps(time.Now(), speed, ecuSnap, gpsSnap)
+
+			// Session GPS breadcrumbs, for GPX/KML export
+			if !injected {
+				s.recordBreadcrumb(now, gpsSnap)
+			}
+
+			// Drag-strip performance timers
+			perfStatus := s.updatePerf(now, speed)
+
+			// Threshold alerts and rules (may trigger a snapshot bundle)
+			alerts := evalAlerts(ecuSnap, s.cfg.Thresholds(), s.tuneRef.Load())
+			alerts = append(alerts, s.rules.eval(now, ecuSnap, s.cfg.AlertRules())...)
+			if afrAlert != nil {
+				alerts = append(alerts, *afrAlert)
+			}
+			alerts = append(alerts, s.tireAlerts(sensorSnap)...)
+			if lb := s.checkLeanBoost(lastECUAt, ecuSnap, s.cfg.Thresholds()); lb != nil {
+				alerts = append(alerts, *lb)
+			}
+			s.knock.update(now, ecuSnap)
+			statsCfg := s.cfg.StatsSnapshot()
+			if !injected {
+				s.trim.update(now, ecuSnap)
+				fuelCfg := s.cfg.FuelSnapshot()
+				flow := fuelFlowLPH(ecuSnap, fuelCfg)
+				s.fuel.update(now, ecuSnap, flow)
+				s.dfco.update(now, ecuSnap, speed.Value, flow, fuelCfg)
+				s.updateTrips(now, speed.Value)
+				s.updateEngineHours(now, ecuSnap)
+				s.stats.update(now, ecuSnap, statsCfg.Channels)
+				s.knockLog.update(now, ecuSnap)
+				s.gears.update(now, ecuSnap, speed.Value, s.cfg.DrivetrainSnapshot())
+				s.tireCal.update(now, ecuSnap, gpsSnap, s.slip.spinning(), s.cfg.DrivetrainSnapshot().TireCircumM)
+			}
+			boost, boostAlert := s.boost.update(now, ecuSnap, s.cfg.Thresholds().BoostErrorWarn)
+			if boostAlert != nil {
+				alerts = append(alerts, *boostAlert)
+			}
+			s.checkAlertSnapshot(time.Now(), s.setAlerts(now, alerts))
+			s.setLights(ecuSnap, alerts)
 
 			// Get odometer
-			s.odoMu.Lock()
-			odo := &OdoData{Total: math.Round(s.odoTotal*10) / 10, Trip: math.Round(s.odoTrip*10) / 10}
-			s.odoMu.Unlock()
+			odo := s.odoData()
+
+			// Coolant countdown after shutdown
+			cooldown := s.cooldown.update(now, ecuSnap, s.cfg.CooldownSnapshot())
+
+			// Engine off: reduced frames at a low rate, then sleep. Polling
+			// carries on while the cooldown is still being tracked.
+			qcfg := s.cfg.QuiescentSnapshot()
+			if s.cooldown.cooling() {
+				qcfg.SleepAfterSec = 0
+			}
+			if power := s.quiet.update(now, ecuSnap, speed.Value, qcfg); power != powerOn {
+				if power == powerSleep {
+					s.endSession() // Waking starts a new drive
+					s.boost.reset()
+					s.knock.reset()
+					s.knockLog.reset()
+					s.dfco.resetDrive()
+					s.tireCal.resetDrive()
+					s.stats.reset()
+				}
+				interval := heartbeatInterval
+				if power == powerOff && qcfg.RateHz > 0 {
+					interval = time.Duration(float64(time.Second) / qcfg.RateHz)
+				}
+				if s.quiet.due(now, interval) {
+					frame := quietFrame(now, power, ecuSnap, odo)
+					if power == powerSleep {
+						frame.Quiet = nil // Clock and odometer only
+					}
+					frame.Cooldown = cooldown
+					s.broadcastData(frame)
+				}
+				continue
+			}
+
+			remoteSnap := s.remoteSnapshot(now)
 
 			// Only broadcast if we have at least something
-			if ecuSnap != nil || gpsSnap != nil {
+			if ecuSnap != nil || gpsSnap != nil || len(lastExtra) > 0 || len(sensorSnap) > 0 || remotesLive(remoteSnap) {
 				// ECU connection status
 				var ecuConn *bool
 				if s.ecuProv != nil {
 					c := s.ecuProv.IsConnected()
+					if injected && ecuSnap == nil {
+						c = false // injected ECU loss
+					}
 					ecuConn = &c
 				}
 
@@ -465,33 +952,96 @@ func (s *Server) pollLoop(ctx context.Context) {
 					GPS:          gpsSnap,
 					Odo:          odo,
 					Speed:        speed,
+					Slip:         slip,
+					Boost:        boost,
+					Fuel:         s.fuelData(speed.Value),
 					ECUConnected: ecuConn,
+					CalcGear:     calculatedGear,
 					Stamp:        time.Now().UnixMilli(),
+					Injected:     injected,
+					Sensors:      sensorSnap,
+					EGT:          egt,
+					IMU:          imuSnap,
+					Autocross:    autoxStatus,
+					Laps:         lapStatus,
+					Ghost:        ghostData,
+					Perf:         perfStatus,
+					Alerts:       alerts,
+					AlertEvents:  s.takeAlertEvents(),
+					Cooldown:     cooldown,
+					Remotes:      remoteSnap,
 				}
-				s.broadcast(frame)
-
-				// Record to CSV log
-				s.logger.Record(ecuSnap, gpsSnap)
+				if ecuSnap != nil && len(s.sensors) > 0 {
+					frame.AFRSource = afrSource
+					frame.Sources = sensorSources
+				}
+				if statsCfg.InFrame && now.Sub(lastStats) >= statsFrameEvery {
+					frame.Stats = s.stats.snapshot()
+					lastStats = now
+				}
+				if s.gpsProv != nil {
+					frame.Direction = s.direction.current()
+				}
+				if ecuStale && gpsStale {
+					// Only extra ECUs, sensors or remotes left
+					frame.NoData = true
+					frame.LastData = lastDataAt(lastECUAt, gpsAt)
+				}
+				if len(s.extraECUs) > 0 {
+					frame.ECUs = make(map[string]*ecu.DataFrame, len(lastExtra))
+					frame.ECUsConnected = make(map[string]bool, len(s.extraECUs))
+					for name, f := range lastExtra {
+						frame.ECUs[name] = f
+					}
+					for _, x := range s.extraECUs {
+						frame.ECUsConnected[x.name] = x.prov.IsConnected()
+					}
+				}
+				data := s.broadcastData(frame)
+				s.history.add(time.Now(), data)
+				s.frames.publish(&frame)
+
+				// Record to CSV log (synthetic faults stay out of the log)
+				if !injected {
+					s.updateLogRate(now, gpsSnap)
+					s.logger.Record(ecuSnap, gpsSnap, egt, imuSnap, (*logger.Slip)(slip))
+					s.recordInflux(now, ecuSnap, gpsSnap, speed.Value, egt, imuSnap)
+				}
+			} else if ecuStale && gpsStale && now.Sub(lastHeartbeat) >= heartbeatInterval {
+				// Total data loss: keep clients informed instead of going
+				// silent, with the last-known odometer
+				lastHeartbeat = now
+				var ecuConn *bool
+				if s.ecuProv != nil {
+					c := s.ecuProv.IsConnected()
+					ecuConn = &c
+				}
+				hb := Frame{
+					Odo:          odo,
+					ECUConnected: ecuConn,
+					Stamp:        now.UnixMilli(),
+					NoData:       true,
+					LastData:     lastDataAt(lastECUAt, gpsAt),
+					Remotes:      remoteSnap,
+				}
+				s.broadcastData(hb)
+				s.frames.publish(&hb)
 			}
 		}
 	}
 }
 
-// calcSpeed returns the best available speed from ECU VSS or GPS.
-func (s *Server) calcSpeed(ecuData *ecu.DataFrame, gpsData *gps.Data) *SpeedData {
-	// Prefer ECU VSS if available and > 0
-	if ecuData != nil && ecuData.VSS > 0 {
-		return &SpeedData{Value: float64(ecuData.VSS), Source: "vss"}
-	}
-	// Fall back to GPS speed
-	if gpsData != nil && gpsData.Valid {
-		return &SpeedData{Value: gpsData.Speed, Source: "gps"}
-	}
-	return &SpeedData{Value: 0, Source: "none"}
+// latestGPS returns the most recent GPS fix, or nil.
+func (s *Server) latestGPS() *gps.Data {
+	s.gpsMu.Lock()
+	defer s.gpsMu.Unlock()
+	return s.lastGPS
 }
 
 // updateOdometer accumulates distance from GPS position changes.
-func (s *Server) updateOdometer(data *gps.Data) {
+// Distance covered in reverse is tallied separately, not added to the
+// total or trip.
+func (s *Server) updateOdometer(data *gps.Data, reverse bool) {
 	s.odoMu.Lock()
 	defer s.odoMu.Unlock()
 
@@ -515,13 +1065,35 @@ func (s *Server) updateOdometer(data *gps.Data) {
 
 	// Minimum movement threshold: ~2 meters
 	if dist > 0.002 {
-		s.odoTotal += dist
-		s.odoTrip += dist
+		s.addDistanceLocked(dist, reverse)
 		s.lastGPSLat = data.Latitude
 		s.lastGPSLon = data.Longitude
+		s.odoMovedLocked()
 	}
 }
 
+// updateOdometerVSS integrates the calibrated VSS speed while there's no
+// GPS fix (GPS disabled, lost in a garage or tunnel). The next fix
+// reseeds the GPS odometer, so the stretch isn't counted twice.
+func (s *Server) updateOdometerVSS(now time.Time, g *gps.Data, speed *SpeedData, reverse bool) {
+	s.odoMu.Lock()
+	defer s.odoMu.Unlock()
+
+	if (g != nil && g.Valid) || speed.Source != "vss" {
+		s.odoVSSAt = time.Time{}
+		return
+	}
+	s.lastGPSValid = false
+	prev := s.odoVSSAt
+	s.odoVSSAt = now
+	dt := now.Sub(prev)
+	if prev.IsZero() || dt <= 0 || dt > time.Second {
+		return
+	}
+	s.addDistanceLocked(speed.Value*dt.Hours(), reverse)
+	s.odoMovedLocked()
+}
+
 // haversineKm calculates the great-circle distance between two lat/lon points.
 func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
 	const R = 6371.0 // Earth radius km
@@ -534,57 +1106,170 @@ func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
 	return R * c
 }
 
-// loadOdometer reads persisted odometer values from disk.
+// loadOdometer reads persisted odometer values from disk. Both the file
+// and its shadow are checked; the valid one with the greater total (then
+// engine hours) wins, so a copy torn or corrupted by a power cut loses
+// nothing.
 func (s *Server) loadOdometer() {
-	data, err := os.ReadFile(s.odoPath)
-	if err != nil {
-		log.Printf("[odo] no saved data at %s (starting at 0)", s.odoPath)
-		return
-	}
-	parts := strings.Split(strings.TrimSpace(string(data)), "\n")
-	if len(parts) >= 1 {
-		if v, err := strconv.ParseFloat(parts[0], 64); err == nil {
-			s.odoTotal = v
+	var best [5]float64
+	found := false
+	for _, name := range []string{odoFile, odoShadowFile} {
+		data, err := s.store.ReadFile(name)
+		if err != nil {
+			continue
 		}
-	}
-	if len(parts) >= 2 {
-		if v, err := strconv.ParseFloat(parts[1], 64); err == nil {
-			s.odoTrip = v
+		v, err := decodeOdometer(data)
+		if err != nil {
+			log.Printf("[odo] ignoring %s: %v", s.store.Path(name), err)
+			continue
+		}
+		if !found || v[0] > best[0] || (v[0] == best[0] && v[4] > best[4]) {
+			best, found = v, true
 		}
 	}
-	log.Printf("[odo] loaded: total=%.1f km, trip=%.1f km", s.odoTotal, s.odoTrip)
+	if !found {
+		log.Printf("[odo] no saved data at %s (starting at 0)", s.store.Path(odoFile))
+		s.odoFresh = true
+		return
+	}
+	s.odoTotal, s.odoTrip, s.odoReverse, s.odoTripB = best[0], best[1], best[2], best[3]
+	s.engineHours = best[4]
+	s.odoSaved = s.odoTotal + s.odoReverse
+	log.Printf("[odo] loaded: total=%.1f km, trip A=%.1f km, trip B=%.1f km, engine %.1f h",
+		s.odoTotal, s.odoTrip, s.odoTripB, s.engineHours)
 }
 
-// saveOdometer persists odometer values to disk.
+// saveOdometer persists odometer values to disk: the file, then its
+// shadow, each replaced atomically.
 func (s *Server) saveOdometer() {
 	s.odoMu.Lock()
 	total := s.odoTotal
 	trip := s.odoTrip
+	reverse := s.odoReverse
+	tripB := s.odoTripB
+	hours := s.engineHours
+	s.odoSaved = total + reverse
 	s.odoMu.Unlock()
 
-	// Ensure directory exists
-	os.MkdirAll(filepath.Dir(s.odoPath), 0755)
+	data := encodeOdometer(total, trip, reverse, tripB, hours)
+	for _, name := range []string{odoFile, odoShadowFile} {
+		if err := s.store.WriteFile(name, data); err != nil {
+			log.Printf("[odo] save failed: %v", err)
+			return
+		}
+	}
+}
+
+// odoMovedLocked asks for a save once the odometer has moved odoFlushKm
+// since the last one, so a power cut loses at most that much. Called with
+// odoMu held.
+func (s *Server) odoMovedLocked() {
+	if s.odoTotal+s.odoReverse-s.odoSaved < odoFlushKm {
+		return
+	}
+	select {
+	case s.odoFlush <- struct{}{}:
+	default:
+	}
+}
+
+// encodeOdometer formats the odometer file: total, trip A, reverse and
+// trip B km and engine hours, one per line, then a CRC-32 of those lines.
+func encodeOdometer(total, trip, reverse, tripB, hours float64) []byte {
+	body := fmt.Sprintf("%.6f\n%.6f\n%.6f\n%.6f\n%.6f\n", total, trip, reverse, tripB, hours)
+	return []byte(fmt.Sprintf("%scrc32 %08x\n", body, crc32.ChecksumIEEE([]byte(body))))
+}
 
-	data := fmt.Sprintf("%.6f\n%.6f\n", total, trip)
-	if err := os.WriteFile(s.odoPath, []byte(data), 0644); err != nil {
-		log.Printf("[odo] save failed: %v", err)
+// decodeOdometer parses an odometer file. Files from releases before the
+// checksum are accepted without one, and missing trailing values are 0.
+func decodeOdometer(data []byte) ([5]float64, error) {
+	var v [5]float64
+	body, sum, ok := strings.Cut(string(data), "crc32 ")
+	if ok {
+		want, err := strconv.ParseUint(strings.TrimSpace(sum), 16, 32)
+		if err != nil || uint32(want) != crc32.ChecksumIEEE([]byte(body)) {
+			return v, fmt.Errorf("checksum mismatch")
+		}
 	}
+	parts := strings.Split(strings.TrimSpace(body), "\n")
+	for i := 0; i < len(parts) && i < len(v); i++ {
+		f, err := strconv.ParseFloat(parts[i], 64)
+		if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+			return v, fmt.Errorf("bad value %q", parts[i])
+		}
+		v[i] = f
+	}
+	return v, nil
+}
+
+// broadcast sends frame to every client and returns its encoding.
+func (s *Server) broadcast(frame Frame) []byte {
+	return s.send(frame, false)
 }
 
-func (s *Server) broadcast(frame Frame) {
+// broadcastData is broadcast for the poll loop's data, engine-off and
+// heartbeat frames, which delta clients get as changes from the last.
+func (s *Server) broadcastData(frame Frame) []byte {
+	return s.send(frame, true)
+}
+
+func (s *Server) send(frame Frame, isData bool) []byte {
+	if frame.Config == nil {
+		frame.Network = s.networkFrame()
+	}
 	data, err := json.Marshal(frame)
 	if err != nil {
-		return
+		return nil
 	}
 
+	s.ws.lastBytes.Store(int64(len(data)))
+
 	s.clientsMu.RLock()
 	defer s.clientsMu.RUnlock()
 
+	// Settings and network frames must arrive; data frames only need the
+	// newest, and only as often as the client's link keeps up with
+	keep := frame.Config != nil || frame.Network != nil
+	now := time.Now()
+	var packed []byte         // data as MessagePack, once a client wants it
+	var parsed map[string]any // data decoded, once a delta or subscribed client wants it
 	for client := range s.clients {
-		select {
-		case client.send <- data:
-		default:
-			// Client too slow, skip
+		if isData && !keep && !client.due(now) {
+			continue
+		}
+		msg := clientMessage{data: data, keep: keep}
+		sub := client.subscription()
+		switch {
+		case isData && (client.delta || sub.filtering()):
+			if parsed == nil && json.Unmarshal(data, &parsed) != nil {
+				continue
+			}
+			f := sub.filter(parsed)
+			if sub.filtering() {
+				if msg.data, err = json.Marshal(f); err != nil {
+					continue
+				}
+			}
+			if client.delta {
+				msg.frame = f
+			} else {
+				msg.data = client.encode(msg.data)
+			}
+		case client.msgpack:
+			if packed == nil {
+				packed = client.encode(data)
+			}
+			msg.data = packed
+		}
+		if client.push(msg) {
+			s.ws.dropped.Add(1)
+		}
+		s.ws.sent.Add(1)
+		if st := client.adjust(now); st != nil {
+			if msg, err := json.Marshal(Frame{Link: st, Stamp: now.UnixMilli()}); err == nil {
+				client.enqueue(client.encode(msg), true)
+			}
 		}
 	}
+	return data
 }
@@ -0,0 +1,314 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/breadcrumb"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+const (
+	sessionDir      = storage.DirRecords + "/sessions"
+	sessionIDLayout = "20060102-150405" // Session IDs are their start time
+	maxSessions     = 100               // Older sessions are deleted
+)
+
+// sessionFile returns the breadcrumb file of session id.
+func sessionFile(id string) string {
+	return sessionDir + "/" + id + ".csv"
+}
+
+// sessionMetaFile returns the file holding session id's vehicle identity.
+func sessionMetaFile(id string) string {
+	return sessionDir + "/" + id + ".json"
+}
+
+// sessionMeta is stored beside a session's breadcrumbs. The summary
+// fields are added when the session ends.
+type sessionMeta struct {
+	Vehicle IdentityConfig `json:"vehicle"`
+	DFCO    *DFCOStats     `json:"dfco,omitempty"`  // Decel fuel cut this drive
+	Stats   *SessionStats  `json:"stats,omitempty"` // Peaks and channel ranges this drive
+}
+
+// validSessionID reports whether id is a session ID (and so safe to use
+// in a path).
+func validSessionID(id string) bool {
+	_, err := time.ParseInLocation(sessionIDLayout, id, time.Local)
+	return err == nil
+}
+
+// session is the drive being recorded. It starts at the first GPS fix and
+// ends when the dash sleeps or shuts down.
+type session struct {
+	mu  sync.Mutex
+	id  string
+	rec *breadcrumb.Recorder
+}
+
+// recordBreadcrumb adds the fix to the current session, starting one if
+// needed.
+func (s *Server) recordBreadcrumb(now time.Time, g *gps.Data) {
+	if g == nil || !g.Valid {
+		return
+	}
+	ss := &s.session
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.rec == nil {
+		dir, err := s.store.Dir(sessionDir)
+		if err != nil {
+			return
+		}
+		id := now.Format(sessionIDLayout)
+		rec, err := breadcrumb.Create(dir + "/" + id + ".csv")
+		if err != nil {
+			log.Printf("[session] %v", err)
+			return
+		}
+		ss.id, ss.rec = id, rec
+		if v := s.cfg.IdentitySnapshot(); !v.IsZero() {
+			if err := s.store.WriteJSON(sessionMetaFile(id), sessionMeta{Vehicle: v}); err != nil {
+				log.Printf("[session] %v", err)
+			}
+		}
+		log.Printf("[session] started %s", id)
+		s.pruneSessions()
+	}
+	err := ss.rec.Add(breadcrumb.Point{
+		Time:  now.UnixMilli(),
+		Lat:   g.Latitude,
+		Lon:   g.Longitude,
+		Alt:   g.Altitude,
+		Speed: g.Speed,
+	})
+	if err != nil {
+		log.Printf("[session] %v", err)
+	}
+}
+
+// endSession closes the current session, if any.
+func (s *Server) endSession() {
+	ss := &s.session
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.rec == nil {
+		return
+	}
+	n := ss.rec.Len()
+	ss.rec.Close()
+	s.saveKnock(ss.id)
+	s.saveFuelTrim()
+	dfco, stats := s.dfco.driveStats(), s.stats.snapshot()
+	if dfco != nil || stats != nil {
+		m := s.readSessionMeta(ss.id)
+		if m == nil {
+			m = &sessionMeta{Vehicle: s.cfg.IdentitySnapshot()}
+		}
+		m.DFCO, m.Stats = dfco, stats
+		if err := s.store.WriteJSON(sessionMetaFile(ss.id), m); err != nil {
+			log.Printf("[session] %v", err)
+		}
+	}
+	log.Printf("[session] ended %s (%d points)", ss.id, n)
+	ss.id, ss.rec = "", nil
+}
+
+// sessionIDs returns the recorded session IDs, newest first.
+func (s *Server) sessionIDs() []string {
+	names, err := s.store.List(sessionDir)
+	if err != nil {
+		log.Printf("[session] list failed: %v", err)
+	}
+	var ids []string
+	for _, n := range names {
+		if id, ok := strings.CutSuffix(n, ".csv"); ok && validSessionID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids
+}
+
+// pruneSessions deletes the oldest sessions past maxSessions.
+func (s *Server) pruneSessions() {
+	ids := s.sessionIDs()
+	for i := maxSessions; i < len(ids); i++ {
+		s.removeSession(ids[i])
+	}
+}
+
+// removeSession deletes a session's files.
+func (s *Server) removeSession(id string) error {
+	s.store.Remove(sessionMetaFile(id))
+	s.store.Remove(sessionKnockFile(id))
+	return s.store.Remove(sessionFile(id))
+}
+
+// readSessionMeta returns a session's metadata, if it has any.
+func (s *Server) readSessionMeta(id string) *sessionMeta {
+	var m sessionMeta
+	if !s.store.Exists(sessionMetaFile(id)) || s.store.ReadJSON(sessionMetaFile(id), &m) != nil {
+		return nil
+	}
+	return &m
+}
+
+// sessionVehicle returns the identity of the car a session was recorded
+// in, if it had one.
+func (s *Server) sessionVehicle(id string) *IdentityConfig {
+	m := s.readSessionMeta(id)
+	if m == nil || m.Vehicle.IsZero() {
+		return nil
+	}
+	return &m.Vehicle
+}
+
+// handleSessions lists recorded sessions.
+//
+//	GET /api/sessions — newest first
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	s.session.mu.Lock()
+	active := s.session.id
+	s.session.mu.Unlock()
+
+	type sessionInfo struct {
+		ID      string          `json:"id"`
+		Start   int64           `json:"start"` // Unix ms
+		Bytes   int64           `json:"bytes"`
+		Active  bool            `json:"active"` // Still recording
+		Vehicle *IdentityConfig `json:"vehicle,omitempty"`
+		DFCO    *DFCOStats      `json:"dfco,omitempty"`  // Set once the session has ended
+		Peaks   *SessionPeaks   `json:"peaks,omitempty"` // Likewise
+	}
+	out := []sessionInfo{}
+	for _, id := range s.sessionIDs() {
+		t, _ := time.ParseInLocation(sessionIDLayout, id, time.Local)
+		info := sessionInfo{ID: id, Start: t.UnixMilli(), Active: id == active}
+		if m := s.readSessionMeta(id); m != nil {
+			if !m.Vehicle.IsZero() {
+				info.Vehicle = &m.Vehicle
+			}
+			info.DFCO = m.DFCO
+			if m.Stats != nil {
+				info.Peaks = &m.Stats.Peaks
+			}
+		}
+		if fi, err := os.Stat(s.store.Path(sessionFile(id))); err == nil {
+			info.Bytes = fi.Size()
+		}
+		out = append(out, info)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// handleSession serves one session.
+//
+//	GET    /api/sessions/{id}/track.gpx[?lap=N] — GPS path as GPX
+//	GET    /api/sessions/{id}/track.kml[?lap=N] — GPS path as KML
+//	GET    /api/sessions/{id}/knock[?format=csv] — knock map
+//	DELETE /api/sessions/{id}
+//
+// With lap, only that lap's part of the path is exported.
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	id, file, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/sessions/"), "/")
+	if !validSessionID(id) || !s.store.Exists(sessionFile(id)) {
+		http.Error(w, "session not found", 404)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodDelete && file == "":
+		s.session.mu.Lock()
+		active := s.session.id == id
+		s.session.mu.Unlock()
+		if active {
+			http.Error(w, "session is still recording", 409)
+			return
+		}
+		if err := s.removeSession(id); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	case r.Method == http.MethodGet && (file == "track.gpx" || file == "track.kml"):
+		f, err := os.Open(s.store.Path(sessionFile(id)))
+		if err != nil {
+			http.Error(w, "session not found", 404)
+			return
+		}
+		pts, err := breadcrumb.Read(f)
+		f.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		name, base := "Session "+id, id
+		if v := s.sessionVehicle(id); v != nil && v.Name != "" {
+			name = v.Name + " session " + id
+			base = slug(v.Name) + "_" + id
+		}
+		if v := r.URL.Query().Get("lap"); v != "" {
+			if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+				http.Error(w, "bad lap number", 400)
+				return
+			}
+			lap, ok := s.findLap(v)
+			if !ok {
+				http.Error(w, "lap not found", 404)
+				return
+			}
+			pts = breadcrumb.Between(pts, lap.Start, lap.Start+lap.TimeMs)
+			if len(pts) == 0 {
+				http.Error(w, "lap not in this session", 404)
+				return
+			}
+			name += " lap " + v
+			base += "_lap" + v
+		}
+
+		if file == "track.gpx" {
+			w.Header().Set("Content-Type", "application/gpx+xml")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+base+`.gpx"`)
+			breadcrumb.WriteGPX(w, name, pts)
+		} else {
+			w.Header().Set("Content-Type", "application/vnd.google-earth.kml+xml")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+base+`.kml"`)
+			breadcrumb.WriteKML(w, name, pts)
+		}
+
+	case r.Method == http.MethodGet && file == "knock":
+		var m KnockMap
+		if !s.store.Exists(sessionKnockFile(id)) || s.store.ReadJSON(sessionKnockFile(id), &m) != nil {
+			http.Error(w, "no knock data for this session", 404)
+			return
+		}
+		base := id
+		if v := s.sessionVehicle(id); v != nil && v.Name != "" {
+			base = slug(v.Name) + "_" + id
+		}
+		writeKnock(w, r, &m, base+"_knock")
+
+	case file == "" || file == "track.gpx" || file == "track.kml" || file == "knock":
+		http.Error(w, "method not allowed", 405)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	psiToPa   = 6894.757
+	celsiusK  = 273.15
+	skVersion = "1.7.0" // SignalK specification the deltas follow
+)
+
+// signalKHub holds the clients of /signalk/v1/stream.
+type signalKHub struct {
+	mu      sync.Mutex
+	clients map[*websocket.Conn]struct{}
+}
+
+// skDelta is a SignalK delta message.
+type skDelta struct {
+	Context string     `json:"context"`
+	Updates []skUpdate `json:"updates"`
+}
+
+type skUpdate struct {
+	Source    skSource  `json:"source"`
+	Timestamp string    `json:"timestamp"`
+	Values    []skValue `json:"values"`
+}
+
+type skSource struct {
+	Label string `json:"label"`
+}
+
+type skValue struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// signalKSelf is the vessel's URN, stable for this host, which deltas
+// are sent in the context of.
+func signalKSelf() string {
+	host, _ := os.Hostname()
+	h := sha1.Sum([]byte("goefidash:" + host))
+	h[6] = h[6]&0x0F | 0x50 // Name-based UUID
+	h[8] = h[8]&0x3F | 0x80
+	return fmt.Sprintf("urn:mrn:signalk:uuid:%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// signalKDelta builds the tick's delta, or returns nil if there's nothing
+// to send.
+func (s *Server) signalKDelta(cfg SignalKConfig, self string, now time.Time) []byte {
+	var values []skValue
+	add := func(path string, v float64) {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			values = append(values, skValue{Path: path, Value: v})
+		}
+	}
+
+	if in := s.gauges.latest.Load(); in != nil {
+		engine := "propulsion." + cfg.Engine + "."
+		builtin := []struct {
+			channel, path string
+			scale, offset float64
+		}{
+			{"rpm", engine + "revolutions", 1.0 / 60, 0},
+			{"coolant", engine + "temperature", 1, celsiusK},
+			{"iat", engine + "intakeManifoldTemperature", 1, celsiusK},
+			{"oilPressure", engine + "oilPressure", psiToPa, 0},
+			{"fuelPressure", engine + "fuel.pressure", psiToPa, 0},
+			{"batteryVoltage", "electrical.batteries." + cfg.Battery + ".voltage", 1, 0},
+		}
+		for _, b := range builtin {
+			if v, ok := in.value(b.channel); ok {
+				add(b.path, v*b.scale+b.offset)
+			}
+		}
+		for _, p := range cfg.Paths {
+			scale := p.Scale
+			if scale == 0 {
+				scale = 1
+			}
+			if v, ok := in.value(p.Channel); ok {
+				add(p.Path, v*scale+p.Offset)
+			}
+		}
+	}
+
+	if g := s.latestGPS(); g != nil && g.Valid {
+		values = append(values, skValue{Path: "navigation.position", Value: map[string]float64{
+			"latitude":  g.Latitude,
+			"longitude": g.Longitude,
+			"altitude":  g.Altitude,
+		}})
+		add("navigation.speedOverGround", g.Speed/3.6)
+		add("navigation.courseOverGroundTrue", g.Heading*math.Pi/180)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	out, err := json.Marshal(skDelta{
+		Context: self,
+		Updates: []skUpdate{{
+			Source:    skSource{Label: "goefidash"},
+			Timestamp: now.UTC().Format("2006-01-02T15:04:05.000Z"),
+			Values:    values,
+		}},
+	})
+	if err != nil {
+		return nil
+	}
+	return append(out, '\n')
+}
+
+// runSignalK sends deltas at the configured rate over TCP, UDP and the
+// WebSocket stream until ctx ends.
+func (s *Server) runSignalK(ctx context.Context) {
+	cfg := s.signalKConfig()
+	self := signalKSelf()
+
+	var udp net.Conn
+	if cfg.UDP != "" {
+		c, err := net.Dial("udp", cfg.UDP)
+		if err != nil {
+			log.Printf("[signalk] udp %s: %v", cfg.UDP, err)
+		} else {
+			udp = c
+			defer udp.Close()
+			log.Printf("[signalk] sending to udp %s", cfg.UDP)
+		}
+	}
+	var tcp *tcpFanout
+	if cfg.Listen != "" {
+		f, err := listenFanout(ctx, "signalk", cfg.Listen)
+		if err != nil {
+			log.Printf("[signalk] listen %s: %v", cfg.Listen, err)
+		} else {
+			tcp = f
+			log.Printf("[signalk] listening on tcp %s", cfg.Listen)
+		}
+	}
+	if udp == nil && tcp == nil && !cfg.WebSocket {
+		return
+	}
+
+	rate := cfg.RateHz
+	if rate <= 0 {
+		rate = 1
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.signalK.closeAll()
+			return
+		case now := <-ticker.C:
+			delta := s.signalKDelta(cfg, self, now)
+			if delta == nil {
+				continue
+			}
+			if udp != nil {
+				udp.Write(delta)
+			}
+			if tcp != nil {
+				tcp.send(delta)
+			}
+			s.signalK.send(delta)
+		}
+	}
+}
+
+// signalKConfig returns the SignalK settings with defaults filled in.
+func (s *Server) signalKConfig() SignalKConfig {
+	cfg := s.cfg.SignalKSnapshot()
+	if cfg.Engine == "" {
+		cfg.Engine = "main"
+	}
+	if cfg.Battery == "" {
+		cfg.Battery = "starter"
+	}
+	return cfg
+}
+
+// send writes a delta to every stream client, dropping those that fail.
+func (h *signalKHub) send(b []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		c.SetWriteDeadline(time.Now().Add(fanoutWrite))
+		if err := c.WriteMessage(websocket.TextMessage, b); err != nil {
+			c.Close()
+			delete(h.clients, c)
+		}
+	}
+}
+
+func (h *signalKHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		c.Close()
+		delete(h.clients, c)
+	}
+}
+
+// handleSignalK is SignalK's discovery document, pointing clients at the
+// stream.
+//
+//	GET /signalk
+func (s *Server) handleSignalK(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.SignalKSnapshot().WebSocket || (r.URL.Path != "/signalk" && r.URL.Path != "/signalk/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	scheme := "ws"
+	if r.TLS != nil {
+		scheme = "wss"
+	}
+	doc := map[string]any{
+		"endpoints": map[string]any{
+			"v1": map[string]string{
+				"version":    skVersion,
+				"signalk-ws": scheme + "://" + r.Host + "/signalk/v1/stream",
+			},
+		},
+		"server": map[string]string{"id": "goefidash", "version": skVersion},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+// handleSignalKStream sends the hello and then every delta, for SignalK
+// apps and chartplotters connecting to the dash directly. Subscription
+// requests are ignored; every client gets everything.
+//
+//	GET /signalk/v1/stream (WebSocket)
+func (s *Server) handleSignalKStream(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.SignalKSnapshot().WebSocket {
+		http.NotFound(w, r)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[signalk] upgrade error: %v", err)
+		return
+	}
+	hello, _ := json.Marshal(map[string]any{
+		"name":      "goefidash",
+		"version":   skVersion,
+		"self":      signalKSelf(),
+		"roles":     []string{"master", "main"},
+		"timestamp": time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+	})
+	conn.SetWriteDeadline(time.Now().Add(fanoutWrite))
+	if err := conn.WriteMessage(websocket.TextMessage, hello); err != nil {
+		conn.Close()
+		return
+	}
+
+	h := &s.signalK
+	h.mu.Lock()
+	if h.clients == nil {
+		h.clients = make(map[*websocket.Conn]struct{})
+	}
+	h.clients[conn] = struct{}{}
+	h.mu.Unlock()
+	log.Printf("[signalk] %s connected to the stream", r.RemoteAddr)
+
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				h.mu.Lock()
+				delete(h.clients, conn)
+				h.mu.Unlock()
+				conn.Close()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/imu"
+)
+
+const (
+	slipMinKph   = 10.0                   // Slip is relative to at least this, so a launch reads as a finite percentage
+	slipMinDelta = 3.0                    // km/h; less is VSS resolution and GPS noise, not wheelspin
+	slipOnAfter  = 100 * time.Millisecond // Slip must stay over the threshold this long to flag
+	slipGPSLag   = 0.15                   // s; GPS speed trails the wheels by about this under acceleration
+)
+
+// SlipData is driven-wheel slip: calibrated VSS against GPS ground speed.
+type SlipData struct {
+	Percent   float64 `json:"percent"`   // + = wheels faster than the car (spin), - = slower (lockup)
+	Wheelspin bool    `json:"wheelspin"` // Slip over the configured threshold
+}
+
+// slipDetector turns wheel and ground speed into slip and a wheelspin
+// flag. The flag needs slip over the threshold for slipOnAfter to set, and
+// clears once slip falls under half the threshold.
+type slipDetector struct {
+	mu   sync.Mutex
+	over time.Time // When slip went over the threshold, zero while under
+	spin bool
+}
+
+// update returns the slip for one broadcast tick. threshold is in percent;
+// 0 never flags wheelspin.
+func (d *slipDetector) update(now time.Time, wheelKph, groundKph, threshold float64) *SlipData {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delta := wheelKph - groundKph
+	pct := delta / math.Max(groundKph, slipMinKph) * 100
+
+	switch {
+	case threshold <= 0:
+		d.over, d.spin = time.Time{}, false
+	case pct >= threshold && delta >= slipMinDelta:
+		if d.over.IsZero() {
+			d.over = now
+		}
+		if now.Sub(d.over) >= slipOnAfter {
+			d.spin = true
+		}
+	case pct < threshold/2:
+		d.over, d.spin = time.Time{}, false
+	default:
+		d.over = time.Time{} // Between the thresholds: hold the flag
+	}
+	return &SlipData{Percent: math.Round(pct*10) / 10, Wheelspin: d.spin}
+}
+
+func (d *slipDetector) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.over, d.spin = time.Time{}, false
+}
+
+// spinning reports whether wheelspin is flagged.
+func (d *slipDetector) spinning() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.spin
+}
+
+// calcSlip compares the ECU VSS, corrected by the speed fuser's scale,
+// with GPS ground speed. VSS is assumed to read the driven wheels (a
+// gearbox or diff sensor). With an IMU the GPS speed is advanced by its
+// lag using longitudinal G, so hard acceleration doesn't read as slip.
+// It returns nil without both sources or before VSS has been calibrated,
+// since an uncalibrated VSS a few percent off would read as constant slip.
+func (s *Server) calcSlip(now time.Time, e *ecu.DataFrame, g *gps.Data, m *imu.Data, speed *SpeedData) *SlipData {
+	cfg := s.cfg.SpeedSnapshot()
+	_, samples := s.speed.calibration()
+	if e == nil || g == nil || !g.Valid || speed.VSSScale == 0 ||
+		(cfg.VSSScale <= 0 && samples < fusionCalTrusted) {
+		s.slip.reset()
+		return nil
+	}
+
+	ground := g.Speed
+	if m != nil {
+		ground = math.Max(0, ground+m.LongG*9.81*3.6*slipGPSLag)
+	}
+	return s.slip.update(now, float64(e.VSS)*speed.VSSScale, ground, cfg.WheelspinPct)
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// snapshotHistory is how much broadcast history a snapshot bundle carries.
+const snapshotHistory = 10 * time.Second
+
+// snapshotPrefix names snapshot bundles inside storage.DirCaptures.
+const snapshotPrefix = "snap-"
+
+// snapshotBundle is a self-contained capture of the dash state, meant to
+// be pasted whole into a forum post or tuner chat.
+type snapshotBundle struct {
+	ID      string            `json:"id"`
+	Reason  string            `json:"reason"`
+	Created int64             `json:"created"` // Unix ms
+	Frame   json.RawMessage   `json:"frame"`   // Latest broadcast frame
+	History []json.RawMessage `json:"history"` // Frames from the last 10 s, oldest first
+	Alerts  []Alert           `json:"alerts"`  // Active at capture time
+	GPS     *gps.Data         `json:"gps,omitempty"`
+	Display DisplayConfig     `json:"display"` // Units and thresholds in effect
+	Vehicle *IdentityConfig   `json:"vehicle,omitempty"`
+}
+
+// snapshotInfo lists a stored bundle.
+type snapshotInfo struct {
+	ID      string `json:"id"`
+	Reason  string `json:"reason"`
+	Created int64  `json:"created"`
+	URL     string `json:"url"`
+}
+
+// frameHistory keeps the last snapshotHistory of broadcast frames.
+type frameHistory struct {
+	mu     sync.Mutex
+	frames []json.RawMessage
+	stamps []time.Time
+}
+
+func (h *frameHistory) add(now time.Time, data []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.frames = append(h.frames, data)
+	h.stamps = append(h.stamps, now)
+
+	// Drop frames older than the window
+	cut := 0
+	for cut < len(h.stamps) && now.Sub(h.stamps[cut]) > snapshotHistory {
+		cut++
+	}
+	if cut > 0 {
+		h.frames = append(h.frames[:0], h.frames[cut:]...)
+		h.stamps = append(h.stamps[:0], h.stamps[cut:]...)
+	}
+}
+
+// latest returns the newest frame and when it was sent, or nil.
+func (h *frameHistory) latest() (json.RawMessage, time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.frames) == 0 {
+		return nil, time.Time{}
+	}
+	return h.frames[len(h.frames)-1], h.stamps[len(h.stamps)-1]
+}
+
+// copy returns the buffered frames, oldest first.
+func (h *frameHistory) copy() []json.RawMessage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]json.RawMessage(nil), h.frames...)
+}
+
+// captureSnapshot writes a bundle and returns its listing.
+func (s *Server) captureSnapshot(reason string) (snapshotInfo, error) {
+	now := time.Now()
+	id := fmt.Sprintf("%s%d", snapshotPrefix, now.UnixMilli())
+
+	b := snapshotBundle{
+		ID:      id,
+		Reason:  reason,
+		Created: now.UnixMilli(),
+		History: s.history.copy(),
+		Alerts:  s.activeAlerts(),
+		GPS:     s.latestGPS(),
+		Display: s.cfg.DisplaySnapshot(),
+	}
+	if b.Alerts == nil {
+		b.Alerts = []Alert{}
+	}
+	if v := s.cfg.IdentitySnapshot(); !v.IsZero() {
+		b.Vehicle = &v
+	}
+	if n := len(b.History); n > 0 {
+		b.Frame = b.History[n-1]
+	} else {
+		b.Frame = json.RawMessage("null")
+	}
+
+	if err := s.store.WriteJSON(storage.DirCaptures+"/"+id+".json", b); err != nil {
+		return snapshotInfo{}, err
+	}
+	log.Printf("[snapshot] captured %s (%s)", id, reason)
+	s.pruneSnapshots()
+	return snapshotInfo{ID: id, Reason: reason, Created: b.Created, URL: snapshotURL(id)}, nil
+}
+
+// snapshotIDs returns stored bundle IDs, oldest first.
+func (s *Server) snapshotIDs() []string {
+	names, err := s.store.List(storage.DirCaptures)
+	if err != nil {
+		log.Printf("[snapshot] list failed: %v", err)
+	}
+	var ids []string
+	for _, n := range names {
+		if strings.HasPrefix(n, snapshotPrefix) && strings.HasSuffix(n, ".json") {
+			ids = append(ids, strings.TrimSuffix(n, ".json"))
+		}
+	}
+	// Same-width millisecond stamps sort lexically
+	sort.Strings(ids)
+	return ids
+}
+
+// pruneSnapshots removes the oldest bundles beyond snapshots.keep.
+func (s *Server) pruneSnapshots() {
+	keep := s.cfg.Snapshots.Keep
+	if keep <= 0 {
+		return
+	}
+	ids := s.snapshotIDs()
+	for len(ids) > keep {
+		if err := s.store.Remove(storage.DirCaptures + "/" + ids[0] + ".json"); err != nil {
+			log.Printf("[snapshot] prune failed: %v", err)
+		}
+		ids = ids[1:]
+	}
+}
+
+// checkAlertSnapshot captures a bundle when a new alert is raised, at
+// most once per snapshots.cooldown_s.
+func (s *Server) checkAlertSnapshot(now time.Time, raised []Alert) {
+	var texts []string
+	for _, a := range raised {
+		if a.ID != "lean_boost" { // Takes its own snapshot
+			texts = append(texts, a.Text)
+		}
+	}
+	if len(texts) == 0 || !s.cfg.Snapshots.OnAlert {
+		return
+	}
+	cooldown := time.Duration(s.cfg.Snapshots.CooldownSec) * time.Second
+	if !s.lastAlertSnap.IsZero() && now.Sub(s.lastAlertSnap) < cooldown {
+		return
+	}
+	s.lastAlertSnap = now
+
+	// Let the history catch a little of what follows the alert
+	go func() {
+		time.Sleep(time.Second)
+		if _, err := s.captureSnapshot("alert: " + strings.Join(texts, ", ")); err != nil {
+			log.Printf("[snapshot] capture failed: %v", err)
+		}
+	}()
+}
+
+// handleSnapshot captures (POST) or serves (GET) snapshot bundles.
+//
+//	POST /api/snapshot              — capture now; optional {"reason": "..."}
+//	GET  /api/snapshot?id=snap-...  — one bundle ("latest" for the newest)
+//	GET  /api/snapshot              — list stored bundles
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		if body, err := io.ReadAll(r.Body); err == nil && len(body) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+		if req.Reason == "" {
+			req.Reason = "manual"
+		}
+		info, err := s.captureSnapshot(req.Reason)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+
+	case http.MethodGet:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			s.listSnapshots(w)
+			return
+		}
+		if id == "latest" {
+			ids := s.snapshotIDs()
+			if len(ids) == 0 {
+				http.Error(w, "no snapshots", 404)
+				return
+			}
+			id = ids[len(ids)-1]
+		}
+		if !strings.HasPrefix(id, snapshotPrefix) || strings.ContainsAny(id, `/\.`) {
+			http.Error(w, "bad snapshot id", 400)
+			return
+		}
+		data, err := s.store.ReadFile(storage.DirCaptures + "/" + id + ".json")
+		if err != nil {
+			http.Error(w, "snapshot not found", 404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
+
+// listSnapshots writes the stored bundles, newest first.
+func (s *Server) listSnapshots(w http.ResponseWriter) {
+	ids := s.snapshotIDs()
+	list := make([]snapshotInfo, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		var b struct {
+			Reason  string `json:"reason"`
+			Created int64  `json:"created"`
+		}
+		if err := s.store.ReadJSON(storage.DirCaptures+"/"+ids[i]+".json", &b); err != nil {
+			continue
+		}
+		list = append(list, snapshotInfo{ID: ids[i], Reason: b.Reason, Created: b.Created, URL: snapshotURL(ids[i])})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func snapshotURL(id string) string { return "/api/snapshot?id=" + id }
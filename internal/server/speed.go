@@ -0,0 +1,249 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// Speed sources (SpeedConfig.Source).
+const (
+	speedFusion = "fusion" // Blend by estimated accuracy
+	speedVSS    = "vss"    // VSS, GPS when there's no VSS
+	speedGPS    = "gps"    // GPS, VSS when there's no fix
+)
+
+const (
+	fusionCalMinKph   = 30   // Calibrate only above this: GPS error is then a small fraction
+	fusionCalMaxAccel = 2.0  // km/h per second; calibrate only at a steady speed
+	fusionCalRate     = 0.02 // Weight of each calibration sample once warmed up (one per second)
+	fusionCalTrusted  = 30   // Samples before the learned scale counts as calibrated
+	fusionMinScale    = 0.5  // Ratios outside this range are wheelspin, lockup or a bad sensor
+	fusionMaxScale    = 2.0
+	fusionWeightSlew  = 0.5 // Max change in GPS weight per second, so source changes don't step
+	fusionGPSSigma    = 1.5 // km/h, GPS speed error when the receiver doesn't say
+)
+
+// vssCalFile is the learned VSS scale's name inside the data directory.
+const vssCalFile = storage.DirState + "/vss_scale.dat"
+
+// speedFuser combines GPS speed and ECU VSS into one speed. Each source is
+// weighted by its estimated error: GPS from the receiver's speed accuracy
+// (or DOP), VSS from a fixed fraction of speed that shrinks once its scale
+// has been calibrated against GPS. A VSS off by a few percent (tyre size,
+// a wrong pulses-per-km) is learned while cruising and corrected, so it no
+// longer wins by default. Either source alone is used as is, and when both
+// drop out the last speed is held briefly (e.g. a tunnel on a car without
+// VSS).
+type speedFuser struct {
+	mu      sync.Mutex
+	scale   float64 // Learned GPS/VSS ratio
+	samples int     // Calibration samples behind scale
+	vssSeen bool    // VSS has read non-zero, so a zero means stopped, not unwired
+
+	weight  float64 // GPS weight, slewed towards the target
+	last    SpeedData
+	lastAt  time.Time // Last update with a live source
+	prevAt  time.Time
+	prevGPS float64
+	calAt   time.Time
+}
+
+// update returns the speed for one broadcast tick. e and g are nil when
+// stale. Calibration is skipped when calibrate is false (injected faults).
+func (f *speedFuser) update(now time.Time, e *ecu.DataFrame, g *gps.Data, cfg SpeedConfig, calibrate bool) *SpeedData {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dt := now.Sub(f.prevAt).Seconds()
+	f.prevAt = now
+
+	if e != nil && e.VSS > 0 {
+		f.vssSeen = true
+	}
+	haveVSS := e != nil && f.vssSeen
+	haveGPS := g != nil && g.Valid
+
+	scale := cfg.VSSScale
+	if scale <= 0 {
+		scale = f.scaleLocked()
+		if calibrate && haveVSS && haveGPS {
+			f.calibrate(now, float64(e.VSS), g.Speed, dt)
+		}
+	}
+	if haveGPS {
+		f.prevGPS = g.Speed
+	}
+
+	if !haveVSS && !haveGPS {
+		if !f.lastAt.IsZero() && now.Sub(f.lastAt).Seconds() < cfg.HoldSec {
+			held := f.last
+			held.Source = "hold"
+			return &held
+		}
+		f.weight = 0
+		return &SpeedData{Value: 0, Source: "none"}
+	}
+
+	var vss, target float64
+	if haveVSS {
+		vss = float64(e.VSS) * scale
+	}
+	switch {
+	case !haveVSS:
+		target = 1
+	case !haveGPS:
+		target = 0
+	case cfg.Source == speedVSS:
+		target = 0
+	case cfg.Source == speedGPS:
+		target = 1
+	default:
+		sg := gpsSpeedSigma(g)
+		sv := f.vssSigma(vss, cfg.VSSScale > 0)
+		target = sv * sv / (sg*sg + sv*sv)
+	}
+
+	// Only one source: use it outright. Both: slew the weight so a change
+	// in GPS quality doesn't step the speed.
+	if !haveVSS || !haveGPS {
+		f.weight = target
+	} else if dt > 0 && dt < 1 {
+		step := fusionWeightSlew * dt
+		f.weight += math.Max(-step, math.Min(step, target-f.weight))
+	} else {
+		f.weight = target
+	}
+
+	var gpsSpeed float64
+	if haveGPS {
+		gpsSpeed = g.Speed
+	}
+	out := SpeedData{
+		Value:     math.Round((f.weight*gpsSpeed+(1-f.weight)*vss)*10) / 10,
+		GPSWeight: math.Round(f.weight*100) / 100,
+	}
+	switch {
+	case out.GPSWeight >= 1:
+		out.Source = "gps"
+	case out.GPSWeight <= 0:
+		out.Source = "vss"
+	default:
+		out.Source = "fusion"
+	}
+	if haveVSS {
+		out.VSSScale = math.Round(scale*1000) / 1000
+	}
+	f.last, f.lastAt = out, now
+	return &out
+}
+
+// calibrate folds one GPS/VSS ratio into the learned scale, at most once a
+// second and only while cruising.
+func (f *speedFuser) calibrate(now time.Time, vss, gpsKph, dt float64) {
+	if gpsKph < fusionCalMinKph || vss <= 0 || now.Sub(f.calAt) < time.Second {
+		return
+	}
+	if dt <= 0 || math.Abs(gpsKph-f.prevGPS)/dt > fusionCalMaxAccel {
+		return
+	}
+	ratio := gpsKph / vss
+	if ratio < fusionMinScale || ratio > fusionMaxScale {
+		return
+	}
+	f.calAt = now
+	// Running mean to start, then a slow EMA that follows tyre wear
+	k := math.Max(1/float64(f.samples+1), fusionCalRate)
+	f.scale = f.scaleLocked() + k*(ratio-f.scaleLocked())
+	f.samples++
+	if f.samples == fusionCalTrusted {
+		log.Printf("[speed] VSS calibrated against GPS: scale %.3f", f.scale)
+	}
+}
+
+func (f *speedFuser) scaleLocked() float64 {
+	if f.scale <= 0 {
+		return 1
+	}
+	return f.scale
+}
+
+// vssSigma is the assumed VSS error in km/h: whole-km/h resolution plus
+// a fraction of speed, 10% until the scale is known and 2% after.
+func (f *speedFuser) vssSigma(vss float64, fixed bool) float64 {
+	rel := 0.10
+	if fixed || f.samples >= fusionCalTrusted {
+		rel = 0.02
+	}
+	return 0.5 + rel*vss
+}
+
+// gpsSpeedSigma is the GPS speed error in km/h: the receiver's own estimate
+// (UBX), else a default widened by poor geometry or few satellites.
+func gpsSpeedSigma(g *gps.Data) float64 {
+	if g.SpeedAcc > 0 {
+		return g.SpeedAcc
+	}
+	s := fusionGPSSigma
+	if g.HDOP > 2 {
+		s *= g.HDOP / 2
+	}
+	if g.Satellites > 0 && g.Satellites < 5 {
+		s *= 2
+	}
+	return s
+}
+
+// calibration returns the learned scale and its sample count.
+func (f *speedFuser) calibration() (float64, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.scale, f.samples
+}
+
+// calcSpeed returns the fused speed from ECU VSS and GPS. VSS isn't
+// calibrated during wheelspin.
+func (s *Server) calcSpeed(now time.Time, ecuData *ecu.DataFrame, gpsData *gps.Data, injected bool) *SpeedData {
+	return s.speed.update(now, ecuData, gpsData, s.cfg.SpeedSnapshot(), !injected && !s.slip.spinning())
+}
+
+// loadSpeedCal reads the learned VSS scale from disk.
+func (s *Server) loadSpeedCal() {
+	data, err := s.store.ReadFile(vssCalFile)
+	if err != nil {
+		return
+	}
+	parts := strings.Fields(string(data))
+	if len(parts) < 2 {
+		return
+	}
+	scale, err1 := strconv.ParseFloat(parts[0], 64)
+	n, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || scale < fusionMinScale || scale > fusionMaxScale {
+		return
+	}
+	s.speed.mu.Lock()
+	s.speed.scale, s.speed.samples = scale, n
+	s.speed.mu.Unlock()
+	log.Printf("[speed] loaded VSS scale %.3f (%d samples)", scale, n)
+}
+
+// saveSpeedCal persists the learned VSS scale.
+func (s *Server) saveSpeedCal() {
+	scale, n := s.speed.calibration()
+	if n == 0 {
+		return
+	}
+	data := fmt.Sprintf("%.6f\n%d\n", scale, n)
+	if err := s.store.WriteFile(vssCalFile, []byte(data)); err != nil {
+		log.Printf("[speed] save failed: %v", err)
+	}
+}
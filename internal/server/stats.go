@@ -0,0 +1,193 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+)
+
+const (
+	statsMaxTick    = time.Second // Longer gaps (ECU dropouts) aren't averaged over
+	statsOilMinRPM  = 2000        // Oil pressure minimum only counts above this
+	statsFrameEvery = time.Second // Frame stats rate, with stats.in_frame
+)
+
+// SessionStats are the current drive's peaks and channel ranges, counted
+// while the engine runs. A drive ends when the dash sleeps.
+type SessionStats struct {
+	Since    int64                    `json:"since"`   // Unix ms of the first frame counted
+	Seconds  float64                  `json:"seconds"` // Engine running time counted
+	Peaks    SessionPeaks             `json:"peaks"`
+	Channels map[string]*ChannelStats `json:"channels"` // By stats.channels name
+}
+
+// SessionPeaks are the extremes that matter after a hard drive. Each is
+// nil until seen; boost and knock only once there has been some.
+type SessionPeaks struct {
+	MaxRPM     *Peak `json:"maxRpm,omitempty"`
+	MaxBoost   *Peak `json:"maxBoost,omitempty"`       // kPa over baro
+	MaxCoolant *Peak `json:"maxCoolant,omitempty"`     // °C
+	MinOilP    *Peak `json:"minOilPressure,omitempty"` // PSI, above statsOilMinRPM
+	MaxKnock   *Peak `json:"maxKnockRetard,omitempty"` // Degrees
+}
+
+// Peak is an extreme and when it happened, to find it in the log.
+type Peak struct {
+	Value float64 `json:"value"`
+	At    int64   `json:"at"` // Unix ms
+	RPM   uint16  `json:"rpm"`
+}
+
+// ChannelStats is one channel's range and time-weighted mean.
+type ChannelStats struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+
+	sum  float64 // Integral over secs
+	secs float64
+}
+
+// statsTracker accumulates SessionStats from ECU frames.
+type statsTracker struct {
+	mu    sync.Mutex
+	last  time.Time
+	stats SessionStats
+}
+
+// update feeds one broadcast tick.
+func (t *statsTracker) update(now time.Time, e *ecu.DataFrame, channels []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e == nil || e.RPM == 0 {
+		t.last = time.Time{}
+		return
+	}
+	st := &t.stats
+	if st.Since == 0 {
+		st.Since = now.UnixMilli()
+	}
+	dt := 0.0
+	if !t.last.IsZero() {
+		if d := now.Sub(t.last); d > 0 && d <= statsMaxTick {
+			dt = d.Seconds()
+		}
+	}
+	t.last = now
+	st.Seconds += dt
+
+	at := now.UnixMilli()
+	peak := func(p **Peak, v float64, higher bool) {
+		if *p == nil || (higher && v > (*p).Value) || (!higher && v < (*p).Value) {
+			*p = &Peak{Value: v, At: at, RPM: e.RPM}
+		}
+	}
+	peak(&st.Peaks.MaxRPM, float64(e.RPM), true)
+	if v, ok := e.Value("map"); ok {
+		baro := 101.3
+		if b, ok := e.Value("baro"); ok && b > 0 {
+			baro = b
+		}
+		if v > baro {
+			peak(&st.Peaks.MaxBoost, v-baro, true)
+		}
+	}
+	if v, ok := e.Value("coolant"); ok {
+		peak(&st.Peaks.MaxCoolant, v, true)
+	}
+	if v, ok := e.Value("oilPressure"); ok && e.RPM > statsOilMinRPM {
+		peak(&st.Peaks.MinOilP, v, false)
+	}
+	if v, ok := e.Value("knockCor"); ok && v > 0 {
+		peak(&st.Peaks.MaxKnock, v, true)
+	}
+
+	if st.Channels == nil {
+		st.Channels = make(map[string]*ChannelStats)
+	}
+	for _, name := range channels {
+		v, ok := e.Value(name)
+		if !ok {
+			continue
+		}
+		c := st.Channels[name]
+		if c == nil {
+			st.Channels[name] = &ChannelStats{Min: v, Max: v, Mean: v}
+			continue
+		}
+		c.Min, c.Max = math.Min(c.Min, v), math.Max(c.Max, v)
+		c.sum += v * dt
+		c.secs += dt
+		if c.secs > 0 {
+			c.Mean = c.sum / c.secs
+		}
+	}
+}
+
+// snapshot returns a rounded copy of the stats, or nil before the engine
+// has run this drive.
+func (t *statsTracker) snapshot() *SessionStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stats.Since == 0 {
+		return nil
+	}
+	out := t.stats
+	out.Seconds = math.Round(out.Seconds)
+	out.Peaks = SessionPeaks{
+		MaxRPM:     roundPeak(out.Peaks.MaxRPM),
+		MaxBoost:   roundPeak(out.Peaks.MaxBoost),
+		MaxCoolant: roundPeak(out.Peaks.MaxCoolant),
+		MinOilP:    roundPeak(out.Peaks.MinOilP),
+		MaxKnock:   roundPeak(out.Peaks.MaxKnock),
+	}
+	out.Channels = make(map[string]*ChannelStats, len(t.stats.Channels))
+	for name, c := range t.stats.Channels {
+		out.Channels[name] = &ChannelStats{
+			Min:  math.Round(c.Min*100) / 100,
+			Max:  math.Round(c.Max*100) / 100,
+			Mean: math.Round(c.Mean*100) / 100,
+		}
+	}
+	return &out
+}
+
+func roundPeak(p *Peak) *Peak {
+	if p == nil {
+		return nil
+	}
+	r := *p
+	r.Value = math.Round(r.Value*10) / 10
+	return &r
+}
+
+// reset starts a new drive's stats.
+func (t *statsTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats, t.last = SessionStats{}, time.Time{}
+}
+
+// handleStats serves the current drive's peaks and channel statistics.
+//
+//	GET    /api/stats — null before the engine has run this drive
+//	DELETE /api/stats — start counting again
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.stats.snapshot())
+
+	case http.MethodDelete:
+		s.stats.reset()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// subscription is the part of the data frames a client asked for, and how
+// often (docs/WEBSOCKET.md). A channel name is an ECU or aux channel,
+// kept in ecu, or a frame member such as gps or alerts, kept whole.
+type subscription struct {
+	channels map[string]bool // nil = the whole frame
+	every    time.Duration   // Between data frames; 0 = every one
+}
+
+// subscriptionAlways are the data frame members every client gets.
+var subscriptionAlways = map[string]bool{"stamp": true, "noData": true, "lastData": true, "network": true}
+
+// subscribeRequest asks for a subscription; an empty one is the whole
+// frame at full rate again.
+type subscribeRequest struct {
+	Channels []string `json:"channels"`
+	RateHz   float64  `json:"rateHz"`
+}
+
+func (r subscribeRequest) subscription() (*subscription, error) {
+	if r.RateHz < 0 || math.IsNaN(r.RateHz) || math.IsInf(r.RateHz, 0) {
+		return nil, fmt.Errorf("bad rateHz %g", r.RateHz)
+	}
+	sub := &subscription{channels: channelSet(r.Channels)}
+	if r.RateHz > 0 {
+		sub.every = time.Duration(float64(time.Second) / r.RateHz)
+	}
+	return sub, nil
+}
+
+// subscriptionQuery reads the subscription a URL asks for with
+// ?channels=rpm,coolant&rateHz=5, or returns nil if it doesn't.
+func subscriptionQuery(q url.Values) (*subscription, error) {
+	var r subscribeRequest
+	if c := q.Get("channels"); c != "" {
+		r.Channels = strings.Split(c, ",")
+	}
+	if hz := q.Get("rateHz"); hz != "" {
+		v, err := strconv.ParseFloat(hz, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad rateHz %q", hz)
+		}
+		r.RateHz = v
+	}
+	if r.Channels == nil && r.RateHz == 0 {
+		return nil, nil
+	}
+	return r.subscription()
+}
+
+// filtering reports whether sub leaves anything out of data frames.
+func (sub *subscription) filtering() bool {
+	return sub != nil && sub.channels != nil
+}
+
+func (sub *subscription) String() string {
+	what := "every channel"
+	if sub.filtering() {
+		what = fmt.Sprintf("%d channel(s)", len(sub.channels))
+	}
+	return what + ", frame rate " + rateName(sub.every)
+}
+
+// filter returns the members of a decoded data frame sub asks for. The
+// result shares values with frame, so neither may be modified.
+func (sub *subscription) filter(frame map[string]any) map[string]any {
+	if !sub.filtering() {
+		return frame
+	}
+	out := make(map[string]any)
+	for k, v := range frame {
+		switch {
+		case sub.channels[k] || subscriptionAlways[k]:
+			out[k] = v
+		case k == "ecu":
+			if e, ok := v.(map[string]any); ok {
+				if e = sub.filterChannels(e); len(e) > 0 {
+					out[k] = e
+				}
+			}
+		}
+	}
+	return out
+}
+
+// filterChannels returns the ECU channels in sub, and the aux channels
+// within aux.
+func (sub *subscription) filterChannels(ecu map[string]any) map[string]any {
+	out := make(map[string]any)
+	for k, v := range ecu {
+		if sub.channels[k] {
+			out[k] = v
+			continue
+		}
+		if aux, ok := v.(map[string]any); ok && k == "aux" {
+			if aux = sub.filterChannels(aux); len(aux) > 0 {
+				out[k] = aux
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,377 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/autox"
+	"github.com/shaunagostinho/speeduino-dash/internal/laps"
+	"github.com/shaunagostinho/speeduino-dash/internal/perf"
+)
+
+const (
+	syncTick     = time.Minute // Restore retries and upload checks
+	syncTimeout  = 30 * time.Second
+	syncVersion  = 1       // SyncBackup.Version written
+	maxSyncBytes = 8 << 20 // Largest backup accepted
+)
+
+// syncClient talks to the backup endpoint.
+var syncClient = &http.Client{Timeout: syncTimeout}
+
+// errNoBackup is a GET of the backup endpoint finding nothing there yet.
+var errNoBackup = errors.New("no backup stored")
+
+// SyncBackup is the backed-up state: everything on the card that can't be
+// rebuilt by driving. Lap traces, sessions and logs are left out.
+type SyncBackup struct {
+	Version int    `json:"version"`
+	Vehicle string `json:"vehicle,omitempty"` // identity.name
+	Saved   int64  `json:"saved"`             // Unix ms
+
+	TotalKm     float64      `json:"totalKm"`
+	TripKm      float64      `json:"tripKm"` // Trip A
+	TripBKm     float64      `json:"tripBKm"`
+	ReverseKm   float64      `json:"reverseKm"`
+	EngineHours float64      `json:"engineHours"`
+	Trips       [2]tripMeter `json:"trips"`
+
+	Laps  []laps.Lap    `json:"laps"`
+	Perf  []perf.Result `json:"perf"`
+	Autox []autox.Run   `json:"autox"`
+}
+
+// SyncStatus is the backup sync state, for GET /api/sync.
+type SyncStatus struct {
+	Enabled     bool   `json:"enabled"`
+	Pending     bool   `json:"pending"`         // Fresh card: uploads wait for a restore
+	LastUpload  int64  `json:"lastUpload"`      // Unix ms, 0 = not this run
+	LastRestore int64  `json:"lastRestore"`     // Unix ms, 0 = not this run
+	Error       string `json:"error,omitempty"` // Last attempt's failure
+}
+
+// syncState is runSync's progress.
+type syncState struct {
+	run sync.Mutex // Held for a whole sync, so a manual one can't overlap
+
+	mu       sync.Mutex
+	uploaded []byte // Last uploaded backup, Saved zeroed, to skip unchanged ones
+	status   SyncStatus
+}
+
+// runSync keeps the remote backup current. On a fresh card the backup is
+// restored first: until that succeeds (or finds nothing stored) nothing
+// is uploaded, so a replacement card can't overwrite the car's history
+// with its own near-zero odometer.
+func (s *Server) runSync(ctx context.Context) {
+	ticker := time.NewTicker(syncTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncOnce(ctx, false)
+		}
+	}
+}
+
+// syncOnce restores the backup if one is pending, then uploads when
+// sync.interval_min has passed since the last upload (or force) and the
+// backup has changed.
+func (s *Server) syncOnce(ctx context.Context, force bool) error {
+	cfg := s.cfg.SyncSnapshot()
+	if cfg.URL == "" {
+		return nil
+	}
+	s.sync.run.Lock()
+	defer s.sync.run.Unlock()
+
+	if s.syncPending() {
+		if err := s.restoreRemote(ctx, cfg); err != nil {
+			return s.syncFailed(fmt.Errorf("restore: %w", err))
+		}
+	}
+
+	b := s.backup()
+	key, _ := json.Marshal(b)
+	interval := time.Duration(max(cfg.IntervalMin, 1)) * time.Minute
+	s.sync.mu.Lock()
+	due := force || time.Since(time.UnixMilli(s.sync.status.LastUpload)) >= interval
+	same := bytes.Equal(key, s.sync.uploaded)
+	s.sync.mu.Unlock()
+	if !due || same {
+		return nil
+	}
+
+	b.Saved = time.Now().UnixMilli()
+	data, err := json.Marshal(b)
+	if err != nil {
+		return s.syncFailed(err)
+	}
+	if _, err := syncRequest(ctx, cfg, http.MethodPut, data); err != nil {
+		return s.syncFailed(fmt.Errorf("upload: %w", err))
+	}
+	s.sync.mu.Lock()
+	if s.sync.status.LastUpload == 0 {
+		log.Printf("[sync] backed up to %s", syncHost(cfg.URL))
+	}
+	s.sync.uploaded = key
+	s.sync.status.LastUpload = b.Saved
+	s.sync.status.Error = ""
+	s.sync.mu.Unlock()
+	return nil
+}
+
+// syncFailed records err as the last failure, logging it if it differs
+// from the previous one, and returns it.
+func (s *Server) syncFailed(err error) error {
+	s.sync.mu.Lock()
+	defer s.sync.mu.Unlock()
+	if msg := err.Error(); msg != s.sync.status.Error {
+		log.Printf("[sync] %s", msg)
+		s.sync.status.Error = msg
+	}
+	return err
+}
+
+// syncPending reports whether the card started without an odometer and
+// nothing has been restored onto it yet.
+func (s *Server) syncPending() bool {
+	s.odoMu.Lock()
+	defer s.odoMu.Unlock()
+	return s.odoFresh
+}
+
+// syncStatus returns the sync state.
+func (s *Server) syncStatus() SyncStatus {
+	s.sync.mu.Lock()
+	st := s.sync.status
+	s.sync.mu.Unlock()
+	st.Enabled = s.cfg.SyncSnapshot().URL != ""
+	st.Pending = st.Enabled && s.syncPending()
+	return st
+}
+
+// restoreRemote fetches the stored backup and applies it. Finding none
+// (a first install) ends the wait for a restore.
+func (s *Server) restoreRemote(ctx context.Context, cfg SyncConfig) error {
+	data, err := syncRequest(ctx, cfg, http.MethodGet, nil)
+	if errors.Is(err, errNoBackup) {
+		log.Printf("[sync] no backup at %s yet", syncHost(cfg.URL))
+		s.odoMu.Lock()
+		s.odoFresh = false
+		s.odoMu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var b SyncBackup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	return s.applyBackup(&b)
+}
+
+// backup returns the current state to back up, with Saved left zero.
+func (s *Server) backup() *SyncBackup {
+	b := &SyncBackup{
+		Version: syncVersion,
+		Vehicle: s.cfg.IdentitySnapshot().Name,
+		Laps:    s.laps.Laps(),
+		Perf:    s.perf.Results(""),
+		Autox:   s.autox.Runs(),
+	}
+	s.odoMu.Lock()
+	b.TotalKm, b.TripKm, b.TripBKm = s.odoTotal, s.odoTrip, s.odoTripB
+	b.ReverseKm, b.EngineHours = s.odoReverse, s.engineHours
+	b.Trips = s.trips
+	s.odoMu.Unlock()
+	return b
+}
+
+// applyBackup restores b. On a fresh card the odometer has been counting
+// from zero since it went in, so b's distances, hours and moving times
+// are added to it. Otherwise b replaces the odometer and trips only if
+// its total is ahead (the card was rolled back to an old image), and
+// engine hours take the larger value, so restoring the same or an older
+// backup changes nothing. Lap, performance and autocross records are
+// restored only where the card has none.
+func (s *Server) applyBackup(b *SyncBackup) error {
+	if b.Version > syncVersion {
+		return fmt.Errorf("backup version %d is newer than this dash supports", b.Version)
+	}
+	if name := s.cfg.IdentitySnapshot().Name; b.Vehicle != "" && name != "" && !strings.EqualFold(b.Vehicle, name) {
+		return fmt.Errorf("backup is for %q, not %q", b.Vehicle, name)
+	}
+
+	s.odoMu.Lock()
+	switch {
+	case s.odoFresh:
+		s.odoTotal += b.TotalKm
+		s.odoTrip += b.TripKm
+		s.odoTripB += b.TripBKm
+		s.odoReverse += b.ReverseKm
+		s.engineHours += b.EngineHours
+		for i, t := range b.Trips {
+			s.trips[i].MovingSec += t.MovingSec
+			s.trips[i].MaxSpeed = math.Max(s.trips[i].MaxSpeed, t.MaxSpeed)
+			s.trips[i].Base += t.Base
+			if t.Since != 0 {
+				s.trips[i].Since = t.Since
+			}
+		}
+	case b.TotalKm > s.odoTotal:
+		s.odoTotal, s.odoTrip, s.odoTripB, s.odoReverse = b.TotalKm, b.TripKm, b.TripBKm, b.ReverseKm
+		s.trips = b.Trips
+		fallthrough
+	default:
+		s.engineHours = math.Max(s.engineHours, b.EngineHours)
+	}
+	s.odoFresh = false
+	s.tripsDirty = true
+	total, hours := s.odoTotal, s.engineHours
+	s.odoMu.Unlock()
+	s.saveOdometer()
+	s.saveTrips()
+
+	var restored []string
+	if len(b.Laps) > 0 && len(s.laps.Laps()) == 0 {
+		s.laps.Restore(b.Laps)
+		s.saveLaps()
+		restored = append(restored, fmt.Sprintf("%d laps", len(b.Laps)))
+	}
+	if len(b.Perf) > 0 && len(s.perf.Results("")) == 0 {
+		s.perf.Restore(b.Perf)
+		s.savePerf()
+		restored = append(restored, fmt.Sprintf("%d perf results", len(b.Perf)))
+	}
+	if len(b.Autox) > 0 && len(s.autox.Runs()) == 0 {
+		s.autox.Restore(b.Autox)
+		s.saveAutox()
+		restored = append(restored, fmt.Sprintf("%d autocross runs", len(b.Autox)))
+	}
+
+	s.sync.mu.Lock()
+	s.sync.status.LastRestore = time.Now().UnixMilli()
+	s.sync.mu.Unlock()
+	msg := fmt.Sprintf("[sync] restored backup from %s: total=%.1f km, engine %.1f h",
+		time.UnixMilli(b.Saved).Format("2006-01-02 15:04"), total, hours)
+	if len(restored) > 0 {
+		msg += ", " + strings.Join(restored, ", ")
+	}
+	log.Print(msg)
+	return nil
+}
+
+// syncRequest sends body (nil for none) to the backup endpoint and
+// returns the reply. Errors leave out the URL, which may hold a key.
+func syncRequest(ctx context.Context, cfg SyncConfig, method string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.New("invalid sync.url")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	resp, err := syncClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return nil, uerr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if method == http.MethodGet && resp.StatusCode == http.StatusNotFound {
+		return nil, errNoBackup
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxSyncBytes))
+}
+
+// syncHost is the endpoint's host, for logs.
+func syncHost(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "sync.url"
+}
+
+// handleSync reports the backup sync state or uploads now.
+//
+//	GET  /api/sync  — SyncStatus
+//	POST /api/sync  — restore if pending, then upload if anything changed
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if s.cfg.SyncSnapshot().URL == "" {
+			http.Error(w, "sync.url is not set", 400)
+			return
+		}
+		if err := s.syncOnce(r.Context(), true); err != nil {
+			http.Error(w, err.Error(), 502)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.syncStatus())
+}
+
+// handleSyncBackup downloads or restores a backup by hand, for moving it
+// between cards without a sync endpoint.
+//
+//	GET  /api/sync/backup  — the SyncBackup document
+//	POST /api/sync/backup  — restore a SyncBackup (see applyBackup)
+func (s *Server) handleSyncBackup(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		b := s.backup()
+		b.Saved = time.Now().UnixMilli()
+		name := "goefidash-backup.json"
+		if v := slug(b.Vehicle); v != "" {
+			name = v + "-backup.json"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+		json.NewEncoder(w).Encode(b)
+
+	case http.MethodPost:
+		var b SyncBackup
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSyncBytes)).Decode(&b); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		if err := s.applyBackup(&b); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	systemCmdTimeout = 15 * time.Second
+	systemActDelay   = time.Second // Lets the response reach the dash before the OS goes down
+)
+
+// runCommand runs argv, returning its trimmed combined output.
+func runCommand(argv ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), systemCmdTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, argv[0], argv[1:]...).CombinedOutput()
+	msg := strings.TrimSpace(string(out))
+	if err != nil {
+		if msg == "" {
+			msg = err.Error()
+		}
+		return msg, fmt.Errorf("%s: %s", strings.Join(argv, " "), msg)
+	}
+	return msg, nil
+}
+
+// sudo runs argv as root. -n makes a missing sudoers rule an error
+// rather than a password prompt that would hang.
+func sudo(argv ...string) (string, error) {
+	return runCommand(append([]string{"sudo", "-n"}, argv...)...)
+}
+
+// sudoAllowed reports whether sudo would run argv without a password.
+func sudoAllowed(argv ...string) error {
+	_, err := runCommand(append([]string{"sudo", "-n", "-l"}, argv...)...)
+	return err
+}
+
+// systemAction returns the command for a maintenance action.
+func systemAction(cfg SystemConfig, action string) []string {
+	switch action {
+	case "reboot":
+		return []string{"systemctl", "reboot"}
+	case "shutdown":
+		return []string{"systemctl", "poweroff"}
+	case "restart-service":
+		return []string{"systemctl", "restart", cfg.Service}
+	}
+	return nil
+}
+
+// hotspotCommand returns the command switching the hotspot on or off.
+func hotspotCommand(h HotspotConfig, on bool) ([]string, error) {
+	switch h.Method {
+	case "nmcli":
+		verb := "down"
+		if on {
+			verb = "up"
+		}
+		return []string{"nmcli", "connection", verb, h.Connection}, nil
+	case "hostapd":
+		verb := "stop"
+		if on {
+			verb = "start"
+		}
+		return []string{"systemctl", verb, "hostapd"}, nil
+	}
+	return nil, fmt.Errorf("unknown hotspot method %q", h.Method)
+}
+
+// hotspotActive reports whether the hotspot is up.
+func hotspotActive(h HotspotConfig) (bool, error) {
+	switch h.Method {
+	case "nmcli":
+		out, err := runCommand("nmcli", "-t", "-f", "NAME", "connection", "show", "--active")
+		if err != nil {
+			return false, err
+		}
+		for _, name := range strings.Split(out, "\n") {
+			if name == h.Connection {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "hostapd":
+		// is-active exits non-zero when inactive; the output says which
+		out, _ := runCommand("systemctl", "is-active", "hostapd")
+		return out == "active", nil
+	}
+	return false, fmt.Errorf("unknown hotspot method %q", h.Method)
+}
+
+// systemRequestOK rejects maintenance requests a web page on another site
+// could have sent. Requiring a JSON content type forces a CORS preflight,
+// which the dash doesn't answer, and a browser names the page's origin on
+// cross-site POSTs; clients such as curl send no Origin at all.
+func systemRequestOK(w http.ResponseWriter, r *http.Request) bool {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		http.Error(w, "Content-Type must be application/json", 415)
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			log.Printf("[system] rejected cross-origin request from %s", origin)
+			http.Error(w, "cross-origin request", 403)
+			return false
+		}
+	}
+	return true
+}
+
+// handleSystem reports what the maintenance API can do.
+//
+//	GET /api/system
+func (s *Server) handleSystem(w http.ResponseWriter, r *http.Request) {
+	cfg := s.cfg.SystemSnapshot()
+	if !cfg.Enabled {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	type hotspotInfo struct {
+		HotspotConfig
+		Active bool   `json:"active"`
+		Error  string `json:"error,omitempty"`
+	}
+	resp := struct {
+		Service string      `json:"service"`
+		Actions []string    `json:"actions"`
+		Hotspot hotspotInfo `json:"hotspot"`
+	}{
+		Service: cfg.Service,
+		Actions: []string{"reboot", "shutdown", "restart-service"},
+		Hotspot: hotspotInfo{HotspotConfig: cfg.Hotspot},
+	}
+	active, err := hotspotActive(cfg.Hotspot)
+	resp.Hotspot.Active = active
+	if err != nil {
+		resp.Hotspot.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleSystemAction reboots, shuts down or restarts the service. The
+// JSON body must repeat the action, {"confirm": "reboot"}, so a stray
+// or replayed request can't take the car computer down, and be sent as
+// application/json from the dash's own origin (systemRequestOK).
+//
+//	POST /api/system/reboot
+//	POST /api/system/shutdown
+//	POST /api/system/restart-service
+//
+// The command runs a moment after the response; if sudo won't allow it
+// the request fails with 403 instead.
+func (s *Server) handleSystemAction(w http.ResponseWriter, r *http.Request) {
+	cfg := s.cfg.SystemSnapshot()
+	action := strings.TrimPrefix(r.URL.Path, "/api/system/")
+	argv := systemAction(cfg, action)
+	if !cfg.Enabled || argv == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	if !systemRequestOK(w, r) {
+		return
+	}
+	var req struct {
+		Confirm string `json:"confirm"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), 400)
+		return
+	}
+	if req.Confirm != action {
+		http.Error(w, fmt.Sprintf(`confirm with {"confirm": %q}`, action), 400)
+		return
+	}
+	if err := sudoAllowed(argv...); err != nil {
+		log.Printf("[system] %s not permitted: %v", action, err)
+		http.Error(w, "not permitted: "+err.Error(), 403)
+		return
+	}
+
+	log.Printf("[system] %s requested from %s", action, r.RemoteAddr)
+	if action != "restart-service" {
+		// Stopping the service on the way down saves these too, but a
+		// hung shutdown shouldn't cost the odometer
+		s.saveOdometer()
+		s.saveSpeedCal()
+	}
+	time.AfterFunc(systemActDelay, func() {
+		if _, err := sudo(argv...); err != nil {
+			log.Printf("[system] %s failed: %v", action, err)
+		}
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// handleHotspot switches the WiFi hotspot. Like the other actions it
+// takes only same-origin JSON.
+//
+//	POST /api/system/hotspot — {"enabled": true|false}
+//
+// Turning it off from a phone connected through it cuts the phone off;
+// the touchscreen can turn it back on.
+func (s *Server) handleHotspot(w http.ResponseWriter, r *http.Request) {
+	cfg := s.cfg.SystemSnapshot()
+	if !cfg.Enabled {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	if !systemRequestOK(w, r) {
+		return
+	}
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+		http.Error(w, `bad request: want {"enabled": true|false}`, 400)
+		return
+	}
+	argv, err := hotspotCommand(cfg.Hotspot, *req.Enabled)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	if _, err := sudo(argv...); err != nil {
+		log.Printf("[system] hotspot: %v", err)
+		http.Error(w, err.Error(), 502)
+		return
+	}
+	state := "off"
+	if *req.Enabled {
+		state = "on"
+	}
+	log.Printf("[system] hotspot %s", state)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "active": *req.Enabled})
+}
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// tireCalFile is the VSS and GPS distances compared so far inside the
+// data directory.
+const tireCalFile = storage.DirState + "/tirecal.json"
+
+const (
+	tireCalMinKm   = 5.0         // Distance compared before a correction is proposed
+	tireCalMaxTick = time.Second // Longer gaps (data dropouts) aren't counted
+)
+
+// TireCalDistance is the same stretches of road measured by the ECU's VSS
+// and by GPS.
+type TireCalDistance struct {
+	GPSKm float64 `json:"gpsKm"`
+	VSSKm float64 `json:"vssKm"`           // Raw VSS, before any scale
+	Ratio float64 `json:"ratio,omitempty"` // GPS/VSS, once tireCalMinKm compared
+}
+
+// ratio returns GPS/VSS, or 0 before tireCalMinKm or when it's out of the
+// plausible range.
+func (d TireCalDistance) ratio() float64 {
+	if d.GPSKm < tireCalMinKm || d.VSSKm <= 0 {
+		return 0
+	}
+	r := d.GPSKm / d.VSSKm
+	if r < fusionMinScale || r > fusionMaxScale {
+		return 0
+	}
+	return r
+}
+
+func (d TireCalDistance) rounded() TireCalDistance {
+	return TireCalDistance{
+		GPSKm: math.Round(d.GPSKm*100) / 100,
+		VSSKm: math.Round(d.VSSKm*100) / 100,
+		Ratio: math.Round(d.ratio()*10000) / 10000,
+	}
+}
+
+// TireCalStatus is the tire circumference calibration so far.
+type TireCalStatus struct {
+	Drive             TireCalDistance `json:"drive"`                       // This drive
+	Total             TireCalDistance `json:"total"`                       // Since the last reset, what suggestions use
+	BaseTireM         float64         `json:"baseTireM"`                   // Tire circumference the ECU's VSS is set up for
+	TireCircumM       float64         `json:"tireCircumM"`                 // Configured
+	VSSScale          float64         `json:"vssScale"`                    // Configured speed.vss_scale, 0 = learned
+	SuggestedTireM    float64         `json:"suggestedTireM,omitempty"`    // For drivetrain.tire_circum_m
+	SuggestedVSSScale float64         `json:"suggestedVssScale,omitempty"` // For speed.vss_scale
+}
+
+// tireCalibrator integrates raw VSS and GPS speed over the same cruising
+// stretches. Unlike the fuser's per-second scale, the distances aren't
+// thrown by a few noisy seconds, and the ratio carries over to the tire:
+// the VSS was set up for BaseTireM, so the real circumference is that
+// times GPS/VSS.
+type tireCalibrator struct {
+	mu      sync.Mutex
+	last    time.Time
+	prevGPS float64
+	drive   TireCalDistance
+	st      tireCalState
+	dirty   bool // Changed since last saved
+}
+
+// tireCalState is the persisted part of tireCalibrator.
+type tireCalState struct {
+	Total     TireCalDistance `json:"total"`
+	BaseTireM float64         `json:"baseTireM"`
+}
+
+// update adds one broadcast tick, while cruising steadily with both VSS
+// and a GPS fix. spinning is wheelspin, when VSS runs ahead.
+func (t *tireCalibrator) update(now time.Time, e *ecu.DataFrame, g *gps.Data, spinning bool, tireM float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e == nil || e.VSS <= 0 || g == nil || !g.Valid || g.Speed < fusionCalMinKph || spinning {
+		t.last = time.Time{}
+		return
+	}
+	prev, prevGPS := t.last, t.prevGPS
+	t.last, t.prevGPS = now, g.Speed
+	dt := now.Sub(prev)
+	if prev.IsZero() || dt <= 0 || dt > tireCalMaxTick ||
+		math.Abs(g.Speed-prevGPS)/dt.Seconds() > fusionCalMaxAccel {
+		return
+	}
+	gpsKm := g.Speed * dt.Hours()
+	vssKm := float64(e.VSS) * dt.Hours()
+	t.drive.GPSKm += gpsKm
+	t.drive.VSSKm += vssKm
+	t.st.Total.GPSKm += gpsKm
+	t.st.Total.VSSKm += vssKm
+	if t.st.BaseTireM <= 0 {
+		t.st.BaseTireM = tireM
+	}
+	t.dirty = true
+}
+
+// status returns the calibration against the current config.
+func (t *tireCalibrator) status(dt DrivetrainConfig, sp SpeedConfig) *TireCalStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := &TireCalStatus{
+		Drive:       t.drive.rounded(),
+		Total:       t.st.Total.rounded(),
+		BaseTireM:   t.st.BaseTireM,
+		TireCircumM: dt.TireCircumM,
+		VSSScale:    sp.VSSScale,
+	}
+	if st.BaseTireM <= 0 {
+		st.BaseTireM = dt.TireCircumM
+	}
+	if r := t.st.Total.ratio(); r > 0 {
+		st.SuggestedTireM = math.Round(st.BaseTireM*r*1000) / 1000
+		st.SuggestedVSSScale = math.Round(r*1000) / 1000
+	}
+	return st
+}
+
+// resetDrive starts a new drive's distances.
+func (t *tireCalibrator) resetDrive() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.drive, t.last = TireCalDistance{}, time.Time{}
+}
+
+// reset forgets everything compared, e.g. after new tires or a VSS change
+// in the ECU.
+func (t *tireCalibrator) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.drive, t.st, t.last, t.dirty = TireCalDistance{}, tireCalState{}, time.Time{}, false
+}
+
+// loadTireCal restores the distances compared from disk.
+func (s *Server) loadTireCal() {
+	var st tireCalState
+	if err := s.store.ReadJSON(tireCalFile, &st); err != nil {
+		return
+	}
+	s.tireCal.mu.Lock()
+	s.tireCal.st = st
+	s.tireCal.mu.Unlock()
+}
+
+// saveTireCal persists the distances compared, if they have changed.
+func (s *Server) saveTireCal() {
+	t := &s.tireCal
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return
+	}
+	t.dirty = false
+	st := t.st
+	t.mu.Unlock()
+	if err := s.store.WriteJSON(tireCalFile, st); err != nil {
+		log.Printf("[tirecal] save failed: %v", err)
+	}
+}
+
+// applyTireCal saves a calibration config patch and pushes the drivetrain
+// to clients, whose gear detection uses the tire circumference.
+func (s *Server) applyTireCal(patch map[string]any) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	if err := s.cfg.UpdateFromJSON(data); err != nil {
+		return err
+	}
+	if err := s.cfg.Save(); err != nil {
+		log.Printf("[config] save failed: %v", err)
+	}
+	dt := s.cfg.DrivetrainSnapshot()
+	s.broadcast(Frame{Drivetrain: &dt, Stamp: time.Now().UnixMilli()})
+	return nil
+}
+
+// handleTireCal shows and applies the tire circumference or VSS scale
+// worked out from GPS. Nothing is written to the config until applied.
+// Applying doesn't restart the comparison: suggestions stay relative to
+// the tire the ECU's VSS is set up for, so applying twice is harmless.
+//
+//	GET    /api/speed/tirecal            — distances and suggestions
+//	POST   /api/speed/tirecal?apply=tire — save drivetrain.tire_circum_m
+//	POST   /api/speed/tirecal?apply=vss  — save speed.vss_scale
+//	DELETE /api/speed/tirecal            — start over
+func (s *Server) handleTireCal(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.tireCal.status(s.cfg.DrivetrainSnapshot(), s.cfg.SpeedSnapshot()))
+
+	case http.MethodPost:
+		apply := r.URL.Query().Get("apply")
+		if apply != "tire" && apply != "vss" {
+			http.Error(w, "apply must be tire or vss", 400)
+			return
+		}
+		st := s.tireCal.status(s.cfg.DrivetrainSnapshot(), s.cfg.SpeedSnapshot())
+		if st.SuggestedTireM == 0 {
+			http.Error(w, fmt.Sprintf("only %.1f km compared so far; drive at least %.0f km with GPS", st.Total.GPSKm, tireCalMinKm), 409)
+			return
+		}
+		patch := map[string]any{"drivetrain": map[string]any{"tireCircumM": st.SuggestedTireM}}
+		if apply == "vss" {
+			patch = map[string]any{"speed": map[string]any{"vssScale": st.SuggestedVSSScale}}
+		}
+		if err := s.applyTireCal(patch); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		log.Printf("[tirecal] applied %s from %.1f km: tire %.3f m, VSS scale %.3f",
+			apply, st.Total.GPSKm, st.SuggestedTireM, st.SuggestedVSSScale)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.tireCal.status(s.cfg.DrivetrainSnapshot(), s.cfg.SpeedSnapshot()))
+
+	case http.MethodDelete:
+		s.tireCal.reset()
+		if err := s.store.Remove(tireCalFile); err != nil {
+			log.Printf("[tirecal] %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+const (
+	toothLogPrefix  = "toothlog-" // Names trigger logs inside storage.DirCaptures
+	toothLogDefault = 5           // Seconds
+	toothLogMax     = 60
+	toothLogKeep    = 20
+)
+
+// toothLogInfo lists a stored trigger log.
+type toothLogInfo struct {
+	ID      string `json:"id"`
+	Mode    string `json:"mode"` // "tooth" or "composite"
+	Created int64  `json:"created"`
+	Entries int    `json:"entries"`
+	URL     string `json:"url"`
+}
+
+// handleToothLog captures the ECU's tooth or composite logger to a CSV
+// file, for diagnosing sync loss without a laptop running TunerStudio.
+//
+//	POST /api/ecu/toothlog/start    — {"seconds": 5, "mode": "tooth"|"composite", "ecu": "name"}
+//	GET  /api/ecu/toothlog          — stored logs, newest first
+//	GET  /api/ecu/toothlog?id=...   — download one
+//
+// The POST returns once the capture ends (at most 60 s); live data keeps
+// flowing while it runs. One capture runs at a time.
+func (s *Server) handleToothLog(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/ecu/toothlog/start":
+		s.startToothLog(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/ecu/toothlog":
+		if id := r.URL.Query().Get("id"); id != "" {
+			s.serveToothLog(w, id)
+			return
+		}
+		s.listToothLogs(w)
+	case r.URL.Path != "/api/ecu/toothlog" && r.URL.Path != "/api/ecu/toothlog/start":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
+
+func (s *Server) startToothLog(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Seconds int    `json:"seconds"`
+		Mode    string `json:"mode"`
+		ECU     string `json:"ecu"`
+	}
+	if body, err := io.ReadAll(r.Body); err == nil && len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	}
+	switch {
+	case req.Seconds == 0:
+		req.Seconds = toothLogDefault
+	case req.Seconds < 0 || req.Seconds > toothLogMax:
+		http.Error(w, fmt.Sprintf("seconds must be 1-%d", toothLogMax), 400)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = "tooth"
+	}
+	if req.Mode != "tooth" && req.Mode != "composite" {
+		http.Error(w, "mode must be tooth or composite", 400)
+		return
+	}
+	prov := s.ecuByName(req.ECU)
+	if prov == nil {
+		http.Error(w, "no such ecu: "+req.ECU, 404)
+		return
+	}
+	tl, ok := prov.(ecu.ToothLogger)
+	if !ok {
+		http.Error(w, prov.Name()+" has no tooth logger", 501)
+		return
+	}
+	if !s.toothLogging.CompareAndSwap(false, true) {
+		http.Error(w, "a tooth log is already being captured", 409)
+		return
+	}
+	defer s.toothLogging.Store(false)
+
+	composite := req.Mode == "composite"
+	log.Printf("[toothlog] capturing %s log for %d s", req.Mode, req.Seconds)
+	now := time.Now()
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(req.Seconds)*time.Second)
+	defer cancel()
+	var entries []ecu.ToothEntry
+	err := tl.ToothLog(ctx, composite, func(e []ecu.ToothEntry) { entries = append(entries, e...) })
+	if err != nil && len(entries) == 0 {
+		http.Error(w, err.Error(), 502)
+		return
+	}
+	if err != nil {
+		log.Printf("[toothlog] capture cut short: %v", err) // Keep what arrived
+	}
+
+	info := toothLogInfo{
+		ID:      fmt.Sprintf("%s%s-%d", toothLogPrefix, req.Mode, now.UnixMilli()),
+		Mode:    req.Mode,
+		Created: now.UnixMilli(),
+		Entries: len(entries),
+	}
+	info.URL = toothLogURL(info.ID)
+	if err := s.store.WriteFile(storage.DirCaptures+"/"+info.ID+".csv", toothLogCSV(entries, composite)); err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	log.Printf("[toothlog] saved %s (%d entries)", info.ID, info.Entries)
+	s.pruneToothLogs()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// toothLogCSV formats a trigger log with a header row. Tooth logs have
+// the gap to each tooth; composite logs the time of each edge and the
+// input levels after it.
+func toothLogCSV(entries []ecu.ToothEntry, composite bool) []byte {
+	var b bytes.Buffer
+	if !composite {
+		b.WriteString("tooth,gapUs\n")
+		for i, e := range entries {
+			fmt.Fprintf(&b, "%d,%d\n", i+1, e.Time)
+		}
+		return b.Bytes()
+	}
+	bit := func(e ecu.ToothEntry, f uint8) int {
+		if e.Flags&f != 0 {
+			return 1
+		}
+		return 0
+	}
+	b.WriteString("entry,timeMs,pri,sec,third,trigger,sync\n")
+	for i, e := range entries {
+		fmt.Fprintf(&b, "%d,%.3f,%d,%d,%d,%d,%d\n", i+1, float64(e.Time)/1000,
+			bit(e, ecu.CompositePri), bit(e, ecu.CompositeSec), bit(e, ecu.CompositeThird),
+			bit(e, ecu.CompositeTrigger), bit(e, ecu.CompositeSync))
+	}
+	return b.Bytes()
+}
+
+// toothLogIDs returns stored trigger log IDs, oldest first.
+func (s *Server) toothLogIDs() []string {
+	names, err := s.store.List(storage.DirCaptures)
+	if err != nil {
+		log.Printf("[toothlog] list failed: %v", err)
+	}
+	var ids []string
+	for _, n := range names {
+		if strings.HasPrefix(n, toothLogPrefix) && strings.HasSuffix(n, ".csv") {
+			ids = append(ids, strings.TrimSuffix(n, ".csv"))
+		}
+	}
+	// Order by the millisecond stamp after the mode
+	sort.Slice(ids, func(i, j int) bool { return toothLogStamp(ids[i]) < toothLogStamp(ids[j]) })
+	return ids
+}
+
+// toothLogStamp returns the Unix ms an ID was created at.
+func toothLogStamp(id string) string {
+	return id[strings.LastIndexByte(id, '-')+1:]
+}
+
+// pruneToothLogs removes the oldest logs beyond toothLogKeep.
+func (s *Server) pruneToothLogs() {
+	ids := s.toothLogIDs()
+	for len(ids) > toothLogKeep {
+		if err := s.store.Remove(storage.DirCaptures + "/" + ids[0] + ".csv"); err != nil {
+			log.Printf("[toothlog] prune failed: %v", err)
+		}
+		ids = ids[1:]
+	}
+}
+
+// listToothLogs writes the stored logs, newest first.
+func (s *Server) listToothLogs(w http.ResponseWriter) {
+	ids := s.toothLogIDs()
+	list := make([]toothLogInfo, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		data, err := s.store.ReadFile(storage.DirCaptures + "/" + ids[i] + ".csv")
+		if err != nil {
+			continue
+		}
+		info := toothLogInfo{ID: ids[i], Entries: bytes.Count(data, []byte("\n")) - 1, URL: toothLogURL(ids[i])}
+		info.Mode, _, _ = strings.Cut(strings.TrimPrefix(ids[i], toothLogPrefix), "-")
+		fmt.Sscan(toothLogStamp(ids[i]), &info.Created)
+		list = append(list, info)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// serveToothLog sends one stored log as a CSV download.
+func (s *Server) serveToothLog(w http.ResponseWriter, id string) {
+	if !strings.HasPrefix(id, toothLogPrefix) || strings.ContainsAny(id, `/\.`) {
+		http.Error(w, "bad tooth log id", 400)
+		return
+	}
+	data, err := s.store.ReadFile(storage.DirCaptures + "/" + id + ".csv")
+	if err != nil {
+		http.Error(w, "tooth log not found", 404)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".csv"))
+	w.Write(data)
+}
+
+func toothLogURL(id string) string { return "/api/ecu/toothlog?id=" + id }
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/sensors"
+)
+
+// tireAlerts checks each corner of fresh tpms readings against the tire
+// thresholds: "tire_<corner>" alerts, whether or not the engine is
+// running, since a slow leak shows up parked too.
+func (s *Server) tireAlerts(readings map[string]*sensors.Reading) []Alert {
+	t := s.cfg.Thresholds()
+	unit := s.cfg.DisplaySnapshot().Units.Pressure
+	var out []Alert
+	now := time.Now().UnixMilli()
+	for _, a := range s.sensors {
+		r := readings[a.cfg.Name]
+		if a.cfg.Type != "tpms" || !r.OK() || now-r.Stamp > auxSensorStale.Milliseconds() {
+			continue
+		}
+		var corners []string
+		for ch := range r.Channels {
+			if c, ok := strings.CutSuffix(strings.TrimPrefix(ch, "tire_"), "_kpa"); ok {
+				corners = append(corners, c)
+			}
+		}
+		sort.Strings(corners)
+		for _, c := range corners {
+			kpa := r.Channels["tire_"+c+"_kpa"]
+			temp, hasTemp := r.Channels["tire_"+c+"_temp"]
+			id, name := "tire_"+c, strings.ToUpper(c)
+			switch {
+			case t.TireFlatKPa > 0 && kpa < t.TireFlatKPa:
+				out = append(out, Alert{ID: id, Level: alertCritical, Text: fmt.Sprintf("FLAT TIRE %s %s", name, tirePressure(kpa, unit))})
+			case t.TireLowKPa > 0 && kpa < t.TireLowKPa:
+				out = append(out, Alert{ID: id, Level: alertWarning, Text: fmt.Sprintf("LOW TIRE %s %s", name, tirePressure(kpa, unit))})
+			case t.TireHotC > 0 && hasTemp && temp >= t.TireHotC:
+				out = append(out, Alert{ID: id, Level: alertWarning, Text: fmt.Sprintf("HOT TIRE %s %.0f°C", name, temp)})
+			}
+		}
+	}
+	return out
+}
+
+// tirePressure formats kPa in the display's pressure unit.
+func tirePressure(kpa float64, unit string) string {
+	switch unit {
+	case "kpa":
+		return fmt.Sprintf("%.0f kPa", kpa)
+	case "bar":
+		return fmt.Sprintf("%.2f bar", kpa/100)
+	}
+	return fmt.Sprintf("%.1f PSI", kpa/6.894757)
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// activeTrackFile is the active track definition inside the data directory.
+const activeTrackFile = storage.DirTracks + "/active.json"
+
+// minHeadingSpeed is the GPS speed (km/h) below which course-over-ground
+// is too noisy to orient a timing line.
+const minHeadingSpeed = 8.0
+
+// loadTrack restores the active track definition from disk.
+func (s *Server) loadTrack() {
+	var t track.Track
+	if err := s.store.ReadJSON(activeTrackFile, &t); err != nil {
+		return
+	}
+	s.trackMu.Lock()
+	s.track = &t
+	s.trackMu.Unlock()
+	log.Printf("[track] loaded active track %q", t.Name)
+}
+
+// saveTrack persists the active track definition (nil removes it).
+func (s *Server) saveTrack(t *track.Track) error {
+	if t == nil {
+		return s.store.Remove(activeTrackFile)
+	}
+	return s.store.WriteJSON(activeTrackFile, t)
+}
+
+// activeTrack returns the active track definition, or nil.
+func (s *Server) activeTrack() *track.Track {
+	s.trackMu.Lock()
+	defer s.trackMu.Unlock()
+	return s.track
+}
+
+// handleTrack serves the active track definition.
+//
+//	GET    /api/track — active track (or null)
+//	DELETE /api/track — forget it
+func (s *Server) handleTrack(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.activeTrack())
+
+	case http.MethodDelete:
+		s.trackMu.Lock()
+		s.track = nil
+		s.trackMu.Unlock()
+		if err := s.saveTrack(nil); err != nil {
+			log.Printf("[track] remove failed: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
+
+// errNoFix is an action that needs the car's position without a valid
+// GPS fix.
+var errNoFix = errors.New("no valid GPS fix")
+
+// handleTrackStartFinish captures the current GPS position and heading as
+// the active track's start/finish line ("set start/finish here").
+// Optional JSON body: {"name": "Backroad loop", "widthM": 25}.
+// If there's no active track, an ad-hoc one is created.
+func (s *Server) handleTrackStartFinish(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+
+	var req struct {
+		Name   string  `json:"name"`
+		WidthM float64 `json:"widthM"`
+	}
+	if body, err := io.ReadAll(r.Body); err == nil && len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	}
+
+	t, err := s.setStartFinish(req.Name, req.WidthM)
+	if err != nil {
+		http.Error(w, err.Error(), 409)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// setStartFinish puts the active track's start/finish line where the car
+// is, creating an ad-hoc track if there's none. name renames the track
+// and widthM <= 0 is the default width.
+func (s *Server) setStartFinish(name string, widthM float64) (*track.Track, error) {
+	fix := s.latestGPS()
+	if fix == nil || !fix.Valid {
+		return nil, errNoFix
+	}
+	if widthM <= 0 {
+		widthM = track.DefaultGateWidthM
+	}
+	line := track.Line{
+		Lat:          fix.Latitude,
+		Lon:          fix.Longitude,
+		Heading:      fix.Heading,
+		HeadingValid: fix.Speed >= minHeadingSpeed,
+		WidthM:       widthM,
+	}
+
+	s.trackMu.Lock()
+	t := s.track
+	if t == nil {
+		if name == "" {
+			name = "Ad-hoc " + time.Now().Format("2006-01-02 15:04")
+		}
+		t = track.New(name)
+	} else {
+		cp := *t
+		t = &cp
+		if name != "" {
+			t.Name = name
+		}
+	}
+	t.StartFinish = &line
+	t.Updated = time.Now().UnixMilli()
+	s.track = t
+	s.trackMu.Unlock()
+
+	if err := s.saveTrack(t); err != nil {
+		log.Printf("[track] save failed: %v", err)
+	}
+	log.Printf("[track] start/finish for %q set at %.6f,%.6f heading %.0f° (valid=%v)",
+		t.Name, line.Lat, line.Lon, line.Heading, line.HeadingValid)
+	return t, nil
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/gps"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+	"github.com/shaunagostinho/speeduino-dash/internal/track"
+)
+
+// userTrackDir holds tracks added through the API, one JSON file each.
+const userTrackDir = storage.DirTracks + "/library"
+
+// slug returns name lower-cased, with anything but letters and digits
+// turned into dashes, for use in file names.
+func slug(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+}
+
+// userTrackFile returns the file for a user track.
+func userTrackFile(name string) string {
+	return userTrackDir + "/" + slug(name) + ".json"
+}
+
+// loadTrackLibrary builds the track library: built-ins, then configured
+// files, then user tracks, each overriding same-named tracks before it.
+func (s *Server) loadTrackLibrary() {
+	lib := track.NewLibrary()
+	for _, path := range s.cfg.TracksSnapshot().Library {
+		n, err := lib.LoadPath(path)
+		if err != nil {
+			log.Printf("[track] library %s: %v", path, err)
+		}
+		if n > 0 {
+			log.Printf("[track] loaded %d tracks from %s", n, path)
+		}
+	}
+	names, err := s.store.List(userTrackDir)
+	if err != nil {
+		log.Printf("[track] list user tracks failed: %v", err)
+	}
+	for _, name := range names {
+		var t track.Track
+		if err := s.store.ReadJSON(userTrackDir+"/"+name, &t); err != nil || t.Validate() != nil {
+			log.Printf("[track] skipping bad user track %s", name)
+			continue
+		}
+		t.Source = track.SourceUser
+		lib.Add(&t)
+	}
+	s.trackLib.Store(lib)
+}
+
+// setActiveTrack makes t the active track and persists it.
+func (s *Server) setActiveTrack(t *track.Track) {
+	s.trackMu.Lock()
+	s.track = t
+	s.trackMu.Unlock()
+	if err := s.saveTrack(t); err != nil {
+		log.Printf("[track] save failed: %v", err)
+	}
+}
+
+// detectTrack picks the active track from the first GPS fix: the library
+// track nearest to it, if within the detection radius. An active track
+// that's already close enough is kept, so one set up on the day (or
+// selected by hand) survives a restart.
+func (s *Server) detectTrack(g *gps.Data) {
+	if g == nil || !g.Valid || s.trackDetected.Swap(true) {
+		return
+	}
+	cfg := s.cfg.TracksSnapshot()
+	if !cfg.AutoDetect {
+		return
+	}
+	pos := track.Point{Lat: g.Latitude, Lon: g.Longitude}
+	if t := s.activeTrack(); t != nil && t.StartFinish != nil && track.DistanceM(pos, t.Location()) <= cfg.DetectRadiusM {
+		log.Printf("[track] at active track %q", t.Name)
+		return
+	}
+	t, d := s.trackLib.Load().Nearest(pos)
+	if t == nil || d > cfg.DetectRadiusM {
+		log.Printf("[track] no library track within %.0f m", cfg.DetectRadiusM)
+		return
+	}
+	s.setActiveTrack(t)
+	log.Printf("[track] detected %q (%.0f m away)", t.Name, d)
+}
+
+// trackInfo is a library track as listed by the API.
+type trackInfo struct {
+	track.Track
+	Active    bool     `json:"active"`
+	DistanceM *float64 `json:"distanceM,omitempty"` // From the current fix
+}
+
+// handleTracks serves the track library.
+//
+//	GET    /api/tracks — every track, nearest first when there's a fix
+//	POST   /api/tracks — add or replace a user track (a track.Track, or
+//	                     "startFinishPoints"/"sectorPoints" gates); with
+//	                     "select": true it also becomes the active track
+//	DELETE /api/tracks?name=<name> — remove a user track
+func (s *Server) handleTracks(w http.ResponseWriter, r *http.Request) {
+	lib := s.trackLib.Load()
+	switch r.Method {
+	case http.MethodGet:
+		var active string
+		if t := s.activeTrack(); t != nil {
+			active = strings.ToLower(t.Name)
+		}
+		fix := s.latestGPS()
+		list := lib.List()
+		out := make([]trackInfo, len(list))
+		for i, t := range list {
+			out[i] = trackInfo{Track: t, Active: strings.ToLower(t.Name) == active}
+			if fix != nil && fix.Valid {
+				d := math.Round(track.DistanceM(track.Point{Lat: fix.Latitude, Lon: fix.Longitude}, t.Location()))
+				out[i].DistanceM = &d
+			}
+		}
+		if fix != nil && fix.Valid {
+			sort.SliceStable(out, func(i, j int) bool { return *out[i].DistanceM < *out[j].DistanceM })
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+
+	case http.MethodPost:
+		var req struct {
+			track.Track
+			StartFinishPoints []track.Point   `json:"startFinishPoints"`
+			SectorPoints      [][]track.Point `json:"sectorPoints"`
+			Select            bool            `json:"select"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request: "+err.Error(), 400)
+			return
+		}
+		t := req.Track
+		if len(req.StartFinishPoints) >= 2 {
+			l := track.LineBetween(req.StartFinishPoints[0], req.StartFinishPoints[1])
+			t.StartFinish = &l
+		}
+		if len(req.SectorPoints) > 0 {
+			t.Sectors = nil
+			for _, sp := range req.SectorPoints {
+				if len(sp) >= 2 {
+					t.Sectors = append(t.Sectors, track.LineBetween(sp[0], sp[1]))
+				}
+			}
+		}
+		if err := t.Validate(); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		now := time.Now().UnixMilli()
+		if t.Created == 0 {
+			t.Created = now
+		}
+		t.Updated = now
+		t.Source = track.SourceUser
+		if err := s.store.WriteJSON(userTrackFile(t.Name), &t); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		lib.Add(&t)
+		log.Printf("[track] added %q to the library", t.Name)
+		if req.Select {
+			cp := t
+			s.setActiveTrack(&cp)
+			log.Printf("[track] selected %q", t.Name)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		t := lib.Get(name)
+		if t == nil {
+			http.Error(w, "track not found", 404)
+			return
+		}
+		if t.Source != track.SourceUser {
+			http.Error(w, "only user tracks can be removed", 409)
+			return
+		}
+		if err := s.store.Remove(userTrackFile(t.Name)); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		// Rebuild so a built-in or file track of the same name returns
+		s.loadTrackLibrary()
+		log.Printf("[track] removed %q from the library", t.Name)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
+
+// handleTrackSelect makes a library track the active one.
+// JSON body: {"name": "Club circuit"}.
+func (s *Server) handleTrackSelect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), 400)
+		return
+	}
+	t := s.trackLib.Load().Get(req.Name)
+	if t == nil {
+		http.Error(w, "track not found", 404)
+		return
+	}
+	s.setActiveTrack(t)
+	log.Printf("[track] selected %q", t.Name)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
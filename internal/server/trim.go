@@ -0,0 +1,214 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/ecu"
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// fuelTrimFile is the learned fuel trims inside the data directory.
+const fuelTrimFile = storage.DirState + "/fueltrim.json"
+
+const (
+	trimMinCLT    = 70.0             // °C; Speeduino's default closed-loop enable temperature
+	trimMaxTick   = time.Second      // Longer gaps (ECU dropouts) aren't counted
+	trimWindow    = 10 * time.Minute // Learning time per cell after which older samples fade
+	trimMinSec    = 2.0              // Cells learned for less are left blank in CSV
+	trimMaxMAPdot = 30               // kPa/s; faster load changes are transients
+)
+
+// FuelTrimMap is the long-term EGO correction over the same RPM × MAP
+// grid as the knock map, kept across drives: where the closed loop keeps
+// adding or pulling fuel, the VE table is off by about that much. Z
+// arrays are indexed [load row][rpm column].
+type FuelTrimMap struct {
+	RPM     []int       `json:"rpm"`     // Column start, RPM
+	Load    []int       `json:"load"`    // Row start, MAP kPa
+	Trim    [][]float64 `json:"trim"`    // Mean EGO correction - 100, % (+ = ECU adding fuel)
+	Seconds [][]float64 `json:"seconds"` // Learning time in the cell, up to trimWindow
+	Updated int64       `json:"updated"` // Unix ms of the last sample
+}
+
+func newFuelTrimMap() *FuelTrimMap {
+	m := &FuelTrimMap{
+		RPM:     make([]int, knockRPMCols),
+		Load:    make([]int, knockLoadRows),
+		Trim:    make([][]float64, knockLoadRows),
+		Seconds: make([][]float64, knockLoadRows),
+	}
+	for c := range m.RPM {
+		m.RPM[c] = c * knockRPMStep
+	}
+	for r := range m.Load {
+		m.Load[r] = r * knockLoadStep
+		m.Trim[r] = make([]float64, knockRPMCols)
+		m.Seconds[r] = make([]float64, knockRPMCols)
+	}
+	return m
+}
+
+// valid reports whether m has the current grid's shape, so a file from a
+// release with different axes is discarded rather than misread.
+func (m *FuelTrimMap) valid() bool {
+	if len(m.RPM) != knockRPMCols || len(m.Trim) != knockLoadRows || len(m.Seconds) != knockLoadRows {
+		return false
+	}
+	for r := range m.Trim {
+		if len(m.Trim[r]) != knockRPMCols || len(m.Seconds[r]) != knockRPMCols {
+			return false
+		}
+	}
+	return true
+}
+
+// fuelTrimLearner accumulates the FuelTrimMap.
+type fuelTrimLearner struct {
+	mu    sync.Mutex
+	m     *FuelTrimMap
+	last  time.Time
+	dirty bool // Changed since last saved
+}
+
+// update adds one broadcast tick. Each cell's trim is a time-weighted
+// mean of the EGO correction, which becomes an exponential average with
+// a trimWindow time constant once the cell is full, so it follows tune
+// changes. Only steady, warm, fuelled running in closed loop is learned.
+func (t *fuelTrimLearner) update(now time.Time, e *ecu.DataFrame) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e == nil || !trimLearning(e) {
+		t.last = time.Time{}
+		return
+	}
+	prev := t.last
+	t.last = now
+	dt := now.Sub(prev)
+	if prev.IsZero() || dt <= 0 || dt > trimMaxTick {
+		return
+	}
+	if t.m == nil {
+		t.m = newFuelTrimMap()
+	}
+	r := min(int(e.MAP)/knockLoadStep, knockLoadRows-1)
+	c := min(int(e.RPM)/knockRPMStep, knockRPMCols-1)
+
+	secs := t.m.Seconds[r][c] + dt.Seconds()
+	trim := float64(e.EGOCorrection) - 100
+	t.m.Trim[r][c] += (trim - t.m.Trim[r][c]) * dt.Seconds() / secs
+	t.m.Seconds[r][c] = math.Min(secs, trimWindow.Seconds())
+	t.m.Updated = now.UnixMilli()
+	t.dirty = true
+}
+
+// trimLearning reports whether e's EGO correction reflects the VE table:
+// engine warm and running on the O2 sensor at a steady load, not in
+// decel cut or afterstart enrichment.
+func trimLearning(e *ecu.DataFrame) bool {
+	return e.Running && e.RPM > 500 && e.Coolant >= trimMinCLT &&
+		e.EGOCorrection > 0 && e.AFR > 0 && !e.DFCOOn && !e.ASE &&
+		e.MAPdot > -trimMaxMAPdot && e.MAPdot < trimMaxMAPdot
+}
+
+// snapshot returns a copy of the map, or nil before anything is learned.
+func (t *fuelTrimLearner) snapshot() *FuelTrimMap {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.m == nil {
+		return nil
+	}
+	out := *t.m
+	out.Trim = make([][]float64, len(t.m.Trim))
+	out.Seconds = make([][]float64, len(t.m.Seconds))
+	for r := range t.m.Trim {
+		out.Trim[r] = append([]float64(nil), t.m.Trim[r]...)
+		out.Seconds[r] = append([]float64(nil), t.m.Seconds[r]...)
+	}
+	return &out
+}
+
+// reset forgets everything learned, after the VE table has been fixed.
+func (t *fuelTrimLearner) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.m, t.last, t.dirty = nil, time.Time{}, false
+}
+
+// loadFuelTrim restores the learned trims from disk.
+func (s *Server) loadFuelTrim() {
+	var m FuelTrimMap
+	if err := s.store.ReadJSON(fuelTrimFile, &m); err != nil {
+		return
+	}
+	if !m.valid() {
+		log.Printf("[trim] ignoring %s: grid doesn't match", s.store.Path(fuelTrimFile))
+		return
+	}
+	s.trim.mu.Lock()
+	s.trim.m = &m
+	s.trim.mu.Unlock()
+	log.Printf("[trim] loaded fuel trims (updated %s)", time.UnixMilli(m.Updated).Format(time.DateTime))
+}
+
+// saveFuelTrim persists the learned trims, if they have changed.
+func (s *Server) saveFuelTrim() {
+	t := &s.trim
+	t.mu.Lock()
+	if !t.dirty || t.m == nil {
+		t.mu.Unlock()
+		return
+	}
+	t.dirty = false
+	t.mu.Unlock()
+	m := t.snapshot()
+	if m == nil { // Cleared meanwhile
+		return
+	}
+	if err := s.store.WriteJSON(fuelTrimFile, m); err != nil {
+		log.Printf("[trim] save failed: %v", err)
+	}
+}
+
+// handleFuelTrim serves or clears the learned fuel trims:
+//
+//	GET    /api/fueltrim[?format=csv] — the map; CSV is trim % per cell
+//	DELETE /api/fueltrim              — forget it, after fixing the VE table
+func (s *Server) handleFuelTrim(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		m := s.trim.snapshot()
+		if m == nil {
+			http.Error(w, "no fuel trims learned yet", 404)
+			return
+		}
+		if r.URL.Query().Get("format") != "csv" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(m)
+			return
+		}
+		writeGridCSV(w, "fueltrim", m.RPM, m.Load, func(row, c int) string {
+			if m.Seconds[row][c] < trimMinSec {
+				return ""
+			}
+			return strconv.FormatFloat(m.Trim[row][c], 'f', 1, 64)
+		})
+
+	case http.MethodDelete:
+		s.trim.reset()
+		if err := s.store.Remove(fuelTrimFile); err != nil {
+			log.Printf("[trim] %v", err)
+		}
+		log.Printf("[trim] fuel trims cleared")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
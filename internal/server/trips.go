@@ -0,0 +1,175 @@
+package server
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+)
+
+// tripsFile is the trip meters' statistics inside the data directory.
+// Their distances are in odoFile.
+const tripsFile = storage.DirState + "/trips.json"
+
+const (
+	tripMovingKph = 3.0         // Slower counts as stopped
+	tripMaxTick   = time.Second // Longer gaps (data dropouts) aren't counted
+)
+
+// Trip meter indexes into Server.trips.
+const (
+	tripA = iota
+	tripB
+)
+
+// tripMeter is a trip's statistics besides distance.
+type tripMeter struct {
+	MovingSec float64 `json:"movingSec"`
+	MaxSpeed  float64 `json:"maxSpeed"` // km/h
+	Since     int64   `json:"since"`    // Unix ms of the last reset, 0 = never
+	Base      float64 `json:"base"`     // Trip km when the statistics began, for the average
+}
+
+// TripStats is one trip meter as sent to clients.
+type TripStats struct {
+	Distance  float64 `json:"distance"`  // km
+	MovingSec float64 `json:"movingSec"` // Time at tripMovingKph or more
+	AvgSpeed  float64 `json:"avgSpeed"`  // km/h over the moving time
+	MaxSpeed  float64 `json:"maxSpeed"`  // km/h
+	Since     int64   `json:"since"`     // Unix ms of the last reset, 0 = never
+}
+
+// stats builds a trip's stats from its distance.
+func (t tripMeter) stats(km float64) *TripStats {
+	st := &TripStats{
+		Distance:  math.Round(km*10) / 10,
+		MovingSec: math.Round(t.MovingSec),
+		MaxSpeed:  math.Round(t.MaxSpeed),
+		Since:     t.Since,
+	}
+	if t.MovingSec > 0 {
+		st.AvgSpeed = math.Round((km-t.Base)/(t.MovingSec/3600)*10) / 10
+	}
+	return st
+}
+
+// addDistanceLocked adds km driven to the odometer and both trips, or to
+// the reverse tally. Called with odoMu held.
+func (s *Server) addDistanceLocked(km float64, reverse bool) {
+	if reverse {
+		s.odoReverse += km
+		return
+	}
+	s.odoTotal += km
+	s.odoTrip += km
+	s.odoTripB += km
+}
+
+// updateTrips adds one broadcast tick's moving time and top speed to both
+// trips. speed is km/h.
+func (s *Server) updateTrips(now time.Time, speed float64) {
+	s.odoMu.Lock()
+	defer s.odoMu.Unlock()
+	prev := s.tripsAt
+	s.tripsAt = now
+	dt := now.Sub(prev)
+	if prev.IsZero() || dt <= 0 || dt > tripMaxTick || speed < tripMovingKph {
+		return
+	}
+	for i := range s.trips {
+		s.trips[i].MovingSec += dt.Seconds()
+		s.trips[i].MaxSpeed = math.Max(s.trips[i].MaxSpeed, speed)
+	}
+	s.tripsDirty = true
+}
+
+// odoData returns the odometer and trip meters for a frame.
+func (s *Server) odoData() *OdoData {
+	s.odoMu.Lock()
+	odo := &OdoData{
+		Total:   math.Round(s.odoTotal*10) / 10,
+		Trip:    math.Round(s.odoTrip*10) / 10,
+		Reverse: math.Round(s.odoReverse*100) / 100,
+		TripA:   s.trips[tripA].stats(s.odoTrip),
+		TripB:   s.trips[tripB].stats(s.odoTripB),
+
+		EngineHours: math.Round(s.engineHours*100) / 100,
+	}
+	s.odoMu.Unlock()
+	odo.DFCO = s.dfco.tripStats()
+	return odo
+}
+
+// loadTrips restores the trip statistics from disk. Without them (first
+// run after an upgrade) trip A's distance so far is left out of its
+// average speed.
+func (s *Server) loadTrips() {
+	s.odoMu.Lock()
+	defer s.odoMu.Unlock()
+	var trips [2]tripMeter
+	if err := s.store.ReadJSON(tripsFile, &trips); err != nil {
+		s.trips[tripA].Base = s.odoTrip
+		s.tripsDirty = true
+		return
+	}
+	s.trips = trips
+}
+
+// saveTrips persists the trip statistics, if they have changed.
+func (s *Server) saveTrips() {
+	s.odoMu.Lock()
+	if !s.tripsDirty {
+		s.odoMu.Unlock()
+		return
+	}
+	s.tripsDirty = false
+	trips := s.trips
+	s.odoMu.Unlock()
+	if err := s.store.WriteJSON(tripsFile, trips); err != nil {
+		log.Printf("[odo] trips save failed: %v", err)
+	}
+}
+
+// resetTrip zeroes trip A (with its reverse tally and DFCO totals) or B.
+func (s *Server) resetTrip(i int) {
+	s.odoMu.Lock()
+	if i == tripA {
+		s.odoTrip, s.odoReverse = 0, 0
+	} else {
+		s.odoTripB = 0
+	}
+	s.trips[i] = tripMeter{Since: time.Now().UnixMilli()}
+	s.tripsDirty = true
+	s.odoMu.Unlock()
+	s.saveOdometer()
+	s.saveTrips()
+	if i == tripA {
+		s.dfco.resetTrip()
+		s.saveDFCO()
+	}
+}
+
+// handleResetTrip resets a trip meter.
+//
+//	POST /api/odo/reset?trip=a|b — default a
+//	POST /api/odo/reset-trip     — trip a, for older clients
+func (s *Server) handleResetTrip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	switch strings.ToLower(r.URL.Query().Get("trip")) {
+	case "", "a":
+		s.resetTrip(tripA)
+	case "b":
+		s.resetTrip(tripB)
+	default:
+		http.Error(w, "trip must be a or b", 400)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/shaunagostinho/speeduino-dash/internal/storage"
+	"github.com/shaunagostinho/speeduino-dash/internal/tune"
+)
+
+// tuneFile is the reference values from the last uploaded tune inside the
+// data directory. The MSQ itself isn't kept.
+const tuneFile = storage.DirState + "/tune.json"
+
+// maxTuneBytes bounds an MSQ upload; real ones are 100–500 KB.
+const maxTuneBytes = 4 << 20
+
+// loadTune restores the tune reference values from disk.
+func (s *Server) loadTune() {
+	var ref tune.Reference
+	if err := s.store.ReadJSON(tuneFile, &ref); err != nil {
+		return
+	}
+	s.tuneRef.Store(&ref)
+	log.Printf("[tune] loaded reference from %s (%s)", ref.File, ref.Signature)
+}
+
+// suggestThresholds derives dash thresholds from a tune: RPM warnings
+// below the rev limiter and AFR warnings a point either side of the
+// target table. Thresholds the tune says nothing about are kept.
+func suggestThresholds(ref *tune.Reference, cur ThresholdConfig) ThresholdConfig {
+	t := cur
+	limit := ref.SoftRevLimit
+	if limit == 0 && ref.RevLimit > 0 {
+		limit = ref.RevLimit - 200
+	}
+	if limit > 0 {
+		gap := 500
+		if cur.RPMDanger > cur.RPMWarn {
+			gap = int(cur.RPMDanger - cur.RPMWarn)
+		}
+		t.RPMDanger = uint16(limit)
+		t.RPMWarn = uint16(max(0, int(limit)-gap))
+		t.RPMMax = uint16(math.Ceil(math.Max(limit, ref.RevLimit)/1000) * 1000)
+		if t.RPMMax <= t.RPMDanger {
+			t.RPMMax += 1000
+		}
+	}
+	if ref.AFR != nil {
+		t.AFRLeanWarn = math.Round((ref.AFR.Max+1)*10) / 10
+		t.AFRRichWarn = math.Round((ref.AFR.Min-1)*10) / 10
+	}
+	return t
+}
+
+// applyThresholds saves t as the dash thresholds and pushes them to
+// clients.
+func (s *Server) applyThresholds(t ThresholdConfig) error {
+	patch, err := json.Marshal(map[string]any{"display": map[string]any{"thresholds": t}})
+	if err != nil {
+		return err
+	}
+	if err := s.cfg.UpdateFromJSON(patch); err != nil {
+		return err
+	}
+	if err := s.cfg.Save(); err != nil {
+		log.Printf("[config] save failed: %v", err)
+	}
+	display := s.cfg.DisplaySnapshot()
+	s.broadcast(Frame{Config: &display, Stamp: time.Now().UnixMilli()})
+	return nil
+}
+
+// annotateAlerts adds what the tune says to alerts it has a value for.
+func annotateAlerts(alerts []Alert, ref *tune.Reference, rpm, mapKPa, tps float64) []Alert {
+	if ref == nil {
+		return alerts
+	}
+	for i, a := range alerts {
+		switch a.ID {
+		case "afr":
+			if t := ref.AFRTable; t != nil {
+				load := mapKPa
+				if t.YLoad == "tps" {
+					load = tps
+				}
+				alerts[i].Tune = fmt.Sprintf("tune target %.1f", t.Lookup(rpm, load))
+			}
+		case "rev":
+			alerts[i].Tune = fmt.Sprintf("tune limit %.0f", revLimit(ref))
+		case "boost":
+			alerts[i].Tune = fmt.Sprintf("tune limit %.0f kPa", ref.BoostLimit)
+		}
+	}
+	return alerts
+}
+
+// revLimit is the RPM the tune starts limiting at.
+func revLimit(ref *tune.Reference) float64 {
+	if ref.SoftRevLimit > 0 {
+		return ref.SoftRevLimit
+	}
+	return ref.RevLimit
+}
+
+// handleTune stores reference values from a TunerStudio tune. The MSQ is
+// the request body; with ?apply=1 the suggested thresholds are saved too.
+//
+//	GET    /api/tune          — reference values, or 404
+//	POST   /api/tune[?apply=1&name=file.msq] — upload an MSQ
+//	DELETE /api/tune          — forget the tune
+func (s *Server) handleTune(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ref := s.tuneRef.Load()
+		if ref == nil {
+			http.Error(w, "no tune loaded", 404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			*tune.Reference
+			Suggested ThresholdConfig `json:"suggested"`
+		}{ref, suggestThresholds(ref, s.cfg.Thresholds())})
+
+	case http.MethodPost:
+		ref, err := tune.Parse(http.MaxBytesReader(w, r.Body, maxTuneBytes))
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		ref.File = r.URL.Query().Get("name")
+		ref.Loaded = time.Now().UnixMilli()
+		if err := s.store.WriteJSON(tuneFile, ref); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		s.tuneRef.Store(ref)
+		log.Printf("[tune] loaded %s (%s): rev limit %.0f, boost cut %.0f kPa",
+			ref.File, ref.Signature, revLimit(ref), ref.BoostLimit)
+
+		suggested := suggestThresholds(ref, s.cfg.Thresholds())
+		applied := r.URL.Query().Get("apply") == "1"
+		if applied {
+			if err := s.applyThresholds(suggested); err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			*tune.Reference
+			Suggested ThresholdConfig `json:"suggested"`
+			Applied   bool            `json:"applied"`
+		}{ref, suggested, applied})
+
+	case http.MethodDelete:
+		s.tuneRef.Store(nil)
+		if err := s.store.Remove(tuneFile); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+
+	default:
+		http.Error(w, "method not allowed", 405)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// UDP telemetry packets (docs/UDP_TELEMETRY.md): a 14-byte header, then
+// each field in order, little-endian.
+//
+//	"GD" | version | flags | seq u16 | stamp u64 (Unix ms) | fields
+const (
+	udpOutVersion = 1
+	udpOutHeader  = 14
+	udpOutMax     = 1400 // Packet size limit, under a typical MTU
+
+	udpFlagECU = 0x01 // ECU data fresh
+	udpFlagGPS = 0x02 // GPS has a fix
+)
+
+// udpOutDefault is sent when no fields are configured.
+var udpOutDefault = []UDPFieldConfig{
+	{Channel: "rpm"}, {Channel: "speed"}, {Channel: "tps"}, {Channel: "map"},
+	{Channel: "coolant"}, {Channel: "afr"},
+}
+
+// udpField is a field's place in the packet.
+type udpField struct {
+	UDPFieldConfig
+	Byte int `json:"byte"` // Offset in the packet
+	Size int `json:"size"`
+}
+
+// udpLayout resolves fields' defaults and positions and returns the
+// packet size.
+func udpLayout(fields []UDPFieldConfig) ([]udpField, int, error) {
+	if len(fields) == 0 {
+		fields = udpOutDefault
+	}
+	out := make([]udpField, len(fields))
+	at := udpOutHeader
+	for i, fc := range fields {
+		if fc.Type == "" {
+			fc.Type = "f32"
+		}
+		if fc.Factor == 0 {
+			fc.Factor = 1
+		}
+		f := udpField{UDPFieldConfig: fc, Byte: at}
+		switch fc.Type {
+		case "f32":
+			f.Size = 4
+		case "i16", "u16":
+			f.Size = 2
+		case "u8":
+			f.Size = 1
+		default:
+			return nil, 0, fmt.Errorf("%s: unknown type %q (f32, i16, u16 or u8)", fc.Channel, fc.Type)
+		}
+		at += f.Size
+		out[i] = f
+	}
+	if at > udpOutMax {
+		return nil, 0, fmt.Errorf("packet of %d bytes is over %d", at, udpOutMax)
+	}
+	return out, at, nil
+}
+
+// put stores v, or the type's "no value" marker if ok is false: NaN for
+// f32, the type's maximum for integers (which values are clamped below).
+func (f udpField) put(b []byte, v float64, ok bool) {
+	p := b[f.Byte:]
+	if f.Type == "f32" {
+		if !ok {
+			v = math.NaN()
+		}
+		binary.LittleEndian.PutUint32(p, math.Float32bits(float32(v)))
+		return
+	}
+	raw := math.Round((v - f.Offset) / f.Factor)
+	switch f.Type {
+	case "i16":
+		raw = max(math.MinInt16, min(math.MaxInt16-1, raw))
+		if !ok {
+			raw = math.MaxInt16
+		}
+		binary.LittleEndian.PutUint16(p, uint16(int16(raw)))
+	case "u16":
+		raw = max(0, min(math.MaxUint16-1, raw))
+		if !ok {
+			raw = math.MaxUint16
+		}
+		binary.LittleEndian.PutUint16(p, uint16(raw))
+	case "u8":
+		raw = max(0, min(math.MaxUint8-1, raw))
+		if !ok {
+			raw = math.MaxUint8
+		}
+		p[0] = uint8(raw)
+	}
+}
+
+// runUDPOut sends a packet of the configured fields at the configured
+// rate until ctx ends.
+func (s *Server) runUDPOut(ctx context.Context) {
+	cfg := s.cfg.UDPOutSnapshot()
+	fields, size, err := udpLayout(cfg.Fields)
+	if err != nil {
+		log.Printf("[udpout] %v", err)
+		return
+	}
+	d := net.Dialer{Control: allowBroadcast}
+	conn, err := d.DialContext(ctx, "udp", cfg.Target)
+	if err != nil {
+		log.Printf("[udpout] %s: %v", cfg.Target, err)
+		return
+	}
+	defer conn.Close()
+	log.Printf("[udpout] sending %d field(s), %d bytes, to %s", len(fields), size, cfg.Target)
+
+	rate := cfg.RateHz
+	if rate <= 0 {
+		rate = 20
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	var seq uint16
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			in := s.gauges.latest.Load()
+			if in == nil {
+				continue
+			}
+			pkt := make([]byte, size)
+			pkt[0], pkt[1], pkt[2] = 'G', 'D', udpOutVersion
+			if in.ecu != nil {
+				pkt[3] |= udpFlagECU
+			}
+			if in.gps {
+				pkt[3] |= udpFlagGPS
+			}
+			binary.LittleEndian.PutUint16(pkt[4:], seq)
+			binary.LittleEndian.PutUint64(pkt[6:], uint64(now.UnixMilli()))
+			seq++
+			for _, f := range fields {
+				v, ok := in.value(f.Channel)
+				f.put(pkt, v, ok)
+			}
+			// Sends fail while the network is down (no route). Nothing
+			// listening at the target is normal, and reported (by ICMP)
+			// only on every other write, so that's not a failure.
+			_, err := conn.Write(pkt)
+			if errors.Is(err, syscall.ECONNREFUSED) {
+				err = nil
+			}
+			switch {
+			case err != nil && !failing:
+				log.Printf("[udpout] send failed: %v", err)
+			case err == nil && failing:
+				log.Printf("[udpout] sending again")
+			}
+			failing = err != nil
+		}
+	}
+}
+
+// handleUDPSchema describes the UDP telemetry packet, so consumers can
+// configure themselves rather than hard-code the layout.
+//
+//	GET /api/udp/schema
+func (s *Server) handleUDPSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", 405)
+		return
+	}
+	cfg := s.cfg.UDPOutSnapshot()
+	fields, size, err := udpLayout(cfg.Fields)
+	if err != nil {
+		http.Error(w, err.Error(), 409)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"target":  cfg.Target,
+		"magic":   "GD",
+		"version": udpOutVersion,
+		"header":  udpOutHeader,
+		"size":    size,
+		"fields":  fields,
+	})
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// allowBroadcast is a net.Dialer Control that lets the socket send to a
+// broadcast address, which otherwise fails with EACCES.
+func allowBroadcast(network, address string, c syscall.RawConn) error {
+	var err error
+	if cerr := c.Control(func(fd uintptr) {
+		err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_BROADCAST, 1)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package server
+
+import "syscall"
+
+// allowBroadcast is a no-op off Linux; broadcast targets may be refused.
+func allowBroadcast(network, address string, c syscall.RawConn) error {
+	return nil
+}
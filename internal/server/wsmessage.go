@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+// wsRequest is a message from a WebSocket client (docs/WEBSOCKET.md):
+// JSON text, whatever encoding the client's frames are in. It carries a
+// command or a subscription, and an ID the reply echoes.
+type wsRequest struct {
+	ID        int64             `json:"id"`
+	Command   string            `json:"command,omitempty"` // A keypad action, e.g. "trip_reset" or "layout:race"
+	Subscribe *subscribeRequest `json:"subscribe,omitempty"`
+}
+
+// Reply answers a WebSocket client's request.
+type Reply struct {
+	ID     int64  `json:"id"`
+	OK     bool   `json:"ok"`
+	Text   string `json:"text,omitempty"`   // What the command did, e.g. "TRIP A RESET"
+	Layout string `json:"layout,omitempty"` // Layout the command switched to
+	Error  string `json:"error,omitempty"`  // Why the request failed
+}
+
+// wsMessage carries out a WebSocket client's request and queues the
+// reply. Commands run as a keypad key bound to them would, and are
+// broadcast the same way so every dash shows what happened; page is the
+// layout last chosen by this client.
+func (s *Server) wsMessage(client *frameClient, msg []byte, page *string) {
+	var req wsRequest
+	err := json.Unmarshal(msg, &req)
+	reply := Reply{ID: req.ID}
+	switch {
+	case err != nil:
+	case req.Command != "" && req.Subscribe != nil:
+		err = errors.New("a command or a subscribe, not both")
+	case req.Subscribe != nil:
+		var sub *subscription
+		if sub, err = req.Subscribe.subscription(); err == nil {
+			client.subscribe(sub)
+		}
+	case req.Command != "":
+		act := s.inputAction(req.Command, page)
+		if act.Error != "" {
+			err = errors.New(act.Error)
+			break
+		}
+		reply.Text, reply.Layout = act.Text, act.Layout
+		s.broadcast(Frame{Keypad: &act, Stamp: time.Now().UnixMilli()})
+	default:
+		err = errors.New("no command or subscribe")
+	}
+
+	if err != nil {
+		log.Printf("[ws] %s: request %d: %v", client.addr, req.ID, err)
+		reply.Error = err.Error()
+	} else {
+		reply.OK = true
+	}
+	if data, err := json.Marshal(Frame{Reply: &reply, Stamp: time.Now().UnixMilli()}); err == nil {
+		client.enqueue(client.encode(data), true)
+	}
+}
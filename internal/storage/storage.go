@@ -0,0 +1,205 @@
+// Package storage owns the dashboard's persistent data directory.
+//
+// Everything the dashboard writes at runtime (state, odometer, records,
+// tracks, captures) lives under a single configurable root so that it
+// can be backed up, wiped or moved as one unit. Writes are
+// atomic (temp file + fsync + rename + directory fsync), so a power cut
+// mid-write leaves either the old file or the new one — never a torn one.
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Well-known subdirectories of the data directory.
+const (
+	DirState    = "state"
+	DirRecords  = "records"
+	DirTracks   = "tracks"
+	DirCaptures = "captures"
+	DirInflux   = "influx"
+)
+
+// DefaultDir is used when no data directory is configured.
+const DefaultDir = "/var/lib/speeduino-dash"
+
+// Store is a crash-safe file store rooted at a single directory.
+// Names passed to its methods are slash-separated paths relative to the root.
+type Store struct {
+	root string
+	mu   sync.Mutex // serialises writers to the same store
+}
+
+// New returns a Store rooted at dir. The directory is created lazily on
+// first write, so a read-only or missing root doesn't prevent startup.
+func New(dir string) *Store {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Store{root: dir}
+}
+
+// Root returns the data directory.
+func (s *Store) Root() string { return s.root }
+
+// Path returns the absolute path of name inside the store.
+func (s *Store) Path(name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(name))
+}
+
+// Dir returns the absolute path of a subdirectory, creating it if needed.
+func (s *Store) Dir(name string) (string, error) {
+	p := s.Path(name)
+	if err := os.MkdirAll(p, 0755); err != nil {
+		return "", fmt.Errorf("storage: mkdir %s: %w", p, err)
+	}
+	return p, nil
+}
+
+// Exists reports whether name exists in the store.
+func (s *Store) Exists(name string) bool {
+	_, err := os.Stat(s.Path(name))
+	return err == nil
+}
+
+// ReadFile reads name from the store.
+func (s *Store) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(s.Path(name))
+}
+
+// WriteFile atomically replaces name with data.
+func (s *Store) WriteFile(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return WriteFileAtomic(s.Path(name), data, 0644)
+}
+
+// ReadJSON decodes name into v.
+func (s *Store) ReadJSON(name string, v interface{}) error {
+	data, err := s.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON atomically writes v as indented JSON to name.
+func (s *Store) WriteJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("storage: marshal %s: %w", name, err)
+	}
+	return s.WriteFile(name, data)
+}
+
+// Remove deletes name from the store. Missing files are not an error.
+func (s *Store) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.Path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns the names of the regular files in subdirectory dir.
+func (s *Store) List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(s.Path(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Migrate moves a file from a legacy location into the store, once.
+// If name already exists in the store, or oldPath doesn't exist, it does
+// nothing. The legacy file is left in place (renamed with a .migrated
+// suffix when possible) so a downgrade can still find it.
+func (s *Store) Migrate(name, oldPath string) error {
+	if oldPath == "" || s.Exists(name) {
+		return nil
+	}
+	data, err := os.ReadFile(oldPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("storage: migrate %s: %w", oldPath, err)
+	}
+	if err := s.WriteFile(name, data); err != nil {
+		return fmt.Errorf("storage: migrate %s: %w", oldPath, err)
+	}
+	if err := os.Rename(oldPath, oldPath+".migrated"); err != nil {
+		log.Printf("[storage] migrated %s → %s (legacy file left in place: %v)", oldPath, s.Path(name), err)
+		return nil
+	}
+	log.Printf("[storage] migrated %s → %s", oldPath, s.Path(name))
+	return nil
+}
+
+// WriteFileAtomic writes data to path via a temp file in the same
+// directory, fsyncs it, renames it over path, then fsyncs the directory.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("storage: mkdir %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("storage: create temp: %w", err)
+	}
+	tmpName := tmp.Name()
+	cleanup := func() { os.Remove(tmpName) }
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		cleanup()
+		return fmt.Errorf("storage: write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		cleanup()
+		return fmt.Errorf("storage: sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("storage: close %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		cleanup()
+		return fmt.Errorf("storage: chmod %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		cleanup()
+		return fmt.Errorf("storage: rename %s: %w", path, err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir fsyncs a directory so a preceding rename is durable.
+// Best effort: not every platform/filesystem allows syncing directories,
+// and the rename has already happened either way.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
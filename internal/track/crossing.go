@@ -0,0 +1,51 @@
+package track
+
+import "math"
+
+// Crossing reports whether travelling from a to b (heading degrees true)
+// crosses the gate. frac is where along a→b the crossing happened (0..1),
+// for interpolating the crossing time between fixes.
+func (l Line) Crossing(a, b Point, heading float64) (ok bool, frac float64) {
+	if l.HeadingValid && angleDiff(heading, l.Heading) > 90 {
+		return false, 0 // Wrong direction
+	}
+
+	// Local flat-earth frame centred on the gate — accurate to well
+	// under a centimetre over a few tens of metres.
+	g1, g2 := l.Endpoints()
+	o := Point{l.Lat, l.Lon}
+	p1, p2 := toLocal(o, a), toLocal(o, b)
+	q1, q2 := toLocal(o, g1), toLocal(o, g2)
+
+	r := [2]float64{p2[0] - p1[0], p2[1] - p1[1]}
+	s := [2]float64{q2[0] - q1[0], q2[1] - q1[1]}
+	den := cross(r, s)
+	if den == 0 {
+		return false, 0 // Parallel or no movement
+	}
+	qp := [2]float64{q1[0] - p1[0], q1[1] - p1[1]}
+	t := cross(qp, s) / den
+	u := cross(qp, r) / den
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return false, 0
+	}
+	return true, t
+}
+
+// toLocal projects p to metres east/north of origin o.
+func toLocal(o, p Point) [2]float64 {
+	x := (p.Lon - o.Lon) * math.Pi / 180 * earthRadiusM * math.Cos(o.Lat*math.Pi/180)
+	y := (p.Lat - o.Lat) * math.Pi / 180 * earthRadiusM
+	return [2]float64{x, y}
+}
+
+func cross(a, b [2]float64) float64 { return a[0]*b[1] - a[1]*b[0] }
+
+// angleDiff returns the absolute difference between two bearings (0..180).
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
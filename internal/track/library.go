@@ -0,0 +1,225 @@
+package track
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Where a library track came from.
+const (
+	SourceBuiltin = "builtin" // Shipped with the dash
+	SourceFile    = "file"    // A configured library file
+	SourceUser    = "user"    // Added through the API
+)
+
+//go:embed library.yaml
+var builtinLibrary []byte
+
+// libraryFile is the on-disk library format, YAML or JSON:
+//
+//	tracks:
+//	  - name: Club circuit
+//	    start_finish_points:       # One point each side of the line
+//	      - {lat: 43.797301, lon: -79.994421}
+//	      - {lat: 43.797162, lon: -79.994150}
+//	    sector_points:             # Optional, in driving order
+//	      - [{lat: ..., lon: ...}, {lat: ..., lon: ...}]
+//
+// A gate can instead be given as a Line (start_finish: {lat, lon,
+// heading, width_m}), as captured by the dash.
+type libraryFile struct {
+	Tracks []libraryEntry `yaml:"tracks" json:"tracks"`
+}
+
+type libraryEntry struct {
+	Track             `yaml:",inline"`
+	StartFinishPoints []Point   `yaml:"start_finish_points" json:"startFinishPoints"`
+	SectorPoints      [][]Point `yaml:"sector_points" json:"sectorPoints"`
+}
+
+// Parse reads a track library. JSON is chosen by a .json name, anything
+// else is read as YAML.
+func Parse(name string, data []byte) ([]*Track, error) {
+	var f libraryFile
+	var err error
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = yaml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("track: parse %s: %w", name, err)
+	}
+	var out []*Track
+	for i, e := range f.Tracks {
+		t := e.Track
+		if len(e.StartFinishPoints) >= 2 {
+			l := LineBetween(e.StartFinishPoints[0], e.StartFinishPoints[1])
+			t.StartFinish = &l
+		}
+		for _, sp := range e.SectorPoints {
+			if len(sp) >= 2 {
+				t.Sectors = append(t.Sectors, LineBetween(sp[0], sp[1]))
+			}
+		}
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("track: %s entry %d: %w", name, i+1, err)
+		}
+		out = append(out, &t)
+	}
+	return out, nil
+}
+
+// Validate checks the track is usable for timing.
+func (t *Track) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("missing name")
+	}
+	if t.StartFinish == nil {
+		return fmt.Errorf("%q has no start/finish line", t.Name)
+	}
+	if math.Abs(t.StartFinish.Lat) > 90 || math.Abs(t.StartFinish.Lon) > 180 ||
+		(t.StartFinish.Lat == 0 && t.StartFinish.Lon == 0) {
+		return fmt.Errorf("%q has a bad start/finish position", t.Name)
+	}
+	return nil
+}
+
+// Library is a set of track definitions looked up by name or position.
+// Names are unique, case-insensitively; a later definition replaces an
+// earlier one, so user tracks override files and files the built-ins.
+type Library struct {
+	mu     sync.RWMutex
+	tracks map[string]*Track // By lower-case name
+}
+
+// NewLibrary returns a library holding the built-in tracks.
+func NewLibrary() *Library {
+	l := &Library{tracks: make(map[string]*Track)}
+	tracks, err := Parse("library.yaml", builtinLibrary)
+	if err != nil {
+		panic(err) // Shipped file; caught by any run
+	}
+	for _, t := range tracks {
+		t.Source = SourceBuiltin
+		l.Add(t)
+	}
+	return l
+}
+
+// LoadPath adds the tracks in a library file, or in every .yaml, .yml and
+// .json file of a directory. It returns how many were added.
+func (l *Library) LoadPath(path string) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("track: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return 0, fmt.Errorf("track: %w", err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+	n := 0
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return n, fmt.Errorf("track: %w", err)
+		}
+		tracks, err := Parse(f, data)
+		if err != nil {
+			return n, err
+		}
+		for _, t := range tracks {
+			t.Source = SourceFile
+			l.Add(t)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Add stores t, replacing any track of the same name.
+func (l *Library) Add(t *Track) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tracks[strings.ToLower(t.Name)] = t
+}
+
+// Remove deletes the named track, reporting whether it was there.
+func (l *Library) Remove(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := strings.ToLower(name)
+	_, ok := l.tracks[key]
+	delete(l.tracks, key)
+	return ok
+}
+
+// Get returns a copy of the named track, or nil.
+func (l *Library) Get(name string) *Track {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	t, ok := l.tracks[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+	cp := *t
+	return &cp
+}
+
+// List returns a copy of every track, sorted by name.
+func (l *Library) List() []Track {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]Track, 0, len(l.tracks))
+	for _, t := range l.tracks {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Nearest returns a copy of the track whose start/finish line is closest
+// to p, and its distance in metres; nil for an empty library.
+func (l *Library) Nearest(p Point) (*Track, float64) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var best *Track
+	bestD := math.Inf(1)
+	for _, t := range l.tracks {
+		if d := DistanceM(p, t.Location()); d < bestD {
+			best, bestD = t, d
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+	cp := *best
+	return &cp, bestD
+}
+
+// Location returns the centre of the start/finish line (zero without one).
+func (t *Track) Location() Point {
+	if t.StartFinish == nil {
+		return Point{}
+	}
+	return Point{t.StartFinish.Lat, t.StartFinish.Lon}
+}
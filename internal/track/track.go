@@ -0,0 +1,103 @@
+// Package track holds circuit definitions — start/finish and sector lines —
+// used for GPS timing.
+package track
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultGateWidthM is the width of a captured timing line.
+const DefaultGateWidthM = 25.0
+
+// Point is a WGS84 position.
+type Point struct {
+	Lat float64 `yaml:"lat" json:"lat"`
+	Lon float64 `yaml:"lon" json:"lon"`
+}
+
+// Line is a timing gate: a segment centred on (Lat, Lon), perpendicular
+// to the direction of travel (Heading, degrees true), WidthM wide.
+// If HeadingValid is false the gate was captured while (nearly)
+// stationary and crossings should be accepted in either direction.
+type Line struct {
+	Lat          float64 `yaml:"lat" json:"lat"`
+	Lon          float64 `yaml:"lon" json:"lon"`
+	Heading      float64 `yaml:"heading" json:"heading"`
+	HeadingValid bool    `yaml:"heading_valid" json:"headingValid"`
+	WidthM       float64 `yaml:"width_m" json:"widthM"`
+}
+
+// Track is a circuit definition.
+type Track struct {
+	Name        string `yaml:"name" json:"name"`
+	StartFinish *Line  `yaml:"start_finish" json:"startFinish,omitempty"`
+	Sectors     []Line `yaml:"sectors" json:"sectors,omitempty"`
+	Created     int64  `yaml:"-" json:"created"`          // Unix ms
+	Updated     int64  `yaml:"-" json:"updated"`          // Unix ms
+	Source      string `yaml:"-" json:"source,omitempty"` // Library the definition came from (Source*)
+}
+
+// New returns an empty track definition.
+func New(name string) *Track {
+	now := time.Now().UnixMilli()
+	return &Track{Name: name, Created: now, Updated: now}
+}
+
+// Endpoints returns the two ends of the gate segment.
+func (l Line) Endpoints() (Point, Point) {
+	w := l.WidthM
+	if w <= 0 {
+		w = DefaultGateWidthM
+	}
+	// Gate runs perpendicular to travel: heading ± 90°
+	a := Offset(Point{l.Lat, l.Lon}, l.Heading-90, w/2)
+	b := Offset(Point{l.Lat, l.Lon}, l.Heading+90, w/2)
+	return a, b
+}
+
+const earthRadiusM = 6371000.0
+
+// Offset returns the point distM metres from p along bearing (degrees true).
+func Offset(p Point, bearing, distM float64) Point {
+	lat1 := p.Lat * math.Pi / 180
+	lon1 := p.Lon * math.Pi / 180
+	brg := bearing * math.Pi / 180
+	d := distM / earthRadiusM
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(brg))
+	lon2 := lon1 + math.Atan2(math.Sin(brg)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return Point{Lat: lat2 * 180 / math.Pi, Lon: lon2 * 180 / math.Pi}
+}
+
+// DistanceM returns the great-circle distance between two points in metres.
+func DistanceM(a, b Point) float64 {
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a.Lat*math.Pi/180)*math.Cos(b.Lat*math.Pi/180)*
+			math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusM * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// Bearing returns the initial great-circle bearing from a to b, degrees
+// true (0..360).
+func Bearing(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// LineBetween returns the gate running from a to b, e.g. two cones or the
+// ends of a painted line. The direction of travel is ambiguous, so
+// crossings count either way.
+func LineBetween(a, b Point) Line {
+	return Line{
+		Lat:     (a.Lat + b.Lat) / 2,
+		Lon:     (a.Lon + b.Lon) / 2,
+		Heading: math.Mod(Bearing(a, b)+90, 360),
+		WidthM:  DistanceM(a, b),
+	}
+}
@@ -0,0 +1,284 @@
+// Package tune reads reference values from a TunerStudio tune file
+// (.msq): rev limits, boost cut and targets, and the AFR target table.
+// Names follow the Speeduino ini; other firmware's limits are read where
+// the name is known.
+package tune
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Reference is what the dash uses from a tune.
+type Reference struct {
+	Signature string `json:"signature"`           // Firmware, e.g. "speeduino 202402"
+	Written   string `json:"written,omitempty"`   // When TunerStudio saved it
+	Comment   string `json:"comment,omitempty"`   // Tune comment
+	Loaded    int64  `json:"loaded"`              // Unix ms, set by the caller
+	File      string `json:"file,omitempty"`      // Uploaded file name
+	Algorithm string `json:"algorithm,omitempty"` // Fuel load: "speed density" or "alpha-n"
+
+	RevLimit     float64 `json:"revLimit,omitempty"`     // Hard rev limit, RPM
+	SoftRevLimit float64 `json:"softRevLimit,omitempty"` // Soft rev limit, RPM
+	BoostLimit   float64 `json:"boostLimit,omitempty"`   // Boost cut, kPa absolute
+
+	Boost    *Summary `json:"boost,omitempty"` // Boost target table, kPa (closed loop only)
+	AFR      *Summary `json:"afr,omitempty"`   // AFR target table
+	AFRTable *Table   `json:"afrTable,omitempty"`
+}
+
+// Summary condenses a table.
+type Summary struct {
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	HighLoad float64 `json:"highLoad"` // Mean of the highest-load row
+}
+
+// Table is a 3D table: Z[row][col] over Y (load) rows and X (RPM) columns.
+type Table struct {
+	X     []float64   `json:"x"`
+	Y     []float64   `json:"y"`
+	Z     [][]float64 `json:"z"`
+	YLoad string      `json:"yLoad"` // What Y is: "map" (kPa) or "tps" (%)
+}
+
+// Constant names, in the order tried.
+var (
+	revLimitNames     = []string{"HardRevLim", "rpmHardLimit"}
+	softRevLimitNames = []string{"SoftRevLim"}
+)
+
+// Parse reads a TunerStudio .msq file.
+func Parse(r io.Reader) (*Reference, error) {
+	var f msqFile
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("tune: parse msq: %w", err)
+	}
+	c := make(map[string]msqConstant)
+	for _, p := range f.Pages {
+		for _, k := range p.Constants {
+			c[k.Name] = k
+		}
+	}
+	if len(c) == 0 {
+		return nil, fmt.Errorf("tune: no constants in file")
+	}
+
+	ref := &Reference{
+		Signature: f.Version.Signature,
+		Written:   f.Bibliography.WriteDate,
+		Comment:   f.Bibliography.TuneComment,
+	}
+	for _, n := range revLimitNames {
+		if v, ok := c[n].scalar(); ok {
+			ref.RevLimit = v
+			break
+		}
+	}
+	for _, n := range softRevLimitNames {
+		if v, ok := c[n].scalar(); ok {
+			ref.SoftRevLimit = v
+			break
+		}
+	}
+
+	yLoad := "map"
+	switch alg := strings.ToLower(c["algorithm"].text()); {
+	case strings.Contains(alg, "alpha"):
+		ref.Algorithm, yLoad = "alpha-n", "tps"
+	case strings.Contains(alg, "speed"):
+		ref.Algorithm = "speed density"
+	}
+
+	if on(c["boostEnabled"]) {
+		if v, ok := c["boostLimit"].scalar(); ok && v > 0 {
+			ref.BoostLimit = v
+		}
+		if !strings.Contains(strings.ToLower(c["boostType"].text()), "open") {
+			if t, err := table(c, "boostTable", "rpmBinsBoost", "loadBinsBoost", "tps"); err == nil {
+				ref.Boost = t.summary()
+			}
+		}
+	}
+
+	if t, err := table(c, "afrTable", "rpmBinsAFR", "loadBinsAFR", yLoad); err == nil {
+		ref.AFRTable = t
+		ref.AFR = t.summary()
+	}
+
+	if ref.RevLimit == 0 && ref.SoftRevLimit == 0 && ref.AFR == nil && ref.Boost == nil {
+		return nil, fmt.Errorf("tune: no known reference values (signature %q)", ref.Signature)
+	}
+	return ref, nil
+}
+
+// Lookup interpolates the table at x, y, clamped to its edges.
+func (t *Table) Lookup(x, y float64) float64 {
+	i, fi := bin(t.Y, y)
+	j, fj := bin(t.X, x)
+	z := func(r, c int) float64 { return t.Z[r][c] }
+	top := z(i, j) + (z(i, j+1)-z(i, j))*fj
+	bot := z(i+1, j) + (z(i+1, j+1)-z(i+1, j))*fj
+	return top + (bot-top)*fi
+}
+
+// bin returns the index of the axis cell holding v and v's fraction
+// across it. Axes are ascending with at least two points.
+func bin(axis []float64, v float64) (int, float64) {
+	n := len(axis)
+	switch {
+	case v <= axis[0]:
+		return 0, 0
+	case v >= axis[n-1]:
+		return n - 2, 1
+	}
+	i := 0
+	for i < n-2 && v >= axis[i+1] {
+		i++
+	}
+	span := axis[i+1] - axis[i]
+	if span <= 0 {
+		return i, 0
+	}
+	return i, (v - axis[i]) / span
+}
+
+func (t *Table) summary() *Summary {
+	s := &Summary{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, row := range t.Z {
+		for _, v := range row {
+			s.Min = math.Min(s.Min, v)
+			s.Max = math.Max(s.Max, v)
+		}
+	}
+	// Rows run with the Y axis, so the highest load is the row with the
+	// largest bin
+	hi := 0
+	for i, y := range t.Y {
+		if y > t.Y[hi] {
+			hi = i
+		}
+	}
+	for _, v := range t.Z[hi] {
+		s.HighLoad += v
+	}
+	s.HighLoad = math.Round(s.HighLoad/float64(len(t.Z[hi]))*10) / 10
+	return s
+}
+
+// table assembles a 3D table from its Z values and axes.
+func table(c map[string]msqConstant, z, x, y, yLoad string) (*Table, error) {
+	get := func(name string) ([]float64, error) {
+		k, ok := c[name]
+		if !ok {
+			return nil, fmt.Errorf("tune: no %s", name)
+		}
+		return k.values()
+	}
+	zv, err := get(z)
+	if err != nil {
+		return nil, err
+	}
+	xv, err := get(x)
+	if err != nil {
+		return nil, err
+	}
+	yv, err := get(y)
+	if err != nil {
+		return nil, err
+	}
+	if len(xv) < 2 || len(yv) < 2 || len(zv) != len(xv)*len(yv) {
+		return nil, fmt.Errorf("tune: %s is %d values for %dx%d axes", z, len(zv), len(xv), len(yv))
+	}
+	t := &Table{X: xv, Y: yv, YLoad: yLoad}
+	for r := range yv {
+		t.Z = append(t.Z, zv[r*len(xv):(r+1)*len(xv)])
+	}
+	return t, nil
+}
+
+// ============================================================================
+// MSQ XML
+// ============================================================================
+
+type msqFile struct {
+	Bibliography struct {
+		WriteDate   string `xml:"writeDate,attr"`
+		TuneComment string `xml:"tuneComment,attr"`
+	} `xml:"bibliography"`
+	Version struct {
+		Signature string `xml:"signature,attr"`
+	} `xml:"versionInfo"`
+	Pages []struct {
+		Constants []msqConstant `xml:"constant"`
+	} `xml:"page"`
+}
+
+type msqConstant struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// text returns a string constant without its quotes.
+func (k msqConstant) text() string {
+	return strings.Trim(strings.TrimSpace(k.Value), `"`)
+}
+
+func (k msqConstant) scalar() (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(k.Value), 64)
+	return v, err == nil
+}
+
+func (k msqConstant) values() ([]float64, error) {
+	fields := strings.Fields(k.Value)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("tune: %s is empty", k.Name)
+	}
+	out := make([]float64, len(fields))
+	for i, s := range fields {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("tune: %s: %w", k.Name, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// on reports whether a bit constant is set ("On", "Yes", "Enabled" or 1).
+func on(k msqConstant) bool {
+	switch strings.ToLower(k.text()) {
+	case "on", "yes", "enabled", "1":
+		return true
+	}
+	return false
+}
+
+// charsetReader decodes the ISO-8859-1 TunerStudio writes.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "iso-8859-1", "latin1", "windows-1252", "us-ascii":
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", label)
+	}
+	var out bytes.Buffer
+	br := bufio.NewReader(input)
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return &out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.WriteRune(rune(b))
+	}
+}
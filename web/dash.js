@@ -36,9 +36,43 @@
         // Classic
         if (odo.total !== undefined && $('odoTotal')) $('odoTotal').textContent = D.convertDistance(odo.total).toFixed(1);
         if (odo.trip !== undefined && $('odoTrip')) $('odoTrip').textContent = D.convertDistance(odo.trip).toFixed(1);
+        if (odo.tripB && $('odoTripB')) $('odoTripB').textContent = D.convertDistance(odo.tripB.distance).toFixed(1);
         // Race
         if (odo.total !== undefined && $('raceOdoTotal')) $('raceOdoTotal').textContent = D.convertDistance(odo.total).toFixed(1);
         if (odo.trip !== undefined && $('raceOdoTrip')) $('raceOdoTrip').textContent = D.convertDistance(odo.trip).toFixed(1);
+        // Trip computer statistics, on hover/long-press
+        if (odo.tripA && $('odoTrip')) $('odoTrip').title = tripSummary(odo.tripA, odo.dfco);
+        if (odo.tripB && $('odoTripB')) $('odoTripB').title = tripSummary(odo.tripB);
+        if (odo.engineHours !== undefined && $('odoTotal')) $('odoTotal').title = `Engine ${odo.engineHours.toFixed(1)} h`;
+    }
+
+    // ---- Fuel consumption ----
+    // Average since the last fill-up, with the instant figure and range on
+    // hover/long-press
+    function updateFuel(fuel) {
+        if (!$('odoFuel')) return;
+        $('odoFuelRow').style.display = '';
+        const mpg = D.units.speed === 'mph';
+        const avg = mpg ? fuel.avgMpg : fuel.avgL100km;
+        $('odoFuel').textContent = avg ? avg.toFixed(1) : '--';
+        $('odoFuelUnit').textContent = mpg ? 'MPG' : 'L/100';
+        const now = mpg ? fuel.mpg : fuel.l100km;
+        let t = now ? `Now ${now.toFixed(1)} ${mpg ? 'MPG' : 'L/100 km'}` : `Now ${fuel.flowLph.toFixed(1)} L/h`;
+        t += ` · used ${fuel.usedL.toFixed(1)} L`;
+        if (fuel.tankL) t += ` · ${fuel.remainingL.toFixed(0)} L left`;
+        if (fuel.rangeKm) t += ` · range ${Math.round(D.convertDistance(fuel.rangeKm))} ${mpg ? 'mi' : 'km'}`;
+        $('odoFuel').title = t;
+    }
+
+    function tripSummary(t, dfco) {
+        const speedUnit = D.units.speed === 'mph' ? 'mph' : 'km/h';
+        const h = Math.floor(t.movingSec / 3600), m = Math.floor(t.movingSec % 3600 / 60);
+        let s = `Moving ${h}h ${m}m · avg ${Math.round(D.convertSpeed(t.avgSpeed))} · max ${Math.round(D.convertSpeed(t.maxSpeed))} ${speedUnit}`;
+        if (dfco) {
+            s += ` · DFCO ${Math.floor(dfco.seconds / 60)}m ${Math.round(dfco.seconds % 60)}s`;
+            if (dfco.savedL) s += `, ~${dfco.savedL.toFixed(2)} L saved`;
+        }
+        return s;
     }
 
     // ---- Unit Labels ----
@@ -57,6 +91,7 @@
         if ($('iatUnit')) $('iatUnit').textContent = tLabel;
         if ($('odoDistUnit')) $('odoDistUnit').textContent = distUnit;
         if ($('odoTripUnit')) $('odoTripUnit').textContent = distUnit;
+        if ($('odoTripBUnit')) $('odoTripBUnit').textContent = distUnit;
 
         // Sweep
         if ($('sweepSpeedUnit')) $('sweepSpeedUnit').textContent = speedLabel;
@@ -75,9 +110,70 @@
 
     // ---- Frame Handler ----
     D.onFrame = function (frame) {
+        if (frame.link) {
+            // Server stepped this client's frame rate for a weak link
+            showWarning(frame.link.reduced
+                ? 'SLOW CONNECTION — ' + frame.link.rateHz + ' Hz'
+                : 'CONNECTION RECOVERED', 'notice');
+            return;
+        }
+        if (frame.keypad) {
+            // Keypad key or GPIO button press: a layout key, or what an action key did
+            const k = frame.keypad;
+            if (k.action === 'alert_ack') dismissWarning();
+            if (k.layout) activateLayout(k.layout);
+            else if (k.error) showWarning(k.action.toUpperCase().replace(/_/g, ' ') + ' FAILED', 'warning');
+            else if (k.text) showWarning(k.text, 'notice');
+            return;
+        }
         if (frame.odo) updateOdometer(frame.odo);
+        if (frame.fuel) updateFuel(frame.fuel);
+        updateQuiet(frame);
     };
 
+    // ---- Engine Off ----
+    function updateQuiet(frame) {
+        const overlay = $('quietOverlay');
+        if (!overlay) return;
+        overlay.classList.toggle('active', !!frame.power);
+        if (!frame.power) return;
+
+        const now = new Date(frame.stamp || Date.now());
+        $('quietClock').textContent = now.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
+        const q = frame.quiet;
+        $('quietBatt').textContent = q ? q.batteryVoltage.toFixed(1) + 'V' : '';
+        $('quietClt').textContent = q ? 'CLT ' + D.formatTemp(q.coolant) : '';
+        $('quietState').textContent = frame.power === 'sleep' ? 'ENGINE OFF — TAP TO WAKE' : 'ENGINE OFF';
+        updateCooldown(frame.cooldown);
+    }
+
+    // Post-shutdown coolant countdown
+    let cooldownDone = false;
+    function updateCooldown(cd) {
+        const el = $('quietCooldown');
+        if (!cd) { el.textContent = ''; cooldownDone = false; return; }
+        el.classList.toggle('done', cd.done);
+        if (cd.done) {
+            el.textContent = 'COOLED TO ' + D.formatTemp(cd.targetC) + ' — SAFE TO COVER';
+            if (!cooldownDone) showWarning('COOLDOWN DONE', 'warning');
+            cooldownDone = true;
+            return;
+        }
+        cooldownDone = false;
+        let eta = 'HEAT SOAK';
+        if (cd.remainingSec > 0) {
+            const m = Math.floor(cd.remainingSec / 60), s = Math.round(cd.remainingSec % 60);
+            eta = m + ':' + String(s).padStart(2, '0') + ' LEFT';
+        }
+        el.textContent = 'COOLDOWN ' + D.formatTemp(cd.coolantC) + ' → ' + D.formatTemp(cd.targetC) + ' · ' + eta;
+    }
+
+    if ($('quietOverlay')) {
+        $('quietOverlay').addEventListener('click', () => {
+            D.command('wake').catch(() => { });
+        });
+    }
+
     D.onConfig = function (cfg) {
         updateUnitLabels();
         if (cfg && cfg.layout) {
@@ -87,7 +183,7 @@
 
     // ---- Warning System ----
     function showWarning(text, priority) {
-        const priorities = { 'critical': 3, 'danger': 2, 'warning': 1 };
+        const priorities = { 'critical': 3, 'danger': 2, 'warning': 1, 'notice': 1 };
         const currentPriority = currentWarning ? priorities[currentWarning.priority] || 0 : 0;
         if ((priorities[priority] || 0) >= currentPriority) {
             currentWarning = { text, priority };
@@ -103,13 +199,35 @@
         }
     }
 
+    // Hides the banner whatever its priority, for an acknowledgement
+    function dismissWarning() {
+        if (warningTimer) clearTimeout(warningTimer);
+        $('warningOverlay').classList.remove('active');
+        currentWarning = null;
+    }
+
     function clearWarning() {
-        if (currentWarning && currentWarning.priority !== 'critical') {
+        // Notices stay up for their 3 s even when the data is fine
+        if (currentWarning && currentWarning.priority !== 'critical' && currentWarning.priority !== 'notice') {
             $('warningOverlay').classList.remove('active');
             currentWarning = null;
         }
     }
 
+    // VVT tracking-error warning level, or '' when within thresholds.
+    function vvtLevel(t, err) {
+        const e = Math.abs(err);
+        if (t.vvtErrorDanger > 0 && e >= t.vvtErrorDanger) return 'danger';
+        if (t.vvtErrorWarn > 0 && e >= t.vvtErrorWarn) return 'warning';
+        return '';
+    }
+
+    function noDataText(frame) {
+        if (!frame.lastData) return 'NO DATA — ECU & GPS';
+        const secs = Math.max(0, Math.round((Date.now() - frame.lastData) / 1000));
+        return 'CONNECTION LOST ' + (secs < 60 ? secs + 's' : Math.floor(secs / 60) + 'm') + ' AGO';
+    }
+
     function setCardState(id, state) {
         const el = $(id);
         if (!el) return;
@@ -117,6 +235,14 @@
         if (state) el.classList.add(state);
     }
 
+    // showCards hides cards whose channel the server pruned from the frame
+    function showCards(ids, show) {
+        for (const id of ids) {
+            const el = $(id);
+            if (el) el.style.display = show ? '' : 'none';
+        }
+    }
+
     function setStatus(id, cls) {
         const el = $(id);
         if (!el) return;
@@ -177,10 +303,9 @@
             const rpmInt = Math.round(smoothRPM);
             const engineRunning = smoothRPM > 500;
 
-            // Gear
-            const rawSpeedKph = speedData ? speedData.value : 0;
-            const calculatedGear = D.detectGear(smoothRPM, rawSpeedKph);
-            gear = calculatedGear !== null ? calculatedGear : (ecu.gear || 0);
+            // Gear: from the drivetrain ratios when configured (the
+            // server sets frame.calculatedGear), else the ECU's
+            gear = ecu.gear || 0;
             gearText = gear === 0 ? 'N' : String(gear);
 
             // RPM class
@@ -193,7 +318,14 @@
             const cltC = ecu.coolant;
             const iatC = ecu.iat;
             const knockRet = ecu.knockCor || 0;
+            const vvtCam = Math.abs(ecu.vvt2Error || 0) > Math.abs(ecu.vvt1Error || 0) ? 2 : 1;
+            const vvtErr = (vvtCam === 2 ? ecu.vvt2Error : ecu.vvt1Error) || 0;
+            const boostCtl = (frame.alerts || []).find(a => a.id === 'boost_ctl'); // Needs history, so from the server
+            const leanBoost = (frame.alerts || []).find(a => a.id === 'lean_boost'); // Counted per ECU frame on the server
             const knockCnt = ecu.knockCount || 0;
+            const hasOil = ecu.oilPressure !== undefined;
+            showCards(['oilCard', 'sweepOilCard', 'raceOilCard'], hasOil);
+            showCards(['knockCard', 'sweepKnockCard', 'raceKnockCard'], ecu.knockCor !== undefined);
 
             // Engine status determination
             let engineStatusText = 'OFF';
@@ -374,9 +506,10 @@
             // ---- Warnings (shared across all layouts, only when engine running) ----
             let wt = '', wp = '';
             if (engineRunning) {
-                if (cltC >= t.cltDanger) { wt = 'COOLANT ' + D.formatTemp(cltC); wp = 'critical'; }
+                if (leanBoost) { wt = leanBoost.text; wp = leanBoost.level; }
+                else if (cltC >= t.cltDanger) { wt = 'COOLANT ' + D.formatTemp(cltC); wp = 'critical'; }
                 else if (iatC >= t.iatDanger) { wt = 'INTAKE HOT ' + D.formatTemp(iatC); wp = 'critical'; }
-                else if (ecu.oilPressure < t.oilPWarn && ecu.rpm > 1000) {
+                else if (hasOil && ecu.oilPressure < t.oilPWarn && ecu.rpm > 1000) {
                     wt = 'LOW OIL ' + Math.round(oilVal) + ' ' + (D.units.pressure === 'psi' ? 'PSI' : D.units.pressure === 'bar' ? 'BAR' : 'kPa');
                     wp = 'critical';
                 }
@@ -384,11 +517,24 @@
                 else if (cltC >= t.cltWarn) { wt = 'COOLANT ' + D.formatTemp(cltC); wp = 'warning'; }
                 else if (iatC >= t.iatWarn) { wt = 'INTAKE ' + D.formatTemp(iatC); wp = 'warning'; }
                 else if (ecu.afr > 16.5 || ecu.afr < 10.5) { wt = 'AFR ' + ecu.afr.toFixed(1); wp = 'danger'; }
+                else if (t.pwImbalanceWarn > 0 && ecu.pwImbalance >= t.pwImbalanceWarn) { wt = 'INJ IMBALANCE ' + Math.round(ecu.pwImbalance) + '%'; wp = 'warning'; }
+                else if (boostCtl) { wt = boostCtl.text; wp = boostCtl.level; }
+                else if (vvtLevel(t, vvtErr)) { wt = 'VVT' + vvtCam + ' ERROR ' + (vvtErr > 0 ? '+' : '') + Math.round(vvtErr) + '°'; wp = vvtLevel(t, vvtErr); }
                 else if (ecu.batteryVoltage < t.battLow) { wt = 'LOW BATT ' + ecu.batteryVoltage.toFixed(1) + 'V'; wp = 'warning'; }
                 else if (ecu.batteryVoltage > t.battHigh) { wt = 'HIGH BATT ' + ecu.batteryVoltage.toFixed(1) + 'V'; wp = 'warning'; }
             }
+            // Configured alert rules and tire pressures, evaluated by the server
+            if (!wt) {
+                const rank = { critical: 3, danger: 2, warning: 1 };
+                const rule = (frame.alerts || []).filter(a => a.id.startsWith('rule:') || a.id.startsWith('tire_'))
+                    .sort((a, b) => (rank[b.level] || 0) - (rank[a.level] || 0))[0];
+                if (rule) { wt = rule.text; wp = rule.level; }
+            }
 
-            if (wt) showWarning(wt, wp); else clearWarning();
+            // Acknowledged alerts stay hidden until they end, get worse or another starts
+            const alerts = frame.alerts || [];
+            const acked = alerts.length > 0 && alerts.every(a => a.acked);
+            if (wt && !acked) showWarning(wt, wp); else clearWarning();
         } else {
             // No ECU data — all layouts
             setStatus('statusSync', '');
@@ -418,7 +564,9 @@
                 $('minEngineStatus').textContent = 'OFF';
                 $('minEngineStatus').className = 'minimal-status-item';
             }
-            clearWarning();
+            // Heartbeat frames: server is up but ECU and GPS have gone quiet
+            if (frame.noData) showWarning(noDataText(frame), 'danger');
+            else clearWarning();
         }
 
         // Always update speed display even without ECU
@@ -433,7 +581,7 @@
     // ---- Trip Reset ----
     if ($('btnResetTrip')) {
         $('btnResetTrip').addEventListener('click', () => {
-            fetch('/api/odo/reset-trip', { method: 'POST' })
+            D.command('trip_reset')
                 .then(() => {
                     if ($('odoTrip')) $('odoTrip').textContent = '0.0';
                     if ($('raceOdoTrip')) $('raceOdoTrip').textContent = '0.0';
@@ -441,6 +589,19 @@
                 .catch(() => { });
         });
     }
+    if ($('btnFillUp')) {
+        $('btnFillUp').addEventListener('click', () => {
+            if (!confirm('Record a fill-up to full?')) return;
+            D.command('fillup').catch(() => { });
+        });
+    }
+    if ($('btnResetTripB')) {
+        $('btnResetTripB').addEventListener('click', () => {
+            D.command('trip_b_reset')
+                .then(() => { if ($('odoTripB')) $('odoTripB').textContent = '0.0'; })
+                .catch(() => { });
+        });
+    }
 
     // ---- HP Peak Reset ----
     if ($('btnResetHP')) {
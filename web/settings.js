@@ -14,8 +14,7 @@
             $('previewRPM').textContent = frame.ecu.rpm;
             const speedKph = frame.speed ? frame.speed.value : 0;
             $('previewSpeed').textContent = Math.round(D.convertSpeed(speedKph));
-            const gear = D.detectGear(frame.ecu.rpm, speedKph);
-            $('previewGear').textContent = gear === null ? (frame.ecu.gear || 'N') : (gear === 0 ? 'N' : gear);
+            $('previewGear').textContent = frame.ecu.gear || 'N';
             const hp = D.calcEstimatedHP(speedKph, frame.stamp || Date.now());
             $('previewHP').textContent = Math.round(hp);
         }
@@ -41,6 +40,13 @@
                 $('cfgRpmDanger').value = t.rpmDanger;
                 $('cfgOilWarn').value = t.oilPWarn || 15;
                 $('cfgKnockWarn').value = t.knockWarn;
+                $('cfgPwImbalanceWarn').value = t.pwImbalanceWarn ?? 10;
+                $('cfgVvtErrorWarn').value = t.vvtErrorWarn ?? 5;
+                $('cfgVvtErrorDanger').value = t.vvtErrorDanger ?? 10;
+                $('cfgBoostErrorWarn').value = t.boostErrorWarn ?? 20;
+                $('cfgLeanBoostKpa').value = t.leanBoostKpa ?? 130;
+                $('cfgLeanBoostMargin').value = t.leanBoostMargin ?? 1.0;
+                $('cfgLeanBoostFrames').value = t.leanBoostFrames ?? 5;
                 $('cfgCltWarn').value = Math.round(tempF ? D.toFahrenheit(t.cltWarn) : t.cltWarn);
                 $('cfgCltDanger').value = Math.round(tempF ? D.toFahrenheit(t.cltDanger) : t.cltDanger);
                 $('cfgIatWarn').value = Math.round(tempF ? D.toFahrenheit(t.iatWarn) : t.iatWarn);
@@ -55,10 +61,19 @@
                 const dt = { ...D.drivetrain, ...cfg.drivetrain };
                 $('cfgFinalDrive').value = dt.finalDrive;
                 $('cfgTireCircum').value = dt.tireCircumM;
+                $('cfgReverseRatio').value = dt.reverseRatio || '';
                 $('cfgGearTolerance').value = Math.round(dt.gearTolerance * 100);
                 $('cfgShowGear').checked = dt.showGear !== false;
+                $('cfgLearnGears').checked = !!dt.learnGears;
                 buildGearRatioList(dt.gearRatios || []);
 
+                // Identity
+                const id = cfg.identity || {};
+                $('cfgIdName').value = id.name || '';
+                $('cfgIdPlate').value = id.plate || '';
+                $('cfgIdEngine').value = id.engine || '';
+                $('cfgIdTune').value = id.tune || '';
+
                 // Vehicle
                 const v = { ...D.vehicle, ...cfg.vehicle };
                 $('cfgVehicleMass').value = v.massKg;
@@ -66,6 +81,15 @@
                 $('cfgFrontalArea').value = v.frontalAreaM2;
                 $('cfgRollingResist').value = v.rollingResist;
 
+                // Fuel
+                const fuel = cfg.fuel || {};
+                $('cfgInjectorCC').value = fuel.injectorCcMin || '';
+                $('cfgInjectors').value = fuel.injectors || '';
+                $('cfgInjOpen').value = fuel.openTimeMs || '';
+                $('cfgSquirts').value = fuel.squirts || '';
+                $('cfgTankL').value = fuel.tankL || '';
+                $('cfgIdleLph').value = fuel.idleLph || '';
+
                 // Apply config to shared module for live preview
                 D.applyConfig(cfg.display || {});
             })
@@ -226,6 +250,60 @@
         setTimeout(() => { btn.textContent = '⚡ Auto-Fill Remaining Gears'; }, 3000);
     }
 
+    // ---- Gear Ratios Learned While Driving ----
+    function useLearnedGears() {
+        const btn = $('btnLearnedGears');
+        const label = '📈 Use Learned Ratios';
+        fetch('/api/gears/learn')
+            .then(r => r.json())
+            .then(st => {
+                if (!st.suggested || st.suggested.length < 2) {
+                    btn.textContent = st.enabled
+                        ? `${st.clusters.length} gear(s) found in ${Math.round(st.seconds / 60)} min — drive more`
+                        : 'Enable Learn While Driving first';
+                    setTimeout(() => { btn.textContent = label; }, 3000);
+                    return;
+                }
+                const list = st.suggested.map((r, i) => `${i + 1}: ${r.toFixed(3)}`).join('\n');
+                if (!confirm(`Use these gear ratios?\n\n${list}`)) return;
+                return fetch('/api/gears/learn?apply=1', { method: 'POST' })
+                    .then(r => { if (!r.ok) throw new Error(r.statusText); })
+                    .then(() => {
+                        buildGearRatioList(st.suggested);
+                        btn.textContent = '✓ Applied';
+                        setTimeout(() => { btn.textContent = label; }, 2500);
+                    });
+            })
+            .catch(err => console.error('[settings] learned gears failed', err));
+    }
+
+    // ---- Tire Circumference from GPS ----
+    function calibrateTire() {
+        const btn = $('btnTireCal');
+        const label = '📡 Calibrate Tire from GPS';
+        fetch('/api/speed/tirecal')
+            .then(r => r.json())
+            .then(st => {
+                if (!st.suggestedTireM) {
+                    btn.textContent = `${st.total.gpsKm.toFixed(1)} km compared — drive at least 5 km with GPS`;
+                    setTimeout(() => { btn.textContent = label; }, 3000);
+                    return;
+                }
+                const pct = Math.abs((1 - 1 / st.total.ratio) * 100).toFixed(1);
+                const dir = st.total.ratio > 1 ? 'low' : 'high';
+                if (!confirm(`VSS reads ${pct}% ${dir} over ${st.total.gpsKm.toFixed(1)} km.\n\n` +
+                    `Tire circumference: ${st.tireCircumM} m → ${st.suggestedTireM} m?`)) return;
+                return fetch('/api/speed/tirecal?apply=tire', { method: 'POST' })
+                    .then(r => { if (!r.ok) throw new Error(r.statusText); })
+                    .then(() => {
+                        $('cfgTireCircum').value = st.suggestedTireM;
+                        btn.textContent = '✓ Applied';
+                        setTimeout(() => { btn.textContent = label; }, 2500);
+                    });
+            })
+            .catch(err => console.error('[settings] tire calibration failed', err));
+    }
+
     // ---- Collect Gear Ratios ----
     function collectGearRatios() {
         const ratios = [];
@@ -269,23 +347,46 @@
                     iatDanger: toC(iatDangerVal),
                     oilPWarn: parseInt($('cfgOilWarn').value),
                     knockWarn: parseInt($('cfgKnockWarn').value),
+                    pwImbalanceWarn: parseFloat($('cfgPwImbalanceWarn').value) || 0,
+                    vvtErrorWarn: parseFloat($('cfgVvtErrorWarn').value) || 0,
+                    vvtErrorDanger: parseFloat($('cfgVvtErrorDanger').value) || 0,
+                    boostErrorWarn: parseFloat($('cfgBoostErrorWarn').value) || 0,
+                    leanBoostKpa: parseFloat($('cfgLeanBoostKpa').value) || 0,
+                    leanBoostMargin: parseFloat($('cfgLeanBoostMargin').value) || 0,
+                    leanBoostFrames: parseInt($('cfgLeanBoostFrames').value) || 1,
                     battLow: parseFloat($('cfgBattLow').value),
                     battHigh: parseFloat($('cfgBattHigh').value),
                 },
             },
             drivetrain: {
                 showGear: $('cfgShowGear').checked,
+                learnGears: $('cfgLearnGears').checked,
                 finalDrive: parseFloat($('cfgFinalDrive').value) || 3.73,
                 tireCircumM: parseFloat($('cfgTireCircum').value) || 1.95,
+                reverseRatio: parseFloat($('cfgReverseRatio').value) || 0,
                 gearTolerance: (parseInt($('cfgGearTolerance').value) || 15) / 100,
                 gearRatios: collectGearRatios(),
             },
+            identity: {
+                name: $('cfgIdName').value.trim(),
+                plate: $('cfgIdPlate').value.trim(),
+                engine: $('cfgIdEngine').value.trim(),
+                tune: $('cfgIdTune').value.trim(),
+            },
             vehicle: {
                 massKg: parseFloat($('cfgVehicleMass').value) || 1200,
                 dragCoeff: parseFloat($('cfgDragCoeff').value) || 0.32,
                 frontalAreaM2: parseFloat($('cfgFrontalArea').value) || 2.2,
                 rollingResist: parseFloat($('cfgRollingResist').value) || 0.012,
             },
+            fuel: {
+                injectorCcMin: parseFloat($('cfgInjectorCC').value) || 0,
+                injectors: parseInt($('cfgInjectors').value) || 4,
+                openTimeMs: parseFloat($('cfgInjOpen').value) || 0,
+                squirts: parseInt($('cfgSquirts').value) || 1,
+                tankL: parseFloat($('cfgTankL').value) || 0,
+                idleLph: parseFloat($('cfgIdleLph').value) || 0,
+            },
         };
 
         fetch('/api/config', {
@@ -297,6 +398,208 @@
             .catch(err => { console.error('[settings] save failed', err); });
     }
 
+    // ---- Track ----
+    function showTrack(t) {
+        const el = $('trackStatus');
+        if (!t || !t.startFinish) {
+            el.textContent = 'No start/finish line set';
+            return;
+        }
+        const sf = t.startFinish;
+        $('cfgTrackName').value = t.name || '';
+        el.textContent = `${t.name}: ${sf.lat.toFixed(6)}, ${sf.lon.toFixed(6)}` +
+            (sf.headingValid ? ` → ${Math.round(sf.heading)}°` : ' (no heading — capture while moving)');
+    }
+
+    function loadTrack() {
+        fetch('/api/track')
+            .then(r => r.json())
+            .then(showTrack)
+            .catch(err => { console.error('[settings] track load failed', err); });
+    }
+
+    function setStartFinish() {
+        fetch('/api/track/startfinish', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ name: $('cfgTrackName').value.trim() }),
+        })
+            .then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+            .then(showTrack)
+            .catch(err => { $('trackStatus').textContent = 'Capture failed: ' + err.message; });
+    }
+
+    function clearTrack() {
+        fetch('/api/track', { method: 'DELETE' })
+            .then(() => { $('cfgTrackName').value = ''; showTrack(null); })
+            .catch(err => { console.error('[settings] track clear failed', err); });
+    }
+
+    // ---- Tune reference ----
+    function showTune(ref) {
+        if (!ref) { $('tuneStatus').textContent = 'No tune loaded'; return; }
+        const parts = [ref.file || 'Tune', ref.signature];
+        const rev = ref.softRevLimit || ref.revLimit;
+        if (rev) parts.push('rev limit ' + rev);
+        if (ref.boostLimit) parts.push('boost cut ' + ref.boostLimit + ' kPa');
+        if (ref.afr) parts.push('AFR targets ' + ref.afr.min + '–' + ref.afr.max);
+        $('tuneStatus').textContent = parts.filter(Boolean).join(' · ');
+    }
+
+    function loadTune() {
+        fetch('/api/tune')
+            .then(r => r.ok ? r.json() : null)
+            .then(showTune)
+            .catch(err => { console.error('[settings] tune load failed', err); });
+    }
+
+    function uploadTune(file) {
+        fetch('/api/tune?apply=1&name=' + encodeURIComponent(file.name), { method: 'POST', body: file })
+            .then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+            .then(ref => { showTune(ref); loadConfig(); })
+            .catch(err => { $('tuneStatus').textContent = 'Tune load failed: ' + err.message; });
+    }
+
+    function clearTune() {
+        fetch('/api/tune', { method: 'DELETE' })
+            .then(() => showTune(null))
+            .catch(err => { console.error('[settings] tune clear failed', err); });
+    }
+
+    // ---- Snapshot ----
+    function captureSnapshot() {
+        fetch('/api/snapshot', { method: 'POST' })
+            .then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+            .then(snap => {
+                const el = $('snapshotStatus');
+                el.textContent = '';
+                const a = document.createElement('a');
+                a.href = snap.url;
+                a.target = '_blank';
+                a.textContent = snap.id;
+                el.append('Saved ', a);
+            })
+            .catch(err => { $('snapshotStatus').textContent = 'Capture failed: ' + err.message; });
+    }
+
+    // ---- ECU Diagnostics ----
+    function ecuCommand(cmd) {
+        const el = $('ecuCmdStatus');
+        el.textContent = cmd + '…';
+        fetch('/api/ecu/command', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ command: cmd }),
+        })
+            .then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+            .then(res => {
+                const val = res.value !== undefined ? res.value : (res.text || res.raw || '—');
+                el.textContent = cmd + ': ' + val;
+            })
+            .catch(err => { el.textContent = cmd + ' failed: ' + err.message; });
+    }
+
+    // Hide commands the ECU's protocol doesn't support
+    function loadEcuCommands() {
+        fetch('/api/ecu/command')
+            .then(r => r.ok ? r.json() : { commands: [] })
+            .then(res => {
+                document.querySelectorAll('[data-ecu-cmd]').forEach(btn => {
+                    btn.hidden = !res.commands.includes(btn.dataset.ecuCmd);
+                });
+            })
+            .catch(() => {});
+    }
+
+    // ---- ECU SD Logs ----
+    function listSdLogs() {
+        const status = $('sdLogStatus');
+        const list = $('sdLogList');
+        status.textContent = 'Reading SD card…';
+        list.textContent = '';
+        fetch('/api/ecu/sdlogs')
+            .then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+            .then(res => {
+                if (!res.status.present) { status.textContent = 'No SD card in the ECU'; return; }
+                status.textContent = res.files.length + ' log(s)' + (res.status.logging ? ' — ECU is logging' : '');
+                res.files.forEach(f => {
+                    const li = document.createElement('li');
+                    const a = document.createElement('a');
+                    a.href = '/api/ecu/sdlogs?file=' + f.index;
+                    a.textContent = f.name;
+                    const when = f.modified ? new Date(f.modified).toLocaleString() + ', ' : '';
+                    li.append(a, ' (' + when + (f.size / 1024).toFixed(1) + ' KB)');
+                    list.append(li);
+                });
+            })
+            .catch(err => { status.textContent = 'SD read failed: ' + err.message; });
+    }
+
+    // ---- ECU Trigger Logs ----
+    function captureToothLog(mode) {
+        const el = $('toothLogStatus');
+        el.textContent = 'Capturing ' + mode + ' log…';
+        fetch('/api/ecu/toothlog/start', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ mode: mode, seconds: 5 }),
+        })
+            .then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+            .then(res => {
+                el.textContent = res.entries + ' entries captured';
+                listToothLogs();
+            })
+            .catch(err => { el.textContent = mode + ' log failed: ' + err.message; });
+    }
+
+    function listToothLogs() {
+        fetch('/api/ecu/toothlog')
+            .then(r => r.ok ? r.json() : [])
+            .then(logs => {
+                const list = $('toothLogList');
+                list.textContent = '';
+                logs.forEach(l => {
+                    const li = document.createElement('li');
+                    const a = document.createElement('a');
+                    a.href = l.url;
+                    a.textContent = l.mode + ' ' + new Date(l.created).toLocaleString();
+                    li.append(a, ' (' + l.entries + ' entries)');
+                    list.append(li);
+                });
+            })
+            .catch(err => { console.error('[settings] tooth log list failed', err); });
+    }
+
+    // ---- Network ----
+    function loadNetwork() {
+        fetch('/api/network')
+            .then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+            .then(st => {
+                const wifi = st.wifi;
+                let text = 'WiFi ' + (wifi.interface ? wifi.interface + ': ' : '');
+                switch (wifi.mode) {
+                    case 'hotspot':
+                        text += 'hotspot "' + wifi.ssid + '"';
+                        if (wifi.stations !== undefined) text += ', ' + wifi.stations + ' connected';
+                        break;
+                    case 'client': text += 'joined "' + wifi.ssid + '"'; break;
+                    case 'disconnected': text += 'not connected to a network'; break;
+                    default: text += 'off';
+                }
+                if (wifi.error) text += ' (' + wifi.error + ')';
+                $('networkStatus').textContent = text;
+
+                const list = $('networkList');
+                list.textContent = '';
+                st.interfaces.forEach(i => {
+                    const li = document.createElement('li');
+                    li.textContent = i.name + ': ' + (i.addrs.length ? i.addrs.join(', ') : 'no address');
+                    list.append(li);
+                });
+            })
+            .catch(err => { $('networkStatus').textContent = 'Network status failed: ' + err.message; });
+    }
+
     // ---- Temperature unit change ----
     $('cfgTempUnit').addEventListener('change', function () {
         const newUnit = this.value;
@@ -316,6 +619,25 @@
     $('btnSave').addEventListener('click', saveConfig);
     $('btnSaveBottom').addEventListener('click', saveConfig);
     $('btnAutoFill').addEventListener('click', autoFillGears);
+    $('btnLearnedGears').addEventListener('click', useLearnedGears);
+    $('btnTireCal').addEventListener('click', calibrateTire);
+    $('btnStartFinish').addEventListener('click', setStartFinish);
+    $('btnClearTrack').addEventListener('click', clearTrack);
+    $('btnSnapshot').addEventListener('click', captureSnapshot);
+    $('btnSdLogs').addEventListener('click', listSdLogs);
+    $('btnNetwork').addEventListener('click', loadNetwork);
+    $('btnTuneUpload').addEventListener('click', () => $('tuneFile').click());
+    $('tuneFile').addEventListener('change', function () {
+        if (this.files.length) uploadTune(this.files[0]);
+        this.value = '';
+    });
+    $('btnTuneClear').addEventListener('click', clearTune);
+    document.querySelectorAll('[data-ecu-cmd]').forEach(btn => {
+        btn.addEventListener('click', () => ecuCommand(btn.dataset.ecuCmd));
+    });
+    document.querySelectorAll('[data-tooth-log]').forEach(btn => {
+        btn.addEventListener('click', () => captureToothLog(btn.dataset.toothLog));
+    });
 
     // Instructions toggle
     $('gearInstructionsToggle').addEventListener('click', function () {
@@ -327,5 +649,10 @@
     window.addEventListener('load', () => {
         D.connect();
         loadConfig();
+        loadTrack();
+        loadEcuCommands();
+        listToothLogs();
+        loadNetwork();
+        loadTune();
     });
 })();
@@ -11,14 +11,19 @@ window.SpeeduinoDash = (function () {
     let ws = null;
     let reconnectTimer = null;
     let lastFrame = null;
+    let dataFrame = null; // Data frame the deltas apply to
     let connected = false;
+    let nextRequest = 1;
+    const pending = new Map(); // Request id → { resolve, reject }
 
     let thresholds = {
         rpmWarn: 6000, rpmDanger: 7000, rpmMax: 8000,
         oilPWarn: 15,
         cltWarn: 95, cltDanger: 105,
         iatWarn: 60, iatDanger: 75,
-        knockWarn: 3,
+        knockWarn: 3, pwImbalanceWarn: 10,
+        vvtErrorWarn: 5, vvtErrorDanger: 10, boostErrorWarn: 20,
+        leanBoostKpa: 130, leanBoostMargin: 1.0, leanBoostFrames: 5,
         battLow: 12.0, battHigh: 15.5,
     };
 
@@ -46,7 +51,8 @@ window.SpeeduinoDash = (function () {
     // ---- WebSocket ----
     function connect() {
         const proto = location.protocol === 'https:' ? 'wss:' : 'ws:';
-        ws = new WebSocket(`${proto}//${location.host}/ws`);
+        ws = new WebSocket(`${proto}//${location.host}/ws?delta=1`);
+        dataFrame = null;
 
         ws.onopen = () => {
             if (reconnectTimer) { clearTimeout(reconnectTimer); reconnectTimer = null; }
@@ -56,22 +62,67 @@ window.SpeeduinoDash = (function () {
 
         ws.onmessage = (evt) => {
             try {
-                const frame = JSON.parse(evt.data);
+                let frame = JSON.parse(evt.data);
+                if (frame.reply) {
+                    const p = pending.get(frame.reply.id);
+                    pending.delete(frame.reply.id);
+                    if (p) frame.reply.ok ? p.resolve(frame.reply) : p.reject(new Error(frame.reply.error));
+                    return;
+                }
+                if (frame.delta) {
+                    // Data frames come as changes from the last one, with
+                    // a whole one every few seconds
+                    dataFrame = frame.key ? frame.delta : mergePatch(dataFrame, frame.delta);
+                    frame = dataFrame;
+                }
                 if (frame.config) {
                     applyConfig(frame.config);
                     if (onConfig) onConfig(frame.config);
                 }
-                if (frame.ecu || frame.gps || frame.speed) lastFrame = frame;
+                if (frame.ecu || frame.gps || frame.speed || frame.noData || frame.power) lastFrame = frame;
                 if (onFrame) onFrame(frame);
             } catch (e) {
                 console.error('[ws] parse error', e);
             }
         };
 
-        ws.onclose = () => { connected = false; if (onConnectionChange) onConnectionChange(false); scheduleReconnect(); };
+        ws.onclose = () => {
+            connected = false;
+            for (const p of pending.values()) p.reject(new Error('disconnected'));
+            pending.clear();
+            if (onConnectionChange) onConnectionChange(false);
+            scheduleReconnect();
+        };
         ws.onerror = () => ws.close();
     }
 
+    // command runs a keypad action on the server (e.g. 'trip_reset') and
+    // resolves with the reply, or rejects if it failed or there's no
+    // connection.
+    function command(name) {
+        return new Promise((resolve, reject) => {
+            if (!ws || ws.readyState !== WebSocket.OPEN) {
+                reject(new Error('not connected'));
+                return;
+            }
+            const id = nextRequest++;
+            pending.set(id, { resolve, reject });
+            ws.send(JSON.stringify({ id, command: name }));
+        });
+    }
+
+    // mergePatch applies a JSON Merge Patch (RFC 7396) to target without
+    // changing it, so earlier frames stay as they were.
+    function mergePatch(target, patch) {
+        if (patch === null || typeof patch !== 'object' || Array.isArray(patch)) return patch;
+        const out = (target && typeof target === 'object' && !Array.isArray(target)) ? { ...target } : {};
+        for (const k in patch) {
+            if (patch[k] === null) delete out[k];
+            else out[k] = mergePatch(out[k], patch[k]);
+        }
+        return out;
+    }
+
     function scheduleReconnect() {
         if (!reconnectTimer) {
             reconnectTimer = setTimeout(() => { reconnectTimer = null; connect(); }, 1000);
@@ -115,20 +166,6 @@ window.SpeeduinoDash = (function () {
         return rpm / wheelRPM;
     }
 
-    function detectGear(rpm, speedKph) {
-        if (!drivetrain.gearRatios || drivetrain.gearRatios.length === 0) return null;
-        const actualRatio = calcOverallRatio(rpm, speedKph);
-        if (actualRatio === 0) return 0;
-        let bestGear = 0, bestError = Infinity;
-        const tolerance = drivetrain.gearTolerance || 0.15;
-        for (let i = 0; i < drivetrain.gearRatios.length; i++) {
-            const expectedRatio = drivetrain.gearRatios[i] * drivetrain.finalDrive;
-            const error = Math.abs(actualRatio - expectedRatio) / expectedRatio;
-            if (error < bestError && error < tolerance) { bestError = error; bestGear = i + 1; }
-        }
-        return bestGear;
-    }
-
     // ---- HP Estimation ----
     const AIR_DENSITY = 1.225;
     const GRAVITY = 9.81;
@@ -155,6 +192,7 @@ window.SpeeduinoDash = (function () {
     // ---- Public API ----
     return {
         connect,
+        command,
         get lastFrame() { return lastFrame; },
         get thresholds() { return thresholds; },
         get units() { return units; },
@@ -169,7 +207,7 @@ window.SpeeduinoDash = (function () {
         applyConfig,
         toFahrenheit, toCelsius, displayTemp, formatTemp,
         convertPressure, convertSpeed, convertDistance,
-        calcOverallRatio, detectGear,
+        calcOverallRatio,
         calcEstimatedHP, resetPeakHP,
     };
 })();